@@ -7,19 +7,27 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"time"
 
 	"github.com/exven/pos-system/internal/config"
 	"github.com/exven/pos-system/internal/server"
 	"github.com/exven/pos-system/modules/auth"
+	"github.com/exven/pos-system/modules/data_import"
+	"github.com/exven/pos-system/modules/inventory"
 	"github.com/exven/pos-system/modules/outlets"
 	"github.com/exven/pos-system/modules/products"
+	"github.com/exven/pos-system/modules/purchasing"
 	"github.com/exven/pos-system/modules/roles"
 	"github.com/exven/pos-system/modules/subscription_plans"
+	"github.com/exven/pos-system/modules/tenant"
+	"github.com/exven/pos-system/modules/transactions"
+	"github.com/exven/pos-system/modules/usage"
 	"github.com/exven/pos-system/shared/container"
 	"github.com/exven/pos-system/shared/infrastructure/cache"
 	"github.com/exven/pos-system/shared/infrastructure/database"
 	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"github.com/exven/pos-system/shared/infrastructure/storage"
 	"gorm.io/gorm"
 )
 
@@ -57,6 +65,23 @@ func main() {
 	}
 	defer redisClient.Close()
 
+	fileStorage, err := storage.New(storage.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: filepath.Join(cfg.Storage.LocalPath, "uploads"),
+		PublicURL: cfg.Storage.PublicURL,
+		S3: storage.S3Config{
+			Endpoint:     cfg.Storage.S3Endpoint,
+			Region:       cfg.Storage.S3Region,
+			Bucket:       cfg.Storage.S3Bucket,
+			AccessKey:    cfg.Storage.S3AccessKey,
+			SecretKey:    cfg.Storage.S3SecretKey,
+			UsePathStyle: cfg.Storage.S3UsePathStyle,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
 	// RabbitMQ temporarily disabled
 	// eventBus, err := messaging.NewRabbitMQEventBus(
 	//		cfg.RabbitMQ.URL,
@@ -72,23 +97,46 @@ func main() {
 
 	di := container.New()
 
-	registerSharedServices(di, cfg, db, redisClient, eventBus)
+	registerSharedServices(di, cfg, db, redisClient, eventBus, fileStorage)
 
 	authModule := auth.NewModule(di, db, redisClient, eventBus, cfg.JWT)
 	authModule.Register()
 
-	productsModule := products.NewModule(di, db, eventBus)
+	productsModule := products.NewModule(di, db, eventBus, fileStorage, cfg.FileUpload)
 	productsModule.Register()
 
 	outletsModule := outlets.NewModule(di, db, eventBus)
 	outletsModule.Register()
 
+	inventoryModule := inventory.NewModule(di, db, eventBus)
+	inventoryModule.Register()
+
+	purchasingModule := purchasing.NewModule(di, db, eventBus)
+	purchasingModule.Register()
+
 	subscriptionPlansModule := subscription_plans.NewModule(di, db, eventBus)
 	subscriptionPlansModule.Register()
 
 	rolesModule := roles.NewModule(di, db, eventBus)
 	rolesModule.Register()
 
+	tenantModule := tenant.NewModule(di, db, eventBus, cfg.Storage, cfg.DataExport)
+	tenantModule.Register()
+
+	dataImportModule := data_import.NewModule(di, db, eventBus, cfg.Storage)
+	dataImportModule.Register()
+
+	usageModule := usage.NewModule(di, db, redisClient, cfg.Storage)
+	usageModule.Register()
+
+	salePrices := server.NewSalePriceResolver(productsModule.GetPriceListService(), productsModule.GetUnitService())
+	transactionsModule := transactions.NewModule(di, db, eventBus, salePrices, server.NewSaleStockRecorder(inventoryModule.GetService()), usageModule.GetMeter())
+	transactionsModule.Register()
+
+	rollupCtx, stopRollup := context.WithCancel(context.Background())
+	defer stopRollup()
+	go usageModule.StartRollup(rollupCtx, cfg.Usage.RollupInterval)
+
 	srv := server.New(cfg, di)
 	log.Println("Server instance created successfully")
 	log.Println("Auth module registered successfully")
@@ -119,7 +167,7 @@ func main() {
 	log.Println("Server shutdown complete")
 }
 
-func registerSharedServices(di *container.DIContainer, cfg *config.Config, db *gorm.DB, redisClient *cache.RedisClient, eventBus messaging.EventBus) {
+func registerSharedServices(di *container.DIContainer, cfg *config.Config, db *gorm.DB, redisClient *cache.RedisClient, eventBus messaging.EventBus, fileStorage storage.Storage) {
 	di.RegisterSingleton("config", func() *config.Config {
 		return cfg
 	})
@@ -135,4 +183,8 @@ func registerSharedServices(di *container.DIContainer, cfg *config.Config, db *g
 	di.RegisterSingleton("eventBus", func() messaging.EventBus {
 		return eventBus
 	})
+
+	di.RegisterSingleton("storage", func() storage.Storage {
+		return fileStorage
+	})
 }
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/inventory"
+	"github.com/exven/pos-system/modules/products"
+	productsdomain "github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/container"
 	"github.com/exven/pos-system/shared/infrastructure/database"
 	"github.com/exven/pos-system/shared/utils/crypto"
 	"gorm.io/gorm"
@@ -16,11 +22,15 @@ import (
 
 func main() {
 	var command string
-	flag.StringVar(&command, "command", "", "Migration command: migrate, seed, rollback")
+	var tenantID uint64
+	var fix bool
+	flag.StringVar(&command, "command", "", "Migration command: migrate, seed, rollback, reconcile")
+	flag.Uint64Var(&tenantID, "tenant", 0, "Tenant to reconcile stock for; all tenants when 0")
+	flag.BoolVar(&fix, "fix", false, "Set drifted stock levels to their ledger sum when reconciling")
 	flag.Parse()
 
 	if command == "" {
-		log.Fatal("Command is required. Use -command=migrate|seed|rollback")
+		log.Fatal("Command is required. Use -command=migrate|seed|rollback|reconcile")
 	}
 
 	cfg, err := config.Load()
@@ -57,6 +67,15 @@ func main() {
 	case "rollback":
 		fmt.Println("Rollback functionality not implemented yet")
 		os.Exit(1)
+	case "reconcile":
+		drifted, err := runReconcile(db, tenantID, fix)
+		if err != nil {
+			log.Fatalf("Reconciliation failed: %v", err)
+		}
+		// Drift left in place fails the command, so scheduled checks notice it
+		if drifted > 0 && !fix {
+			os.Exit(1)
+		}
 	default:
 		log.Fatalf("Unknown command: %s", command)
 	}
@@ -64,11 +83,12 @@ func main() {
 
 func runMigrations(db *gorm.DB) error {
 	// Auto migrate all schema models in proper order to handle foreign key dependencies
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		// Subscription and tenant management
 		&database.SubscriptionPlan{},
 		&database.Tenant{},
 		&database.TenantSubscription{},
+		&database.TenantUsage{},
 
 		// User management and roles
 		&database.Role{},
@@ -79,18 +99,59 @@ func runMigrations(db *gorm.DB) error {
 		// Product management
 		&database.ProductCategory{},
 		&database.Product{},
+		&database.ProductVariant{},
 		&database.ProductStock{},
+		&database.Unit{},
+		&database.ProductUnit{},
+		&database.ProductRecipeItem{},
+		&database.ProductBundleGroup{},
+		&database.ProductBundleItem{},
+		&database.ModifierGroup{},
+		&database.Modifier{},
+		&database.ProductModifierGroup{},
+		&database.CategoryModifierGroup{},
+		&database.ProductPriceChange{},
+		&database.Image{},
 
 		// Customer management
+		&database.CustomerGroup{},
 		&database.Customer{},
 
+		// Price lists, scoped to outlets and customer groups
+		&database.PriceList{},
+		&database.PriceListOutlet{},
+		&database.PriceListCustomerGroup{},
+		&database.PriceListItem{},
+
+		// Stock lots, referenced by sale and receipt lines
+		&database.StockLot{},
+
 		// Sales and transactions
 		&database.SalesTransaction{},
 		&database.TransactionItem{},
+		&database.TransactionItemComponent{},
+		&database.TransactionItemModifier{},
 		&database.TransactionPayment{},
 
 		// Stock movements and inventory
 		&database.StockMovement{},
+		&database.StockAdjustment{},
+		&database.StockAdjustmentItem{},
+		&database.StockTransfer{},
+		&database.StockTransferItem{},
+		&database.Stocktake{},
+		&database.StocktakeItem{},
+		&database.StocktakeCount{},
+		&database.StockAlert{},
+		&database.StockMovementLot{},
+		&database.StockCostLayer{},
+
+		// Suppliers and purchasing
+		&database.Supplier{},
+		&database.PurchaseOrder{},
+		&database.PurchaseOrderItem{},
+		&database.GoodsReceipt{},
+		&database.GoodsReceiptItem{},
 
 		// Archive tables for data retention
 		&database.ArchivedTransaction{},
@@ -100,7 +161,223 @@ func runMigrations(db *gorm.DB) error {
 		// System and audit tables
 		&database.AuditLog{},
 		&database.DataRetentionLog{},
+		&database.ImpersonationSession{},
+		&database.TenantExport{},
+		&database.ImportJob{},
 	)
+	if err != nil {
+		return err
+	}
+
+	// Data retention logs used to cascade with their tenant, which would erase
+	// the record of a tenant deletion together with the tenant itself
+	if db.Migrator().HasConstraint(&database.DataRetentionLog{}, "fk_data_retention_logs_tenant") {
+		if err := db.Migrator().DropConstraint(&database.DataRetentionLog{}, "fk_data_retention_logs_tenant"); err != nil {
+			return err
+		}
+	}
+
+	// Stock rows used to be unique per product and outlet; variants hold
+	// their own rows now, so uniqueness moved to the partial indexes
+	if db.Migrator().HasIndex(&database.ProductStock{}, "idx_product_stocks_product_outlet") {
+		if err := db.Migrator().DropIndex(&database.ProductStock{}, "idx_product_stocks_product_outlet"); err != nil {
+			return err
+		}
+	}
+
+	// Transaction items used to name the lot they were sold from, which one
+	// column cannot do for an item taken from several lots; the lots of a
+	// sale are traced through its stock movements in stock_movement_lots
+	for _, model := range []interface{}{&database.TransactionItem{}, &database.ArchivedTransactionItem{}} {
+		if db.Migrator().HasColumn(model, "lot_id") {
+			if err := db.Migrator().DropColumn(model, "lot_id"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, statement := range database.ProductSearchSQL {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to set up product search: %w", err)
+		}
+	}
+
+	for _, statement := range database.ProductUnitSQL {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to set line units: %w", err)
+		}
+	}
+
+	if err := uniqueBarcodes(db); err != nil {
+		return err
+	}
+
+	return convertProductVariants(db)
+}
+
+// convertProductVariants generates variants for the products whose options
+// were saved in their variants column before variants had rows of their own.
+// Products that already have variants are skipped, so migrating again
+// changes nothing; a product that cannot be converted is reported and left
+// as it is.
+func convertProductVariants(db *gorm.DB) error {
+	var productsToConvert []database.Product
+	err := db.Where("variants IS NOT NULL AND variants::text NOT IN ('null', '{}')").
+		Where("NOT EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = products.id)").
+		Order("id").
+		Find(&productsToConvert).Error
+	if err != nil {
+		return fmt.Errorf("failed to find products with variants: %w", err)
+	}
+
+	productService := products.NewModule(container.New(), db, nil, nil, config.FileUploadConfig{}).GetService()
+	ctx := context.Background()
+
+	for _, product := range productsToConvert {
+		options := variantOptions(product.Variants)
+		if len(options) == 0 {
+			continue
+		}
+
+		req := productsdomain.GenerateVariantsRequest{Options: options}
+		variants, err := productService.GenerateVariants(ctx, product.TenantID, product.ID, req)
+		if err != nil {
+			fmt.Printf("product %d (%s): variants not converted: %v\n", product.ID, product.SKU, err)
+			continue
+		}
+		fmt.Printf("product %d (%s): converted to %d variants\n", product.ID, product.SKU, len(variants))
+	}
+
+	return nil
+}
+
+// barcodeIndexes keep barcodes unique per tenant among products and among
+// variants, each replacing the plain index the table had on its barcodes.
+// Products and variants without a barcode stay out of them.
+var barcodeIndexes = []struct {
+	table    string
+	index    string
+	replaces string
+}{
+	{"products", "idx_products_tenant_barcode", "idx_products_barcode"},
+	{"product_variants", "idx_product_variants_tenant_barcode", "idx_product_variants_barcode"},
+}
+
+// uniqueBarcodes creates the unique barcode indexes. A table whose barcodes
+// are not unique yet keeps its plain index and has its duplicates reported,
+// so they can be fixed before migrating again.
+func uniqueBarcodes(db *gorm.DB) error {
+	for _, barcodeIndex := range barcodeIndexes {
+		var duplicates []struct {
+			TenantID uint64
+			Barcode  string
+			Count    int64
+		}
+		err := db.Table(barcodeIndex.table).
+			Select("tenant_id, barcode, COUNT(*) AS count").
+			Where("barcode <> ''").
+			Group("tenant_id, barcode").
+			Having("COUNT(*) > 1").
+			Order("tenant_id, barcode").
+			Scan(&duplicates).Error
+		if err != nil {
+			return fmt.Errorf("failed to find duplicate barcodes in %s: %w", barcodeIndex.table, err)
+		}
+		if len(duplicates) > 0 {
+			for _, duplicate := range duplicates {
+				fmt.Printf("tenant %d: barcode %s is used by %d %s\n",
+					duplicate.TenantID, duplicate.Barcode, duplicate.Count, barcodeIndex.table)
+			}
+			fmt.Printf("%s: barcodes not made unique until the duplicates are fixed\n", barcodeIndex.table)
+			continue
+		}
+
+		statement := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (tenant_id, barcode) WHERE barcode <> ''",
+			barcodeIndex.index, barcodeIndex.table)
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create %s: %w", barcodeIndex.index, err)
+		}
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", barcodeIndex.replaces)).Error; err != nil {
+			return fmt.Errorf("failed to drop %s: %w", barcodeIndex.replaces, err)
+		}
+	}
+
+	return nil
+}
+
+// variantOptions reads options saved as {"Size": ["S", "M"], ...}, ordered
+// by name. Entries that are not a list of values are skipped.
+func variantOptions(variants database.JSONVariants) []productsdomain.ProductOptionRequest {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var options []productsdomain.ProductOptionRequest
+	for _, name := range names {
+		list, ok := variants[name].([]interface{})
+		if !ok || len(list) == 0 {
+			continue
+		}
+
+		values := make([]string, 0, len(list))
+		for _, value := range list {
+			switch value.(type) {
+			case string, float64, bool:
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		if len(values) > 0 {
+			options = append(options, productsdomain.ProductOptionRequest{Name: name, Values: values})
+		}
+	}
+
+	return options
+}
+
+// runReconcile compares the stock levels of one tenant, or of every tenant,
+// with the sum of their stock movements and prints the drift, fixing it when
+// asked to. It returns the number of drifted stock levels.
+func runReconcile(db *gorm.DB, tenantID uint64, fix bool) (int, error) {
+	tenantIDs := []uint64{tenantID}
+	if tenantID == 0 {
+		if err := db.Model(&database.Tenant{}).Order("id").Pluck("id", &tenantIDs).Error; err != nil {
+			return 0, fmt.Errorf("failed to list tenants: %w", err)
+		}
+	}
+
+	inventoryService := inventory.NewModule(container.New(), db, nil).GetService()
+	ctx := context.Background()
+
+	drifted := 0
+	for _, id := range tenantIDs {
+		drifts, err := inventoryService.ReconcileStock(ctx, id, fix)
+		if err != nil {
+			return drifted, fmt.Errorf("tenant %d: %w", id, err)
+		}
+
+		for _, drift := range drifts {
+			name, sku := drift.ProductName, drift.SKU
+			if drift.VariantID != nil {
+				name, sku = drift.ProductName+" "+drift.VariantName, drift.VariantSKU
+			}
+			fmt.Printf("tenant %d: %s (%s) at %s: stock %g, ledger %g\n",
+				id, name, sku, drift.OutletName, drift.Quantity, drift.LedgerQuantity)
+		}
+		drifted += len(drifts)
+	}
+
+	switch {
+	case drifted == 0:
+		fmt.Println("Stock matches the ledger")
+	case fix:
+		fmt.Printf("Fixed %d stock levels\n", drifted)
+	default:
+		fmt.Printf("%d stock levels drifted from the ledger, run with -fix to correct them\n", drifted)
+	}
+
+	return drifted, nil
 }
 
 func runSeeds(db *gorm.DB) error {
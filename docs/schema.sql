@@ -40,6 +40,7 @@ CREATE TABLE tenants (
     currency VARCHAR(3) DEFAULT 'IDR',
     is_active BOOLEAN DEFAULT TRUE,
     trial_ends_at TIMESTAMP WITH TIME ZONE NULL,
+    settings JSONB, -- Pengaturan tenant (struk, pajak, pembulatan, format angka)
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -71,6 +72,27 @@ CREATE TABLE tenant_subscriptions (
 CREATE INDEX idx_tenant_subscriptions_tenant_status ON tenant_subscriptions(tenant_id, status);
 CREATE INDEX idx_tenant_subscriptions_ends_at ON tenant_subscriptions(ends_at);
 
+-- Tabel untuk metering penggunaan tenant per hari/bulan (zona waktu tenant)
+CREATE TABLE tenant_usages (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    period VARCHAR(10) NOT NULL, -- day, month
+    period_start DATE NOT NULL, -- hari pertama periode di zona waktu tenant
+    transactions BIGINT DEFAULT 0,
+    api_calls BIGINT DEFAULT 0,
+    active_users INTEGER DEFAULT 0,
+    outlets INTEGER DEFAULT 0,
+    products INTEGER DEFAULT 0,
+    storage_bytes BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, period, period_start)
+);
+
+CREATE INDEX idx_tenant_usages_period_start ON tenant_usages(period, period_start);
+
 
 
 -- =============================================
@@ -195,14 +217,15 @@ CREATE TABLE products (
     barcode VARCHAR(100),
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    unit VARCHAR(50) DEFAULT 'pcs', -- satuan (pcs, kg, liter, dll)
+    unit VARCHAR(50) DEFAULT 'pcs', -- satuan dasar stock (pcs, kg, liter, dll)
     cost_price DECIMAL(12,2) DEFAULT 0.00,
     selling_price DECIMAL(12,2) NOT NULL,
-    min_stock INTEGER DEFAULT 0,
+    min_stock DECIMAL(15,3) DEFAULT 0,
     track_stock BOOLEAN DEFAULT TRUE,
+    track_lots BOOLEAN DEFAULT FALSE, -- Stok disimpan per lot/batch dengan tanggal kedaluwarsa
     is_active BOOLEAN DEFAULT TRUE,
     images JSONB, -- Array URL gambar
-    variants JSONB, -- Array varian produk (size, color, dll)
+    variants JSONB, -- Opsi varian produk beserta nilainya, mis. {"Size": ["S", "M"], "Color": ["Red"]}
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
@@ -212,39 +235,317 @@ CREATE TABLE products (
 );
 
 CREATE INDEX idx_products_tenant_category ON products(tenant_id, category_id);
-CREATE INDEX idx_products_barcode ON products(barcode);
+CREATE UNIQUE INDEX idx_products_tenant_barcode ON products(tenant_id, barcode) WHERE barcode <> '';
 CREATE INDEX idx_products_name ON products(name);
 
+-- Pencarian produk: full-text dengan prefix dan trigram untuk salah ketik
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(sku, '') || ' ' || coalesce(barcode, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING gin (search_vector);
+CREATE INDEX idx_products_name_trgm ON products USING gin (name gin_trgm_ops);
+CREATE INDEX idx_products_sku_trgm ON products USING gin (sku gin_trgm_ops);
+CREATE INDEX idx_product_categories_name_trgm ON product_categories USING gin (name gin_trgm_ops);
+
+-- Tabel varian produk: satu baris per kombinasi nilai opsi, dengan SKU, barcode, harga dan stock sendiri
+-- SKU varian unik per tenant bersama SKU produk
+CREATE TABLE product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    sku VARCHAR(100) NOT NULL, -- Unik bersama SKU produk, dicek aplikasi di bawah advisory lock per tenant
+    barcode VARCHAR(100),
+    name VARCHAR(255) NOT NULL, -- Nilai opsi digabung, mis. "M / Red"
+    options JSONB NOT NULL, -- Nilai setiap opsi, mis. {"Size": "M", "Color": "Red"}
+    selling_price DECIMAL(12,2), -- NULL berarti memakai harga jual produk
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, sku)
+);
+
+CREATE INDEX idx_product_variants_product_id ON product_variants(product_id);
+CREATE UNIQUE INDEX idx_product_variants_tenant_barcode ON product_variants(tenant_id, barcode) WHERE barcode <> '';
+
+-- Tabel satuan per tenant (pcs, box, kg, dll)
+-- allow_decimal menentukan apakah jumlah dalam satuan ini boleh pecahan
+CREATE TABLE units (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    code VARCHAR(50) NOT NULL, -- Sama dengan products.unit untuk satuan dasar
+    name VARCHAR(100) NOT NULL,
+    allow_decimal BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_units_tenant_code ON units(tenant_id, code);
+
+-- Tabel konversi satuan produk: satu baris per satuan selain satuan dasar produk
+-- factor = jumlah satuan dasar dalam satu satuan ini, mis. 1 box = 12 pcs
+-- Stock selalu disimpan dalam satuan dasar
+CREATE TABLE product_units (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    unit_id BIGINT NOT NULL,
+    factor DECIMAL(15,6) NOT NULL,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (unit_id) REFERENCES units(id)
+);
+
+CREATE UNIQUE INDEX idx_product_units_product_unit ON product_units(product_id, unit_id);
+CREATE INDEX idx_product_units_unit ON product_units(unit_id);
 
 
 -- Tabel stock per outlet
 CREATE TABLE product_stocks (
     id BIGSERIAL PRIMARY KEY,
     product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- NULL untuk stock produk itu sendiri
     outlet_id BIGINT NOT NULL,
-    quantity INTEGER NOT NULL DEFAULT 0,
-    reserved_quantity INTEGER DEFAULT 0, -- Stock yang di-reserve untuk order
+    quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+    reserved_quantity DECIMAL(15,3) DEFAULT 0, -- Stock yang di-reserve untuk order
+    average_cost DECIMAL(12,2) NOT NULL DEFAULT 0, -- Harga pokok rata-rata tertimbang stock yang ada
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
     FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
-    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
-    UNIQUE (product_id, outlet_id)
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE
 );
 
+CREATE UNIQUE INDEX idx_product_stocks_product_outlet_base ON product_stocks(product_id, outlet_id) WHERE variant_id IS NULL;
+CREATE UNIQUE INDEX idx_product_stocks_variant_outlet ON product_stocks(variant_id, outlet_id) WHERE variant_id IS NOT NULL;
 CREATE INDEX idx_product_stocks_outlet_quantity ON product_stocks(outlet_id, quantity);
 
+-- Tabel resep/bill of materials: komponen yang dipakai setiap kali produk terjual
+-- quantity dalam satuan komponen per 1 unit produk; komponen tidak boleh punya resep sendiri
+CREATE TABLE product_recipe_items (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    component_id BIGINT NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (component_id) REFERENCES products(id) ON DELETE NO ACTION, -- dicek di akhir statement agar hapus tenant tetap bisa cascade
+    UNIQUE (product_id, component_id)
+);
+
+CREATE INDEX idx_product_recipe_items_component ON product_recipe_items(component_id);
+
+-- Tabel paket/bundle: produk paket (mis. "Paket Hemat") dijual dengan harga paket
+-- dan mengurangi stok komponennya, bukan stok produk paket itu sendiri
+-- Grup pilihan: pembeli memilih pick_count item dari grup (mis. pilih 1 minuman dari 4)
+CREATE TABLE product_bundle_groups (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    pick_count INTEGER NOT NULL DEFAULT 1,
+    sort_order INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_bundle_groups_product_id ON product_bundle_groups(product_id);
+
+-- Komponen paket: group_id NULL berarti selalu termasuk, selain itu pilihan dari grup
+-- quantity dalam satuan komponen per 1 paket; komponen tidak boleh berupa paket
+CREATE TABLE product_bundle_items (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    group_id BIGINT,
+    component_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity DECIMAL(15,3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (group_id) REFERENCES product_bundle_groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (component_id) REFERENCES products(id) ON DELETE NO ACTION, -- dicek di akhir statement agar hapus tenant tetap bisa cascade
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE NO ACTION
+);
+
+CREATE INDEX idx_product_bundle_items_product_id ON product_bundle_items(product_id);
+CREATE INDEX idx_product_bundle_items_group_id ON product_bundle_items(group_id);
+CREATE INDEX idx_product_bundle_items_component_id ON product_bundle_items(component_id);
+CREATE INDEX idx_product_bundle_items_variant_id ON product_bundle_items(variant_id);
+
+-- Grup modifier untuk F&B (mis. "Level Gula", "Topping"), dipasang ke produk atau kategori
+-- Penjualan memilih min_select sampai max_select modifier; min_select > 0 berarti wajib
+CREATE TABLE modifier_groups (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    min_select INTEGER NOT NULL DEFAULT 0,
+    max_select INTEGER NOT NULL DEFAULT 1,
+    sort_order INTEGER DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_modifier_groups_tenant_id ON modifier_groups(tenant_id);
+
+-- Pilihan modifier: price_delta ditambahkan ke harga per unit (boleh negatif)
+-- product_id opsional: stok produk tersebut berkurang quantity per unit terjual
+CREATE TABLE modifiers (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    product_id BIGINT,
+    quantity DECIMAL(15,3) NOT NULL DEFAULT 1,
+    sort_order INTEGER DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE NO ACTION -- dicek di akhir statement agar hapus tenant tetap bisa cascade
+);
+
+CREATE INDEX idx_modifiers_group_id ON modifiers(group_id);
+CREATE INDEX idx_modifiers_product_id ON modifiers(product_id);
+
+-- Grup modifier per produk
+CREATE TABLE product_modifier_groups (
+    product_id BIGINT NOT NULL,
+    modifier_group_id BIGINT NOT NULL,
+    sort_order INTEGER DEFAULT 0,
+
+    PRIMARY KEY (product_id, modifier_group_id),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (modifier_group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_modifier_groups_modifier_group_id ON product_modifier_groups(modifier_group_id);
+
+-- Grup modifier per kategori, berlaku untuk semua produk langsung di kategori tersebut
+CREATE TABLE category_modifier_groups (
+    category_id BIGINT NOT NULL,
+    modifier_group_id BIGINT NOT NULL,
+    sort_order INTEGER DEFAULT 0,
+
+    PRIMARY KEY (category_id, modifier_group_id),
+    FOREIGN KEY (category_id) REFERENCES product_categories(id) ON DELETE CASCADE,
+    FOREIGN KEY (modifier_group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_category_modifier_groups_modifier_group_id ON category_modifier_groups(modifier_group_id);
+
+-- Riwayat perubahan harga pokok dan harga jual produk
+-- source: product_update (ubah produk), bulk_update (ubah harga massal per kategori),
+-- goods_receipt (harga pokok dari penerimaan barang) atau import (impor produk)
+CREATE TABLE product_price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    old_cost_price DECIMAL(12,2) NOT NULL,
+    new_cost_price DECIMAL(12,2) NOT NULL,
+    old_selling_price DECIMAL(12,2) NOT NULL,
+    new_selling_price DECIMAL(12,2) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reason TEXT,
+    changed_by BIGINT NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL, -- Waktu harga baru mulai berlaku
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_product_price_changes_tenant_id ON product_price_changes(tenant_id);
+CREATE INDEX idx_product_price_changes_product_effective ON product_price_changes(product_id, effective_at);
+
+-- Tabel gambar produk dan kategori yang diunggah; file disimpan di file storage (lokal atau S3)
+CREATE TABLE images (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT, -- Diisi untuk gambar produk
+    category_id BIGINT, -- Diisi untuk gambar kategori
+    storage_key VARCHAR(500) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    thumbnails JSONB, -- Nama ukuran thumbnail ke storage key, contoh: {"small": "...", "medium": "..."}
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES product_categories(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_images_tenant_id ON images(tenant_id);
+CREATE INDEX idx_images_product_id ON images(product_id);
+CREATE INDEX idx_images_category_id ON images(category_id);
+
+-- Tabel lot/batch stok untuk produk dengan track_lots (barang mudah kedaluwarsa)
+-- Jumlah semua lot tidak pernah melebihi product_stocks.quantity; sisa stok tanpa lot dipakai paling akhir
+CREATE TABLE stock_lots (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+    lot_number VARCHAR(100) NOT NULL,
+    expiry_date DATE, -- NULL jika lot tidak kedaluwarsa
+    quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    UNIQUE (product_id, outlet_id, lot_number)
+);
+
+CREATE INDEX idx_stock_lots_tenant_expiry ON stock_lots(tenant_id, expiry_date);
+
 
 
 -- =============================================
 -- CUSTOMER MANAGEMENT
 -- =============================================
 
+-- Tabel grup pelanggan (mis. grosir, member) untuk harga khusus
+CREATE TABLE customer_groups (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, name)
+);
+
 -- Tabel pelanggan
 CREATE TYPE gender_type AS ENUM ('male', 'female');
 
 CREATE TABLE customers (
     id BIGSERIAL PRIMARY KEY,
     tenant_id BIGINT NOT NULL,
+    customer_group_id BIGINT, -- NULL berarti tanpa grup
     code VARCHAR(50), -- Kode pelanggan
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255),
@@ -265,11 +566,81 @@ CREATE TABLE customers (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
     FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_group_id) REFERENCES customer_groups(id) ON DELETE SET NULL,
     UNIQUE (tenant_id, code)
 );
 
 CREATE INDEX idx_customers_tenant_phone ON customers(tenant_id, phone);
 CREATE INDEX idx_customers_tenant_email ON customers(tenant_id, email);
+CREATE INDEX idx_customers_customer_group_id ON customers(customer_group_id);
+
+-- =============================================
+-- PRICING
+-- =============================================
+
+-- Tabel daftar harga: menggantikan harga jual selama masa berlaku
+-- Tanpa outlet berlaku di semua outlet, tanpa grup pelanggan berlaku untuk semua pelanggan
+-- Jika beberapa daftar harga berlaku, priority tertinggi yang dipakai
+CREATE TABLE price_lists (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE, -- NULL berarti berlaku sejak awal
+    ends_at TIMESTAMP WITH TIME ZONE, -- Eksklusif; NULL berarti berlaku seterusnya
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_price_lists_tenant_id ON price_lists(tenant_id);
+
+-- Outlet tempat daftar harga berlaku
+CREATE TABLE price_list_outlets (
+    price_list_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+
+    PRIMARY KEY (price_list_id, outlet_id),
+    FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_price_list_outlets_outlet_id ON price_list_outlets(outlet_id);
+
+-- Grup pelanggan yang mendapat daftar harga
+CREATE TABLE price_list_customer_groups (
+    price_list_id BIGINT NOT NULL,
+    customer_group_id BIGINT NOT NULL,
+
+    PRIMARY KEY (price_list_id, customer_group_id),
+    FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_group_id) REFERENCES customer_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_price_list_customer_groups_customer_group_id ON price_list_customer_groups(customer_group_id);
+
+-- Harga produk atau varian dalam daftar harga
+-- Harga produk juga berlaku untuk variannya yang tidak punya harga sendiri
+CREATE TABLE price_list_items (
+    id BIGSERIAL PRIMARY KEY,
+    price_list_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- NULL untuk harga produk itu sendiri
+    price DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_price_list_items_product ON price_list_items(price_list_id, product_id) WHERE variant_id IS NULL;
+CREATE UNIQUE INDEX idx_price_list_items_variant ON price_list_items(price_list_id, variant_id) WHERE variant_id IS NOT NULL;
+CREATE INDEX idx_price_list_items_product_id ON price_list_items(product_id);
 
 
 
@@ -331,28 +702,80 @@ CREATE TABLE transaction_items (
     id BIGSERIAL PRIMARY KEY,
     transaction_id BIGINT NOT NULL,
     product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- Varian yang terjual, jika ada
     -- Snapshot data produk saat transaksi (denormalisasi)
     product_name_snapshot VARCHAR(255) NOT NULL,
     product_sku_snapshot VARCHAR(100) NOT NULL,
     product_category_snapshot VARCHAR(255),
-    product_unit_snapshot VARCHAR(50) DEFAULT 'pcs',
+    product_unit_snapshot VARCHAR(50) DEFAULT 'pcs', -- Satuan jual
     -- Data transaksi
-    quantity INTEGER NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL, -- Dalam satuan jual
+    unit_factor DECIMAL(15,6) NOT NULL DEFAULT 1, -- Jumlah satuan dasar per satuan jual
     unit_price DECIMAL(12,2) NOT NULL,
     cost_price_snapshot DECIMAL(12,2) DEFAULT 0.00, -- Untuk profit calculation
     discount_amount DECIMAL(12,2) DEFAULT 0.00,
     total_price DECIMAL(15,2) NOT NULL,
     notes TEXT,
+    -- Lot yang terjual ada di stock_movement_lots lewat movement 'sale' transaksi ini,
+    -- karena satu item bisa diambil dari beberapa lot
     
     FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
-    FOREIGN KEY (product_id) REFERENCES products(id)
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE SET NULL
 );
 
 CREATE INDEX idx_transaction_items_transaction ON transaction_items(transaction_id);
 CREATE INDEX idx_transaction_items_product ON transaction_items(product_id);
+CREATE INDEX idx_transaction_items_variant ON transaction_items(variant_id);
 CREATE INDEX idx_transaction_items_product_sku_snapshot ON transaction_items(product_sku_snapshot);
 CREATE INDEX idx_transaction_items_product_name_snapshot ON transaction_items(product_name_snapshot);
 
+-- Komponen paket yang terjual per item transaksi, untuk laporan penjualan komponen
+-- allocated_amount: bagian harga paket, dibagi sesuai harga jual masing-masing komponen
+CREATE TABLE transaction_item_components (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_item_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity DECIMAL(15,3) NOT NULL,
+    allocated_amount DECIMAL(15,2) NOT NULL,
+    cost_amount DECIMAL(15,2) DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (transaction_item_id) REFERENCES transaction_items(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_transaction_item_components_transaction_item_id ON transaction_item_components(transaction_item_id);
+CREATE INDEX idx_transaction_item_components_product_id ON transaction_item_components(product_id);
+CREATE INDEX idx_transaction_item_components_variant_id ON transaction_item_components(variant_id);
+
+-- Modifier yang dipilih per item transaksi, untuk struk, tiket dapur dan laporan
+-- Nama grup, nama dan price_delta disalin saat transaksi; quantity = jumlah unit item
+-- product_quantity: stok produk modifier yang berkurang
+CREATE TABLE transaction_item_modifiers (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_item_id BIGINT NOT NULL,
+    modifier_id BIGINT,
+    group_name_snapshot VARCHAR(100) NOT NULL,
+    name_snapshot VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    quantity DECIMAL(15,3) NOT NULL,
+    product_id BIGINT,
+    product_quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+    cost_amount DECIMAL(15,2) DEFAULT 0.00,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (transaction_item_id) REFERENCES transaction_items(id) ON DELETE CASCADE,
+    FOREIGN KEY (modifier_id) REFERENCES modifiers(id) ON DELETE SET NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_transaction_item_modifiers_transaction_item_id ON transaction_item_modifiers(transaction_item_id);
+CREATE INDEX idx_transaction_item_modifiers_modifier_id ON transaction_item_modifiers(modifier_id);
+CREATE INDEX idx_transaction_item_modifiers_product_id ON transaction_item_modifiers(product_id);
+
 -- Tabel pembayaran (untuk multiple payment method)
 CREATE TYPE payment_method_single AS ENUM ('cash', 'card', 'transfer', 'ewallet');
 
@@ -376,29 +799,372 @@ CREATE INDEX idx_transaction_payments_transaction ON transaction_payments(transa
 
 -- Types untuk stock movement
 CREATE TYPE movement_type AS ENUM ('in', 'out', 'adjustment', 'transfer');
-CREATE TYPE reference_type AS ENUM ('sale', 'purchase', 'adjustment', 'transfer', 'initial');
+CREATE TYPE reference_type AS ENUM ('sale', 'purchase', 'adjustment', 'transfer', 'initial', 'stocktake');
 
 CREATE TABLE stock_movements (
     id BIGSERIAL PRIMARY KEY,
     product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- Diisi untuk movement stock varian
     outlet_id BIGINT NOT NULL,
     movement_type movement_type NOT NULL,
-    quantity INTEGER NOT NULL, -- Bisa negatif untuk stock out
+    quantity DECIMAL(15,3) NOT NULL, -- Bisa negatif untuk stock out
     reference_type reference_type NOT NULL,
     reference_id BIGINT, -- ID dari transaksi terkait
     notes TEXT,
+    unit_cost DECIMAL(12,2), -- NULL untuk movement sebelum ada costing
+    total_cost DECIMAL(15,2), -- Bertanda sama dengan quantity
     created_by BIGINT NOT NULL,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
     FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
     FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
     FOREIGN KEY (created_by) REFERENCES users(id)
 );
 
 CREATE INDEX idx_stock_movements_product_outlet ON stock_movements(product_id, outlet_id);
+CREATE INDEX idx_stock_movements_variant_id ON stock_movements(variant_id);
 CREATE INDEX idx_stock_movements_outlet_date ON stock_movements(outlet_id, created_at);
 CREATE INDEX idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
 
+-- Tabel untuk penyesuaian stok manual (rusak, hilang, kedaluwarsa, koreksi hitung)
+CREATE TABLE stock_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+    adjustment_number VARCHAR(50) NOT NULL,
+    status VARCHAR(30) NOT NULL, -- pending_approval, applied, rejected
+    total_value DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Nilai absolut penyesuaian berdasarkan harga pokok
+    notes TEXT,
+    created_by BIGINT NOT NULL,
+    reviewed_by BIGINT, -- User yang menyetujui atau menolak
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    rejection_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (reviewed_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX idx_stock_adjustments_tenant_number ON stock_adjustments(tenant_id, adjustment_number);
+CREATE INDEX idx_stock_adjustments_tenant_status ON stock_adjustments(tenant_id, status);
+
+CREATE TABLE stock_adjustment_items (
+    id BIGSERIAL PRIMARY KEY,
+    adjustment_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL, -- Negatif untuk pengurangan stok
+    reason VARCHAR(30) NOT NULL, -- breakage, theft, expiry, count_correction, found, other
+    unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Snapshot harga pokok saat penyesuaian dibuat
+    notes TEXT,
+
+    FOREIGN KEY (adjustment_id) REFERENCES stock_adjustments(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_adjustment_items_adjustment ON stock_adjustment_items(adjustment_id);
+
+-- Tabel untuk transfer stok antar outlet (draft -> dispatched -> received)
+CREATE TABLE stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    transfer_number VARCHAR(50) NOT NULL,
+    source_outlet_id BIGINT NOT NULL,
+    destination_outlet_id BIGINT NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft', -- draft, dispatched, partially_received, received, cancelled
+    notes TEXT,
+    created_by BIGINT NOT NULL,
+    dispatched_by BIGINT,
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    received_by BIGINT,
+    received_at TIMESTAMP WITH TIME ZONE, -- Waktu penerimaan selesai
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (destination_outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (dispatched_by) REFERENCES users(id),
+    FOREIGN KEY (received_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX idx_stock_transfers_tenant_number ON stock_transfers(tenant_id, transfer_number);
+CREATE INDEX idx_stock_transfers_tenant_status ON stock_transfers(tenant_id, status);
+CREATE INDEX idx_stock_transfers_source_outlet_id ON stock_transfers(source_outlet_id);
+CREATE INDEX idx_stock_transfers_destination_outlet_id ON stock_transfers(destination_outlet_id);
+
+CREATE TABLE stock_transfer_items (
+    id BIGSERIAL PRIMARY KEY,
+    transfer_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    unit VARCHAR(50) NOT NULL DEFAULT '', -- Satuan pengiriman
+    unit_factor DECIMAL(15,6) NOT NULL DEFAULT 1, -- Jumlah satuan dasar per satuan pengiriman
+    quantity DECIMAL(15,3) NOT NULL, -- Jumlah yang dikirim, dalam satuan pengiriman
+    received_quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+    discrepancy_reason TEXT, -- Alasan selisih antara jumlah dikirim dan diterima
+
+    FOREIGN KEY (transfer_id) REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_transfer_items_transfer ON stock_transfer_items(transfer_id);
+
+-- Tabel untuk stock opname per outlet (counting -> finalized)
+CREATE TABLE stocktakes (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+    stocktake_number VARCHAR(50) NOT NULL,
+    category_id BIGINT, -- Batasi penghitungan ke satu kategori
+    status VARCHAR(30) NOT NULL DEFAULT 'counting', -- counting, finalized, cancelled
+    notes TEXT,
+    created_by BIGINT NOT NULL,
+    finalized_by BIGINT,
+    finalized_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES product_categories(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (finalized_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX idx_stocktakes_tenant_number ON stocktakes(tenant_id, stocktake_number);
+CREATE INDEX idx_stocktakes_tenant_status ON stocktakes(tenant_id, status);
+CREATE INDEX idx_stocktakes_outlet_id ON stocktakes(outlet_id);
+
+CREATE TABLE stocktake_items (
+    id BIGSERIAL PRIMARY KEY,
+    stocktake_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    expected_quantity DECIMAL(15,3) NOT NULL, -- Snapshot stok saat stock opname dimulai
+    counted_quantity DECIMAL(15,3), -- NULL jika produk belum dihitung
+    unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Snapshot harga pokok saat stock opname dimulai
+    counted_at TIMESTAMP WITH TIME ZONE, -- Waktu hitungan terakhir
+
+    FOREIGN KEY (stocktake_id) REFERENCES stocktakes(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_stocktake_items_stocktake_product ON stocktake_items(stocktake_id, product_id);
+
+-- Riwayat hitungan per perangkat; counted_quantity adalah jumlah semua hitungan
+CREATE TABLE stocktake_counts (
+    id BIGSERIAL PRIMARY KEY,
+    stocktake_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL, -- Negatif untuk mengoreksi hitungan sebelumnya
+    device_id VARCHAR(100),
+    counted_by BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (stocktake_id) REFERENCES stocktakes(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (counted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_stocktake_counts_stocktake_id ON stocktake_counts(stocktake_id);
+
+-- Peringatan stok menipis saat pergerakan stok menurunkan stok ke min_stock atau di bawahnya
+CREATE TABLE stock_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL, -- Sisa stok setelah pergerakan
+    min_stock DECIMAL(15,3) NOT NULL, -- Snapshot min_stock produk
+    status VARCHAR(30) NOT NULL DEFAULT 'open', -- open, acknowledged
+    stock_movement_id BIGINT, -- Pergerakan stok yang memicu peringatan
+    reference_type VARCHAR(30) NOT NULL,
+    reference_id BIGINT,
+    acknowledged_by BIGINT,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (stock_movement_id) REFERENCES stock_movements(id) ON DELETE SET NULL,
+    FOREIGN KEY (acknowledged_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_stock_alerts_tenant_status ON stock_alerts(tenant_id, status);
+CREATE INDEX idx_stock_alerts_product_outlet ON stock_alerts(product_id, outlet_id);
+CREATE INDEX idx_stock_alerts_reference ON stock_alerts(reference_type, reference_id);
+
+-- Bagian pergerakan stok yang diambil dari atau dimasukkan ke satu lot
+-- Jalur recall: lot -> stock_movement_lots -> stock_movements (reference_type 'sale', reference_id = transaksi)
+CREATE TABLE stock_movement_lots (
+    id BIGSERIAL PRIMARY KEY,
+    stock_movement_id BIGINT NOT NULL,
+    lot_id BIGINT NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL, -- Negatif jika diambil dari lot
+
+    FOREIGN KEY (stock_movement_id) REFERENCES stock_movements(id) ON DELETE CASCADE,
+    FOREIGN KEY (lot_id) REFERENCES stock_lots(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_movement_lots_stock_movement_id ON stock_movement_lots(stock_movement_id);
+CREATE INDEX idx_stock_movement_lots_lot_id ON stock_movement_lots(lot_id);
+
+-- Cost layer: stock yang masuk ke outlet dengan satu harga pokok, dipakai urut dari yang terlama (FIFO)
+CREATE TABLE stock_cost_layers (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- Layer stock varian, dipakai terpisah dari layer produk
+    outlet_id BIGINT NOT NULL,
+    stock_movement_id BIGINT NOT NULL, -- Movement yang membuat layer
+    unit_cost DECIMAL(12,2) NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL,
+    remaining_quantity DECIMAL(15,3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (stock_movement_id) REFERENCES stock_movements(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_cost_layers_tenant_id ON stock_cost_layers(tenant_id);
+CREATE INDEX idx_stock_cost_layers_product_outlet ON stock_cost_layers(product_id, outlet_id);
+CREATE INDEX idx_stock_cost_layers_variant_id ON stock_cost_layers(variant_id);
+CREATE INDEX idx_stock_cost_layers_stock_movement_id ON stock_cost_layers(stock_movement_id);
+
+-- =============================================
+-- SUPPLIERS & PURCHASING
+-- =============================================
+
+CREATE TABLE suppliers (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    contact_person VARCHAR(255),
+    email VARCHAR(255),
+    phone VARCHAR(20),
+    address TEXT,
+    city VARCHAR(100),
+    tax_number VARCHAR(50), -- NPWP supplier
+    payment_term_days INTEGER DEFAULT 0, -- Jangka waktu pembayaran (hari)
+    notes TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_suppliers_tenant_code ON suppliers(tenant_id, code);
+CREATE INDEX idx_suppliers_tenant_name ON suppliers(tenant_id, name);
+
+-- Tabel purchase order ke supplier
+CREATE TABLE purchase_orders (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    order_number VARCHAR(50) NOT NULL,
+    supplier_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL, -- Outlet tujuan pengiriman
+    status VARCHAR(30) NOT NULL DEFAULT 'draft', -- draft, ordered, partially_received, received, cancelled
+    expected_date DATE,
+    total_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Total berdasarkan harga beli yang diharapkan
+    notes TEXT,
+    created_by BIGINT NOT NULL,
+    ordered_by BIGINT,
+    ordered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (supplier_id) REFERENCES suppliers(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (ordered_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX idx_purchase_orders_tenant_number ON purchase_orders(tenant_id, order_number);
+CREATE INDEX idx_purchase_orders_tenant_status ON purchase_orders(tenant_id, status);
+CREATE INDEX idx_purchase_orders_supplier_id ON purchase_orders(supplier_id);
+
+CREATE TABLE purchase_order_items (
+    id BIGSERIAL PRIMARY KEY,
+    purchase_order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- Varian yang dipesan, jika ada
+    unit VARCHAR(50) NOT NULL DEFAULT '', -- Satuan pembelian
+    unit_factor DECIMAL(15,6) NOT NULL DEFAULT 1, -- Jumlah satuan dasar per satuan pembelian
+    quantity DECIMAL(15,3) NOT NULL,
+    unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Harga beli yang diharapkan per satuan pembelian
+    received_quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_purchase_order_items_purchase_order_id ON purchase_order_items(purchase_order_id);
+CREATE INDEX idx_purchase_order_items_variant_id ON purchase_order_items(variant_id);
+
+-- Tabel penerimaan barang (goods received note)
+CREATE TABLE goods_receipts (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    receipt_number VARCHAR(50) NOT NULL,
+    purchase_order_id BIGINT, -- NULL untuk penerimaan tanpa PO
+    supplier_id BIGINT NOT NULL,
+    outlet_id BIGINT NOT NULL,
+    supplier_invoice VARCHAR(100), -- Nomor faktur/surat jalan supplier
+    cost_price_policy VARCHAR(30) NOT NULL, -- last_cost, weighted_average
+    total_cost DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    notes TEXT,
+    received_by BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id) ON DELETE SET NULL,
+    FOREIGN KEY (supplier_id) REFERENCES suppliers(id) ON DELETE CASCADE,
+    FOREIGN KEY (outlet_id) REFERENCES outlets(id) ON DELETE CASCADE,
+    FOREIGN KEY (received_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX idx_goods_receipts_tenant_number ON goods_receipts(tenant_id, receipt_number);
+CREATE INDEX idx_goods_receipts_tenant_date ON goods_receipts(tenant_id, created_at);
+CREATE INDEX idx_goods_receipts_purchase_order_id ON goods_receipts(purchase_order_id);
+CREATE INDEX idx_goods_receipts_supplier_id ON goods_receipts(supplier_id);
+
+CREATE TABLE goods_receipt_items (
+    id BIGSERIAL PRIMARY KEY,
+    goods_receipt_id BIGINT NOT NULL,
+    purchase_order_item_id BIGINT,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT, -- Varian yang diterima, jika ada
+    unit VARCHAR(50) NOT NULL DEFAULT '', -- Satuan penerimaan
+    unit_factor DECIMAL(15,6) NOT NULL DEFAULT 1, -- Jumlah satuan dasar per satuan penerimaan
+    quantity DECIMAL(15,3) NOT NULL,
+    unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Harga beli aktual per satuan penerimaan
+    previous_cost_price DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Harga pokok produk sebelum penerimaan
+    new_cost_price DECIMAL(15,2) NOT NULL DEFAULT 0.00, -- Harga pokok produk setelah penerimaan
+    lot_id BIGINT, -- Lot tempat barang diterima
+
+    FOREIGN KEY (goods_receipt_id) REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    FOREIGN KEY (purchase_order_item_id) REFERENCES purchase_order_items(id) ON DELETE SET NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+    FOREIGN KEY (lot_id) REFERENCES stock_lots(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_goods_receipt_items_goods_receipt_id ON goods_receipt_items(goods_receipt_id);
+CREATE INDEX idx_goods_receipt_items_purchase_order_item_id ON goods_receipt_items(purchase_order_item_id);
+CREATE INDEX idx_goods_receipt_items_variant_id ON goods_receipt_items(variant_id);
+CREATE INDEX idx_goods_receipt_items_lot_id ON goods_receipt_items(lot_id);
+
 -- =============================================
 -- BACKUP TABLES FOR DATA RETENTION
 -- =============================================
@@ -446,12 +1212,14 @@ CREATE TABLE archived_transaction_items (
     id BIGINT PRIMARY KEY,
     transaction_id BIGINT NOT NULL,
     product_id BIGINT NOT NULL,
+    variant_id BIGINT,
     -- Snapshot data yang sudah tersimpan dari tabel asli
     product_name_snapshot VARCHAR(255) NOT NULL,
     product_sku_snapshot VARCHAR(100) NOT NULL,
     product_category_snapshot VARCHAR(255),
     product_unit_snapshot VARCHAR(50) DEFAULT 'pcs',
-    quantity INTEGER NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL,
+    unit_factor DECIMAL(15,6) NOT NULL DEFAULT 1,
     unit_price DECIMAL(12,2) NOT NULL,
     cost_price_snapshot DECIMAL(12,2) DEFAULT 0.00,
     discount_amount DECIMAL(12,2) DEFAULT 0.00,
@@ -463,6 +1231,7 @@ CREATE TABLE archived_transaction_items (
 CREATE INDEX idx_archived_transaction_items_transaction ON archived_transaction_items(transaction_id);
 CREATE INDEX idx_archived_transaction_items_product_sku_snapshot ON archived_transaction_items(product_sku_snapshot);
 CREATE INDEX idx_archived_transaction_items_archived_date ON archived_transaction_items(archived_at);
+CREATE INDEX idx_archived_transaction_items_variant ON archived_transaction_items(variant_id);
 
 -- Tabel backup pembayaran
 CREATE TABLE archived_transaction_payments (
@@ -480,7 +1249,7 @@ CREATE INDEX idx_archived_transaction_payments_transaction ON archived_transacti
 CREATE INDEX idx_archived_transaction_payments_archived_date ON archived_transaction_payments(archived_at);
 
 -- Types untuk data retention
-CREATE TYPE retention_type AS ENUM ('transaction_archive', 'transaction_delete', 'audit_cleanup');
+CREATE TYPE retention_type AS ENUM ('transaction_archive', 'transaction_delete', 'audit_cleanup', 'tenant_delete');
 CREATE TYPE retention_status AS ENUM ('success', 'failed', 'partial');
 
 -- Tabel untuk tracking data retention policy
@@ -494,13 +1263,64 @@ CREATE TABLE data_retention_logs (
     execution_time DECIMAL(8,3), -- dalam detik
     status retention_status DEFAULT 'success',
     error_message TEXT,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    
-    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    -- Tanpa foreign key ke tenants: log penghapusan tenant harus tetap ada setelah tenant dihapus
 );
 
 CREATE INDEX idx_data_retention_logs_tenant_type_date ON data_retention_logs(tenant_id, retention_type, created_at);
 
+-- Tabel untuk export data tenant (takeout)
+CREATE TABLE tenant_exports (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    requested_by BIGINT NOT NULL,
+    format VARCHAR(10) NOT NULL, -- csv, json
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, completed, failed
+    file_path VARCHAR(500),
+    file_size BIGINT DEFAULT 0,
+    record_counts JSONB,
+    error_message TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (requested_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tenant_exports_tenant_date ON tenant_exports(tenant_id, created_at);
+
+-- Tabel untuk import data dari spreadsheet (CSV/XLSX)
+CREATE TABLE import_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    created_by BIGINT NOT NULL,
+    import_type VARCHAR(30) NOT NULL, -- products, categories, customers, opening_stock
+    file_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    file_format VARCHAR(10) NOT NULL, -- csv, xlsx
+    status VARCHAR(30) NOT NULL DEFAULT 'uploaded',
+    headers JSONB,
+    mapping JSONB, -- field import -> header kolom
+    total_rows INTEGER DEFAULT 0,
+    processed_rows INTEGER DEFAULT 0,
+    success_rows INTEGER DEFAULT 0,
+    failed_rows INTEGER DEFAULT 0,
+    rollback_on_failure BOOLEAN DEFAULT false,
+    row_errors JSONB,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_import_jobs_tenant_date ON import_jobs(tenant_id, created_at);
+
 -- =============================================
 -- SYSTEM & AUDIT
 -- =============================================
@@ -510,6 +1330,7 @@ CREATE TABLE audit_logs (
     id BIGSERIAL PRIMARY KEY,
     tenant_id BIGINT NOT NULL,
     user_id BIGINT,
+    actor_id BIGINT, -- User asli (super admin) saat impersonation
     action VARCHAR(100) NOT NULL,
     table_name VARCHAR(100),
     record_id BIGINT,
@@ -520,13 +1341,36 @@ CREATE TABLE audit_logs (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
     FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
 );
 
 CREATE INDEX idx_audit_logs_tenant_date ON audit_logs(tenant_id, created_at);
 CREATE INDEX idx_audit_logs_user_date ON audit_logs(user_id, created_at);
+CREATE INDEX idx_audit_logs_actor_date ON audit_logs(actor_id, created_at);
 CREATE INDEX idx_audit_logs_table_record ON audit_logs(table_name, record_id);
 
+-- Tabel sesi impersonation oleh tim support (super admin)
+CREATE TABLE impersonation_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL, -- Tenant yang di-impersonate
+    actor_id BIGINT NOT NULL, -- Super admin yang melakukan impersonation
+    user_id BIGINT NOT NULL, -- User yang di-impersonate
+    reason TEXT NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_impersonation_sessions_tenant_date ON impersonation_sessions(tenant_id, created_at);
+CREATE INDEX idx_impersonation_sessions_actor ON impersonation_sessions(actor_id);
+
 -- =============================================
 -- INDEXES TAMBAHAN UNTUK PERFORMA
 -- =============================================
@@ -19,6 +19,9 @@ type Config struct {
 	RateLimit  RateLimitConfig
 	Log        LogConfig
 	FileUpload FileUploadConfig
+	Storage    StorageConfig
+	DataExport DataExportConfig
+	Usage      UsageConfig
 	Worker     WorkerConfig
 }
 
@@ -55,9 +58,10 @@ type RabbitMQConfig struct {
 }
 
 type JWTConfig struct {
-	Secret            string
-	ExpiryHours       int
-	RefreshExpiryDays int
+	Secret                     string
+	ExpiryHours                int
+	RefreshExpiryDays          int
+	ImpersonationExpiryMinutes int
 }
 
 type CORSConfig struct {
@@ -77,7 +81,30 @@ type LogConfig struct {
 }
 
 type FileUploadConfig struct {
-	MaxSize int64
+	MaxSize      int64
+	MaxImageSize int64
+}
+
+type StorageConfig struct {
+	LocalPath string
+	// Driver selects where uploaded images are kept: "local" under
+	// LocalPath/uploads, or "s3" in an S3 compatible bucket
+	Driver         string
+	PublicURL      string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+type DataExportConfig struct {
+	ExpiryHours int
+}
+
+type UsageConfig struct {
+	RollupInterval time.Duration
 }
 
 type WorkerConfig struct {
@@ -113,6 +140,7 @@ func Load() (*Config, error) {
 
 	viper.SetDefault("JWT_EXPIRY_HOURS", 24)
 	viper.SetDefault("JWT_REFRESH_EXPIRY_DAYS", 7)
+	viper.SetDefault("JWT_IMPERSONATION_EXPIRY_MINUTES", 30)
 
 	viper.SetDefault("RATE_LIMIT_REQUESTS_PER_MINUTE", 60)
 	viper.SetDefault("RATE_LIMIT_BURST", 10)
@@ -121,11 +149,22 @@ func Load() (*Config, error) {
 	viper.SetDefault("LOG_FORMAT", "json")
 
 	viper.SetDefault("MAX_UPLOAD_SIZE", 10485760)
+	viper.SetDefault("MAX_IMAGE_UPLOAD_SIZE", 5242880)
+
+	viper.SetDefault("STORAGE_LOCAL_PATH", "./storage")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_PUBLIC_URL", "http://localhost:8080/uploads")
+	viper.SetDefault("STORAGE_S3_REGION", "us-east-1")
+
+	viper.SetDefault("DATA_EXPORT_EXPIRY_HOURS", 168)
+
+	viper.SetDefault("USAGE_ROLLUP_INTERVAL", "5m")
 
 	viper.SetDefault("WORKER_POOL_SIZE", 10)
 	viper.SetDefault("WORKER_QUEUE_SIZE", 100)
 
 	connMaxLifetime, _ := time.ParseDuration(viper.GetString("DB_CONNECTION_MAX_LIFETIME"))
+	usageRollupInterval, _ := time.ParseDuration(viper.GetString("USAGE_ROLLUP_INTERVAL"))
 
 	config := &Config{
 		App: AppConfig{
@@ -157,9 +196,10 @@ func Load() (*Config, error) {
 			QueuePrefix: viper.GetString("RABBITMQ_QUEUE_PREFIX"),
 		},
 		JWT: JWTConfig{
-			Secret:            viper.GetString("JWT_SECRET"),
-			ExpiryHours:       viper.GetInt("JWT_EXPIRY_HOURS"),
-			RefreshExpiryDays: viper.GetInt("JWT_REFRESH_EXPIRY_DAYS"),
+			Secret:                     viper.GetString("JWT_SECRET"),
+			ExpiryHours:                viper.GetInt("JWT_EXPIRY_HOURS"),
+			RefreshExpiryDays:          viper.GetInt("JWT_REFRESH_EXPIRY_DAYS"),
+			ImpersonationExpiryMinutes: viper.GetInt("JWT_IMPERSONATION_EXPIRY_MINUTES"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: parseCORSString(viper.GetString("CORS_ALLOWED_ORIGINS")),
@@ -175,7 +215,25 @@ func Load() (*Config, error) {
 			Format: viper.GetString("LOG_FORMAT"),
 		},
 		FileUpload: FileUploadConfig{
-			MaxSize: viper.GetInt64("MAX_UPLOAD_SIZE"),
+			MaxSize:      viper.GetInt64("MAX_UPLOAD_SIZE"),
+			MaxImageSize: viper.GetInt64("MAX_IMAGE_UPLOAD_SIZE"),
+		},
+		Storage: StorageConfig{
+			LocalPath:      viper.GetString("STORAGE_LOCAL_PATH"),
+			Driver:         viper.GetString("STORAGE_DRIVER"),
+			PublicURL:      viper.GetString("STORAGE_PUBLIC_URL"),
+			S3Endpoint:     viper.GetString("STORAGE_S3_ENDPOINT"),
+			S3Region:       viper.GetString("STORAGE_S3_REGION"),
+			S3Bucket:       viper.GetString("STORAGE_S3_BUCKET"),
+			S3AccessKey:    viper.GetString("STORAGE_S3_ACCESS_KEY"),
+			S3SecretKey:    viper.GetString("STORAGE_S3_SECRET_KEY"),
+			S3UsePathStyle: viper.GetBool("STORAGE_S3_USE_PATH_STYLE"),
+		},
+		DataExport: DataExportConfig{
+			ExpiryHours: viper.GetInt("DATA_EXPORT_EXPIRY_HOURS"),
+		},
+		Usage: UsageConfig{
+			RollupInterval: usageRollupInterval,
 		},
 		Worker: WorkerConfig{
 			PoolSize:  viper.GetInt("WORKER_POOL_SIZE"),
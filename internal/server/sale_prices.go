@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	productsDomain "github.com/exven/pos-system/modules/products/domain"
+	transactionsDomain "github.com/exven/pos-system/modules/transactions/domain"
+)
+
+// salePriceResolver lets checkout price sold items through the products
+// module's price lists, which the transactions module does not import
+type salePriceResolver struct {
+	priceListService productsDomain.PriceListService
+	unitService      productsDomain.UnitService
+}
+
+// NewSalePriceResolver connects the transactions module's checkout to the
+// products module's prices
+func NewSalePriceResolver(priceListService productsDomain.PriceListService, unitService productsDomain.UnitService) transactionsDomain.PriceResolver {
+	return &salePriceResolver{
+		priceListService: priceListService,
+		unitService:      unitService,
+	}
+}
+
+// ResolvePrices prices every item of the transaction as sold now at its
+// outlet to its customer. Prices are per base unit, so an item sold in
+// another unit costs its factor times as much.
+func (r *salePriceResolver) ResolvePrices(ctx context.Context, transaction *transactionsDomain.Transaction) error {
+	outletID := transaction.OutletID
+	req := productsDomain.ResolvePricesRequest{
+		OutletID:   &outletID,
+		CustomerID: transaction.CustomerID,
+		Items:      make([]productsDomain.PriceItemRequest, len(transaction.Items)),
+	}
+	for i, item := range transaction.Items {
+		req.Items[i] = productsDomain.PriceItemRequest{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			ModifierIDs: item.ModifierIDs,
+		}
+	}
+
+	prices, err := r.priceListService.ResolvePrices(ctx, transaction.TenantID, req)
+	if err != nil {
+		return err
+	}
+
+	units := make(map[uint64][]*productsDomain.ProductUnit)
+	for i, item := range transaction.Items {
+		factor := 1.0
+		if item.Unit != "" {
+			productUnits, ok := units[item.ProductID]
+			if !ok {
+				productUnits, err = r.unitService.GetProductUnits(ctx, transaction.TenantID, item.ProductID)
+				if err != nil {
+					return err
+				}
+				units[item.ProductID] = productUnits
+			}
+
+			factor, err = unitFactor(productUnits, item.Unit)
+			if err != nil {
+				return err
+			}
+		}
+
+		item.UnitPrice = math.Round(prices[i].Price*factor*100) / 100
+	}
+
+	return nil
+}
+
+func unitFactor(units []*productsDomain.ProductUnit, code string) (float64, error) {
+	for _, unit := range units {
+		if unit.Code == code {
+			return unit.Factor, nil
+		}
+	}
+	return 0, errors.New("unit is not configured for the product")
+}
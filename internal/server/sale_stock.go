@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+
+	inventoryDomain "github.com/exven/pos-system/modules/inventory/domain"
+	transactionsDomain "github.com/exven/pos-system/modules/transactions/domain"
+)
+
+// saleStockRecorder lets checkout take sold items from stock through the
+// inventory module, which the transactions module does not import
+type saleStockRecorder struct {
+	inventoryService inventoryDomain.InventoryService
+}
+
+// NewSaleStockRecorder connects the transactions module's checkout to the
+// inventory module's stock
+func NewSaleStockRecorder(inventoryService inventoryDomain.InventoryService) transactionsDomain.StockRecorder {
+	return &saleStockRecorder{inventoryService: inventoryService}
+}
+
+// RecordSale records the transaction's items as a sale of its outlet's
+// stock and copies each line's unit, unit factor and cost back onto its
+// item. An item's amount after discounts is what a bundle allocates over its
+// components.
+func (r *saleStockRecorder) RecordSale(ctx context.Context, userID uint64, transaction *transactionsDomain.Transaction) error {
+	req := inventoryDomain.RecordSaleRequest{
+		TransactionID: transaction.ID,
+		OutletID:      transaction.OutletID,
+		Items:         make([]inventoryDomain.SaleItemRequest, len(transaction.Items)),
+	}
+	for i, item := range transaction.Items {
+		req.Items[i] = inventoryDomain.SaleItemRequest{
+			ProductID:         item.ProductID,
+			VariantID:         item.VariantID,
+			Quantity:          item.Quantity,
+			Unit:              item.Unit,
+			TransactionItemID: item.ID,
+			Amount:            item.TotalPrice,
+			Choices:           item.Choices,
+			ModifierIDs:       item.ModifierIDs,
+		}
+	}
+
+	sale, err := r.inventoryService.RecordSale(ctx, transaction.TenantID, userID, req)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range sale.Lines {
+		item := transaction.Items[i]
+		item.Unit = line.Unit
+		item.UnitFactor = line.UnitFactor
+		item.CostPrice = line.UnitCost()
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/inventory"
+	"github.com/exven/pos-system/modules/products"
+	"github.com/exven/pos-system/modules/transactions"
+	transactionsDomain "github.com/exven/pos-system/modules/transactions/domain"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestCheckoutTakesRecipeComponentsFromStock completes sales of a product
+// made by recipe through checkout and checks what they did to the stock of
+// its components. It needs a migrated PostgreSQL database in
+// TEST_DATABASE_URL and is skipped without one.
+func TestCheckoutTakesRecipeComponentsFromStock(t *testing.T) {
+	db := openTestDB(t)
+	tenant, cashier, outlet := createCheckoutTenant(t, db)
+
+	milk := database.Product{TenantID: tenant.ID, SKU: "MILK", Name: "Milk", Unit: "ml", CostPrice: 0.02, TrackStock: true, IsActive: true}
+	beans := database.Product{TenantID: tenant.ID, SKU: "BEANS", Name: "Beans", Unit: "g", CostPrice: 0.15, TrackStock: true, IsActive: true}
+	latte := database.Product{TenantID: tenant.ID, SKU: "LATTE", Name: "Latte", Unit: "pcs", SellingPrice: 30000, TrackStock: false, IsActive: true}
+	for _, product := range []*database.Product{&milk, &beans, &latte} {
+		mustCreate(t, db, product)
+	}
+	mustCreate(t, db, &database.ProductRecipeItem{ProductID: latte.ID, ComponentID: milk.ID, Quantity: 150})
+	mustCreate(t, db, &database.ProductRecipeItem{ProductID: latte.ID, ComponentID: beans.ID, Quantity: 18})
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, opening := range []struct {
+			product  *database.Product
+			quantity float64
+		}{{&milk, 1000}, {&beans, 500}} {
+			_, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     opening.product.ID,
+				OutletID:      outlet.ID,
+				Quantity:      opening.quantity,
+				MovementType:  "in",
+				ReferenceType: "initial",
+				CreatedBy:     cashier.ID,
+				UnitCost:      &opening.product.CostPrice,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to stock components: %v", err)
+	}
+
+	checkout := newCheckout(db)
+	ctx := context.Background()
+
+	sell := func(quantity float64) (*transactionsDomain.Transaction, error) {
+		return checkout.CreateTransaction(ctx, tenant.ID, cashier.ID, transactionsDomain.CreateTransactionRequest{
+			OutletID: outlet.ID,
+			Items: []transactionsDomain.TransactionItemRequest{
+				{ProductID: latte.ID, Quantity: quantity},
+			},
+			Payments: []transactionsDomain.TransactionPaymentRequest{
+				{PaymentMethod: "cash", Amount: quantity * latte.SellingPrice},
+			},
+		})
+	}
+
+	transaction, err := sell(2)
+	if err != nil {
+		t.Fatalf("failed to complete sale: %v", err)
+	}
+
+	assertStock(t, db, outlet.ID, milk.ID, 700)
+	assertStock(t, db, outlet.ID, beans.ID, 464)
+
+	var movements int64
+	db.Table("stock_movements").
+		Where("reference_type = ? AND reference_id = ?", "sale", transaction.ID).
+		Count(&movements)
+	if movements != 2 {
+		t.Errorf("sale movements = %d, want 2", movements)
+	}
+
+	// 150 ml of milk at 0.02 and 18 g of beans at 0.15 per latte
+	if got := transaction.Items[0].CostPrice; got != 5.7 {
+		t.Errorf("cost price snapshot = %v, want 5.7", got)
+	}
+
+	// Five more lattes need 750 ml of milk; the sale is not stored at all
+	if _, err := sell(5); err == nil || err.Error() != "insufficient stock" {
+		t.Fatalf("sale beyond stock: got error %v, want insufficient stock", err)
+	}
+
+	var stored int64
+	db.Table("transactions").Where("tenant_id = ?", tenant.ID).Count(&stored)
+	if stored != 1 {
+		t.Errorf("stored transactions = %d, want 1", stored)
+	}
+	assertStock(t, db, outlet.ID, milk.ID, 700)
+	assertStock(t, db, outlet.ID, beans.ID, 464)
+}
+
+// TestCheckoutSellsLotsFirstExpiredFirstOut sells a lot tracked product
+// and traces the sale to the lots it was served from, as a recall would.
+func TestCheckoutSellsLotsFirstExpiredFirstOut(t *testing.T) {
+	db := openTestDB(t)
+	tenant, cashier, outlet := createCheckoutTenant(t, db)
+
+	syrup := database.Product{TenantID: tenant.ID, SKU: "SYRUP", Name: "Cough Syrup", Unit: "pcs", CostPrice: 12000, SellingPrice: 18000, TrackStock: true, TrackLots: true, IsActive: true}
+	mustCreate(t, db, &syrup)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	expiry := func(days int) *time.Time {
+		date := today.AddDate(0, 0, days)
+		return &date
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := stockledger.Apply(tx, stockledger.Change{
+			ProductID:     syrup.ID,
+			OutletID:      outlet.ID,
+			Quantity:      12,
+			MovementType:  "in",
+			ReferenceType: "initial",
+			CreatedBy:     cashier.ID,
+			UnitCost:      &syrup.CostPrice,
+			Lots: []stockledger.LotQuantity{
+				{LotNumber: "LATE", ExpiryDate: expiry(30), Quantity: 3},
+				{LotNumber: "SOON", ExpiryDate: expiry(10), Quantity: 4},
+				{LotNumber: "EXPIRED", ExpiryDate: expiry(-1), Quantity: 5},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to stock lots: %v", err)
+	}
+
+	transaction, err := newCheckout(db).CreateTransaction(context.Background(), tenant.ID, cashier.ID, transactionsDomain.CreateTransactionRequest{
+		OutletID: outlet.ID,
+		Items: []transactionsDomain.TransactionItemRequest{
+			{ProductID: syrup.ID, Quantity: 6},
+		},
+		Payments: []transactionsDomain.TransactionPaymentRequest{
+			{PaymentMethod: "cash", Amount: 6 * syrup.SellingPrice},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to complete sale: %v", err)
+	}
+
+	// The recall path: the lots of the sale's movements
+	var sold []struct {
+		LotNumber string
+		Quantity  float64
+	}
+	err = db.Table("stock_movement_lots sml").
+		Select("sl.lot_number, sml.quantity").
+		Joins("JOIN stock_movements sm ON sm.id = sml.stock_movement_id").
+		Joins("JOIN stock_lots sl ON sl.id = sml.lot_id").
+		Where("sm.reference_type = ? AND sm.reference_id = ?", "sale", transaction.ID).
+		Order("sl.expiry_date ASC").
+		Scan(&sold).Error
+	if err != nil {
+		t.Fatalf("failed to read the lots of the sale: %v", err)
+	}
+
+	want := []struct {
+		LotNumber string
+		Quantity  float64
+	}{{"SOON", -4}, {"LATE", -2}}
+	if !reflect.DeepEqual(sold, want) {
+		t.Errorf("lots of the sale = %+v, want %+v", sold, want)
+	}
+
+	var expired float64
+	db.Table("stock_lots").Select("quantity").Where("product_id = ? AND lot_number = ?", syrup.ID, "EXPIRED").Scan(&expired)
+	if expired != 5 {
+		t.Errorf("expired lot holds %v, want 5 untouched", expired)
+	}
+	assertStock(t, db, outlet.ID, syrup.ID, 6)
+}
+
+// openTestDB connects to the migrated PostgreSQL database in
+// TEST_DATABASE_URL, skipping the test without one
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	return db
+}
+
+// createCheckoutTenant creates a tenant with a cashier and an outlet, and
+// deletes it with everything it owns once the test ends
+func createCheckoutTenant(t *testing.T, db *gorm.DB) (database.Tenant, database.User, database.Outlet) {
+	t.Helper()
+
+	suffix := time.Now().UnixNano()
+	role := database.Role{Name: fmt.Sprintf("checkout-test-%d", suffix), DisplayName: "Checkout test"}
+	mustCreate(t, db, &role)
+	tenant := database.Tenant{Name: "Checkout test", Email: fmt.Sprintf("checkout-%d@example.test", suffix)}
+	mustCreate(t, db, &tenant)
+	t.Cleanup(func() {
+		if err := db.Delete(&tenant).Error; err != nil {
+			t.Logf("failed to delete test tenant %d: %v", tenant.ID, err)
+		}
+		db.Delete(&role)
+	})
+
+	cashier := database.User{TenantID: tenant.ID, RoleID: role.ID, Email: "cashier@example.test", PasswordHash: "-", FullName: "Test Cashier", IsActive: true}
+	mustCreate(t, db, &cashier)
+	outlet := database.Outlet{TenantID: tenant.ID, Name: "Main", Code: "MAIN", IsActive: true}
+	mustCreate(t, db, &outlet)
+
+	return tenant, cashier, outlet
+}
+
+// newCheckout wires checkout to the products and inventory modules as the
+// server does, without metering
+func newCheckout(db *gorm.DB) transactionsDomain.TransactionService {
+	inventoryService := inventory.NewModule(container.New(), db, nil).GetService()
+	productsModule := products.NewModule(container.New(), db, nil, nil, config.FileUploadConfig{})
+	salePrices := NewSalePriceResolver(productsModule.GetPriceListService(), productsModule.GetUnitService())
+	return transactions.NewModule(container.New(), db, nil, salePrices, NewSaleStockRecorder(inventoryService), unmetered{}).GetService()
+}
+
+// unmetered lets a tenant make any number of sales
+type unmetered struct{}
+
+func (unmetered) CheckTransactionLimit(ctx context.Context, tenantID uint64) error { return nil }
+
+func (unmetered) RecordTransaction(ctx context.Context, tenantID uint64) error { return nil }
+
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("failed to create %T: %v", value, err)
+	}
+}
+
+func assertStock(t *testing.T, db *gorm.DB, outletID, productID uint64, want float64) {
+	t.Helper()
+	var quantity float64
+	err := db.Table("product_stocks").
+		Select("quantity").
+		Where("product_id = ? AND outlet_id = ? AND variant_id IS NULL", productID, outletID).
+		Scan(&quantity).Error
+	if err != nil {
+		t.Fatalf("failed to read stock: %v", err)
+	}
+	if quantity != want {
+		t.Errorf("stock of product %d = %v, want %v", productID, quantity, want)
+	}
+}
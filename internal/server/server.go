@@ -4,15 +4,25 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path/filepath"
 
 	"github.com/exven/pos-system/internal/config"
 	"github.com/exven/pos-system/modules/auth/domain"
 	"github.com/exven/pos-system/modules/auth/handlers"
+	"github.com/exven/pos-system/modules/data_import"
+	"github.com/exven/pos-system/modules/inventory"
 	"github.com/exven/pos-system/modules/outlets"
 	"github.com/exven/pos-system/modules/products"
+	"github.com/exven/pos-system/modules/purchasing"
 	"github.com/exven/pos-system/modules/roles"
 	"github.com/exven/pos-system/modules/subscription_plans"
+	"github.com/exven/pos-system/modules/tenant"
+	"github.com/exven/pos-system/modules/transactions"
+	"github.com/exven/pos-system/modules/usage"
+	usageDomain "github.com/exven/pos-system/modules/usage/domain"
 	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/cache"
+	"github.com/exven/pos-system/shared/infrastructure/storage"
 	"github.com/exven/pos-system/shared/middleware"
 	"github.com/exven/pos-system/shared/validator"
 	"github.com/labstack/echo/v4"
@@ -81,6 +91,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 func (s *Server) registerRoutes() {
 	s.echo.GET("/health", s.healthCheck)
 
+	// Local storage is served by the API itself; S3 serves its own files
+	if s.config.Storage.Driver != storage.DriverS3 {
+		s.echo.Static("/uploads", filepath.Join(s.config.Storage.LocalPath, "uploads"))
+	}
+
 	api := s.echo.Group("/api/v1")
 
 	authService := s.container.MustGet("auth.service").(domain.AuthService)
@@ -91,9 +106,17 @@ func (s *Server) registerRoutes() {
 	protected.Use(middleware.JWTAuth(s.config.JWT.Secret))
 	protected.Use(middleware.TenantContext())
 
-	// Get the products module and register its routes
 	db := s.container.MustGet("db").(*gorm.DB)
-	productsModule := products.NewModule(s.container, db, nil)
+	protected.Use(middleware.ImpersonationAudit(db))
+
+	usageMeter := s.container.MustGet("usage.meter").(usageDomain.UsageMeter)
+	protected.Use(middleware.UsageMetering(usageMeter))
+
+	authHandler.RegisterProtectedRoutes(protected)
+
+	// Get the products module and register its routes
+	fileStorage := s.container.MustGet("storage").(storage.Storage)
+	productsModule := products.NewModule(s.container, db, nil, fileStorage, s.config.FileUpload)
 	productHandler := productsModule.GetHandler()
 	productHandler.RegisterRoutes(protected)
 
@@ -102,6 +125,24 @@ func (s *Server) registerRoutes() {
 	outletHandler := outletsModule.GetHandler()
 	outletHandler.RegisterRoutes(protected)
 
+	// Get the inventory module and register its routes
+	inventoryModule := inventory.NewModule(s.container, db, nil)
+	inventoryHandler := inventoryModule.GetHandler()
+	inventoryHandler.RegisterRoutes(protected)
+
+	// Get the purchasing module and register its routes
+	purchasingModule := purchasing.NewModule(s.container, db, nil)
+	purchasingHandler := purchasingModule.GetHandler()
+	purchasingHandler.RegisterRoutes(protected)
+
+	// Get the transactions module and register its routes; checkout prices
+	// sold items through the products module, takes them from stock through
+	// the inventory module and is metered by the usage module
+	salePrices := NewSalePriceResolver(productsModule.GetPriceListService(), productsModule.GetUnitService())
+	transactionsModule := transactions.NewModule(s.container, db, nil, salePrices, NewSaleStockRecorder(inventoryModule.GetService()), usageMeter)
+	transactionHandler := transactionsModule.GetHandler()
+	transactionHandler.RegisterRoutes(protected)
+
 	// Get the subscription plans module and register its routes (no auth required)
 	subscriptionPlansModule := subscription_plans.NewModule(s.container, db, nil)
 	subscriptionPlanHandler := subscriptionPlansModule.GetHandler()
@@ -112,6 +153,22 @@ func (s *Server) registerRoutes() {
 	roleHandler := rolesModule.GetHandler()
 	roleHandler.RegisterRoutes(api)
 
+	// Get the tenant module and register its routes
+	tenantModule := tenant.NewModule(s.container, db, nil, s.config.Storage, s.config.DataExport)
+	tenantHandler := tenantModule.GetHandler()
+	tenantHandler.RegisterRoutes(protected)
+
+	// Get the data import module and register its routes
+	dataImportModule := data_import.NewModule(s.container, db, nil, s.config.Storage)
+	importHandler := dataImportModule.GetHandler()
+	importHandler.RegisterRoutes(protected)
+
+	// Get the usage module and register its routes
+	redisClient := s.container.MustGet("redis").(*cache.RedisClient)
+	usageModule := usage.NewModule(s.container, db, redisClient, s.config.Storage)
+	usageHandler := usageModule.GetHandler()
+	usageHandler.RegisterRoutes(protected)
+
 }
 
 func (s *Server) healthCheck(c echo.Context) error {
@@ -60,3 +60,32 @@ type ResetPasswordRequest struct {
 type VerifyEmailRequest struct {
 	Token string `json:"token" validate:"required"`
 }
+
+type ImpersonateRequest struct {
+	UserID uint64 `json:"user_id" validate:"required"`
+	Reason string `json:"reason" validate:"required,min=10,max=500"`
+}
+
+type ImpersonateResponse struct {
+	AccessToken     string       `json:"access_token"`
+	ExpiresIn       int64        `json:"expires_in"`
+	ImpersonationID uint64       `json:"impersonation_id"`
+	User            UserResponse `json:"user"`
+}
+
+type ImpersonationSessionResponse struct {
+	ID         uint64  `json:"id"`
+	TenantID   uint64  `json:"tenant_id"`
+	ActorID    uint64  `json:"actor_id"`
+	ActorName  string  `json:"actor_name"`
+	ActorEmail string  `json:"actor_email"`
+	UserID     uint64  `json:"user_id"`
+	UserName   string  `json:"user_name"`
+	UserEmail  string  `json:"user_email"`
+	Reason     string  `json:"reason"`
+	IPAddress  string  `json:"ip_address"`
+	IsActive   bool    `json:"is_active"`
+	ExpiresAt  string  `json:"expires_at"`
+	EndedAt    *string `json:"ended_at"`
+	CreatedAt  string  `json:"created_at"`
+}
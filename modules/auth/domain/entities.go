@@ -67,9 +67,35 @@ type TokenPair struct {
 }
 
 type TokenClaims struct {
-	UserID    uint64
+	UserID          uint64
+	TenantID        uint64
+	Email           string
+	RoleID          uint64
+	ActorID         *uint64
+	ImpersonationID *uint64
+	ExpiresAt       time.Time
+}
+
+const RoleSuperAdmin = "super_admin"
+
+type ImpersonationSession struct {
+	ID        uint64
 	TenantID  uint64
-	Email     string
-	RoleID    uint64
+	ActorID   uint64
+	UserID    uint64
+	Reason    string
+	IPAddress string
+	UserAgent string
 	ExpiresAt time.Time
+	EndedAt   *time.Time
+	CreatedAt time.Time
+
+	ActorName  string
+	ActorEmail string
+	UserName   string
+	UserEmail  string
+}
+
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
 }
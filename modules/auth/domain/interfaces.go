@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type UserRepository interface {
@@ -24,6 +25,15 @@ type SessionRepository interface {
 	DeleteExpired(ctx context.Context) error
 }
 
+type ImpersonationRepository interface {
+	// Create stores the session together with its "impersonation.start"
+	// audit log entry.
+	Create(ctx context.Context, session *ImpersonationSession) error
+	FindByID(ctx context.Context, sessionID uint64) (*ImpersonationSession, error)
+	FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*ImpersonationSession, int64, error)
+	End(ctx context.Context, sessionID uint64) error
+}
+
 type AuthService interface {
 	Login(ctx context.Context, credentials LoginCredentials) (*TokenPair, *User, error)
 	Register(ctx context.Context, req RegisterRequest) (*User, error)
@@ -33,11 +43,15 @@ type AuthService interface {
 	ChangePassword(ctx context.Context, userID uint64, oldPassword, newPassword string) error
 	ResetPassword(ctx context.Context, email string) error
 	VerifyEmail(ctx context.Context, token string) error
+	Impersonate(ctx context.Context, actorID uint64, req ImpersonateRequest, ipAddress, userAgent string) (*TokenPair, *ImpersonationSession, *User, error)
+	EndImpersonation(ctx context.Context, tenantID, sessionID uint64) error
+	GetImpersonationSessions(ctx context.Context, tenantID uint64, limit, offset int) ([]*ImpersonationSession, int64, error)
 }
 
 type TokenService interface {
 	GenerateAccessToken(user *User) (string, error)
 	GenerateRefreshToken(user *User) (string, error)
+	GenerateImpersonationToken(user *User, actor *User, sessionID uint64, expiresAt time.Time) (string, error)
 	ValidateAccessToken(token string) (*TokenClaims, error)
 	ValidateRefreshToken(token string) (*TokenClaims, error)
 }
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/exven/pos-system/modules/auth/domain"
 	"github.com/exven/pos-system/shared/utils/response"
 	"github.com/labstack/echo/v4"
@@ -27,6 +31,15 @@ func (h *AuthHandler) RegisterRoutes(e *echo.Group) {
 	auth.POST("/verify-email", h.VerifyEmail)
 }
 
+// RegisterProtectedRoutes registers the auth routes that require an
+// authenticated user.
+func (h *AuthHandler) RegisterProtectedRoutes(e *echo.Group) {
+	auth := e.Group("/auth")
+	auth.POST("/impersonate", h.Impersonate)
+	auth.GET("/impersonations", h.GetImpersonationSessions)
+	auth.DELETE("/impersonations/:id", h.EndImpersonation)
+}
+
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req domain.LoginRequest
 	if err := c.Bind(&req); err != nil {
@@ -190,3 +203,140 @@ func (h *AuthHandler) VerifyEmail(c echo.Context) error {
 
 	return response.Success(c, "Email verified successfully", nil)
 }
+
+func (h *AuthHandler) Impersonate(c echo.Context) error {
+	var req domain.ImpersonateRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	// An impersonation token cannot be used to start another impersonation
+	if c.Get("actor_id") != nil {
+		return response.Error(c, http.StatusForbidden, "cannot impersonate while impersonating", nil)
+	}
+
+	actorID := c.Get("user_id").(uint64)
+
+	tokenPair, session, user, err := h.authService.Impersonate(
+		c.Request().Context(),
+		actorID,
+		req,
+		c.RealIP(),
+		c.Request().UserAgent(),
+	)
+	if err != nil {
+		switch err.Error() {
+		case "only super admins can impersonate users", "super admins cannot be impersonated":
+			return response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case "user not found":
+			return response.NotFound(c, "User not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	impersonateResponse := domain.ImpersonateResponse{
+		AccessToken:     tokenPair.AccessToken,
+		ExpiresIn:       tokenPair.ExpiresIn,
+		ImpersonationID: session.ID,
+		User: domain.UserResponse{
+			ID:       user.ID,
+			TenantID: user.TenantID,
+			Email:    user.Email,
+			FullName: user.FullName,
+			Phone:    user.Phone,
+			IsActive: user.IsActive,
+		},
+	}
+
+	if user.Role != nil {
+		impersonateResponse.User.Role = domain.RoleResponse{
+			ID:          user.Role.ID,
+			Name:        user.Role.Name,
+			DisplayName: user.Role.DisplayName,
+			Permissions: user.Role.Permissions,
+		}
+	}
+
+	return response.Created(c, "Impersonation started successfully", impersonateResponse)
+}
+
+func (h *AuthHandler) GetImpersonationSessions(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	sessions, total, err := h.authService.GetImpersonationSessions(c.Request().Context(), tenantID, limit, (page-1)*limit)
+	if err != nil {
+		return response.InternalError(c, "Failed to get impersonation sessions")
+	}
+
+	now := time.Now()
+	sessionResponses := make([]domain.ImpersonationSessionResponse, len(sessions))
+	for i, session := range sessions {
+		sessionResponses[i] = h.impersonationSessionToResponse(session, now)
+	}
+
+	return response.SuccessWithPagination(c, "Impersonation sessions retrieved successfully", sessionResponses, page, limit, int(total))
+}
+
+func (h *AuthHandler) EndImpersonation(c echo.Context) error {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid impersonation session ID")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	if err := h.authService.EndImpersonation(c.Request().Context(), tenantID, sessionID); err != nil {
+		if err.Error() == "impersonation session not found" {
+			return response.NotFound(c, "Impersonation session not found")
+		}
+		return response.InternalError(c, "Failed to end impersonation session")
+	}
+
+	return response.Success(c, "Impersonation session ended successfully", nil)
+}
+
+// Helper functions
+
+func (h *AuthHandler) impersonationSessionToResponse(session *domain.ImpersonationSession, now time.Time) domain.ImpersonationSessionResponse {
+	sessionResponse := domain.ImpersonationSessionResponse{
+		ID:         session.ID,
+		TenantID:   session.TenantID,
+		ActorID:    session.ActorID,
+		ActorName:  session.ActorName,
+		ActorEmail: session.ActorEmail,
+		UserID:     session.UserID,
+		UserName:   session.UserName,
+		UserEmail:  session.UserEmail,
+		Reason:     session.Reason,
+		IPAddress:  session.IPAddress,
+		IsActive:   session.IsActive(now),
+		ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+	}
+
+	if session.EndedAt != nil {
+		endedAt := session.EndedAt.Format(time.RFC3339)
+		sessionResponse.EndedAt = &endedAt
+	}
+
+	return sessionResponse
+}
@@ -43,6 +43,10 @@ func (m *Module) Register() {
 		return persistence.NewSessionRepository()
 	})
 
+	m.container.RegisterSingleton("auth.impersonationRepository", func() interface{} {
+		return persistence.NewImpersonationRepository(m.db)
+	})
+
 	m.container.RegisterSingleton("auth.tokenService", func() interface{} {
 		return services.NewTokenService(
 			m.jwtConfig.Secret,
@@ -58,6 +62,7 @@ func (m *Module) Register() {
 	m.container.RegisterSingleton("auth.service", func() interface{} {
 		userRepo := persistence.NewUserRepository(m.db)
 		sessionRepo := persistence.NewSessionRepository()
+		impersonationRepo := persistence.NewImpersonationRepository(m.db)
 		tokenService := services.NewTokenService(
 			m.jwtConfig.Secret,
 			m.jwtConfig.ExpiryHours,
@@ -68,9 +73,11 @@ func (m *Module) Register() {
 		return services.NewAuthService(
 			userRepo,
 			sessionRepo,
+			impersonationRepo,
 			tokenService,
 			passwordService,
 			m.eventBus,
+			m.jwtConfig.ImpersonationExpiryMinutes,
 		)
 	})
 }
@@ -0,0 +1,148 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/auth/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+type ImpersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+func (r *ImpersonationRepository) Create(ctx context.Context, session *domain.ImpersonationSession) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sessionModel := &ImpersonationSessionModel{}
+		sessionModel.FromDomainImpersonationSession(session)
+
+		if err := tx.Omit("Actor", "User").Create(sessionModel).Error; err != nil {
+			return err
+		}
+
+		session.ID = sessionModel.ID
+		session.CreatedAt = sessionModel.CreatedAt
+
+		recordID := session.ID
+		userID := session.UserID
+		actorID := session.ActorID
+		auditLog := &AuditLogModel{
+			TenantID:    session.TenantID,
+			UserID:      &userID,
+			ActorID:     &actorID,
+			Action:      "impersonation.start",
+			RecordTable: "impersonation_sessions",
+			RecordID:    &recordID,
+			NewValues: database.JSONMap{
+				"reason":     session.Reason,
+				"expires_at": session.ExpiresAt.Format(time.RFC3339),
+			},
+			IPAddress: nullableIP(session.IPAddress),
+			UserAgent: session.UserAgent,
+		}
+
+		return tx.Create(auditLog).Error
+	})
+}
+
+func (r *ImpersonationRepository) FindByID(ctx context.Context, sessionID uint64) (*domain.ImpersonationSession, error) {
+	var sessionModel ImpersonationSessionModel
+	err := r.db.WithContext(ctx).
+		Preload("Actor").
+		Preload("User").
+		First(&sessionModel, sessionID).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("impersonation session not found")
+		}
+		return nil, err
+	}
+
+	return sessionModel.ToDomainImpersonationSession(), nil
+}
+
+func (r *ImpersonationRepository) FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.ImpersonationSession, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).
+		Model(&ImpersonationSessionModel{}).
+		Where("tenant_id = ?", tenantID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sessionModels []ImpersonationSessionModel
+	err := r.db.WithContext(ctx).
+		Preload("Actor").
+		Preload("User").
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessionModels).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sessions := make([]*domain.ImpersonationSession, len(sessionModels))
+	for i := range sessionModels {
+		sessions[i] = sessionModels[i].ToDomainImpersonationSession()
+	}
+
+	return sessions, total, nil
+}
+
+func (r *ImpersonationRepository) End(ctx context.Context, sessionID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sessionModel ImpersonationSessionModel
+		if err := tx.First(&sessionModel, sessionID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("impersonation session not found")
+			}
+			return err
+		}
+
+		if sessionModel.EndedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&ImpersonationSessionModel{}).
+			Where("id = ?", sessionID).
+			Update("ended_at", now).Error; err != nil {
+			return err
+		}
+
+		recordID := sessionModel.ID
+		userID := sessionModel.UserID
+		actorID := sessionModel.ActorID
+		auditLog := &AuditLogModel{
+			TenantID:    sessionModel.TenantID,
+			UserID:      &userID,
+			ActorID:     &actorID,
+			Action:      "impersonation.end",
+			RecordTable: "impersonation_sessions",
+			RecordID:    &recordID,
+			NewValues: database.JSONMap{
+				"ended_at": now.Format(time.RFC3339),
+			},
+		}
+
+		return tx.Create(auditLog).Error
+	})
+}
+
+func nullableIP(ip string) *string {
+	if ip == "" {
+		return nil
+	}
+	return &ip
+}
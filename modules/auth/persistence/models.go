@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/exven/pos-system/modules/auth/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
 )
 
 // UserModel maps to the database users table
@@ -137,3 +138,77 @@ func (t *TenantModel) ToDomainTenant() *domain.Tenant {
 		UpdatedAt:    t.UpdatedAt,
 	}
 }
+
+// ImpersonationSessionModel maps to the database impersonation_sessions table
+type ImpersonationSessionModel struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID  uint64 `gorm:"not null"`
+	ActorID   uint64 `gorm:"not null"`
+	UserID    uint64 `gorm:"not null"`
+	Reason    string `gorm:"type:text;not null"`
+	IPAddress string `gorm:"size:45"`
+	UserAgent string `gorm:"type:text"`
+	ExpiresAt time.Time
+	EndedAt   *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	Actor UserModel `gorm:"foreignKey:ActorID"`
+	User  UserModel `gorm:"foreignKey:UserID"`
+}
+
+func (ImpersonationSessionModel) TableName() string {
+	return "impersonation_sessions"
+}
+
+// AuditLogModel maps to the database audit_logs table
+type AuditLogModel struct {
+	ID          uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64 `gorm:"not null"`
+	UserID      *uint64
+	ActorID     *uint64
+	Action      string `gorm:"size:100;not null"`
+	RecordTable string `gorm:"column:table_name;size:100"`
+	RecordID    *uint64
+	NewValues   database.JSONMap `gorm:"type:jsonb"`
+	IPAddress   *string          `gorm:"type:inet"`
+	UserAgent   string           `gorm:"type:text"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime"`
+}
+
+func (AuditLogModel) TableName() string {
+	return "audit_logs"
+}
+
+// ToDomainImpersonationSession converts ImpersonationSessionModel to domain.ImpersonationSession
+func (m *ImpersonationSessionModel) ToDomainImpersonationSession() *domain.ImpersonationSession {
+	return &domain.ImpersonationSession{
+		ID:         m.ID,
+		TenantID:   m.TenantID,
+		ActorID:    m.ActorID,
+		UserID:     m.UserID,
+		Reason:     m.Reason,
+		IPAddress:  m.IPAddress,
+		UserAgent:  m.UserAgent,
+		ExpiresAt:  m.ExpiresAt,
+		EndedAt:    m.EndedAt,
+		CreatedAt:  m.CreatedAt,
+		ActorName:  m.Actor.FullName,
+		ActorEmail: m.Actor.Email,
+		UserName:   m.User.FullName,
+		UserEmail:  m.User.Email,
+	}
+}
+
+// FromDomainImpersonationSession converts domain.ImpersonationSession to ImpersonationSessionModel
+func (m *ImpersonationSessionModel) FromDomainImpersonationSession(session *domain.ImpersonationSession) {
+	m.ID = session.ID
+	m.TenantID = session.TenantID
+	m.ActorID = session.ActorID
+	m.UserID = session.UserID
+	m.Reason = session.Reason
+	m.IPAddress = session.IPAddress
+	m.UserAgent = session.UserAgent
+	m.ExpiresAt = session.ExpiresAt
+	m.EndedAt = session.EndedAt
+	m.CreatedAt = session.CreatedAt
+}
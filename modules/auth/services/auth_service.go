@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/exven/pos-system/modules/auth/domain"
@@ -11,26 +12,32 @@ import (
 )
 
 type AuthService struct {
-	userRepo        domain.UserRepository
-	sessionRepo     domain.SessionRepository
-	tokenService    domain.TokenService
-	passwordService domain.PasswordService
-	eventBus        messaging.EventBus
+	userRepo            domain.UserRepository
+	sessionRepo         domain.SessionRepository
+	impersonationRepo   domain.ImpersonationRepository
+	tokenService        domain.TokenService
+	passwordService     domain.PasswordService
+	eventBus            messaging.EventBus
+	impersonationExpiry time.Duration
 }
 
 func NewAuthService(
 	userRepo domain.UserRepository,
 	sessionRepo domain.SessionRepository,
+	impersonationRepo domain.ImpersonationRepository,
 	tokenService domain.TokenService,
 	passwordService domain.PasswordService,
 	eventBus messaging.EventBus,
+	impersonationExpiryMinutes int,
 ) *AuthService {
 	return &AuthService{
-		userRepo:        userRepo,
-		sessionRepo:     sessionRepo,
-		tokenService:    tokenService,
-		passwordService: passwordService,
-		eventBus:        eventBus,
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		impersonationRepo:   impersonationRepo,
+		tokenService:        tokenService,
+		passwordService:     passwordService,
+		eventBus:            eventBus,
+		impersonationExpiry: time.Duration(impersonationExpiryMinutes) * time.Minute,
 	}
 }
 
@@ -235,6 +242,89 @@ func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	return errors.New("not implemented")
 }
 
+func (s *AuthService) Impersonate(ctx context.Context, actorID uint64, req domain.ImpersonateRequest, ipAddress, userAgent string) (*domain.TokenPair, *domain.ImpersonationSession, *domain.User, error) {
+	actor, err := s.userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if actor.Role == nil || actor.Role.Name != domain.RoleSuperAdmin {
+		return nil, nil, nil, fmt.Errorf("only super admins can impersonate users")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if user.ID == actor.ID {
+		return nil, nil, nil, fmt.Errorf("cannot impersonate yourself")
+	}
+
+	if user.Role != nil && user.Role.Name == domain.RoleSuperAdmin {
+		return nil, nil, nil, fmt.Errorf("super admins cannot be impersonated")
+	}
+
+	if !user.IsActive {
+		return nil, nil, nil, fmt.Errorf("user account is inactive")
+	}
+
+	session := &domain.ImpersonationSession{
+		TenantID:  user.TenantID,
+		ActorID:   actor.ID,
+		UserID:    user.ID,
+		Reason:    strings.TrimSpace(req.Reason),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(s.impersonationExpiry),
+	}
+
+	if err := s.impersonationRepo.Create(ctx, session); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	accessToken, err := s.tokenService.GenerateImpersonationToken(user, actor, session.ID, session.ExpiresAt)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	session.ActorName = actor.FullName
+	session.ActorEmail = actor.Email
+	session.UserName = user.FullName
+	session.UserEmail = user.Email
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("user.impersonated", user.TenantID, actor.ID, map[string]interface{}{
+			"impersonation_id": session.ID,
+			"user_id":          user.ID,
+			"reason":           session.Reason,
+		})
+		s.eventBus.Publish(ctx, "auth.impersonation", event)
+	}
+
+	return &domain.TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(s.impersonationExpiry.Seconds()),
+	}, session, user, nil
+}
+
+func (s *AuthService) EndImpersonation(ctx context.Context, tenantID, sessionID uint64) error {
+	session, err := s.impersonationRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.TenantID != tenantID {
+		return fmt.Errorf("impersonation session not found")
+	}
+
+	return s.impersonationRepo.End(ctx, sessionID)
+}
+
+func (s *AuthService) GetImpersonationSessions(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.ImpersonationSession, int64, error) {
+	return s.impersonationRepo.FindByTenant(ctx, tenantID, limit, offset)
+}
+
 func generateSessionID() string {
 	return fmt.Sprintf("sess_%d", time.Now().UnixNano())
 }
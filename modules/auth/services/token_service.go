@@ -50,6 +50,30 @@ func (s *TokenService) GenerateRefreshToken(user *domain.User) (string, error) {
 	return token.SignedString([]byte(s.jwtSecret))
 }
 
+// GenerateImpersonationToken issues a short-lived access token for user on
+// behalf of actor. The "act" claim identifies the real caller so every request
+// made with the token can be attributed to them.
+func (s *TokenService) GenerateImpersonationToken(user *domain.User, actor *domain.User, sessionID uint64, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"tenant_id": user.TenantID,
+		"email":     user.Email,
+		"role_id":   user.RoleID,
+		"act": map[string]interface{}{
+			"user_id":   actor.ID,
+			"tenant_id": actor.TenantID,
+			"email":     actor.Email,
+		},
+		"impersonation_id": sessionID,
+		"exp":              expiresAt.Unix(),
+		"iat":              time.Now().Unix(),
+		"type":             "access",
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.jwtSecret))
+}
+
 func (s *TokenService) ValidateAccessToken(tokenString string) (*domain.TokenClaims, error) {
 	return s.validateToken(tokenString, "access")
 }
@@ -111,11 +135,28 @@ func (s *TokenService) validateToken(tokenString string, tokenType string) (*dom
 		return nil, fmt.Errorf("invalid exp in token")
 	}
 
-	return &domain.TokenClaims{
+	tokenClaims := &domain.TokenClaims{
 		UserID:    uint64(userID),
 		TenantID:  uint64(tenantID),
 		Email:     email,
 		RoleID:    uint64(roleID),
 		ExpiresAt: time.Unix(int64(exp), 0),
-	}, nil
+	}
+
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorID, ok := act["user_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid act claim in token")
+		}
+		impersonationID, ok := claims["impersonation_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid impersonation_id in token")
+		}
+		actor := uint64(actorID)
+		session := uint64(impersonationID)
+		tokenClaims.ActorID = &actor
+		tokenClaims.ImpersonationID = &session
+	}
+
+	return tokenClaims, nil
 }
@@ -5,24 +5,37 @@ import (
 )
 
 type Customer struct {
+	ID              uint64
+	TenantID        uint64
+	CustomerGroupID *uint64
+	Code            string
+	Name            string
+	Email           string
+	Phone           string
+	Address         string
+	City            string
+	Province        string
+	PostalCode      string
+	BirthDate       *time.Time
+	Gender          string
+	LoyaltyPoints   int
+	TotalSpent      float64
+	VisitCount      int
+	LastVisitAt     *time.Time
+	Notes           string
+	IsActive        bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// CustomerGroup groups customers that are priced alike, such as wholesale
+// buyers. Price lists can be limited to customer groups.
+type CustomerGroup struct {
 	ID            uint64
 	TenantID      uint64
-	Code          string
 	Name          string
-	Email         string
-	Phone         string
-	Address       string
-	City          string
-	Province      string
-	PostalCode    string
-	BirthDate     *time.Time
-	Gender        string
-	LoyaltyPoints int
-	TotalSpent    float64
-	VisitCount    int
-	LastVisitAt   *time.Time
-	Notes         string
-	IsActive      bool
+	Description   string
+	CustomerCount int64
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
-}
\ No newline at end of file
+}
@@ -17,6 +17,16 @@ type CustomerRepository interface {
 	UpdateStats(ctx context.Context, customerID uint64, totalSpent float64, visitCount int) error
 }
 
+type CustomerGroupRepository interface {
+	Create(ctx context.Context, group *CustomerGroup) error
+	Update(ctx context.Context, group *CustomerGroup) error
+	Delete(ctx context.Context, tenantID, groupID uint64) error
+	FindByID(ctx context.Context, tenantID, groupID uint64) (*CustomerGroup, error)
+	FindAll(ctx context.Context, tenantID uint64) ([]*CustomerGroup, error)
+	IsNameExists(ctx context.Context, tenantID uint64, name string, excludeID *uint64) (bool, error)
+	IsUsedByPriceList(ctx context.Context, groupID uint64) (bool, error)
+}
+
 type CustomerService interface {
 	Create(ctx context.Context, tenantID uint64, req CreateCustomerRequest) (*Customer, error)
 	GetByID(ctx context.Context, tenantID, customerID uint64) (*Customer, error)
@@ -26,4 +36,14 @@ type CustomerService interface {
 	GetAll(ctx context.Context, tenantID uint64, query CustomerQuery) ([]*Customer, int64, error)
 	Update(ctx context.Context, tenantID, customerID uint64, req UpdateCustomerRequest) (*Customer, error)
 	Delete(ctx context.Context, tenantID, customerID uint64) error
-}
\ No newline at end of file
+}
+
+type CustomerGroupService interface {
+	Create(ctx context.Context, tenantID uint64, req CreateCustomerGroupRequest) (*CustomerGroup, error)
+	Update(ctx context.Context, tenantID, groupID uint64, req UpdateCustomerGroupRequest) (*CustomerGroup, error)
+	// Delete removes the group; its customers stay, without a group. A group
+	// that a price list is limited to cannot be deleted.
+	Delete(ctx context.Context, tenantID, groupID uint64) error
+	GetByID(ctx context.Context, tenantID, groupID uint64) (*CustomerGroup, error)
+	GetAll(ctx context.Context, tenantID uint64) ([]*CustomerGroup, error)
+}
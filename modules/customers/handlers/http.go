@@ -12,11 +12,13 @@ import (
 
 type CustomerHandler struct {
 	customerService domain.CustomerService
+	groupService    domain.CustomerGroupService
 }
 
-func NewCustomerHandler(customerService domain.CustomerService) *CustomerHandler {
+func NewCustomerHandler(customerService domain.CustomerService, groupService domain.CustomerGroupService) *CustomerHandler {
 	return &CustomerHandler{
 		customerService: customerService,
+		groupService:    groupService,
 	}
 }
 
@@ -32,6 +34,14 @@ func (h *CustomerHandler) RegisterRoutes(e *echo.Group) {
 	customers.GET("/code/:code", h.GetCustomerByCode)
 	customers.GET("/phone/:phone", h.GetCustomerByPhone)
 	customers.GET("/email/:email", h.GetCustomerByEmail)
+
+	// Customer group routes
+	groups := customers.Group("/groups")
+	groups.POST("", h.CreateCustomerGroup)
+	groups.GET("", h.GetCustomerGroups)
+	groups.GET("/:id", h.GetCustomerGroup)
+	groups.PUT("/:id", h.UpdateCustomerGroup)
+	groups.DELETE("/:id", h.DeleteCustomerGroup)
 }
 
 func (h *CustomerHandler) CreateCustomer(c echo.Context) error {
@@ -97,6 +107,12 @@ func (h *CustomerHandler) GetCustomers(c echo.Context) error {
 	query.Province = c.QueryParam("province")
 	query.Gender = c.QueryParam("gender")
 
+	if groupID := c.QueryParam("customer_group_id"); groupID != "" {
+		if id, err := strconv.ParseUint(groupID, 10, 64); err == nil {
+			query.CustomerGroupID = &id
+		}
+	}
+
 	if isActive := c.QueryParam("is_active"); isActive != "" {
 		if active, err := strconv.ParseBool(isActive); err == nil {
 			query.IsActive = &active
@@ -257,29 +273,158 @@ func (h *CustomerHandler) GetCustomerByEmail(c echo.Context) error {
 	return response.Success(c, "Customer retrieved successfully", responseData)
 }
 
+// Customer group handlers
+
+func (h *CustomerHandler) CreateCustomerGroup(c echo.Context) error {
+	var req domain.CreateCustomerGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	group, err := h.groupService.Create(c.Request().Context(), tenantID, req)
+	if err != nil {
+		if err.Error() == "customer group with this name already exists" {
+			return response.Error(c, http.StatusConflict, err.Error(), map[string][]string{
+				"name": {"Name already exists"},
+			})
+		}
+		return response.InternalError(c, "Failed to create customer group")
+	}
+
+	return response.Created(c, "Customer group created successfully", h.customerGroupToResponse(group))
+}
+
+func (h *CustomerHandler) GetCustomerGroups(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groups, err := h.groupService.GetAll(c.Request().Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, "Failed to get customer groups")
+	}
+
+	groupResponses := make([]domain.CustomerGroupResponse, len(groups))
+	for i, group := range groups {
+		groupResponses[i] = h.customerGroupToResponse(group)
+	}
+
+	return response.Success(c, "Customer groups retrieved successfully", groupResponses)
+}
+
+func (h *CustomerHandler) GetCustomerGroup(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer group ID")
+	}
+
+	group, err := h.groupService.GetByID(c.Request().Context(), tenantID, groupID)
+	if err != nil {
+		if err.Error() == "customer group not found" {
+			return response.NotFound(c, "Customer group not found")
+		}
+		return response.InternalError(c, "Failed to get customer group")
+	}
+
+	return response.Success(c, "Customer group retrieved successfully", h.customerGroupToResponse(group))
+}
+
+func (h *CustomerHandler) UpdateCustomerGroup(c echo.Context) error {
+	var req domain.UpdateCustomerGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer group ID")
+	}
+
+	group, err := h.groupService.Update(c.Request().Context(), tenantID, groupID, req)
+	if err != nil {
+		switch err.Error() {
+		case "customer group not found":
+			return response.NotFound(c, "Customer group not found")
+		case "customer group with this name already exists":
+			return response.Error(c, http.StatusConflict, err.Error(), map[string][]string{
+				"name": {"Name already exists"},
+			})
+		}
+		return response.InternalError(c, "Failed to update customer group")
+	}
+
+	return response.Success(c, "Customer group updated successfully", h.customerGroupToResponse(group))
+}
+
+func (h *CustomerHandler) DeleteCustomerGroup(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid customer group ID")
+	}
+
+	err = h.groupService.Delete(c.Request().Context(), tenantID, groupID)
+	if err != nil {
+		switch err.Error() {
+		case "customer group not found":
+			return response.NotFound(c, "Customer group not found")
+		case "customer group is used by a price list":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to delete customer group")
+	}
+
+	return response.Success(c, "Customer group deleted successfully", nil)
+}
+
 // Helper functions
 
 func (h *CustomerHandler) customerToResponse(customer *domain.Customer) domain.CustomerResponse {
 	return domain.CustomerResponse{
-		ID:            customer.ID,
-		TenantID:      customer.TenantID,
-		Code:          customer.Code,
-		Name:          customer.Name,
-		Email:         customer.Email,
-		Phone:         customer.Phone,
-		Address:       customer.Address,
-		City:          customer.City,
-		Province:      customer.Province,
-		PostalCode:    customer.PostalCode,
-		BirthDate:     customer.BirthDate,
-		Gender:        customer.Gender,
-		LoyaltyPoints: customer.LoyaltyPoints,
-		TotalSpent:    customer.TotalSpent,
-		VisitCount:    customer.VisitCount,
-		LastVisitAt:   customer.LastVisitAt,
-		Notes:         customer.Notes,
-		IsActive:      customer.IsActive,
-		CreatedAt:     customer.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:     customer.UpdatedAt.Format(time.RFC3339),
-	}
-}
\ No newline at end of file
+		ID:              customer.ID,
+		TenantID:        customer.TenantID,
+		CustomerGroupID: customer.CustomerGroupID,
+		Code:            customer.Code,
+		Name:            customer.Name,
+		Email:           customer.Email,
+		Phone:           customer.Phone,
+		Address:         customer.Address,
+		City:            customer.City,
+		Province:        customer.Province,
+		PostalCode:      customer.PostalCode,
+		BirthDate:       customer.BirthDate,
+		Gender:          customer.Gender,
+		LoyaltyPoints:   customer.LoyaltyPoints,
+		TotalSpent:      customer.TotalSpent,
+		VisitCount:      customer.VisitCount,
+		LastVisitAt:     customer.LastVisitAt,
+		Notes:           customer.Notes,
+		IsActive:        customer.IsActive,
+		CreatedAt:       customer.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       customer.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *CustomerHandler) customerGroupToResponse(group *domain.CustomerGroup) domain.CustomerGroupResponse {
+	return domain.CustomerGroupResponse{
+		ID:            group.ID,
+		Name:          group.Name,
+		Description:   group.Description,
+		CustomerCount: group.CustomerCount,
+		CreatedAt:     group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     group.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -32,23 +32,35 @@ func (m *Module) Register() {
 	m.container.RegisterSingleton("customers.customerRepository", func() interface{} {
 		return persistence.NewCustomerRepository(m.db)
 	})
+	m.container.RegisterSingleton("customers.customerGroupRepository", func() interface{} {
+		return persistence.NewCustomerGroupRepository(m.db)
+	})
 
 	// Register services
 	m.container.RegisterSingleton("customers.customerService", func() interface{} {
 		repo := persistence.NewCustomerRepository(m.db)
-		return services.NewCustomerService(repo)
+		groupRepo := persistence.NewCustomerGroupRepository(m.db)
+		return services.NewCustomerService(repo, groupRepo)
+	})
+	m.container.RegisterSingleton("customers.customerGroupService", func() interface{} {
+		groupRepo := persistence.NewCustomerGroupRepository(m.db)
+		return services.NewCustomerGroupService(groupRepo)
 	})
 
 	// Register handlers
 	m.container.RegisterSingleton("customers.handler", func() interface{} {
 		repo := persistence.NewCustomerRepository(m.db)
-		service := services.NewCustomerService(repo)
-		return handlers.NewCustomerHandler(service)
+		groupRepo := persistence.NewCustomerGroupRepository(m.db)
+		service := services.NewCustomerService(repo, groupRepo)
+		groupService := services.NewCustomerGroupService(groupRepo)
+		return handlers.NewCustomerHandler(service, groupService)
 	})
 }
 
 func (m *Module) GetHandler() *handlers.CustomerHandler {
 	repo := persistence.NewCustomerRepository(m.db)
-	service := services.NewCustomerService(repo)
-	return handlers.NewCustomerHandler(service)
+	groupRepo := persistence.NewCustomerGroupRepository(m.db)
+	service := services.NewCustomerService(repo, groupRepo)
+	groupService := services.NewCustomerGroupService(groupRepo)
+	return handlers.NewCustomerHandler(service, groupService)
 }
\ No newline at end of file
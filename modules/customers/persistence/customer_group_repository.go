@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/customers/domain"
+	"gorm.io/gorm"
+)
+
+// customerCountSQL counts the active customers of a group
+const customerCountSQL = "(SELECT COUNT(*) FROM customers c WHERE c.customer_group_id = customer_groups.id AND c.is_active = true) AS customer_count"
+
+type customerGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerGroupRepository(db *gorm.DB) domain.CustomerGroupRepository {
+	return &customerGroupRepository{db: db}
+}
+
+func (r *customerGroupRepository) Create(ctx context.Context, group *domain.CustomerGroup) error {
+	model := &CustomerGroupModel{}
+	model.FromDomainCustomerGroup(group)
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return errors.New("customer group with this name already exists")
+		}
+		return fmt.Errorf("failed to create customer group: %w", err)
+	}
+
+	group.ID = model.ID
+	group.CreatedAt = model.CreatedAt
+	group.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+func (r *customerGroupRepository) Update(ctx context.Context, group *domain.CustomerGroup) error {
+	result := r.db.WithContext(ctx).
+		Model(&CustomerGroupModel{}).
+		Where("id = ? AND tenant_id = ?", group.ID, group.TenantID).
+		Updates(map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+			"updated_at":  time.Now(),
+		})
+
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
+			return errors.New("customer group with this name already exists")
+		}
+		return fmt.Errorf("failed to update customer group: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("customer group not found")
+	}
+
+	return nil
+}
+
+func (r *customerGroupRepository) Delete(ctx context.Context, tenantID, groupID uint64) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", groupID, tenantID).
+		Delete(&CustomerGroupModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete customer group: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("customer group not found")
+	}
+
+	return nil
+}
+
+func (r *customerGroupRepository) FindByID(ctx context.Context, tenantID, groupID uint64) (*domain.CustomerGroup, error) {
+	var model CustomerGroupWithCountModel
+
+	err := r.db.WithContext(ctx).
+		Model(&CustomerGroupModel{}).
+		Select("customer_groups.*, "+customerCountSQL).
+		Where("id = ? AND tenant_id = ?", groupID, tenantID).
+		Take(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("customer group not found")
+		}
+		return nil, fmt.Errorf("failed to find customer group: %w", err)
+	}
+
+	group := model.ToDomainCustomerGroup()
+	group.CustomerCount = model.CustomerCount
+
+	return group, nil
+}
+
+func (r *customerGroupRepository) FindAll(ctx context.Context, tenantID uint64) ([]*domain.CustomerGroup, error) {
+	var models []CustomerGroupWithCountModel
+
+	err := r.db.WithContext(ctx).
+		Model(&CustomerGroupModel{}).
+		Select("customer_groups.*, "+customerCountSQL).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer groups: %w", err)
+	}
+
+	groups := make([]*domain.CustomerGroup, len(models))
+	for i := range models {
+		groups[i] = models[i].ToDomainCustomerGroup()
+		groups[i].CustomerCount = models[i].CustomerCount
+	}
+
+	return groups, nil
+}
+
+func (r *customerGroupRepository) IsNameExists(ctx context.Context, tenantID uint64, name string, excludeID *uint64) (bool, error) {
+	query := r.db.WithContext(ctx).
+		Model(&CustomerGroupModel{}).
+		Where("tenant_id = ? AND LOWER(name) = LOWER(?)", tenantID, name)
+
+	if excludeID != nil {
+		query = query.Where("id != ?", *excludeID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check customer group name: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *customerGroupRepository) IsUsedByPriceList(ctx context.Context, groupID uint64) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Table("price_list_customer_groups").
+		Where("customer_group_id = ?", groupID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check customer group price lists: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -160,6 +160,11 @@ func (r *customerRepository) GetAll(ctx context.Context, tenantID uint64, query
 		fetchQuery = fetchQuery.Where("gender = ?", query.Gender)
 	}
 
+	if query.CustomerGroupID != nil {
+		countQuery = countQuery.Where("customer_group_id = ?", *query.CustomerGroupID)
+		fetchQuery = fetchQuery.Where("customer_group_id = ?", *query.CustomerGroupID)
+	}
+
 	if query.IsActive != nil {
 		countQuery = countQuery.Where("is_active = ?", *query.IsActive)
 		fetchQuery = fetchQuery.Where("is_active = ?", *query.IsActive)
@@ -229,8 +234,11 @@ func (r *customerRepository) Update(ctx context.Context, customer *domain.Custom
 	model := &CustomerModel{}
 	model.FromDomainCustomer(customer)
 
+	// Updates skips zero fields, so the group is set on its own to allow
+	// removing the customer from its group
 	result := r.db.WithContext(ctx).
 		Where("id = ? AND tenant_id = ?", customer.ID, customer.TenantID).
+		Omit("customer_group_id").
 		Updates(model)
 
 	if result.Error != nil {
@@ -244,6 +252,14 @@ func (r *customerRepository) Update(ctx context.Context, customer *domain.Custom
 		return errors.New("customer not found")
 	}
 
+	err := r.db.WithContext(ctx).
+		Model(&CustomerModel{}).
+		Where("id = ? AND tenant_id = ?", customer.ID, customer.TenantID).
+		Update("customer_group_id", customer.CustomerGroupID).Error
+	if err != nil {
+		return fmt.Errorf("failed to update customer group: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/customers/domain"
+)
+
+type customerGroupService struct {
+	groupRepo domain.CustomerGroupRepository
+}
+
+func NewCustomerGroupService(groupRepo domain.CustomerGroupRepository) domain.CustomerGroupService {
+	return &customerGroupService{
+		groupRepo: groupRepo,
+	}
+}
+
+func (s *customerGroupService) Create(ctx context.Context, tenantID uint64, req domain.CreateCustomerGroupRequest) (*domain.CustomerGroup, error) {
+	name := strings.TrimSpace(req.Name)
+
+	// Validate name uniqueness
+	exists, err := s.groupRepo.IsNameExists(ctx, tenantID, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("customer group with this name already exists")
+	}
+
+	group := &domain.CustomerGroup{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *customerGroupService) Update(ctx context.Context, tenantID, groupID uint64, req domain.UpdateCustomerGroupRequest) (*domain.CustomerGroup, error) {
+	group, err := s.groupRepo.FindByID(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+
+	// Validate name uniqueness (excluding current group)
+	exists, err := s.groupRepo.IsNameExists(ctx, tenantID, name, &groupID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("customer group with this name already exists")
+	}
+
+	group.Name = name
+	group.Description = strings.TrimSpace(req.Description)
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.FindByID(ctx, tenantID, groupID)
+}
+
+func (s *customerGroupService) Delete(ctx context.Context, tenantID, groupID uint64) error {
+	if _, err := s.groupRepo.FindByID(ctx, tenantID, groupID); err != nil {
+		return err
+	}
+
+	// Dropping the group from a price list would widen the list to every
+	// customer, so the price list has to be changed first
+	used, err := s.groupRepo.IsUsedByPriceList(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("customer group is used by a price list")
+	}
+
+	return s.groupRepo.Delete(ctx, tenantID, groupID)
+}
+
+func (s *customerGroupService) GetByID(ctx context.Context, tenantID, groupID uint64) (*domain.CustomerGroup, error) {
+	return s.groupRepo.FindByID(ctx, tenantID, groupID)
+}
+
+func (s *customerGroupService) GetAll(ctx context.Context, tenantID uint64) ([]*domain.CustomerGroup, error) {
+	return s.groupRepo.FindAll(ctx, tenantID)
+}
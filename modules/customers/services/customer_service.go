@@ -12,11 +12,13 @@ import (
 
 type customerService struct {
 	customerRepo domain.CustomerRepository
+	groupRepo    domain.CustomerGroupRepository
 }
 
-func NewCustomerService(customerRepo domain.CustomerRepository) domain.CustomerService {
+func NewCustomerService(customerRepo domain.CustomerRepository, groupRepo domain.CustomerGroupRepository) domain.CustomerService {
 	return &customerService{
 		customerRepo: customerRepo,
+		groupRepo:    groupRepo,
 	}
 }
 
@@ -54,6 +56,13 @@ func (s *customerService) Create(ctx context.Context, tenantID uint64, req domai
 		}
 	}
 
+	// Validate customer group exists if provided
+	if req.CustomerGroupID != nil {
+		if _, err := s.groupRepo.FindByID(ctx, tenantID, *req.CustomerGroupID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate customer code if not provided
 	code := strings.TrimSpace(req.Code)
 	if code == "" {
@@ -73,25 +82,26 @@ func (s *customerService) Create(ctx context.Context, tenantID uint64, req domai
 
 	// Create customer entity
 	customer := &domain.Customer{
-		TenantID:      tenantID,
-		Code:          code,
-		Name:          strings.TrimSpace(req.Name),
-		Email:         strings.TrimSpace(req.Email),
-		Phone:         strings.TrimSpace(req.Phone),
-		Address:       strings.TrimSpace(req.Address),
-		City:          strings.TrimSpace(req.City),
-		Province:      strings.TrimSpace(req.Province),
-		PostalCode:    strings.TrimSpace(req.PostalCode),
-		BirthDate:     req.BirthDate,
-		Gender:        req.Gender,
-		LoyaltyPoints: 0,
-		TotalSpent:    0.0,
-		VisitCount:    0,
-		LastVisitAt:   nil,
-		Notes:         strings.TrimSpace(req.Notes),
-		IsActive:      true,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		TenantID:        tenantID,
+		CustomerGroupID: req.CustomerGroupID,
+		Code:            code,
+		Name:            strings.TrimSpace(req.Name),
+		Email:           strings.TrimSpace(req.Email),
+		Phone:           strings.TrimSpace(req.Phone),
+		Address:         strings.TrimSpace(req.Address),
+		City:            strings.TrimSpace(req.City),
+		Province:        strings.TrimSpace(req.Province),
+		PostalCode:      strings.TrimSpace(req.PostalCode),
+		BirthDate:       req.BirthDate,
+		Gender:          req.Gender,
+		LoyaltyPoints:   0,
+		TotalSpent:      0.0,
+		VisitCount:      0,
+		LastVisitAt:     nil,
+		Notes:           strings.TrimSpace(req.Notes),
+		IsActive:        true,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	err := s.customerRepo.Create(ctx, customer)
@@ -142,7 +152,15 @@ func (s *customerService) Update(ctx context.Context, tenantID, customerID uint6
 		}
 	}
 
+	// Validate customer group exists if provided
+	if req.CustomerGroupID != nil {
+		if _, err := s.groupRepo.FindByID(ctx, tenantID, *req.CustomerGroupID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update customer entity
+	existingCustomer.CustomerGroupID = req.CustomerGroupID
 	existingCustomer.Code = strings.TrimSpace(req.Code)
 	existingCustomer.Name = strings.TrimSpace(req.Name)
 	existingCustomer.Email = strings.TrimSpace(req.Email)
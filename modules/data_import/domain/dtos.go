@@ -0,0 +1,57 @@
+package domain
+
+type ValidateImportRequest struct {
+	// Mapping maps an import field to the spreadsheet column header it is
+	// read from.
+	Mapping map[string]string `json:"mapping" validate:"required"`
+}
+
+type CommitImportRequest struct {
+	RollbackOnFailure bool `json:"rollback_on_failure"`
+}
+
+type ImportFieldResponse struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	OneOf    []string `json:"one_of,omitempty"`
+}
+
+type RowErrorResponse struct {
+	Row    int                 `json:"row"`
+	Errors map[string][]string `json:"errors"`
+}
+
+type ImportJobResponse struct {
+	ID                uint64                `json:"id"`
+	ImportType        string                `json:"import_type"`
+	FileName          string                `json:"file_name"`
+	FileFormat        string                `json:"file_format"`
+	Status            string                `json:"status"`
+	Headers           []string              `json:"headers"`
+	Mapping           map[string]string     `json:"mapping"`
+	Fields            []ImportFieldResponse `json:"fields"`
+	TotalRows         int                   `json:"total_rows"`
+	ProcessedRows     int                   `json:"processed_rows"`
+	SuccessRows       int                   `json:"success_rows"`
+	FailedRows        int                   `json:"failed_rows"`
+	Progress          float64               `json:"progress"`
+	RollbackOnFailure bool                  `json:"rollback_on_failure"`
+	ErrorMessage      string                `json:"error_message,omitempty"`
+	StartedAt         *string               `json:"started_at"`
+	CompletedAt       *string               `json:"completed_at"`
+	CreatedAt         string                `json:"created_at"`
+	UpdatedAt         string                `json:"updated_at"`
+}
+
+type ImportJobDetailResponse struct {
+	ImportJobResponse
+	SampleRows [][]string         `json:"sample_rows,omitempty"`
+	RowErrors  []RowErrorResponse `json:"row_errors"`
+}
+
+type ImportValidationResponse struct {
+	ImportJobResponse
+	ValidRows int                `json:"valid_rows"`
+	RowErrors []RowErrorResponse `json:"row_errors"`
+}
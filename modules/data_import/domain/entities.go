@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	ImportTypeProducts     = "products"
+	ImportTypeCategories   = "categories"
+	ImportTypeCustomers    = "customers"
+	ImportTypeOpeningStock = "opening_stock"
+)
+
+const (
+	ImportStatusUploaded            = "uploaded"
+	ImportStatusValidated           = "validated"
+	ImportStatusInvalid             = "invalid"
+	ImportStatusQueued              = "queued"
+	ImportStatusProcessing          = "processing"
+	ImportStatusCompleted           = "completed"
+	ImportStatusCompletedWithErrors = "completed_with_errors"
+	ImportStatusFailed              = "failed"
+)
+
+const (
+	FieldKindString  = "string"
+	FieldKindInt     = "int"
+	FieldKindDecimal = "decimal"
+	FieldKindBool    = "bool"
+	FieldKindDate    = "date"
+)
+
+// FieldSpec describes a column an import type accepts.
+type FieldSpec struct {
+	Name     string
+	Kind     string
+	Required bool
+	MaxLen   int
+	Min      *float64
+	OneOf    []string
+	// Aliases are normalized header names that map to the field when
+	// suggesting a column mapping.
+	Aliases []string
+}
+
+type ImportJob struct {
+	ID                uint64
+	TenantID          uint64
+	CreatedBy         uint64
+	ImportType        string
+	FileName          string
+	FilePath          string
+	FileFormat        string
+	Status            string
+	Headers           []string
+	Mapping           map[string]string
+	TotalRows         int
+	ProcessedRows     int
+	SuccessRows       int
+	FailedRows        int
+	RollbackOnFailure bool
+	RowErrors         []RowError
+	ErrorMessage      string
+	StartedAt         *time.Time
+	CompletedAt       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Progress returns the share of rows processed, from 0 to 100.
+func (j *ImportJob) Progress() float64 {
+	if j.TotalRows == 0 {
+		return 0
+	}
+	return float64(j.ProcessedRows) * 100 / float64(j.TotalRows)
+}
+
+func (j *ImportJob) IsRunning() bool {
+	return j.Status == ImportStatusQueued || j.Status == ImportStatusProcessing
+}
+
+// RowError holds the field errors of one spreadsheet row, keyed like
+// response.ValidationError. Row is the 1-based line in the file, counting the
+// header row.
+type RowError struct {
+	Row    int
+	Errors map[string][]string
+}
+
+// ImportRow is a parsed and type-converted spreadsheet row.
+type ImportRow struct {
+	Row    int
+	Values map[string]interface{}
+}
+
+func (r ImportRow) String(field string) string {
+	value, _ := r.Values[field].(string)
+	return value
+}
+
+func (r ImportRow) Int(field string) (int, bool) {
+	value, ok := r.Values[field].(int)
+	return value, ok
+}
+
+func (r ImportRow) Decimal(field string) (float64, bool) {
+	value, ok := r.Values[field].(float64)
+	return value, ok
+}
+
+func (r ImportRow) Bool(field string) (bool, bool) {
+	value, ok := r.Values[field].(bool)
+	return value, ok
+}
+
+func (r ImportRow) Date(field string) (*time.Time, bool) {
+	value, ok := r.Values[field].(time.Time)
+	if !ok {
+		return nil, false
+	}
+	return &value, true
+}
+
+// ImportReferences are the existing tenant records an import is checked
+// against.
+type ImportReferences struct {
+	CategoryIDs map[string]uint64
+	// CategoryPaths keys categories by their lower-cased path from the root,
+	// e.g. "minuman > kopi"
+	CategoryPaths map[string]uint64
+	ProductIDs    map[string]uint64
+	VariantSKUs   map[string]bool
+	OutletIDs     map[string]uint64
+	CustomerCodes map[string]bool
+}
+
+// CategoryPathSeparator separates the levels of a category path in the
+// product import, as in "Minuman > Kopi".
+const CategoryPathSeparator = ">"
+
+// CategoryPath splits a category path into its trimmed levels.
+func CategoryPath(path string) []string {
+	levels := strings.Split(path, CategoryPathSeparator)
+	for i, level := range levels {
+		levels[i] = strings.TrimSpace(level)
+	}
+	return levels
+}
+
+// CategoryPathKey is the CategoryPaths key of a path's levels.
+func CategoryPathKey(levels []string) string {
+	return strings.ToLower(strings.Join(levels, " "+CategoryPathSeparator+" "))
+}
+
+// ImageURLs splits a list of image URLs separated by commas or whitespace.
+func ImageURLs(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+// ImportFields lists the accepted fields per import type.
+var ImportFields = map[string][]FieldSpec{
+	ImportTypeCategories: {
+		{Name: "name", Kind: FieldKindString, Required: true, MaxLen: 255, Aliases: []string{"category", "categoryname", "nama", "namakategori", "kategori"}},
+		{Name: "description", Kind: FieldKindString, Aliases: []string{"desc", "deskripsi", "keterangan"}},
+		{Name: "parent", Kind: FieldKindString, MaxLen: 255, Aliases: []string{"parentcategory", "parentname", "induk", "kategoriinduk"}},
+		{Name: "sort_order", Kind: FieldKindInt, Aliases: []string{"sortorder", "order", "urutan"}},
+		{Name: "is_active", Kind: FieldKindBool, Aliases: []string{"active", "isactive", "status", "aktif"}},
+	},
+	ImportTypeProducts: {
+		{Name: "sku", Kind: FieldKindString, Required: true, MaxLen: 100, Aliases: []string{"sku", "code", "productcode", "itemcode", "kode", "kodeproduk", "kodebarang"}},
+		{Name: "name", Kind: FieldKindString, Required: true, MaxLen: 255, Aliases: []string{"name", "productname", "itemname", "item", "nama", "namaproduk", "namabarang"}},
+		{Name: "barcode", Kind: FieldKindString, MaxLen: 100, Aliases: []string{"barcode", "ean", "upc", "kodebarcode"}},
+		{Name: "category", Kind: FieldKindString, Aliases: []string{"category", "categoryname", "categorypath", "kategori"}},
+		{Name: "description", Kind: FieldKindString, Aliases: []string{"desc", "description", "deskripsi", "keterangan"}},
+		{Name: "unit", Kind: FieldKindString, MaxLen: 50, Aliases: []string{"unit", "uom", "satuan"}},
+		{Name: "cost_price", Kind: FieldKindDecimal, Min: float64Ptr(0), Aliases: []string{"cost", "costprice", "purchaseprice", "hargamodal", "hargabeli", "hpp"}},
+		{Name: "selling_price", Kind: FieldKindDecimal, Required: true, Min: float64Ptr(0), Aliases: []string{"price", "sellingprice", "saleprice", "retailprice", "harga", "hargajual"}},
+		{Name: "min_stock", Kind: FieldKindDecimal, Min: float64Ptr(0), Aliases: []string{"minstock", "minimumstock", "reorderlevel", "stokminimum", "stokmin"}},
+		{Name: "track_stock", Kind: FieldKindBool, Aliases: []string{"trackstock", "trackinventory", "lacakstok"}},
+		{Name: "is_active", Kind: FieldKindBool, Aliases: []string{"active", "isactive", "status", "aktif"}},
+		{Name: "images", Kind: FieldKindString, Aliases: []string{"images", "image", "imageurl", "imageurls", "gambar", "foto"}},
+	},
+	ImportTypeCustomers: {
+		{Name: "name", Kind: FieldKindString, Required: true, MaxLen: 255, Aliases: []string{"name", "customername", "fullname", "nama", "namapelanggan"}},
+		{Name: "code", Kind: FieldKindString, MaxLen: 50, Aliases: []string{"code", "customercode", "customerid", "kode", "kodepelanggan"}},
+		{Name: "email", Kind: FieldKindString, MaxLen: 255, Aliases: []string{"email", "emailaddress", "surel"}},
+		{Name: "phone", Kind: FieldKindString, MaxLen: 20, Aliases: []string{"phone", "phonenumber", "mobile", "telepon", "notelp", "nohp", "hp"}},
+		{Name: "address", Kind: FieldKindString, Aliases: []string{"address", "alamat"}},
+		{Name: "city", Kind: FieldKindString, MaxLen: 100, Aliases: []string{"city", "kota"}},
+		{Name: "province", Kind: FieldKindString, MaxLen: 100, Aliases: []string{"province", "state", "provinsi"}},
+		{Name: "postal_code", Kind: FieldKindString, MaxLen: 10, Aliases: []string{"postalcode", "zip", "zipcode", "kodepos"}},
+		{Name: "birth_date", Kind: FieldKindDate, Aliases: []string{"birthdate", "dateofbirth", "dob", "tanggallahir"}},
+		{Name: "gender", Kind: FieldKindString, OneOf: []string{"male", "female"}, Aliases: []string{"gender", "sex", "jeniskelamin"}},
+		{Name: "loyalty_points", Kind: FieldKindInt, Min: float64Ptr(0), Aliases: []string{"points", "loyaltypoints", "poin"}},
+		{Name: "notes", Kind: FieldKindString, Aliases: []string{"notes", "note", "catatan"}},
+	},
+	ImportTypeOpeningStock: {
+		{Name: "sku", Kind: FieldKindString, Required: true, MaxLen: 100, Aliases: []string{"sku", "code", "productcode", "itemcode", "kode", "kodeproduk", "kodebarang"}},
+		{Name: "outlet_code", Kind: FieldKindString, Required: true, MaxLen: 50, Aliases: []string{"outlet", "outletcode", "store", "storecode", "location", "kodeoutlet", "toko"}},
+		{Name: "quantity", Kind: FieldKindDecimal, Required: true, Min: float64Ptr(0), Aliases: []string{"quantity", "qty", "stock", "onhand", "jumlah", "stok"}},
+	},
+}
+
+// FieldErrors reports request-level problems, such as an invalid column
+// mapping, in the response.ValidationError shape.
+type FieldErrors map[string][]string
+
+func (e FieldErrors) Error() string {
+	return "invalid column mapping"
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *ImportJob) error
+	Update(ctx context.Context, job *ImportJob) error
+	// Queue marks a job in the given status as queued, failing with "import
+	// has already been committed" when its status has changed meanwhile.
+	Queue(ctx context.Context, job *ImportJob, status string) error
+	UpdateProgress(ctx context.Context, job *ImportJob) error
+	FindByID(ctx context.Context, tenantID, jobID uint64) (*ImportJob, error)
+	FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*ImportJob, int64, error)
+}
+
+// ImportRecordRepository reads and writes the records an import targets.
+type ImportRecordRepository interface {
+	LoadReferences(ctx context.Context, tenantID uint64) (*ImportReferences, error)
+	// Import writes the rows of a job. With rollbackOnFailure every row is
+	// written in one database transaction that is rolled back on the first
+	// failing row; otherwise each row is committed on its own and failures
+	// are reported through onRow. onRow is called after every row with a nil
+	// error on success.
+	Import(ctx context.Context, job *ImportJob, rows []ImportRow, rollbackOnFailure bool, onRow func(row ImportRow, err error)) error
+}
+
+type ImportService interface {
+	Upload(ctx context.Context, tenantID, userID uint64, importType, fileName string, file io.Reader) (*ImportJob, [][]string, error)
+	GetJob(ctx context.Context, tenantID, jobID uint64) (*ImportJob, error)
+	GetJobs(ctx context.Context, tenantID uint64, limit, offset int) ([]*ImportJob, int64, error)
+	// Validate is the dry run: it maps and checks every row without writing
+	// anything and stores the mapping on the job.
+	Validate(ctx context.Context, tenantID, jobID uint64, req ValidateImportRequest) (*ImportJob, int, error)
+	// Commit queues the job for a background import.
+	Commit(ctx context.Context, tenantID, jobID uint64, req CommitImportRequest) (*ImportJob, error)
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+	"github.com/exven/pos-system/shared/types"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type ImportHandler struct {
+	importService domain.ImportService
+}
+
+func NewImportHandler(importService domain.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+func (h *ImportHandler) RegisterRoutes(e *echo.Group) {
+	imports := e.Group("/imports")
+
+	imports.GET("/fields", h.GetFields)
+	imports.POST("", h.Upload)
+	imports.GET("", h.GetJobs)
+	imports.GET("/:id", h.GetJob)
+	imports.POST("/:id/validate", h.Validate)
+	imports.POST("/:id/commit", h.Commit)
+}
+
+func (h *ImportHandler) GetFields(c echo.Context) error {
+	fields := make(map[string][]domain.ImportFieldResponse, len(domain.ImportFields))
+	for importType := range domain.ImportFields {
+		fields[importType] = h.fieldsToResponse(importType)
+	}
+
+	return response.Success(c, "Import fields retrieved successfully", fields)
+}
+
+func (h *ImportHandler) Upload(c echo.Context) error {
+	importType := c.FormValue("import_type")
+	if importType == "" {
+		return response.ValidationError(c, map[string][]string{
+			"import_type": {"Import type is required"},
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"file": {"File is required"},
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.BadRequest(c, "Failed to read uploaded file")
+	}
+	defer file.Close()
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	job, sample, err := h.importService.Upload(c.Request().Context(), tenantID, userID, importType, fileHeader.Filename, file)
+	if err != nil {
+		switch err.Error() {
+		case "unsupported import type":
+			return response.ValidationError(c, map[string][]string{
+				"import_type": {"Import type must be one of products, categories, customers, opening_stock"},
+			})
+		case "file must be a CSV or XLSX spreadsheet":
+			return response.ValidationError(c, map[string][]string{
+				"file": {"File must be a CSV or XLSX spreadsheet"},
+			})
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "File uploaded successfully", domain.ImportJobDetailResponse{
+		ImportJobResponse: h.jobToResponse(job),
+		SampleRows:        sample,
+		RowErrors:         []domain.RowErrorResponse{},
+	})
+}
+
+func (h *ImportHandler) GetJobs(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	jobs, total, err := h.importService.GetJobs(c.Request().Context(), tenantID, limit, (page-1)*limit)
+	if err != nil {
+		return response.InternalError(c, "Failed to get imports")
+	}
+
+	jobResponses := make([]domain.ImportJobResponse, len(jobs))
+	for i, job := range jobs {
+		jobResponses[i] = h.jobToResponse(job)
+	}
+
+	return response.SuccessWithPagination(c, "Imports retrieved successfully", jobResponses, page, limit, int(total))
+}
+
+func (h *ImportHandler) GetJob(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid import ID")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	job, err := h.importService.GetJob(c.Request().Context(), tenantID, jobID)
+	if err != nil {
+		if err.Error() == "import job not found" {
+			return response.NotFound(c, "Import not found")
+		}
+		return response.InternalError(c, "Failed to get import")
+	}
+
+	return response.Success(c, "Import retrieved successfully", domain.ImportJobDetailResponse{
+		ImportJobResponse: h.jobToResponse(job),
+		RowErrors:         h.rowErrorsToResponse(job.RowErrors),
+	})
+}
+
+func (h *ImportHandler) Validate(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid import ID")
+	}
+
+	var req domain.ValidateImportRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	job, validRows, err := h.importService.Validate(c.Request().Context(), tenantID, jobID, req)
+	if err != nil {
+		var fieldErrors domain.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			return response.ValidationError(c, fieldErrors)
+		}
+
+		switch err.Error() {
+		case "import job not found":
+			return response.NotFound(c, "Import not found")
+		case "import has already been committed":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to validate import")
+	}
+
+	return response.Success(c, "Import validated successfully", domain.ImportValidationResponse{
+		ImportJobResponse: h.jobToResponse(job),
+		ValidRows:         validRows,
+		RowErrors:         h.rowErrorsToResponse(job.RowErrors),
+	})
+}
+
+func (h *ImportHandler) Commit(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid import ID")
+	}
+
+	var req domain.CommitImportRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	job, err := h.importService.Commit(c.Request().Context(), tenantID, jobID, req)
+	if err != nil {
+		switch err.Error() {
+		case "import job not found":
+			return response.NotFound(c, "Import not found")
+		case "import must be validated first", "import has validation errors":
+			return response.BadRequest(c, err.Error())
+		case "import has already been committed":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to start import")
+	}
+
+	return c.JSON(http.StatusAccepted, types.SuccessResponse{
+		Message: "Import started successfully",
+		Data:    h.jobToResponse(job),
+	})
+}
+
+// Helper functions
+
+func (h *ImportHandler) jobToResponse(job *domain.ImportJob) domain.ImportJobResponse {
+	jobResponse := domain.ImportJobResponse{
+		ID:                job.ID,
+		ImportType:        job.ImportType,
+		FileName:          job.FileName,
+		FileFormat:        job.FileFormat,
+		Status:            job.Status,
+		Headers:           job.Headers,
+		Mapping:           job.Mapping,
+		Fields:            h.fieldsToResponse(job.ImportType),
+		TotalRows:         job.TotalRows,
+		ProcessedRows:     job.ProcessedRows,
+		SuccessRows:       job.SuccessRows,
+		FailedRows:        job.FailedRows,
+		Progress:          job.Progress(),
+		RollbackOnFailure: job.RollbackOnFailure,
+		ErrorMessage:      job.ErrorMessage,
+		CreatedAt:         job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         job.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if jobResponse.Headers == nil {
+		jobResponse.Headers = []string{}
+	}
+	if jobResponse.Mapping == nil {
+		jobResponse.Mapping = map[string]string{}
+	}
+
+	if job.StartedAt != nil {
+		startedAt := job.StartedAt.Format(time.RFC3339)
+		jobResponse.StartedAt = &startedAt
+	}
+
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.Format(time.RFC3339)
+		jobResponse.CompletedAt = &completedAt
+	}
+
+	return jobResponse
+}
+
+func (h *ImportHandler) fieldsToResponse(importType string) []domain.ImportFieldResponse {
+	specs := domain.ImportFields[importType]
+	fields := make([]domain.ImportFieldResponse, len(specs))
+	for i, spec := range specs {
+		fields[i] = domain.ImportFieldResponse{
+			Name:     spec.Name,
+			Type:     spec.Kind,
+			Required: spec.Required,
+			OneOf:    spec.OneOf,
+		}
+	}
+	return fields
+}
+
+func (h *ImportHandler) rowErrorsToResponse(rowErrors []domain.RowError) []domain.RowErrorResponse {
+	responses := make([]domain.RowErrorResponse, len(rowErrors))
+	for i, rowError := range rowErrors {
+		responses[i] = domain.RowErrorResponse{
+			Row:    rowError.Row,
+			Errors: rowError.Errors,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,68 @@
+package data_import
+
+import (
+	"path/filepath"
+
+	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/data_import/handlers"
+	"github.com/exven/pos-system/modules/data_import/persistence"
+	"github.com/exven/pos-system/modules/data_import/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container     container.Container
+	db            *gorm.DB
+	eventBus      messaging.EventBus
+	storageConfig config.StorageConfig
+}
+
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	eventBus messaging.EventBus,
+	storageConfig config.StorageConfig,
+) *Module {
+	return &Module{
+		container:     container,
+		db:            db,
+		eventBus:      eventBus,
+		storageConfig: storageConfig,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("data_import.importJobRepository", func() interface{} {
+		return persistence.NewImportJobRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("data_import.importRecordRepository", func() interface{} {
+		return persistence.NewImportRecordRepository(m.db)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("data_import.importService", func() interface{} {
+		jobRepo := persistence.NewImportJobRepository(m.db)
+		recordRepo := persistence.NewImportRecordRepository(m.db)
+		return services.NewImportService(jobRepo, recordRepo, m.eventBus, m.importDir())
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("data_import.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+func (m *Module) GetHandler() *handlers.ImportHandler {
+	jobRepo := persistence.NewImportJobRepository(m.db)
+	recordRepo := persistence.NewImportRecordRepository(m.db)
+	service := services.NewImportService(jobRepo, recordRepo, m.eventBus, m.importDir())
+	return handlers.NewImportHandler(service)
+}
+
+func (m *Module) importDir() string {
+	return filepath.Join(m.storageConfig.LocalPath, "imports")
+}
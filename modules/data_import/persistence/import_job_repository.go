@@ -0,0 +1,142 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+	"gorm.io/gorm"
+)
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) domain.ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job *domain.ImportJob) error {
+	model := &ImportJobModel{}
+	model.FromDomainImportJob(job)
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	job.ID = model.ID
+	job.CreatedAt = model.CreatedAt
+	job.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+func (r *importJobRepository) Update(ctx context.Context, job *domain.ImportJob) error {
+	model := &ImportJobModel{}
+	model.FromDomainImportJob(job)
+
+	if err := r.db.WithContext(ctx).Save(model).Error; err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	job.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+// Queue moves a job from status to queued for a commit. The status is
+// checked in the update itself, so of two concurrent commits only one
+// queues the job.
+func (r *importJobRepository) Queue(ctx context.Context, job *domain.ImportJob, status string) error {
+	result := r.db.WithContext(ctx).
+		Model(&ImportJobModel{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", job.ID, job.TenantID, status).
+		Updates(map[string]interface{}{
+			"status":              domain.ImportStatusQueued,
+			"rollback_on_failure": job.RollbackOnFailure,
+			"processed_rows":      0,
+			"success_rows":        0,
+			"failed_rows":         0,
+			"row_errors":          JSONRowErrorsModel{},
+			"updated_at":          time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to queue import job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("import has already been committed")
+	}
+
+	job.Status = domain.ImportStatusQueued
+	job.ProcessedRows = 0
+	job.SuccessRows = 0
+	job.FailedRows = 0
+	job.RowErrors = []domain.RowError{}
+
+	return nil
+}
+
+// UpdateProgress writes only the counters so progress can be reported while
+// the row errors are still being collected.
+func (r *importJobRepository) UpdateProgress(ctx context.Context, job *domain.ImportJob) error {
+	err := r.db.WithContext(ctx).
+		Model(&ImportJobModel{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"processed_rows": job.ProcessedRows,
+			"success_rows":   job.SuccessRows,
+			"failed_rows":    job.FailedRows,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update import progress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *importJobRepository) FindByID(ctx context.Context, tenantID, jobID uint64) (*domain.ImportJob, error) {
+	var model ImportJobModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", jobID, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("import job not found")
+		}
+		return nil, fmt.Errorf("failed to find import job: %w", err)
+	}
+
+	return model.ToDomainImportJob(), nil
+}
+
+func (r *importJobRepository) FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.ImportJob, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).
+		Model(&ImportJobModel{}).
+		Where("tenant_id = ?", tenantID).
+		Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count import jobs: %w", err)
+	}
+
+	var models []ImportJobModel
+	err := r.db.WithContext(ctx).
+		Omit("row_errors").
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find import jobs: %w", err)
+	}
+
+	jobs := make([]*domain.ImportJob, len(models))
+	for i := range models {
+		jobs[i] = models[i].ToDomainImportJob()
+	}
+
+	return jobs, total, nil
+}
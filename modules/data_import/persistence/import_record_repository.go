@@ -0,0 +1,509 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type importRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRecordRepository(db *gorm.DB) domain.ImportRecordRepository {
+	return &importRecordRepository{db: db}
+}
+
+func (r *importRecordRepository) LoadReferences(ctx context.Context, tenantID uint64) (*domain.ImportReferences, error) {
+	refs := &domain.ImportReferences{
+		CategoryIDs:   map[string]uint64{},
+		CategoryPaths: map[string]uint64{},
+		ProductIDs:    map[string]uint64{},
+		VariantSKUs:   map[string]bool{},
+		OutletIDs:     map[string]uint64{},
+		CustomerCodes: map[string]bool{},
+	}
+
+	var categories []struct {
+		ID       uint64
+		ParentID *uint64
+		Name     string
+	}
+	if err := r.db.WithContext(ctx).Table("product_categories").
+		Select("id, parent_id, name").Where("tenant_id = ?", tenantID).Order("id").
+		Scan(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	parents := make(map[uint64]*uint64, len(categories))
+	names := make(map[uint64]string, len(categories))
+	for _, category := range categories {
+		key := strings.ToLower(category.Name)
+		if _, exists := refs.CategoryIDs[key]; !exists {
+			refs.CategoryIDs[key] = category.ID
+		}
+		parents[category.ID] = category.ParentID
+		names[category.ID] = category.Name
+	}
+	for _, category := range categories {
+		levels := []string{category.Name}
+		// The depth cap guards against a parent cycle
+		for parent := category.ParentID; parent != nil && len(levels) <= len(categories); parent = parents[*parent] {
+			name, ok := names[*parent]
+			if !ok {
+				break
+			}
+			levels = append([]string{name}, levels...)
+		}
+		key := domain.CategoryPathKey(levels)
+		if _, exists := refs.CategoryPaths[key]; !exists {
+			refs.CategoryPaths[key] = category.ID
+		}
+	}
+
+	var products []struct {
+		ID  uint64
+		SKU string
+	}
+	if err := r.db.WithContext(ctx).Table("products").
+		Select("id, sku").Where("tenant_id = ?", tenantID).
+		Scan(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+	for _, product := range products {
+		refs.ProductIDs[product.SKU] = product.ID
+	}
+
+	// Variant SKUs share the products' namespace
+	var variantSKUs []string
+	if err := r.db.WithContext(ctx).Table("product_variants").
+		Where("tenant_id = ?", tenantID).
+		Pluck("sku", &variantSKUs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+	for _, sku := range variantSKUs {
+		refs.VariantSKUs[sku] = true
+	}
+
+	var outlets []struct {
+		ID   uint64
+		Code string
+	}
+	if err := r.db.WithContext(ctx).Table("outlets").
+		Select("id, code").Where("tenant_id = ?", tenantID).
+		Scan(&outlets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load outlets: %w", err)
+	}
+	for _, outlet := range outlets {
+		refs.OutletIDs[strings.ToLower(outlet.Code)] = outlet.ID
+	}
+
+	var codes []string
+	if err := r.db.WithContext(ctx).Table("customers").
+		Where("tenant_id = ? AND code <> ''", tenantID).
+		Pluck("code", &codes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load customers: %w", err)
+	}
+	for _, code := range codes {
+		refs.CustomerCodes[code] = true
+	}
+
+	return refs, nil
+}
+
+func (r *importRecordRepository) Import(ctx context.Context, job *domain.ImportJob, rows []domain.ImportRow, rollbackOnFailure bool, onRow func(row domain.ImportRow, err error)) error {
+	refs, err := r.LoadReferences(ctx, job.TenantID)
+	if err != nil {
+		return err
+	}
+
+	if rollbackOnFailure {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				err := r.writeRow(tx, job, refs, row)
+				onRow(row, err)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, row := range rows {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return r.writeRow(tx, job, refs, row)
+		})
+		onRow(row, err)
+	}
+
+	return nil
+}
+
+func (r *importRecordRepository) writeRow(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, row domain.ImportRow) error {
+	switch job.ImportType {
+	case domain.ImportTypeCategories:
+		return r.writeCategory(tx, job, refs, row)
+	case domain.ImportTypeProducts:
+		return r.writeProduct(tx, job, refs, row)
+	case domain.ImportTypeCustomers:
+		return r.writeCustomer(tx, job, refs, row)
+	case domain.ImportTypeOpeningStock:
+		return r.writeOpeningStock(tx, job, refs, row)
+	}
+	return fmt.Errorf("unsupported import type: %s", job.ImportType)
+}
+
+func (r *importRecordRepository) writeCategory(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, row domain.ImportRow) error {
+	category := &CategoryModel{
+		TenantID:    job.TenantID,
+		Name:        row.String("name"),
+		Description: row.String("description"),
+		IsActive:    true,
+	}
+
+	if parent := row.String("parent"); parent != "" {
+		parentID, ok := refs.CategoryIDs[strings.ToLower(parent)]
+		if !ok {
+			return errors.New("parent category not found")
+		}
+		category.ParentID = &parentID
+	}
+	if sortOrder, ok := row.Int("sort_order"); ok {
+		category.SortOrder = sortOrder
+	}
+	if isActive, ok := row.Bool("is_active"); ok {
+		category.IsActive = isActive
+	}
+
+	if err := tx.Create(category).Error; err != nil {
+		return err
+	}
+
+	key := strings.ToLower(category.Name)
+	if _, exists := refs.CategoryIDs[key]; !exists {
+		refs.CategoryIDs[key] = category.ID
+	}
+
+	return nil
+}
+
+// writeProduct creates the product, or updates the fields the row fills in
+// when its SKU exists. Categories missing along the row's category path are
+// created on the way.
+func (r *importRecordRepository) writeProduct(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, row domain.ImportRow) error {
+	sku := row.String("sku")
+	if refs.VariantSKUs[sku] {
+		return errors.New("SKU belongs to a product variant")
+	}
+
+	var categoryID *uint64
+	var createdPaths map[string]uint64
+	if category := row.String("category"); category != "" {
+		id, created, err := r.categoryByPath(tx, job, refs, category)
+		if err != nil {
+			return err
+		}
+		categoryID, createdPaths = &id, created
+	}
+
+	var images JSONStringsModel
+	if value := row.String("images"); value != "" {
+		images = domain.ImageURLs(value)
+	}
+
+	// The references were loaded before the import started; products and
+	// variants created since then hold their SKUs and barcodes too. SKUs are
+	// locked before barcodes, as everywhere else.
+	productID, exists := refs.ProductIDs[sku]
+	if !exists {
+		taken, err := database.LockSKU(tx, job.TenantID, sku, nil, nil)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("SKU is already in use")
+		}
+	}
+	if barcode := row.String("barcode"); barcode != "" {
+		var excludeProductID *uint64
+		if exists {
+			excludeProductID = &productID
+		}
+		taken, err := database.LockBarcode(tx, job.TenantID, barcode, excludeProductID, nil)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("barcode is already in use")
+		}
+	}
+
+	if exists {
+		now := time.Now()
+		updates := map[string]interface{}{
+			"name":       row.String("name"),
+			"updated_at": now,
+		}
+		if categoryID != nil {
+			updates["category_id"] = *categoryID
+		}
+		for _, field := range []string{"barcode", "description", "unit"} {
+			if value := row.String(field); value != "" {
+				updates[field] = value
+			}
+		}
+		for _, field := range []string{"cost_price", "selling_price"} {
+			if value, ok := row.Decimal(field); ok {
+				updates[field] = value
+			}
+		}
+		if minStock, ok := row.Decimal("min_stock"); ok {
+			updates["min_stock"] = minStock
+		}
+		for _, field := range []string{"track_stock", "is_active"} {
+			if value, ok := row.Bool(field); ok {
+				updates[field] = value
+			}
+		}
+		if images != nil {
+			updates["images"] = images
+		}
+
+		// The prices before the update go into the price history
+		var current ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "cost_price", "selling_price").
+			Where("id = ? AND tenant_id = ?", productID, job.TenantID).
+			First(&current).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&ProductModel{}).
+			Where("id = ? AND tenant_id = ?", productID, job.TenantID).
+			Updates(updates).Error
+		if err != nil {
+			return err
+		}
+
+		change := &database.ProductPriceChange{
+			TenantID:        job.TenantID,
+			ProductID:       productID,
+			OldCostPrice:    current.CostPrice,
+			NewCostPrice:    current.CostPrice,
+			OldSellingPrice: current.SellingPrice,
+			NewSellingPrice: current.SellingPrice,
+			Source:          database.PriceChangeSourceImport,
+			Reason:          fmt.Sprintf("Product import #%d, row %d", job.ID, row.Row),
+			ChangedBy:       job.CreatedBy,
+			EffectiveAt:     now,
+		}
+		if costPrice, ok := row.Decimal("cost_price"); ok {
+			change.NewCostPrice = costPrice
+		}
+		if sellingPrice, ok := row.Decimal("selling_price"); ok {
+			change.NewSellingPrice = sellingPrice
+		}
+		if change.NewCostPrice != change.OldCostPrice || change.NewSellingPrice != change.OldSellingPrice {
+			if err := database.RecordPriceChange(tx, change); err != nil {
+				return err
+			}
+		}
+	} else {
+		product := &ProductModel{
+			TenantID:    job.TenantID,
+			CategoryID:  categoryID,
+			SKU:         sku,
+			Barcode:     row.String("barcode"),
+			Name:        row.String("name"),
+			Description: row.String("description"),
+			Unit:        row.String("unit"),
+			TrackStock:  true,
+			IsActive:    true,
+			Images:      JSONStringsModel{},
+		}
+
+		if product.Unit == "" {
+			product.Unit = "pcs"
+		}
+		if costPrice, ok := row.Decimal("cost_price"); ok {
+			product.CostPrice = costPrice
+		}
+		if sellingPrice, ok := row.Decimal("selling_price"); ok {
+			product.SellingPrice = sellingPrice
+		}
+		if minStock, ok := row.Decimal("min_stock"); ok {
+			product.MinStock = minStock
+		}
+		if trackStock, ok := row.Bool("track_stock"); ok {
+			product.TrackStock = trackStock
+		}
+		if isActive, ok := row.Bool("is_active"); ok {
+			product.IsActive = isActive
+		}
+		if images != nil {
+			product.Images = images
+		}
+
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		refs.ProductIDs[sku] = product.ID
+	}
+
+	// Only now that the row is written can later rows rely on its categories
+	for key, id := range createdPaths {
+		refs.CategoryPaths[key] = id
+
+		name := key[strings.LastIndex(key, domain.CategoryPathSeparator)+1:]
+		if name = strings.TrimSpace(name); refs.CategoryIDs[name] == 0 {
+			refs.CategoryIDs[name] = id
+		}
+	}
+
+	return nil
+}
+
+// categoryByPath resolves a category path such as "Minuman > Kopi", creating
+// the levels that do not exist yet. A single name that is not a root
+// category also matches a subcategory of that name. The created categories
+// are returned by path key rather than added to refs, since the row may
+// still fail.
+func (r *importRecordRepository) categoryByPath(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, path string) (uint64, map[string]uint64, error) {
+	levels := domain.CategoryPath(path)
+	if len(levels) == 1 {
+		if id, ok := refs.CategoryPaths[domain.CategoryPathKey(levels)]; ok {
+			return id, nil, nil
+		}
+		if id, ok := refs.CategoryIDs[strings.ToLower(levels[0])]; ok {
+			return id, nil, nil
+		}
+	}
+
+	created := map[string]uint64{}
+	var parentID *uint64
+	for i, name := range levels {
+		if name == "" {
+			return 0, nil, errors.New("category path has an empty level")
+		}
+
+		key := domain.CategoryPathKey(levels[:i+1])
+		if id, ok := refs.CategoryPaths[key]; ok {
+			parentID = &id
+			continue
+		}
+
+		category := &CategoryModel{
+			TenantID: job.TenantID,
+			ParentID: parentID,
+			Name:     name,
+			IsActive: true,
+		}
+		if err := tx.Create(category).Error; err != nil {
+			return 0, nil, fmt.Errorf("failed to create category %s: %w", name, err)
+		}
+		created[key] = category.ID
+		parentID = &category.ID
+	}
+
+	return *parentID, created, nil
+}
+
+func (r *importRecordRepository) writeCustomer(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, row domain.ImportRow) error {
+	code := row.String("code")
+	if code == "" {
+		// Imported customers without a code get one that traces back to
+		// the import row
+		code = fmt.Sprintf("IMP%d-%d", job.ID, row.Row)
+	}
+	if refs.CustomerCodes[code] {
+		return errors.New("customer with this code already exists")
+	}
+
+	customer := &CustomerModel{
+		TenantID:   job.TenantID,
+		Code:       code,
+		Name:       row.String("name"),
+		Email:      strings.ToLower(row.String("email")),
+		Phone:      row.String("phone"),
+		Address:    row.String("address"),
+		City:       row.String("city"),
+		Province:   row.String("province"),
+		PostalCode: row.String("postal_code"),
+		Notes:      row.String("notes"),
+		IsActive:   true,
+	}
+
+	if birthDate, ok := row.Date("birth_date"); ok {
+		customer.BirthDate = birthDate
+	}
+	if gender := row.String("gender"); gender != "" {
+		customer.Gender = &gender
+	}
+	if points, ok := row.Int("loyalty_points"); ok {
+		customer.LoyaltyPoints = points
+	}
+
+	if err := tx.Create(customer).Error; err != nil {
+		return err
+	}
+
+	refs.CustomerCodes[code] = true
+	return nil
+}
+
+func (r *importRecordRepository) writeOpeningStock(tx *gorm.DB, job *domain.ImportJob, refs *domain.ImportReferences, row domain.ImportRow) error {
+	productID, ok := refs.ProductIDs[row.String("sku")]
+	if !ok {
+		return errors.New("product not found")
+	}
+	outletID, ok := refs.OutletIDs[strings.ToLower(row.String("outlet_code"))]
+	if !ok {
+		return errors.New("outlet not found")
+	}
+	quantity, _ := row.Decimal("quantity")
+
+	stock, err := stockledger.LockStockRow(tx, productID, nil, outletID)
+	if err != nil {
+		return err
+	}
+
+	difference := stockledger.RoundQuantity(quantity - stock.Quantity)
+	if difference == 0 {
+		return nil
+	}
+
+	// The difference moves like the initial stock of a new product: stock
+	// coming in at the product's cost price, or going out of its layers and
+	// lots
+	change := stockledger.Change{
+		ProductID:     productID,
+		OutletID:      outletID,
+		Quantity:      difference,
+		MovementType:  "in",
+		ReferenceType: "initial",
+		ReferenceID:   job.ID,
+		Notes:         fmt.Sprintf("Opening stock import #%d, row %d", job.ID, row.Row),
+		CreatedBy:     job.CreatedBy,
+	}
+	if difference > 0 {
+		var costPrice float64
+		if err := tx.Table("products").Select("cost_price").Where("id = ?", productID).Scan(&costPrice).Error; err != nil {
+			return fmt.Errorf("failed to find product cost price: %w", err)
+		}
+		change.UnitCost = &costPrice
+	} else {
+		change.MovementType = "out"
+	}
+
+	_, err = stockledger.Apply(tx, change)
+	return err
+}
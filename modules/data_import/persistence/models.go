@@ -0,0 +1,224 @@
+package persistence
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+)
+
+type JSONStringsModel []string
+
+func (j JSONStringsModel) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+func (j *JSONStringsModel) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type JSONMappingModel map[string]string
+
+func (j JSONMappingModel) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+func (j *JSONMappingModel) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type RowErrorModel struct {
+	Row    int                 `json:"row"`
+	Errors map[string][]string `json:"errors"`
+}
+
+type JSONRowErrorsModel []RowErrorModel
+
+func (j JSONRowErrorsModel) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+func (j *JSONRowErrorsModel) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type ImportJobModel struct {
+	ID                uint64             `gorm:"primaryKey;autoIncrement"`
+	TenantID          uint64             `gorm:"not null"`
+	CreatedBy         uint64             `gorm:"not null"`
+	ImportType        string             `gorm:"size:30;not null"`
+	FileName          string             `gorm:"size:255;not null"`
+	FilePath          string             `gorm:"size:500;not null"`
+	FileFormat        string             `gorm:"size:10;not null"`
+	Status            string             `gorm:"size:30;not null"`
+	Headers           JSONStringsModel   `gorm:"type:jsonb"`
+	Mapping           JSONMappingModel   `gorm:"type:jsonb"`
+	TotalRows         int                `gorm:"default:0"`
+	ProcessedRows     int                `gorm:"default:0"`
+	SuccessRows       int                `gorm:"default:0"`
+	FailedRows        int                `gorm:"default:0"`
+	RollbackOnFailure bool               `gorm:"default:false"`
+	RowErrors         JSONRowErrorsModel `gorm:"type:jsonb"`
+	ErrorMessage      string             `gorm:"type:text"`
+	StartedAt         *time.Time
+	CompletedAt       *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ImportJobModel) TableName() string {
+	return "import_jobs"
+}
+
+// Target table models, limited to the columns an import writes
+
+type CategoryModel struct {
+	ID          uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64 `gorm:"not null"`
+	ParentID    *uint64
+	Name        string    `gorm:"size:255;not null"`
+	Description string    `gorm:"type:text"`
+	SortOrder   int       `gorm:"default:0"`
+	IsActive    bool      `gorm:"default:true"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (CategoryModel) TableName() string {
+	return "product_categories"
+}
+
+type ProductModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64 `gorm:"not null"`
+	CategoryID   *uint64
+	SKU          string           `gorm:"size:100;not null"`
+	Barcode      string           `gorm:"size:100"`
+	Name         string           `gorm:"size:255;not null"`
+	Description  string           `gorm:"type:text"`
+	Unit         string           `gorm:"size:50"`
+	CostPrice    float64          `gorm:"type:decimal(12,2)"`
+	SellingPrice float64          `gorm:"type:decimal(12,2);not null"`
+	MinStock     float64          `gorm:"type:decimal(15,3);default:0"`
+	TrackStock   bool             `gorm:"default:true"`
+	IsActive     bool             `gorm:"default:true"`
+	Images       JSONStringsModel `gorm:"type:jsonb"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time        `gorm:"autoUpdateTime"`
+}
+
+func (ProductModel) TableName() string {
+	return "products"
+}
+
+type CustomerModel struct {
+	ID            uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID      uint64 `gorm:"not null"`
+	Code          string `gorm:"size:50"`
+	Name          string `gorm:"size:255;not null"`
+	Email         string `gorm:"size:255"`
+	Phone         string `gorm:"size:20"`
+	Address       string `gorm:"type:text"`
+	City          string `gorm:"size:100"`
+	Province      string `gorm:"size:100"`
+	PostalCode    string `gorm:"size:10"`
+	BirthDate     *time.Time
+	Gender        *string
+	LoyaltyPoints int       `gorm:"default:0"`
+	Notes         string    `gorm:"type:text"`
+	IsActive      bool      `gorm:"default:true"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (CustomerModel) TableName() string {
+	return "customers"
+}
+
+// Mapper functions
+
+func (m *ImportJobModel) ToDomainImportJob() *domain.ImportJob {
+	rowErrors := make([]domain.RowError, len(m.RowErrors))
+	for i, rowError := range m.RowErrors {
+		rowErrors[i] = domain.RowError{Row: rowError.Row, Errors: rowError.Errors}
+	}
+
+	return &domain.ImportJob{
+		ID:                m.ID,
+		TenantID:          m.TenantID,
+		CreatedBy:         m.CreatedBy,
+		ImportType:        m.ImportType,
+		FileName:          m.FileName,
+		FilePath:          m.FilePath,
+		FileFormat:        m.FileFormat,
+		Status:            m.Status,
+		Headers:           []string(m.Headers),
+		Mapping:           map[string]string(m.Mapping),
+		TotalRows:         m.TotalRows,
+		ProcessedRows:     m.ProcessedRows,
+		SuccessRows:       m.SuccessRows,
+		FailedRows:        m.FailedRows,
+		RollbackOnFailure: m.RollbackOnFailure,
+		RowErrors:         rowErrors,
+		ErrorMessage:      m.ErrorMessage,
+		StartedAt:         m.StartedAt,
+		CompletedAt:       m.CompletedAt,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
+
+func (m *ImportJobModel) FromDomainImportJob(job *domain.ImportJob) {
+	rowErrors := make(JSONRowErrorsModel, len(job.RowErrors))
+	for i, rowError := range job.RowErrors {
+		rowErrors[i] = RowErrorModel{Row: rowError.Row, Errors: rowError.Errors}
+	}
+
+	m.ID = job.ID
+	m.TenantID = job.TenantID
+	m.CreatedBy = job.CreatedBy
+	m.ImportType = job.ImportType
+	m.FileName = job.FileName
+	m.FilePath = job.FilePath
+	m.FileFormat = job.FileFormat
+	m.Status = job.Status
+	m.Headers = JSONStringsModel(job.Headers)
+	m.Mapping = JSONMappingModel(job.Mapping)
+	m.TotalRows = job.TotalRows
+	m.ProcessedRows = job.ProcessedRows
+	m.SuccessRows = job.SuccessRows
+	m.FailedRows = job.FailedRows
+	m.RollbackOnFailure = job.RollbackOnFailure
+	m.RowErrors = rowErrors
+	m.ErrorMessage = job.ErrorMessage
+	m.StartedAt = job.StartedAt
+	m.CompletedAt = job.CompletedAt
+	m.CreatedAt = job.CreatedAt
+	m.UpdatedAt = job.UpdatedAt
+}
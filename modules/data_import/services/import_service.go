@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"github.com/exven/pos-system/shared/utils/spreadsheet"
+)
+
+const (
+	// maxImportRows bounds the data rows of one file
+	maxImportRows = 20000
+	// maxStoredRowErrors bounds the row errors kept on a job
+	maxStoredRowErrors = 1000
+	// progressInterval is how many rows are processed between progress writes
+	progressInterval = 50
+	sampleRowCount   = 5
+	importTimeout    = time.Hour
+)
+
+type importService struct {
+	jobRepo    domain.ImportJobRepository
+	recordRepo domain.ImportRecordRepository
+	eventBus   messaging.EventBus
+	importDir  string
+}
+
+func NewImportService(
+	jobRepo domain.ImportJobRepository,
+	recordRepo domain.ImportRecordRepository,
+	eventBus messaging.EventBus,
+	importDir string,
+) domain.ImportService {
+	return &importService{
+		jobRepo:    jobRepo,
+		recordRepo: recordRepo,
+		eventBus:   eventBus,
+		importDir:  importDir,
+	}
+}
+
+func (s *importService) Upload(ctx context.Context, tenantID, userID uint64, importType, fileName string, file io.Reader) (*domain.ImportJob, [][]string, error) {
+	if _, ok := domain.ImportFields[importType]; !ok {
+		return nil, nil, errors.New("unsupported import type")
+	}
+
+	format := spreadsheet.FormatFromFilename(fileName)
+	if format == "" {
+		return nil, nil, errors.New("file must be a CSV or XLSX spreadsheet")
+	}
+
+	dir := filepath.Join(s.importDir, fmt.Sprintf("%d", tenantID))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, nil, fmt.Errorf("failed to create import directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%d.%s", time.Now().UnixNano(), format))
+	if err := saveFile(path, file); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := spreadsheet.ReadFile(path, format)
+	if err != nil {
+		os.Remove(path)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(rows) < 2 {
+		os.Remove(path)
+		return nil, nil, errors.New("file must have a header row and at least one data row")
+	}
+	if len(rows)-1 > maxImportRows {
+		os.Remove(path)
+		return nil, nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+	}
+
+	headers := make([]string, len(rows[0]))
+	for i, header := range rows[0] {
+		headers[i] = strings.TrimSpace(header)
+	}
+
+	job := &domain.ImportJob{
+		TenantID:   tenantID,
+		CreatedBy:  userID,
+		ImportType: importType,
+		FileName:   filepath.Base(fileName),
+		FilePath:   path,
+		FileFormat: format,
+		Status:     domain.ImportStatusUploaded,
+		Headers:    headers,
+		Mapping:    suggestMapping(importType, headers),
+		TotalRows:  len(rows) - 1,
+		RowErrors:  []domain.RowError{},
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		os.Remove(path)
+		return nil, nil, err
+	}
+
+	sample := rows[1:]
+	if len(sample) > sampleRowCount {
+		sample = sample[:sampleRowCount]
+	}
+
+	return job, sample, nil
+}
+
+func (s *importService) GetJob(ctx context.Context, tenantID, jobID uint64) (*domain.ImportJob, error) {
+	return s.jobRepo.FindByID(ctx, tenantID, jobID)
+}
+
+func (s *importService) GetJobs(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.ImportJob, int64, error) {
+	return s.jobRepo.FindByTenant(ctx, tenantID, limit, offset)
+}
+
+func (s *importService) Validate(ctx context.Context, tenantID, jobID uint64, req domain.ValidateImportRequest) (*domain.ImportJob, int, error) {
+	job, err := s.jobRepo.FindByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if job.IsRunning() || job.CompletedAt != nil {
+		return nil, 0, errors.New("import has already been committed")
+	}
+
+	job.Mapping = req.Mapping
+	validRows, rowErrors, err := s.prepareRows(ctx, job)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	job.RowErrors = rowErrors
+	job.Status = domain.ImportStatusValidated
+	if len(rowErrors) > 0 {
+		job.Status = domain.ImportStatusInvalid
+	}
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, 0, err
+	}
+
+	return job, len(validRows), nil
+}
+
+func (s *importService) Commit(ctx context.Context, tenantID, jobID uint64, req domain.CommitImportRequest) (*domain.ImportJob, error) {
+	job, err := s.jobRepo.FindByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case domain.ImportStatusValidated:
+	case domain.ImportStatusInvalid:
+		if req.RollbackOnFailure {
+			return nil, errors.New("import has validation errors")
+		}
+	case domain.ImportStatusUploaded:
+		return nil, errors.New("import must be validated first")
+	default:
+		return nil, errors.New("import has already been committed")
+	}
+
+	job.RollbackOnFailure = req.RollbackOnFailure
+	if err := s.jobRepo.Queue(ctx, job, job.Status); err != nil {
+		return nil, err
+	}
+
+	// The import outlives the request, so it runs on its own context
+	go s.runImport(*job)
+
+	return job, nil
+}
+
+func (s *importService) runImport(job domain.ImportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	job.Status = domain.ImportStatusProcessing
+	job.StartedAt = &startedAt
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		log.Printf("import job %d: failed to mark processing: %v", job.ID, err)
+		return
+	}
+
+	// Rows are checked again since the tenant's data may have changed
+	// since the dry run
+	rows, rowErrors, err := s.prepareRows(ctx, &job)
+	if err != nil {
+		s.finishImport(ctx, &job, err)
+		return
+	}
+
+	for _, rowError := range rowErrors {
+		s.addRowError(&job, rowError)
+	}
+	job.ProcessedRows = len(rowErrors)
+	job.FailedRows = len(rowErrors)
+
+	if job.RollbackOnFailure && len(rowErrors) > 0 {
+		s.finishImport(ctx, &job, errors.New("import has validation errors, nothing was imported"))
+		return
+	}
+
+	err = s.recordRepo.Import(ctx, &job, rows, job.RollbackOnFailure, func(row domain.ImportRow, rowErr error) {
+		job.ProcessedRows++
+		if rowErr != nil {
+			job.FailedRows++
+			s.addRowError(&job, domain.RowError{
+				Row:    row.Row,
+				Errors: map[string][]string{"row": {rowErr.Error()}},
+			})
+		} else {
+			job.SuccessRows++
+		}
+
+		if job.ProcessedRows%progressInterval == 0 {
+			if err := s.jobRepo.UpdateProgress(ctx, &job); err != nil {
+				log.Printf("import job %d: %v", job.ID, err)
+			}
+		}
+	})
+	if err != nil && job.RollbackOnFailure {
+		// Nothing from the rolled back transaction was kept
+		job.SuccessRows = 0
+		err = fmt.Errorf("import rolled back: %w", err)
+	}
+
+	s.finishImport(ctx, &job, err)
+}
+
+func (s *importService) finishImport(ctx context.Context, job *domain.ImportJob, importErr error) {
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+
+	switch {
+	case importErr != nil:
+		job.Status = domain.ImportStatusFailed
+		job.ErrorMessage = importErr.Error()
+	case job.FailedRows > 0:
+		job.Status = domain.ImportStatusCompletedWithErrors
+	default:
+		job.Status = domain.ImportStatusCompleted
+	}
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("import job %d: failed to save result: %v", job.ID, err)
+		return
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("import.completed", job.TenantID, job.CreatedBy, map[string]interface{}{
+			"import_id":    job.ID,
+			"import_type":  job.ImportType,
+			"status":       job.Status,
+			"success_rows": job.SuccessRows,
+			"failed_rows":  job.FailedRows,
+		})
+		s.eventBus.Publish(ctx, "import.completed", event)
+	}
+}
+
+// prepareRows reads the job file, applies the column mapping and checks every
+// row. It returns the rows that passed and the errors of those that did not.
+func (s *importService) prepareRows(ctx context.Context, job *domain.ImportJob) ([]domain.ImportRow, []domain.RowError, error) {
+	cells, err := spreadsheet.ReadFile(job.FilePath, job.FileFormat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(cells) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+
+	columns, err := resolveMapping(job.ImportType, job.Headers, job.Mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs, err := s.recordRepo.LoadReferences(ctx, job.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checker := newRowChecker(job.ImportType, refs)
+	rows := make([]domain.ImportRow, 0, len(cells)-1)
+	rowErrors := []domain.RowError{}
+
+	for i, rowCells := range cells[1:] {
+		line := i + 2
+		row, errs := parseRow(job.ImportType, line, rowCells, columns)
+		checker.check(row, errs)
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, domain.RowError{Row: line, Errors: errs})
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+func (s *importService) addRowError(job *domain.ImportJob, rowError domain.RowError) {
+	if len(job.RowErrors) < maxStoredRowErrors {
+		job.RowErrors = append(job.RowErrors, rowError)
+	}
+}
+
+func saveFile(path string, file io.Reader) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, file); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+)
+
+// rowChecker applies the checks that need the tenant's existing records or
+// the earlier rows of the file, such as duplicate SKUs and unknown outlets.
+type rowChecker struct {
+	importType string
+	refs       *domain.ImportReferences
+	// seen maps a unique key to the first line it appeared on
+	seen map[string]int
+	// categories holds category names created by earlier rows
+	categories map[string]bool
+}
+
+func newRowChecker(importType string, refs *domain.ImportReferences) *rowChecker {
+	return &rowChecker{
+		importType: importType,
+		refs:       refs,
+		seen:       map[string]int{},
+		categories: map[string]bool{},
+	}
+}
+
+func (c *rowChecker) check(row domain.ImportRow, errs map[string][]string) {
+	switch c.importType {
+	case domain.ImportTypeCategories:
+		c.checkCategory(row, errs)
+	case domain.ImportTypeProducts:
+		c.checkProduct(row, errs)
+	case domain.ImportTypeCustomers:
+		c.checkCustomer(row, errs)
+	case domain.ImportTypeOpeningStock:
+		c.checkOpeningStock(row, errs)
+	}
+}
+
+func (c *rowChecker) checkCategory(row domain.ImportRow, errs map[string][]string) {
+	name := strings.ToLower(row.String("name"))
+	if name != "" {
+		if _, exists := c.refs.CategoryIDs[name]; exists {
+			errs["name"] = append(errs["name"], "Category already exists")
+		} else if line, dup := c.seen[name]; dup {
+			errs["name"] = append(errs["name"], fmt.Sprintf("Duplicate category in row %d", line))
+		} else {
+			c.seen[name] = row.Row
+		}
+	}
+
+	if parent := strings.ToLower(row.String("parent")); parent != "" {
+		_, exists := c.refs.CategoryIDs[parent]
+		if !exists && !c.categories[parent] {
+			errs["parent"] = append(errs["parent"], "Parent category not found; list parents before their subcategories")
+		}
+	}
+
+	if len(errs) == 0 && name != "" {
+		c.categories[name] = true
+	}
+}
+
+// checkProduct allows existing SKUs, which the import updates, and
+// categories that do not exist yet, which it creates
+func (c *rowChecker) checkProduct(row domain.ImportRow, errs map[string][]string) {
+	if sku := row.String("sku"); sku != "" {
+		if c.refs.VariantSKUs[sku] {
+			errs["sku"] = append(errs["sku"], "SKU belongs to a product variant")
+		} else if line, dup := c.seen[sku]; dup {
+			errs["sku"] = append(errs["sku"], fmt.Sprintf("Duplicate SKU in row %d", line))
+		} else {
+			c.seen[sku] = row.Row
+		}
+	}
+
+	if category := row.String("category"); category != "" {
+		for _, level := range domain.CategoryPath(category) {
+			if level == "" {
+				errs["category"] = append(errs["category"], "Category path has an empty level")
+				break
+			}
+			if len(level) > 255 {
+				errs["category"] = append(errs["category"], "Category names must be at most 255 characters")
+				break
+			}
+		}
+	}
+
+	for _, image := range domain.ImageURLs(row.String("images")) {
+		if !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") {
+			errs["images"] = append(errs["images"], "Images must be http or https URLs")
+			break
+		}
+	}
+}
+
+func (c *rowChecker) checkCustomer(row domain.ImportRow, errs map[string][]string) {
+	if code := row.String("code"); code != "" {
+		if c.refs.CustomerCodes[code] {
+			errs["code"] = append(errs["code"], "Customer with this code already exists")
+		} else if line, dup := c.seen[code]; dup {
+			errs["code"] = append(errs["code"], fmt.Sprintf("Duplicate code in row %d", line))
+		} else {
+			c.seen[code] = row.Row
+		}
+	}
+
+	if email := row.String("email"); email != "" && !isValidEmail(email) {
+		errs["email"] = append(errs["email"], "Invalid email format")
+	}
+}
+
+func (c *rowChecker) checkOpeningStock(row domain.ImportRow, errs map[string][]string) {
+	sku := row.String("sku")
+	if sku != "" {
+		if _, exists := c.refs.ProductIDs[sku]; !exists {
+			errs["sku"] = append(errs["sku"], "Product not found")
+		}
+	}
+
+	outletCode := strings.ToLower(row.String("outlet_code"))
+	if outletCode != "" {
+		if _, exists := c.refs.OutletIDs[outletCode]; !exists {
+			errs["outlet_code"] = append(errs["outlet_code"], "Outlet not found")
+		}
+	}
+
+	if sku != "" && outletCode != "" {
+		key := sku + "\x00" + outletCode
+		if line, dup := c.seen[key]; dup {
+			errs["sku"] = append(errs["sku"], fmt.Sprintf("Duplicate product and outlet in row %d", line))
+		} else {
+			c.seen[key] = row.Row
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/exven/pos-system/modules/data_import/domain"
+)
+
+// excelEpoch is day zero of spreadsheet date serial numbers.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006", "2-1-2006", "2006/01/02"}
+
+var boolValues = map[string]bool{
+	"true": true, "yes": true, "y": true, "1": true, "ya": true, "active": true, "aktif": true,
+	"false": false, "no": false, "n": false, "0": false, "tidak": false, "inactive": false, "nonaktif": false,
+}
+
+// normalizeHeader lowercases a header and drops everything but letters and
+// digits, so "Harga Jual", "harga_jual" and "HargaJual" compare equal.
+func normalizeHeader(header string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// suggestMapping matches spreadsheet headers to import fields by name and
+// alias.
+func suggestMapping(importType string, headers []string) map[string]string {
+	mapping := map[string]string{}
+	for _, field := range domain.ImportFields[importType] {
+		candidates := append([]string{normalizeHeader(field.Name)}, field.Aliases...)
+		for _, header := range headers {
+			normalized := normalizeHeader(header)
+			for _, candidate := range candidates {
+				if normalized == candidate {
+					mapping[field.Name] = header
+					break
+				}
+			}
+			if _, ok := mapping[field.Name]; ok {
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// resolveMapping checks a column mapping against the import fields and the
+// file headers and returns the column index of every mapped field.
+func resolveMapping(importType string, headers []string, mapping map[string]string) (map[string]int, error) {
+	fields := domain.ImportFields[importType]
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.Name] = true
+	}
+
+	headerIndex := make(map[string]int, len(headers))
+	for i, header := range headers {
+		headerIndex[strings.TrimSpace(header)] = i
+	}
+
+	errs := domain.FieldErrors{}
+	columns := map[string]int{}
+	for field, header := range mapping {
+		if !known[field] {
+			errs[field] = append(errs[field], field+" is not a field of this import")
+			continue
+		}
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		index, ok := headerIndex[strings.TrimSpace(header)]
+		if !ok {
+			errs[field] = append(errs[field], fmt.Sprintf("Column %q not found in file", header))
+			continue
+		}
+		columns[field] = index
+	}
+
+	for _, field := range fields {
+		if _, ok := columns[field.Name]; field.Required && !ok && len(errs[field.Name]) == 0 {
+			errs[field.Name] = append(errs[field.Name], field.Name+" must be mapped to a column")
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return columns, nil
+}
+
+// parseRow converts the mapped cells of a row to typed values. line is the
+// 1-based line in the file.
+func parseRow(importType string, line int, cells []string, columns map[string]int) (domain.ImportRow, map[string][]string) {
+	row := domain.ImportRow{Row: line, Values: map[string]interface{}{}}
+	errs := map[string][]string{}
+
+	for _, field := range domain.ImportFields[importType] {
+		index, mapped := columns[field.Name]
+		raw := ""
+		if mapped && index < len(cells) {
+			raw = strings.TrimSpace(cells[index])
+		}
+
+		if raw == "" {
+			if field.Required {
+				errs[field.Name] = append(errs[field.Name], field.Name+" is required")
+			}
+			continue
+		}
+
+		value, message := parseValue(field, raw)
+		if message != "" {
+			errs[field.Name] = append(errs[field.Name], message)
+			continue
+		}
+		row.Values[field.Name] = value
+	}
+
+	return row, errs
+}
+
+func parseValue(field domain.FieldSpec, raw string) (interface{}, string) {
+	switch field.Kind {
+	case domain.FieldKindInt:
+		number, err := parseNumber(raw)
+		if err != nil || number != float64(int(number)) {
+			return nil, field.Name + " must be a whole number"
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, fmt.Sprintf("%s must be at least %g", field.Name, *field.Min)
+		}
+		return int(number), ""
+	case domain.FieldKindDecimal:
+		number, err := parseNumber(raw)
+		if err != nil {
+			return nil, field.Name + " must be a number"
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, fmt.Sprintf("%s must be at least %g", field.Name, *field.Min)
+		}
+		return number, ""
+	case domain.FieldKindBool:
+		value, ok := boolValues[strings.ToLower(raw)]
+		if !ok {
+			return nil, field.Name + " must be true or false"
+		}
+		return value, ""
+	case domain.FieldKindDate:
+		date, ok := parseDate(raw)
+		if !ok {
+			return nil, field.Name + " must be a date (YYYY-MM-DD or DD/MM/YYYY)"
+		}
+		return date, ""
+	}
+
+	if field.MaxLen > 0 && len([]rune(raw)) > field.MaxLen {
+		return nil, fmt.Sprintf("%s must be at most %d characters", field.Name, field.MaxLen)
+	}
+	if len(field.OneOf) > 0 {
+		value := strings.ToLower(raw)
+		for _, option := range field.OneOf {
+			if value == option {
+				return value, ""
+			}
+		}
+		return nil, fmt.Sprintf("%s must be one of %s", field.Name, strings.Join(field.OneOf, ", "))
+	}
+	return raw, ""
+}
+
+// parseNumber accepts plain numbers as well as locale formatted amounts such
+// as "Rp 15.000", "15,000.50" and "15.000,50".
+func parseNumber(raw string) (float64, error) {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "Rp"), "$")
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+
+	lastDot := strings.LastIndex(value, ".")
+	lastComma := strings.LastIndex(value, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastComma >= 0:
+		// A single comma followed by one or two digits is a decimal comma,
+		// anything else groups thousands
+		if strings.Count(value, ",") == 1 && len(value)-lastComma-1 <= 2 {
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case strings.Count(value, ".") > 1:
+		value = strings.ReplaceAll(value, ".", "")
+	case lastDot >= 0 && len(value)-lastDot-1 == 3 && !strings.HasPrefix(value, "0."):
+		// "15.000" is fifteen thousand in Indonesian spreadsheets
+		value = strings.ReplaceAll(value, ".", "")
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+func parseDate(raw string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, raw); err == nil {
+			return date, true
+		}
+	}
+
+	// XLSX stores dates as day serial numbers
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil && serial > 0 && serial < 2958466 {
+		return excelEpoch.AddDate(0, 0, int(serial)), true
+	}
+
+	return time.Time{}, false
+}
+
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
@@ -0,0 +1,474 @@
+package domain
+
+import "time"
+
+type StockQuery struct {
+	OutletID   *uint64 `query:"outlet_id"`
+	CategoryID *uint64 `query:"category_id"`
+	ProductID  *uint64 `query:"product_id"`
+	Search     string  `query:"search"`
+	LowStock   bool    `query:"low_stock"`
+	ZeroStock  bool    `query:"zero_stock"`
+}
+
+type StockLevelResponse struct {
+	ProductID         uint64  `json:"product_id"`
+	SKU               string  `json:"sku"`
+	Barcode           string  `json:"barcode"`
+	ProductName       string  `json:"product_name"`
+	Unit              string  `json:"unit"`
+	CategoryID        *uint64 `json:"category_id"`
+	CategoryName      string  `json:"category_name"`
+	OutletID          uint64  `json:"outlet_id"`
+	OutletName        string  `json:"outlet_name"`
+	OutletCode        string  `json:"outlet_code"`
+	Quantity          float64 `json:"quantity"`
+	ReservedQuantity  float64 `json:"reserved_quantity"`
+	AvailableQuantity float64 `json:"available_quantity"`
+	MinStock          float64 `json:"min_stock"`
+	IsLowStock        bool    `json:"is_low_stock"`
+	IsOutOfStock      bool    `json:"is_out_of_stock"`
+	UpdatedAt         *string `json:"updated_at"`
+}
+
+type ProductStockResponse struct {
+	ProductID              uint64               `json:"product_id"`
+	SKU                    string               `json:"sku"`
+	ProductName            string               `json:"product_name"`
+	Unit                   string               `json:"unit"`
+	MinStock               float64              `json:"min_stock"`
+	TotalQuantity          float64              `json:"total_quantity"`
+	TotalReservedQuantity  float64              `json:"total_reserved_quantity"`
+	TotalAvailableQuantity float64              `json:"total_available_quantity"`
+	Outlets                []StockLevelResponse `json:"outlets"`
+}
+
+type AdjustmentQuery struct {
+	OutletID *uint64 `query:"outlet_id"`
+	Status   string  `query:"status"`
+}
+
+type CreateAdjustmentRequest struct {
+	OutletID uint64                  `json:"outlet_id" validate:"required"`
+	Notes    string                  `json:"notes" validate:"max=1000"`
+	Items    []AdjustmentItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+type AdjustmentItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required"`
+	Reason    string  `json:"reason" validate:"required,oneof=breakage theft expiry count_correction found other"`
+	Notes     string  `json:"notes" validate:"max=500"`
+}
+
+type RejectAdjustmentRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type StockAdjustmentResponse struct {
+	ID               uint64                        `json:"id"`
+	AdjustmentNumber string                        `json:"adjustment_number"`
+	OutletID         uint64                        `json:"outlet_id"`
+	OutletName       string                        `json:"outlet_name"`
+	Status           string                        `json:"status"`
+	TotalValue       float64                       `json:"total_value"`
+	Notes            string                        `json:"notes"`
+	CreatedBy        uint64                        `json:"created_by"`
+	ReviewedBy       *uint64                       `json:"reviewed_by"`
+	ReviewedAt       *string                       `json:"reviewed_at"`
+	RejectionReason  string                        `json:"rejection_reason,omitempty"`
+	Items            []StockAdjustmentItemResponse `json:"items,omitempty"`
+	CreatedAt        string                        `json:"created_at"`
+	UpdatedAt        string                        `json:"updated_at"`
+}
+
+type StockAdjustmentItemResponse struct {
+	ID          uint64  `json:"id"`
+	ProductID   uint64  `json:"product_id"`
+	SKU         string  `json:"sku"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`
+	Reason      string  `json:"reason"`
+	UnitCost    float64 `json:"unit_cost"`
+	Value       float64 `json:"value"`
+	Notes       string  `json:"notes"`
+}
+
+type TransferQuery struct {
+	OutletID *uint64 `query:"outlet_id"`
+	Status   string  `query:"status"`
+}
+
+type CreateTransferRequest struct {
+	SourceOutletID      uint64                `json:"source_outlet_id" validate:"required"`
+	DestinationOutletID uint64                `json:"destination_outlet_id" validate:"required,nefield=SourceOutletID"`
+	Notes               string                `json:"notes" validate:"max=1000"`
+	Items               []TransferItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+type UpdateTransferRequest struct {
+	Notes string                `json:"notes" validate:"max=1000"`
+	Items []TransferItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// TransferItemRequest is one product to transfer. Its quantity is in Unit,
+// the product's base unit when empty.
+type TransferItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required"`
+	Unit      string  `json:"unit" validate:"max=50"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type ReceiveTransferRequest struct {
+	Items    []ReceiveTransferItemRequest `json:"items" validate:"omitempty,max=200,dive"`
+	Complete bool                         `json:"complete"`
+}
+
+// ReceiveTransferItemRequest is a received quantity in the unit of the
+// product's transfer item
+type ReceiveTransferItemRequest struct {
+	ProductID         uint64  `json:"product_id" validate:"required"`
+	Quantity          float64 `json:"quantity" validate:"min=0"`
+	DiscrepancyReason string  `json:"discrepancy_reason" validate:"max=500"`
+}
+
+type StockTransferResponse struct {
+	ID                    uint64                      `json:"id"`
+	TransferNumber        string                      `json:"transfer_number"`
+	SourceOutletID        uint64                      `json:"source_outlet_id"`
+	SourceOutletName      string                      `json:"source_outlet_name"`
+	DestinationOutletID   uint64                      `json:"destination_outlet_id"`
+	DestinationOutletName string                      `json:"destination_outlet_name"`
+	Status                string                      `json:"status"`
+	Notes                 string                      `json:"notes"`
+	CreatedBy             uint64                      `json:"created_by"`
+	DispatchedBy          *uint64                     `json:"dispatched_by"`
+	DispatchedAt          *string                     `json:"dispatched_at"`
+	ReceivedBy            *uint64                     `json:"received_by"`
+	ReceivedAt            *string                     `json:"received_at"`
+	Items                 []StockTransferItemResponse `json:"items,omitempty"`
+	CreatedAt             string                      `json:"created_at"`
+	UpdatedAt             string                      `json:"updated_at"`
+}
+
+type StockTransferItemResponse struct {
+	ID                  uint64  `json:"id"`
+	ProductID           uint64  `json:"product_id"`
+	SKU                 string  `json:"sku"`
+	ProductName         string  `json:"product_name"`
+	Unit                string  `json:"unit"`
+	UnitFactor          float64 `json:"unit_factor"`
+	Quantity            float64 `json:"quantity"`
+	ReceivedQuantity    float64 `json:"received_quantity"`
+	OutstandingQuantity float64 `json:"outstanding_quantity"`
+	DiscrepancyReason   string  `json:"discrepancy_reason,omitempty"`
+}
+
+type StocktakeQuery struct {
+	OutletID *uint64 `query:"outlet_id"`
+	Status   string  `query:"status"`
+}
+
+// StocktakeItemQuery filters the variance report. Status is one of counted,
+// uncounted or variance.
+type StocktakeItemQuery struct {
+	Status string `query:"status"`
+	Search string `query:"search"`
+}
+
+type CreateStocktakeRequest struct {
+	OutletID   uint64  `json:"outlet_id" validate:"required"`
+	CategoryID *uint64 `json:"category_id"`
+	Notes      string  `json:"notes" validate:"max=1000"`
+}
+
+type RecordStocktakeCountsRequest struct {
+	DeviceID string                      `json:"device_id" validate:"max=100"`
+	Items    []StocktakeCountItemRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+// StocktakeCountItemRequest identifies the product by ID or by a scanned
+// barcode. Negative quantities correct earlier counts.
+type StocktakeCountItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required_without=Barcode"`
+	Barcode   string  `json:"barcode" validate:"required_without=ProductID,max=100"`
+	Quantity  float64 `json:"quantity" validate:"required"`
+}
+
+type FinalizeStocktakeRequest struct {
+	ZeroUncounted bool `json:"zero_uncounted"`
+}
+
+type StocktakeResponse struct {
+	ID               uint64  `json:"id"`
+	StocktakeNumber  string  `json:"stocktake_number"`
+	OutletID         uint64  `json:"outlet_id"`
+	OutletName       string  `json:"outlet_name"`
+	CategoryID       *uint64 `json:"category_id"`
+	CategoryName     string  `json:"category_name"`
+	Status           string  `json:"status"`
+	Notes            string  `json:"notes"`
+	ItemCount        int     `json:"item_count"`
+	CountedCount     int     `json:"counted_count"`
+	UncountedCount   int     `json:"uncounted_count"`
+	VarianceCount    int     `json:"variance_count"`
+	ShortageValue    float64 `json:"shortage_value"`
+	SurplusValue     float64 `json:"surplus_value"`
+	NetVarianceValue float64 `json:"net_variance_value"`
+	CreatedBy        uint64  `json:"created_by"`
+	FinalizedBy      *uint64 `json:"finalized_by"`
+	FinalizedAt      *string `json:"finalized_at"`
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
+}
+
+type StocktakeItemResponse struct {
+	ID               uint64   `json:"id"`
+	ProductID        uint64   `json:"product_id"`
+	SKU              string   `json:"sku"`
+	Barcode          string   `json:"barcode"`
+	ProductName      string   `json:"product_name"`
+	ExpectedQuantity float64  `json:"expected_quantity"`
+	CountedQuantity  *float64 `json:"counted_quantity"`
+	Variance         float64  `json:"variance"`
+	UnitCost         float64  `json:"unit_cost"`
+	VarianceValue    float64  `json:"variance_value"`
+	CountedAt        *string  `json:"counted_at"`
+}
+
+type AlertQuery struct {
+	OutletID  *uint64 `query:"outlet_id"`
+	ProductID *uint64 `query:"product_id"`
+	Status    string  `query:"status"`
+}
+
+type StockAlertResponse struct {
+	ID              uint64  `json:"id"`
+	ProductID       uint64  `json:"product_id"`
+	SKU             string  `json:"sku"`
+	ProductName     string  `json:"product_name"`
+	OutletID        uint64  `json:"outlet_id"`
+	OutletName      string  `json:"outlet_name"`
+	Quantity        float64 `json:"quantity"`
+	MinStock        float64 `json:"min_stock"`
+	CurrentQuantity float64 `json:"current_quantity"`
+	Status          string  `json:"status"`
+	StockMovementID *uint64 `json:"stock_movement_id"`
+	ReferenceType   string  `json:"reference_type"`
+	ReferenceID     *uint64 `json:"reference_id"`
+	AcknowledgedBy  *uint64 `json:"acknowledged_by"`
+	AcknowledgedAt  *string `json:"acknowledged_at"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// ReorderQuery selects the outlet to reorder for. Days is the sales period
+// the velocity is taken from and CoverDays how many days of sales to order.
+type ReorderQuery struct {
+	OutletID   uint64  `query:"outlet_id"`
+	SupplierID *uint64 `query:"supplier_id"`
+	Days       int     `query:"days"`
+	CoverDays  int     `query:"cover_days"`
+}
+
+type ReorderSuggestionResponse struct {
+	SupplierID    *uint64               `json:"supplier_id"`
+	SupplierName  string                `json:"supplier_name"`
+	ItemCount     int                   `json:"item_count"`
+	EstimatedCost float64               `json:"estimated_cost"`
+	Items         []ReorderLineResponse `json:"items"`
+}
+
+type ReorderLineResponse struct {
+	ProductID         uint64  `json:"product_id"`
+	SKU               string  `json:"sku"`
+	ProductName       string  `json:"product_name"`
+	Unit              string  `json:"unit"`
+	Quantity          float64 `json:"quantity"`
+	MinStock          float64 `json:"min_stock"`
+	OnOrderQuantity   float64 `json:"on_order_quantity"`
+	SoldQuantity      float64 `json:"sold_quantity"`
+	AverageDailySales float64 `json:"average_daily_sales"`
+	SuggestedQuantity float64 `json:"suggested_quantity"`
+	UnitCost          float64 `json:"unit_cost"`
+	EstimatedCost     float64 `json:"estimated_cost"`
+}
+
+// LotQuery filters lots. ExpiresBefore is set by the expiring lots report
+// and not bound from the query string.
+type LotQuery struct {
+	OutletID      *uint64 `query:"outlet_id"`
+	ProductID     *uint64 `query:"product_id"`
+	Search        string  `query:"search"`
+	InStock       bool    `query:"in_stock"`
+	ExpiresBefore *time.Time
+}
+
+// ExpiringLotQuery selects the lots in stock that expire within Days days,
+// including lots that have already expired
+type ExpiringLotQuery struct {
+	OutletID  *uint64 `query:"outlet_id"`
+	ProductID *uint64 `query:"product_id"`
+	Days      int     `query:"days"`
+}
+
+type StockLotResponse struct {
+	ID           uint64                     `json:"id"`
+	ProductID    uint64                     `json:"product_id"`
+	SKU          string                     `json:"sku"`
+	ProductName  string                     `json:"product_name"`
+	Unit         string                     `json:"unit"`
+	OutletID     uint64                     `json:"outlet_id"`
+	OutletName   string                     `json:"outlet_name"`
+	LotNumber    string                     `json:"lot_number"`
+	ExpiryDate   *string                    `json:"expiry_date"`
+	DaysToExpiry *int                       `json:"days_to_expiry"`
+	IsExpired    bool                       `json:"is_expired"`
+	Quantity     float64                    `json:"quantity"`
+	UnitCost     float64                    `json:"unit_cost"`
+	Value        float64                    `json:"value"`
+	CreatedAt    string                     `json:"created_at"`
+	UpdatedAt    string                     `json:"updated_at"`
+	Movements    []StockLotMovementResponse `json:"movements,omitempty"`
+}
+
+type StockLotMovementResponse struct {
+	StockMovementID uint64  `json:"stock_movement_id"`
+	MovementType    string  `json:"movement_type"`
+	ReferenceType   string  `json:"reference_type"`
+	ReferenceID     *uint64 `json:"reference_id"`
+	Quantity        float64 `json:"quantity"`
+	Notes           string  `json:"notes"`
+	CreatedBy       uint64  `json:"created_by"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// ValuationQuery selects the day whose closing stock is valued, in the
+// tenant's timezone
+type ValuationQuery struct {
+	OutletID *uint64
+	AsOf     time.Time
+}
+
+// CostOfGoodsSoldQuery selects the days sales are costed for, both days
+// included, in the tenant's timezone
+type CostOfGoodsSoldQuery struct {
+	OutletID  *uint64
+	ProductID *uint64
+	DateFrom  time.Time
+	DateTo    time.Time
+}
+
+type StockValuationResponse struct {
+	AsOf          string                    `json:"as_of"`
+	TotalQuantity float64                   `json:"total_quantity"`
+	TotalValue    float64                   `json:"total_value"`
+	Outlets       []OutletValuationResponse `json:"outlets"`
+}
+
+type OutletValuationResponse struct {
+	OutletID     uint64  `json:"outlet_id"`
+	OutletName   string  `json:"outlet_name"`
+	ProductCount int     `json:"product_count"`
+	Quantity     float64 `json:"quantity"`
+	Value        float64 `json:"value"`
+}
+
+type CostOfGoodsSoldResponse struct {
+	DateFrom      string                        `json:"date_from"`
+	DateTo        string                        `json:"date_to"`
+	TotalQuantity float64                       `json:"total_quantity"`
+	TotalCost     float64                       `json:"total_cost"`
+	Items         []CostOfGoodsSoldLineResponse `json:"items"`
+}
+
+type CostOfGoodsSoldLineResponse struct {
+	ProductID       uint64  `json:"product_id"`
+	SKU             string  `json:"sku"`
+	ProductName     string  `json:"product_name"`
+	Unit            string  `json:"unit"`
+	Quantity        float64 `json:"quantity"`
+	AverageUnitCost float64 `json:"average_unit_cost"`
+	Cost            float64 `json:"cost"`
+}
+
+// MovementQuery filters the stock ledger. The date range is in the tenant's
+// timezone, both days included.
+type MovementQuery struct {
+	ProductID     *uint64
+	VariantID     *uint64
+	OutletID      *uint64
+	MovementType  string
+	ReferenceType string
+	ReferenceID   *uint64
+	DateFrom      *time.Time
+	DateTo        *time.Time
+}
+
+type StockMovementResponse struct {
+	ID            uint64   `json:"id"`
+	ProductID     uint64   `json:"product_id"`
+	SKU           string   `json:"sku"`
+	ProductName   string   `json:"product_name"`
+	Unit          string   `json:"unit"`
+	VariantID     *uint64  `json:"variant_id"`
+	VariantSKU    string   `json:"variant_sku,omitempty"`
+	VariantName   string   `json:"variant_name,omitempty"`
+	OutletID      uint64   `json:"outlet_id"`
+	OutletName    string   `json:"outlet_name"`
+	MovementType  string   `json:"movement_type"`
+	Quantity      float64  `json:"quantity"`
+	Balance       float64  `json:"balance"`
+	ReferenceType string   `json:"reference_type"`
+	ReferenceID   *uint64  `json:"reference_id"`
+	Notes         string   `json:"notes"`
+	UnitCost      *float64 `json:"unit_cost"`
+	TotalCost     *float64 `json:"total_cost"`
+	CreatedBy     uint64   `json:"created_by"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+type StockReconciliationResponse struct {
+	Fixed      bool                 `json:"fixed"`
+	DriftCount int                  `json:"drift_count"`
+	Items      []StockDriftResponse `json:"items"`
+}
+
+type StockDriftResponse struct {
+	ProductID      uint64  `json:"product_id"`
+	SKU            string  `json:"sku"`
+	ProductName    string  `json:"product_name"`
+	VariantID      *uint64 `json:"variant_id"`
+	VariantSKU     string  `json:"variant_sku,omitempty"`
+	VariantName    string  `json:"variant_name,omitempty"`
+	OutletID       uint64  `json:"outlet_id"`
+	OutletName     string  `json:"outlet_name"`
+	Quantity       float64 `json:"quantity"`
+	LedgerQuantity float64 `json:"ledger_quantity"`
+	Difference     float64 `json:"difference"`
+}
+
+// RecordSaleRequest is sent by the transactions module at checkout, not over
+// HTTP
+type RecordSaleRequest struct {
+	TransactionID uint64            `json:"transaction_id" validate:"required"`
+	OutletID      uint64            `json:"outlet_id" validate:"required"`
+	Items         []SaleItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// SaleItemRequest is one transaction item. Items of a bundle name the
+// transaction item, which its components are recorded against, the item's
+// amount after discounts, which is allocated over the components, and the
+// bundle items chosen from the bundle's groups. Items with modifiers name
+// the transaction item too, which the picked modifiers are stored on. Items
+// sold in a unit other than the product's base unit store its factor on
+// their transaction item, if they name one.
+type SaleItemRequest struct {
+	ProductID         uint64   `json:"product_id" validate:"required"`
+	VariantID         *uint64  `json:"variant_id"`
+	Quantity          float64  `json:"quantity" validate:"required"`
+	Unit              string   `json:"unit" validate:"max=50"`
+	TransactionItemID uint64   `json:"transaction_item_id"`
+	Amount            float64  `json:"amount"`
+	Choices           []uint64 `json:"choices" validate:"max=50"`
+	ModifierIDs       []uint64 `json:"modifier_ids" validate:"max=50"`
+}
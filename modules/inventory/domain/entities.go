@@ -0,0 +1,821 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	MovementTypeIn         = "in"
+	MovementTypeOut        = "out"
+	MovementTypeAdjustment = "adjustment"
+	MovementTypeTransfer   = "transfer"
+)
+
+const (
+	ReferenceTypeSale       = "sale"
+	ReferenceTypePurchase   = "purchase"
+	ReferenceTypeAdjustment = "adjustment"
+	ReferenceTypeTransfer   = "transfer"
+	ReferenceTypeInitial    = "initial"
+	ReferenceTypeStocktake  = "stocktake"
+)
+
+const (
+	CostingMethodFIFO            = "fifo"
+	CostingMethodWeightedAverage = "weighted_average"
+)
+
+// RoundQuantity rounds a stock quantity to the three decimals it is stored
+// with, so sums of decimal quantities compare as they would in the database
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// UnitConversion is a unit a product's quantities may be given in. One of
+// the unit is Factor of the product's base unit.
+type UnitConversion struct {
+	Unit         string
+	Factor       float64
+	AllowDecimal bool
+}
+
+// ProductUnits are the units of one product: its base unit, at factor 1,
+// and the units converted to it, keyed by unit code
+type ProductUnits struct {
+	BaseUnit string
+	Units    map[string]*UnitConversion
+}
+
+// Convert returns the unit a quantity is given in, the base unit when unit
+// is empty, and the quantity in the base unit. Quantities in a unit that
+// does not allow decimals must be whole.
+func (u *ProductUnits) Convert(unit string, quantity float64) (*UnitConversion, float64, error) {
+	if unit == "" {
+		unit = u.BaseUnit
+	}
+
+	conversion, ok := u.Units[unit]
+	if !ok {
+		return nil, 0, errors.New("unit is not configured for the product")
+	}
+	if !conversion.AllowDecimal && quantity != math.Trunc(quantity) {
+		return nil, 0, errors.New("quantity must be a whole number of its unit")
+	}
+
+	return conversion, RoundQuantity(quantity * conversion.Factor), nil
+}
+
+// StockLevel is the stock of a tracked product at one outlet. Outlets that
+// have never held the product have a zero quantity and a nil UpdatedAt.
+type StockLevel struct {
+	ProductID        uint64
+	SKU              string
+	Barcode          string
+	ProductName      string
+	Unit             string
+	CategoryID       *uint64
+	CategoryName     string
+	MinStock         float64
+	OutletID         uint64
+	OutletName       string
+	OutletCode       string
+	Quantity         float64
+	ReservedQuantity float64
+	UpdatedAt        *time.Time
+}
+
+func (s *StockLevel) AvailableQuantity() float64 {
+	return RoundQuantity(s.Quantity - s.ReservedQuantity)
+}
+
+func (s *StockLevel) IsLowStock() bool {
+	return s.Quantity <= s.MinStock
+}
+
+func (s *StockLevel) IsOutOfStock() bool {
+	return s.Quantity <= 0
+}
+
+// ProductStock is the stock of a tracked product across the tenant's outlets
+type ProductStock struct {
+	ProductID   uint64
+	SKU         string
+	ProductName string
+	Unit        string
+	MinStock    float64
+	Outlets     []*StockLevel
+}
+
+func (p *ProductStock) TotalQuantity() float64 {
+	total := 0.0
+	for _, outlet := range p.Outlets {
+		total += outlet.Quantity
+	}
+	return RoundQuantity(total)
+}
+
+func (p *ProductStock) TotalReservedQuantity() float64 {
+	total := 0.0
+	for _, outlet := range p.Outlets {
+		total += outlet.ReservedQuantity
+	}
+	return RoundQuantity(total)
+}
+
+const (
+	AdjustmentStatusPendingApproval = "pending_approval"
+	AdjustmentStatusApplied         = "applied"
+	AdjustmentStatusRejected        = "rejected"
+)
+
+const (
+	AdjustmentReasonBreakage        = "breakage"
+	AdjustmentReasonTheft           = "theft"
+	AdjustmentReasonExpiry          = "expiry"
+	AdjustmentReasonCountCorrection = "count_correction"
+	AdjustmentReasonFound           = "found"
+	AdjustmentReasonOther           = "other"
+)
+
+const (
+	RoleSuperAdmin  = "super_admin"
+	RoleTenantOwner = "tenant_owner"
+	RoleManager     = "manager"
+)
+
+// StockAdjustment is a manual stock correction at one outlet. Quantities of
+// its items are signed: negative items remove stock.
+type StockAdjustment struct {
+	ID               uint64
+	TenantID         uint64
+	OutletID         uint64
+	OutletName       string
+	AdjustmentNumber string
+	Status           string
+	TotalValue       float64
+	Notes            string
+	CreatedBy        uint64
+	ReviewedBy       *uint64
+	ReviewedAt       *time.Time
+	RejectionReason  string
+	Items            []*StockAdjustmentItem
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// ItemsValue is the absolute value of the items at cost, so removals and
+// additions both count towards the approval threshold.
+func (a *StockAdjustment) ItemsValue() float64 {
+	total := 0.0
+	for _, item := range a.Items {
+		total += item.Value()
+	}
+	return total
+}
+
+func (a *StockAdjustment) IsPendingApproval() bool {
+	return a.Status == AdjustmentStatusPendingApproval
+}
+
+type StockAdjustmentItem struct {
+	ID          uint64
+	ProductID   uint64
+	SKU         string
+	ProductName string
+	Quantity    float64
+	Reason      string
+	UnitCost    float64
+	Notes       string
+}
+
+func (i *StockAdjustmentItem) Value() float64 {
+	return math.Abs(i.Quantity) * i.UnitCost
+}
+
+// StockProduct is the product data stock documents need
+type StockProduct struct {
+	ID         uint64
+	SKU        string
+	Barcode    string
+	Name       string
+	CostPrice  float64
+	TrackStock bool
+}
+
+type InventoryUser struct {
+	ID       uint64
+	TenantID uint64
+	RoleName string
+}
+
+// CanApproveAdjustments reports whether the user may apply adjustments above
+// the tenant's approval threshold and finalize stocktakes.
+func (u *InventoryUser) CanApproveAdjustments() bool {
+	switch u.RoleName {
+	case RoleManager, RoleTenantOwner, RoleSuperAdmin:
+		return true
+	}
+	return false
+}
+
+const (
+	TransferStatusDraft             = "draft"
+	TransferStatusDispatched        = "dispatched"
+	TransferStatusPartiallyReceived = "partially_received"
+	TransferStatusReceived          = "received"
+	TransferStatusCancelled         = "cancelled"
+)
+
+// StockTransfer moves stock between two outlets of a tenant. Dispatch takes
+// the full quantities out of the source outlet; receipts put the received
+// quantities into the destination outlet, possibly over several deliveries.
+type StockTransfer struct {
+	ID                    uint64
+	TenantID              uint64
+	TransferNumber        string
+	SourceOutletID        uint64
+	SourceOutletName      string
+	DestinationOutletID   uint64
+	DestinationOutletName string
+	Status                string
+	Notes                 string
+	CreatedBy             uint64
+	DispatchedBy          *uint64
+	DispatchedAt          *time.Time
+	ReceivedBy            *uint64
+	ReceivedAt            *time.Time
+	Items                 []*StockTransferItem
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+func (t *StockTransfer) IsDraft() bool {
+	return t.Status == TransferStatusDraft
+}
+
+// CanReceive reports whether deliveries can still be recorded against the
+// transfer.
+func (t *StockTransfer) CanReceive() bool {
+	return t.Status == TransferStatusDispatched || t.Status == TransferStatusPartiallyReceived
+}
+
+// StockTransferItem is one product of a transfer. Its quantities are in
+// Unit, of which one is UnitFactor of the product's base unit.
+type StockTransferItem struct {
+	ID                uint64
+	ProductID         uint64
+	SKU               string
+	ProductName       string
+	Unit              string
+	UnitFactor        float64
+	Quantity          float64
+	ReceivedQuantity  float64
+	DiscrepancyReason string
+}
+
+// OutstandingQuantity is the dispatched quantity that has not been received
+// yet. Once the transfer is received it is the quantity lost in transit.
+func (i *StockTransferItem) OutstandingQuantity() float64 {
+	return RoundQuantity(i.Quantity - i.ReceivedQuantity)
+}
+
+// BaseQuantity is a quantity of the item in the product's base unit
+func (i *StockTransferItem) BaseQuantity(quantity float64) float64 {
+	return RoundQuantity(quantity * i.UnitFactor)
+}
+
+// TransferReceipt is one delivery against a dispatched transfer. Quantities
+// are keyed by product, in the unit of its transfer item. Complete closes
+// the transfer, recording whatever is still outstanding as a discrepancy.
+type TransferReceipt struct {
+	Quantities         map[uint64]float64
+	DiscrepancyReasons map[uint64]string
+	Complete           bool
+	ReceivedBy         uint64
+}
+
+const (
+	StocktakeStatusCounting  = "counting"
+	StocktakeStatusFinalized = "finalized"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+// Stocktake is a physical count of an outlet's stock. Expected quantities
+// are frozen when it starts, so sales during the count do not move the
+// target; finalizing posts counted minus expected as adjustment movements.
+type Stocktake struct {
+	ID              uint64
+	TenantID        uint64
+	OutletID        uint64
+	OutletName      string
+	StocktakeNumber string
+	CategoryID      *uint64
+	CategoryName    string
+	Status          string
+	Notes           string
+	CreatedBy       uint64
+	FinalizedBy     *uint64
+	FinalizedAt     *time.Time
+	Summary         StocktakeSummary
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (s *Stocktake) IsCounting() bool {
+	return s.Status == StocktakeStatusCounting
+}
+
+// StocktakeSummary totals the items of a stocktake. Uncounted items have no
+// variance.
+type StocktakeSummary struct {
+	ItemCount     int
+	CountedCount  int
+	VarianceCount int
+	ShortageValue float64
+	SurplusValue  float64
+}
+
+func (s *StocktakeSummary) UncountedCount() int {
+	return s.ItemCount - s.CountedCount
+}
+
+// NetVarianceValue is the change in stock value at cost that finalizing
+// posts; negative when more is missing than found.
+func (s *StocktakeSummary) NetVarianceValue() float64 {
+	return math.Round((s.SurplusValue-s.ShortageValue)*100) / 100
+}
+
+type StocktakeItem struct {
+	ID               uint64
+	ProductID        uint64
+	SKU              string
+	Barcode          string
+	ProductName      string
+	ExpectedQuantity float64
+	CountedQuantity  *float64
+	UnitCost         float64
+	CountedAt        *time.Time
+}
+
+func (i *StocktakeItem) IsCounted() bool {
+	return i.CountedQuantity != nil
+}
+
+// Variance is counted minus expected, zero while the item is uncounted
+func (i *StocktakeItem) Variance() float64 {
+	if i.CountedQuantity == nil {
+		return 0
+	}
+	return RoundQuantity(*i.CountedQuantity - i.ExpectedQuantity)
+}
+
+func (i *StocktakeItem) VarianceValue() float64 {
+	return math.Round(i.Variance()*i.UnitCost*100) / 100
+}
+
+// StocktakeCountBatch is one submission of counts from a counting device.
+// Counts are added to what has been counted before.
+type StocktakeCountBatch struct {
+	DeviceID   string
+	CountedBy  uint64
+	Quantities map[uint64]float64
+}
+
+const (
+	StockAlertStatusOpen         = "open"
+	StockAlertStatusAcknowledged = "acknowledged"
+)
+
+// StockAlert is raised when a stock movement takes a product at an outlet
+// down to its minimum stock. CurrentQuantity is the stock now, which may
+// have recovered since.
+type StockAlert struct {
+	ID              uint64
+	TenantID        uint64
+	ProductID       uint64
+	SKU             string
+	ProductName     string
+	OutletID        uint64
+	OutletName      string
+	Quantity        float64
+	MinStock        float64
+	CurrentQuantity float64
+	Status          string
+	StockMovementID *uint64
+	ReferenceType   string
+	ReferenceID     *uint64
+	AcknowledgedBy  *uint64
+	AcknowledgedAt  *time.Time
+	CreatedAt       time.Time
+}
+
+func (a *StockAlert) IsOpen() bool {
+	return a.Status == StockAlertStatusOpen
+}
+
+// ReorderCandidate is a tracked product at an outlet with its recent sales,
+// stock on order and the supplier it was last received from
+type ReorderCandidate struct {
+	ProductID       uint64
+	SKU             string
+	ProductName     string
+	Unit            string
+	MinStock        float64
+	CostPrice       float64
+	Quantity        float64
+	SoldQuantity    float64
+	OnOrderQuantity float64
+	SupplierID      *uint64
+	SupplierName    string
+	LastUnitCost    *float64
+}
+
+// ReorderLine is the quantity of one product worth ordering: enough to
+// cover the average daily sales for the cover period on top of the minimum
+// stock, less what is on hand and on order.
+type ReorderLine struct {
+	ProductID         uint64
+	SKU               string
+	ProductName       string
+	Unit              string
+	Quantity          float64
+	MinStock          float64
+	OnOrderQuantity   float64
+	SoldQuantity      float64
+	AverageDailySales float64
+	SuggestedQuantity float64
+	UnitCost          float64
+}
+
+func (l *ReorderLine) EstimatedCost() float64 {
+	return math.Round(l.SuggestedQuantity*l.UnitCost*100) / 100
+}
+
+// ReorderSuggestion groups the lines to order from one supplier. Products
+// never received from a supplier are grouped under a nil SupplierID.
+type ReorderSuggestion struct {
+	SupplierID   *uint64
+	SupplierName string
+	Lines        []*ReorderLine
+}
+
+func (s *ReorderSuggestion) EstimatedCost() float64 {
+	total := 0.0
+	for _, line := range s.Lines {
+		total += line.EstimatedCost()
+	}
+	return math.Round(total*100) / 100
+}
+
+// SuggestReorder turns candidates into per-supplier suggestions from their
+// sales over the last salesDays days, aiming to cover coverDays of sales.
+// Suppliers are sorted by name with unknown suppliers last.
+func SuggestReorder(candidates []*ReorderCandidate, salesDays, coverDays int) []*ReorderSuggestion {
+	groups := map[uint64]*ReorderSuggestion{}
+	var unknown *ReorderSuggestion
+
+	for _, candidate := range candidates {
+		daily := candidate.SoldQuantity / float64(salesDays)
+		target := math.Ceil(RoundQuantity(daily*float64(coverDays))) + candidate.MinStock
+		suggested := math.Ceil(RoundQuantity(target - candidate.Quantity - candidate.OnOrderQuantity))
+		if suggested <= 0 {
+			continue
+		}
+
+		unitCost := candidate.CostPrice
+		if candidate.LastUnitCost != nil {
+			unitCost = *candidate.LastUnitCost
+		}
+
+		line := &ReorderLine{
+			ProductID:         candidate.ProductID,
+			SKU:               candidate.SKU,
+			ProductName:       candidate.ProductName,
+			Unit:              candidate.Unit,
+			Quantity:          candidate.Quantity,
+			MinStock:          candidate.MinStock,
+			OnOrderQuantity:   candidate.OnOrderQuantity,
+			SoldQuantity:      candidate.SoldQuantity,
+			AverageDailySales: math.Round(daily*100) / 100,
+			SuggestedQuantity: suggested,
+			UnitCost:          unitCost,
+		}
+
+		if candidate.SupplierID == nil {
+			if unknown == nil {
+				unknown = &ReorderSuggestion{}
+			}
+			unknown.Lines = append(unknown.Lines, line)
+			continue
+		}
+
+		group, ok := groups[*candidate.SupplierID]
+		if !ok {
+			group = &ReorderSuggestion{
+				SupplierID:   candidate.SupplierID,
+				SupplierName: candidate.SupplierName,
+			}
+			groups[*candidate.SupplierID] = group
+		}
+		group.Lines = append(group.Lines, line)
+	}
+
+	suggestions := make([]*ReorderSuggestion, 0, len(groups)+1)
+	for _, group := range groups {
+		suggestions = append(suggestions, group)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].SupplierName != suggestions[j].SupplierName {
+			return suggestions[i].SupplierName < suggestions[j].SupplierName
+		}
+		return *suggestions[i].SupplierID < *suggestions[j].SupplierID
+	})
+	if unknown != nil {
+		suggestions = append(suggestions, unknown)
+	}
+
+	return suggestions
+}
+
+// StockLot is one batch of a lot-tracked product at an outlet. UnitCost is
+// the product's current cost price, used to value the lot.
+type StockLot struct {
+	ID          uint64
+	TenantID    uint64
+	ProductID   uint64
+	SKU         string
+	ProductName string
+	Unit        string
+	OutletID    uint64
+	OutletName  string
+	LotNumber   string
+	ExpiryDate  *time.Time
+	Quantity    float64
+	UnitCost    float64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Movements []*StockLotMovement
+}
+
+func (l *StockLot) IsExpired(now time.Time) bool {
+	return LotExpired(l.ExpiryDate, now)
+}
+
+// DaysToExpiry counts the days from now until the lot expires; negative
+// values are days since it expired. Lots without an expiry date return nil.
+func (l *StockLot) DaysToExpiry(now time.Time) *int {
+	if l.ExpiryDate == nil {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(l.ExpiryDate.Year(), l.ExpiryDate.Month(), l.ExpiryDate.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(expiry.Sub(today).Hours() / 24)
+	return &days
+}
+
+func (l *StockLot) Value() float64 {
+	return math.Round(l.Quantity*l.UnitCost*100) / 100
+}
+
+// LotExpired reports whether a lot with the given expiry date has expired
+// by now. A lot can still be sold on its expiry date.
+func LotExpired(expiryDate *time.Time, now time.Time) bool {
+	return expiryDate != nil && expiryDate.Format("2006-01-02") < now.Format("2006-01-02")
+}
+
+// StockLotMovement is the share of one stock movement taken from or put
+// into a lot, for tracing a lot back to its receipt and forward to its sales
+type StockLotMovement struct {
+	StockMovementID uint64
+	MovementType    string
+	ReferenceType   string
+	ReferenceID     *uint64
+	Quantity        float64
+	Notes           string
+	CreatedBy       uint64
+	CreatedAt       time.Time
+}
+
+// OutletValuation is the stock an outlet held at the end of a day, valued
+// at the cost it entered at
+type OutletValuation struct {
+	OutletID     uint64
+	OutletName   string
+	ProductCount int
+	Quantity     float64
+	Value        float64
+}
+
+// StockValuation is the tenant's stock value at the end of AsOf, rebuilt
+// from the stock movements recorded up to then
+type StockValuation struct {
+	AsOf    time.Time
+	Outlets []*OutletValuation
+}
+
+func (v *StockValuation) TotalQuantity() float64 {
+	total := 0.0
+	for _, outlet := range v.Outlets {
+		total += outlet.Quantity
+	}
+	return RoundQuantity(total)
+}
+
+func (v *StockValuation) TotalValue() float64 {
+	total := 0.0
+	for _, outlet := range v.Outlets {
+		total += outlet.Value
+	}
+	return math.Round(total*100) / 100
+}
+
+// CostOfGoodsSoldLine is the quantity of one product sold over a period and
+// what it cost, net of returns
+type CostOfGoodsSoldLine struct {
+	ProductID   uint64
+	SKU         string
+	ProductName string
+	Unit        string
+	Quantity    float64
+	Cost        float64
+}
+
+func (l *CostOfGoodsSoldLine) AverageUnitCost() float64 {
+	if l.Quantity == 0 {
+		return 0
+	}
+	return math.Round(l.Cost/l.Quantity*100) / 100
+}
+
+// CostOfGoodsSold is the cost of the stock sold from DateFrom to DateTo,
+// both days included
+type CostOfGoodsSold struct {
+	DateFrom time.Time
+	DateTo   time.Time
+	Lines    []*CostOfGoodsSoldLine
+}
+
+func (c *CostOfGoodsSold) TotalQuantity() float64 {
+	total := 0.0
+	for _, line := range c.Lines {
+		total += line.Quantity
+	}
+	return RoundQuantity(total)
+}
+
+func (c *CostOfGoodsSold) TotalCost() float64 {
+	total := 0.0
+	for _, line := range c.Lines {
+		total += line.Cost
+	}
+	return math.Round(total*100) / 100
+}
+
+// StockMovement is one entry of the stock ledger. Balance is the product's
+// stock at the outlet right after the movement, summed from the ledger.
+type StockMovement struct {
+	ID            uint64
+	ProductID     uint64
+	SKU           string
+	ProductName   string
+	Unit          string
+	VariantID     *uint64
+	VariantSKU    string
+	VariantName   string
+	OutletID      uint64
+	OutletName    string
+	MovementType  string
+	Quantity      float64
+	Balance       float64
+	ReferenceType string
+	ReferenceID   *uint64
+	Notes         string
+	UnitCost      *float64
+	TotalCost     *float64
+	CreatedBy     uint64
+	CreatedAt     time.Time
+}
+
+// StockDrift is a product's or variant's stock at an outlet that no longer
+// matches the sum of its stock movements
+type StockDrift struct {
+	ProductID      uint64
+	SKU            string
+	ProductName    string
+	VariantID      *uint64
+	VariantSKU     string
+	VariantName    string
+	OutletID       uint64
+	OutletName     string
+	Quantity       float64
+	LedgerQuantity float64
+}
+
+func (d *StockDrift) Difference() float64 {
+	return RoundQuantity(d.Quantity - d.LedgerQuantity)
+}
+
+// Sale is the stock side of a checkout. Recording it takes every line from
+// the outlet's stock, a negative quantity returning it, and fills in what
+// each line cost.
+type Sale struct {
+	TransactionID uint64
+	OutletID      uint64
+	CreatedBy     uint64
+	Lines         []*SaleLine
+}
+
+// SaleLine is one transaction item of a sale. A line of a variant takes the
+// variant's stock instead of the product's. Products with a recipe take
+// their components from stock as well; Cost rolls up the cost of everything
+// the line consumed. A line of a bundle takes its components, the fixed
+// ones and those in Choices, instead of the bundle, and lists them in
+// Components with their share of Amount. The modifiers picked in
+// ModifierIDs are listed in Modifiers, and take their products along.
+// Quantity is in Unit, the product's base unit when empty; UnitFactor is
+// filled in with the base units in one of it.
+type SaleLine struct {
+	ProductID         uint64
+	VariantID         *uint64
+	Quantity          float64
+	Unit              string
+	UnitFactor        float64
+	TransactionItemID uint64
+	Amount            float64
+	Choices           []uint64
+	ModifierIDs       []uint64
+	Cost              float64
+	Components        []*SaleComponent
+	Modifiers         []*SaleModifier
+}
+
+// SaleComponent is a component of a bundle sold on a sale line
+type SaleComponent struct {
+	ProductID uint64
+	VariantID *uint64
+	Quantity  float64
+	Amount    float64
+	Cost      float64
+}
+
+// SaleModifier is a modifier picked on a sale line, as it was at the sale.
+// Quantity is the base units of the line's product it was picked for and
+// ProductQuantity what it took of its product, if it has one.
+type SaleModifier struct {
+	ModifierID      uint64
+	GroupName       string
+	Name            string
+	PriceDelta      float64
+	Quantity        float64
+	ProductID       *uint64
+	ProductQuantity float64
+	Cost            float64
+}
+
+// AllocateAmount splits amount over the weights in proportion, rounded to
+// cents, the last share taking the rounding difference so the shares add up
+// to amount. Weights that are all zero share equally.
+func AllocateAmount(amount float64, weights []float64) []float64 {
+	shares := make([]float64, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	var allocated float64
+	for i, weight := range weights {
+		if i == len(weights)-1 {
+			shares[i] = math.Round((amount-allocated)*100) / 100
+			break
+		}
+		share := amount / float64(len(weights))
+		if total > 0 {
+			share = amount * weight / total
+		}
+		shares[i] = math.Round(share*100) / 100
+		allocated += shares[i]
+	}
+	return shares
+}
+
+// BaseQuantity is the line's quantity in the product's base unit
+func (l *SaleLine) BaseQuantity() float64 {
+	return RoundQuantity(l.Quantity * l.UnitFactor)
+}
+
+// UnitCost is the cost of one sold unit of the line, for the transaction
+// item's cost price snapshot
+func (l *SaleLine) UnitCost() float64 {
+	if l.Quantity == 0 {
+		return 0
+	}
+	return math.Round(l.Cost/l.Quantity*100) / 100
+}
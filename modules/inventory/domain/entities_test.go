@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAllocateAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		weights []float64
+		want    []float64
+	}{
+		{"no weights", 100, nil, []float64{}},
+		{"single weight", 45000, []float64{3}, []float64{45000}},
+		{"proportional", 100, []float64{1, 1, 2}, []float64{25, 25, 50}},
+		{"last share takes the remainder", 100, []float64{1, 1, 1}, []float64{33.33, 33.33, 33.34}},
+		{"remainder below the rounded share", 10, []float64{1, 2}, []float64{3.33, 6.67}},
+		{"shares round half away from zero", 0.05, []float64{1, 1}, []float64{0.03, 0.02}},
+		{"zero weights share equally", 10, []float64{0, 0, 0}, []float64{3.33, 3.33, 3.34}},
+		{"zero weight gets nothing", 30000, []float64{20000, 0, 10000}, []float64{20000, 0, 10000}},
+		{"zero amount", 0, []float64{1, 2}, []float64{0, 0}},
+		{"negative amount of a return", -100, []float64{1, 1, 1}, []float64{-33.33, -33.33, -33.34}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AllocateAmount(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("AllocateAmount(%v, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+
+			var total float64
+			for _, share := range got {
+				total += share
+			}
+			if len(got) > 0 && math.Abs(total-tt.amount) > 0.001 {
+				t.Errorf("shares add up to %v, want %v", total, tt.amount)
+			}
+		})
+	}
+}
+
+func TestProductUnitsConvert(t *testing.T) {
+	units := &ProductUnits{
+		BaseUnit: "g",
+		Units: map[string]*UnitConversion{
+			"g":    {Unit: "g", Factor: 1, AllowDecimal: true},
+			"kg":   {Unit: "kg", Factor: 1000, AllowDecimal: true},
+			"pack": {Unit: "pack", Factor: 250, AllowDecimal: false},
+			"oz":   {Unit: "oz", Factor: 28.349523, AllowDecimal: true},
+			"cup":  {Unit: "cup", Factor: 0.333333, AllowDecimal: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		unit     string
+		quantity float64
+		wantUnit string
+		want     float64
+		wantErr  string
+	}{
+		{"empty unit is the base unit", "", 12.5, "g", 12.5, ""},
+		{"base unit", "g", 40, "g", 40, ""},
+		{"whole unit", "pack", 2, "pack", 500, ""},
+		{"fractional quantity", "kg", 1.25, "kg", 1250, ""},
+		{"fractional factor", "oz", 2, "oz", 56.699, ""},
+		{"factor below one", "cup", 3, "cup", 1, ""},
+		{"negative quantity of a return", "pack", -1, "pack", -250, ""},
+		{"fraction of a whole unit", "pack", 1.5, "", 0, "quantity must be a whole number of its unit"},
+		{"unknown unit", "box", 1, "", 0, "unit is not configured for the product"},
+		{"unit codes are case sensitive", "KG", 1, "", 0, "unit is not configured for the product"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversion, got, err := units.Convert(tt.unit, tt.quantity)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Convert(%q, %v) error = %v, want %q", tt.unit, tt.quantity, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert(%q, %v) error = %v", tt.unit, tt.quantity, err)
+			}
+			if conversion.Unit != tt.wantUnit || got != tt.want {
+				t.Errorf("Convert(%q, %v) = %s %v, want %s %v", tt.unit, tt.quantity, conversion.Unit, got, tt.wantUnit, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductUnitsConvertRoundTrip(t *testing.T) {
+	units := &ProductUnits{
+		BaseUnit: "pcs",
+		Units: map[string]*UnitConversion{
+			"pcs":    {Unit: "pcs", Factor: 1},
+			"dozen":  {Unit: "dozen", Factor: 12},
+			"carton": {Unit: "carton", Factor: 144},
+		},
+	}
+
+	for _, unit := range []string{"pcs", "dozen", "carton"} {
+		for _, quantity := range []float64{1, 7, 250} {
+			conversion, base, err := units.Convert(unit, quantity)
+			if err != nil {
+				t.Fatalf("Convert(%q, %v) error = %v", unit, quantity, err)
+			}
+
+			// Back from the base unit gives the quantity sold
+			if back := RoundQuantity(base / conversion.Factor); back != quantity {
+				t.Errorf("%v %s is %v pcs, which is %v %s back", quantity, unit, base, back, unit)
+			}
+
+			// The base quantity converts to itself
+			_, again, err := units.Convert("", base)
+			if err != nil || again != base {
+				t.Errorf("Convert(\"\", %v) = %v, %v, want %v", base, again, err, base)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type StockRepository interface {
+	FindAll(ctx context.Context, tenantID uint64, query StockQuery, limit, offset int) ([]*StockLevel, int64, error)
+	FindByProduct(ctx context.Context, tenantID, productID uint64) (*ProductStock, error)
+	FindUser(ctx context.Context, tenantID, userID uint64) (*InventoryUser, error)
+	OutletExists(ctx context.Context, tenantID, outletID uint64) (bool, error)
+	FindProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*StockProduct, error)
+	FindProductUnits(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*ProductUnits, error)
+	FindProductByBarcode(ctx context.Context, tenantID uint64, barcode string) (*StockProduct, error)
+	CategoryExists(ctx context.Context, tenantID, categoryID uint64) (bool, error)
+	FindReorderCandidates(ctx context.Context, tenantID, outletID uint64, since time.Time) ([]*ReorderCandidate, error)
+	FindValuation(ctx context.Context, tenantID uint64, query ValuationQuery) ([]*OutletValuation, error)
+	FindCostOfGoodsSold(ctx context.Context, tenantID uint64, query CostOfGoodsSoldQuery) ([]*CostOfGoodsSoldLine, error)
+}
+
+type MovementRepository interface {
+	FindAll(ctx context.Context, tenantID uint64, query MovementQuery, limit, offset int) ([]*StockMovement, int64, error)
+	FindDrift(ctx context.Context, tenantID uint64) ([]*StockDrift, error)
+	FixDrift(ctx context.Context, tenantID uint64) ([]*StockDrift, error)
+}
+
+type AdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *StockAdjustment) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*StockAdjustment, error)
+	FindAll(ctx context.Context, tenantID uint64, query AdjustmentQuery, limit, offset int) ([]*StockAdjustment, int64, error)
+	Approve(ctx context.Context, tenantID, id, approvedBy uint64) (*StockAdjustment, error)
+	Reject(ctx context.Context, tenantID, id, rejectedBy uint64, reason string) (*StockAdjustment, error)
+	FindApprovalThreshold(ctx context.Context, tenantID uint64) (float64, error)
+}
+
+type TransferRepository interface {
+	Create(ctx context.Context, transfer *StockTransfer) error
+	UpdateDraft(ctx context.Context, transfer *StockTransfer) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*StockTransfer, error)
+	FindAll(ctx context.Context, tenantID uint64, query TransferQuery, limit, offset int) ([]*StockTransfer, int64, error)
+	Dispatch(ctx context.Context, tenantID, id, dispatchedBy uint64) error
+	Receive(ctx context.Context, tenantID, id uint64, receipt TransferReceipt) error
+	Cancel(ctx context.Context, tenantID, id uint64) error
+}
+
+type StocktakeRepository interface {
+	Create(ctx context.Context, stocktake *Stocktake) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*Stocktake, error)
+	FindAll(ctx context.Context, tenantID uint64, query StocktakeQuery, limit, offset int) ([]*Stocktake, int64, error)
+	FindItems(ctx context.Context, tenantID, id uint64, query StocktakeItemQuery, limit, offset int) ([]*StocktakeItem, int64, error)
+	RecordCounts(ctx context.Context, tenantID, id uint64, batch StocktakeCountBatch) ([]*StocktakeItem, error)
+	Finalize(ctx context.Context, tenantID, id, finalizedBy uint64, zeroUncounted bool) error
+	Cancel(ctx context.Context, tenantID, id uint64) error
+}
+
+type AlertRepository interface {
+	FindByID(ctx context.Context, tenantID, id uint64) (*StockAlert, error)
+	FindAll(ctx context.Context, tenantID uint64, query AlertQuery, limit, offset int) ([]*StockAlert, int64, error)
+	FindByReference(ctx context.Context, tenantID uint64, referenceType string, referenceID uint64) ([]*StockAlert, error)
+	Acknowledge(ctx context.Context, tenantID, id, acknowledgedBy uint64) error
+}
+
+type LotRepository interface {
+	FindByID(ctx context.Context, tenantID, id uint64) (*StockLot, error)
+	FindAll(ctx context.Context, tenantID uint64, query LotQuery, limit, offset int) ([]*StockLot, int64, error)
+	FindMovements(ctx context.Context, lotID uint64) ([]*StockLotMovement, error)
+}
+
+type SaleRepository interface {
+	// Record takes the sale from stock and fills in the cost of its lines
+	Record(ctx context.Context, tenantID uint64, sale *Sale) error
+}
+
+type InventoryService interface {
+	GetStocks(ctx context.Context, tenantID uint64, query StockQuery, limit, offset int) ([]*StockLevel, int64, error)
+	GetProductStock(ctx context.Context, tenantID, productID uint64) (*ProductStock, error)
+
+	CreateAdjustment(ctx context.Context, tenantID, userID uint64, req CreateAdjustmentRequest) (*StockAdjustment, error)
+	GetAdjustment(ctx context.Context, tenantID, id uint64) (*StockAdjustment, error)
+	GetAdjustments(ctx context.Context, tenantID uint64, query AdjustmentQuery, limit, offset int) ([]*StockAdjustment, int64, error)
+	ApproveAdjustment(ctx context.Context, tenantID, userID, id uint64) (*StockAdjustment, error)
+	RejectAdjustment(ctx context.Context, tenantID, userID, id uint64, req RejectAdjustmentRequest) (*StockAdjustment, error)
+
+	CreateTransfer(ctx context.Context, tenantID, userID uint64, req CreateTransferRequest) (*StockTransfer, error)
+	UpdateTransfer(ctx context.Context, tenantID, id uint64, req UpdateTransferRequest) (*StockTransfer, error)
+	GetTransfer(ctx context.Context, tenantID, id uint64) (*StockTransfer, error)
+	GetTransfers(ctx context.Context, tenantID uint64, query TransferQuery, limit, offset int) ([]*StockTransfer, int64, error)
+	DispatchTransfer(ctx context.Context, tenantID, userID, id uint64) (*StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, tenantID, userID, id uint64, req ReceiveTransferRequest) (*StockTransfer, error)
+	CancelTransfer(ctx context.Context, tenantID, id uint64) (*StockTransfer, error)
+
+	CreateStocktake(ctx context.Context, tenantID, userID uint64, req CreateStocktakeRequest) (*Stocktake, error)
+	GetStocktake(ctx context.Context, tenantID, id uint64) (*Stocktake, error)
+	GetStocktakes(ctx context.Context, tenantID uint64, query StocktakeQuery, limit, offset int) ([]*Stocktake, int64, error)
+	GetStocktakeItems(ctx context.Context, tenantID, id uint64, query StocktakeItemQuery, limit, offset int) ([]*StocktakeItem, int64, error)
+	RecordStocktakeCounts(ctx context.Context, tenantID, userID, id uint64, req RecordStocktakeCountsRequest) ([]*StocktakeItem, error)
+	FinalizeStocktake(ctx context.Context, tenantID, userID, id uint64, req FinalizeStocktakeRequest) (*Stocktake, error)
+	CancelStocktake(ctx context.Context, tenantID, id uint64) (*Stocktake, error)
+
+	GetAlerts(ctx context.Context, tenantID uint64, query AlertQuery, limit, offset int) ([]*StockAlert, int64, error)
+	AcknowledgeAlert(ctx context.Context, tenantID, userID, id uint64) (*StockAlert, error)
+	GetReorderSuggestions(ctx context.Context, tenantID uint64, query ReorderQuery) ([]*ReorderSuggestion, error)
+
+	GetLots(ctx context.Context, tenantID uint64, query LotQuery, limit, offset int) ([]*StockLot, int64, error)
+	GetLot(ctx context.Context, tenantID, id uint64) (*StockLot, error)
+	GetExpiringLots(ctx context.Context, tenantID uint64, query ExpiringLotQuery, limit, offset int) ([]*StockLot, int64, error)
+
+	GetValuation(ctx context.Context, tenantID uint64, query ValuationQuery) (*StockValuation, error)
+	GetCostOfGoodsSold(ctx context.Context, tenantID uint64, query CostOfGoodsSoldQuery) (*CostOfGoodsSold, error)
+
+	GetMovements(ctx context.Context, tenantID uint64, query MovementQuery, limit, offset int) ([]*StockMovement, int64, error)
+	ReconcileStock(ctx context.Context, tenantID uint64, fix bool) ([]*StockDrift, error)
+	FixStockDrift(ctx context.Context, tenantID, userID uint64) ([]*StockDrift, error)
+
+	RecordSale(ctx context.Context, tenantID, userID uint64, req RecordSaleRequest) (*Sale, error)
+}
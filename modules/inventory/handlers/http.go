@@ -0,0 +1,1624 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type InventoryHandler struct {
+	inventoryService domain.InventoryService
+}
+
+func NewInventoryHandler(inventoryService domain.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+func (h *InventoryHandler) RegisterRoutes(e *echo.Group) {
+	inventory := e.Group("/inventory")
+
+	// Stock level routes
+	inventory.GET("/stocks", h.GetStocks)
+	inventory.GET("/products/:id/stocks", h.GetProductStock)
+
+	// Stock adjustment routes
+	inventory.GET("/adjustments", h.GetAdjustments)
+	inventory.POST("/adjustments", h.CreateAdjustment)
+	inventory.GET("/adjustments/:id", h.GetAdjustment)
+	inventory.POST("/adjustments/:id/approve", h.ApproveAdjustment)
+	inventory.POST("/adjustments/:id/reject", h.RejectAdjustment)
+
+	// Stock transfer routes
+	inventory.GET("/transfers", h.GetTransfers)
+	inventory.POST("/transfers", h.CreateTransfer)
+	inventory.GET("/transfers/:id", h.GetTransfer)
+	inventory.PUT("/transfers/:id", h.UpdateTransfer)
+	inventory.POST("/transfers/:id/dispatch", h.DispatchTransfer)
+	inventory.POST("/transfers/:id/receive", h.ReceiveTransfer)
+	inventory.POST("/transfers/:id/cancel", h.CancelTransfer)
+
+	// Stocktake routes
+	inventory.GET("/stocktakes", h.GetStocktakes)
+	inventory.POST("/stocktakes", h.CreateStocktake)
+	inventory.GET("/stocktakes/:id", h.GetStocktake)
+	inventory.GET("/stocktakes/:id/items", h.GetStocktakeItems)
+	inventory.POST("/stocktakes/:id/counts", h.RecordStocktakeCounts)
+	inventory.POST("/stocktakes/:id/finalize", h.FinalizeStocktake)
+	inventory.POST("/stocktakes/:id/cancel", h.CancelStocktake)
+
+	// Low stock routes
+	inventory.GET("/alerts", h.GetAlerts)
+	inventory.POST("/alerts/:id/acknowledge", h.AcknowledgeAlert)
+	inventory.GET("/reorder-suggestions", h.GetReorderSuggestions)
+
+	// Lot routes
+	inventory.GET("/lots", h.GetLots)
+	inventory.GET("/lots/expiring", h.GetExpiringLots)
+	inventory.GET("/lots/:id", h.GetLot)
+
+	// Costing routes
+	inventory.GET("/valuation", h.GetValuation)
+	inventory.GET("/cogs", h.GetCostOfGoodsSold)
+
+	// Ledger routes
+	inventory.GET("/movements", h.GetMovements)
+	inventory.GET("/reconciliation", h.GetReconciliation)
+	inventory.POST("/reconciliation", h.ReconcileStock)
+}
+
+func (h *InventoryHandler) GetStocks(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	// Parse pagination parameters
+	page := 1
+	limit := 50
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.StockQuery{
+		Search: c.QueryParam("search"),
+	}
+	fieldErrors := map[string][]string{}
+
+	for param, target := range map[string]**uint64{
+		"outlet_id":   &query.OutletID,
+		"category_id": &query.CategoryID,
+		"product_id":  &query.ProductID,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be a valid ID"}
+				continue
+			}
+			*target = &id
+		}
+	}
+
+	for param, target := range map[string]*bool{
+		"low_stock":  &query.LowStock,
+		"zero_stock": &query.ZeroStock,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be true or false"}
+				continue
+			}
+			*target = flag
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	levels, total, err := h.inventoryService.GetStocks(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock levels")
+	}
+
+	levelResponses := make([]domain.StockLevelResponse, len(levels))
+	for i, level := range levels {
+		levelResponses[i] = h.stockLevelToResponse(level)
+	}
+
+	return response.SuccessWithPagination(c, "Stock levels retrieved successfully", levelResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetProductStock(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	productStock, err := h.inventoryService.GetProductStock(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		switch err.Error() {
+		case "product not found":
+			return response.NotFound(c, "Product not found")
+		case "product does not track stock":
+			return response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to get product stock")
+	}
+
+	outletResponses := make([]domain.StockLevelResponse, len(productStock.Outlets))
+	for i, level := range productStock.Outlets {
+		outletResponses[i] = h.stockLevelToResponse(level)
+	}
+
+	totalQuantity := productStock.TotalQuantity()
+	totalReserved := productStock.TotalReservedQuantity()
+
+	return response.Success(c, "Product stock retrieved successfully", domain.ProductStockResponse{
+		ProductID:              productStock.ProductID,
+		SKU:                    productStock.SKU,
+		ProductName:            productStock.ProductName,
+		Unit:                   productStock.Unit,
+		MinStock:               productStock.MinStock,
+		TotalQuantity:          totalQuantity,
+		TotalReservedQuantity:  totalReserved,
+		TotalAvailableQuantity: totalQuantity - totalReserved,
+		Outlets:                outletResponses,
+	})
+}
+
+func (h *InventoryHandler) CreateAdjustment(c echo.Context) error {
+	var req domain.CreateAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	adjustment, err := h.inventoryService.CreateAdjustment(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.adjustmentError(c, err, "Failed to create stock adjustment")
+	}
+
+	message := "Stock adjustment applied successfully"
+	if adjustment.IsPendingApproval() {
+		message = "Stock adjustment submitted for approval"
+	}
+
+	return response.Created(c, message, h.adjustmentToResponse(adjustment))
+}
+
+func (h *InventoryHandler) GetAdjustments(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.AdjustmentQuery{}
+	fieldErrors := map[string][]string{}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		outletID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.OutletID = &outletID
+		}
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", domain.AdjustmentStatusPendingApproval, domain.AdjustmentStatusApplied, domain.AdjustmentStatusRejected:
+		query.Status = status
+	default:
+		fieldErrors["status"] = []string{"Must be one of pending_approval, applied, rejected"}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	adjustments, total, err := h.inventoryService.GetAdjustments(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock adjustments")
+	}
+
+	adjustmentResponses := make([]domain.StockAdjustmentResponse, len(adjustments))
+	for i, adjustment := range adjustments {
+		adjustmentResponses[i] = h.adjustmentToResponse(adjustment)
+	}
+
+	return response.SuccessWithPagination(c, "Stock adjustments retrieved successfully", adjustmentResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetAdjustment(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid adjustment ID")
+	}
+
+	adjustment, err := h.inventoryService.GetAdjustment(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.adjustmentError(c, err, "Failed to get stock adjustment")
+	}
+
+	return response.Success(c, "Stock adjustment retrieved successfully", h.adjustmentToResponse(adjustment))
+}
+
+func (h *InventoryHandler) ApproveAdjustment(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid adjustment ID")
+	}
+
+	adjustment, err := h.inventoryService.ApproveAdjustment(c.Request().Context(), tenantID, userID, id)
+	if err != nil {
+		return h.adjustmentError(c, err, "Failed to approve stock adjustment")
+	}
+
+	return response.Success(c, "Stock adjustment approved successfully", h.adjustmentToResponse(adjustment))
+}
+
+func (h *InventoryHandler) RejectAdjustment(c echo.Context) error {
+	var req domain.RejectAdjustmentRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid adjustment ID")
+	}
+
+	adjustment, err := h.inventoryService.RejectAdjustment(c.Request().Context(), tenantID, userID, id, req)
+	if err != nil {
+		return h.adjustmentError(c, err, "Failed to reject stock adjustment")
+	}
+
+	return response.Success(c, "Stock adjustment rejected successfully", h.adjustmentToResponse(adjustment))
+}
+
+func (h *InventoryHandler) CreateTransfer(c echo.Context) error {
+	var req domain.CreateTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	transfer, err := h.inventoryService.CreateTransfer(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.transferError(c, err, "Failed to create stock transfer")
+	}
+
+	return response.Created(c, "Stock transfer created successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) GetTransfers(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.TransferQuery{}
+	fieldErrors := map[string][]string{}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		outletID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.OutletID = &outletID
+		}
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", domain.TransferStatusDraft, domain.TransferStatusDispatched, domain.TransferStatusPartiallyReceived,
+		domain.TransferStatusReceived, domain.TransferStatusCancelled:
+		query.Status = status
+	default:
+		fieldErrors["status"] = []string{"Must be one of draft, dispatched, partially_received, received, cancelled"}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	transfers, total, err := h.inventoryService.GetTransfers(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock transfers")
+	}
+
+	transferResponses := make([]domain.StockTransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		transferResponses[i] = h.transferToResponse(transfer)
+	}
+
+	return response.SuccessWithPagination(c, "Stock transfers retrieved successfully", transferResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetTransfer(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transfer ID")
+	}
+
+	transfer, err := h.inventoryService.GetTransfer(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.transferError(c, err, "Failed to get stock transfer")
+	}
+
+	return response.Success(c, "Stock transfer retrieved successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) UpdateTransfer(c echo.Context) error {
+	var req domain.UpdateTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transfer ID")
+	}
+
+	transfer, err := h.inventoryService.UpdateTransfer(c.Request().Context(), tenantID, id, req)
+	if err != nil {
+		return h.transferError(c, err, "Failed to update stock transfer")
+	}
+
+	return response.Success(c, "Stock transfer updated successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) DispatchTransfer(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transfer ID")
+	}
+
+	transfer, err := h.inventoryService.DispatchTransfer(c.Request().Context(), tenantID, userID, id)
+	if err != nil {
+		return h.transferError(c, err, "Failed to dispatch stock transfer")
+	}
+
+	return response.Success(c, "Stock transfer dispatched successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) ReceiveTransfer(c echo.Context) error {
+	var req domain.ReceiveTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transfer ID")
+	}
+
+	transfer, err := h.inventoryService.ReceiveTransfer(c.Request().Context(), tenantID, userID, id, req)
+	if err != nil {
+		return h.transferError(c, err, "Failed to receive stock transfer")
+	}
+
+	return response.Success(c, "Stock transfer received successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) CancelTransfer(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transfer ID")
+	}
+
+	transfer, err := h.inventoryService.CancelTransfer(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.transferError(c, err, "Failed to cancel stock transfer")
+	}
+
+	return response.Success(c, "Stock transfer cancelled successfully", h.transferToResponse(transfer))
+}
+
+func (h *InventoryHandler) CreateStocktake(c echo.Context) error {
+	var req domain.CreateStocktakeRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	stocktake, err := h.inventoryService.CreateStocktake(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to create stocktake")
+	}
+
+	return response.Created(c, "Stocktake started successfully", h.stocktakeToResponse(stocktake))
+}
+
+func (h *InventoryHandler) GetStocktakes(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.StocktakeQuery{}
+	fieldErrors := map[string][]string{}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		outletID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.OutletID = &outletID
+		}
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", domain.StocktakeStatusCounting, domain.StocktakeStatusFinalized, domain.StocktakeStatusCancelled:
+		query.Status = status
+	default:
+		fieldErrors["status"] = []string{"Must be one of counting, finalized, cancelled"}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	stocktakes, total, err := h.inventoryService.GetStocktakes(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stocktakes")
+	}
+
+	stocktakeResponses := make([]domain.StocktakeResponse, len(stocktakes))
+	for i, stocktake := range stocktakes {
+		stocktakeResponses[i] = h.stocktakeToResponse(stocktake)
+	}
+
+	return response.SuccessWithPagination(c, "Stocktakes retrieved successfully", stocktakeResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetStocktake(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stocktake ID")
+	}
+
+	stocktake, err := h.inventoryService.GetStocktake(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to get stocktake")
+	}
+
+	return response.Success(c, "Stocktake retrieved successfully", h.stocktakeToResponse(stocktake))
+}
+
+// GetStocktakeItems is the variance report: expected against counted
+// quantities with their value at cost
+func (h *InventoryHandler) GetStocktakeItems(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stocktake ID")
+	}
+
+	// Parse pagination parameters
+	page := 1
+	limit := 50
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.StocktakeItemQuery{
+		Search: c.QueryParam("search"),
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", "counted", "uncounted", "variance":
+		query.Status = status
+	default:
+		return response.ValidationError(c, map[string][]string{
+			"status": {"Must be one of counted, uncounted, variance"},
+		})
+	}
+
+	items, total, err := h.inventoryService.GetStocktakeItems(c.Request().Context(), tenantID, id, query, limit, offset)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to get stocktake items")
+	}
+
+	itemResponses := make([]domain.StocktakeItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = h.stocktakeItemToResponse(item)
+	}
+
+	return response.SuccessWithPagination(c, "Stocktake items retrieved successfully", itemResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) RecordStocktakeCounts(c echo.Context) error {
+	var req domain.RecordStocktakeCountsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stocktake ID")
+	}
+
+	items, err := h.inventoryService.RecordStocktakeCounts(c.Request().Context(), tenantID, userID, id, req)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to record stocktake counts")
+	}
+
+	itemResponses := make([]domain.StocktakeItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = h.stocktakeItemToResponse(item)
+	}
+
+	return response.Success(c, "Stocktake counts recorded successfully", itemResponses)
+}
+
+func (h *InventoryHandler) FinalizeStocktake(c echo.Context) error {
+	var req domain.FinalizeStocktakeRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stocktake ID")
+	}
+
+	stocktake, err := h.inventoryService.FinalizeStocktake(c.Request().Context(), tenantID, userID, id, req)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to finalize stocktake")
+	}
+
+	return response.Success(c, "Stocktake finalized successfully", h.stocktakeToResponse(stocktake))
+}
+
+func (h *InventoryHandler) CancelStocktake(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid stocktake ID")
+	}
+
+	stocktake, err := h.inventoryService.CancelStocktake(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.stocktakeError(c, err, "Failed to cancel stocktake")
+	}
+
+	return response.Success(c, "Stocktake cancelled successfully", h.stocktakeToResponse(stocktake))
+}
+
+func (h *InventoryHandler) GetAlerts(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	// Parse pagination parameters
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	query := domain.AlertQuery{}
+	fieldErrors := map[string][]string{}
+
+	for param, target := range map[string]**uint64{
+		"outlet_id":  &query.OutletID,
+		"product_id": &query.ProductID,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be a valid ID"}
+				continue
+			}
+			*target = &id
+		}
+	}
+
+	switch status := c.QueryParam("status"); status {
+	case "", domain.StockAlertStatusOpen, domain.StockAlertStatusAcknowledged:
+		query.Status = status
+	default:
+		fieldErrors["status"] = []string{"Must be one of open, acknowledged"}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	alerts, total, err := h.inventoryService.GetAlerts(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock alerts")
+	}
+
+	alertResponses := make([]domain.StockAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		alertResponses[i] = h.alertToResponse(alert)
+	}
+
+	return response.SuccessWithPagination(c, "Stock alerts retrieved successfully", alertResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) AcknowledgeAlert(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid alert ID")
+	}
+
+	alert, err := h.inventoryService.AcknowledgeAlert(c.Request().Context(), tenantID, userID, id)
+	if err != nil {
+		switch err.Error() {
+		case "alert not found":
+			return response.NotFound(c, "Stock alert not found")
+		case "only managers can acknowledge alerts":
+			return response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case "alert is already acknowledged":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to acknowledge stock alert")
+	}
+
+	return response.Success(c, "Stock alert acknowledged successfully", h.alertToResponse(alert))
+}
+
+func (h *InventoryHandler) GetReorderSuggestions(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	query := domain.ReorderQuery{}
+	fieldErrors := map[string][]string{}
+
+	if value := c.QueryParam("outlet_id"); value == "" {
+		fieldErrors["outlet_id"] = []string{"Outlet is required"}
+	} else if outletID, err := strconv.ParseUint(value, 10, 64); err != nil {
+		fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+	} else {
+		query.OutletID = outletID
+	}
+
+	if value := c.QueryParam("supplier_id"); value != "" {
+		supplierID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["supplier_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.SupplierID = &supplierID
+		}
+	}
+
+	for param, target := range map[string]struct {
+		value *int
+		max   int
+	}{
+		"days":       {&query.Days, 365},
+		"cover_days": {&query.CoverDays, 180},
+	} {
+		if value := c.QueryParam(param); value != "" {
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 1 || days > target.max {
+				fieldErrors[param] = []string{"Must be between 1 and " + strconv.Itoa(target.max)}
+				continue
+			}
+			*target.value = days
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	suggestions, err := h.inventoryService.GetReorderSuggestions(c.Request().Context(), tenantID, query)
+	if err != nil {
+		if err.Error() == "outlet not found" {
+			return response.ValidationError(c, map[string][]string{
+				"outlet_id": {"Outlet not found"},
+			})
+		}
+		return response.InternalError(c, "Failed to get reorder suggestions")
+	}
+
+	suggestionResponses := make([]domain.ReorderSuggestionResponse, len(suggestions))
+	for i, suggestion := range suggestions {
+		suggestionResponses[i] = h.reorderSuggestionToResponse(suggestion)
+	}
+
+	return response.Success(c, "Reorder suggestions retrieved successfully", suggestionResponses)
+}
+
+func (h *InventoryHandler) GetLots(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := stockListPagination(c)
+
+	query := domain.LotQuery{
+		Search: c.QueryParam("search"),
+	}
+	fieldErrors := outletProductFilters(c, &query.OutletID, &query.ProductID)
+
+	if value := c.QueryParam("in_stock"); value != "" {
+		inStock, err := strconv.ParseBool(value)
+		if err != nil {
+			fieldErrors["in_stock"] = []string{"Must be true or false"}
+		}
+		query.InStock = inStock
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	lots, total, err := h.inventoryService.GetLots(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock lots")
+	}
+
+	return response.SuccessWithPagination(c, "Stock lots retrieved successfully", h.lotsToResponse(lots), page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetExpiringLots(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := stockListPagination(c)
+
+	query := domain.ExpiringLotQuery{}
+	fieldErrors := outletProductFilters(c, &query.OutletID, &query.ProductID)
+
+	if value := c.QueryParam("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 365 {
+			fieldErrors["days"] = []string{"Must be between 1 and 365"}
+		}
+		query.Days = days
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	lots, total, err := h.inventoryService.GetExpiringLots(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get expiring lots")
+	}
+
+	return response.SuccessWithPagination(c, "Expiring lots retrieved successfully", h.lotsToResponse(lots), page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetLot(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid lot ID")
+	}
+
+	lot, err := h.inventoryService.GetLot(c.Request().Context(), tenantID, id)
+	if err != nil {
+		if err.Error() == "lot not found" {
+			return response.NotFound(c, "Stock lot not found")
+		}
+		return response.InternalError(c, "Failed to get stock lot")
+	}
+
+	return response.Success(c, "Stock lot retrieved successfully", h.lotToResponse(lot, time.Now()))
+}
+
+func (h *InventoryHandler) GetValuation(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	query := domain.ValuationQuery{}
+	fieldErrors := map[string][]string{}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		outletID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.OutletID = &outletID
+		}
+	}
+	query.AsOf = dateParam(c, "as_of", fieldErrors)
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	valuation, err := h.inventoryService.GetValuation(c.Request().Context(), tenantID, query)
+	if err != nil {
+		return response.InternalError(c, "Failed to get stock valuation")
+	}
+
+	valuationResponse := domain.StockValuationResponse{
+		AsOf:          valuation.AsOf.Format("2006-01-02"),
+		TotalQuantity: valuation.TotalQuantity(),
+		TotalValue:    valuation.TotalValue(),
+		Outlets:       make([]domain.OutletValuationResponse, len(valuation.Outlets)),
+	}
+	for i, outlet := range valuation.Outlets {
+		valuationResponse.Outlets[i] = domain.OutletValuationResponse{
+			OutletID:     outlet.OutletID,
+			OutletName:   outlet.OutletName,
+			ProductCount: outlet.ProductCount,
+			Quantity:     outlet.Quantity,
+			Value:        outlet.Value,
+		}
+	}
+
+	return response.Success(c, "Stock valuation retrieved successfully", valuationResponse)
+}
+
+func (h *InventoryHandler) GetCostOfGoodsSold(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	query := domain.CostOfGoodsSoldQuery{}
+	fieldErrors := outletProductFilters(c, &query.OutletID, &query.ProductID)
+	query.DateFrom = dateParam(c, "date_from", fieldErrors)
+	query.DateTo = dateParam(c, "date_to", fieldErrors)
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	cogs, err := h.inventoryService.GetCostOfGoodsSold(c.Request().Context(), tenantID, query)
+	if err != nil {
+		if err.Error() == "date from must not be after date to" {
+			return response.ValidationError(c, map[string][]string{
+				"date_from": {"Must not be after date_to"},
+			})
+		}
+		return response.InternalError(c, "Failed to get cost of goods sold")
+	}
+
+	cogsResponse := domain.CostOfGoodsSoldResponse{
+		DateFrom:      cogs.DateFrom.Format("2006-01-02"),
+		DateTo:        cogs.DateTo.Format("2006-01-02"),
+		TotalQuantity: cogs.TotalQuantity(),
+		TotalCost:     cogs.TotalCost(),
+		Items:         make([]domain.CostOfGoodsSoldLineResponse, len(cogs.Lines)),
+	}
+	for i, line := range cogs.Lines {
+		cogsResponse.Items[i] = domain.CostOfGoodsSoldLineResponse{
+			ProductID:       line.ProductID,
+			SKU:             line.SKU,
+			ProductName:     line.ProductName,
+			Unit:            line.Unit,
+			Quantity:        line.Quantity,
+			AverageUnitCost: line.AverageUnitCost(),
+			Cost:            line.Cost,
+		}
+	}
+
+	return response.Success(c, "Cost of goods sold retrieved successfully", cogsResponse)
+}
+
+func (h *InventoryHandler) GetMovements(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := stockListPagination(c)
+
+	query := domain.MovementQuery{
+		MovementType:  c.QueryParam("movement_type"),
+		ReferenceType: c.QueryParam("reference_type"),
+	}
+	fieldErrors := outletProductFilters(c, &query.OutletID, &query.ProductID)
+
+	switch query.MovementType {
+	case "", domain.MovementTypeIn, domain.MovementTypeOut, domain.MovementTypeAdjustment, domain.MovementTypeTransfer:
+	default:
+		fieldErrors["movement_type"] = []string{"Must be one of in, out, adjustment, transfer"}
+	}
+
+	switch query.ReferenceType {
+	case "", domain.ReferenceTypeSale, domain.ReferenceTypePurchase, domain.ReferenceTypeAdjustment,
+		domain.ReferenceTypeTransfer, domain.ReferenceTypeInitial, domain.ReferenceTypeStocktake:
+	default:
+		fieldErrors["reference_type"] = []string{"Must be one of sale, purchase, adjustment, transfer, initial, stocktake"}
+	}
+
+	if value := c.QueryParam("variant_id"); value != "" {
+		variantID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["variant_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.VariantID = &variantID
+		}
+	}
+
+	if value := c.QueryParam("reference_id"); value != "" {
+		referenceID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fieldErrors["reference_id"] = []string{"Must be a valid ID"}
+		} else {
+			query.ReferenceID = &referenceID
+		}
+	}
+
+	if dateFrom := dateParam(c, "date_from", fieldErrors); !dateFrom.IsZero() {
+		query.DateFrom = &dateFrom
+	}
+	if dateTo := dateParam(c, "date_to", fieldErrors); !dateTo.IsZero() {
+		query.DateTo = &dateTo
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	movements, total, err := h.inventoryService.GetMovements(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		if err.Error() == "date from must not be after date to" {
+			return response.ValidationError(c, map[string][]string{
+				"date_from": {"Must not be after date_to"},
+			})
+		}
+		return response.InternalError(c, "Failed to get stock movements")
+	}
+
+	movementResponses := make([]domain.StockMovementResponse, len(movements))
+	for i, movement := range movements {
+		movementResponses[i] = domain.StockMovementResponse{
+			ID:            movement.ID,
+			ProductID:     movement.ProductID,
+			SKU:           movement.SKU,
+			ProductName:   movement.ProductName,
+			Unit:          movement.Unit,
+			VariantID:     movement.VariantID,
+			VariantSKU:    movement.VariantSKU,
+			VariantName:   movement.VariantName,
+			OutletID:      movement.OutletID,
+			OutletName:    movement.OutletName,
+			MovementType:  movement.MovementType,
+			Quantity:      movement.Quantity,
+			Balance:       movement.Balance,
+			ReferenceType: movement.ReferenceType,
+			ReferenceID:   movement.ReferenceID,
+			Notes:         movement.Notes,
+			UnitCost:      movement.UnitCost,
+			TotalCost:     movement.TotalCost,
+			CreatedBy:     movement.CreatedBy,
+			CreatedAt:     movement.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return response.SuccessWithPagination(c, "Stock movements retrieved successfully", movementResponses, page, limit, int(total))
+}
+
+func (h *InventoryHandler) GetReconciliation(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	drifts, err := h.inventoryService.ReconcileStock(c.Request().Context(), tenantID, false)
+	if err != nil {
+		return response.InternalError(c, "Failed to reconcile stock")
+	}
+
+	return response.Success(c, "Stock reconciliation retrieved successfully", reconciliationToResponse(drifts, false))
+}
+
+func (h *InventoryHandler) ReconcileStock(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	drifts, err := h.inventoryService.FixStockDrift(c.Request().Context(), tenantID, userID)
+	if err != nil {
+		if err.Error() == "only managers can reconcile stock" {
+			return response.Error(c, http.StatusForbidden, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to reconcile stock")
+	}
+
+	return response.Success(c, "Stock reconciled successfully", reconciliationToResponse(drifts, true))
+}
+
+// Helper functions
+
+func (h *InventoryHandler) adjustmentError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "adjustment not found":
+		return response.NotFound(c, "Stock adjustment not found")
+	case "outlet not found":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet not found"},
+		})
+	case "product not found":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product not found"},
+		})
+	case "product does not track stock":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product does not track stock"},
+		})
+	case "only managers can review adjustments":
+		return response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case "adjustment is not pending approval":
+		return response.Error(c, http.StatusConflict, err.Error(), nil)
+	case "insufficient stock":
+		return response.Error(c, http.StatusUnprocessableEntity, "adjustment would make stock negative", nil)
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *InventoryHandler) adjustmentToResponse(adjustment *domain.StockAdjustment) domain.StockAdjustmentResponse {
+	adjustmentResponse := domain.StockAdjustmentResponse{
+		ID:               adjustment.ID,
+		AdjustmentNumber: adjustment.AdjustmentNumber,
+		OutletID:         adjustment.OutletID,
+		OutletName:       adjustment.OutletName,
+		Status:           adjustment.Status,
+		TotalValue:       adjustment.TotalValue,
+		Notes:            adjustment.Notes,
+		CreatedBy:        adjustment.CreatedBy,
+		ReviewedBy:       adjustment.ReviewedBy,
+		RejectionReason:  adjustment.RejectionReason,
+		CreatedAt:        adjustment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        adjustment.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if adjustment.ReviewedAt != nil {
+		reviewedAt := adjustment.ReviewedAt.Format(time.RFC3339)
+		adjustmentResponse.ReviewedAt = &reviewedAt
+	}
+
+	if adjustment.Items != nil {
+		adjustmentResponse.Items = make([]domain.StockAdjustmentItemResponse, len(adjustment.Items))
+		for i, item := range adjustment.Items {
+			adjustmentResponse.Items[i] = domain.StockAdjustmentItemResponse{
+				ID:          item.ID,
+				ProductID:   item.ProductID,
+				SKU:         item.SKU,
+				ProductName: item.ProductName,
+				Quantity:    item.Quantity,
+				Reason:      item.Reason,
+				UnitCost:    item.UnitCost,
+				Value:       item.Value(),
+				Notes:       item.Notes,
+			}
+		}
+	}
+
+	return adjustmentResponse
+}
+
+func (h *InventoryHandler) stockLevelToResponse(level *domain.StockLevel) domain.StockLevelResponse {
+	levelResponse := domain.StockLevelResponse{
+		ProductID:         level.ProductID,
+		SKU:               level.SKU,
+		Barcode:           level.Barcode,
+		ProductName:       level.ProductName,
+		Unit:              level.Unit,
+		CategoryID:        level.CategoryID,
+		CategoryName:      level.CategoryName,
+		OutletID:          level.OutletID,
+		OutletName:        level.OutletName,
+		OutletCode:        level.OutletCode,
+		Quantity:          level.Quantity,
+		ReservedQuantity:  level.ReservedQuantity,
+		AvailableQuantity: level.AvailableQuantity(),
+		MinStock:          level.MinStock,
+		IsLowStock:        level.IsLowStock(),
+		IsOutOfStock:      level.IsOutOfStock(),
+	}
+
+	if level.UpdatedAt != nil {
+		updatedAt := level.UpdatedAt.Format(time.RFC3339)
+		levelResponse.UpdatedAt = &updatedAt
+	}
+
+	return levelResponse
+}
+
+func (h *InventoryHandler) transferError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "transfer not found":
+		return response.NotFound(c, "Stock transfer not found")
+	case "outlet not found":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet not found"},
+		})
+	case "product not found":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product not found"},
+		})
+	case "product does not track stock":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product does not track stock"},
+		})
+	case "duplicate product in transfer", "duplicate product in receipt":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Each product can only be listed once"},
+		})
+	case "product is not part of the transfer":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Product is not part of the transfer"},
+		})
+	case "unit is not configured for the product":
+		return response.ValidationError(c, map[string][]string{
+			"unit": {"Unit is not configured for the product"},
+		})
+	case "quantity must be a whole number of its unit":
+		return response.ValidationError(c, map[string][]string{
+			"quantity": {"Quantity must be a whole number of its unit"},
+		})
+	case "received quantity exceeds outstanding quantity":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Received quantity exceeds the outstanding quantity"},
+		})
+	case "nothing to receive":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Items are required unless the receipt completes the transfer"},
+		})
+	case "transfer is not a draft", "transfer is not awaiting receipt":
+		return response.Error(c, http.StatusConflict, err.Error(), nil)
+	case "insufficient stock":
+		return response.Error(c, http.StatusUnprocessableEntity, "insufficient stock at the source outlet", nil)
+	case "lot expiry date does not match":
+		return response.Error(c, http.StatusUnprocessableEntity, "lot expiry date does not match the lot at the destination outlet", nil)
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *InventoryHandler) transferToResponse(transfer *domain.StockTransfer) domain.StockTransferResponse {
+	transferResponse := domain.StockTransferResponse{
+		ID:                    transfer.ID,
+		TransferNumber:        transfer.TransferNumber,
+		SourceOutletID:        transfer.SourceOutletID,
+		SourceOutletName:      transfer.SourceOutletName,
+		DestinationOutletID:   transfer.DestinationOutletID,
+		DestinationOutletName: transfer.DestinationOutletName,
+		Status:                transfer.Status,
+		Notes:                 transfer.Notes,
+		CreatedBy:             transfer.CreatedBy,
+		DispatchedBy:          transfer.DispatchedBy,
+		ReceivedBy:            transfer.ReceivedBy,
+		CreatedAt:             transfer.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             transfer.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if transfer.DispatchedAt != nil {
+		dispatchedAt := transfer.DispatchedAt.Format(time.RFC3339)
+		transferResponse.DispatchedAt = &dispatchedAt
+	}
+	if transfer.ReceivedAt != nil {
+		receivedAt := transfer.ReceivedAt.Format(time.RFC3339)
+		transferResponse.ReceivedAt = &receivedAt
+	}
+
+	if transfer.Items != nil {
+		transferResponse.Items = make([]domain.StockTransferItemResponse, len(transfer.Items))
+		for i, item := range transfer.Items {
+			transferResponse.Items[i] = domain.StockTransferItemResponse{
+				ID:                  item.ID,
+				ProductID:           item.ProductID,
+				SKU:                 item.SKU,
+				ProductName:         item.ProductName,
+				Unit:                item.Unit,
+				UnitFactor:          item.UnitFactor,
+				Quantity:            item.Quantity,
+				ReceivedQuantity:    item.ReceivedQuantity,
+				OutstandingQuantity: item.OutstandingQuantity(),
+				DiscrepancyReason:   item.DiscrepancyReason,
+			}
+		}
+	}
+
+	return transferResponse
+}
+
+func (h *InventoryHandler) stocktakeError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "stocktake not found":
+		return response.NotFound(c, "Stocktake not found")
+	case "outlet not found":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet not found"},
+		})
+	case "category not found":
+		return response.ValidationError(c, map[string][]string{
+			"category_id": {"Category not found"},
+		})
+	case "product not found":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Product not found"},
+		})
+	case "product is not part of the stocktake":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Product is not part of the stocktake"},
+		})
+	case "counted quantity cannot be negative":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Counted quantity cannot be negative"},
+		})
+	case "no products to count":
+		return response.Error(c, http.StatusUnprocessableEntity, "no tracked products to count at this outlet", nil)
+	case "only managers can finalize stocktakes":
+		return response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case "outlet already has a stocktake in progress", "stocktake is not in progress":
+		return response.Error(c, http.StatusConflict, err.Error(), nil)
+	case "insufficient stock":
+		return response.Error(c, http.StatusUnprocessableEntity, "stocktake would make stock negative", nil)
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *InventoryHandler) stocktakeToResponse(stocktake *domain.Stocktake) domain.StocktakeResponse {
+	stocktakeResponse := domain.StocktakeResponse{
+		ID:               stocktake.ID,
+		StocktakeNumber:  stocktake.StocktakeNumber,
+		OutletID:         stocktake.OutletID,
+		OutletName:       stocktake.OutletName,
+		CategoryID:       stocktake.CategoryID,
+		CategoryName:     stocktake.CategoryName,
+		Status:           stocktake.Status,
+		Notes:            stocktake.Notes,
+		ItemCount:        stocktake.Summary.ItemCount,
+		CountedCount:     stocktake.Summary.CountedCount,
+		UncountedCount:   stocktake.Summary.UncountedCount(),
+		VarianceCount:    stocktake.Summary.VarianceCount,
+		ShortageValue:    stocktake.Summary.ShortageValue,
+		SurplusValue:     stocktake.Summary.SurplusValue,
+		NetVarianceValue: stocktake.Summary.NetVarianceValue(),
+		CreatedBy:        stocktake.CreatedBy,
+		FinalizedBy:      stocktake.FinalizedBy,
+		CreatedAt:        stocktake.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        stocktake.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if stocktake.FinalizedAt != nil {
+		finalizedAt := stocktake.FinalizedAt.Format(time.RFC3339)
+		stocktakeResponse.FinalizedAt = &finalizedAt
+	}
+
+	return stocktakeResponse
+}
+
+func (h *InventoryHandler) stocktakeItemToResponse(item *domain.StocktakeItem) domain.StocktakeItemResponse {
+	itemResponse := domain.StocktakeItemResponse{
+		ID:               item.ID,
+		ProductID:        item.ProductID,
+		SKU:              item.SKU,
+		Barcode:          item.Barcode,
+		ProductName:      item.ProductName,
+		ExpectedQuantity: item.ExpectedQuantity,
+		CountedQuantity:  item.CountedQuantity,
+		Variance:         item.Variance(),
+		UnitCost:         item.UnitCost,
+		VarianceValue:    item.VarianceValue(),
+	}
+
+	if item.CountedAt != nil {
+		countedAt := item.CountedAt.Format(time.RFC3339)
+		itemResponse.CountedAt = &countedAt
+	}
+
+	return itemResponse
+}
+
+func (h *InventoryHandler) alertToResponse(alert *domain.StockAlert) domain.StockAlertResponse {
+	alertResponse := domain.StockAlertResponse{
+		ID:              alert.ID,
+		ProductID:       alert.ProductID,
+		SKU:             alert.SKU,
+		ProductName:     alert.ProductName,
+		OutletID:        alert.OutletID,
+		OutletName:      alert.OutletName,
+		Quantity:        alert.Quantity,
+		MinStock:        alert.MinStock,
+		CurrentQuantity: alert.CurrentQuantity,
+		Status:          alert.Status,
+		StockMovementID: alert.StockMovementID,
+		ReferenceType:   alert.ReferenceType,
+		ReferenceID:     alert.ReferenceID,
+		AcknowledgedBy:  alert.AcknowledgedBy,
+		CreatedAt:       alert.CreatedAt.Format(time.RFC3339),
+	}
+
+	if alert.AcknowledgedAt != nil {
+		acknowledgedAt := alert.AcknowledgedAt.Format(time.RFC3339)
+		alertResponse.AcknowledgedAt = &acknowledgedAt
+	}
+
+	return alertResponse
+}
+
+// stockListPagination reads the page and limit of the lot and movement
+// lists, which default to 50 entries per page like the stock list
+func stockListPagination(c echo.Context) (int, int, int) {
+	page := 1
+	limit := 50
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	return page, limit, (page - 1) * limit
+}
+
+// outletProductFilters parses the optional outlet_id and product_id filters
+func outletProductFilters(c echo.Context, outletID, productID **uint64) map[string][]string {
+	fieldErrors := map[string][]string{}
+
+	for param, target := range map[string]**uint64{
+		"outlet_id":  outletID,
+		"product_id": productID,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be a valid ID"}
+				continue
+			}
+			*target = &id
+		}
+	}
+
+	return fieldErrors
+}
+
+// dateParam parses an optional YYYY-MM-DD query parameter, recording a field
+// error when it is malformed. A missing parameter gives the zero time.
+func dateParam(c echo.Context, param string, fieldErrors map[string][]string) time.Time {
+	value := c.QueryParam(param)
+	if value == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		fieldErrors[param] = []string{"Must be a date in YYYY-MM-DD format"}
+	}
+	return date
+}
+
+func (h *InventoryHandler) lotsToResponse(lots []*domain.StockLot) []domain.StockLotResponse {
+	now := time.Now()
+	lotResponses := make([]domain.StockLotResponse, len(lots))
+	for i, lot := range lots {
+		lotResponses[i] = h.lotToResponse(lot, now)
+	}
+	return lotResponses
+}
+
+func (h *InventoryHandler) lotToResponse(lot *domain.StockLot, now time.Time) domain.StockLotResponse {
+	lotResponse := domain.StockLotResponse{
+		ID:           lot.ID,
+		ProductID:    lot.ProductID,
+		SKU:          lot.SKU,
+		ProductName:  lot.ProductName,
+		Unit:         lot.Unit,
+		OutletID:     lot.OutletID,
+		OutletName:   lot.OutletName,
+		LotNumber:    lot.LotNumber,
+		DaysToExpiry: lot.DaysToExpiry(now),
+		IsExpired:    lot.IsExpired(now),
+		Quantity:     lot.Quantity,
+		UnitCost:     lot.UnitCost,
+		Value:        lot.Value(),
+		CreatedAt:    lot.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    lot.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if lot.ExpiryDate != nil {
+		expiryDate := lot.ExpiryDate.Format("2006-01-02")
+		lotResponse.ExpiryDate = &expiryDate
+	}
+
+	if lot.Movements != nil {
+		lotResponse.Movements = make([]domain.StockLotMovementResponse, len(lot.Movements))
+		for i, movement := range lot.Movements {
+			lotResponse.Movements[i] = domain.StockLotMovementResponse{
+				StockMovementID: movement.StockMovementID,
+				MovementType:    movement.MovementType,
+				ReferenceType:   movement.ReferenceType,
+				ReferenceID:     movement.ReferenceID,
+				Quantity:        movement.Quantity,
+				Notes:           movement.Notes,
+				CreatedBy:       movement.CreatedBy,
+				CreatedAt:       movement.CreatedAt.Format(time.RFC3339),
+			}
+		}
+	}
+
+	return lotResponse
+}
+
+func (h *InventoryHandler) reorderSuggestionToResponse(suggestion *domain.ReorderSuggestion) domain.ReorderSuggestionResponse {
+	suggestionResponse := domain.ReorderSuggestionResponse{
+		SupplierID:    suggestion.SupplierID,
+		SupplierName:  suggestion.SupplierName,
+		ItemCount:     len(suggestion.Lines),
+		EstimatedCost: suggestion.EstimatedCost(),
+		Items:         make([]domain.ReorderLineResponse, len(suggestion.Lines)),
+	}
+
+	for i, line := range suggestion.Lines {
+		suggestionResponse.Items[i] = domain.ReorderLineResponse{
+			ProductID:         line.ProductID,
+			SKU:               line.SKU,
+			ProductName:       line.ProductName,
+			Unit:              line.Unit,
+			Quantity:          line.Quantity,
+			MinStock:          line.MinStock,
+			OnOrderQuantity:   line.OnOrderQuantity,
+			SoldQuantity:      line.SoldQuantity,
+			AverageDailySales: line.AverageDailySales,
+			SuggestedQuantity: line.SuggestedQuantity,
+			UnitCost:          line.UnitCost,
+			EstimatedCost:     line.EstimatedCost(),
+		}
+	}
+
+	return suggestionResponse
+}
+
+func reconciliationToResponse(drifts []*domain.StockDrift, fixed bool) domain.StockReconciliationResponse {
+	reconciliation := domain.StockReconciliationResponse{
+		Fixed:      fixed,
+		DriftCount: len(drifts),
+		Items:      make([]domain.StockDriftResponse, len(drifts)),
+	}
+	for i, drift := range drifts {
+		reconciliation.Items[i] = domain.StockDriftResponse{
+			ProductID:      drift.ProductID,
+			SKU:            drift.SKU,
+			ProductName:    drift.ProductName,
+			VariantID:      drift.VariantID,
+			VariantSKU:     drift.VariantSKU,
+			VariantName:    drift.VariantName,
+			OutletID:       drift.OutletID,
+			OutletName:     drift.OutletName,
+			Quantity:       drift.Quantity,
+			LedgerQuantity: drift.LedgerQuantity,
+			Difference:     drift.Difference(),
+		}
+	}
+	return reconciliation
+}
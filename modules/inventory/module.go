@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/modules/inventory/handlers"
+	"github.com/exven/pos-system/modules/inventory/persistence"
+	"github.com/exven/pos-system/modules/inventory/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container container.Container
+	db        *gorm.DB
+	eventBus  messaging.EventBus
+}
+
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	eventBus messaging.EventBus,
+) *Module {
+	return &Module{
+		container: container,
+		db:        db,
+		eventBus:  eventBus,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("inventory.stockRepository", func() interface{} {
+		return persistence.NewStockRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.adjustmentRepository", func() interface{} {
+		return persistence.NewAdjustmentRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.transferRepository", func() interface{} {
+		return persistence.NewTransferRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.stocktakeRepository", func() interface{} {
+		return persistence.NewStocktakeRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.alertRepository", func() interface{} {
+		return persistence.NewAlertRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.lotRepository", func() interface{} {
+		return persistence.NewLotRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.movementRepository", func() interface{} {
+		return persistence.NewMovementRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("inventory.saleRepository", func() interface{} {
+		return persistence.NewSaleRepository(m.db)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("inventory.inventoryService", func() interface{} {
+		return m.GetService()
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("inventory.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+// GetService builds the inventory service, also for commands that run
+// outside the API server
+func (m *Module) GetService() domain.InventoryService {
+	stockRepo := persistence.NewStockRepository(m.db)
+	adjustmentRepo := persistence.NewAdjustmentRepository(m.db)
+	transferRepo := persistence.NewTransferRepository(m.db)
+	stocktakeRepo := persistence.NewStocktakeRepository(m.db)
+	alertRepo := persistence.NewAlertRepository(m.db)
+	lotRepo := persistence.NewLotRepository(m.db)
+	movementRepo := persistence.NewMovementRepository(m.db)
+	saleRepo := persistence.NewSaleRepository(m.db)
+	return services.NewInventoryService(stockRepo, adjustmentRepo, transferRepo, stocktakeRepo, alertRepo, lotRepo, movementRepo, saleRepo, m.eventBus)
+}
+
+func (m *Module) GetHandler() *handlers.InventoryHandler {
+	return handlers.NewInventoryHandler(m.GetService())
+}
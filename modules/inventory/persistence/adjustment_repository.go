@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type adjustmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAdjustmentRepository(db *gorm.DB) domain.AdjustmentRepository {
+	return &adjustmentRepository{db: db}
+}
+
+// Create saves the adjustment with a new adjustment number. Applied
+// adjustments are written to stock in the same transaction.
+func (r *adjustmentRepository) Create(ctx context.Context, adjustment *domain.StockAdjustment) error {
+	var model StockAdjustmentModel
+	model.FromDomainAdjustment(adjustment)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		number, err := nextDocumentNumber(tx, "stock_adjustments", "adjustment_number", "ADJ", model.TenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		model.AdjustmentNumber = number
+
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create stock adjustment: %w", err)
+		}
+
+		if model.Status == domain.AdjustmentStatusApplied {
+			return applyAdjustment(tx, &model)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	adjustment.ID = model.ID
+	adjustment.AdjustmentNumber = model.AdjustmentNumber
+	adjustment.CreatedAt = model.CreatedAt
+	adjustment.UpdatedAt = model.UpdatedAt
+	for i := range model.Items {
+		adjustment.Items[i].ID = model.Items[i].ID
+	}
+
+	return nil
+}
+
+func (r *adjustmentRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.StockAdjustment, error) {
+	var model StockAdjustmentModel
+
+	err := r.db.WithContext(ctx).
+		Preload("Outlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product").
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("adjustment not found")
+		}
+		return nil, fmt.Errorf("failed to find stock adjustment: %w", err)
+	}
+
+	return model.ToDomainAdjustment(), nil
+}
+
+func (r *adjustmentRepository) FindAll(ctx context.Context, tenantID uint64, query domain.AdjustmentQuery, limit, offset int) ([]*domain.StockAdjustment, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&StockAdjustmentModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("outlet_id = ?", *query.OutletID)
+	}
+	if query.Status != "" {
+		filtered = filtered.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock adjustments: %w", err)
+	}
+
+	var models []StockAdjustmentModel
+	err := filtered.
+		Preload("Outlet").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock adjustments: %w", err)
+	}
+
+	adjustments := make([]*domain.StockAdjustment, len(models))
+	for i := range models {
+		adjustments[i] = models[i].ToDomainAdjustment()
+	}
+
+	return adjustments, total, nil
+}
+
+// Approve applies a pending adjustment to stock. The adjustment row is locked
+// so it cannot be approved or rejected twice.
+func (r *adjustmentRepository) Approve(ctx context.Context, tenantID, id, approvedBy uint64) (*domain.StockAdjustment, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lockPending(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("adjustment_id = ?", model.ID).Find(&model.Items).Error; err != nil {
+			return fmt.Errorf("failed to find adjustment items: %w", err)
+		}
+
+		err = tx.Model(&StockAdjustmentModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":      domain.AdjustmentStatusApplied,
+			"reviewed_by": approvedBy,
+			"reviewed_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to approve stock adjustment: %w", err)
+		}
+
+		return applyAdjustment(tx, model)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, tenantID, id)
+}
+
+func (r *adjustmentRepository) Reject(ctx context.Context, tenantID, id, rejectedBy uint64, reason string) (*domain.StockAdjustment, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lockPending(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&StockAdjustmentModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":           domain.AdjustmentStatusRejected,
+			"reviewed_by":      rejectedBy,
+			"reviewed_at":      time.Now(),
+			"rejection_reason": reason,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to reject stock adjustment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, tenantID, id)
+}
+
+func (r *adjustmentRepository) lockPending(tx *gorm.DB, tenantID, id uint64) (*StockAdjustmentModel, error) {
+	var model StockAdjustmentModel
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("adjustment not found")
+		}
+		return nil, fmt.Errorf("failed to find stock adjustment: %w", err)
+	}
+
+	if model.Status != domain.AdjustmentStatusPendingApproval {
+		return nil, errors.New("adjustment is not pending approval")
+	}
+
+	return &model, nil
+}
+
+// applyAdjustment writes every item of the adjustment to product_stocks and
+// stock_movements. Items are applied in product order so concurrent
+// adjustments lock stock rows in the same order.
+func applyAdjustment(tx *gorm.DB, model *StockAdjustmentModel) error {
+	items := make([]StockAdjustmentItemModel, len(model.Items))
+	copy(items, model.Items)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+
+	for _, item := range items {
+		notes := fmt.Sprintf("Adjustment %s: %s", model.AdjustmentNumber, item.Reason)
+		if item.Notes != "" {
+			notes += " - " + item.Notes
+		}
+
+		_, err := stockledger.Apply(tx, stockledger.Change{
+			ProductID:     item.ProductID,
+			OutletID:      model.OutletID,
+			Quantity:      item.Quantity,
+			MovementType:  domain.MovementTypeAdjustment,
+			ReferenceType: domain.ReferenceTypeAdjustment,
+			ReferenceID:   model.ID,
+			Notes:         notes,
+			CreatedBy:     model.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindApprovalThreshold reads the adjustment approval threshold from the
+// tenant settings document. Tenants that never saved it get zero.
+func (r *adjustmentRepository) FindApprovalThreshold(ctx context.Context, tenantID uint64) (float64, error) {
+	var threshold float64
+
+	err := r.db.WithContext(ctx).
+		Table("tenants").
+		Select("COALESCE((settings->'inventory'->>'adjustment_approval_threshold')::numeric, 0)").
+		Where("id = ?", tenantID).
+		Scan(&threshold).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find approval threshold: %w", err)
+	}
+
+	return threshold, nil
+}
@@ -0,0 +1,139 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const stockAlertColumns = "stock_alerts.*, p.sku, p.name AS product_name, o.name AS outlet_name, " +
+	"COALESCE(ps.quantity, 0) AS current_quantity"
+
+type alertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) domain.AlertRepository {
+	return &alertRepository{db: db}
+}
+
+func (r *alertRepository) alerts(ctx context.Context, tenantID uint64) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&StockAlertModel{}).
+		Joins("JOIN products p ON p.id = stock_alerts.product_id").
+		Joins("JOIN outlets o ON o.id = stock_alerts.outlet_id").
+		Joins("LEFT JOIN product_stocks ps ON ps.product_id = stock_alerts.product_id AND ps.outlet_id = stock_alerts.outlet_id AND ps.variant_id IS NULL").
+		Where("stock_alerts.tenant_id = ?", tenantID)
+}
+
+func (r *alertRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.StockAlert, error) {
+	var model StockAlertRowModel
+
+	err := r.alerts(ctx, tenantID).
+		Select(stockAlertColumns).
+		Where("stock_alerts.id = ?", id).
+		Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alert not found")
+		}
+		return nil, fmt.Errorf("failed to find stock alert: %w", err)
+	}
+
+	return model.ToDomainAlert(), nil
+}
+
+func (r *alertRepository) FindAll(ctx context.Context, tenantID uint64, query domain.AlertQuery, limit, offset int) ([]*domain.StockAlert, int64, error) {
+	filtered := r.alerts(ctx, tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("stock_alerts.outlet_id = ?", *query.OutletID)
+	}
+	if query.ProductID != nil {
+		filtered = filtered.Where("stock_alerts.product_id = ?", *query.ProductID)
+	}
+	if query.Status != "" {
+		filtered = filtered.Where("stock_alerts.status = ?", query.Status)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock alerts: %w", err)
+	}
+
+	var models []StockAlertRowModel
+	err := filtered.
+		Select(stockAlertColumns).
+		Order("stock_alerts.created_at DESC, stock_alerts.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock alerts: %w", err)
+	}
+
+	alerts := make([]*domain.StockAlert, len(models))
+	for i := range models {
+		alerts[i] = models[i].ToDomainAlert()
+	}
+
+	return alerts, total, nil
+}
+
+// FindByReference returns the alerts raised by the movements of one stock
+// document
+func (r *alertRepository) FindByReference(ctx context.Context, tenantID uint64, referenceType string, referenceID uint64) ([]*domain.StockAlert, error) {
+	var models []StockAlertRowModel
+
+	err := r.alerts(ctx, tenantID).
+		Select(stockAlertColumns).
+		Where("stock_alerts.reference_type = ? AND stock_alerts.reference_id = ?", referenceType, referenceID).
+		Order("stock_alerts.id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock alerts: %w", err)
+	}
+
+	alerts := make([]*domain.StockAlert, len(models))
+	for i := range models {
+		alerts[i] = models[i].ToDomainAlert()
+	}
+
+	return alerts, nil
+}
+
+func (r *alertRepository) Acknowledge(ctx context.Context, tenantID, id, acknowledgedBy uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model StockAlertModel
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", id, tenantID).
+			First(&model).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("alert not found")
+			}
+			return fmt.Errorf("failed to find stock alert: %w", err)
+		}
+
+		if model.Status != domain.StockAlertStatusOpen {
+			return errors.New("alert is already acknowledged")
+		}
+
+		err = tx.Model(&StockAlertModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":          domain.StockAlertStatusAcknowledged,
+			"acknowledged_by": acknowledgedBy,
+			"acknowledged_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to acknowledge stock alert: %w", err)
+		}
+
+		return nil
+	})
+}
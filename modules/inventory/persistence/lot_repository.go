@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"gorm.io/gorm"
+)
+
+const stockLotColumns = "stock_lots.*, p.sku, p.name AS product_name, p.unit, p.cost_price, o.name AS outlet_name"
+
+type lotRepository struct {
+	db *gorm.DB
+}
+
+func NewLotRepository(db *gorm.DB) domain.LotRepository {
+	return &lotRepository{db: db}
+}
+
+func (r *lotRepository) lots(ctx context.Context, tenantID uint64) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&StockLotModel{}).
+		Joins("JOIN products p ON p.id = stock_lots.product_id").
+		Joins("JOIN outlets o ON o.id = stock_lots.outlet_id").
+		Where("stock_lots.tenant_id = ?", tenantID)
+}
+
+func (r *lotRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.StockLot, error) {
+	var model StockLotRowModel
+
+	err := r.lots(ctx, tenantID).
+		Select(stockLotColumns).
+		Where("stock_lots.id = ?", id).
+		Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("lot not found")
+		}
+		return nil, fmt.Errorf("failed to find stock lot: %w", err)
+	}
+
+	return model.ToDomainLot(), nil
+}
+
+// FindAll lists lots earliest expiry first, the order they are sold in.
+// Lots without an expiry date come last.
+func (r *lotRepository) FindAll(ctx context.Context, tenantID uint64, query domain.LotQuery, limit, offset int) ([]*domain.StockLot, int64, error) {
+	filtered := r.lots(ctx, tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("stock_lots.outlet_id = ?", *query.OutletID)
+	}
+	if query.ProductID != nil {
+		filtered = filtered.Where("stock_lots.product_id = ?", *query.ProductID)
+	}
+	if search := strings.TrimSpace(query.Search); search != "" {
+		filtered = filtered.Where("stock_lots.lot_number ILIKE ?", "%"+search+"%")
+	}
+	if query.InStock {
+		filtered = filtered.Where("stock_lots.quantity > 0")
+	}
+	if query.ExpiresBefore != nil {
+		filtered = filtered.Where("stock_lots.expiry_date < ?", query.ExpiresBefore.Format("2006-01-02"))
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock lots: %w", err)
+	}
+
+	var models []StockLotRowModel
+	err := filtered.
+		Select(stockLotColumns).
+		Order("stock_lots.expiry_date ASC NULLS LAST, stock_lots.id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock lots: %w", err)
+	}
+
+	lots := make([]*domain.StockLot, len(models))
+	for i := range models {
+		lots[i] = models[i].ToDomainLot()
+	}
+
+	return lots, total, nil
+}
+
+// FindMovements traces a lot through every stock movement that put stock
+// into it or took stock from it, oldest first
+func (r *lotRepository) FindMovements(ctx context.Context, lotID uint64) ([]*domain.StockLotMovement, error) {
+	var models []StockLotMovementModel
+
+	err := r.db.WithContext(ctx).
+		Table("stock_movement_lots sml").
+		Select("sm.id AS stock_movement_id, sm.movement_type, sm.reference_type, sm.reference_id, "+
+			"sml.quantity, sm.notes, sm.created_by, sm.created_at").
+		Joins("JOIN stock_movements sm ON sm.id = sml.stock_movement_id").
+		Where("sml.lot_id = ?", lotID).
+		Order("sm.created_at ASC, sm.id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock lot movements: %w", err)
+	}
+
+	movements := make([]*domain.StockLotMovement, len(models))
+	for i := range models {
+		movements[i] = models[i].ToDomainMovement()
+	}
+
+	return movements, nil
+}
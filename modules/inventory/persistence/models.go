@@ -0,0 +1,858 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+)
+
+// StockLevelModel is a tracked product joined with an outlet and the
+// product's stock there
+type StockLevelModel struct {
+	ProductID        uint64     `gorm:"column:product_id"`
+	SKU              string     `gorm:"column:sku"`
+	Barcode          string     `gorm:"column:barcode"`
+	ProductName      string     `gorm:"column:product_name"`
+	Unit             string     `gorm:"column:unit"`
+	CategoryID       *uint64    `gorm:"column:category_id"`
+	CategoryName     *string    `gorm:"column:category_name"`
+	MinStock         float64    `gorm:"column:min_stock"`
+	OutletID         uint64     `gorm:"column:outlet_id"`
+	OutletName       string     `gorm:"column:outlet_name"`
+	OutletCode       string     `gorm:"column:outlet_code"`
+	Quantity         float64    `gorm:"column:quantity"`
+	ReservedQuantity float64    `gorm:"column:reserved_quantity"`
+	UpdatedAt        *time.Time `gorm:"column:updated_at"`
+}
+
+func (m *StockLevelModel) ToDomainStockLevel() *domain.StockLevel {
+	level := &domain.StockLevel{
+		ProductID:        m.ProductID,
+		SKU:              m.SKU,
+		Barcode:          m.Barcode,
+		ProductName:      m.ProductName,
+		Unit:             m.Unit,
+		CategoryID:       m.CategoryID,
+		MinStock:         m.MinStock,
+		OutletID:         m.OutletID,
+		OutletName:       m.OutletName,
+		OutletCode:       m.OutletCode,
+		Quantity:         m.Quantity,
+		ReservedQuantity: m.ReservedQuantity,
+		UpdatedAt:        m.UpdatedAt,
+	}
+
+	if m.CategoryName != nil {
+		level.CategoryName = *m.CategoryName
+	}
+
+	return level
+}
+
+type StockMovementModel struct {
+	ID            uint64  `gorm:"primaryKey;autoIncrement"`
+	ProductID     uint64  `gorm:"not null"`
+	VariantID     *uint64 `gorm:"column:variant_id"`
+	OutletID      uint64  `gorm:"not null"`
+	MovementType  string  `gorm:"not null"`
+	Quantity      float64 `gorm:"type:decimal(15,3);not null"`
+	ReferenceType string  `gorm:"not null"`
+	ReferenceID   *uint64
+	Notes         string    `gorm:"type:text"`
+	UnitCost      *float64  `gorm:"type:decimal(12,2)"`
+	TotalCost     *float64  `gorm:"type:decimal(15,2)"`
+	CreatedBy     uint64    `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (StockMovementModel) TableName() string {
+	return "stock_movements"
+}
+
+type StockAdjustmentModel struct {
+	ID               uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID         uint64 `gorm:"not null"`
+	OutletID         uint64 `gorm:"not null"`
+	AdjustmentNumber string `gorm:"size:50;not null"`
+	Status           string `gorm:"size:30;not null"`
+	TotalValue       float64
+	Notes            string `gorm:"type:text"`
+	CreatedBy        uint64 `gorm:"not null"`
+	ReviewedBy       *uint64
+	ReviewedAt       *time.Time
+	RejectionReason  string    `gorm:"type:text"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+
+	Outlet *StockOutletModel          `gorm:"foreignKey:OutletID"`
+	Items  []StockAdjustmentItemModel `gorm:"foreignKey:AdjustmentID"`
+}
+
+func (StockAdjustmentModel) TableName() string {
+	return "stock_adjustments"
+}
+
+type StockAdjustmentItemModel struct {
+	ID           uint64  `gorm:"primaryKey;autoIncrement"`
+	AdjustmentID uint64  `gorm:"not null"`
+	ProductID    uint64  `gorm:"not null"`
+	Quantity     float64 `gorm:"type:decimal(15,3);not null"`
+	Reason       string  `gorm:"size:30;not null"`
+	UnitCost     float64
+	Notes        string `gorm:"type:text"`
+
+	Product *StockProductModel `gorm:"foreignKey:ProductID"`
+}
+
+func (StockAdjustmentItemModel) TableName() string {
+	return "stock_adjustment_items"
+}
+
+type StockOutletModel struct {
+	ID   uint64
+	Name string
+}
+
+func (StockOutletModel) TableName() string {
+	return "outlets"
+}
+
+type StockProductModel struct {
+	ID         uint64
+	SKU        string
+	Barcode    string
+	Name       string
+	CostPrice  float64
+	TrackStock bool
+}
+
+// UnitConversionModel is a unit one product's quantities may be given in,
+// its base unit included
+type UnitConversionModel struct {
+	ProductID    uint64  `gorm:"column:product_id"`
+	Unit         string  `gorm:"column:unit"`
+	Factor       float64 `gorm:"column:factor"`
+	AllowDecimal bool    `gorm:"column:allow_decimal"`
+	IsBase       bool    `gorm:"column:is_base"`
+}
+
+func (StockProductModel) TableName() string {
+	return "products"
+}
+
+func (m *StockProductModel) ToDomainProduct() *domain.StockProduct {
+	return &domain.StockProduct{
+		ID:         m.ID,
+		SKU:        m.SKU,
+		Barcode:    m.Barcode,
+		Name:       m.Name,
+		CostPrice:  m.CostPrice,
+		TrackStock: m.TrackStock,
+	}
+}
+
+type InventoryUserModel struct {
+	ID       uint64 `gorm:"column:id"`
+	TenantID uint64 `gorm:"column:tenant_id"`
+	RoleName string `gorm:"column:role_name"`
+}
+
+func (m *StockAdjustmentModel) ToDomainAdjustment() *domain.StockAdjustment {
+	adjustment := &domain.StockAdjustment{
+		ID:               m.ID,
+		TenantID:         m.TenantID,
+		OutletID:         m.OutletID,
+		AdjustmentNumber: m.AdjustmentNumber,
+		Status:           m.Status,
+		TotalValue:       m.TotalValue,
+		Notes:            m.Notes,
+		CreatedBy:        m.CreatedBy,
+		ReviewedBy:       m.ReviewedBy,
+		ReviewedAt:       m.ReviewedAt,
+		RejectionReason:  m.RejectionReason,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+
+	if m.Outlet != nil {
+		adjustment.OutletName = m.Outlet.Name
+	}
+
+	if m.Items != nil {
+		adjustment.Items = make([]*domain.StockAdjustmentItem, len(m.Items))
+		for i := range m.Items {
+			adjustment.Items[i] = m.Items[i].ToDomainItem()
+		}
+	}
+
+	return adjustment
+}
+
+func (m *StockAdjustmentModel) FromDomainAdjustment(adjustment *domain.StockAdjustment) {
+	m.ID = adjustment.ID
+	m.TenantID = adjustment.TenantID
+	m.OutletID = adjustment.OutletID
+	m.AdjustmentNumber = adjustment.AdjustmentNumber
+	m.Status = adjustment.Status
+	m.TotalValue = adjustment.TotalValue
+	m.Notes = adjustment.Notes
+	m.CreatedBy = adjustment.CreatedBy
+	m.ReviewedBy = adjustment.ReviewedBy
+	m.ReviewedAt = adjustment.ReviewedAt
+	m.RejectionReason = adjustment.RejectionReason
+
+	m.Items = make([]StockAdjustmentItemModel, len(adjustment.Items))
+	for i, item := range adjustment.Items {
+		m.Items[i] = StockAdjustmentItemModel{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Reason:    item.Reason,
+			UnitCost:  item.UnitCost,
+			Notes:     item.Notes,
+		}
+	}
+}
+
+func (m *StockAdjustmentItemModel) ToDomainItem() *domain.StockAdjustmentItem {
+	item := &domain.StockAdjustmentItem{
+		ID:        m.ID,
+		ProductID: m.ProductID,
+		Quantity:  m.Quantity,
+		Reason:    m.Reason,
+		UnitCost:  m.UnitCost,
+		Notes:     m.Notes,
+	}
+
+	if m.Product != nil {
+		item.SKU = m.Product.SKU
+		item.ProductName = m.Product.Name
+	}
+
+	return item
+}
+
+type StockTransferModel struct {
+	ID                  uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID            uint64 `gorm:"not null"`
+	TransferNumber      string `gorm:"size:50;not null"`
+	SourceOutletID      uint64 `gorm:"not null"`
+	DestinationOutletID uint64 `gorm:"not null"`
+	Status              string `gorm:"size:30;not null"`
+	Notes               string `gorm:"type:text"`
+	CreatedBy           uint64 `gorm:"not null"`
+	DispatchedBy        *uint64
+	DispatchedAt        *time.Time
+	ReceivedBy          *uint64
+	ReceivedAt          *time.Time
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+
+	SourceOutlet      *StockOutletModel        `gorm:"foreignKey:SourceOutletID"`
+	DestinationOutlet *StockOutletModel        `gorm:"foreignKey:DestinationOutletID"`
+	Items             []StockTransferItemModel `gorm:"foreignKey:TransferID"`
+}
+
+func (StockTransferModel) TableName() string {
+	return "stock_transfers"
+}
+
+type StockTransferItemModel struct {
+	ID                uint64  `gorm:"primaryKey;autoIncrement"`
+	TransferID        uint64  `gorm:"not null"`
+	ProductID         uint64  `gorm:"not null"`
+	Unit              string  `gorm:"size:50;not null;default:''"`
+	UnitFactor        float64 `gorm:"type:decimal(15,6);not null;default:1"`
+	Quantity          float64 `gorm:"type:decimal(15,3);not null"`
+	ReceivedQuantity  float64 `gorm:"type:decimal(15,3);not null;default:0"`
+	DiscrepancyReason string  `gorm:"type:text"`
+
+	Product *StockProductModel `gorm:"foreignKey:ProductID"`
+}
+
+func (StockTransferItemModel) TableName() string {
+	return "stock_transfer_items"
+}
+
+func (m *StockTransferModel) ToDomainTransfer() *domain.StockTransfer {
+	transfer := &domain.StockTransfer{
+		ID:                  m.ID,
+		TenantID:            m.TenantID,
+		TransferNumber:      m.TransferNumber,
+		SourceOutletID:      m.SourceOutletID,
+		DestinationOutletID: m.DestinationOutletID,
+		Status:              m.Status,
+		Notes:               m.Notes,
+		CreatedBy:           m.CreatedBy,
+		DispatchedBy:        m.DispatchedBy,
+		DispatchedAt:        m.DispatchedAt,
+		ReceivedBy:          m.ReceivedBy,
+		ReceivedAt:          m.ReceivedAt,
+		CreatedAt:           m.CreatedAt,
+		UpdatedAt:           m.UpdatedAt,
+	}
+
+	if m.SourceOutlet != nil {
+		transfer.SourceOutletName = m.SourceOutlet.Name
+	}
+	if m.DestinationOutlet != nil {
+		transfer.DestinationOutletName = m.DestinationOutlet.Name
+	}
+
+	if m.Items != nil {
+		transfer.Items = make([]*domain.StockTransferItem, len(m.Items))
+		for i := range m.Items {
+			transfer.Items[i] = m.Items[i].ToDomainItem()
+		}
+	}
+
+	return transfer
+}
+
+func (m *StockTransferModel) FromDomainTransfer(transfer *domain.StockTransfer) {
+	m.ID = transfer.ID
+	m.TenantID = transfer.TenantID
+	m.TransferNumber = transfer.TransferNumber
+	m.SourceOutletID = transfer.SourceOutletID
+	m.DestinationOutletID = transfer.DestinationOutletID
+	m.Status = transfer.Status
+	m.Notes = transfer.Notes
+	m.CreatedBy = transfer.CreatedBy
+	m.DispatchedBy = transfer.DispatchedBy
+	m.DispatchedAt = transfer.DispatchedAt
+	m.ReceivedBy = transfer.ReceivedBy
+	m.ReceivedAt = transfer.ReceivedAt
+
+	m.Items = make([]StockTransferItemModel, len(transfer.Items))
+	for i, item := range transfer.Items {
+		m.Items[i] = StockTransferItemModel{
+			TransferID:        transfer.ID,
+			ProductID:         item.ProductID,
+			Unit:              item.Unit,
+			UnitFactor:        item.UnitFactor,
+			Quantity:          item.Quantity,
+			ReceivedQuantity:  item.ReceivedQuantity,
+			DiscrepancyReason: item.DiscrepancyReason,
+		}
+	}
+}
+
+func (m *StockTransferItemModel) ToDomainItem() *domain.StockTransferItem {
+	item := &domain.StockTransferItem{
+		ID:                m.ID,
+		ProductID:         m.ProductID,
+		Unit:              m.Unit,
+		UnitFactor:        m.UnitFactor,
+		Quantity:          m.Quantity,
+		ReceivedQuantity:  m.ReceivedQuantity,
+		DiscrepancyReason: m.DiscrepancyReason,
+	}
+
+	if m.Product != nil {
+		item.SKU = m.Product.SKU
+		item.ProductName = m.Product.Name
+	}
+
+	return item
+}
+
+type StocktakeModel struct {
+	ID              uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64 `gorm:"not null"`
+	OutletID        uint64 `gorm:"not null"`
+	StocktakeNumber string `gorm:"size:50;not null"`
+	CategoryID      *uint64
+	Status          string `gorm:"size:30;not null"`
+	Notes           string `gorm:"type:text"`
+	CreatedBy       uint64 `gorm:"not null"`
+	FinalizedBy     *uint64
+	FinalizedAt     *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+
+	Outlet   *StockOutletModel   `gorm:"foreignKey:OutletID"`
+	Category *StockCategoryModel `gorm:"foreignKey:CategoryID"`
+}
+
+func (StocktakeModel) TableName() string {
+	return "stocktakes"
+}
+
+type StocktakeItemModel struct {
+	ID               uint64  `gorm:"primaryKey;autoIncrement"`
+	StocktakeID      uint64  `gorm:"not null"`
+	ProductID        uint64  `gorm:"not null"`
+	ExpectedQuantity float64 `gorm:"type:decimal(15,3);not null"`
+	CountedQuantity  *float64
+	UnitCost         float64
+	CountedAt        *time.Time
+
+	Product *StockProductModel `gorm:"foreignKey:ProductID"`
+}
+
+func (StocktakeItemModel) TableName() string {
+	return "stocktake_items"
+}
+
+type StocktakeCountModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	StocktakeID uint64    `gorm:"not null"`
+	ProductID   uint64    `gorm:"not null"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"`
+	DeviceID    string    `gorm:"size:100"`
+	CountedBy   uint64    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (StocktakeCountModel) TableName() string {
+	return "stocktake_counts"
+}
+
+type StockCategoryModel struct {
+	ID   uint64
+	Name string
+}
+
+func (StockCategoryModel) TableName() string {
+	return "product_categories"
+}
+
+// StocktakeSummaryModel is the aggregate of a stocktake's items
+type StocktakeSummaryModel struct {
+	StocktakeID   uint64  `gorm:"column:stocktake_id"`
+	ItemCount     int     `gorm:"column:item_count"`
+	CountedCount  int     `gorm:"column:counted_count"`
+	VarianceCount int     `gorm:"column:variance_count"`
+	ShortageValue float64 `gorm:"column:shortage_value"`
+	SurplusValue  float64 `gorm:"column:surplus_value"`
+}
+
+func (m *StocktakeModel) ToDomainStocktake() *domain.Stocktake {
+	stocktake := &domain.Stocktake{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		OutletID:        m.OutletID,
+		StocktakeNumber: m.StocktakeNumber,
+		CategoryID:      m.CategoryID,
+		Status:          m.Status,
+		Notes:           m.Notes,
+		CreatedBy:       m.CreatedBy,
+		FinalizedBy:     m.FinalizedBy,
+		FinalizedAt:     m.FinalizedAt,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+
+	if m.Outlet != nil {
+		stocktake.OutletName = m.Outlet.Name
+	}
+	if m.Category != nil {
+		stocktake.CategoryName = m.Category.Name
+	}
+
+	return stocktake
+}
+
+func (m *StocktakeModel) FromDomainStocktake(stocktake *domain.Stocktake) {
+	m.ID = stocktake.ID
+	m.TenantID = stocktake.TenantID
+	m.OutletID = stocktake.OutletID
+	m.StocktakeNumber = stocktake.StocktakeNumber
+	m.CategoryID = stocktake.CategoryID
+	m.Status = stocktake.Status
+	m.Notes = stocktake.Notes
+	m.CreatedBy = stocktake.CreatedBy
+	m.FinalizedBy = stocktake.FinalizedBy
+	m.FinalizedAt = stocktake.FinalizedAt
+}
+
+func (m *StocktakeSummaryModel) ToDomainSummary() domain.StocktakeSummary {
+	return domain.StocktakeSummary{
+		ItemCount:     m.ItemCount,
+		CountedCount:  m.CountedCount,
+		VarianceCount: m.VarianceCount,
+		ShortageValue: m.ShortageValue,
+		SurplusValue:  m.SurplusValue,
+	}
+}
+
+func (m *StocktakeItemModel) ToDomainItem() *domain.StocktakeItem {
+	item := &domain.StocktakeItem{
+		ID:               m.ID,
+		ProductID:        m.ProductID,
+		ExpectedQuantity: m.ExpectedQuantity,
+		CountedQuantity:  m.CountedQuantity,
+		UnitCost:         m.UnitCost,
+		CountedAt:        m.CountedAt,
+	}
+
+	if m.Product != nil {
+		item.SKU = m.Product.SKU
+		item.Barcode = m.Product.Barcode
+		item.ProductName = m.Product.Name
+	}
+
+	return item
+}
+
+type StockAlertModel struct {
+	ID              uint64  `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64  `gorm:"not null"`
+	ProductID       uint64  `gorm:"not null"`
+	OutletID        uint64  `gorm:"not null"`
+	Quantity        float64 `gorm:"type:decimal(15,3);not null"`
+	MinStock        float64 `gorm:"type:decimal(15,3);not null"`
+	Status          string  `gorm:"size:30;not null"`
+	StockMovementID *uint64
+	ReferenceType   string `gorm:"size:30;not null"`
+	ReferenceID     *uint64
+	AcknowledgedBy  *uint64
+	AcknowledgedAt  *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (StockAlertModel) TableName() string {
+	return "stock_alerts"
+}
+
+// StockAlertRowModel is an alert joined with its product, outlet and the
+// product's current stock there
+type StockAlertRowModel struct {
+	StockAlertModel
+	SKU             string  `gorm:"column:sku"`
+	ProductName     string  `gorm:"column:product_name"`
+	OutletName      string  `gorm:"column:outlet_name"`
+	CurrentQuantity float64 `gorm:"column:current_quantity"`
+}
+
+func (m *StockAlertRowModel) ToDomainAlert() *domain.StockAlert {
+	return &domain.StockAlert{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		ProductID:       m.ProductID,
+		SKU:             m.SKU,
+		ProductName:     m.ProductName,
+		OutletID:        m.OutletID,
+		OutletName:      m.OutletName,
+		Quantity:        m.Quantity,
+		MinStock:        m.MinStock,
+		CurrentQuantity: m.CurrentQuantity,
+		Status:          m.Status,
+		StockMovementID: m.StockMovementID,
+		ReferenceType:   m.ReferenceType,
+		ReferenceID:     m.ReferenceID,
+		AcknowledgedBy:  m.AcknowledgedBy,
+		AcknowledgedAt:  m.AcknowledgedAt,
+		CreatedAt:       m.CreatedAt,
+	}
+}
+
+type ReorderCandidateModel struct {
+	ProductID       uint64   `gorm:"column:product_id"`
+	SKU             string   `gorm:"column:sku"`
+	ProductName     string   `gorm:"column:product_name"`
+	Unit            string   `gorm:"column:unit"`
+	MinStock        float64  `gorm:"column:min_stock"`
+	CostPrice       float64  `gorm:"column:cost_price"`
+	Quantity        float64  `gorm:"column:quantity"`
+	SoldQuantity    float64  `gorm:"column:sold_quantity"`
+	OnOrderQuantity float64  `gorm:"column:on_order_quantity"`
+	SupplierID      *uint64  `gorm:"column:supplier_id"`
+	SupplierName    *string  `gorm:"column:supplier_name"`
+	LastUnitCost    *float64 `gorm:"column:last_unit_cost"`
+}
+
+func (m *ReorderCandidateModel) ToDomainCandidate() *domain.ReorderCandidate {
+	candidate := &domain.ReorderCandidate{
+		ProductID:       m.ProductID,
+		SKU:             m.SKU,
+		ProductName:     m.ProductName,
+		Unit:            m.Unit,
+		MinStock:        m.MinStock,
+		CostPrice:       m.CostPrice,
+		Quantity:        m.Quantity,
+		SoldQuantity:    m.SoldQuantity,
+		OnOrderQuantity: m.OnOrderQuantity,
+		SupplierID:      m.SupplierID,
+		LastUnitCost:    m.LastUnitCost,
+	}
+
+	if m.SupplierName != nil {
+		candidate.SupplierName = *m.SupplierName
+	}
+
+	return candidate
+}
+
+type StockLotModel struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID   uint64     `gorm:"not null"`
+	ProductID  uint64     `gorm:"not null"`
+	OutletID   uint64     `gorm:"not null"`
+	LotNumber  string     `gorm:"size:100;not null"`
+	ExpiryDate *time.Time `gorm:"type:date"`
+	Quantity   float64    `gorm:"type:decimal(15,3);not null;default:0"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (StockLotModel) TableName() string {
+	return "stock_lots"
+}
+
+// StockLotRowModel is a lot joined with its product and outlet
+type StockLotRowModel struct {
+	StockLotModel
+	SKU         string  `gorm:"column:sku"`
+	ProductName string  `gorm:"column:product_name"`
+	Unit        string  `gorm:"column:unit"`
+	CostPrice   float64 `gorm:"column:cost_price"`
+	OutletName  string  `gorm:"column:outlet_name"`
+}
+
+func (m *StockLotRowModel) ToDomainLot() *domain.StockLot {
+	return &domain.StockLot{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		ProductID:   m.ProductID,
+		SKU:         m.SKU,
+		ProductName: m.ProductName,
+		Unit:        m.Unit,
+		OutletID:    m.OutletID,
+		OutletName:  m.OutletName,
+		LotNumber:   m.LotNumber,
+		ExpiryDate:  m.ExpiryDate,
+		Quantity:    m.Quantity,
+		UnitCost:    m.CostPrice,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// StockLotMovementModel is a lot's share of a stock movement joined with
+// the movement
+type StockLotMovementModel struct {
+	StockMovementID uint64    `gorm:"column:stock_movement_id"`
+	MovementType    string    `gorm:"column:movement_type"`
+	ReferenceType   string    `gorm:"column:reference_type"`
+	ReferenceID     *uint64   `gorm:"column:reference_id"`
+	Quantity        float64   `gorm:"column:quantity"`
+	Notes           string    `gorm:"column:notes"`
+	CreatedBy       uint64    `gorm:"column:created_by"`
+	CreatedAt       time.Time `gorm:"column:created_at"`
+}
+
+func (m *StockLotMovementModel) ToDomainMovement() *domain.StockLotMovement {
+	return &domain.StockLotMovement{
+		StockMovementID: m.StockMovementID,
+		MovementType:    m.MovementType,
+		ReferenceType:   m.ReferenceType,
+		ReferenceID:     m.ReferenceID,
+		Quantity:        m.Quantity,
+		Notes:           m.Notes,
+		CreatedBy:       m.CreatedBy,
+		CreatedAt:       m.CreatedAt,
+	}
+}
+
+// OutletValuationModel is an outlet's closing stock summed from the ledger
+type OutletValuationModel struct {
+	OutletID     uint64  `gorm:"column:outlet_id"`
+	OutletName   string  `gorm:"column:outlet_name"`
+	ProductCount int     `gorm:"column:product_count"`
+	Quantity     float64 `gorm:"column:quantity"`
+	Value        float64 `gorm:"column:value"`
+}
+
+func (m *OutletValuationModel) ToDomainValuation() *domain.OutletValuation {
+	return &domain.OutletValuation{
+		OutletID:     m.OutletID,
+		OutletName:   m.OutletName,
+		ProductCount: m.ProductCount,
+		Quantity:     m.Quantity,
+		Value:        m.Value,
+	}
+}
+
+type CostOfGoodsSoldLineModel struct {
+	ProductID   uint64  `gorm:"column:product_id"`
+	SKU         string  `gorm:"column:sku"`
+	ProductName string  `gorm:"column:product_name"`
+	Unit        string  `gorm:"column:unit"`
+	Quantity    float64 `gorm:"column:quantity"`
+	Cost        float64 `gorm:"column:cost"`
+}
+
+func (m *CostOfGoodsSoldLineModel) ToDomainLine() *domain.CostOfGoodsSoldLine {
+	return &domain.CostOfGoodsSoldLine{
+		ProductID:   m.ProductID,
+		SKU:         m.SKU,
+		ProductName: m.ProductName,
+		Unit:        m.Unit,
+		Quantity:    m.Quantity,
+		Cost:        m.Cost,
+	}
+}
+
+// StockMovementRowModel is a stock movement with its running balance,
+// joined with its product and outlet
+type StockMovementRowModel struct {
+	ID            uint64    `gorm:"column:id"`
+	ProductID     uint64    `gorm:"column:product_id"`
+	SKU           string    `gorm:"column:sku"`
+	ProductName   string    `gorm:"column:product_name"`
+	Unit          string    `gorm:"column:unit"`
+	VariantID     *uint64   `gorm:"column:variant_id"`
+	VariantSKU    string    `gorm:"column:variant_sku"`
+	VariantName   string    `gorm:"column:variant_name"`
+	OutletID      uint64    `gorm:"column:outlet_id"`
+	OutletName    string    `gorm:"column:outlet_name"`
+	MovementType  string    `gorm:"column:movement_type"`
+	Quantity      float64   `gorm:"column:quantity"`
+	Balance       float64   `gorm:"column:balance"`
+	ReferenceType string    `gorm:"column:reference_type"`
+	ReferenceID   *uint64   `gorm:"column:reference_id"`
+	Notes         string    `gorm:"column:notes"`
+	UnitCost      *float64  `gorm:"column:unit_cost"`
+	TotalCost     *float64  `gorm:"column:total_cost"`
+	CreatedBy     uint64    `gorm:"column:created_by"`
+	CreatedAt     time.Time `gorm:"column:created_at"`
+}
+
+func (m *StockMovementRowModel) ToDomainMovement() *domain.StockMovement {
+	return &domain.StockMovement{
+		ID:            m.ID,
+		ProductID:     m.ProductID,
+		SKU:           m.SKU,
+		ProductName:   m.ProductName,
+		Unit:          m.Unit,
+		VariantID:     m.VariantID,
+		VariantSKU:    m.VariantSKU,
+		VariantName:   m.VariantName,
+		OutletID:      m.OutletID,
+		OutletName:    m.OutletName,
+		MovementType:  m.MovementType,
+		Quantity:      m.Quantity,
+		Balance:       m.Balance,
+		ReferenceType: m.ReferenceType,
+		ReferenceID:   m.ReferenceID,
+		Notes:         m.Notes,
+		UnitCost:      m.UnitCost,
+		TotalCost:     m.TotalCost,
+		CreatedBy:     m.CreatedBy,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+type StockDriftModel struct {
+	ProductID      uint64  `gorm:"column:product_id"`
+	SKU            string  `gorm:"column:sku"`
+	ProductName    string  `gorm:"column:product_name"`
+	VariantID      *uint64 `gorm:"column:variant_id"`
+	VariantSKU     string  `gorm:"column:variant_sku"`
+	VariantName    string  `gorm:"column:variant_name"`
+	OutletID       uint64  `gorm:"column:outlet_id"`
+	OutletName     string  `gorm:"column:outlet_name"`
+	Quantity       float64 `gorm:"column:quantity"`
+	LedgerQuantity float64 `gorm:"column:ledger_quantity"`
+}
+
+func (m *StockDriftModel) ToDomainDrift() *domain.StockDrift {
+	return &domain.StockDrift{
+		ProductID:      m.ProductID,
+		SKU:            m.SKU,
+		ProductName:    m.ProductName,
+		VariantID:      m.VariantID,
+		VariantSKU:     m.VariantSKU,
+		VariantName:    m.VariantName,
+		OutletID:       m.OutletID,
+		OutletName:     m.OutletName,
+		Quantity:       m.Quantity,
+		LedgerQuantity: m.LedgerQuantity,
+	}
+}
+
+// RecipeComponentModel is one component of a sold product's recipe
+type RecipeComponentModel struct {
+	ProductID   uint64  `gorm:"column:product_id"`
+	ComponentID uint64  `gorm:"column:component_id"`
+	Quantity    float64 `gorm:"column:quantity"`
+	CostPrice   float64 `gorm:"column:cost_price"`
+	TrackStock  bool    `gorm:"column:track_stock"`
+}
+
+// BundleComponentModel is a component of a sold bundle, priced at what it
+// sells for on its own
+type BundleComponentModel struct {
+	ID          uint64  `gorm:"column:id"`
+	ProductID   uint64  `gorm:"column:product_id"`
+	GroupID     *uint64 `gorm:"column:group_id"`
+	ComponentID uint64  `gorm:"column:component_id"`
+	VariantID   *uint64 `gorm:"column:variant_id"`
+	Quantity    float64 `gorm:"column:quantity"`
+	Price       float64 `gorm:"column:price"`
+}
+
+// BundleGroupModel is a choice group of a sold bundle
+type BundleGroupModel struct {
+	ID        uint64 `gorm:"column:id"`
+	ProductID uint64 `gorm:"column:product_id"`
+	PickCount int    `gorm:"column:pick_count"`
+}
+
+type TransactionItemComponentModel struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TransactionItemID uint64    `gorm:"not null"`
+	ProductID         uint64    `gorm:"not null"`
+	VariantID         *uint64   `gorm:"column:variant_id"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	AllocatedAmount   float64   `gorm:"type:decimal(15,2);not null"`
+	CostAmount        float64   `gorm:"type:decimal(15,2)"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (TransactionItemComponentModel) TableName() string {
+	return "transaction_item_components"
+}
+
+// SaleModifierGroupModel is an active modifier group a sold product offers
+type SaleModifierGroupModel struct {
+	ID        uint64 `gorm:"column:id"`
+	OfferedTo uint64 `gorm:"column:offered_to"`
+	Name      string `gorm:"column:name"`
+	MinSelect int    `gorm:"column:min_select"`
+	MaxSelect int    `gorm:"column:max_select"`
+}
+
+// SaleModifierModel is an active modifier of a group offered on a sale
+type SaleModifierModel struct {
+	ID         uint64  `gorm:"column:id"`
+	GroupID    uint64  `gorm:"column:group_id"`
+	Name       string  `gorm:"column:name"`
+	PriceDelta float64 `gorm:"column:price_delta"`
+	ProductID  *uint64 `gorm:"column:product_id"`
+	Quantity   float64 `gorm:"column:quantity"`
+}
+
+type TransactionItemModifierModel struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TransactionItemID uint64    `gorm:"not null"`
+	ModifierID        *uint64   `gorm:"column:modifier_id"`
+	GroupNameSnapshot string    `gorm:"size:100;not null"`
+	NameSnapshot      string    `gorm:"size:100;not null"`
+	PriceDelta        float64   `gorm:"type:decimal(12,2);not null"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	ProductID         *uint64   `gorm:"column:product_id"`
+	ProductQuantity   float64   `gorm:"type:decimal(15,3);not null"`
+	CostAmount        float64   `gorm:"type:decimal(15,2)"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (TransactionItemModifierModel) TableName() string {
+	return "transaction_item_modifiers"
+}
+
+// SaleVariantModel is a variant sold on a sale line
+type SaleVariantModel struct {
+	ID        uint64 `gorm:"column:id"`
+	ProductID uint64 `gorm:"column:product_id"`
+}
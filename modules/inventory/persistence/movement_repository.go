@@ -0,0 +1,212 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+)
+
+// stockDriftSQL compares every stock row of the tenant's products with the
+// sum of the product's movements at the outlet, variants kept apart under
+// variant key 0 for the product itself. Movements without a stock row and
+// stock rows without movements are compared against zero.
+const stockDriftSQL = `WITH ledger AS (
+	SELECT sm.product_id, COALESCE(sm.variant_id, 0) AS variant_key, sm.outlet_id, SUM(sm.quantity) AS quantity
+	FROM stock_movements sm
+	JOIN products p ON p.id = sm.product_id
+	WHERE p.tenant_id = @tenant_id
+	GROUP BY sm.product_id, COALESCE(sm.variant_id, 0), sm.outlet_id
+), stock AS (
+	SELECT ps.product_id, COALESCE(ps.variant_id, 0) AS variant_key, ps.outlet_id, ps.quantity
+	FROM product_stocks ps
+	JOIN products p ON p.id = ps.product_id
+	WHERE p.tenant_id = @tenant_id
+)
+SELECT p.id AS product_id, p.sku, p.name AS product_name,
+	v.id AS variant_id, COALESCE(v.sku, '') AS variant_sku, COALESCE(v.name, '') AS variant_name,
+	o.id AS outlet_id, o.name AS outlet_name,
+	COALESCE(s.quantity, 0) AS quantity, COALESCE(l.quantity, 0) AS ledger_quantity
+FROM stock s
+FULL JOIN ledger l ON l.product_id = s.product_id AND l.variant_key = s.variant_key AND l.outlet_id = s.outlet_id
+JOIN products p ON p.id = COALESCE(s.product_id, l.product_id)
+LEFT JOIN product_variants v ON v.id = COALESCE(s.variant_key, l.variant_key)
+JOIN outlets o ON o.id = COALESCE(s.outlet_id, l.outlet_id)
+WHERE COALESCE(s.quantity, 0) <> COALESCE(l.quantity, 0)
+ORDER BY p.name ASC, p.id ASC, v.name ASC NULLS FIRST, v.id ASC NULLS FIRST, o.name ASC, o.id ASC`
+
+type movementRepository struct {
+	db *gorm.DB
+}
+
+func NewMovementRepository(db *gorm.DB) domain.MovementRepository {
+	return &movementRepository{db: db}
+}
+
+// FindAll lists the ledger newest first. The running balance is summed over
+// every movement of the product or variant at the outlet before the other filters are
+// applied, so it stays the real stock level after each movement.
+func (r *movementRepository) FindAll(ctx context.Context, tenantID uint64, query domain.MovementQuery, limit, offset int) ([]*domain.StockMovement, int64, error) {
+	ledger := r.db.WithContext(ctx).
+		Table("stock_movements sm").
+		Select("sm.*, SUM(sm.quantity) OVER (PARTITION BY sm.product_id, sm.variant_id, sm.outlet_id ORDER BY sm.created_at ASC, sm.id ASC) AS balance").
+		Joins("JOIN products lp ON lp.id = sm.product_id").
+		Where("lp.tenant_id = ?", tenantID)
+	if query.ProductID != nil {
+		ledger = ledger.Where("sm.product_id = ?", *query.ProductID)
+	}
+	if query.VariantID != nil {
+		ledger = ledger.Where("sm.variant_id = ?", *query.VariantID)
+	}
+	if query.OutletID != nil {
+		ledger = ledger.Where("sm.outlet_id = ?", *query.OutletID)
+	}
+
+	filtered := r.db.WithContext(ctx).
+		Table("(?) AS m", ledger).
+		Joins("JOIN products p ON p.id = m.product_id").
+		Joins("LEFT JOIN product_variants v ON v.id = m.variant_id").
+		Joins("JOIN outlets o ON o.id = m.outlet_id").
+		Joins("JOIN tenants t ON t.id = p.tenant_id")
+	if query.MovementType != "" {
+		filtered = filtered.Where("m.movement_type = ?", query.MovementType)
+	}
+	if query.ReferenceType != "" {
+		filtered = filtered.Where("m.reference_type = ?", query.ReferenceType)
+	}
+	if query.ReferenceID != nil {
+		filtered = filtered.Where("m.reference_id = ?", *query.ReferenceID)
+	}
+	// Days are cut at midnight in the tenant's timezone
+	if query.DateFrom != nil {
+		filtered = filtered.Where("m.created_at >= CAST(CAST(? AS date) AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')",
+			query.DateFrom.Format("2006-01-02"))
+	}
+	if query.DateTo != nil {
+		filtered = filtered.Where("m.created_at < CAST(CAST(? AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')",
+			query.DateTo.Format("2006-01-02"))
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	var models []StockMovementRowModel
+	err := filtered.
+		Select("m.*, p.sku, p.name AS product_name, p.unit, COALESCE(v.sku, '') AS variant_sku, COALESCE(v.name, '') AS variant_name, o.name AS outlet_name").
+		Order("m.created_at DESC, m.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock movements: %w", err)
+	}
+
+	movements := make([]*domain.StockMovement, len(models))
+	for i := range models {
+		movements[i] = models[i].ToDomainMovement()
+	}
+
+	return movements, total, nil
+}
+
+func (r *movementRepository) FindDrift(ctx context.Context, tenantID uint64) ([]*domain.StockDrift, error) {
+	return r.findDrift(r.db.WithContext(ctx), tenantID)
+}
+
+// FixDrift sets every drifted stock row to the sum of its movements. Each
+// row is locked and its ledger summed again under the lock, since stock
+// movements are only written while holding it. Rows are locked in product,
+// variant and outlet order, like every other stock write.
+func (r *movementRepository) FixDrift(ctx context.Context, tenantID uint64) ([]*domain.StockDrift, error) {
+	var fixed []*domain.StockDrift
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		drifts, err := r.findDrift(tx, tenantID)
+		if err != nil {
+			return err
+		}
+		sort.Slice(drifts, func(i, j int) bool {
+			if drifts[i].ProductID != drifts[j].ProductID {
+				return drifts[i].ProductID < drifts[j].ProductID
+			}
+			if variantKey(drifts[i].VariantID) != variantKey(drifts[j].VariantID) {
+				return variantKey(drifts[i].VariantID) < variantKey(drifts[j].VariantID)
+			}
+			return drifts[i].OutletID < drifts[j].OutletID
+		})
+
+		for _, drift := range drifts {
+			stock, err := stockledger.LockStockRow(tx, drift.ProductID, drift.VariantID, drift.OutletID)
+			if err != nil {
+				return err
+			}
+
+			ledger := tx.Model(&StockMovementModel{}).
+				Select("COALESCE(SUM(quantity), 0)").
+				Where("product_id = ? AND outlet_id = ?", drift.ProductID, drift.OutletID)
+			if drift.VariantID != nil {
+				ledger = ledger.Where("variant_id = ?", *drift.VariantID)
+			} else {
+				ledger = ledger.Where("variant_id IS NULL")
+			}
+
+			var ledgerQuantity float64
+			if err := ledger.Scan(&ledgerQuantity).Error; err != nil {
+				return fmt.Errorf("failed to sum stock movements: %w", err)
+			}
+			if stock.Quantity == ledgerQuantity {
+				continue
+			}
+			drift.Quantity = stock.Quantity
+			drift.LedgerQuantity = ledgerQuantity
+
+			err = tx.Model(&stock).Updates(map[string]interface{}{
+				"quantity":   ledgerQuantity,
+				"updated_at": time.Now(),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update product stock: %w", err)
+			}
+
+			fixed = append(fixed, drift)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fixed, nil
+}
+
+func (r *movementRepository) findDrift(db *gorm.DB, tenantID uint64) ([]*domain.StockDrift, error) {
+	var models []StockDriftModel
+
+	err := db.Raw(stockDriftSQL, map[string]interface{}{"tenant_id": tenantID}).
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock drift: %w", err)
+	}
+
+	drifts := make([]*domain.StockDrift, len(models))
+	for i := range models {
+		drifts[i] = models[i].ToDomainDrift()
+	}
+
+	return drifts, nil
+}
+
+// variantKey orders the product's own stock before its variants
+func variantKey(variantID *uint64) uint64 {
+	if variantID == nil {
+		return 0
+	}
+	return *variantID
+}
@@ -0,0 +1,548 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+)
+
+type saleRepository struct {
+	db *gorm.DB
+}
+
+func NewSaleRepository(db *gorm.DB) domain.SaleRepository {
+	return &saleRepository{db: db}
+}
+
+// saleConsumption is the stock one sale line takes of one product, either
+// the sold product or variant itself, a component of its recipe or bundle
+// or the product of a picked modifier. Cost is the cost of the bundle
+// component or modifier the stock is taken for, if any.
+type saleConsumption struct {
+	Line      *domain.SaleLine
+	Cost      *float64
+	ProductID uint64
+	VariantID *uint64
+	Quantity  float64
+	Notes     string
+}
+
+// saleBundle is a sold bundle: the components of every bundle, the choices
+// by bundle item ID and the groups they are chosen from
+type saleBundle struct {
+	Items   []BundleComponentModel
+	Choices map[uint64]*BundleComponentModel
+	Groups  []BundleGroupModel
+}
+
+// pick returns the components of one bundle sold with the choices, which
+// must pick as many items of every group as it asks for
+func (b *saleBundle) pick(choices []uint64) ([]BundleComponentModel, error) {
+	items := append([]BundleComponentModel(nil), b.Items...)
+	picked := make(map[uint64]int, len(b.Groups))
+	for _, choice := range choices {
+		item, ok := b.Choices[choice]
+		if !ok {
+			return nil, errors.New("invalid bundle choice")
+		}
+		picked[*item.GroupID]++
+		items = append(items, *item)
+	}
+
+	for _, group := range b.Groups {
+		if picked[group.ID] != group.PickCount {
+			return nil, errors.New("bundle choices do not match its groups")
+		}
+	}
+
+	return items, nil
+}
+
+// saleModifierGroup is an active modifier group a sold product offers, with
+// its active modifiers
+type saleModifierGroup struct {
+	SaleModifierGroupModel
+	Modifiers []SaleModifierModel
+}
+
+// saleModifierGroups are the groups one sold product offers
+type saleModifierGroups []*saleModifierGroup
+
+// pick returns the modifiers picked for one base unit of the product, in group
+// order. Every pick must be a modifier the product offers, picked once, and
+// every group must get from its minimum up to its maximum picks.
+func (g saleModifierGroups) pick(modifierIDs []uint64) ([]*domain.SaleModifier, error) {
+	picked := make(map[uint64]bool, len(modifierIDs))
+	for _, id := range modifierIDs {
+		if picked[id] {
+			return nil, errors.New("modifier is picked more than once")
+		}
+		picked[id] = true
+	}
+
+	var modifiers []*domain.SaleModifier
+	for _, group := range g {
+		count := 0
+		for _, modifier := range group.Modifiers {
+			if !picked[modifier.ID] {
+				continue
+			}
+			delete(picked, modifier.ID)
+			count++
+
+			saleModifier := &domain.SaleModifier{
+				ModifierID: modifier.ID,
+				GroupName:  group.Name,
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+				ProductID:  modifier.ProductID,
+			}
+			if modifier.ProductID != nil {
+				saleModifier.ProductQuantity = modifier.Quantity
+			}
+			modifiers = append(modifiers, saleModifier)
+		}
+		if count < group.MinSelect || count > group.MaxSelect {
+			return nil, errors.New("modifiers do not match the product's modifier groups")
+		}
+	}
+
+	if len(picked) > 0 {
+		return nil, errors.New("invalid modifier")
+	}
+
+	return modifiers, nil
+}
+
+// Record takes a sale from the outlet's stock in one transaction. A tracked
+// product is taken from stock itself, or from its variant's stock, and the
+// tracked components of its recipe are taken along with it. Untracked
+// products and components are costed at their cost price. A bundle takes
+// its components instead of itself, each as if sold on its own, and records
+// them against the transaction item with their share of the line amount.
+// The modifiers picked for a line are checked against the groups its
+// product offers, take their products from stock like components and are
+// stored on the transaction item as they were at the sale. Lines sold in
+// another unit than the base unit take their quantity converted to it, and
+// store the conversion on their transaction item when they name one.
+// Called inside checkout's transaction, the sale is recorded in it.
+func (r *saleRepository) Record(ctx context.Context, tenantID uint64, sale *domain.Sale) error {
+	return database.InTransaction(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		productIDs := make([]uint64, len(sale.Lines))
+		var variantIDs []uint64
+		for i, line := range sale.Lines {
+			productIDs[i] = line.ProductID
+			if line.VariantID != nil {
+				variantIDs = append(variantIDs, *line.VariantID)
+			}
+		}
+
+		bundles, err := findSaleBundles(tx, productIDs)
+		if err != nil {
+			return err
+		}
+
+		modifierGroups, err := findSaleModifierGroups(tx, tenantID, productIDs)
+		if err != nil {
+			return err
+		}
+
+		units, err := findProductUnits(tx, tenantID, productIDs)
+		if err != nil {
+			return err
+		}
+
+		// Components of bundles are costed and taken from stock like sold
+		// products
+		stockIDs := productIDs
+		for _, bundle := range bundles {
+			for _, item := range bundle.Items {
+				stockIDs = append(stockIDs, item.ComponentID)
+			}
+			for _, item := range bundle.Choices {
+				stockIDs = append(stockIDs, item.ComponentID)
+			}
+		}
+		for _, groups := range modifierGroups {
+			for _, group := range groups {
+				for _, modifier := range group.Modifiers {
+					if modifier.ProductID != nil {
+						stockIDs = append(stockIDs, *modifier.ProductID)
+					}
+				}
+			}
+		}
+
+		var products []StockProductModel
+		err = tx.Where("tenant_id = ? AND id IN ?", tenantID, stockIDs).Find(&products).Error
+		if err != nil {
+			return fmt.Errorf("failed to find products: %w", err)
+		}
+		productsByID := make(map[uint64]*StockProductModel, len(products))
+		for i := range products {
+			productsByID[products[i].ID] = &products[i]
+		}
+
+		variantProducts := make(map[uint64]uint64, len(variantIDs))
+		if len(variantIDs) > 0 {
+			var variants []SaleVariantModel
+			err := tx.Table("product_variants").
+				Select("id, product_id").
+				Where("tenant_id = ? AND id IN ?", tenantID, variantIDs).
+				Find(&variants).Error
+			if err != nil {
+				return fmt.Errorf("failed to find variants: %w", err)
+			}
+			for _, variant := range variants {
+				variantProducts[variant.ID] = variant.ProductID
+			}
+		}
+
+		var components []RecipeComponentModel
+		err = tx.Table("product_recipe_items ri").
+			Select("ri.product_id, ri.component_id, ri.quantity, c.cost_price, c.track_stock").
+			Joins("JOIN products c ON c.id = ri.component_id").
+			Where("ri.product_id IN ?", stockIDs).
+			Order("ri.component_id ASC").
+			Find(&components).Error
+		if err != nil {
+			return fmt.Errorf("failed to find recipes: %w", err)
+		}
+		recipes := make(map[uint64][]RecipeComponentModel)
+		for _, component := range components {
+			recipes[component.ProductID] = append(recipes[component.ProductID], component)
+		}
+
+		var consumptions []saleConsumption
+
+		// take adds what selling quantity of the product takes from stock,
+		// costing what is not tracked. The cost is added to attributed as
+		// well, when given.
+		take := func(line *domain.SaleLine, attributed *float64, product *StockProductModel, variantID *uint64, quantity float64, notes string) {
+			addCost := func(cost float64) {
+				line.Cost += cost
+				if attributed != nil {
+					*attributed += cost
+				}
+			}
+
+			recipe := recipes[product.ID]
+			if product.TrackStock {
+				consumptions = append(consumptions, saleConsumption{
+					Line:      line,
+					Cost:      attributed,
+					ProductID: product.ID,
+					VariantID: variantID,
+					Quantity:  quantity,
+					Notes:     notes,
+				})
+			} else if len(recipe) == 0 {
+				addCost(quantity * product.CostPrice)
+			}
+
+			for _, recipeComponent := range recipe {
+				componentQuantity := domain.RoundQuantity(quantity * recipeComponent.Quantity)
+				if !recipeComponent.TrackStock {
+					addCost(componentQuantity * recipeComponent.CostPrice)
+					continue
+				}
+				consumptions = append(consumptions, saleConsumption{
+					Line:      line,
+					Cost:      attributed,
+					ProductID: recipeComponent.ComponentID,
+					Quantity:  componentQuantity,
+					Notes:     fmt.Sprintf("Recipe of %s", product.SKU),
+				})
+			}
+		}
+
+		for _, line := range sale.Lines {
+			line.Cost = 0
+			line.Components = nil
+
+			product, ok := productsByID[line.ProductID]
+			if !ok {
+				return errors.New("product not found")
+			}
+			if line.VariantID != nil && variantProducts[*line.VariantID] != line.ProductID {
+				return errors.New("variant not found")
+			}
+
+			conversion, quantity, err := units[line.ProductID].Convert(line.Unit, line.Quantity)
+			if err != nil {
+				return err
+			}
+			line.Unit = conversion.Unit
+			line.UnitFactor = conversion.Factor
+
+			line.Modifiers, err = modifierGroups[line.ProductID].pick(line.ModifierIDs)
+			if err != nil {
+				return err
+			}
+			if len(line.Modifiers) > 0 && line.TransactionItemID == 0 {
+				return errors.New("sale lines with modifiers need their transaction item")
+			}
+			// Modifiers are picked per base unit, like the product is taken
+			// from stock: a modifier on a dozen sold counts and consumes
+			// twelve times
+			for _, modifier := range line.Modifiers {
+				modifier.Quantity = quantity
+				if modifier.ProductID == nil {
+					continue
+				}
+				modifierProduct, ok := productsByID[*modifier.ProductID]
+				if !ok {
+					return errors.New("product not found")
+				}
+				modifier.ProductQuantity = domain.RoundQuantity(modifier.ProductQuantity * quantity)
+				take(line, &modifier.Cost, modifierProduct, nil, modifier.ProductQuantity,
+					fmt.Sprintf("Modifier %s of %s", modifier.Name, product.SKU))
+			}
+
+			bundle, ok := bundles[line.ProductID]
+			if !ok {
+				if len(line.Choices) > 0 {
+					return errors.New("only bundles have choices")
+				}
+				take(line, nil, product, line.VariantID, quantity, "")
+				continue
+			}
+
+			if line.TransactionItemID == 0 {
+				return errors.New("bundle sale lines need their transaction item")
+			}
+
+			items, err := bundle.pick(line.Choices)
+			if err != nil {
+				return err
+			}
+
+			weights := make([]float64, len(items))
+			for i, item := range items {
+				weights[i] = item.Quantity * item.Price
+			}
+			amounts := domain.AllocateAmount(line.Amount, weights)
+
+			for i, item := range items {
+				componentProduct, ok := productsByID[item.ComponentID]
+				if !ok {
+					return errors.New("product not found")
+				}
+				component := &domain.SaleComponent{
+					ProductID: item.ComponentID,
+					VariantID: item.VariantID,
+					Quantity:  domain.RoundQuantity(quantity * item.Quantity),
+					Amount:    amounts[i],
+				}
+				line.Components = append(line.Components, component)
+				take(line, &component.Cost, componentProduct, item.VariantID, component.Quantity,
+					fmt.Sprintf("Bundle %s", product.SKU))
+			}
+		}
+
+		// Lock stock rows in product and variant order so concurrent sales
+		// sharing ingredients cannot deadlock
+		sort.SliceStable(consumptions, func(i, j int) bool {
+			if consumptions[i].ProductID != consumptions[j].ProductID {
+				return consumptions[i].ProductID < consumptions[j].ProductID
+			}
+			return variantKey(consumptions[i].VariantID) < variantKey(consumptions[j].VariantID)
+		})
+
+		for _, consumption := range consumptions {
+			movementType := domain.MovementTypeOut
+			if consumption.Quantity < 0 {
+				movementType = domain.MovementTypeIn
+			}
+
+			movement, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     consumption.ProductID,
+				VariantID:     consumption.VariantID,
+				OutletID:      sale.OutletID,
+				Quantity:      -consumption.Quantity,
+				MovementType:  movementType,
+				ReferenceType: domain.ReferenceTypeSale,
+				ReferenceID:   sale.TransactionID,
+				Notes:         consumption.Notes,
+				CreatedBy:     sale.CreatedBy,
+			})
+			if err != nil {
+				return err
+			}
+
+			consumption.Line.Cost -= *movement.TotalCost
+			if consumption.Cost != nil {
+				*consumption.Cost -= *movement.TotalCost
+			}
+		}
+
+		var records []TransactionItemComponentModel
+		var modifierRecords []TransactionItemModifierModel
+		for _, line := range sale.Lines {
+			if line.UnitFactor != 1 && line.TransactionItemID != 0 {
+				err := tx.Table("transaction_items").
+					Where("id = ?", line.TransactionItemID).
+					Update("unit_factor", line.UnitFactor).Error
+				if err != nil {
+					return fmt.Errorf("failed to record sale unit: %w", err)
+				}
+			}
+			line.Cost = math.Round(line.Cost*100) / 100
+			for _, component := range line.Components {
+				component.Cost = math.Round(component.Cost*100) / 100
+				records = append(records, TransactionItemComponentModel{
+					TransactionItemID: line.TransactionItemID,
+					ProductID:         component.ProductID,
+					VariantID:         component.VariantID,
+					Quantity:          component.Quantity,
+					AllocatedAmount:   component.Amount,
+					CostAmount:        component.Cost,
+				})
+			}
+			for _, modifier := range line.Modifiers {
+				modifier.Cost = math.Round(modifier.Cost*100) / 100
+				modifierID := modifier.ModifierID
+				modifierRecords = append(modifierRecords, TransactionItemModifierModel{
+					TransactionItemID: line.TransactionItemID,
+					ModifierID:        &modifierID,
+					GroupNameSnapshot: modifier.GroupName,
+					NameSnapshot:      modifier.Name,
+					PriceDelta:        modifier.PriceDelta,
+					Quantity:          modifier.Quantity,
+					ProductID:         modifier.ProductID,
+					ProductQuantity:   modifier.ProductQuantity,
+					CostAmount:        modifier.Cost,
+				})
+			}
+		}
+
+		if len(records) > 0 {
+			if err := tx.Create(&records).Error; err != nil {
+				return fmt.Errorf("failed to record bundle components: %w", err)
+			}
+		}
+
+		if len(modifierRecords) > 0 {
+			if err := tx.Create(&modifierRecords).Error; err != nil {
+				return fmt.Errorf("failed to record modifiers: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// findSaleBundles returns the bundles among the sold products, keyed by
+// product ID
+func findSaleBundles(tx *gorm.DB, productIDs []uint64) (map[uint64]*saleBundle, error) {
+	var items []BundleComponentModel
+	err := tx.Table("product_bundle_items bi").
+		Select("bi.id, bi.product_id, bi.group_id, bi.component_id, bi.variant_id, bi.quantity, "+
+			"COALESCE(v.selling_price, c.selling_price) AS price").
+		Joins("JOIN products c ON c.id = bi.component_id").
+		Joins("LEFT JOIN product_variants v ON v.id = bi.variant_id").
+		Where("bi.product_id IN ?", productIDs).
+		Order("bi.id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundles: %w", err)
+	}
+
+	bundles := make(map[uint64]*saleBundle)
+	for i := range items {
+		bundle, ok := bundles[items[i].ProductID]
+		if !ok {
+			bundle = &saleBundle{Choices: make(map[uint64]*BundleComponentModel)}
+			bundles[items[i].ProductID] = bundle
+		}
+		if items[i].GroupID == nil {
+			bundle.Items = append(bundle.Items, items[i])
+		} else {
+			bundle.Choices[items[i].ID] = &items[i]
+		}
+	}
+	if len(bundles) == 0 {
+		return bundles, nil
+	}
+
+	var groups []BundleGroupModel
+	err = tx.Table("product_bundle_groups").
+		Select("id, product_id, pick_count").
+		Where("product_id IN ?", productIDs).
+		Order("sort_order ASC, id ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle groups: %w", err)
+	}
+	for _, group := range groups {
+		if bundle, ok := bundles[group.ProductID]; ok {
+			bundle.Groups = append(bundle.Groups, group)
+		}
+	}
+
+	return bundles, nil
+}
+
+// findSaleModifierGroups returns the active modifier groups the sold
+// products offer, through their category or on their own, keyed by product
+// ID
+func findSaleModifierGroups(tx *gorm.DB, tenantID uint64, productIDs []uint64) (map[uint64]saleModifierGroups, error) {
+	var models []SaleModifierGroupModel
+	err := tx.Raw(
+		"SELECT DISTINCT ON (o.offered_to, g.id) g.id, o.offered_to, g.name, g.min_select, g.max_select FROM ("+
+			"SELECT p.id AS offered_to, cmg.modifier_group_id FROM products p "+
+			"JOIN category_modifier_groups cmg ON cmg.category_id = p.category_id "+
+			"WHERE p.tenant_id = @tenant AND p.id IN @products "+
+			"UNION ALL "+
+			"SELECT pmg.product_id, pmg.modifier_group_id FROM product_modifier_groups pmg WHERE pmg.product_id IN @products"+
+			") o JOIN modifier_groups g ON g.id = o.modifier_group_id AND g.tenant_id = @tenant AND g.is_active = true "+
+			"ORDER BY o.offered_to, g.id",
+		map[string]interface{}{
+			"tenant":   tenantID,
+			"products": productIDs,
+		},
+	).Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find modifier groups: %w", err)
+	}
+
+	offered := make(map[uint64]saleModifierGroups)
+	if len(models) == 0 {
+		return offered, nil
+	}
+
+	groups := make(map[uint64][]*saleModifierGroup)
+	var groupIDs []uint64
+	for _, model := range models {
+		group := &saleModifierGroup{SaleModifierGroupModel: model}
+		offered[model.OfferedTo] = append(offered[model.OfferedTo], group)
+		if _, ok := groups[model.ID]; !ok {
+			groupIDs = append(groupIDs, model.ID)
+		}
+		groups[model.ID] = append(groups[model.ID], group)
+	}
+
+	var modifiers []SaleModifierModel
+	err = tx.Table("modifiers").
+		Select("id, group_id, name, price_delta, product_id, quantity").
+		Where("group_id IN ? AND is_active = ?", groupIDs, true).
+		Order("sort_order ASC, id ASC").
+		Find(&modifiers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find modifiers: %w", err)
+	}
+	for _, modifier := range modifiers {
+		for _, group := range groups[modifier.GroupID] {
+			group.Modifiers = append(group.Modifiers, modifier)
+		}
+	}
+
+	return offered, nil
+}
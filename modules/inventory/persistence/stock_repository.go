@@ -0,0 +1,472 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"gorm.io/gorm"
+)
+
+const stockLevelColumns = "p.id AS product_id, p.sku, p.barcode, p.name AS product_name, p.unit, " +
+	"p.category_id, pc.name AS category_name, p.min_stock, " +
+	"o.id AS outlet_id, o.name AS outlet_name, o.code AS outlet_code, " +
+	"COALESCE(ps.quantity, 0) AS quantity, COALESCE(ps.reserved_quantity, 0) AS reserved_quantity, ps.updated_at"
+
+type stockRepository struct {
+	db *gorm.DB
+}
+
+func NewStockRepository(db *gorm.DB) domain.StockRepository {
+	return &stockRepository{db: db}
+}
+
+// stockLevels pairs every tracked product of the tenant with every outlet,
+// so outlets without a product_stocks row show up with zero stock. Inactive
+// outlets are only included while they still hold a stock row.
+func (r *stockRepository) stockLevels(ctx context.Context, tenantID uint64) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("products p").
+		Joins("JOIN outlets o ON o.tenant_id = p.tenant_id").
+		Joins("LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.outlet_id = o.id AND ps.variant_id IS NULL").
+		Joins("LEFT JOIN product_categories pc ON pc.id = p.category_id").
+		Where("p.tenant_id = ? AND p.track_stock = ?", tenantID, true).
+		Where("o.is_active = ? OR ps.id IS NOT NULL", true)
+}
+
+func (r *stockRepository) FindAll(ctx context.Context, tenantID uint64, query domain.StockQuery, limit, offset int) ([]*domain.StockLevel, int64, error) {
+	filtered := r.stockLevels(ctx, tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("o.id = ?", *query.OutletID)
+	}
+	if query.CategoryID != nil {
+		filtered = filtered.Where("p.category_id = ?", *query.CategoryID)
+	}
+	if query.ProductID != nil {
+		filtered = filtered.Where("p.id = ?", *query.ProductID)
+	}
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + search + "%"
+		filtered = filtered.Where("p.name ILIKE ? OR p.sku ILIKE ? OR p.barcode = ?", pattern, pattern, search)
+	}
+	if query.LowStock {
+		filtered = filtered.Where("COALESCE(ps.quantity, 0) <= p.min_stock")
+	}
+	if query.ZeroStock {
+		filtered = filtered.Where("COALESCE(ps.quantity, 0) <= 0")
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock levels: %w", err)
+	}
+
+	var models []StockLevelModel
+	err := filtered.
+		Select(stockLevelColumns).
+		Order("p.name ASC, o.name ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock levels: %w", err)
+	}
+
+	levels := make([]*domain.StockLevel, len(models))
+	for i := range models {
+		levels[i] = models[i].ToDomainStockLevel()
+	}
+
+	return levels, total, nil
+}
+
+func (r *stockRepository) FindByProduct(ctx context.Context, tenantID, productID uint64) (*domain.ProductStock, error) {
+	var models []StockLevelModel
+
+	err := r.stockLevels(ctx, tenantID).
+		Select(stockLevelColumns).
+		Where("p.id = ?", productID).
+		Order("o.name ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product stock: %w", err)
+	}
+
+	if len(models) == 0 {
+		// Either the product does not exist or it is not tracked, or the
+		// tenant has no outlets yet
+		var product struct {
+			ID         uint64
+			SKU        string
+			Name       string
+			Unit       string
+			MinStock   float64
+			TrackStock bool
+		}
+		err := r.db.WithContext(ctx).
+			Table("products").
+			Select("id, sku, name, unit, min_stock, track_stock").
+			Where("id = ? AND tenant_id = ?", productID, tenantID).
+			Take(&product).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("product not found")
+			}
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if !product.TrackStock {
+			return nil, errors.New("product does not track stock")
+		}
+
+		return &domain.ProductStock{
+			ProductID:   product.ID,
+			SKU:         product.SKU,
+			ProductName: product.Name,
+			Unit:        product.Unit,
+			MinStock:    product.MinStock,
+			Outlets:     []*domain.StockLevel{},
+		}, nil
+	}
+
+	productStock := &domain.ProductStock{
+		ProductID:   models[0].ProductID,
+		SKU:         models[0].SKU,
+		ProductName: models[0].ProductName,
+		Unit:        models[0].Unit,
+		MinStock:    models[0].MinStock,
+		Outlets:     make([]*domain.StockLevel, len(models)),
+	}
+	for i := range models {
+		productStock.Outlets[i] = models[i].ToDomainStockLevel()
+	}
+
+	return productStock, nil
+}
+
+func (r *stockRepository) FindUser(ctx context.Context, tenantID, userID uint64) (*domain.InventoryUser, error) {
+	var model InventoryUserModel
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.id, users.tenant_id, roles.name AS role_name").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.id = ? AND users.tenant_id = ?", userID, tenantID).
+		Take(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return &domain.InventoryUser{
+		ID:       model.ID,
+		TenantID: model.TenantID,
+		RoleName: model.RoleName,
+	}, nil
+}
+
+func (r *stockRepository) OutletExists(ctx context.Context, tenantID, outletID uint64) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Table("outlets").
+		Where("id = ? AND tenant_id = ? AND is_active = ?", outletID, tenantID, true).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to find outlet: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *stockRepository) FindProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*domain.StockProduct, error) {
+	var models []StockProductModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, productIDs).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+
+	products := make(map[uint64]*domain.StockProduct, len(models))
+	for i := range models {
+		products[models[i].ID] = models[i].ToDomainProduct()
+	}
+
+	return products, nil
+}
+
+func (r *stockRepository) FindProductUnits(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*domain.ProductUnits, error) {
+	return findProductUnits(r.db.WithContext(ctx), tenantID, productIDs)
+}
+
+// productUnitsSQL lists the base unit of every product, whose decimals
+// follow the tenant's unit of the same code, and the units converted to it
+const productUnitsSQL = `SELECT p.id AS product_id, p.unit, 1 AS factor,
+	COALESCE(u.allow_decimal, FALSE) AS allow_decimal, TRUE AS is_base
+FROM products p
+LEFT JOIN units u ON u.tenant_id = p.tenant_id AND u.code = p.unit
+WHERE p.tenant_id = @tenant_id AND p.id IN @product_ids
+UNION ALL
+SELECT pu.product_id, u.code, pu.factor, u.allow_decimal, FALSE
+FROM product_units pu
+JOIN units u ON u.id = pu.unit_id
+WHERE u.tenant_id = @tenant_id AND pu.product_id IN @product_ids`
+
+// findProductUnits returns the units the products' quantities may be given
+// in, keyed by product ID
+func findProductUnits(tx *gorm.DB, tenantID uint64, productIDs []uint64) (map[uint64]*domain.ProductUnits, error) {
+	units := make(map[uint64]*domain.ProductUnits, len(productIDs))
+	if len(productIDs) == 0 {
+		return units, nil
+	}
+
+	var models []UnitConversionModel
+	err := tx.Raw(productUnitsSQL, map[string]interface{}{
+		"tenant_id":   tenantID,
+		"product_ids": productIDs,
+	}).Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product units: %w", err)
+	}
+
+	for _, model := range models {
+		productUnits, ok := units[model.ProductID]
+		if !ok {
+			productUnits = &domain.ProductUnits{Units: make(map[string]*domain.UnitConversion)}
+			units[model.ProductID] = productUnits
+		}
+		if model.IsBase {
+			productUnits.BaseUnit = model.Unit
+		}
+		productUnits.Units[model.Unit] = &domain.UnitConversion{
+			Unit:         model.Unit,
+			Factor:       model.Factor,
+			AllowDecimal: model.AllowDecimal,
+		}
+	}
+
+	return units, nil
+}
+
+// FindProductByBarcode looks a scanned barcode up the same way the product
+// catalogue does: an exact match within the tenant.
+func (r *stockRepository) FindProductByBarcode(ctx context.Context, tenantID uint64, barcode string) (*domain.StockProduct, error) {
+	var model StockProductModel
+
+	err := r.db.WithContext(ctx).
+		Where("barcode = ? AND tenant_id = ?", barcode, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, fmt.Errorf("failed to find product by barcode: %w", err)
+	}
+
+	return model.ToDomainProduct(), nil
+}
+
+func (r *stockRepository) CategoryExists(ctx context.Context, tenantID, categoryID uint64) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Table("product_categories").
+		Where("id = ? AND tenant_id = ?", categoryID, tenantID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to find category: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// reorderCandidatesSQL lists the outlet's active tracked products that sold
+// since @since, on their own, in a bundle or with a modifier, or are at
+// their minimum stock,
+// with the quantity on open purchase orders and the supplier and unit cost
+// of the latest goods receipt. Quantities and costs are in base units.
+const reorderCandidatesSQL = `WITH sold_items AS (
+	SELECT ti.product_id, ti.quantity * ti.unit_factor
+	FROM transaction_items ti
+	JOIN transactions t ON t.id = ti.transaction_id
+	WHERE t.tenant_id = @tenant_id AND t.outlet_id = @outlet_id
+	AND t.status = 'completed' AND t.transaction_date >= @since
+	UNION ALL
+	SELECT tic.product_id, tic.quantity
+	FROM transaction_item_components tic
+	JOIN transaction_items ti ON ti.id = tic.transaction_item_id
+	JOIN transactions t ON t.id = ti.transaction_id
+	WHERE t.tenant_id = @tenant_id AND t.outlet_id = @outlet_id
+	AND t.status = 'completed' AND t.transaction_date >= @since
+	UNION ALL
+	SELECT tim.product_id, tim.product_quantity
+	FROM transaction_item_modifiers tim
+	JOIN transaction_items ti ON ti.id = tim.transaction_item_id
+	JOIN transactions t ON t.id = ti.transaction_id
+	WHERE t.tenant_id = @tenant_id AND t.outlet_id = @outlet_id
+	AND t.status = 'completed' AND t.transaction_date >= @since
+	AND tim.product_id IS NOT NULL
+), sales AS (
+	SELECT product_id, SUM(quantity) AS sold
+	FROM sold_items
+	GROUP BY product_id
+), on_order AS (
+	SELECT poi.product_id, SUM((poi.quantity - poi.received_quantity) * poi.unit_factor) AS quantity
+	FROM purchase_order_items poi
+	JOIN purchase_orders po ON po.id = poi.purchase_order_id
+	WHERE po.tenant_id = @tenant_id AND po.outlet_id = @outlet_id
+	AND po.status IN ('ordered', 'partially_received')
+	GROUP BY poi.product_id
+), last_receipt AS (
+	SELECT DISTINCT ON (gri.product_id) gri.product_id, gr.supplier_id,
+		ROUND(gri.unit_cost / gri.unit_factor, 2) AS unit_cost
+	FROM goods_receipt_items gri
+	JOIN goods_receipts gr ON gr.id = gri.goods_receipt_id
+	WHERE gr.tenant_id = @tenant_id
+	ORDER BY gri.product_id, gr.created_at DESC, gri.id DESC
+)
+SELECT p.id AS product_id, p.sku, p.name AS product_name, p.unit, p.min_stock, p.cost_price,
+	COALESCE(ps.quantity, 0) AS quantity, COALESCE(s.sold, 0) AS sold_quantity,
+	COALESCE(oo.quantity, 0) AS on_order_quantity,
+	lr.supplier_id, sup.name AS supplier_name, lr.unit_cost AS last_unit_cost
+FROM products p
+LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.outlet_id = @outlet_id AND ps.variant_id IS NULL
+LEFT JOIN sales s ON s.product_id = p.id
+LEFT JOIN on_order oo ON oo.product_id = p.id
+LEFT JOIN last_receipt lr ON lr.product_id = p.id
+LEFT JOIN suppliers sup ON sup.id = lr.supplier_id
+WHERE p.tenant_id = @tenant_id AND p.track_stock = TRUE AND p.is_active = TRUE
+AND (s.sold > 0 OR COALESCE(ps.quantity, 0) <= p.min_stock)
+ORDER BY p.name ASC, p.id ASC`
+
+func (r *stockRepository) FindReorderCandidates(ctx context.Context, tenantID, outletID uint64, since time.Time) ([]*domain.ReorderCandidate, error) {
+	var models []ReorderCandidateModel
+
+	err := r.db.WithContext(ctx).
+		Raw(reorderCandidatesSQL, map[string]interface{}{
+			"tenant_id": tenantID,
+			"outlet_id": outletID,
+			"since":     since,
+		}).
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reorder candidates: %w", err)
+	}
+
+	candidates := make([]*domain.ReorderCandidate, len(models))
+	for i := range models {
+		candidates[i] = models[i].ToDomainCandidate()
+	}
+
+	return candidates, nil
+}
+
+// valuationSQL sums every product's movements at every outlet up to the end
+// of the day, midnight in the tenant's timezone, into its closing quantity
+// and value. Movements recorded before costing have no total cost and are
+// valued at the product's current cost price.
+const valuationSQL = `WITH closing AS (
+	SELECT sm.outlet_id, sm.product_id, SUM(sm.quantity) AS quantity,
+		SUM(COALESCE(sm.total_cost, sm.quantity * p.cost_price)) AS value
+	FROM stock_movements sm
+	JOIN products p ON p.id = sm.product_id
+	JOIN tenants t ON t.id = p.tenant_id
+	WHERE p.tenant_id = @tenant_id
+	AND sm.created_at < CAST(CAST(@as_of AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')
+	GROUP BY sm.outlet_id, sm.product_id
+)
+SELECT o.id AS outlet_id, o.name AS outlet_name,
+	COUNT(c.product_id) FILTER (WHERE c.quantity <> 0) AS product_count,
+	COALESCE(SUM(c.quantity), 0) AS quantity,
+	COALESCE(ROUND(SUM(c.value), 2), 0) AS value
+FROM outlets o
+LEFT JOIN closing c ON c.outlet_id = o.id
+WHERE o.tenant_id = @tenant_id AND (o.is_active = TRUE OR c.outlet_id IS NOT NULL) %s
+GROUP BY o.id, o.name
+ORDER BY o.name ASC, o.id ASC`
+
+func (r *stockRepository) FindValuation(ctx context.Context, tenantID uint64, query domain.ValuationQuery) ([]*domain.OutletValuation, error) {
+	params := map[string]interface{}{
+		"tenant_id": tenantID,
+		"as_of":     query.AsOf.Format("2006-01-02"),
+	}
+
+	filter := ""
+	if query.OutletID != nil {
+		filter = "AND o.id = @outlet_id"
+		params["outlet_id"] = *query.OutletID
+	}
+
+	var models []OutletValuationModel
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf(valuationSQL, filter), params).
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock valuation: %w", err)
+	}
+
+	outlets := make([]*domain.OutletValuation, len(models))
+	for i := range models {
+		outlets[i] = models[i].ToDomainValuation()
+	}
+
+	return outlets, nil
+}
+
+// costOfGoodsSoldSQL nets the sale movements of every product over the
+// days, cut at midnight in the tenant's timezone, so returned stock reduces
+// the cost of goods sold. Sales recorded before costing are costed at the
+// product's current cost price.
+const costOfGoodsSoldSQL = `SELECT p.id AS product_id, p.sku, p.name AS product_name, p.unit,
+	-SUM(sm.quantity) AS quantity,
+	-ROUND(SUM(COALESCE(sm.total_cost, sm.quantity * p.cost_price)), 2) AS cost
+FROM stock_movements sm
+JOIN products p ON p.id = sm.product_id
+JOIN tenants t ON t.id = p.tenant_id
+WHERE p.tenant_id = @tenant_id AND sm.reference_type = @reference_type
+AND sm.created_at >= CAST(CAST(@date_from AS date) AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')
+AND sm.created_at < CAST(CAST(@date_to AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC') %s
+GROUP BY p.id, p.sku, p.name, p.unit
+ORDER BY cost DESC, p.name ASC, p.id ASC`
+
+func (r *stockRepository) FindCostOfGoodsSold(ctx context.Context, tenantID uint64, query domain.CostOfGoodsSoldQuery) ([]*domain.CostOfGoodsSoldLine, error) {
+	params := map[string]interface{}{
+		"tenant_id":      tenantID,
+		"reference_type": domain.ReferenceTypeSale,
+		"date_from":      query.DateFrom.Format("2006-01-02"),
+		"date_to":        query.DateTo.Format("2006-01-02"),
+	}
+
+	filter := ""
+	if query.OutletID != nil {
+		filter += " AND sm.outlet_id = @outlet_id"
+		params["outlet_id"] = *query.OutletID
+	}
+	if query.ProductID != nil {
+		filter += " AND sm.product_id = @product_id"
+		params["product_id"] = *query.ProductID
+	}
+
+	var models []CostOfGoodsSoldLineModel
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf(costOfGoodsSoldSQL, filter), params).
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cost of goods sold: %w", err)
+	}
+
+	lines := make([]*domain.CostOfGoodsSoldLine, len(models))
+	for i := range models {
+		lines[i] = models[i].ToDomainLine()
+	}
+
+	return lines, nil
+}
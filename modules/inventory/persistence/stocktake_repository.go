@@ -0,0 +1,446 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const stocktakeSummaryColumns = "stocktake_id, COUNT(*) AS item_count, COUNT(counted_quantity) AS counted_count, " +
+	"COUNT(*) FILTER (WHERE counted_quantity <> expected_quantity) AS variance_count, " +
+	"COALESCE(ROUND(SUM((expected_quantity - counted_quantity) * unit_cost) " +
+	"FILTER (WHERE counted_quantity < expected_quantity), 2), 0) AS shortage_value, " +
+	"COALESCE(ROUND(SUM((counted_quantity - expected_quantity) * unit_cost) " +
+	"FILTER (WHERE counted_quantity > expected_quantity), 2), 0) AS surplus_value"
+
+// stocktakeSnapshotSQL freezes the expected quantities of a new stocktake:
+// every tracked product of the tenant that is active or still holds stock at
+// the outlet, optionally limited to a category and its subcategories.
+const stocktakeSnapshotSQL = `INSERT INTO stocktake_items (stocktake_id, product_id, expected_quantity, unit_cost)
+SELECT @stocktake_id, p.id, COALESCE(ps.quantity, 0), p.cost_price
+FROM products p
+LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.outlet_id = @outlet_id AND ps.variant_id IS NULL
+WHERE p.tenant_id = @tenant_id AND p.track_stock = TRUE
+AND (p.is_active = TRUE OR COALESCE(ps.quantity, 0) <> 0)
+AND (CAST(@category_id AS BIGINT) IS NULL OR p.category_id IN (
+	WITH RECURSIVE scope AS (
+		SELECT id FROM product_categories WHERE id = @category_id
+		UNION ALL
+		SELECT pc.id FROM product_categories pc JOIN scope ON pc.parent_id = scope.id
+	)
+	SELECT id FROM scope
+))`
+
+type stocktakeRepository struct {
+	db *gorm.DB
+}
+
+func NewStocktakeRepository(db *gorm.DB) domain.StocktakeRepository {
+	return &stocktakeRepository{db: db}
+}
+
+// Create starts a stocktake and snapshots the outlet's expected quantities.
+// An outlet can only have one stocktake in progress; the document number
+// lock serializes the check.
+func (r *stocktakeRepository) Create(ctx context.Context, stocktake *domain.Stocktake) error {
+	var model StocktakeModel
+	model.FromDomainStocktake(stocktake)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		number, err := nextDocumentNumber(tx, "stocktakes", "stocktake_number", "STK", model.TenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		model.StocktakeNumber = number
+
+		var inProgress int64
+		err = tx.Model(&StocktakeModel{}).
+			Where("tenant_id = ? AND outlet_id = ? AND status = ?", model.TenantID, model.OutletID, domain.StocktakeStatusCounting).
+			Count(&inProgress).Error
+		if err != nil {
+			return fmt.Errorf("failed to check stocktakes in progress: %w", err)
+		}
+		if inProgress > 0 {
+			return errors.New("outlet already has a stocktake in progress")
+		}
+
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create stocktake: %w", err)
+		}
+
+		result := tx.Exec(stocktakeSnapshotSQL, map[string]interface{}{
+			"stocktake_id": model.ID,
+			"outlet_id":    model.OutletID,
+			"tenant_id":    model.TenantID,
+			"category_id":  model.CategoryID,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to snapshot stock: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("no products to count")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	stocktake.ID = model.ID
+	stocktake.StocktakeNumber = model.StocktakeNumber
+	stocktake.CreatedAt = model.CreatedAt
+	stocktake.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+func (r *stocktakeRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.Stocktake, error) {
+	var model StocktakeModel
+
+	err := r.db.WithContext(ctx).
+		Preload("Outlet").
+		Preload("Category").
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stocktake not found")
+		}
+		return nil, fmt.Errorf("failed to find stocktake: %w", err)
+	}
+
+	stocktake := model.ToDomainStocktake()
+
+	summaries, err := r.summaries(ctx, []uint64{model.ID})
+	if err != nil {
+		return nil, err
+	}
+	stocktake.Summary = summaries[model.ID]
+
+	return stocktake, nil
+}
+
+func (r *stocktakeRepository) FindAll(ctx context.Context, tenantID uint64, query domain.StocktakeQuery, limit, offset int) ([]*domain.Stocktake, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&StocktakeModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("outlet_id = ?", *query.OutletID)
+	}
+	if query.Status != "" {
+		filtered = filtered.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stocktakes: %w", err)
+	}
+
+	var models []StocktakeModel
+	err := filtered.
+		Preload("Outlet").
+		Preload("Category").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stocktakes: %w", err)
+	}
+
+	ids := make([]uint64, len(models))
+	for i := range models {
+		ids[i] = models[i].ID
+	}
+
+	summaries, err := r.summaries(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stocktakes := make([]*domain.Stocktake, len(models))
+	for i := range models {
+		stocktakes[i] = models[i].ToDomainStocktake()
+		stocktakes[i].Summary = summaries[models[i].ID]
+	}
+
+	return stocktakes, total, nil
+}
+
+// FindItems returns the variance report of a stocktake. Items with a
+// variance are listed by the size of their cost impact, everything else by
+// product name.
+func (r *stocktakeRepository) FindItems(ctx context.Context, tenantID, id uint64, query domain.StocktakeItemQuery, limit, offset int) ([]*domain.StocktakeItem, int64, error) {
+	if _, err := r.FindByID(ctx, tenantID, id); err != nil {
+		return nil, 0, err
+	}
+
+	filtered := r.db.WithContext(ctx).
+		Model(&StocktakeItemModel{}).
+		Joins("JOIN products p ON p.id = stocktake_items.product_id").
+		Where("stocktake_items.stocktake_id = ?", id)
+
+	order := "p.name ASC, stocktake_items.id ASC"
+	switch query.Status {
+	case "counted":
+		filtered = filtered.Where("stocktake_items.counted_quantity IS NOT NULL")
+	case "uncounted":
+		filtered = filtered.Where("stocktake_items.counted_quantity IS NULL")
+	case "variance":
+		filtered = filtered.Where("stocktake_items.counted_quantity <> stocktake_items.expected_quantity")
+		order = "ABS((stocktake_items.counted_quantity - stocktake_items.expected_quantity) * stocktake_items.unit_cost) DESC, " + order
+	}
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + search + "%"
+		filtered = filtered.Where("p.name ILIKE ? OR p.sku ILIKE ? OR p.barcode = ?", pattern, pattern, search)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stocktake items: %w", err)
+	}
+
+	var models []StocktakeItemModel
+	err := filtered.
+		Preload("Product").
+		Order(order).
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stocktake items: %w", err)
+	}
+
+	items := make([]*domain.StocktakeItem, len(models))
+	for i := range models {
+		items[i] = models[i].ToDomainItem()
+	}
+
+	return items, total, nil
+}
+
+// RecordCounts adds one batch of counts to the stocktake. The stocktake row
+// is share-locked so batches from several devices run side by side while
+// finalizing waits for them; each item row is locked for its update.
+func (r *stocktakeRepository) RecordCounts(ctx context.Context, tenantID, id uint64, batch domain.StocktakeCountBatch) ([]*domain.StocktakeItem, error) {
+	productIDs := make([]uint64, 0, len(batch.Quantities))
+	for productID := range batch.Quantities {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool {
+		return productIDs[i] < productIDs[j]
+	})
+
+	itemIDs := make([]uint64, len(productIDs))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := r.lockCounting(tx, tenantID, id, "SHARE"); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i, productID := range productIDs {
+			var item StocktakeItemModel
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("stocktake_id = ? AND product_id = ?", id, productID).
+				First(&item).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("product is not part of the stocktake")
+				}
+				return fmt.Errorf("failed to find stocktake item: %w", err)
+			}
+
+			quantity := batch.Quantities[productID]
+			counted := quantity
+			if item.CountedQuantity != nil {
+				counted += *item.CountedQuantity
+			}
+			if counted < 0 {
+				return errors.New("counted quantity cannot be negative")
+			}
+
+			err = tx.Model(&StocktakeItemModel{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+				"counted_quantity": counted,
+				"counted_at":       now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update stocktake item: %w", err)
+			}
+
+			count := &StocktakeCountModel{
+				StocktakeID: id,
+				ProductID:   productID,
+				Quantity:    quantity,
+				DeviceID:    batch.DeviceID,
+				CountedBy:   batch.CountedBy,
+			}
+			if err := tx.Create(count).Error; err != nil {
+				return fmt.Errorf("failed to create stocktake count: %w", err)
+			}
+
+			itemIDs[i] = item.ID
+		}
+
+		return tx.Model(&StocktakeModel{}).Where("id = ?", id).Update("updated_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var models []StocktakeItemModel
+	err = r.db.WithContext(ctx).
+		Preload("Product").
+		Where("id IN ?", itemIDs).
+		Order("product_id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stocktake items: %w", err)
+	}
+
+	items := make([]*domain.StocktakeItem, len(models))
+	for i := range models {
+		items[i] = models[i].ToDomainItem()
+	}
+
+	return items, nil
+}
+
+// Finalize posts the variance of every counted item as an adjustment
+// movement and closes the stocktake. Uncounted items are left alone unless
+// zeroUncounted records them as counted at zero.
+func (r *stocktakeRepository) Finalize(ctx context.Context, tenantID, id, finalizedBy uint64, zeroUncounted bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lockCounting(tx, tenantID, id, "UPDATE")
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if zeroUncounted {
+			err := tx.Model(&StocktakeItemModel{}).
+				Where("stocktake_id = ? AND counted_quantity IS NULL", model.ID).
+				Updates(map[string]interface{}{
+					"counted_quantity": 0,
+					"counted_at":       now,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to zero uncounted items: %w", err)
+			}
+		}
+
+		// Items in product order, so concurrent documents lock stock rows
+		// in the same order
+		var items []StocktakeItemModel
+		err = tx.Where("stocktake_id = ? AND counted_quantity <> expected_quantity", model.ID).
+			Order("product_id ASC").
+			Find(&items).Error
+		if err != nil {
+			return fmt.Errorf("failed to find stocktake items: %w", err)
+		}
+
+		for _, item := range items {
+			_, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     item.ProductID,
+				OutletID:      model.OutletID,
+				Quantity:      *item.CountedQuantity - item.ExpectedQuantity,
+				MovementType:  domain.MovementTypeAdjustment,
+				ReferenceType: domain.ReferenceTypeStocktake,
+				ReferenceID:   model.ID,
+				Notes: fmt.Sprintf("Stocktake %s: counted %g, expected %g",
+					model.StocktakeNumber, *item.CountedQuantity, item.ExpectedQuantity),
+				CreatedBy: finalizedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&StocktakeModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":       domain.StocktakeStatusFinalized,
+			"finalized_by": finalizedBy,
+			"finalized_at": now,
+			"updated_at":   now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to finalize stocktake: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *stocktakeRepository) Cancel(ctx context.Context, tenantID, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lockCounting(tx, tenantID, id, "UPDATE")
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&StocktakeModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":     domain.StocktakeStatusCancelled,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to cancel stocktake: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// lockCounting locks a stocktake that is still being counted with the given
+// lock strength
+func (r *stocktakeRepository) lockCounting(tx *gorm.DB, tenantID, id uint64, strength string) (*StocktakeModel, error) {
+	var model StocktakeModel
+
+	err := tx.Clauses(clause.Locking{Strength: strength}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stocktake not found")
+		}
+		return nil, fmt.Errorf("failed to find stocktake: %w", err)
+	}
+
+	if model.Status != domain.StocktakeStatusCounting {
+		return nil, errors.New("stocktake is not in progress")
+	}
+
+	return &model, nil
+}
+
+func (r *stocktakeRepository) summaries(ctx context.Context, ids []uint64) (map[uint64]domain.StocktakeSummary, error) {
+	summaries := make(map[uint64]domain.StocktakeSummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	var models []StocktakeSummaryModel
+	err := r.db.WithContext(ctx).
+		Table("stocktake_items").
+		Select(stocktakeSummaryColumns).
+		Where("stocktake_id IN ?", ids).
+		Group("stocktake_id").
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize stocktakes: %w", err)
+	}
+
+	for i := range models {
+		summaries[models[i].StocktakeID] = models[i].ToDomainSummary()
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,410 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) domain.TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(ctx context.Context, transfer *domain.StockTransfer) error {
+	var model StockTransferModel
+	model.FromDomainTransfer(transfer)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		number, err := nextDocumentNumber(tx, "stock_transfers", "transfer_number", "TRF", model.TenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		model.TransferNumber = number
+
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create stock transfer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	transfer.ID = model.ID
+	transfer.TransferNumber = model.TransferNumber
+	transfer.CreatedAt = model.CreatedAt
+	transfer.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// UpdateDraft replaces the notes and items of a draft transfer
+func (r *transferRepository) UpdateDraft(ctx context.Context, transfer *domain.StockTransfer) error {
+	var model StockTransferModel
+	model.FromDomainTransfer(transfer)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := r.lock(tx, transfer.TenantID, transfer.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.TransferStatusDraft {
+			return errors.New("transfer is not a draft")
+		}
+
+		err = tx.Model(&StockTransferModel{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+			"notes":      model.Notes,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update stock transfer: %w", err)
+		}
+
+		if err := tx.Where("transfer_id = ?", current.ID).Delete(&StockTransferItemModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete transfer items: %w", err)
+		}
+		if err := tx.Create(&model.Items).Error; err != nil {
+			return fmt.Errorf("failed to create transfer items: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *transferRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.StockTransfer, error) {
+	var model StockTransferModel
+
+	err := r.db.WithContext(ctx).
+		Preload("SourceOutlet").
+		Preload("DestinationOutlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product").
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transfer not found")
+		}
+		return nil, fmt.Errorf("failed to find stock transfer: %w", err)
+	}
+
+	return model.ToDomainTransfer(), nil
+}
+
+func (r *transferRepository) FindAll(ctx context.Context, tenantID uint64, query domain.TransferQuery, limit, offset int) ([]*domain.StockTransfer, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&StockTransferModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("source_outlet_id = ? OR destination_outlet_id = ?", *query.OutletID, *query.OutletID)
+	}
+	if query.Status != "" {
+		filtered = filtered.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock transfers: %w", err)
+	}
+
+	var models []StockTransferModel
+	err := filtered.
+		Preload("SourceOutlet").
+		Preload("DestinationOutlet").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find stock transfers: %w", err)
+	}
+
+	transfers := make([]*domain.StockTransfer, len(models))
+	for i := range models {
+		transfers[i] = models[i].ToDomainTransfer()
+	}
+
+	return transfers, total, nil
+}
+
+// Dispatch takes the transfer quantities, converted to base units, out of
+// the source outlet and marks the transfer dispatched. Nothing is dispatched
+// if any product is short.
+func (r *transferRepository) Dispatch(ctx context.Context, tenantID, id, dispatchedBy uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lock(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if model.Status != domain.TransferStatusDraft {
+			return errors.New("transfer is not a draft")
+		}
+
+		items, err := r.items(tx, model.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			_, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     item.ProductID,
+				OutletID:      model.SourceOutletID,
+				Quantity:      -domain.RoundQuantity(item.Quantity * item.UnitFactor),
+				MovementType:  domain.MovementTypeTransfer,
+				ReferenceType: domain.ReferenceTypeTransfer,
+				ReferenceID:   model.ID,
+				Notes:         fmt.Sprintf("Transfer %s dispatched", model.TransferNumber),
+				CreatedBy:     dispatchedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&StockTransferModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":        domain.TransferStatusDispatched,
+			"dispatched_by": dispatchedBy,
+			"dispatched_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to dispatch stock transfer: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Receive puts one delivery into the destination outlet. The transfer is
+// received once nothing is outstanding or the receipt completes it.
+// Quantities are counted in the items' units and stocked in base units.
+func (r *transferRepository) Receive(ctx context.Context, tenantID, id uint64, receipt domain.TransferReceipt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lock(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if model.Status != domain.TransferStatusDispatched && model.Status != domain.TransferStatusPartiallyReceived {
+			return errors.New("transfer is not awaiting receipt")
+		}
+
+		items, err := r.items(tx, model.ID)
+		if err != nil {
+			return err
+		}
+
+		inTransfer := make(map[uint64]bool, len(items))
+		for _, item := range items {
+			inTransfer[item.ProductID] = true
+		}
+		for productID := range receipt.Quantities {
+			if !inTransfer[productID] {
+				return errors.New("product is not part of the transfer")
+			}
+		}
+
+		outstanding := 0.0
+		for _, item := range items {
+			quantity := receipt.Quantities[item.ProductID]
+			if quantity > domain.RoundQuantity(item.Quantity-item.ReceivedQuantity) {
+				return errors.New("received quantity exceeds outstanding quantity")
+			}
+
+			updates := map[string]interface{}{}
+			if quantity > 0 {
+				baseQuantity := domain.RoundQuantity(quantity * item.UnitFactor)
+				lots, err := r.outstandingLots(tx, model.ID, item.ProductID, baseQuantity)
+				if err != nil {
+					return err
+				}
+				unitCost, err := r.dispatchedUnitCost(tx, model, item.ProductID)
+				if err != nil {
+					return err
+				}
+
+				_, err = stockledger.Apply(tx, stockledger.Change{
+					ProductID:     item.ProductID,
+					OutletID:      model.DestinationOutletID,
+					Quantity:      baseQuantity,
+					MovementType:  domain.MovementTypeTransfer,
+					ReferenceType: domain.ReferenceTypeTransfer,
+					ReferenceID:   model.ID,
+					Notes:         fmt.Sprintf("Transfer %s received", model.TransferNumber),
+					CreatedBy:     receipt.ReceivedBy,
+					UnitCost:      unitCost,
+					Lots:          lots,
+				})
+				if err != nil {
+					return err
+				}
+				updates["received_quantity"] = domain.RoundQuantity(item.ReceivedQuantity + quantity)
+			}
+			remaining := domain.RoundQuantity(item.Quantity - item.ReceivedQuantity - quantity)
+			outstanding += remaining
+
+			reason := receipt.DiscrepancyReasons[item.ProductID]
+			if reason == "" && remaining > 0 && receipt.Complete && item.DiscrepancyReason == "" {
+				reason = "Not received"
+			}
+			if reason != "" {
+				updates["discrepancy_reason"] = reason
+			}
+
+			if len(updates) > 0 {
+				if err := tx.Model(&StockTransferItemModel{}).Where("id = ?", item.ID).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to update transfer item: %w", err)
+				}
+			}
+		}
+
+		transferUpdates := map[string]interface{}{
+			"status":     domain.TransferStatusPartiallyReceived,
+			"updated_at": time.Now(),
+		}
+		if outstanding == 0 || receipt.Complete {
+			transferUpdates["status"] = domain.TransferStatusReceived
+			transferUpdates["received_by"] = receipt.ReceivedBy
+			transferUpdates["received_at"] = time.Now()
+		}
+
+		if err := tx.Model(&StockTransferModel{}).Where("id = ?", model.ID).Updates(transferUpdates).Error; err != nil {
+			return fmt.Errorf("failed to receive stock transfer: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *transferRepository) Cancel(ctx context.Context, tenantID, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := r.lock(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if model.Status != domain.TransferStatusDraft {
+			return errors.New("transfer is not a draft")
+		}
+
+		err = tx.Model(&StockTransferModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":     domain.TransferStatusCancelled,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to cancel stock transfer: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// dispatchedUnitCost is the unit cost at which a product left the source
+// outlet, so stock arrives at the destination at the cost it was taken out
+// at. It is nil for transfers dispatched before costing.
+func (r *transferRepository) dispatchedUnitCost(tx *gorm.DB, model *StockTransferModel, productID uint64) (*float64, error) {
+	var dispatched struct {
+		Quantity  float64
+		TotalCost float64
+	}
+	err := tx.Model(&StockMovementModel{}).
+		Select("COALESCE(-SUM(quantity), 0) AS quantity, COALESCE(-SUM(total_cost), 0) AS total_cost").
+		Where("reference_type = ? AND reference_id = ? AND product_id = ? AND outlet_id = ?",
+			domain.ReferenceTypeTransfer, model.ID, productID, model.SourceOutletID).
+		Where("quantity < 0 AND total_cost IS NOT NULL").
+		Take(&dispatched).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dispatched cost: %w", err)
+	}
+	if dispatched.Quantity <= 0 {
+		return nil, nil
+	}
+
+	unitCost := stockledger.RoundCost(dispatched.TotalCost / dispatched.Quantity)
+	return &unitCost, nil
+}
+
+func (r *transferRepository) lock(tx *gorm.DB, tenantID, id uint64) (*StockTransferModel, error) {
+	var model StockTransferModel
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transfer not found")
+		}
+		return nil, fmt.Errorf("failed to find stock transfer: %w", err)
+	}
+
+	return &model, nil
+}
+
+// transferLotsSQL sums, per lot number, what a transfer's movements took
+// from lots at the source outlet and put into lots at the destination. What
+// is left negative was dispatched from the lot and is still in transit.
+const transferLotsSQL = `SELECT sl.lot_number, sl.expiry_date, -SUM(sml.quantity) AS quantity
+FROM stock_movement_lots sml
+JOIN stock_movements sm ON sm.id = sml.stock_movement_id
+JOIN stock_lots sl ON sl.id = sml.lot_id
+WHERE sm.reference_type = @reference_type AND sm.reference_id = @transfer_id AND sm.product_id = @product_id
+GROUP BY sl.lot_number, sl.expiry_date
+HAVING SUM(sml.quantity) < 0
+ORDER BY sl.expiry_date ASC NULLS LAST, sl.lot_number ASC`
+
+// outstandingLots splits a received quantity over the lots it was
+// dispatched from, earliest expiry first, so lots keep their number and
+// expiry date at the destination. Stock dispatched from outside any lot
+// arrives outside any lot.
+func (r *transferRepository) outstandingLots(tx *gorm.DB, transferID, productID uint64, quantity float64) ([]stockledger.LotQuantity, error) {
+	var outstanding []stockledger.LotQuantity
+
+	err := tx.Raw(transferLotsSQL, map[string]interface{}{
+		"reference_type": domain.ReferenceTypeTransfer,
+		"transfer_id":    transferID,
+		"product_id":     productID,
+	}).Scan(&outstanding).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transferred lots: %w", err)
+	}
+
+	lots := make([]stockledger.LotQuantity, 0, len(outstanding))
+	for _, lot := range outstanding {
+		if quantity <= 0 {
+			break
+		}
+		if lot.Quantity > quantity {
+			lot.Quantity = quantity
+		}
+		lots = append(lots, lot)
+		quantity = domain.RoundQuantity(quantity - lot.Quantity)
+	}
+
+	return lots, nil
+}
+
+// items returns the transfer items in product order, so concurrent
+// documents lock stock rows in the same order.
+func (r *transferRepository) items(tx *gorm.DB, transferID uint64) ([]StockTransferItemModel, error) {
+	var items []StockTransferItemModel
+
+	err := tx.Where("transfer_id = ?", transferID).
+		Order("product_id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transfer items: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// CreateAdjustment records a manual stock adjustment. It is applied to stock
+// right away unless its value at cost exceeds the tenant's approval
+// threshold and the user cannot approve adjustments, in which case it waits
+// for a manager.
+func (s *inventoryService) CreateAdjustment(ctx context.Context, tenantID, userID uint64, req domain.CreateAdjustmentRequest) (*domain.StockAdjustment, error) {
+	user, err := s.stockRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.stockRepo.OutletExists(ctx, tenantID, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	productIDs := make([]uint64, len(req.Items))
+	for i, item := range req.Items {
+		productIDs[i] = item.ProductID
+	}
+
+	products, err := s.stockRepo.FindProducts(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &domain.StockAdjustment{
+		TenantID:  tenantID,
+		OutletID:  req.OutletID,
+		Notes:     strings.TrimSpace(req.Notes),
+		CreatedBy: userID,
+		Items:     make([]*domain.StockAdjustmentItem, len(req.Items)),
+	}
+
+	for i, item := range req.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, errors.New("product not found")
+		}
+		if !product.TrackStock {
+			return nil, errors.New("product does not track stock")
+		}
+
+		adjustment.Items[i] = &domain.StockAdjustmentItem{
+			ProductID:   product.ID,
+			SKU:         product.SKU,
+			ProductName: product.Name,
+			Quantity:    item.Quantity,
+			Reason:      item.Reason,
+			UnitCost:    product.CostPrice,
+			Notes:       strings.TrimSpace(item.Notes),
+		}
+	}
+	adjustment.TotalValue = adjustment.ItemsValue()
+
+	threshold, err := s.adjustmentRepo.FindApprovalThreshold(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment.Status = domain.AdjustmentStatusApplied
+	if threshold > 0 && adjustment.TotalValue > threshold {
+		if !user.CanApproveAdjustments() {
+			adjustment.Status = domain.AdjustmentStatusPendingApproval
+		} else {
+			// Managers approve their own adjustments above the threshold
+			adjustment.ReviewedBy = &userID
+		}
+	}
+
+	if err := s.adjustmentRepo.Create(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	if adjustment.IsPendingApproval() {
+		s.publishAdjustment(ctx, "stock.adjustment_requested", adjustment, userID)
+	} else {
+		s.publishAdjustment(ctx, "stock.adjusted", adjustment, userID)
+		s.publishLowStock(ctx, tenantID, userID, domain.ReferenceTypeAdjustment, adjustment.ID)
+	}
+
+	return s.adjustmentRepo.FindByID(ctx, tenantID, adjustment.ID)
+}
+
+func (s *inventoryService) GetAdjustment(ctx context.Context, tenantID, id uint64) (*domain.StockAdjustment, error) {
+	return s.adjustmentRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *inventoryService) GetAdjustments(ctx context.Context, tenantID uint64, query domain.AdjustmentQuery, limit, offset int) ([]*domain.StockAdjustment, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.adjustmentRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *inventoryService) ApproveAdjustment(ctx context.Context, tenantID, userID, id uint64) (*domain.StockAdjustment, error) {
+	if err := s.requireApprover(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	adjustment, err := s.adjustmentRepo.Approve(ctx, tenantID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishAdjustment(ctx, "stock.adjusted", adjustment, userID)
+	s.publishLowStock(ctx, tenantID, userID, domain.ReferenceTypeAdjustment, adjustment.ID)
+
+	return adjustment, nil
+}
+
+func (s *inventoryService) RejectAdjustment(ctx context.Context, tenantID, userID, id uint64, req domain.RejectAdjustmentRequest) (*domain.StockAdjustment, error) {
+	if err := s.requireApprover(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.adjustmentRepo.Reject(ctx, tenantID, id, userID, strings.TrimSpace(req.Reason))
+}
+
+func (s *inventoryService) requireApprover(ctx context.Context, tenantID, userID uint64) error {
+	user, err := s.stockRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !user.CanApproveAdjustments() {
+		return errors.New("only managers can review adjustments")
+	}
+	return nil
+}
+
+func (s *inventoryService) publishAdjustment(ctx context.Context, eventType string, adjustment *domain.StockAdjustment, userID uint64) {
+	if s.eventBus == nil {
+		return
+	}
+
+	event := messaging.NewEvent(eventType, adjustment.TenantID, userID, map[string]interface{}{
+		"adjustment_id":     adjustment.ID,
+		"adjustment_number": adjustment.AdjustmentNumber,
+		"outlet_id":         adjustment.OutletID,
+		"total_value":       adjustment.TotalValue,
+	})
+	s.eventBus.Publish(ctx, eventType, event)
+}
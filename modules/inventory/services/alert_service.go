@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+func (s *inventoryService) GetAlerts(ctx context.Context, tenantID uint64, query domain.AlertQuery, limit, offset int) ([]*domain.StockAlert, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.alertRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *inventoryService) AcknowledgeAlert(ctx context.Context, tenantID, userID, id uint64) (*domain.StockAlert, error) {
+	user, err := s.stockRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanApproveAdjustments() {
+		return nil, errors.New("only managers can acknowledge alerts")
+	}
+
+	if err := s.alertRepo.Acknowledge(ctx, tenantID, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.alertRepo.FindByID(ctx, tenantID, id)
+}
+
+// GetReorderSuggestions proposes what to order for an outlet, grouped by
+// the supplier each product was last received from
+func (s *inventoryService) GetReorderSuggestions(ctx context.Context, tenantID uint64, query domain.ReorderQuery) ([]*domain.ReorderSuggestion, error) {
+	exists, err := s.stockRepo.OutletExists(ctx, tenantID, query.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	if query.Days <= 0 {
+		query.Days = 30
+	}
+	if query.CoverDays <= 0 {
+		query.CoverDays = 14
+	}
+
+	since := time.Now().AddDate(0, 0, -query.Days)
+	candidates, err := s.stockRepo.FindReorderCandidates(ctx, tenantID, query.OutletID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := domain.SuggestReorder(candidates, query.Days, query.CoverDays)
+	if query.SupplierID == nil {
+		return suggestions, nil
+	}
+
+	for _, suggestion := range suggestions {
+		if suggestion.SupplierID != nil && *suggestion.SupplierID == *query.SupplierID {
+			return []*domain.ReorderSuggestion{suggestion}, nil
+		}
+	}
+	return []*domain.ReorderSuggestion{}, nil
+}
+
+// publishLowStock publishes a stock.low event for every alert raised by the
+// movements of one stock document
+func (s *inventoryService) publishLowStock(ctx context.Context, tenantID, userID uint64, referenceType string, referenceID uint64) {
+	if s.eventBus == nil {
+		return
+	}
+
+	alerts, err := s.alertRepo.FindByReference(ctx, tenantID, referenceType, referenceID)
+	if err != nil {
+		return
+	}
+
+	for _, alert := range alerts {
+		event := messaging.NewEvent("stock.low", tenantID, userID, map[string]interface{}{
+			"alert_id":     alert.ID,
+			"product_id":   alert.ProductID,
+			"sku":          alert.SKU,
+			"product_name": alert.ProductName,
+			"outlet_id":    alert.OutletID,
+			"quantity":     alert.Quantity,
+			"min_stock":    alert.MinStock,
+		})
+		s.eventBus.Publish(ctx, "stock.low", event)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+type inventoryService struct {
+	stockRepo      domain.StockRepository
+	adjustmentRepo domain.AdjustmentRepository
+	transferRepo   domain.TransferRepository
+	stocktakeRepo  domain.StocktakeRepository
+	alertRepo      domain.AlertRepository
+	lotRepo        domain.LotRepository
+	movementRepo   domain.MovementRepository
+	saleRepo       domain.SaleRepository
+	eventBus       messaging.EventBus
+}
+
+func NewInventoryService(
+	stockRepo domain.StockRepository,
+	adjustmentRepo domain.AdjustmentRepository,
+	transferRepo domain.TransferRepository,
+	stocktakeRepo domain.StocktakeRepository,
+	alertRepo domain.AlertRepository,
+	lotRepo domain.LotRepository,
+	movementRepo domain.MovementRepository,
+	saleRepo domain.SaleRepository,
+	eventBus messaging.EventBus,
+) domain.InventoryService {
+	return &inventoryService{
+		stockRepo:      stockRepo,
+		adjustmentRepo: adjustmentRepo,
+		transferRepo:   transferRepo,
+		stocktakeRepo:  stocktakeRepo,
+		alertRepo:      alertRepo,
+		lotRepo:        lotRepo,
+		movementRepo:   movementRepo,
+		saleRepo:       saleRepo,
+		eventBus:       eventBus,
+	}
+}
+
+func (s *inventoryService) GetStocks(ctx context.Context, tenantID uint64, query domain.StockQuery, limit, offset int) ([]*domain.StockLevel, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.stockRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *inventoryService) GetProductStock(ctx context.Context, tenantID, productID uint64) (*domain.ProductStock, error) {
+	return s.stockRepo.FindByProduct(ctx, tenantID, productID)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+)
+
+func (s *inventoryService) GetLots(ctx context.Context, tenantID uint64, query domain.LotQuery, limit, offset int) ([]*domain.StockLot, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.lotRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+// GetLot returns a lot with every movement that touched it, for tracing a
+// recalled batch to the documents that received, moved and sold it
+func (s *inventoryService) GetLot(ctx context.Context, tenantID, id uint64) (*domain.StockLot, error) {
+	lot, err := s.lotRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lot.Movements, err = s.lotRepo.FindMovements(ctx, lot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return lot, nil
+}
+
+// GetExpiringLots lists the lots still in stock that expire within the next
+// query.Days days, defaulting to 30. Lots that have already expired are
+// included, since they still need to be written off.
+func (s *inventoryService) GetExpiringLots(ctx context.Context, tenantID uint64, query domain.ExpiringLotQuery, limit, offset int) ([]*domain.StockLot, int64, error) {
+	if query.Days <= 0 {
+		query.Days = 30
+	}
+
+	expiresBefore := time.Now().AddDate(0, 0, query.Days+1)
+	lotQuery := domain.LotQuery{
+		OutletID:      query.OutletID,
+		ProductID:     query.ProductID,
+		InStock:       true,
+		ExpiresBefore: &expiresBefore,
+	}
+
+	return s.GetLots(ctx, tenantID, lotQuery, limit, offset)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+)
+
+func (s *inventoryService) GetMovements(ctx context.Context, tenantID uint64, query domain.MovementQuery, limit, offset int) ([]*domain.StockMovement, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if query.DateFrom != nil && query.DateTo != nil && query.DateFrom.After(*query.DateTo) {
+		return nil, 0, errors.New("date from must not be after date to")
+	}
+
+	return s.movementRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+// ReconcileStock compares the tenant's stock levels with the sum of their
+// stock movements and returns the ones that drifted. With fix, the drifted
+// stock levels are set to their ledger sum and only the fixed ones are
+// returned.
+func (s *inventoryService) ReconcileStock(ctx context.Context, tenantID uint64, fix bool) ([]*domain.StockDrift, error) {
+	if !fix {
+		return s.movementRepo.FindDrift(ctx, tenantID)
+	}
+
+	return s.movementRepo.FixDrift(ctx, tenantID)
+}
+
+// FixStockDrift reconciles the tenant's stock on behalf of a user. Only
+// managers may overwrite stock levels.
+func (s *inventoryService) FixStockDrift(ctx context.Context, tenantID, userID uint64) ([]*domain.StockDrift, error) {
+	user, err := s.stockRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanApproveAdjustments() {
+		return nil, errors.New("only managers can reconcile stock")
+	}
+
+	return s.ReconcileStock(ctx, tenantID, true)
+}
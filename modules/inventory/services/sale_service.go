@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+)
+
+// RecordSale takes a completed transaction's items from the outlet's stock,
+// consuming the components of products sold by recipe or as a bundle and
+// the products of picked modifiers, and returns the cost of every line for
+// the items' cost price snapshots.
+// Checkout calls it inside the transaction that stores the sale, which it
+// joins; items with a negative quantity are returned to stock.
+func (s *inventoryService) RecordSale(ctx context.Context, tenantID, userID uint64, req domain.RecordSaleRequest) (*domain.Sale, error) {
+	exists, err := s.stockRepo.OutletExists(ctx, tenantID, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	sale := &domain.Sale{
+		TransactionID: req.TransactionID,
+		OutletID:      req.OutletID,
+		CreatedBy:     userID,
+		Lines:         make([]*domain.SaleLine, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		if item.Quantity == 0 {
+			return nil, errors.New("sale quantity must not be zero")
+		}
+		sale.Lines[i] = &domain.SaleLine{
+			ProductID:         item.ProductID,
+			VariantID:         item.VariantID,
+			Quantity:          item.Quantity,
+			Unit:              strings.TrimSpace(item.Unit),
+			TransactionItemID: item.TransactionItemID,
+			Amount:            item.Amount,
+			Choices:           item.Choices,
+			ModifierIDs:       item.ModifierIDs,
+		}
+	}
+
+	if err := s.saleRepo.Record(ctx, tenantID, sale); err != nil {
+		return nil, err
+	}
+
+	// Inside checkout the alerts are only visible once the sale commits
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		s.publishLowStock(ctx, tenantID, userID, domain.ReferenceTypeSale, sale.TransactionID)
+	})
+
+	return sale, nil
+}
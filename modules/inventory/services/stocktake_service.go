@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// CreateStocktake starts counting an outlet. The outlet's current stock is
+// frozen as the expected quantities; stock keeps moving while counting.
+func (s *inventoryService) CreateStocktake(ctx context.Context, tenantID, userID uint64, req domain.CreateStocktakeRequest) (*domain.Stocktake, error) {
+	exists, err := s.stockRepo.OutletExists(ctx, tenantID, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	if req.CategoryID != nil {
+		exists, err := s.stockRepo.CategoryExists(ctx, tenantID, *req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("category not found")
+		}
+	}
+
+	stocktake := &domain.Stocktake{
+		TenantID:   tenantID,
+		OutletID:   req.OutletID,
+		CategoryID: req.CategoryID,
+		Status:     domain.StocktakeStatusCounting,
+		Notes:      strings.TrimSpace(req.Notes),
+		CreatedBy:  userID,
+	}
+
+	if err := s.stocktakeRepo.Create(ctx, stocktake); err != nil {
+		return nil, err
+	}
+
+	return s.stocktakeRepo.FindByID(ctx, tenantID, stocktake.ID)
+}
+
+func (s *inventoryService) GetStocktake(ctx context.Context, tenantID, id uint64) (*domain.Stocktake, error) {
+	return s.stocktakeRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *inventoryService) GetStocktakes(ctx context.Context, tenantID uint64, query domain.StocktakeQuery, limit, offset int) ([]*domain.Stocktake, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.stocktakeRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *inventoryService) GetStocktakeItems(ctx context.Context, tenantID, id uint64, query domain.StocktakeItemQuery, limit, offset int) ([]*domain.StocktakeItem, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.stocktakeRepo.FindItems(ctx, tenantID, id, query, limit, offset)
+}
+
+// RecordStocktakeCounts adds counted quantities to a stocktake. Scanned
+// barcodes are resolved to products, and repeated lines for one product in
+// a batch add up, so a scanner can send one line per scan.
+func (s *inventoryService) RecordStocktakeCounts(ctx context.Context, tenantID, userID, id uint64, req domain.RecordStocktakeCountsRequest) ([]*domain.StocktakeItem, error) {
+	batch := domain.StocktakeCountBatch{
+		DeviceID:   strings.TrimSpace(req.DeviceID),
+		CountedBy:  userID,
+		Quantities: make(map[uint64]float64, len(req.Items)),
+	}
+
+	for _, item := range req.Items {
+		productID := item.ProductID
+		if productID == 0 {
+			product, err := s.stockRepo.FindProductByBarcode(ctx, tenantID, strings.TrimSpace(item.Barcode))
+			if err != nil {
+				return nil, err
+			}
+			productID = product.ID
+		}
+		batch.Quantities[productID] += item.Quantity
+	}
+
+	return s.stocktakeRepo.RecordCounts(ctx, tenantID, id, batch)
+}
+
+// FinalizeStocktake posts the stocktake's variances to stock. Only users
+// who can approve adjustments may finalize, since every variance is one.
+func (s *inventoryService) FinalizeStocktake(ctx context.Context, tenantID, userID, id uint64, req domain.FinalizeStocktakeRequest) (*domain.Stocktake, error) {
+	user, err := s.stockRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanApproveAdjustments() {
+		return nil, errors.New("only managers can finalize stocktakes")
+	}
+
+	if err := s.stocktakeRepo.Finalize(ctx, tenantID, id, userID, req.ZeroUncounted); err != nil {
+		return nil, err
+	}
+
+	stocktake, err := s.stocktakeRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("stock.stocktake_finalized", tenantID, userID, map[string]interface{}{
+			"stocktake_id":       stocktake.ID,
+			"stocktake_number":   stocktake.StocktakeNumber,
+			"outlet_id":          stocktake.OutletID,
+			"variance_count":     stocktake.Summary.VarianceCount,
+			"net_variance_value": stocktake.Summary.NetVarianceValue(),
+		})
+		s.eventBus.Publish(ctx, "stock.stocktake_finalized", event)
+	}
+
+	s.publishLowStock(ctx, tenantID, userID, domain.ReferenceTypeStocktake, stocktake.ID)
+
+	return stocktake, nil
+}
+
+func (s *inventoryService) CancelStocktake(ctx context.Context, tenantID, id uint64) (*domain.Stocktake, error) {
+	if err := s.stocktakeRepo.Cancel(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	return s.stocktakeRepo.FindByID(ctx, tenantID, id)
+}
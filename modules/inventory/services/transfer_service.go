@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// CreateTransfer saves a draft transfer. Stock does not move until the
+// transfer is dispatched.
+func (s *inventoryService) CreateTransfer(ctx context.Context, tenantID, userID uint64, req domain.CreateTransferRequest) (*domain.StockTransfer, error) {
+	for _, outletID := range []uint64{req.SourceOutletID, req.DestinationOutletID} {
+		exists, err := s.stockRepo.OutletExists(ctx, tenantID, outletID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("outlet not found")
+		}
+	}
+
+	items, err := s.transferItems(ctx, tenantID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &domain.StockTransfer{
+		TenantID:            tenantID,
+		SourceOutletID:      req.SourceOutletID,
+		DestinationOutletID: req.DestinationOutletID,
+		Status:              domain.TransferStatusDraft,
+		Notes:               strings.TrimSpace(req.Notes),
+		CreatedBy:           userID,
+		Items:               items,
+	}
+
+	if err := s.transferRepo.Create(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.FindByID(ctx, tenantID, transfer.ID)
+}
+
+func (s *inventoryService) UpdateTransfer(ctx context.Context, tenantID, id uint64, req domain.UpdateTransferRequest) (*domain.StockTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !transfer.IsDraft() {
+		return nil, errors.New("transfer is not a draft")
+	}
+
+	items, err := s.transferItems(ctx, tenantID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.Notes = strings.TrimSpace(req.Notes)
+	transfer.Items = items
+
+	if err := s.transferRepo.UpdateDraft(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *inventoryService) GetTransfer(ctx context.Context, tenantID, id uint64) (*domain.StockTransfer, error) {
+	return s.transferRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *inventoryService) GetTransfers(ctx context.Context, tenantID uint64, query domain.TransferQuery, limit, offset int) ([]*domain.StockTransfer, int64, error) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.transferRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *inventoryService) DispatchTransfer(ctx context.Context, tenantID, userID, id uint64) (*domain.StockTransfer, error) {
+	if err := s.transferRepo.Dispatch(ctx, tenantID, id, userID); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.transferRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishTransfer(ctx, "stock.transfer_dispatched", transfer, userID)
+	s.publishLowStock(ctx, tenantID, userID, domain.ReferenceTypeTransfer, transfer.ID)
+
+	return transfer, nil
+}
+
+func (s *inventoryService) ReceiveTransfer(ctx context.Context, tenantID, userID, id uint64, req domain.ReceiveTransferRequest) (*domain.StockTransfer, error) {
+	receipt := domain.TransferReceipt{
+		Quantities:         make(map[uint64]float64, len(req.Items)),
+		DiscrepancyReasons: make(map[uint64]string, len(req.Items)),
+		Complete:           req.Complete,
+		ReceivedBy:         userID,
+	}
+
+	for _, item := range req.Items {
+		if _, ok := receipt.Quantities[item.ProductID]; ok {
+			return nil, errors.New("duplicate product in receipt")
+		}
+		receipt.Quantities[item.ProductID] = item.Quantity
+		if reason := strings.TrimSpace(item.DiscrepancyReason); reason != "" {
+			receipt.DiscrepancyReasons[item.ProductID] = reason
+		}
+	}
+
+	if len(receipt.Quantities) == 0 && !receipt.Complete {
+		return nil, errors.New("nothing to receive")
+	}
+
+	if err := s.checkReceivedUnits(ctx, tenantID, id, receipt); err != nil {
+		return nil, err
+	}
+
+	if err := s.transferRepo.Receive(ctx, tenantID, id, receipt); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.transferRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status == domain.TransferStatusReceived {
+		s.publishTransfer(ctx, "stock.transfer_received", transfer, userID)
+	}
+
+	return transfer, nil
+}
+
+func (s *inventoryService) CancelTransfer(ctx context.Context, tenantID, id uint64) (*domain.StockTransfer, error) {
+	if err := s.transferRepo.Cancel(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.FindByID(ctx, tenantID, id)
+}
+
+// transferItems checks the requested products and turns them into transfer
+// items in the requested units. Every product can appear once per transfer.
+func (s *inventoryService) transferItems(ctx context.Context, tenantID uint64, requests []domain.TransferItemRequest) ([]*domain.StockTransferItem, error) {
+	productIDs := make([]uint64, len(requests))
+	for i, item := range requests {
+		productIDs[i] = item.ProductID
+	}
+
+	products, err := s.stockRepo.FindProducts(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.stockRepo.FindProductUnits(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint64]bool, len(requests))
+	items := make([]*domain.StockTransferItem, len(requests))
+	for i, item := range requests {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, errors.New("product not found")
+		}
+		if !product.TrackStock {
+			return nil, errors.New("product does not track stock")
+		}
+		if seen[product.ID] {
+			return nil, errors.New("duplicate product in transfer")
+		}
+		seen[product.ID] = true
+
+		conversion, _, err := units[product.ID].Convert(strings.TrimSpace(item.Unit), item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		items[i] = &domain.StockTransferItem{
+			ProductID:   product.ID,
+			SKU:         product.SKU,
+			ProductName: product.Name,
+			Unit:        conversion.Unit,
+			UnitFactor:  conversion.Factor,
+			Quantity:    item.Quantity,
+		}
+	}
+
+	return items, nil
+}
+
+// checkReceivedUnits makes sure received quantities are whole numbers of
+// their transfer item's unit, unless the unit allows decimals
+func (s *inventoryService) checkReceivedUnits(ctx context.Context, tenantID, id uint64, receipt domain.TransferReceipt) error {
+	if len(receipt.Quantities) == 0 {
+		return nil
+	}
+
+	transfer, err := s.transferRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	productIDs := make([]uint64, len(transfer.Items))
+	for i, item := range transfer.Items {
+		productIDs[i] = item.ProductID
+	}
+
+	units, err := s.stockRepo.FindProductUnits(ctx, tenantID, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range transfer.Items {
+		quantity, ok := receipt.Quantities[item.ProductID]
+		if !ok || units[item.ProductID] == nil {
+			continue
+		}
+		// Units removed from the product since are not checked
+		conversion, ok := units[item.ProductID].Units[item.Unit]
+		if ok && !conversion.AllowDecimal && quantity != math.Trunc(quantity) {
+			return errors.New("quantity must be a whole number of its unit")
+		}
+	}
+
+	return nil
+}
+
+func (s *inventoryService) publishTransfer(ctx context.Context, eventType string, transfer *domain.StockTransfer, userID uint64) {
+	if s.eventBus == nil {
+		return
+	}
+
+	event := messaging.NewEvent(eventType, transfer.TenantID, userID, map[string]interface{}{
+		"transfer_id":           transfer.ID,
+		"transfer_number":       transfer.TransferNumber,
+		"source_outlet_id":      transfer.SourceOutletID,
+		"destination_outlet_id": transfer.DestinationOutletID,
+	})
+	s.eventBus.Publish(ctx, eventType, event)
+}
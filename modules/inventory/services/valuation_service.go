@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/exven/pos-system/modules/inventory/domain"
+)
+
+// GetValuation values the tenant's stock per outlet at the end of
+// query.AsOf, defaulting to today
+func (s *inventoryService) GetValuation(ctx context.Context, tenantID uint64, query domain.ValuationQuery) (*domain.StockValuation, error) {
+	if query.AsOf.IsZero() {
+		query.AsOf = time.Now()
+	}
+
+	outlets, err := s.stockRepo.FindValuation(ctx, tenantID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StockValuation{
+		AsOf:    query.AsOf,
+		Outlets: outlets,
+	}, nil
+}
+
+// GetCostOfGoodsSold costs the sales between two days, defaulting to the
+// last 30 days up to today
+func (s *inventoryService) GetCostOfGoodsSold(ctx context.Context, tenantID uint64, query domain.CostOfGoodsSoldQuery) (*domain.CostOfGoodsSold, error) {
+	if query.DateTo.IsZero() {
+		query.DateTo = time.Now()
+	}
+	if query.DateFrom.IsZero() {
+		query.DateFrom = query.DateTo.AddDate(0, 0, -29)
+	}
+	if query.DateFrom.After(query.DateTo) {
+		return nil, errors.New("date from must not be after date to")
+	}
+
+	lines, err := s.stockRepo.FindCostOfGoodsSold(ctx, tenantID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CostOfGoodsSold{
+		DateFrom: query.DateFrom,
+		DateTo:   query.DateTo,
+		Lines:    lines,
+	}, nil
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // ProductCategory DTOs
 
 type CreateProductCategoryRequest struct {
@@ -61,26 +63,35 @@ type CreateProductRequest struct {
 	Unit         string                 `json:"unit" validate:"max=50"`
 	CostPrice    float64                `json:"cost_price" validate:"min=0"`
 	SellingPrice float64                `json:"selling_price" validate:"required,min=0"`
-	MinStock     int                    `json:"min_stock" validate:"min=0"`
+	MinStock     float64                `json:"min_stock" validate:"min=0"`
 	TrackStock   bool                   `json:"track_stock"`
+	TrackLots    bool                   `json:"track_lots"`
 	Images       []string               `json:"images"`
 	Variants     map[string]interface{} `json:"variants"`
+	InitialStock []InitialStockRequest  `json:"initial_stock" validate:"omitempty,dive"`
+}
+
+type InitialStockRequest struct {
+	OutletID uint64  `json:"outlet_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"min=0"`
 }
 
 type UpdateProductRequest struct {
-	CategoryID   *uint64                `json:"category_id"`
-	SKU          string                 `json:"sku" validate:"required,max=100"`
-	Barcode      string                 `json:"barcode" validate:"max=100"`
-	Name         string                 `json:"name" validate:"required,min=1,max=255"`
-	Description  string                 `json:"description"`
-	Unit         string                 `json:"unit" validate:"max=50"`
-	CostPrice    float64                `json:"cost_price" validate:"min=0"`
-	SellingPrice float64                `json:"selling_price" validate:"required,min=0"`
-	MinStock     int                    `json:"min_stock" validate:"min=0"`
-	TrackStock   bool                   `json:"track_stock"`
-	IsActive     bool                   `json:"is_active"`
-	Images       []string               `json:"images"`
-	Variants     map[string]interface{} `json:"variants"`
+	CategoryID        *uint64                `json:"category_id"`
+	SKU               string                 `json:"sku" validate:"required,max=100"`
+	Barcode           string                 `json:"barcode" validate:"max=100"`
+	Name              string                 `json:"name" validate:"required,min=1,max=255"`
+	Description       string                 `json:"description"`
+	Unit              string                 `json:"unit" validate:"max=50"`
+	CostPrice         float64                `json:"cost_price" validate:"min=0"`
+	SellingPrice      float64                `json:"selling_price" validate:"required,min=0"`
+	MinStock          float64                `json:"min_stock" validate:"min=0"`
+	TrackStock        bool                   `json:"track_stock"`
+	TrackLots         bool                   `json:"track_lots"`
+	IsActive          bool                   `json:"is_active"`
+	Images            []string               `json:"images"`
+	Variants          map[string]interface{} `json:"variants"`
+	PriceChangeReason string                 `json:"price_change_reason" validate:"max=255"`
 }
 
 type ProductResponse struct {
@@ -94,14 +105,289 @@ type ProductResponse struct {
 	Unit         string                   `json:"unit"`
 	CostPrice    float64                  `json:"cost_price"`
 	SellingPrice float64                  `json:"selling_price"`
-	MinStock     int                      `json:"min_stock"`
+	MinStock     float64                  `json:"min_stock"`
 	TrackStock   bool                     `json:"track_stock"`
+	TrackLots    bool                     `json:"track_lots"`
 	IsActive     bool                     `json:"is_active"`
 	Images       []string                 `json:"images"`
 	Variants     map[string]interface{}   `json:"variants"`
 	CreatedAt    string                   `json:"created_at"`
 	UpdatedAt    string                   `json:"updated_at"`
 	Category     *ProductCategoryResponse `json:"category,omitempty"`
+	Stocks       []ProductStockResponse   `json:"stocks,omitempty"`
+	Variant      *ProductVariantResponse  `json:"variant,omitempty"`
+	Price        *ResolvedPriceResponse   `json:"price,omitempty"`
+}
+
+type ProductStockResponse struct {
+	OutletID          uint64  `json:"outlet_id"`
+	OutletName        string  `json:"outlet_name"`
+	OutletCode        string  `json:"outlet_code"`
+	Quantity          float64 `json:"quantity"`
+	ReservedQuantity  float64 `json:"reserved_quantity"`
+	AvailableQuantity float64 `json:"available_quantity"`
+	UpdatedAt         *string `json:"updated_at"`
+}
+
+// Variant DTOs
+
+type GenerateVariantsRequest struct {
+	Options []ProductOptionRequest `json:"options" validate:"required,min=1,max=3,dive"`
+}
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,min=1,max=50,dive,required,max=50"`
+}
+
+type UpdateVariantRequest struct {
+	SKU          string   `json:"sku" validate:"required,max=100"`
+	Barcode      string   `json:"barcode" validate:"max=100"`
+	SellingPrice *float64 `json:"selling_price" validate:"omitempty,min=0"`
+	IsActive     bool     `json:"is_active"`
+}
+
+type ProductVariantResponse struct {
+	ID           uint64                 `json:"id"`
+	ProductID    uint64                 `json:"product_id"`
+	SKU          string                 `json:"sku"`
+	Barcode      string                 `json:"barcode"`
+	Name         string                 `json:"name"`
+	Options      map[string]string      `json:"options"`
+	SellingPrice *float64               `json:"selling_price"`
+	Price        float64                `json:"price"`
+	IsActive     bool                   `json:"is_active"`
+	CreatedAt    string                 `json:"created_at"`
+	UpdatedAt    string                 `json:"updated_at"`
+	Stocks       []ProductStockResponse `json:"stocks,omitempty"`
+}
+
+// Recipe DTOs
+
+type SetRecipeRequest struct {
+	Items []RecipeItemRequest `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+type RecipeItemRequest struct {
+	ComponentID uint64  `json:"component_id" validate:"required"`
+	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type RecipeResponse struct {
+	ProductID uint64               `json:"product_id"`
+	Cost      float64              `json:"cost"`
+	Items     []RecipeItemResponse `json:"items"`
+}
+
+type RecipeItemResponse struct {
+	ID          uint64  `json:"id"`
+	ComponentID uint64  `json:"component_id"`
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Unit        string  `json:"unit"`
+	CostPrice   float64 `json:"cost_price"`
+	TrackStock  bool    `json:"track_stock"`
+	Quantity    float64 `json:"quantity"`
+	Cost        float64 `json:"cost"`
+}
+
+// Bundle DTOs
+
+type SetBundleRequest struct {
+	Items  []BundleItemRequest  `json:"items" validate:"max=50,dive"`
+	Groups []BundleGroupRequest `json:"groups" validate:"max=10,dive"`
+}
+
+type BundleGroupRequest struct {
+	Name      string              `json:"name" validate:"required,min=1,max=100"`
+	PickCount int                 `json:"pick_count" validate:"omitempty,min=1"`
+	Items     []BundleItemRequest `json:"items" validate:"required,min=1,max=50,dive"`
+}
+
+type BundleItemRequest struct {
+	ComponentID uint64  `json:"component_id" validate:"required"`
+	VariantID   *uint64 `json:"variant_id"`
+	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type BundleResponse struct {
+	ProductID uint64                `json:"product_id"`
+	Price     float64               `json:"price"`
+	Items     []BundleItemResponse  `json:"items"`
+	Groups    []BundleGroupResponse `json:"groups"`
+}
+
+type BundleGroupResponse struct {
+	ID        uint64               `json:"id"`
+	Name      string               `json:"name"`
+	PickCount int                  `json:"pick_count"`
+	Items     []BundleItemResponse `json:"items"`
+}
+
+type BundleItemResponse struct {
+	ID          uint64  `json:"id"`
+	ComponentID uint64  `json:"component_id"`
+	VariantID   *uint64 `json:"variant_id"`
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	VariantName string  `json:"variant_name,omitempty"`
+	Unit        string  `json:"unit"`
+	TrackStock  bool    `json:"track_stock"`
+	Quantity    float64 `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+// BundleSalesQuery limits a bundle's component sales to completed
+// transactions from DateFrom up to DateTo, both inclusive and either
+// optional
+type BundleSalesQuery struct {
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+type BundleComponentSalesResponse struct {
+	ComponentID     uint64  `json:"component_id"`
+	VariantID       *uint64 `json:"variant_id"`
+	SKU             string  `json:"sku"`
+	Name            string  `json:"name"`
+	VariantName     string  `json:"variant_name,omitempty"`
+	Quantity        float64 `json:"quantity"`
+	AllocatedAmount float64 `json:"allocated_amount"`
+	CostAmount      float64 `json:"cost_amount"`
+}
+
+// Modifier DTOs
+
+type CreateModifierGroupRequest struct {
+	Name      string            `json:"name" validate:"required,min=1,max=100"`
+	MinSelect int               `json:"min_select" validate:"min=0,max=50"`
+	MaxSelect int               `json:"max_select" validate:"required,min=1,max=50"`
+	SortOrder int               `json:"sort_order"`
+	Modifiers []ModifierRequest `json:"modifiers" validate:"required,min=1,max=50,dive"`
+}
+
+// UpdateModifierGroupRequest replaces the group's modifiers. Modifiers with
+// an ID keep it, so past sales still point at them; the group's modifiers
+// left out are deleted.
+type UpdateModifierGroupRequest struct {
+	Name      string            `json:"name" validate:"required,min=1,max=100"`
+	MinSelect int               `json:"min_select" validate:"min=0,max=50"`
+	MaxSelect int               `json:"max_select" validate:"required,min=1,max=50"`
+	SortOrder int               `json:"sort_order"`
+	IsActive  bool              `json:"is_active"`
+	Modifiers []ModifierRequest `json:"modifiers" validate:"required,min=1,max=50,dive"`
+}
+
+// ModifierRequest is one modifier of a group. Quantity, of the product taken
+// from stock per unit sold, defaults to 1. New modifiers are always active.
+type ModifierRequest struct {
+	ID         *uint64 `json:"id"`
+	Name       string  `json:"name" validate:"required,min=1,max=100"`
+	PriceDelta float64 `json:"price_delta"`
+	ProductID  *uint64 `json:"product_id"`
+	Quantity   float64 `json:"quantity" validate:"omitempty,gt=0"`
+	IsActive   bool    `json:"is_active"`
+}
+
+type ModifierGroupQuery struct {
+	IsActive *bool `query:"is_active"`
+	Page     int   `query:"page"`
+	Limit    int   `query:"limit"`
+}
+
+// SetModifierGroupsRequest replaces the modifier groups attached to a
+// product or category, offered in the order given
+type SetModifierGroupsRequest struct {
+	GroupIDs []uint64 `json:"group_ids" validate:"max=20"`
+}
+
+type ModifierGroupResponse struct {
+	ID        uint64             `json:"id"`
+	Name      string             `json:"name"`
+	MinSelect int                `json:"min_select"`
+	MaxSelect int                `json:"max_select"`
+	Required  bool               `json:"required"`
+	SortOrder int                `json:"sort_order"`
+	IsActive  bool               `json:"is_active"`
+	Inherited bool               `json:"inherited,omitempty"`
+	CreatedAt string             `json:"created_at"`
+	UpdatedAt string             `json:"updated_at"`
+	Modifiers []ModifierResponse `json:"modifiers"`
+}
+
+type ModifierResponse struct {
+	ID          uint64  `json:"id"`
+	Name        string  `json:"name"`
+	PriceDelta  float64 `json:"price_delta"`
+	ProductID   *uint64 `json:"product_id"`
+	ProductSKU  string  `json:"product_sku,omitempty"`
+	ProductName string  `json:"product_name,omitempty"`
+	Quantity    float64 `json:"quantity"`
+	IsActive    bool    `json:"is_active"`
+}
+
+// ModifierSalesQuery limits modifier sales to completed transactions from
+// DateFrom up to DateTo, both inclusive and either optional
+type ModifierSalesQuery struct {
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+type ModifierSalesResponse struct {
+	ModifierID *uint64 `json:"modifier_id"`
+	GroupName  string  `json:"group_name"`
+	Name       string  `json:"name"`
+	Quantity   float64 `json:"quantity"`
+	Amount     float64 `json:"amount"`
+	CostAmount float64 `json:"cost_amount"`
+}
+
+// Unit DTOs
+
+type CreateUnitRequest struct {
+	Code         string `json:"code" validate:"required,min=1,max=50"`
+	Name         string `json:"name" validate:"required,min=1,max=100"`
+	AllowDecimal bool   `json:"allow_decimal"`
+}
+
+// UpdateUnitRequest changes a unit's name and decimals. Its code, which
+// products and documents refer to it by, stays.
+type UpdateUnitRequest struct {
+	Name         string `json:"name" validate:"required,min=1,max=100"`
+	AllowDecimal bool   `json:"allow_decimal"`
+}
+
+type UnitResponse struct {
+	ID           uint64 `json:"id"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	AllowDecimal bool   `json:"allow_decimal"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// SetProductUnitsRequest replaces the units a product converts to its base
+// unit
+type SetProductUnitsRequest struct {
+	Units []ProductUnitRequest `json:"units" validate:"max=20,dive"`
+}
+
+// ProductUnitRequest converts the unit with the code to the product's base
+// unit: one of it is Factor base units
+type ProductUnitRequest struct {
+	Unit   string  `json:"unit" validate:"required,max=50"`
+	Factor float64 `json:"factor" validate:"required,gt=0"`
+}
+
+// ProductUnitResponse is a unit of a product, its base unit first at factor
+// 1
+type ProductUnitResponse struct {
+	UnitID       *uint64 `json:"unit_id"`
+	Unit         string  `json:"unit"`
+	Name         string  `json:"name"`
+	Factor       float64 `json:"factor"`
+	AllowDecimal bool    `json:"allow_decimal"`
+	IsBase       bool    `json:"is_base"`
 }
 
 type ProductListResponse struct {
@@ -112,13 +398,211 @@ type ProductListResponse struct {
 }
 
 type ProductQuery struct {
-	CategoryID *uint64 `query:"category_id"`
-	SKU        string  `query:"sku"`
-	Barcode    string  `query:"barcode"`
-	Name       string  `query:"name"`
-	IsActive   *bool   `query:"is_active"`
-	Page       int     `query:"page"`
-	Limit      int     `query:"limit"`
-	Sort       string  `query:"sort"`
-	Order      string  `query:"order"`
+	Search     string   `query:"search"`
+	CategoryID *uint64  `query:"category_id"`
+	SKU        string   `query:"sku"`
+	Barcode    string   `query:"barcode"`
+	Name       string   `query:"name"`
+	IsActive   *bool    `query:"is_active"`
+	TrackStock *bool    `query:"track_stock"`
+	OutletID   *uint64  `query:"outlet_id"`
+	InStock    bool     `query:"in_stock"`
+	MinPrice   *float64 `query:"min_price"`
+	MaxPrice   *float64 `query:"max_price"`
+	Page       int      `query:"page"`
+	Limit      int      `query:"limit"`
+	Sort       string   `query:"sort"`
+	Order      string   `query:"order"`
+}
+
+// Price history DTOs
+
+type PriceChangeQuery struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+type PriceChangeResponse struct {
+	ID              uint64  `json:"id,omitempty"`
+	ProductID       uint64  `json:"product_id"`
+	ProductSKU      string  `json:"product_sku"`
+	ProductName     string  `json:"product_name"`
+	OldCostPrice    float64 `json:"old_cost_price"`
+	NewCostPrice    float64 `json:"new_cost_price"`
+	OldSellingPrice float64 `json:"old_selling_price"`
+	NewSellingPrice float64 `json:"new_selling_price"`
+	Source          string  `json:"source"`
+	Reason          string  `json:"reason"`
+	ChangedBy       uint64  `json:"changed_by"`
+	ChangedByName   string  `json:"changed_by_name,omitempty"`
+	EffectiveAt     string  `json:"effective_at"`
+}
+
+// BulkPriceUpdateRequest changes the cost or selling price of the active
+// products in a category by a percentage or a fixed amount
+type BulkPriceUpdateRequest struct {
+	CategoryID           uint64  `json:"category_id" validate:"required"`
+	IncludeSubcategories bool    `json:"include_subcategories"`
+	Field                string  `json:"field" validate:"required,oneof=selling_price cost_price"`
+	Method               string  `json:"method" validate:"required,oneof=percentage fixed"`
+	Value                float64 `json:"value"`
+	Rounding             string  `json:"rounding" validate:"omitempty,oneof=none nearest up down"`
+	RoundTo              float64 `json:"round_to" validate:"min=0"`
+	Reason               string  `json:"reason" validate:"max=255"`
+	DryRun               bool    `json:"dry_run"`
+}
+
+type BulkPriceUpdateResponse struct {
+	DryRun       bool                  `json:"dry_run"`
+	UpdatedCount int                   `json:"updated_count"`
+	Changes      []PriceChangeResponse `json:"changes"`
+}
+
+// Price list DTOs
+
+type CreatePriceListRequest struct {
+	Name             string     `json:"name" validate:"required,min=1,max=255"`
+	Description      string     `json:"description"`
+	Priority         int        `json:"priority" validate:"min=0,max=1000"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	OutletIDs        []uint64   `json:"outlet_ids" validate:"max=100"`
+	CustomerGroupIDs []uint64   `json:"customer_group_ids" validate:"max=100"`
+}
+
+type UpdatePriceListRequest struct {
+	Name             string     `json:"name" validate:"required,min=1,max=255"`
+	Description      string     `json:"description"`
+	Priority         int        `json:"priority" validate:"min=0,max=1000"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	IsActive         bool       `json:"is_active"`
+	OutletIDs        []uint64   `json:"outlet_ids" validate:"max=100"`
+	CustomerGroupIDs []uint64   `json:"customer_group_ids" validate:"max=100"`
+}
+
+// SetPriceListItemsRequest adds items to a price list, replacing the price
+// of products and variants the list already has
+type SetPriceListItemsRequest struct {
+	Items []PriceListItemRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+type PriceListItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required"`
+	VariantID *uint64 `json:"variant_id"`
+	Price     float64 `json:"price" validate:"min=0"`
+}
+
+type PriceListQuery struct {
+	IsActive *bool   `query:"is_active"`
+	OutletID *uint64 `query:"outlet_id"`
+	Page     int     `query:"page"`
+	Limit    int     `query:"limit"`
+}
+
+type PriceListResponse struct {
+	ID               uint64                  `json:"id"`
+	Name             string                  `json:"name"`
+	Description      string                  `json:"description"`
+	Priority         int                     `json:"priority"`
+	StartsAt         *string                 `json:"starts_at"`
+	EndsAt           *string                 `json:"ends_at"`
+	IsActive         bool                    `json:"is_active"`
+	IsValidNow       bool                    `json:"is_valid_now"`
+	OutletIDs        []uint64                `json:"outlet_ids"`
+	CustomerGroupIDs []uint64                `json:"customer_group_ids"`
+	ItemCount        int64                   `json:"item_count"`
+	CreatedAt        string                  `json:"created_at"`
+	UpdatedAt        string                  `json:"updated_at"`
+	Items            []PriceListItemResponse `json:"items,omitempty"`
+}
+
+type PriceListItemResponse struct {
+	ID          uint64  `json:"id"`
+	ProductID   uint64  `json:"product_id"`
+	ProductSKU  string  `json:"product_sku"`
+	ProductName string  `json:"product_name"`
+	VariantID   *uint64 `json:"variant_id,omitempty"`
+	VariantSKU  string  `json:"variant_sku,omitempty"`
+	VariantName string  `json:"variant_name,omitempty"`
+	Price       float64 `json:"price"`
+}
+
+// ResolvePricesRequest asks for the prices of products sold at an outlet to
+// a customer at a time; both may be left out, and the time defaults to now
+type ResolvePricesRequest struct {
+	OutletID   *uint64            `json:"outlet_id"`
+	CustomerID *uint64            `json:"customer_id"`
+	At         *time.Time         `json:"at"`
+	Items      []PriceItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// PriceItemRequest is one product sold, with the modifiers picked for it
+type PriceItemRequest struct {
+	ProductID   uint64   `json:"product_id" validate:"required"`
+	VariantID   *uint64  `json:"variant_id"`
+	ModifierIDs []uint64 `json:"modifier_ids" validate:"max=50"`
+}
+
+type ResolvedPriceResponse struct {
+	ProductID      uint64                     `json:"product_id"`
+	VariantID      *uint64                    `json:"variant_id,omitempty"`
+	BasePrice      float64                    `json:"base_price"`
+	ModifiersPrice float64                    `json:"modifiers_price"`
+	Price          float64                    `json:"price"`
+	PriceListID    *uint64                    `json:"price_list_id"`
+	PriceListName  string                     `json:"price_list_name,omitempty"`
+	Modifiers      []ResolvedModifierResponse `json:"modifiers,omitempty"`
+}
+
+type ResolvedModifierResponse struct {
+	ID         uint64  `json:"id"`
+	GroupID    uint64  `json:"group_id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+}
+
+type ImageResponse struct {
+	ID          uint64            `json:"id"`
+	URL         string            `json:"url"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	CreatedAt   string            `json:"created_at"`
+}
+
+// AssignBarcodesRequest assigns barcodes to the listed products that have
+// none, or to every active product without one when the list is empty. The
+// format defaults to the tenant's barcode settings.
+type AssignBarcodesRequest struct {
+	ProductIDs []uint64 `json:"product_ids" validate:"max=1000"`
+	Format     string   `json:"format" validate:"omitempty,oneof=ean13 code128"`
+}
+
+type AssignBarcodesResponse struct {
+	AssignedCount int                       `json:"assigned_count"`
+	Products      []AssignedBarcodeResponse `json:"products"`
+}
+
+type AssignedBarcodeResponse struct {
+	ProductID uint64 `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Barcode   string `json:"barcode"`
+}
+
+// PrintLabelsRequest asks for a PDF sheet of labels, priced at the outlet
+// when one is given
+type PrintLabelsRequest struct {
+	Template string             `json:"template" validate:"required,oneof=shelf price_tag"`
+	OutletID *uint64            `json:"outlet_id"`
+	Items    []LabelItemRequest `json:"items" validate:"required,min=1,max=500,dive"`
+}
+
+type LabelItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required"`
+	VariantID *uint64 `json:"variant_id"`
+	Copies    int     `json:"copies" validate:"omitempty,min=1,max=100"`
 }
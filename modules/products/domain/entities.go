@@ -1,7 +1,13 @@
 package domain
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/exven/pos-system/shared/utils/barcode"
 )
 
 type ProductCategory struct {
@@ -31,8 +37,9 @@ type Product struct {
 	Unit         string
 	CostPrice    float64
 	SellingPrice float64
-	MinStock     int
+	MinStock     float64
 	TrackStock   bool
+	TrackLots    bool
 	IsActive     bool
 	Images       []string
 	Variants     map[string]interface{}
@@ -40,4 +47,626 @@ type Product struct {
 	UpdatedAt    time.Time
 
 	Category *ProductCategory
+	Stocks   []*ProductStock
+	Variant  *ProductVariant // The variant a barcode lookup resolved to
+}
+
+// ProductOption is a dimension a product varies in, such as size, with the
+// values it comes in. A product's options are kept in its Variants map, from
+// option name to values.
+type ProductOption struct {
+	Name   string
+	Values []string
+}
+
+// ProductVariant is one combination of a product's option values, with its
+// own SKU, barcode and stock. SellingPrice overrides the product's price
+// when set.
+type ProductVariant struct {
+	ID           uint64
+	TenantID     uint64
+	ProductID    uint64
+	SKU          string
+	Barcode      string
+	Name         string
+	Options      map[string]string
+	SellingPrice *float64
+	IsActive     bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Stocks []*ProductStock
+}
+
+// Price is what the variant sells for
+func (v *ProductVariant) Price(product *Product) float64 {
+	if v.SellingPrice != nil {
+		return *v.SellingPrice
+	}
+	return product.SellingPrice
+}
+
+// SameOptions reports whether the variant is the given combination of
+// option values
+func (v *ProductVariant) SameOptions(options map[string]string) bool {
+	if len(v.Options) != len(options) {
+		return false
+	}
+	for name, value := range options {
+		if v.Options[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ProductStock is the stock of a product at one outlet. UpdatedAt is nil for
+// outlets that have never held the product.
+type ProductStock struct {
+	OutletID         uint64
+	OutletName       string
+	OutletCode       string
+	Quantity         float64
+	ReservedQuantity float64
+	UpdatedAt        *time.Time
+}
+
+// Outlet is a stock location, as listed in product exports
+type Outlet struct {
+	ID   uint64
+	Code string
+	Name string
+}
+
+func (s *ProductStock) AvailableQuantity() float64 {
+	return RoundQuantity(s.Quantity - s.ReservedQuantity)
+}
+
+// RoundQuantity rounds a quantity to the three decimals it is stored with
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// Recipe lists the components consumed whenever its product is sold
+type Recipe struct {
+	ProductID uint64
+	Items     []*RecipeItem
+}
+
+// Cost rolls the components' cost prices up into the cost of one unit of
+// the product
+func (r *Recipe) Cost() float64 {
+	var cost float64
+	for _, item := range r.Items {
+		cost += item.Cost()
+	}
+	return math.Round(cost*100) / 100
+}
+
+// RecipeItem is one component of a recipe. Quantity is in the component's
+// unit, per unit of the product sold.
+type RecipeItem struct {
+	ID          uint64
+	ProductID   uint64
+	ComponentID uint64
+	Quantity    float64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Component *Product
+}
+
+func (i *RecipeItem) Cost() float64 {
+	if i.Component == nil {
+		return 0
+	}
+	return float64(i.Quantity) * i.Component.CostPrice
+}
+
+// Bundle lists the components of a bundle product, sold together at the
+// bundle's selling price. Items are part of every bundle sold; each group
+// adds its PickCount choices, picked at checkout.
+type Bundle struct {
+	ProductID    uint64
+	SellingPrice float64
+	Items        []*BundleItem
+	Groups       []*BundleGroup
+}
+
+// BundleGroup is a choice within a bundle, such as one drink out of four
+type BundleGroup struct {
+	ID        uint64
+	ProductID uint64
+	Name      string
+	PickCount int
+	SortOrder int
+	Items     []*BundleItem
+}
+
+// BundleItem is one component of a bundle, or one choice of a group.
+// Quantity is in the component's unit, per bundle sold.
+type BundleItem struct {
+	ID          uint64
+	ProductID   uint64
+	GroupID     *uint64
+	ComponentID uint64
+	VariantID   *uint64
+	Quantity    float64
+
+	Component *Product
+	Variant   *ProductVariant
+}
+
+// Price is what the item's quantity of the component sells for on its own
+func (i *BundleItem) Price() float64 {
+	if i.Component == nil {
+		return 0
+	}
+	price := i.Component.SellingPrice
+	if i.Variant != nil {
+		price = i.Variant.Price(i.Component)
+	}
+	return float64(i.Quantity) * price
+}
+
+// BundleComponentSales sums what was sold of a component through a bundle,
+// with the share of the bundle amounts allocated to it
+type BundleComponentSales struct {
+	ComponentID     uint64
+	VariantID       *uint64
+	SKU             string
+	Name            string
+	VariantName     string
+	Quantity        float64
+	AllocatedAmount float64
+	CostAmount      float64
+}
+
+// ModifierGroup is a set of options picked for a product at the counter,
+// such as sugar level or toppings, from MinSelect up to MaxSelect of its
+// modifiers. Groups are attached to products and to categories, whose
+// products all offer them; Inherited marks a group a product offers through
+// its category.
+type ModifierGroup struct {
+	ID        uint64
+	TenantID  uint64
+	Name      string
+	MinSelect int
+	MaxSelect int
+	SortOrder int
+	IsActive  bool
+	Inherited bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Modifiers []*Modifier
+}
+
+// IsRequired reports whether every sale of the product must pick from the
+// group
+func (g *ModifierGroup) IsRequired() bool {
+	return g.MinSelect > 0
+}
+
+// Modifier is one option of a modifier group. PriceDelta, which may be
+// negative, is added to the price of every unit it is picked for. A
+// modifier with a product takes Quantity of it from stock per unit sold.
+type Modifier struct {
+	ID          uint64
+	GroupID     uint64
+	Name        string
+	PriceDelta  float64
+	ProductID   *uint64
+	Quantity    float64
+	SortOrder   int
+	IsActive    bool
+	ProductSKU  string
+	ProductName string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SelectModifiers checks the modifiers picked for a product against the
+// groups it offers and returns them in group order. Every pick must be an
+// active modifier of an active group, picked once, and every active group
+// must get from its MinSelect up to its MaxSelect picks.
+func SelectModifiers(groups []*ModifierGroup, modifierIDs []uint64) ([]*Modifier, error) {
+	picked := make(map[uint64]bool, len(modifierIDs))
+	for _, id := range modifierIDs {
+		if picked[id] {
+			return nil, errors.New("modifier is picked more than once")
+		}
+		picked[id] = true
+	}
+
+	var selected []*Modifier
+	for _, group := range groups {
+		if !group.IsActive {
+			continue
+		}
+		count := 0
+		for _, modifier := range group.Modifiers {
+			if modifier.IsActive && picked[modifier.ID] {
+				selected = append(selected, modifier)
+				delete(picked, modifier.ID)
+				count++
+			}
+		}
+		if count < group.MinSelect || count > group.MaxSelect {
+			return nil, errors.New("modifiers do not match the product's modifier groups")
+		}
+	}
+
+	if len(picked) > 0 {
+		return nil, errors.New("invalid modifier")
+	}
+
+	return selected, nil
+}
+
+// ModifierSales sums the sales of a modifier, by the group and modifier
+// names it was sold under. Amount is what its price delta added.
+type ModifierSales struct {
+	ModifierID *uint64
+	GroupName  string
+	Name       string
+	Quantity   float64
+	Amount     float64
+	CostAmount float64
+}
+
+// Unit is a unit of measure of the tenant, such as pcs, kg or ctn, named by
+// its code. Quantities of a unit that does not allow decimals are whole.
+// Products name their base unit by code; their stock is kept in it.
+type Unit struct {
+	ID           uint64
+	TenantID     uint64
+	Code         string
+	Name         string
+	AllowDecimal bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ProductUnit converts a unit a product is sold, ordered or transferred in
+// to its base unit: one of the unit is Factor of the base unit, as a carton
+// holds 24 pieces. IsBase marks the base unit itself, at factor 1; its
+// UnitID is zero when the tenant has no unit with its code.
+type ProductUnit struct {
+	ID           uint64
+	ProductID    uint64
+	UnitID       uint64
+	Code         string
+	Name         string
+	AllowDecimal bool
+	Factor       float64
+	IsBase       bool
+}
+
+const (
+	PriceChangeSourceProductUpdate = "product_update"
+	PriceChangeSourceBulkUpdate    = "bulk_update"
+	PriceChangeSourceGoodsReceipt  = "goods_receipt"
+	PriceChangeSourceImport        = "import"
+)
+
+// PriceChange records a change of a product's cost or selling price
+type PriceChange struct {
+	ID              uint64
+	TenantID        uint64
+	ProductID       uint64
+	ProductSKU      string
+	ProductName     string
+	OldCostPrice    float64
+	NewCostPrice    float64
+	OldSellingPrice float64
+	NewSellingPrice float64
+	Source          string
+	Reason          string
+	ChangedBy       uint64
+	ChangedByName   string
+	EffectiveAt     time.Time
+	CreatedAt       time.Time
+}
+
+// PriceRule changes a price by a percentage or a fixed amount, then rounds
+// the result to a multiple of RoundTo
+type PriceRule struct {
+	Method   string
+	Value    float64
+	Rounding string
+	RoundTo  float64
+}
+
+const (
+	PriceRuleMethodPercentage = "percentage"
+	PriceRuleMethodFixed      = "fixed"
+
+	PriceRoundingNone    = "none"
+	PriceRoundingNearest = "nearest"
+	PriceRoundingUp      = "up"
+	PriceRoundingDown    = "down"
+)
+
+// Apply returns the price after the rule, in whole cents
+func (r PriceRule) Apply(price float64) float64 {
+	if r.Method == PriceRuleMethodPercentage {
+		price = price * (100 + r.Value) / 100
+	} else {
+		price += r.Value
+	}
+
+	step := r.RoundTo
+	if step <= 0 {
+		step = 1
+	}
+	switch r.Rounding {
+	case PriceRoundingNearest:
+		price = math.Round(price/step) * step
+	case PriceRoundingUp:
+		// Cents guard against 10.000000001 rounding up to the next step
+		price = math.Ceil(math.Round(price/step*100)/100) * step
+	case PriceRoundingDown:
+		price = math.Floor(math.Round(price/step*100)/100) * step
+	}
+
+	return math.Round(price*100) / 100
+}
+
+// PriceList overrides the selling prices of its items while it is valid,
+// from StartsAt up to but not including EndsAt; either end may be open. A
+// list limited to outlets or customer groups only prices sales at those
+// outlets or to customers in those groups.
+type PriceList struct {
+	ID               uint64
+	TenantID         uint64
+	Name             string
+	Description      string
+	Priority         int
+	StartsAt         *time.Time
+	EndsAt           *time.Time
+	IsActive         bool
+	OutletIDs        []uint64
+	CustomerGroupIDs []uint64
+	ItemCount        int64
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	Items []*PriceListItem
+}
+
+// IsValidAt reports whether the list is active and within its window at t
+func (l *PriceList) IsValidAt(t time.Time) bool {
+	if !l.IsActive {
+		return false
+	}
+	if l.StartsAt != nil && t.Before(*l.StartsAt) {
+		return false
+	}
+	return l.EndsAt == nil || t.Before(*l.EndsAt)
+}
+
+// PriceListItem prices a product, or one of its variants, in a price list.
+// A product's item also prices its variants without an item of their own.
+type PriceListItem struct {
+	ID          uint64
+	PriceListID uint64
+	ProductID   uint64
+	VariantID   *uint64
+	Price       float64
+	ProductSKU  string
+	ProductName string
+	VariantSKU  string
+	VariantName string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// PriceCandidate is a price list item that applies to a sale
+type PriceCandidate struct {
+	PriceListID   uint64
+	PriceListName string
+	Priority      int
+	StartsAt      *time.Time
+	ProductID     uint64
+	VariantID     *uint64
+	Price         float64
+}
+
+// outranks reports whether c takes precedence over other: the higher
+// priority wins, then an item of the variant over one of its product, then
+// the list that started last, then the newest list.
+func (c *PriceCandidate) outranks(other *PriceCandidate) bool {
+	if c.Priority != other.Priority {
+		return c.Priority > other.Priority
+	}
+	if (c.VariantID != nil) != (other.VariantID != nil) {
+		return c.VariantID != nil
+	}
+	if !sameTime(c.StartsAt, other.StartsAt) {
+		if c.StartsAt == nil || other.StartsAt == nil {
+			return other.StartsAt == nil
+		}
+		return c.StartsAt.After(*other.StartsAt)
+	}
+	return c.PriceListID > other.PriceListID
+}
+
+// BestPrice picks the candidate that prices the product, or the variant when
+// variantID is set, or nil when none applies
+func BestPrice(candidates []*PriceCandidate, productID uint64, variantID *uint64) *PriceCandidate {
+	var best *PriceCandidate
+	for _, candidate := range candidates {
+		if candidate.ProductID != productID {
+			continue
+		}
+		if candidate.VariantID != nil && (variantID == nil || *candidate.VariantID != *variantID) {
+			continue
+		}
+		if best == nil || candidate.outranks(best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// ResolvedPrice is the price a product, or variant, sells for. BasePrice is
+// its own selling price; Price is the price list's when one applies, plus
+// ModifiersPrice, the price deltas of the modifiers picked for it.
+type ResolvedPrice struct {
+	ProductID      uint64
+	VariantID      *uint64
+	BasePrice      float64
+	ModifiersPrice float64
+	Price          float64
+	PriceListID    *uint64
+	PriceListName  string
+	Modifiers      []*Modifier
+}
+
+// MaxProductImages is the most images a product can have
+const MaxProductImages = 10
+
+// ThumbnailSize is a thumbnail made of every uploaded image, fitting within a
+// square of Size pixels
+type ThumbnailSize struct {
+	Name string
+	Size int
+}
+
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Size: 150},
+	{Name: "medium", Size: 400},
+	{Name: "large", Size: 800},
+}
+
+// Image is an uploaded picture of a product or a category, kept in file
+// storage. Thumbnails maps each thumbnail size name to its storage key; URL
+// and ThumbnailURLs are where the files are served from.
+type Image struct {
+	ID          uint64
+	TenantID    uint64
+	ProductID   *uint64
+	CategoryID  *uint64
+	StorageKey  string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Thumbnails  map[string]string
+	CreatedBy   uint64
+	CreatedAt   time.Time
+
+	URL           string
+	ThumbnailURLs map[string]string
+}
+
+const (
+	BarcodeFormatEAN13   = "ean13"
+	BarcodeFormatCode128 = "code128"
+
+	DefaultBarcodePrefix = "20"
+)
+
+// BarcodeSettings are the tenant's settings for barcodes assigned to
+// products without one
+type BarcodeSettings struct {
+	Prefix     string
+	Format     string
+	AutoAssign bool
+}
+
+// BarcodeScheme numbers assigned barcodes: the prefix followed by a
+// sequence number, 12 digits in all. EAN-13 barcodes add a check digit.
+type BarcodeScheme struct {
+	Prefix string
+	Format string
+}
+
+// Length is the length of the scheme's barcodes
+func (s BarcodeScheme) Length() int {
+	if s.Format == BarcodeFormatEAN13 {
+		return 13
+	}
+	return 12
+}
+
+// SequenceDigits is the number of digits the sequence number takes up
+func (s BarcodeScheme) SequenceDigits() int {
+	return 12 - len(s.Prefix)
+}
+
+// Barcode returns the barcode with the given sequence number
+func (s BarcodeScheme) Barcode(sequence uint64) (string, error) {
+	digits := strconv.FormatUint(sequence, 10)
+	if len(digits) > s.SequenceDigits() {
+		return "", errors.New("barcode numbers of the prefix are used up")
+	}
+
+	code := s.Prefix + strings.Repeat("0", s.SequenceDigits()-len(digits)) + digits
+	if s.Format == BarcodeFormatEAN13 {
+		check, err := barcode.EAN13CheckDigit(code)
+		if err != nil {
+			return "", err
+		}
+		code += string(check)
+	}
+	return code, nil
+}
+
+// NumberFormat is how the tenant writes amounts of money
+type NumberFormat struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	DecimalPlaces      int
+	CurrencySymbol     string
+}
+
+// Format writes the amount with the currency symbol, as in "Rp 12.500"
+func (f NumberFormat) Format(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	text := strconv.FormatFloat(amount, 'f', f.DecimalPlaces, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.ThousandsSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.DecimalSeparator)
+		b.WriteString(fraction)
+	}
+
+	if f.CurrencySymbol == "" {
+		return sign + b.String()
+	}
+	return sign + f.CurrencySymbol + " " + b.String()
+}
+
+const (
+	LabelTemplateShelf    = "shelf"
+	LabelTemplatePriceTag = "price_tag"
+)
+
+// Label is what one shelf label or price tag shows
+type Label struct {
+	Name    string
+	SKU     string
+	Barcode string
+	Price   string
 }
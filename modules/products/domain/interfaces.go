@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"io"
+	"time"
 )
 
 type ProductCategoryRepository interface {
@@ -16,16 +18,179 @@ type ProductCategoryRepository interface {
 }
 
 type ProductRepository interface {
-	Create(ctx context.Context, product *Product) error
-	Update(ctx context.Context, product *Product) error
+	// Create stores the product together with a stock row for each of its
+	// Stocks. Non-zero quantities enter through the stock ledger as
+	// "initial" stock movements by createdBy.
+	Create(ctx context.Context, product *Product, createdBy uint64) error
+	// Update stores the product and, when priceChange is not nil, records it
+	// in the same transaction
+	Update(ctx context.Context, product *Product, priceChange *PriceChange) error
 	Delete(ctx context.Context, tenantID, productID uint64) error
 	FindByID(ctx context.Context, tenantID, productID uint64) (*Product, error)
-	FindAll(ctx context.Context, tenantID uint64, limit, offset int) ([]*Product, int64, error)
+	FindAll(ctx context.Context, tenantID uint64, query ProductQuery) ([]*Product, int64, error)
 	FindBySKU(ctx context.Context, tenantID uint64, sku string) (*Product, error)
 	FindByBarcode(ctx context.Context, tenantID uint64, barcode string) (*Product, error)
 	FindByCategory(ctx context.Context, tenantID, categoryID uint64, limit, offset int) ([]*Product, int64, error)
 	CheckSKUExists(ctx context.Context, tenantID uint64, sku string, excludeID *uint64) (bool, error)
 	Count(ctx context.Context, tenantID uint64) (int64, error)
+	FindActiveOutletIDs(ctx context.Context, tenantID uint64) ([]uint64, error)
+	FindStocks(ctx context.Context, tenantID, productID uint64) ([]*ProductStock, error)
+	// FindBatch lists up to limit products with an ID above afterID, in ID
+	// order, for walking the whole catalogue
+	FindBatch(ctx context.Context, tenantID, afterID uint64, limit int) ([]*Product, error)
+	FindActiveOutlets(ctx context.Context, tenantID uint64) ([]*Outlet, error)
+	// FindStockTotals sums each product's stock per outlet, variants included
+	FindStockTotals(ctx context.Context, productIDs []uint64) (map[uint64]map[uint64]float64, error)
+	FindPriceChanges(ctx context.Context, tenantID, productID uint64, limit, offset int) ([]*PriceChange, int64, error)
+	// FindActiveByCategory lists the active products of a category and,
+	// with includeSubcategories, of the categories below it
+	FindActiveByCategory(ctx context.Context, tenantID, categoryID uint64, includeSubcategories bool) ([]*Product, error)
+	// UpdatePrices sets the new prices of the changes' products and records
+	// the changes, all in one transaction
+	UpdatePrices(ctx context.Context, changes []*PriceChange) error
+	// FindNumberFormat returns how the tenant writes amounts of money
+	FindNumberFormat(ctx context.Context, tenantID uint64) (*NumberFormat, error)
+}
+
+type BarcodeRepository interface {
+	FindSettings(ctx context.Context, tenantID uint64) (*BarcodeSettings, error)
+	// Exists reports whether a product or variant of the tenant, other than
+	// the excluded ones, has the barcode
+	Exists(ctx context.Context, tenantID uint64, barcode string, excludeProductID, excludeVariantID *uint64) (bool, error)
+	// Assign numbers the products without a barcode among productIDs, or all
+	// active products without one when productIDs is empty, continuing after
+	// the highest barcode of the scheme in use. It returns the products that
+	// got a barcode.
+	Assign(ctx context.Context, tenantID uint64, productIDs []uint64, scheme BarcodeScheme) ([]*Product, error)
+}
+
+type VariantRepository interface {
+	FindByID(ctx context.Context, tenantID, productID, variantID uint64) (*ProductVariant, error)
+	FindByProduct(ctx context.Context, productID uint64) ([]*ProductVariant, error)
+	FindByBarcode(ctx context.Context, tenantID uint64, barcode string) (*ProductVariant, error)
+	FindStocks(ctx context.Context, tenantID uint64, variantIDs []uint64) (map[uint64][]*ProductStock, error)
+	// Sync stores the product's options, creates the new variants with a stock
+	// row at every outlet in stockOutletIDs and updates the existing ones
+	Sync(ctx context.Context, product *Product, variants []*ProductVariant, stockOutletIDs []uint64) error
+	Update(ctx context.Context, variant *ProductVariant) error
+	Delete(ctx context.Context, variantID uint64) error
+	HasMovements(ctx context.Context, variantID uint64) (bool, error)
+	CheckSKUExists(ctx context.Context, tenantID uint64, sku string, excludeID *uint64) (bool, error)
+}
+
+type RecipeRepository interface {
+	FindByProduct(ctx context.Context, productID uint64) ([]*RecipeItem, error)
+	// Replace swaps the product's recipe for items; an empty list removes it
+	Replace(ctx context.Context, productID uint64, items []*RecipeItem) error
+	FindComponents(ctx context.Context, tenantID uint64, componentIDs []uint64) (map[uint64]*Product, error)
+	HasRecipe(ctx context.Context, productIDs []uint64) (bool, error)
+	IsComponent(ctx context.Context, productID uint64) (bool, error)
+}
+
+type BundleRepository interface {
+	// FindByProduct returns the product's bundle, without items or groups
+	// when the product is not a bundle
+	FindByProduct(ctx context.Context, productID uint64) (*Bundle, error)
+	// Replace swaps the product's bundle for bundle; nil removes it
+	Replace(ctx context.Context, productID uint64, bundle *Bundle) error
+	HasBundle(ctx context.Context, productIDs []uint64) (bool, error)
+	IsComponent(ctx context.Context, productID uint64) (bool, error)
+	IsVariantComponent(ctx context.Context, variantID uint64) (bool, error)
+	// FindComponentSales sums the components sold through the bundle by
+	// component and variant
+	FindComponentSales(ctx context.Context, tenantID, productID uint64, query BundleSalesQuery) ([]*BundleComponentSales, error)
+}
+
+type ModifierRepository interface {
+	// Create stores the group with its modifiers
+	Create(ctx context.Context, group *ModifierGroup) error
+	// Update stores the group and its modifiers, creating those without an
+	// ID and deleting the group's other modifiers
+	Update(ctx context.Context, group *ModifierGroup) error
+	Delete(ctx context.Context, tenantID, groupID uint64) error
+	// FindByID returns the group with its modifiers
+	FindByID(ctx context.Context, tenantID, groupID uint64) (*ModifierGroup, error)
+	FindAll(ctx context.Context, tenantID uint64, query ModifierGroupQuery) ([]*ModifierGroup, int64, error)
+	CountGroups(ctx context.Context, tenantID uint64, groupIDs []uint64) (int64, error)
+	// FindForProducts returns the groups each product offers, with their
+	// modifiers: the category's groups, marked inherited, then the
+	// product's own
+	FindForProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64][]*ModifierGroup, error)
+	FindByCategory(ctx context.Context, categoryID uint64) ([]*ModifierGroup, error)
+	// ReplaceForProduct attaches the groups to the product instead of its
+	// current ones, in order
+	ReplaceForProduct(ctx context.Context, productID uint64, groupIDs []uint64) error
+	ReplaceForCategory(ctx context.Context, categoryID uint64, groupIDs []uint64) error
+	// IsModifierProduct reports whether a modifier takes the product from
+	// stock
+	IsModifierProduct(ctx context.Context, productID uint64) (bool, error)
+	// FindSales sums the modifiers sold by modifier and the names they were
+	// sold under
+	FindSales(ctx context.Context, tenantID uint64, query ModifierSalesQuery) ([]*ModifierSales, error)
+}
+
+type UnitRepository interface {
+	Create(ctx context.Context, unit *Unit) error
+	Update(ctx context.Context, unit *Unit) error
+	// Delete refuses units a product converts or is kept in
+	Delete(ctx context.Context, tenantID, unitID uint64) error
+	FindByID(ctx context.Context, tenantID, unitID uint64) (*Unit, error)
+	FindAll(ctx context.Context, tenantID uint64) ([]*Unit, error)
+	// FindByCodes returns the tenant's units with the codes, keyed by code
+	FindByCodes(ctx context.Context, tenantID uint64, codes []string) (map[string]*Unit, error)
+	CodeExists(ctx context.Context, tenantID uint64, code string) (bool, error)
+	// FindProductUnits returns the units the product converts, by factor
+	FindProductUnits(ctx context.Context, productID uint64) ([]*ProductUnit, error)
+	// ReplaceProductUnits converts the units instead of the product's
+	// current ones
+	ReplaceProductUnits(ctx context.Context, productID uint64, units []*ProductUnit) error
+}
+
+type PriceListRepository interface {
+	// Create stores the list with its outlets and customer groups
+	Create(ctx context.Context, list *PriceList) error
+	// Update stores the list, replacing its outlets and customer groups
+	Update(ctx context.Context, list *PriceList) error
+	Delete(ctx context.Context, tenantID, listID uint64) error
+	FindByID(ctx context.Context, tenantID, listID uint64) (*PriceList, error)
+	FindAll(ctx context.Context, tenantID uint64, query PriceListQuery) ([]*PriceList, int64, error)
+	FindItems(ctx context.Context, listID uint64) ([]*PriceListItem, error)
+	// UpsertItems adds the items, replacing the price of products and
+	// variants already in the list
+	UpsertItems(ctx context.Context, listID uint64, items []*PriceListItem) error
+	DeleteItem(ctx context.Context, listID, itemID uint64) error
+	CountOutlets(ctx context.Context, tenantID uint64, outletIDs []uint64) (int64, error)
+	CountCustomerGroups(ctx context.Context, tenantID uint64, groupIDs []uint64) (int64, error)
+	// FindCustomerGroupID returns the group of an active customer, nil when
+	// the customer has none
+	FindCustomerGroupID(ctx context.Context, tenantID, customerID uint64) (*uint64, error)
+	// FindCandidates lists the items of the lists valid at a time that
+	// apply at the outlet and to the customer group, either of which may be
+	// nil, for the given products
+	FindCandidates(ctx context.Context, tenantID uint64, productIDs []uint64, outletID, customerGroupID *uint64, at time.Time) ([]*PriceCandidate, error)
+	// FindBasePrices returns the selling prices of the tenant's products and
+	// the variants, keyed by ID; missing IDs do not belong to the tenant
+	FindBasePrices(ctx context.Context, tenantID uint64, productIDs, variantIDs []uint64) (map[uint64]float64, map[uint64]*ProductVariant, error)
+}
+
+// ImageRepository stores uploaded images and keeps the image URLs of their
+// products and categories in step with them
+type ImageRepository interface {
+	// CreateForProduct stores the image and adds its URL to the product's
+	// images
+	CreateForProduct(ctx context.Context, image *Image) error
+	// ReplaceForCategory stores the image as the category's image and returns
+	// the images it replaced
+	ReplaceForCategory(ctx context.Context, image *Image) ([]*Image, error)
+	FindByID(ctx context.Context, tenantID, imageID uint64) (*Image, error)
+	FindByProduct(ctx context.Context, tenantID, productID uint64) ([]*Image, error)
+	CountByProduct(ctx context.Context, tenantID, productID uint64) (int64, error)
+	// DeleteFromProduct removes the image and its URL from the product's
+	// images
+	DeleteFromProduct(ctx context.Context, image *Image) error
+	// DeleteFromCategory removes the category's images, clears its image URL
+	// and returns the removed images
+	DeleteFromCategory(ctx context.Context, tenantID, categoryID uint64) ([]*Image, error)
 }
 
 type ProductCategoryService interface {
@@ -38,12 +203,106 @@ type ProductCategoryService interface {
 }
 
 type ProductService interface {
-	Create(ctx context.Context, tenantID uint64, req CreateProductRequest) (*Product, error)
-	Update(ctx context.Context, tenantID, productID uint64, req UpdateProductRequest) (*Product, error)
+	Create(ctx context.Context, tenantID, userID uint64, req CreateProductRequest) (*Product, error)
+	Update(ctx context.Context, tenantID, userID, productID uint64, req UpdateProductRequest) (*Product, error)
 	Delete(ctx context.Context, tenantID, productID uint64) error
 	GetByID(ctx context.Context, tenantID, productID uint64) (*Product, error)
-	GetAll(ctx context.Context, tenantID uint64, limit, offset int) ([]*Product, int64, error)
+	GetAll(ctx context.Context, tenantID uint64, query ProductQuery) ([]*Product, int64, error)
 	GetBySKU(ctx context.Context, tenantID uint64, sku string) (*Product, error)
 	GetByBarcode(ctx context.Context, tenantID uint64, barcode string) (*Product, error)
 	GetByCategory(ctx context.Context, tenantID, categoryID uint64, limit, offset int) ([]*Product, int64, error)
+	GetStocks(ctx context.Context, tenantID, productID uint64) ([]*ProductStock, error)
+	// Export writes every product of the tenant to w as a spreadsheet
+	Export(ctx context.Context, tenantID uint64, format string, w io.Writer) error
+	GetPriceHistory(ctx context.Context, tenantID, productID uint64, query PriceChangeQuery) ([]*PriceChange, int64, error)
+	// BulkUpdatePrices changes the price of a category's products; with
+	// DryRun the changes are only returned
+	BulkUpdatePrices(ctx context.Context, tenantID, userID uint64, req BulkPriceUpdateRequest) ([]*PriceChange, error)
+
+	GetVariants(ctx context.Context, tenantID, productID uint64) ([]*ProductVariant, error)
+	GetVariantStocks(ctx context.Context, tenantID uint64, variants []*ProductVariant) error
+	GenerateVariants(ctx context.Context, tenantID, productID uint64, req GenerateVariantsRequest) ([]*ProductVariant, error)
+	UpdateVariant(ctx context.Context, tenantID, productID, variantID uint64, req UpdateVariantRequest) (*ProductVariant, error)
+	DeleteVariant(ctx context.Context, tenantID, productID, variantID uint64) error
+
+	GetRecipe(ctx context.Context, tenantID, productID uint64) (*Recipe, error)
+	SetRecipe(ctx context.Context, tenantID, productID uint64, req SetRecipeRequest) (*Recipe, error)
+	DeleteRecipe(ctx context.Context, tenantID, productID uint64) error
+
+	GetBundle(ctx context.Context, tenantID, productID uint64) (*Bundle, error)
+	SetBundle(ctx context.Context, tenantID, productID uint64, req SetBundleRequest) (*Bundle, error)
+	DeleteBundle(ctx context.Context, tenantID, productID uint64) error
+	GetBundleSales(ctx context.Context, tenantID, productID uint64, query BundleSalesQuery) ([]*BundleComponentSales, error)
+
+	// AssignBarcodes gives products without a barcode one from the tenant's
+	// barcode scheme and returns them
+	AssignBarcodes(ctx context.Context, tenantID uint64, req AssignBarcodesRequest) ([]*Product, error)
+}
+
+// PriceListService manages price lists and resolves the price a product
+// sells for. Checkout resolves the prices of a sale's items through it.
+type PriceListService interface {
+	Create(ctx context.Context, tenantID uint64, req CreatePriceListRequest) (*PriceList, error)
+	Update(ctx context.Context, tenantID, listID uint64, req UpdatePriceListRequest) (*PriceList, error)
+	Delete(ctx context.Context, tenantID, listID uint64) error
+	// GetByID returns the list with its items
+	GetByID(ctx context.Context, tenantID, listID uint64) (*PriceList, error)
+	GetAll(ctx context.Context, tenantID uint64, query PriceListQuery) ([]*PriceList, int64, error)
+	SetItems(ctx context.Context, tenantID, listID uint64, req SetPriceListItemsRequest) (*PriceList, error)
+	DeleteItem(ctx context.Context, tenantID, listID, itemID uint64) error
+
+	// ResolvePrices returns the effective price of every item, in order
+	ResolvePrices(ctx context.Context, tenantID uint64, req ResolvePricesRequest) ([]*ResolvedPrice, error)
+}
+
+// ModifierService manages modifier groups and the products and categories
+// that offer them. Prices of picked modifiers are resolved along with the
+// product through PriceListService.
+type ModifierService interface {
+	Create(ctx context.Context, tenantID uint64, req CreateModifierGroupRequest) (*ModifierGroup, error)
+	Update(ctx context.Context, tenantID, groupID uint64, req UpdateModifierGroupRequest) (*ModifierGroup, error)
+	Delete(ctx context.Context, tenantID, groupID uint64) error
+	GetByID(ctx context.Context, tenantID, groupID uint64) (*ModifierGroup, error)
+	GetAll(ctx context.Context, tenantID uint64, query ModifierGroupQuery) ([]*ModifierGroup, int64, error)
+
+	// GetProductGroups returns the groups the product offers, its
+	// category's included
+	GetProductGroups(ctx context.Context, tenantID, productID uint64) ([]*ModifierGroup, error)
+	SetProductGroups(ctx context.Context, tenantID, productID uint64, req SetModifierGroupsRequest) ([]*ModifierGroup, error)
+	GetCategoryGroups(ctx context.Context, tenantID, categoryID uint64) ([]*ModifierGroup, error)
+	SetCategoryGroups(ctx context.Context, tenantID, categoryID uint64, req SetModifierGroupsRequest) ([]*ModifierGroup, error)
+
+	GetSales(ctx context.Context, tenantID uint64, query ModifierSalesQuery) ([]*ModifierSales, error)
+}
+
+// UnitService manages the tenant's units of measure and the units each
+// product converts to its base unit
+type UnitService interface {
+	Create(ctx context.Context, tenantID uint64, req CreateUnitRequest) (*Unit, error)
+	Update(ctx context.Context, tenantID, unitID uint64, req UpdateUnitRequest) (*Unit, error)
+	Delete(ctx context.Context, tenantID, unitID uint64) error
+	GetByID(ctx context.Context, tenantID, unitID uint64) (*Unit, error)
+	GetAll(ctx context.Context, tenantID uint64) ([]*Unit, error)
+
+	// GetProductUnits returns the product's base unit, then the units it
+	// converts to it
+	GetProductUnits(ctx context.Context, tenantID, productID uint64) ([]*ProductUnit, error)
+	SetProductUnits(ctx context.Context, tenantID, productID uint64, req SetProductUnitsRequest) ([]*ProductUnit, error)
+}
+
+// ImageService uploads product and category images to file storage, along
+// with their thumbnails
+type ImageService interface {
+	UploadProductImage(ctx context.Context, tenantID, userID, productID uint64, r io.Reader) (*Image, error)
+	GetProductImages(ctx context.Context, tenantID, productID uint64) ([]*Image, error)
+	DeleteProductImage(ctx context.Context, tenantID, productID, imageID uint64) error
+	// UploadCategoryImage replaces the category's image
+	UploadCategoryImage(ctx context.Context, tenantID, userID, categoryID uint64, r io.Reader) (*Image, error)
+	DeleteCategoryImage(ctx context.Context, tenantID, categoryID uint64) error
+}
+
+// LabelService renders printable shelf labels and price tags
+type LabelService interface {
+	// RenderLabels returns a PDF of the requested labels
+	RenderLabels(ctx context.Context, tenantID uint64, req PrintLabelsRequest) ([]byte, error)
 }
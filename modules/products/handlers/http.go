@@ -1,23 +1,39 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/exven/pos-system/modules/products/domain"
 	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/exven/pos-system/shared/utils/spreadsheet"
 	"github.com/labstack/echo/v4"
 )
 
 type ProductHandler struct {
-	categoryService domain.ProductCategoryService
-	productService  domain.ProductService
+	categoryService  domain.ProductCategoryService
+	productService   domain.ProductService
+	priceListService domain.PriceListService
+	imageService     domain.ImageService
+	labelService     domain.LabelService
+	modifierService  domain.ModifierService
+	unitService      domain.UnitService
 }
 
-func NewProductHandler(categoryService domain.ProductCategoryService, productService domain.ProductService) *ProductHandler {
+func NewProductHandler(categoryService domain.ProductCategoryService, productService domain.ProductService, priceListService domain.PriceListService, imageService domain.ImageService, labelService domain.LabelService, modifierService domain.ModifierService, unitService domain.UnitService) *ProductHandler {
 	return &ProductHandler{
-		categoryService: categoryService,
-		productService:  productService,
+		categoryService:  categoryService,
+		productService:   productService,
+		priceListService: priceListService,
+		imageService:     imageService,
+		labelService:     labelService,
+		modifierService:  modifierService,
+		unitService:      unitService,
 	}
 }
 
@@ -27,11 +43,34 @@ func (h *ProductHandler) RegisterRoutes(e *echo.Group) {
 	// Product routes
 	products.POST("", h.CreateProduct)
 	products.GET("", h.GetProducts)
+	products.GET("/export", h.ExportProducts)
+	products.POST("/bulk-price-update", h.BulkUpdatePrices)
+	products.POST("/barcodes/assign", h.AssignBarcodes)
+	products.POST("/labels", h.PrintLabels)
 	products.GET("/:id", h.GetProduct)
 	products.PUT("/:id", h.UpdateProduct)
 	products.DELETE("/:id", h.DeleteProduct)
 	products.GET("/sku/:sku", h.GetProductBySKU)
 	products.GET("/barcode/:barcode", h.GetProductByBarcode)
+	products.GET("/:id/price-history", h.GetPriceHistory)
+	products.GET("/:id/variants", h.GetVariants)
+	products.POST("/:id/variants/generate", h.GenerateVariants)
+	products.PUT("/:id/variants/:variant_id", h.UpdateVariant)
+	products.DELETE("/:id/variants/:variant_id", h.DeleteVariant)
+	products.GET("/:id/recipe", h.GetRecipe)
+	products.PUT("/:id/recipe", h.SetRecipe)
+	products.DELETE("/:id/recipe", h.DeleteRecipe)
+	products.GET("/:id/bundle", h.GetBundle)
+	products.PUT("/:id/bundle", h.SetBundle)
+	products.DELETE("/:id/bundle", h.DeleteBundle)
+	products.GET("/:id/bundle/sales", h.GetBundleSales)
+	products.GET("/:id/modifier-groups", h.GetProductModifierGroups)
+	products.PUT("/:id/modifier-groups", h.SetProductModifierGroups)
+	products.GET("/:id/units", h.GetProductUnits)
+	products.PUT("/:id/units", h.SetProductUnits)
+	products.POST("/:id/images", h.UploadProductImage)
+	products.GET("/:id/images", h.GetProductImages)
+	products.DELETE("/:id/images/:image_id", h.DeleteProductImage)
 
 	// Product Categories routes
 	categories := products.Group("/categories")
@@ -42,6 +81,38 @@ func (h *ProductHandler) RegisterRoutes(e *echo.Group) {
 	categories.DELETE("/:id", h.DeleteCategory)
 	categories.GET("/hierarchy", h.GetCategoryHierarchy)
 	categories.GET("/:id/products", h.GetProductsByCategory)
+	categories.PUT("/:id/image", h.UploadCategoryImage)
+	categories.DELETE("/:id/image", h.DeleteCategoryImage)
+	categories.GET("/:id/modifier-groups", h.GetCategoryModifierGroups)
+	categories.PUT("/:id/modifier-groups", h.SetCategoryModifierGroups)
+
+	// Price List routes
+	priceLists := e.Group("/price-lists")
+	priceLists.POST("", h.CreatePriceList)
+	priceLists.GET("", h.GetPriceLists)
+	priceLists.POST("/resolve", h.ResolvePrices)
+	priceLists.GET("/:id", h.GetPriceList)
+	priceLists.PUT("/:id", h.UpdatePriceList)
+	priceLists.DELETE("/:id", h.DeletePriceList)
+	priceLists.PUT("/:id/items", h.SetPriceListItems)
+	priceLists.DELETE("/:id/items/:item_id", h.DeletePriceListItem)
+
+	// Modifier Group routes
+	modifierGroups := e.Group("/modifier-groups")
+	modifierGroups.POST("", h.CreateModifierGroup)
+	modifierGroups.GET("", h.GetModifierGroups)
+	modifierGroups.GET("/sales", h.GetModifierSales)
+	modifierGroups.GET("/:id", h.GetModifierGroup)
+	modifierGroups.PUT("/:id", h.UpdateModifierGroup)
+	modifierGroups.DELETE("/:id", h.DeleteModifierGroup)
+
+	// Unit routes
+	units := e.Group("/units")
+	units.POST("", h.CreateUnit)
+	units.GET("", h.GetUnits)
+	units.GET("/:id", h.GetUnit)
+	units.PUT("/:id", h.UpdateUnit)
+	units.DELETE("/:id", h.DeleteUnit)
 }
 
 // Product handlers
@@ -59,8 +130,9 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 	}
 
 	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
 
-	product, err := h.productService.Create(c.Request().Context(), tenantID, req)
+	product, err := h.productService.Create(c.Request().Context(), tenantID, userID, req)
 	if err != nil {
 		return response.BadRequest(c, err.Error())
 	}
@@ -72,56 +144,1433 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 func (h *ProductHandler) GetProducts(c echo.Context) error {
 	tenantID := c.Get("tenant_id").(uint64)
 
+	// Parse query parameters
+	query := domain.ProductQuery{
+		Page:    1,
+		Limit:   50,
+		Search:  c.QueryParam("search"),
+		SKU:     c.QueryParam("sku"),
+		Barcode: c.QueryParam("barcode"),
+		Name:    c.QueryParam("name"),
+		Sort:    c.QueryParam("sort"),
+		Order:   c.QueryParam("order"),
+	}
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			query.Page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			query.Limit = limitInt
+		}
+	}
+
+	fieldErrors := make(map[string][]string)
+
+	if value := c.QueryParam("category_id"); value != "" {
+		if categoryID, err := strconv.ParseUint(value, 10, 64); err == nil {
+			query.CategoryID = &categoryID
+		} else {
+			fieldErrors["category_id"] = []string{"Must be a valid ID"}
+		}
+	}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		if outletID, err := strconv.ParseUint(value, 10, 64); err == nil {
+			query.OutletID = &outletID
+		} else {
+			fieldErrors["outlet_id"] = []string{"Must be a valid ID"}
+		}
+	}
+
+	if value := c.QueryParam("is_active"); value != "" {
+		if active, err := strconv.ParseBool(value); err == nil {
+			query.IsActive = &active
+		} else {
+			fieldErrors["is_active"] = []string{"Must be true or false"}
+		}
+	}
+
+	if value := c.QueryParam("track_stock"); value != "" {
+		if trackStock, err := strconv.ParseBool(value); err == nil {
+			query.TrackStock = &trackStock
+		} else {
+			fieldErrors["track_stock"] = []string{"Must be true or false"}
+		}
+	}
+
+	if value := c.QueryParam("in_stock"); value != "" {
+		if inStock, err := strconv.ParseBool(value); err == nil {
+			query.InStock = inStock
+		} else {
+			fieldErrors["in_stock"] = []string{"Must be true or false"}
+		}
+	}
+
+	if value := c.QueryParam("min_price"); value != "" {
+		if price, err := strconv.ParseFloat(value, 64); err == nil && price >= 0 {
+			query.MinPrice = &price
+		} else {
+			fieldErrors["min_price"] = []string{"Must be a number of at least 0"}
+		}
+	}
+
+	if value := c.QueryParam("max_price"); value != "" {
+		if price, err := strconv.ParseFloat(value, 64); err == nil && price >= 0 {
+			query.MaxPrice = &price
+		} else {
+			fieldErrors["max_price"] = []string{"Must be a number of at least 0"}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	products, total, err := h.productService.GetAll(c.Request().Context(), tenantID, query)
+	if err != nil {
+		switch err.Error() {
+		case "outlet is required to filter by stock":
+			return response.ValidationError(c, map[string][]string{
+				"outlet_id": {"Required when in_stock is set"},
+			})
+		case "min price must not be greater than max price":
+			return response.ValidationError(c, map[string][]string{
+				"min_price": {"Must not be greater than max_price"},
+			})
+		}
+		return response.InternalError(c, "Failed to get products")
+	}
+
+	productResponses := make([]domain.ProductResponse, len(products))
+	for i, product := range products {
+		productResponses[i] = h.productToResponse(product)
+	}
+
+	return response.SuccessWithPagination(c, "Products retrieved successfully", productResponses, query.Page, query.Limit, int(total))
+}
+
+func (h *ProductHandler) GetProduct(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.NotFound(c, "Product not found")
+	}
+
+	productResponse := h.productToResponse(product)
+	if err := h.embedStocks(c, product, &productResponse); err != nil {
+		return response.InternalError(c, "Failed to get product stock")
+	}
+	if err := h.embedPrice(c, product, &productResponse); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Product retrieved successfully", productResponse)
+}
+
+func (h *ProductHandler) UpdateProduct(c echo.Context) error {
+	var req domain.UpdateProductRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	product, err := h.productService.Update(c.Request().Context(), tenantID, userID, productID, req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	productResponse := h.productToResponse(product)
+	return response.Success(c, "Product updated successfully", productResponse)
+}
+
+func (h *ProductHandler) DeleteProduct(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	err = h.productService.Delete(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Product deleted successfully", nil)
+}
+
+func (h *ProductHandler) GetProductBySKU(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	sku := c.Param("sku")
+
+	product, err := h.productService.GetBySKU(c.Request().Context(), tenantID, sku)
+	if err != nil {
+		return response.NotFound(c, "Product not found")
+	}
+
+	productResponse := h.productToResponse(product)
+	if err := h.embedStocks(c, product, &productResponse); err != nil {
+		return response.InternalError(c, "Failed to get product stock")
+	}
+	if err := h.embedPrice(c, product, &productResponse); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Product retrieved successfully", productResponse)
+}
+
+// ExportProducts streams the catalogue as a CSV or XLSX download
+func (h *ProductHandler) ExportProducts(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = spreadsheet.FormatCSV
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return response.ValidationError(c, map[string][]string{
+			"format": {"Must be csv or xlsx"},
+		})
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102"), format)
+	c.Response().Header().Set(echo.HeaderContentType, spreadsheet.ContentType(format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.productService.Export(c.Request().Context(), tenantID, format, c.Response()); err != nil {
+		// Once rows are sent the status is out, so the download is cut short
+		if c.Response().Committed {
+			log.Printf("failed to export products for tenant %d: %v", tenantID, err)
+			return nil
+		}
+		c.Response().Header().Del(echo.HeaderContentType)
+		c.Response().Header().Del(echo.HeaderContentDisposition)
+		return response.InternalError(c, "Failed to export products")
+	}
+
+	return nil
+}
+
+func (h *ProductHandler) GetProductByBarcode(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	barcode := c.Param("barcode")
+
+	product, err := h.productService.GetByBarcode(c.Request().Context(), tenantID, barcode)
+	if err != nil {
+		return response.NotFound(c, "Product not found")
+	}
+
+	productResponse := h.productToResponse(product)
+	if err := h.embedStocks(c, product, &productResponse); err != nil {
+		return response.InternalError(c, "Failed to get product stock")
+	}
+	if err := h.embedPrice(c, product, &productResponse); err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Product retrieved successfully", productResponse)
+}
+
+func (h *ProductHandler) GetProductsByCategory(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID")
+	}
+
 	// Parse pagination parameters
 	page := 1
 	limit := 50
 
-	if p := c.QueryParam("page"); p != "" {
-		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
-			page = pageInt
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	offset := (page - 1) * limit
+
+	products, total, err := h.productService.GetByCategory(c.Request().Context(), tenantID, categoryID, limit, offset)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	productResponses := make([]domain.ProductResponse, len(products))
+	for i, product := range products {
+		productResponses[i] = h.productToResponse(product)
+	}
+
+	return response.SuccessWithPagination(c, "Products retrieved successfully", productResponses, page, limit, int(total))
+}
+
+// Variant handlers
+
+func (h *ProductHandler) GetVariants(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.NotFound(c, "Product not found")
+	}
+
+	variants, err := h.productService.GetVariants(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.InternalError(c, "Failed to get variants")
+	}
+
+	// Per-outlet stock is included with ?include_stock=true, as for products
+	include, _ := strconv.ParseBool(c.QueryParam("include_stock"))
+	if include && product.TrackStock {
+		if err := h.productService.GetVariantStocks(c.Request().Context(), tenantID, variants); err != nil {
+			return response.InternalError(c, "Failed to get variant stock")
+		}
+	}
+
+	variantResponses := make([]domain.ProductVariantResponse, len(variants))
+	for i, variant := range variants {
+		variantResponses[i] = h.variantToResponse(variant, product)
+	}
+
+	return response.Success(c, "Variants retrieved successfully", variantResponses)
+}
+
+func (h *ProductHandler) GenerateVariants(c echo.Context) error {
+	var req domain.GenerateVariantsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variants, err := h.productService.GenerateVariants(c.Request().Context(), tenantID, productID, req)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.InternalError(c, "Failed to get product")
+	}
+
+	variantResponses := make([]domain.ProductVariantResponse, len(variants))
+	for i, variant := range variants {
+		variantResponses[i] = h.variantToResponse(variant, product)
+	}
+
+	return response.Success(c, "Variants generated successfully", variantResponses)
+}
+
+func (h *ProductHandler) UpdateVariant(c echo.Context) error {
+	var req domain.UpdateVariantRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		return response.NotFound(c, "Product not found")
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Request().Context(), tenantID, productID, variantID, req)
+	if err != nil {
+		if err.Error() == "variant not found" {
+			return response.NotFound(c, "Variant not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Variant updated successfully", h.variantToResponse(variant, product))
+}
+
+func (h *ProductHandler) DeleteVariant(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	variantID, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid variant ID")
+	}
+
+	err = h.productService.DeleteVariant(c.Request().Context(), tenantID, productID, variantID)
+	if err != nil {
+		if err.Error() == "variant not found" {
+			return response.NotFound(c, "Variant not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Variant deleted successfully", nil)
+}
+
+// Price history handlers
+
+func (h *ProductHandler) GetPriceHistory(c echo.Context) error {
+	var query domain.PriceChangeQuery
+	if err := c.Bind(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	changes, total, err := h.productService.GetPriceHistory(c.Request().Context(), tenantID, productID, query)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get price history")
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	changeResponses := make([]domain.PriceChangeResponse, len(changes))
+	for i, change := range changes {
+		changeResponses[i] = h.priceChangeToResponse(change)
+	}
+
+	return response.SuccessWithPagination(c, "Price history retrieved successfully", changeResponses, query.Page, query.Limit, int(total))
+}
+
+// BulkUpdatePrices changes the price of a category's products at once
+func (h *ProductHandler) BulkUpdatePrices(c echo.Context) error {
+	var req domain.BulkPriceUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	changes, err := h.productService.BulkUpdatePrices(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		if err.Error() == "category not found" {
+			return response.NotFound(c, "Category not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	result := domain.BulkPriceUpdateResponse{
+		DryRun:       req.DryRun,
+		UpdatedCount: len(changes),
+		Changes:      make([]domain.PriceChangeResponse, len(changes)),
+	}
+	for i, change := range changes {
+		result.Changes[i] = h.priceChangeToResponse(change)
+	}
+
+	message := "Prices updated successfully"
+	if req.DryRun {
+		message = "Price update previewed successfully"
+	}
+
+	return response.Success(c, message, result)
+}
+
+// Barcode and label handlers
+
+// AssignBarcodes gives products without a barcode one from the tenant's
+// barcode scheme
+func (h *ProductHandler) AssignBarcodes(c echo.Context) error {
+	var req domain.AssignBarcodesRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	products, err := h.productService.AssignBarcodes(c.Request().Context(), tenantID, req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	result := domain.AssignBarcodesResponse{
+		AssignedCount: len(products),
+		Products:      make([]domain.AssignedBarcodeResponse, len(products)),
+	}
+	for i, product := range products {
+		result.Products[i] = domain.AssignedBarcodeResponse{
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Barcode:   product.Barcode,
+		}
+	}
+
+	return response.Success(c, "Barcodes assigned successfully", result)
+}
+
+// PrintLabels returns a PDF sheet of shelf labels or price tags
+func (h *ProductHandler) PrintLabels(c echo.Context) error {
+	var req domain.PrintLabelsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	document, err := h.labelService.RenderLabels(c.Request().Context(), tenantID, req)
+	if err != nil {
+		switch err.Error() {
+		case "product not found":
+			return response.NotFound(c, "Product not found")
+		case "variant not found":
+			return response.NotFound(c, "Variant not found")
+		case "outlet not found":
+			return response.NotFound(c, "Outlet not found")
+		case "too many labels":
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalError(c, "Failed to render labels")
+	}
+
+	filename := fmt.Sprintf("labels-%s.pdf", time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/pdf", document)
+}
+
+// Image handlers
+
+func (h *ProductHandler) UploadProductImage(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	file, err := openImageUpload(c)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	image, err := h.imageService.UploadProductImage(c.Request().Context(), tenantID, userID, productID, file)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return imageUploadError(c, err)
+	}
+
+	return response.Created(c, "Image uploaded successfully", h.imageToResponse(image))
+}
+
+func (h *ProductHandler) GetProductImages(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	images, err := h.imageService.GetProductImages(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get images")
+	}
+
+	imageResponses := make([]domain.ImageResponse, len(images))
+	for i, image := range images {
+		imageResponses[i] = h.imageToResponse(image)
+	}
+
+	return response.Success(c, "Images retrieved successfully", imageResponses)
+}
+
+func (h *ProductHandler) DeleteProductImage(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	imageID, err := strconv.ParseUint(c.Param("image_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid image ID")
+	}
+
+	err = h.imageService.DeleteProductImage(c.Request().Context(), tenantID, productID, imageID)
+	if err != nil {
+		if err.Error() == "image not found" {
+			return response.NotFound(c, "Image not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Image deleted successfully", nil)
+}
+
+// UploadCategoryImage replaces the category's image with an uploaded one
+func (h *ProductHandler) UploadCategoryImage(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID")
+	}
+
+	file, err := openImageUpload(c)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	image, err := h.imageService.UploadCategoryImage(c.Request().Context(), tenantID, userID, categoryID, file)
+	if err != nil {
+		if err.Error() == "product category not found" {
+			return response.NotFound(c, "Category not found")
+		}
+		return imageUploadError(c, err)
+	}
+
+	return response.Success(c, "Image uploaded successfully", h.imageToResponse(image))
+}
+
+func (h *ProductHandler) DeleteCategoryImage(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID")
+	}
+
+	err = h.imageService.DeleteCategoryImage(c.Request().Context(), tenantID, categoryID)
+	if err != nil {
+		switch err.Error() {
+		case "product category not found":
+			return response.NotFound(c, "Category not found")
+		case "image not found":
+			return response.NotFound(c, "Image not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Image deleted successfully", nil)
+}
+
+// openImageUpload opens the "image" file of a multipart upload. On failure
+// the error response has already been written and is returned.
+func openImageUpload(c echo.Context) (multipart.File, error) {
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		return nil, response.ValidationError(c, map[string][]string{
+			"image": {"Image is required"},
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, response.BadRequest(c, "Failed to read uploaded file")
+	}
+
+	return file, nil
+}
+
+func imageUploadError(c echo.Context, err error) error {
+	switch err.Error() {
+	case "image is too large":
+		return response.Error(c, http.StatusRequestEntityTooLarge, "Image is too large", nil)
+	case "unsupported image type":
+		return response.ValidationError(c, map[string][]string{
+			"image": {"Image must be a JPEG, PNG or GIF"},
+		})
+	case "invalid image", "image dimensions are too large", "product image limit reached":
+		return response.BadRequest(c, err.Error())
+	}
+	return response.InternalError(c, "Failed to upload image")
+}
+
+// Recipe handlers
+
+func (h *ProductHandler) GetRecipe(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	recipe, err := h.productService.GetRecipe(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get recipe")
+	}
+
+	return response.Success(c, "Recipe retrieved successfully", h.recipeToResponse(recipe))
+}
+
+func (h *ProductHandler) SetRecipe(c echo.Context) error {
+	var req domain.SetRecipeRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	recipe, err := h.productService.SetRecipe(c.Request().Context(), tenantID, productID, req)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Recipe saved successfully", h.recipeToResponse(recipe))
+}
+
+func (h *ProductHandler) DeleteRecipe(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	err = h.productService.DeleteRecipe(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Recipe deleted successfully", nil)
+}
+
+// Bundle handlers
+
+func (h *ProductHandler) GetBundle(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	bundle, err := h.productService.GetBundle(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get bundle")
+	}
+
+	return response.Success(c, "Bundle retrieved successfully", h.bundleToResponse(bundle))
+}
+
+func (h *ProductHandler) SetBundle(c echo.Context) error {
+	var req domain.SetBundleRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	bundle, err := h.productService.SetBundle(c.Request().Context(), tenantID, productID, req)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Bundle saved successfully", h.bundleToResponse(bundle))
+}
+
+func (h *ProductHandler) DeleteBundle(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	err = h.productService.DeleteBundle(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Bundle deleted successfully", nil)
+}
+
+// GetBundleSales reports the components sold through a bundle with their
+// share of its price, for the optional ?date_from= and ?date_to=
+func (h *ProductHandler) GetBundleSales(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	fieldErrors := make(map[string][]string)
+	query := domain.BundleSalesQuery{
+		DateFrom: dateParam(c, "date_from", fieldErrors),
+		DateTo:   dateParam(c, "date_to", fieldErrors),
+	}
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	sales, err := h.productService.GetBundleSales(c.Request().Context(), tenantID, productID, query)
+	if err != nil {
+		switch err.Error() {
+		case "product not found":
+			return response.NotFound(c, "Product not found")
+		case "date from must not be after date to":
+			return response.ValidationError(c, map[string][]string{
+				"date_from": {"Must not be after date_to"},
+			})
+		}
+		return response.InternalError(c, "Failed to get bundle sales")
+	}
+
+	salesResponses := make([]domain.BundleComponentSalesResponse, len(sales))
+	for i, line := range sales {
+		salesResponses[i] = domain.BundleComponentSalesResponse{
+			ComponentID:     line.ComponentID,
+			VariantID:       line.VariantID,
+			SKU:             line.SKU,
+			Name:            line.Name,
+			VariantName:     line.VariantName,
+			Quantity:        line.Quantity,
+			AllocatedAmount: line.AllocatedAmount,
+			CostAmount:      line.CostAmount,
+		}
+	}
+
+	return response.Success(c, "Bundle sales retrieved successfully", salesResponses)
+}
+
+// Price List handlers
+
+func (h *ProductHandler) CreatePriceList(c echo.Context) error {
+	var req domain.CreatePriceListRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	list, err := h.priceListService.Create(c.Request().Context(), tenantID, req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "Price list created successfully", h.priceListToResponse(list))
+}
+
+func (h *ProductHandler) GetPriceLists(c echo.Context) error {
+	var query domain.PriceListQuery
+	if err := c.Bind(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	lists, total, err := h.priceListService.GetAll(c.Request().Context(), tenantID, query)
+	if err != nil {
+		return response.InternalError(c, "Failed to get price lists")
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	listResponses := make([]domain.PriceListResponse, len(lists))
+	for i, list := range lists {
+		listResponses[i] = h.priceListToResponse(list)
+	}
+
+	return response.SuccessWithPagination(c, "Price lists retrieved successfully", listResponses, query.Page, query.Limit, int(total))
+}
+
+func (h *ProductHandler) GetPriceList(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list ID")
+	}
+
+	list, err := h.priceListService.GetByID(c.Request().Context(), tenantID, listID)
+	if err != nil {
+		if err.Error() == "price list not found" {
+			return response.NotFound(c, "Price list not found")
+		}
+		return response.InternalError(c, "Failed to get price list")
+	}
+
+	return response.Success(c, "Price list retrieved successfully", h.priceListToResponse(list))
+}
+
+func (h *ProductHandler) UpdatePriceList(c echo.Context) error {
+	var req domain.UpdatePriceListRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list ID")
+	}
+
+	list, err := h.priceListService.Update(c.Request().Context(), tenantID, listID, req)
+	if err != nil {
+		if err.Error() == "price list not found" {
+			return response.NotFound(c, "Price list not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Price list updated successfully", h.priceListToResponse(list))
+}
+
+func (h *ProductHandler) DeletePriceList(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list ID")
+	}
+
+	err = h.priceListService.Delete(c.Request().Context(), tenantID, listID)
+	if err != nil {
+		if err.Error() == "price list not found" {
+			return response.NotFound(c, "Price list not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Price list deleted successfully", nil)
+}
+
+func (h *ProductHandler) SetPriceListItems(c echo.Context) error {
+	var req domain.SetPriceListItemsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list ID")
+	}
+
+	list, err := h.priceListService.SetItems(c.Request().Context(), tenantID, listID, req)
+	if err != nil {
+		if err.Error() == "price list not found" {
+			return response.NotFound(c, "Price list not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Price list items saved successfully", h.priceListToResponse(list))
+}
+
+func (h *ProductHandler) DeletePriceListItem(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	listID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list ID")
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid price list item ID")
+	}
+
+	err = h.priceListService.DeleteItem(c.Request().Context(), tenantID, listID, itemID)
+	if err != nil {
+		switch err.Error() {
+		case "price list not found":
+			return response.NotFound(c, "Price list not found")
+		case "price list item not found":
+			return response.NotFound(c, "Price list item not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Price list item deleted successfully", nil)
+}
+
+// Modifier Group handlers
+
+func (h *ProductHandler) CreateModifierGroup(c echo.Context) error {
+	var req domain.CreateModifierGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	group, err := h.modifierService.Create(c.Request().Context(), tenantID, req)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Created(c, "Modifier group created successfully", h.modifierGroupToResponse(group))
+}
+
+func (h *ProductHandler) GetModifierGroups(c echo.Context) error {
+	var query domain.ModifierGroupQuery
+	if err := c.Bind(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groups, total, err := h.modifierService.GetAll(c.Request().Context(), tenantID, query)
+	if err != nil {
+		return response.InternalError(c, "Failed to get modifier groups")
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	return response.SuccessWithPagination(c, "Modifier groups retrieved successfully", h.modifierGroupsToResponse(groups), query.Page, query.Limit, int(total))
+}
+
+func (h *ProductHandler) GetModifierGroup(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid modifier group ID")
+	}
+
+	group, err := h.modifierService.GetByID(c.Request().Context(), tenantID, groupID)
+	if err != nil {
+		if err.Error() == "modifier group not found" {
+			return response.NotFound(c, "Modifier group not found")
+		}
+		return response.InternalError(c, "Failed to get modifier group")
+	}
+
+	return response.Success(c, "Modifier group retrieved successfully", h.modifierGroupToResponse(group))
+}
+
+func (h *ProductHandler) UpdateModifierGroup(c echo.Context) error {
+	var req domain.UpdateModifierGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid modifier group ID")
+	}
+
+	group, err := h.modifierService.Update(c.Request().Context(), tenantID, groupID, req)
+	if err != nil {
+		if err.Error() == "modifier group not found" {
+			return response.NotFound(c, "Modifier group not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Modifier group updated successfully", h.modifierGroupToResponse(group))
+}
+
+func (h *ProductHandler) DeleteModifierGroup(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid modifier group ID")
+	}
+
+	err = h.modifierService.Delete(c.Request().Context(), tenantID, groupID)
+	if err != nil {
+		if err.Error() == "modifier group not found" {
+			return response.NotFound(c, "Modifier group not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Modifier group deleted successfully", nil)
+}
+
+// GetModifierSales reports the modifiers sold, for the optional ?date_from=
+// and ?date_to=
+func (h *ProductHandler) GetModifierSales(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	fieldErrors := make(map[string][]string)
+	query := domain.ModifierSalesQuery{
+		DateFrom: dateParam(c, "date_from", fieldErrors),
+		DateTo:   dateParam(c, "date_to", fieldErrors),
+	}
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	sales, err := h.modifierService.GetSales(c.Request().Context(), tenantID, query)
+	if err != nil {
+		if err.Error() == "date from must not be after date to" {
+			return response.ValidationError(c, map[string][]string{
+				"date_from": {"Must not be after date_to"},
+			})
+		}
+		return response.InternalError(c, "Failed to get modifier sales")
+	}
+
+	salesResponses := make([]domain.ModifierSalesResponse, len(sales))
+	for i, line := range sales {
+		salesResponses[i] = domain.ModifierSalesResponse{
+			ModifierID: line.ModifierID,
+			GroupName:  line.GroupName,
+			Name:       line.Name,
+			Quantity:   line.Quantity,
+			Amount:     line.Amount,
+			CostAmount: line.CostAmount,
+		}
+	}
+
+	return response.Success(c, "Modifier sales retrieved successfully", salesResponses)
+}
+
+func (h *ProductHandler) GetProductModifierGroups(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	groups, err := h.modifierService.GetProductGroups(c.Request().Context(), tenantID, productID)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get product modifier groups")
+	}
+
+	return response.Success(c, "Product modifier groups retrieved successfully", h.modifierGroupsToResponse(groups))
+}
+
+func (h *ProductHandler) SetProductModifierGroups(c echo.Context) error {
+	var req domain.SetModifierGroupsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid product ID")
+	}
+
+	groups, err := h.modifierService.SetProductGroups(c.Request().Context(), tenantID, productID, req)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Product modifier groups saved successfully", h.modifierGroupsToResponse(groups))
+}
+
+func (h *ProductHandler) GetCategoryModifierGroups(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID")
+	}
+
+	groups, err := h.modifierService.GetCategoryGroups(c.Request().Context(), tenantID, categoryID)
+	if err != nil {
+		if err.Error() == "product category not found" {
+			return response.NotFound(c, "Category not found")
 		}
+		return response.InternalError(c, "Failed to get category modifier groups")
 	}
 
-	if l := c.QueryParam("limit"); l != "" {
-		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
-			limit = limitInt
+	return response.Success(c, "Category modifier groups retrieved successfully", h.modifierGroupsToResponse(groups))
+}
+
+func (h *ProductHandler) SetCategoryModifierGroups(c echo.Context) error {
+	var req domain.SetModifierGroupsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID")
+	}
+
+	groups, err := h.modifierService.SetCategoryGroups(c.Request().Context(), tenantID, categoryID, req)
+	if err != nil {
+		if err.Error() == "product category not found" {
+			return response.NotFound(c, "Category not found")
 		}
+		return response.BadRequest(c, err.Error())
 	}
 
-	offset := (page - 1) * limit
+	return response.Success(c, "Category modifier groups saved successfully", h.modifierGroupsToResponse(groups))
+}
+
+// Unit handlers
 
-	products, total, err := h.productService.GetAll(c.Request().Context(), tenantID, limit, offset)
+func (h *ProductHandler) CreateUnit(c echo.Context) error {
+	var req domain.CreateUnitRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	unit, err := h.unitService.Create(c.Request().Context(), tenantID, req)
 	if err != nil {
-		return response.InternalError(c, "Failed to get products")
+		return response.BadRequest(c, err.Error())
 	}
 
-	productResponses := make([]domain.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = h.productToResponse(product)
+	return response.Created(c, "Unit created successfully", h.unitToResponse(unit))
+}
+
+func (h *ProductHandler) GetUnits(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	units, err := h.unitService.GetAll(c.Request().Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, "Failed to get units")
 	}
 
-	return response.SuccessWithPagination(c, "Products retrieved successfully", productResponses, page, limit, int(total))
+	unitResponses := make([]domain.UnitResponse, len(units))
+	for i, unit := range units {
+		unitResponses[i] = h.unitToResponse(unit)
+	}
+
+	return response.Success(c, "Units retrieved successfully", unitResponses)
 }
 
-func (h *ProductHandler) GetProduct(c echo.Context) error {
+func (h *ProductHandler) GetUnit(c echo.Context) error {
 	tenantID := c.Get("tenant_id").(uint64)
 
-	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return response.BadRequest(c, "Invalid product ID")
+		return response.BadRequest(c, "Invalid unit ID")
 	}
 
-	product, err := h.productService.GetByID(c.Request().Context(), tenantID, productID)
+	unit, err := h.unitService.GetByID(c.Request().Context(), tenantID, unitID)
 	if err != nil {
-		return response.NotFound(c, "Product not found")
+		if err.Error() == "unit not found" {
+			return response.NotFound(c, "Unit not found")
+		}
+		return response.InternalError(c, "Failed to get unit")
 	}
 
-	productResponse := h.productToResponse(product)
-	return response.Success(c, "Product retrieved successfully", productResponse)
+	return response.Success(c, "Unit retrieved successfully", h.unitToResponse(unit))
 }
 
-func (h *ProductHandler) UpdateProduct(c echo.Context) error {
-	var req domain.UpdateProductRequest
+func (h *ProductHandler) UpdateUnit(c echo.Context) error {
+	var req domain.UpdateUnitRequest
 	if err := c.Bind(&req); err != nil {
 		return response.BadRequest(c, "Invalid request format")
 	}
@@ -134,99 +1583,117 @@ func (h *ProductHandler) UpdateProduct(c echo.Context) error {
 
 	tenantID := c.Get("tenant_id").(uint64)
 
-	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return response.BadRequest(c, "Invalid product ID")
+		return response.BadRequest(c, "Invalid unit ID")
 	}
 
-	product, err := h.productService.Update(c.Request().Context(), tenantID, productID, req)
+	unit, err := h.unitService.Update(c.Request().Context(), tenantID, unitID, req)
 	if err != nil {
+		if err.Error() == "unit not found" {
+			return response.NotFound(c, "Unit not found")
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	productResponse := h.productToResponse(product)
-	return response.Success(c, "Product updated successfully", productResponse)
+	return response.Success(c, "Unit updated successfully", h.unitToResponse(unit))
 }
 
-func (h *ProductHandler) DeleteProduct(c echo.Context) error {
+func (h *ProductHandler) DeleteUnit(c echo.Context) error {
 	tenantID := c.Get("tenant_id").(uint64)
 
-	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	unitID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return response.BadRequest(c, "Invalid product ID")
+		return response.BadRequest(c, "Invalid unit ID")
 	}
 
-	err = h.productService.Delete(c.Request().Context(), tenantID, productID)
+	err = h.unitService.Delete(c.Request().Context(), tenantID, unitID)
 	if err != nil {
+		if err.Error() == "unit not found" {
+			return response.NotFound(c, "Unit not found")
+		}
 		return response.BadRequest(c, err.Error())
 	}
 
-	return response.Success(c, "Product deleted successfully", nil)
+	return response.Success(c, "Unit deleted successfully", nil)
 }
 
-func (h *ProductHandler) GetProductBySKU(c echo.Context) error {
+func (h *ProductHandler) GetProductUnits(c echo.Context) error {
 	tenantID := c.Get("tenant_id").(uint64)
-	sku := c.Param("sku")
 
-	product, err := h.productService.GetBySKU(c.Request().Context(), tenantID, sku)
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return response.NotFound(c, "Product not found")
+		return response.BadRequest(c, "Invalid product ID")
 	}
 
-	productResponse := h.productToResponse(product)
-	return response.Success(c, "Product retrieved successfully", productResponse)
-}
-
-func (h *ProductHandler) GetProductByBarcode(c echo.Context) error {
-	tenantID := c.Get("tenant_id").(uint64)
-	barcode := c.Param("barcode")
-
-	product, err := h.productService.GetByBarcode(c.Request().Context(), tenantID, barcode)
+	units, err := h.unitService.GetProductUnits(c.Request().Context(), tenantID, productID)
 	if err != nil {
-		return response.NotFound(c, "Product not found")
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
+		}
+		return response.InternalError(c, "Failed to get product units")
 	}
 
-	productResponse := h.productToResponse(product)
-	return response.Success(c, "Product retrieved successfully", productResponse)
+	return response.Success(c, "Product units retrieved successfully", h.productUnitsToResponse(units))
 }
 
-func (h *ProductHandler) GetProductsByCategory(c echo.Context) error {
+func (h *ProductHandler) SetProductUnits(c echo.Context) error {
+	var req domain.SetProductUnitsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
+
 	tenantID := c.Get("tenant_id").(uint64)
 
-	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return response.BadRequest(c, "Invalid category ID")
+		return response.BadRequest(c, "Invalid product ID")
 	}
 
-	// Parse pagination parameters
-	page := 1
-	limit := 50
-
-	if p := c.QueryParam("page"); p != "" {
-		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
-			page = pageInt
+	units, err := h.unitService.SetProductUnits(c.Request().Context(), tenantID, productID, req)
+	if err != nil {
+		if err.Error() == "product not found" {
+			return response.NotFound(c, "Product not found")
 		}
+		return response.BadRequest(c, err.Error())
 	}
 
-	if l := c.QueryParam("limit"); l != "" {
-		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
-			limit = limitInt
-		}
+	return response.Success(c, "Product units saved successfully", h.productUnitsToResponse(units))
+}
+
+// ResolvePrices returns the effective price of products for a sale, the way
+// checkout prices its lines
+func (h *ProductHandler) ResolvePrices(c echo.Context) error {
+	var req domain.ResolvePricesRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
 	}
 
-	offset := (page - 1) * limit
+	if err := c.Validate(req); err != nil {
+		return response.ValidationError(c, map[string][]string{
+			"request": {err.Error()},
+		})
+	}
 
-	products, total, err := h.productService.GetByCategory(c.Request().Context(), tenantID, categoryID, limit, offset)
+	tenantID := c.Get("tenant_id").(uint64)
+
+	prices, err := h.priceListService.ResolvePrices(c.Request().Context(), tenantID, req)
 	if err != nil {
 		return response.BadRequest(c, err.Error())
 	}
 
-	productResponses := make([]domain.ProductResponse, len(products))
-	for i, product := range products {
-		productResponses[i] = h.productToResponse(product)
+	priceResponses := make([]domain.ResolvedPriceResponse, len(prices))
+	for i, price := range prices {
+		priceResponses[i] = h.resolvedPriceToResponse(price)
 	}
 
-	return response.SuccessWithPagination(c, "Products retrieved successfully", productResponses, page, limit, int(total))
+	return response.Success(c, "Prices resolved successfully", priceResponses)
 }
 
 // Product Category handlers
@@ -424,6 +1891,77 @@ func (h *ProductHandler) categoryToResponseWithHierarchy(category *domain.Produc
 	return response
 }
 
+// embedStocks adds the per-outlet stock of tracked products to a product
+// detail response when requested with ?include_stock=true
+func (h *ProductHandler) embedStocks(c echo.Context, product *domain.Product, productResponse *domain.ProductResponse) error {
+	if !product.TrackStock {
+		return nil
+	}
+	if include, _ := strconv.ParseBool(c.QueryParam("include_stock")); !include {
+		return nil
+	}
+
+	// A barcode that resolved to a variant shows the variant's stock
+	if product.Variant != nil {
+		variants := []*domain.ProductVariant{product.Variant}
+		if err := h.productService.GetVariantStocks(c.Request().Context(), product.TenantID, variants); err != nil {
+			return err
+		}
+		productResponse.Stocks = h.stocksToResponse(product.Variant.Stocks)
+		return nil
+	}
+
+	stocks, err := h.productService.GetStocks(c.Request().Context(), product.TenantID, product.ID)
+	if err != nil {
+		return err
+	}
+
+	productResponse.Stocks = h.stocksToResponse(stocks)
+	return nil
+}
+
+func (h *ProductHandler) stocksToResponse(stocks []*domain.ProductStock) []domain.ProductStockResponse {
+	responses := make([]domain.ProductStockResponse, len(stocks))
+	for i, stock := range stocks {
+		stockResponse := domain.ProductStockResponse{
+			OutletID:          stock.OutletID,
+			OutletName:        stock.OutletName,
+			OutletCode:        stock.OutletCode,
+			Quantity:          stock.Quantity,
+			ReservedQuantity:  stock.ReservedQuantity,
+			AvailableQuantity: stock.AvailableQuantity(),
+		}
+		if stock.UpdatedAt != nil {
+			updatedAt := stock.UpdatedAt.Format(time.RFC3339)
+			stockResponse.UpdatedAt = &updatedAt
+		}
+		responses[i] = stockResponse
+	}
+	return responses
+}
+
+func (h *ProductHandler) variantToResponse(variant *domain.ProductVariant, product *domain.Product) domain.ProductVariantResponse {
+	response := domain.ProductVariantResponse{
+		ID:           variant.ID,
+		ProductID:    variant.ProductID,
+		SKU:          variant.SKU,
+		Barcode:      variant.Barcode,
+		Name:         variant.Name,
+		Options:      variant.Options,
+		SellingPrice: variant.SellingPrice,
+		Price:        variant.Price(product),
+		IsActive:     variant.IsActive,
+		CreatedAt:    variant.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    variant.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if variant.Stocks != nil {
+		response.Stocks = h.stocksToResponse(variant.Stocks)
+	}
+
+	return response
+}
+
 func (h *ProductHandler) productToResponse(product *domain.Product) domain.ProductResponse {
 	response := domain.ProductResponse{
 		ID:           product.ID,
@@ -438,6 +1976,7 @@ func (h *ProductHandler) productToResponse(product *domain.Product) domain.Produ
 		SellingPrice: product.SellingPrice,
 		MinStock:     product.MinStock,
 		TrackStock:   product.TrackStock,
+		TrackLots:    product.TrackLots,
 		IsActive:     product.IsActive,
 		Images:       product.Images,
 		Variants:     product.Variants,
@@ -450,5 +1989,306 @@ func (h *ProductHandler) productToResponse(product *domain.Product) domain.Produ
 		response.Category = &categoryResponse
 	}
 
+	if product.Variant != nil {
+		variantResponse := h.variantToResponse(product.Variant, product)
+		response.Variant = &variantResponse
+	}
+
+	return response
+}
+
+func (h *ProductHandler) priceChangeToResponse(change *domain.PriceChange) domain.PriceChangeResponse {
+	return domain.PriceChangeResponse{
+		ID:              change.ID,
+		ProductID:       change.ProductID,
+		ProductSKU:      change.ProductSKU,
+		ProductName:     change.ProductName,
+		OldCostPrice:    change.OldCostPrice,
+		NewCostPrice:    change.NewCostPrice,
+		OldSellingPrice: change.OldSellingPrice,
+		NewSellingPrice: change.NewSellingPrice,
+		Source:          change.Source,
+		Reason:          change.Reason,
+		ChangedBy:       change.ChangedBy,
+		ChangedByName:   change.ChangedByName,
+		EffectiveAt:     change.EffectiveAt.Format(time.RFC3339),
+	}
+}
+
+func (h *ProductHandler) imageToResponse(image *domain.Image) domain.ImageResponse {
+	return domain.ImageResponse{
+		ID:          image.ID,
+		URL:         image.URL,
+		ContentType: image.ContentType,
+		Size:        image.Size,
+		Width:       image.Width,
+		Height:      image.Height,
+		Thumbnails:  image.ThumbnailURLs,
+		CreatedAt:   image.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *ProductHandler) recipeToResponse(recipe *domain.Recipe) domain.RecipeResponse {
+	response := domain.RecipeResponse{
+		ProductID: recipe.ProductID,
+		Cost:      recipe.Cost(),
+		Items:     make([]domain.RecipeItemResponse, len(recipe.Items)),
+	}
+
+	for i, item := range recipe.Items {
+		response.Items[i] = domain.RecipeItemResponse{
+			ID:          item.ID,
+			ComponentID: item.ComponentID,
+			SKU:         item.Component.SKU,
+			Name:        item.Component.Name,
+			Unit:        item.Component.Unit,
+			CostPrice:   item.Component.CostPrice,
+			TrackStock:  item.Component.TrackStock,
+			Quantity:    item.Quantity,
+			Cost:        item.Cost(),
+		}
+	}
+
+	return response
+}
+
+// dateParam parses an optional YYYY-MM-DD query parameter, recording a field
+// error when it is malformed. A missing parameter gives nil.
+func dateParam(c echo.Context, param string, fieldErrors map[string][]string) *time.Time {
+	value := c.QueryParam(param)
+	if value == "" {
+		return nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		fieldErrors[param] = []string{"Must be a date in YYYY-MM-DD format"}
+		return nil
+	}
+	return &date
+}
+
+func (h *ProductHandler) bundleToResponse(bundle *domain.Bundle) domain.BundleResponse {
+	response := domain.BundleResponse{
+		ProductID: bundle.ProductID,
+		Price:     bundle.SellingPrice,
+		Items:     h.bundleItemsToResponse(bundle.Items),
+		Groups:    make([]domain.BundleGroupResponse, len(bundle.Groups)),
+	}
+
+	for i, group := range bundle.Groups {
+		response.Groups[i] = domain.BundleGroupResponse{
+			ID:        group.ID,
+			Name:      group.Name,
+			PickCount: group.PickCount,
+			Items:     h.bundleItemsToResponse(group.Items),
+		}
+	}
+
+	return response
+}
+
+func (h *ProductHandler) bundleItemsToResponse(items []*domain.BundleItem) []domain.BundleItemResponse {
+	responses := make([]domain.BundleItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = domain.BundleItemResponse{
+			ID:          item.ID,
+			ComponentID: item.ComponentID,
+			VariantID:   item.VariantID,
+			SKU:         item.Component.SKU,
+			Name:        item.Component.Name,
+			Unit:        item.Component.Unit,
+			TrackStock:  item.Component.TrackStock,
+			Quantity:    item.Quantity,
+			Price:       item.Price(),
+		}
+		if item.Variant != nil {
+			responses[i].SKU = item.Variant.SKU
+			responses[i].VariantName = item.Variant.Name
+		}
+	}
+	return responses
+}
+
+// embedPrice adds the effective price of a looked up product, for the
+// optional ?outlet_id=, ?customer_id= and ?at= of the sale
+func (h *ProductHandler) embedPrice(c echo.Context, product *domain.Product, productResponse *domain.ProductResponse) error {
+	req := domain.ResolvePricesRequest{
+		Items: []domain.PriceItemRequest{{ProductID: product.ID}},
+	}
+	if product.Variant != nil {
+		req.Items[0].VariantID = &product.Variant.ID
+	}
+
+	if value := c.QueryParam("outlet_id"); value != "" {
+		outletID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return errors.New("invalid outlet_id")
+		}
+		req.OutletID = &outletID
+	}
+	if value := c.QueryParam("customer_id"); value != "" {
+		customerID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return errors.New("invalid customer_id")
+		}
+		req.CustomerID = &customerID
+	}
+	if value := c.QueryParam("at"); value != "" {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errors.New("invalid at, expected RFC3339 time")
+		}
+		req.At = &at
+	}
+
+	prices, err := h.priceListService.ResolvePrices(c.Request().Context(), product.TenantID, req)
+	if err != nil {
+		return err
+	}
+
+	priceResponse := h.resolvedPriceToResponse(prices[0])
+	productResponse.Price = &priceResponse
+	return nil
+}
+
+func (h *ProductHandler) priceListToResponse(list *domain.PriceList) domain.PriceListResponse {
+	response := domain.PriceListResponse{
+		ID:               list.ID,
+		Name:             list.Name,
+		Description:      list.Description,
+		Priority:         list.Priority,
+		IsActive:         list.IsActive,
+		IsValidNow:       list.IsValidAt(time.Now()),
+		OutletIDs:        list.OutletIDs,
+		CustomerGroupIDs: list.CustomerGroupIDs,
+		ItemCount:        list.ItemCount,
+		CreatedAt:        list.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        list.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if list.StartsAt != nil {
+		startsAt := list.StartsAt.Format(time.RFC3339)
+		response.StartsAt = &startsAt
+	}
+	if list.EndsAt != nil {
+		endsAt := list.EndsAt.Format(time.RFC3339)
+		response.EndsAt = &endsAt
+	}
+
+	if response.OutletIDs == nil {
+		response.OutletIDs = []uint64{}
+	}
+	if response.CustomerGroupIDs == nil {
+		response.CustomerGroupIDs = []uint64{}
+	}
+
+	if list.Items != nil {
+		response.Items = make([]domain.PriceListItemResponse, len(list.Items))
+		for i, item := range list.Items {
+			response.Items[i] = domain.PriceListItemResponse{
+				ID:          item.ID,
+				ProductID:   item.ProductID,
+				ProductSKU:  item.ProductSKU,
+				ProductName: item.ProductName,
+				VariantID:   item.VariantID,
+				VariantSKU:  item.VariantSKU,
+				VariantName: item.VariantName,
+				Price:       item.Price,
+			}
+		}
+	}
+
+	return response
+}
+
+func (h *ProductHandler) modifierGroupsToResponse(groups []*domain.ModifierGroup) []domain.ModifierGroupResponse {
+	responses := make([]domain.ModifierGroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = h.modifierGroupToResponse(group)
+	}
+	return responses
+}
+
+func (h *ProductHandler) modifierGroupToResponse(group *domain.ModifierGroup) domain.ModifierGroupResponse {
+	response := domain.ModifierGroupResponse{
+		ID:        group.ID,
+		Name:      group.Name,
+		MinSelect: group.MinSelect,
+		MaxSelect: group.MaxSelect,
+		Required:  group.IsRequired(),
+		SortOrder: group.SortOrder,
+		IsActive:  group.IsActive,
+		Inherited: group.Inherited,
+		CreatedAt: group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: group.UpdatedAt.Format(time.RFC3339),
+		Modifiers: make([]domain.ModifierResponse, len(group.Modifiers)),
+	}
+
+	for i, modifier := range group.Modifiers {
+		response.Modifiers[i] = domain.ModifierResponse{
+			ID:          modifier.ID,
+			Name:        modifier.Name,
+			PriceDelta:  modifier.PriceDelta,
+			ProductID:   modifier.ProductID,
+			ProductSKU:  modifier.ProductSKU,
+			ProductName: modifier.ProductName,
+			Quantity:    modifier.Quantity,
+			IsActive:    modifier.IsActive,
+		}
+	}
+
+	return response
+}
+
+func (h *ProductHandler) unitToResponse(unit *domain.Unit) domain.UnitResponse {
+	return domain.UnitResponse{
+		ID:           unit.ID,
+		Code:         unit.Code,
+		Name:         unit.Name,
+		AllowDecimal: unit.AllowDecimal,
+		CreatedAt:    unit.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    unit.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *ProductHandler) productUnitsToResponse(units []*domain.ProductUnit) []domain.ProductUnitResponse {
+	responses := make([]domain.ProductUnitResponse, len(units))
+	for i, unit := range units {
+		responses[i] = domain.ProductUnitResponse{
+			Unit:         unit.Code,
+			Name:         unit.Name,
+			Factor:       unit.Factor,
+			AllowDecimal: unit.AllowDecimal,
+			IsBase:       unit.IsBase,
+		}
+		if unit.UnitID != 0 {
+			unitID := unit.UnitID
+			responses[i].UnitID = &unitID
+		}
+	}
+	return responses
+}
+
+func (h *ProductHandler) resolvedPriceToResponse(price *domain.ResolvedPrice) domain.ResolvedPriceResponse {
+	response := domain.ResolvedPriceResponse{
+		ProductID:      price.ProductID,
+		VariantID:      price.VariantID,
+		BasePrice:      price.BasePrice,
+		ModifiersPrice: price.ModifiersPrice,
+		Price:          price.Price,
+		PriceListID:    price.PriceListID,
+		PriceListName:  price.PriceListName,
+	}
+
+	for _, modifier := range price.Modifiers {
+		response.Modifiers = append(response.Modifiers, domain.ResolvedModifierResponse{
+			ID:         modifier.ID,
+			GroupID:    modifier.GroupID,
+			Name:       modifier.Name,
+			PriceDelta: modifier.PriceDelta,
+		})
+	}
+
 	return response
 }
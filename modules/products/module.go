@@ -1,29 +1,38 @@
 package products
 
 import (
+	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/products/domain"
 	"github.com/exven/pos-system/modules/products/handlers"
 	"github.com/exven/pos-system/modules/products/persistence"
 	"github.com/exven/pos-system/modules/products/services"
 	"github.com/exven/pos-system/shared/container"
 	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"github.com/exven/pos-system/shared/infrastructure/storage"
 	"gorm.io/gorm"
 )
 
 type Module struct {
-	container container.Container
-	db        *gorm.DB
-	eventBus  messaging.EventBus
+	container    container.Container
+	db           *gorm.DB
+	eventBus     messaging.EventBus
+	storage      storage.Storage
+	uploadConfig config.FileUploadConfig
 }
 
 func NewModule(
 	container container.Container,
 	db *gorm.DB,
 	eventBus messaging.EventBus,
+	fileStorage storage.Storage,
+	uploadConfig config.FileUploadConfig,
 ) *Module {
 	return &Module{
-		container: container,
-		db:        db,
-		eventBus:  eventBus,
+		container:    container,
+		db:           db,
+		eventBus:     eventBus,
+		storage:      fileStorage,
+		uploadConfig: uploadConfig,
 	}
 }
 
@@ -37,6 +46,38 @@ func (m *Module) Register() {
 		return persistence.NewProductRepository(m.db)
 	})
 
+	m.container.RegisterSingleton("products.variantRepository", func() interface{} {
+		return persistence.NewVariantRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.recipeRepository", func() interface{} {
+		return persistence.NewRecipeRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.priceListRepository", func() interface{} {
+		return persistence.NewPriceListRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.imageRepository", func() interface{} {
+		return persistence.NewImageRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.bundleRepository", func() interface{} {
+		return persistence.NewBundleRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.barcodeRepository", func() interface{} {
+		return persistence.NewBarcodeRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.modifierRepository", func() interface{} {
+		return persistence.NewModifierRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("products.unitRepository", func() interface{} {
+		return persistence.NewUnitRepository(m.db)
+	})
+
 	// Register services
 	m.container.RegisterSingleton("products.categoryService", func() interface{} {
 		repo := persistence.NewProductCategoryRepository(m.db)
@@ -44,25 +85,94 @@ func (m *Module) Register() {
 	})
 
 	m.container.RegisterSingleton("products.productService", func() interface{} {
-		productRepo := persistence.NewProductRepository(m.db)
-		categoryRepo := persistence.NewProductCategoryRepository(m.db)
-		return services.NewProductService(productRepo, categoryRepo)
+		return m.GetService()
+	})
+
+	m.container.RegisterSingleton("products.priceListService", func() interface{} {
+		return m.GetPriceListService()
+	})
+
+	m.container.RegisterSingleton("products.imageService", func() interface{} {
+		return m.GetImageService()
+	})
+
+	m.container.RegisterSingleton("products.labelService", func() interface{} {
+		return m.GetLabelService()
+	})
+
+	m.container.RegisterSingleton("products.modifierService", func() interface{} {
+		return m.GetModifierService()
+	})
+
+	m.container.RegisterSingleton("products.unitService", func() interface{} {
+		return m.GetUnitService()
 	})
 
 	// Register handlers
 	m.container.RegisterSingleton("products.handler", func() interface{} {
-		categoryRepo := persistence.NewProductCategoryRepository(m.db)
-		categoryService := services.NewProductCategoryService(categoryRepo)
-		productRepo := persistence.NewProductRepository(m.db)
-		productService := services.NewProductService(productRepo, categoryRepo)
-		return handlers.NewProductHandler(categoryService, productService)
+		return m.GetHandler()
 	})
 }
 
+// GetService builds the product service, also for commands that run outside
+// the API server
+func (m *Module) GetService() domain.ProductService {
+	productRepo := persistence.NewProductRepository(m.db)
+	categoryRepo := persistence.NewProductCategoryRepository(m.db)
+	variantRepo := persistence.NewVariantRepository(m.db)
+	recipeRepo := persistence.NewRecipeRepository(m.db)
+	barcodeRepo := persistence.NewBarcodeRepository(m.db)
+	bundleRepo := persistence.NewBundleRepository(m.db)
+	modifierRepo := persistence.NewModifierRepository(m.db)
+	unitRepo := persistence.NewUnitRepository(m.db)
+	return services.NewProductService(productRepo, categoryRepo, variantRepo, recipeRepo, barcodeRepo, bundleRepo, modifierRepo, unitRepo)
+}
+
+// GetPriceListService builds the price list service that resolves the
+// effective price of products for a sale
+func (m *Module) GetPriceListService() domain.PriceListService {
+	priceListRepo := persistence.NewPriceListRepository(m.db)
+	modifierRepo := persistence.NewModifierRepository(m.db)
+	return services.NewPriceListService(priceListRepo, modifierRepo)
+}
+
+// GetImageService builds the service that uploads product and category
+// images to the module's file storage
+func (m *Module) GetImageService() domain.ImageService {
+	imageRepo := persistence.NewImageRepository(m.db)
+	productRepo := persistence.NewProductRepository(m.db)
+	categoryRepo := persistence.NewProductCategoryRepository(m.db)
+	return services.NewImageService(imageRepo, productRepo, categoryRepo, m.storage, m.uploadConfig.MaxImageSize)
+}
+
+// GetLabelService builds the service that renders shelf labels and price
+// tags
+func (m *Module) GetLabelService() domain.LabelService {
+	productRepo := persistence.NewProductRepository(m.db)
+	variantRepo := persistence.NewVariantRepository(m.db)
+	return services.NewLabelService(productRepo, variantRepo, m.GetPriceListService())
+}
+
+// GetModifierService builds the service that manages modifier groups and
+// the products and categories offering them
+func (m *Module) GetModifierService() domain.ModifierService {
+	modifierRepo := persistence.NewModifierRepository(m.db)
+	productRepo := persistence.NewProductRepository(m.db)
+	categoryRepo := persistence.NewProductCategoryRepository(m.db)
+	bundleRepo := persistence.NewBundleRepository(m.db)
+	return services.NewModifierService(modifierRepo, productRepo, categoryRepo, bundleRepo)
+}
+
+// GetUnitService builds the service that manages units of measure and the
+// units products convert to their base unit
+func (m *Module) GetUnitService() domain.UnitService {
+	unitRepo := persistence.NewUnitRepository(m.db)
+	productRepo := persistence.NewProductRepository(m.db)
+	return services.NewUnitService(unitRepo, productRepo)
+}
+
 func (m *Module) GetHandler() *handlers.ProductHandler {
 	categoryRepo := persistence.NewProductCategoryRepository(m.db)
 	categoryService := services.NewProductCategoryService(categoryRepo)
-	productRepo := persistence.NewProductRepository(m.db)
-	productService := services.NewProductService(productRepo, categoryRepo)
-	return handlers.NewProductHandler(categoryService, productService)
+	return handlers.NewProductHandler(categoryService, m.GetService(), m.GetPriceListService(), m.GetImageService(), m.GetLabelService(), m.GetModifierService(), m.GetUnitService())
 }
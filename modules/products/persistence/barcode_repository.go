@@ -0,0 +1,182 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"gorm.io/gorm"
+)
+
+type barcodeRepository struct {
+	db *gorm.DB
+}
+
+func NewBarcodeRepository(db *gorm.DB) domain.BarcodeRepository {
+	return &barcodeRepository{db: db}
+}
+
+func (r *barcodeRepository) FindSettings(ctx context.Context, tenantID uint64) (*domain.BarcodeSettings, error) {
+	var row struct {
+		Prefix     string
+		Format     string
+		AutoAssign bool
+	}
+
+	// Tenants that never saved barcode settings use the defaults
+	err := r.db.WithContext(ctx).
+		Table("tenants").
+		Select("COALESCE(NULLIF(settings->'barcode'->>'prefix', ''), ?) AS prefix, "+
+			"COALESCE(NULLIF(settings->'barcode'->>'format', ''), ?) AS format, "+
+			"COALESCE((settings->'barcode'->>'auto_assign')::boolean, false) AS auto_assign",
+			domain.DefaultBarcodePrefix, domain.BarcodeFormatEAN13).
+		Where("id = ?", tenantID).
+		Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to find barcode settings: %w", err)
+	}
+
+	return &domain.BarcodeSettings{
+		Prefix:     row.Prefix,
+		Format:     row.Format,
+		AutoAssign: row.AutoAssign,
+	}, nil
+}
+
+func (r *barcodeRepository) Exists(ctx context.Context, tenantID uint64, barcode string, excludeProductID, excludeVariantID *uint64) (bool, error) {
+	var count int64
+
+	products := r.db.WithContext(ctx).
+		Model(&ProductModel{}).
+		Where("tenant_id = ? AND barcode = ?", tenantID, barcode)
+	if excludeProductID != nil {
+		products = products.Where("id != ?", *excludeProductID)
+	}
+	if err := products.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check product barcode: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	variants := r.db.WithContext(ctx).
+		Model(&ProductVariantModel{}).
+		Where("tenant_id = ? AND barcode = ?", tenantID, barcode)
+	if excludeVariantID != nil {
+		variants = variants.Where("id != ?", *excludeVariantID)
+	}
+	if err := variants.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check variant barcode: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// checkBarcode refuses a barcode that another product or variant of the
+// tenant has, checked under the tenant's barcode lock until tx ends. Empty
+// barcodes are not checked.
+func checkBarcode(tx *gorm.DB, tenantID uint64, barcode string, excludeProductID, excludeVariantID *uint64) error {
+	if barcode == "" {
+		return nil
+	}
+
+	taken, err := database.LockBarcode(tx, tenantID, barcode, excludeProductID, excludeVariantID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.New("barcode is already in use")
+	}
+
+	return nil
+}
+
+func (r *barcodeRepository) Assign(ctx context.Context, tenantID uint64, productIDs []uint64, scheme domain.BarcodeScheme) ([]*domain.Product, error) {
+	var assigned []*domain.Product
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Concurrent assignments for the tenant would take the same numbers;
+		// barcodes typed in are checked under the same lock
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", "product_barcodes", int32(tenantID)).Error; err != nil {
+			return fmt.Errorf("failed to lock barcodes: %w", err)
+		}
+
+		query := tx.Model(&ProductModel{}).
+			Select("id, sku, name").
+			Where("tenant_id = ? AND COALESCE(barcode, '') = ''", tenantID)
+		if len(productIDs) > 0 {
+			query = query.Where("id IN ?", productIDs)
+		} else {
+			query = query.Where("is_active = ?", true)
+		}
+
+		var models []ProductModel
+		if err := query.Order("id").Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to find products without barcode: %w", err)
+		}
+		if len(models) == 0 {
+			return nil
+		}
+
+		// Numbering continues after the highest barcode of the scheme held
+		// by any product or variant, typed in or assigned, so new ones are
+		// always unused
+		var last uint64
+		err := tx.Raw(
+			"SELECT COALESCE(MAX(SUBSTRING(b.barcode FROM @start FOR @digits)::bigint), 0) FROM ("+
+				"SELECT barcode FROM products WHERE tenant_id = @tenant "+
+				"UNION ALL SELECT barcode FROM product_variants WHERE tenant_id = @tenant"+
+				") b WHERE b.barcode ~ @pattern",
+			map[string]interface{}{
+				"tenant":  tenantID,
+				"start":   len(scheme.Prefix) + 1,
+				"digits":  scheme.SequenceDigits(),
+				"pattern": fmt.Sprintf("^%s[0-9]{%d}$", regexp.QuoteMeta(scheme.Prefix), scheme.Length()-len(scheme.Prefix)),
+			},
+		).Scan(&last).Error
+		if err != nil {
+			return fmt.Errorf("failed to find last barcode: %w", err)
+		}
+
+		now := time.Now()
+		for i := range models {
+			last++
+			code, err := scheme.Barcode(last)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&ProductModel{}).
+				Where("id = ?", models[i].ID).
+				Updates(map[string]interface{}{
+					"barcode":    code,
+					"updated_at": now,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to assign barcode: %w", err)
+			}
+
+			assigned = append(assigned, &domain.Product{
+				ID:       models[i].ID,
+				TenantID: tenantID,
+				SKU:      models[i].SKU,
+				Name:     models[i].Name,
+				Barcode:  code,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assigned, nil
+}
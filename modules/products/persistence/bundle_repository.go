@@ -0,0 +1,236 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+func NewBundleRepository(db *gorm.DB) domain.BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+func (r *bundleRepository) FindByProduct(ctx context.Context, productID uint64) (*domain.Bundle, error) {
+	var groupModels []ProductBundleGroupModel
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("sort_order ASC, id ASC").
+		Find(&groupModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle groups: %w", err)
+	}
+
+	var itemModels []BundleItemRowModel
+	err = r.db.WithContext(ctx).
+		Table("product_bundle_items bi").
+		Select("bi.*, c.sku AS component_sku, c.name AS component_name, c.unit AS component_unit, "+
+			"c.selling_price AS component_selling_price, c.track_stock AS component_track_stock, "+
+			"v.sku AS variant_sku, v.name AS variant_name, v.selling_price AS variant_selling_price").
+		Joins("JOIN products c ON c.id = bi.component_id").
+		Joins("LEFT JOIN product_variants v ON v.id = bi.variant_id").
+		Where("bi.product_id = ?", productID).
+		Order("bi.id ASC").
+		Find(&itemModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle items: %w", err)
+	}
+
+	bundle := &domain.Bundle{
+		ProductID: productID,
+		Groups:    make([]*domain.BundleGroup, len(groupModels)),
+	}
+	groups := make(map[uint64]*domain.BundleGroup, len(groupModels))
+	for i, model := range groupModels {
+		bundle.Groups[i] = &domain.BundleGroup{
+			ID:        model.ID,
+			ProductID: model.ProductID,
+			Name:      model.Name,
+			PickCount: model.PickCount,
+			SortOrder: model.SortOrder,
+		}
+		groups[model.ID] = bundle.Groups[i]
+	}
+
+	for i := range itemModels {
+		item := itemModels[i].ToDomainBundleItem()
+		if item.GroupID == nil {
+			bundle.Items = append(bundle.Items, item)
+			continue
+		}
+		if group, ok := groups[*item.GroupID]; ok {
+			group.Items = append(group.Items, item)
+		}
+	}
+
+	return bundle, nil
+}
+
+func (r *bundleRepository) Replace(ctx context.Context, productID uint64, bundle *domain.Bundle) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent edits of the same bundle
+		var product ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", productID).
+			Take(&product).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductBundleItemModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete bundle items: %w", err)
+		}
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductBundleGroupModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete bundle groups: %w", err)
+		}
+
+		if bundle == nil {
+			return nil
+		}
+
+		createItems := func(groupID *uint64, items []*domain.BundleItem) error {
+			if len(items) == 0 {
+				return nil
+			}
+
+			models := make([]ProductBundleItemModel, len(items))
+			for i, item := range items {
+				models[i] = ProductBundleItemModel{
+					ProductID:   productID,
+					GroupID:     groupID,
+					ComponentID: item.ComponentID,
+					VariantID:   item.VariantID,
+					Quantity:    item.Quantity,
+				}
+			}
+			if err := tx.Create(&models).Error; err != nil {
+				return fmt.Errorf("failed to create bundle items: %w", err)
+			}
+
+			for i := range models {
+				items[i].ID = models[i].ID
+				items[i].ProductID = productID
+				items[i].GroupID = groupID
+			}
+			return nil
+		}
+
+		if err := createItems(nil, bundle.Items); err != nil {
+			return err
+		}
+
+		for i, group := range bundle.Groups {
+			model := ProductBundleGroupModel{
+				ProductID: productID,
+				Name:      group.Name,
+				PickCount: group.PickCount,
+				SortOrder: i,
+			}
+			if err := tx.Create(&model).Error; err != nil {
+				return fmt.Errorf("failed to create bundle group: %w", err)
+			}
+			group.ID = model.ID
+			group.ProductID = productID
+			group.SortOrder = i
+
+			if err := createItems(&group.ID, group.Items); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *bundleRepository) HasBundle(ctx context.Context, productIDs []uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ProductBundleItemModel{}).
+		Where("product_id IN ?", productIDs).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check bundles: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *bundleRepository) IsComponent(ctx context.Context, productID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ProductBundleItemModel{}).
+		Where("component_id = ?", productID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check bundle components: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *bundleRepository) IsVariantComponent(ctx context.Context, variantID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ProductBundleItemModel{}).
+		Where("variant_id = ?", variantID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check bundle components: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *bundleRepository) FindComponentSales(ctx context.Context, tenantID, productID uint64, query domain.BundleSalesQuery) ([]*domain.BundleComponentSales, error) {
+	var models []BundleComponentSalesModel
+
+	db := r.db.WithContext(ctx).
+		Table("transaction_item_components tic").
+		Select("tic.product_id AS component_id, tic.variant_id, p.sku, p.name, COALESCE(v.name, '') AS variant_name, "+
+			"SUM(tic.quantity) AS quantity, SUM(tic.allocated_amount) AS allocated_amount, SUM(tic.cost_amount) AS cost_amount").
+		Joins("JOIN transaction_items ti ON ti.id = tic.transaction_item_id").
+		Joins("JOIN transactions t ON t.id = ti.transaction_id").
+		Joins("JOIN tenants tn ON tn.id = t.tenant_id").
+		Joins("JOIN products p ON p.id = tic.product_id").
+		Joins("LEFT JOIN product_variants v ON v.id = tic.variant_id").
+		Where("t.tenant_id = ? AND ti.product_id = ? AND t.status = 'completed'", tenantID, productID)
+
+	// Days are cut at midnight in the tenant's timezone
+	if query.DateFrom != nil {
+		db = db.Where("t.transaction_date >= CAST(CAST(? AS date) AS timestamp) AT TIME ZONE COALESCE(NULLIF(tn.timezone, ''), 'UTC')",
+			query.DateFrom.Format("2006-01-02"))
+	}
+	if query.DateTo != nil {
+		db = db.Where("t.transaction_date < CAST(CAST(? AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(tn.timezone, ''), 'UTC')",
+			query.DateTo.Format("2006-01-02"))
+	}
+
+	err := db.
+		Group("tic.product_id, tic.variant_id, p.sku, p.name, v.name").
+		Order("p.name ASC, tic.product_id ASC, tic.variant_id ASC NULLS FIRST").
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle sales: %w", err)
+	}
+
+	sales := make([]*domain.BundleComponentSales, len(models))
+	for i := range models {
+		sales[i] = models[i].ToDomainSales()
+	}
+
+	return sales, nil
+}
@@ -0,0 +1,206 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+)
+
+type imageRepository struct {
+	db *gorm.DB
+}
+
+func NewImageRepository(db *gorm.DB) domain.ImageRepository {
+	return &imageRepository{db: db}
+}
+
+func (r *imageRepository) CreateForProduct(ctx context.Context, image *domain.Image) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model := &ImageModel{}
+		model.FromDomainImage(image)
+
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("failed to create image: %w", err)
+		}
+
+		result := tx.Exec(
+			"UPDATE products SET images = COALESCE(images, '[]'::jsonb) || to_jsonb(?::text), updated_at = NOW() "+
+				"WHERE id = ? AND tenant_id = ?",
+			image.URL, *image.ProductID, image.TenantID,
+		)
+		if result.Error != nil {
+			return fmt.Errorf("failed to add product image: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("product not found")
+		}
+
+		image.ID = model.ID
+		image.CreatedAt = model.CreatedAt
+		return nil
+	})
+}
+
+func (r *imageRepository) ReplaceForCategory(ctx context.Context, image *domain.Image) ([]*domain.Image, error) {
+	var replaced []*domain.Image
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		replaced, err = r.deleteCategoryImages(tx, image.TenantID, *image.CategoryID)
+		if err != nil {
+			return err
+		}
+
+		model := &ImageModel{}
+		model.FromDomainImage(image)
+
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("failed to create image: %w", err)
+		}
+
+		if err := r.setCategoryImageURL(tx, image.TenantID, *image.CategoryID, image.URL); err != nil {
+			return err
+		}
+
+		image.ID = model.ID
+		image.CreatedAt = model.CreatedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+func (r *imageRepository) FindByID(ctx context.Context, tenantID, imageID uint64) (*domain.Image, error) {
+	var model ImageModel
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", imageID, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("image not found")
+		}
+		return nil, fmt.Errorf("failed to find image: %w", err)
+	}
+
+	return model.ToDomainImage(), nil
+}
+
+func (r *imageRepository) FindByProduct(ctx context.Context, tenantID, productID uint64) ([]*domain.Image, error) {
+	var models []ImageModel
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+		Order("id").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product images: %w", err)
+	}
+
+	images := make([]*domain.Image, len(models))
+	for i := range models {
+		images[i] = models[i].ToDomainImage()
+	}
+	return images, nil
+}
+
+func (r *imageRepository) CountByProduct(ctx context.Context, tenantID, productID uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ImageModel{}).
+		Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count product images: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *imageRepository) DeleteFromProduct(ctx context.Context, image *domain.Image) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND tenant_id = ?", image.ID, image.TenantID).Delete(&ImageModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete image: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("image not found")
+		}
+
+		err := tx.Exec(
+			"UPDATE products SET images = images - ?::text, updated_at = NOW() WHERE id = ? AND tenant_id = ?",
+			image.URL, *image.ProductID, image.TenantID,
+		).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove product image: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *imageRepository) DeleteFromCategory(ctx context.Context, tenantID, categoryID uint64) ([]*domain.Image, error) {
+	var deleted []*domain.Image
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		deleted, err = r.deleteCategoryImages(tx, tenantID, categoryID)
+		if err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return errors.New("image not found")
+		}
+
+		return r.setCategoryImageURL(tx, tenantID, categoryID, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// deleteCategoryImages removes the category's images and returns them
+func (r *imageRepository) deleteCategoryImages(tx *gorm.DB, tenantID, categoryID uint64) ([]*domain.Image, error) {
+	var models []ImageModel
+	err := tx.Where("tenant_id = ? AND category_id = ?", tenantID, categoryID).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find category images: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint64, len(models))
+	images := make([]*domain.Image, len(models))
+	for i := range models {
+		ids[i] = models[i].ID
+		images[i] = models[i].ToDomainImage()
+	}
+
+	if err := tx.Where("id IN ?", ids).Delete(&ImageModel{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete category images: %w", err)
+	}
+
+	return images, nil
+}
+
+func (r *imageRepository) setCategoryImageURL(tx *gorm.DB, tenantID, categoryID uint64, url string) error {
+	result := tx.Model(&ProductCategoryModel{}).
+		Where("id = ? AND tenant_id = ?", categoryID, tenantID).
+		Update("image_url", url)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update category image: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("product category not found")
+	}
+
+	return nil
+}
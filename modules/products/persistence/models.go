@@ -72,14 +72,15 @@ type ProductModel struct {
 	TenantID     uint64            `gorm:"not null;uniqueIndex:idx_products_tenant_sku;index:idx_products_tenant_category"`
 	CategoryID   *uint64           `gorm:"index:idx_products_tenant_category"`
 	SKU          string            `gorm:"size:100;not null;uniqueIndex:idx_products_tenant_sku"`
-	Barcode      string            `gorm:"size:100;index:idx_products_barcode"`
+	Barcode      string            `gorm:"size:100"`
 	Name         string            `gorm:"size:255;not null;index:idx_products_name"`
 	Description  string            `gorm:"type:text"`
 	Unit         string            `gorm:"size:50;default:'pcs'"`
 	CostPrice    float64           `gorm:"type:decimal(12,2);default:0.00"`
 	SellingPrice float64           `gorm:"type:decimal(12,2);not null"`
-	MinStock     int               `gorm:"default:0"`
+	MinStock     float64           `gorm:"type:decimal(15,3);default:0"`
 	TrackStock   bool              `gorm:"default:true"`
+	TrackLots    bool              `gorm:"default:false"`
 	IsActive     bool              `gorm:"default:true"`
 	Images       JSONArrayModel    `gorm:"type:jsonb"`
 	Variants     JSONVariantsModel `gorm:"type:jsonb"`
@@ -91,6 +92,139 @@ func (ProductModel) TableName() string {
 	return "products"
 }
 
+type ProductStockModel struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID        uint64    `gorm:"not null"`
+	VariantID        *uint64   `gorm:"column:variant_id"`
+	OutletID         uint64    `gorm:"not null"`
+	Quantity         float64   `gorm:"type:decimal(15,3);not null;default:0"`
+	ReservedQuantity float64   `gorm:"type:decimal(15,3);default:0"`
+	AverageCost      float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductStockModel) TableName() string {
+	return "product_stocks"
+}
+
+type StockMovementModel struct {
+	ID            uint64  `gorm:"primaryKey;autoIncrement"`
+	ProductID     uint64  `gorm:"not null"`
+	OutletID      uint64  `gorm:"not null"`
+	MovementType  string  `gorm:"not null"`
+	Quantity      float64 `gorm:"type:decimal(15,3);not null"`
+	ReferenceType string  `gorm:"not null"`
+	ReferenceID   *uint64
+	Notes         string    `gorm:"type:text"`
+	UnitCost      *float64  `gorm:"type:decimal(12,2)"`
+	TotalCost     *float64  `gorm:"type:decimal(15,2)"`
+	CreatedBy     uint64    `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (StockMovementModel) TableName() string {
+	return "stock_movements"
+}
+
+// OutletStockModel is a tenant outlet joined with the product's stock there
+type OutletModel struct {
+	ID   uint64 `gorm:"column:id"`
+	Code string `gorm:"column:code"`
+	Name string `gorm:"column:name"`
+}
+
+type StockTotalModel struct {
+	ProductID uint64  `gorm:"column:product_id"`
+	OutletID  uint64  `gorm:"column:outlet_id"`
+	Quantity  float64 `gorm:"column:quantity"`
+}
+
+type OutletStockModel struct {
+	OutletID         uint64     `gorm:"column:outlet_id"`
+	OutletName       string     `gorm:"column:outlet_name"`
+	OutletCode       string     `gorm:"column:outlet_code"`
+	Quantity         float64    `gorm:"column:quantity"`
+	ReservedQuantity float64    `gorm:"column:reserved_quantity"`
+	UpdatedAt        *time.Time `gorm:"column:updated_at"`
+}
+
+func (m *OutletStockModel) ToDomainProductStock() *domain.ProductStock {
+	return &domain.ProductStock{
+		OutletID:         m.OutletID,
+		OutletName:       m.OutletName,
+		OutletCode:       m.OutletCode,
+		Quantity:         m.Quantity,
+		ReservedQuantity: m.ReservedQuantity,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
+
+// VariantStockModel is the stock of a variant at one outlet
+type VariantStockModel struct {
+	VariantID uint64 `gorm:"column:variant_id"`
+	OutletStockModel
+}
+
+type ProductVariantModel struct {
+	ID           uint64            `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64            `gorm:"not null"`
+	ProductID    uint64            `gorm:"not null"`
+	SKU          string            `gorm:"size:100;not null"`
+	Barcode      string            `gorm:"size:100"`
+	Name         string            `gorm:"size:255;not null"`
+	Options      JSONVariantsModel `gorm:"type:jsonb;not null"`
+	SellingPrice *float64          `gorm:"type:decimal(12,2)"`
+	IsActive     bool              `gorm:"default:true"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime"`
+}
+
+func (ProductVariantModel) TableName() string {
+	return "product_variants"
+}
+
+func (m *ProductVariantModel) ToDomainVariant() *domain.ProductVariant {
+	options := make(map[string]string, len(m.Options))
+	for name, value := range m.Options {
+		if text, ok := value.(string); ok {
+			options[name] = text
+		}
+	}
+
+	return &domain.ProductVariant{
+		ID:           m.ID,
+		TenantID:     m.TenantID,
+		ProductID:    m.ProductID,
+		SKU:          m.SKU,
+		Barcode:      m.Barcode,
+		Name:         m.Name,
+		Options:      options,
+		SellingPrice: m.SellingPrice,
+		IsActive:     m.IsActive,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func (m *ProductVariantModel) FromDomainVariant(variant *domain.ProductVariant) {
+	m.ID = variant.ID
+	m.TenantID = variant.TenantID
+	m.ProductID = variant.ProductID
+	m.SKU = variant.SKU
+	m.Barcode = variant.Barcode
+	m.Name = variant.Name
+	m.SellingPrice = variant.SellingPrice
+	m.IsActive = variant.IsActive
+	m.CreatedAt = variant.CreatedAt
+	m.UpdatedAt = variant.UpdatedAt
+
+	options := make(JSONVariantsModel, len(variant.Options))
+	for name, value := range variant.Options {
+		options[name] = value
+	}
+	m.Options = options
+}
+
 type ProductCategoryWithParentModel struct {
 	ProductCategoryModel
 	ParentName *string `gorm:"column:parent_name"`
@@ -155,6 +289,7 @@ func (p *ProductModel) ToDomainProduct() *domain.Product {
 		SellingPrice: p.SellingPrice,
 		MinStock:     p.MinStock,
 		TrackStock:   p.TrackStock,
+		TrackLots:    p.TrackLots,
 		IsActive:     p.IsActive,
 		Images:       images,
 		Variants:     variants,
@@ -176,6 +311,7 @@ func (p *ProductModel) FromDomainProduct(product *domain.Product) {
 	p.SellingPrice = product.SellingPrice
 	p.MinStock = product.MinStock
 	p.TrackStock = product.TrackStock
+	p.TrackLots = product.TrackLots
 	p.IsActive = product.IsActive
 	p.CreatedAt = product.CreatedAt
 	p.UpdatedAt = product.UpdatedAt
@@ -192,3 +328,620 @@ func (p *ProductModel) FromDomainProduct(product *domain.Product) {
 	}
 	p.Variants = variants
 }
+
+type ProductRecipeItemModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64    `gorm:"not null"`
+	ComponentID uint64    `gorm:"not null"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductRecipeItemModel) TableName() string {
+	return "product_recipe_items"
+}
+
+// RecipeItemRowModel is a recipe item joined with its component product
+type RecipeItemRowModel struct {
+	ProductRecipeItemModel
+	ComponentSKU        string  `gorm:"column:component_sku"`
+	ComponentName       string  `gorm:"column:component_name"`
+	ComponentUnit       string  `gorm:"column:component_unit"`
+	ComponentCostPrice  float64 `gorm:"column:component_cost_price"`
+	ComponentTrackStock bool    `gorm:"column:component_track_stock"`
+}
+
+func (m *RecipeItemRowModel) ToDomainRecipeItem() *domain.RecipeItem {
+	return &domain.RecipeItem{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		ComponentID: m.ComponentID,
+		Quantity:    m.Quantity,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		Component: &domain.Product{
+			ID:         m.ComponentID,
+			SKU:        m.ComponentSKU,
+			Name:       m.ComponentName,
+			Unit:       m.ComponentUnit,
+			CostPrice:  m.ComponentCostPrice,
+			TrackStock: m.ComponentTrackStock,
+		},
+	}
+}
+
+type ProductBundleGroupModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null"`
+	Name      string    `gorm:"size:100;not null"`
+	PickCount int       `gorm:"not null"`
+	SortOrder int       `gorm:"default:0"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductBundleGroupModel) TableName() string {
+	return "product_bundle_groups"
+}
+
+type ProductBundleItemModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64    `gorm:"not null"`
+	GroupID     *uint64   `gorm:"column:group_id"`
+	ComponentID uint64    `gorm:"not null"`
+	VariantID   *uint64   `gorm:"column:variant_id"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductBundleItemModel) TableName() string {
+	return "product_bundle_items"
+}
+
+// BundleItemRowModel is a bundle item joined with its component product and
+// variant
+type BundleItemRowModel struct {
+	ProductBundleItemModel
+	ComponentSKU          string   `gorm:"column:component_sku"`
+	ComponentName         string   `gorm:"column:component_name"`
+	ComponentUnit         string   `gorm:"column:component_unit"`
+	ComponentSellingPrice float64  `gorm:"column:component_selling_price"`
+	ComponentTrackStock   bool     `gorm:"column:component_track_stock"`
+	VariantSKU            string   `gorm:"column:variant_sku"`
+	VariantName           string   `gorm:"column:variant_name"`
+	VariantSellingPrice   *float64 `gorm:"column:variant_selling_price"`
+}
+
+func (m *BundleItemRowModel) ToDomainBundleItem() *domain.BundleItem {
+	item := &domain.BundleItem{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		GroupID:     m.GroupID,
+		ComponentID: m.ComponentID,
+		VariantID:   m.VariantID,
+		Quantity:    m.Quantity,
+		Component: &domain.Product{
+			ID:           m.ComponentID,
+			SKU:          m.ComponentSKU,
+			Name:         m.ComponentName,
+			Unit:         m.ComponentUnit,
+			SellingPrice: m.ComponentSellingPrice,
+			TrackStock:   m.ComponentTrackStock,
+		},
+	}
+	if m.VariantID != nil {
+		item.Variant = &domain.ProductVariant{
+			ID:           *m.VariantID,
+			ProductID:    m.ComponentID,
+			SKU:          m.VariantSKU,
+			Name:         m.VariantName,
+			SellingPrice: m.VariantSellingPrice,
+		}
+	}
+	return item
+}
+
+// BundleComponentSalesModel is a row of a bundle's component sales
+type BundleComponentSalesModel struct {
+	ComponentID     uint64  `gorm:"column:component_id"`
+	VariantID       *uint64 `gorm:"column:variant_id"`
+	SKU             string  `gorm:"column:sku"`
+	Name            string  `gorm:"column:name"`
+	VariantName     string  `gorm:"column:variant_name"`
+	Quantity        float64 `gorm:"column:quantity"`
+	AllocatedAmount float64 `gorm:"column:allocated_amount"`
+	CostAmount      float64 `gorm:"column:cost_amount"`
+}
+
+func (m *BundleComponentSalesModel) ToDomainSales() *domain.BundleComponentSales {
+	return &domain.BundleComponentSales{
+		ComponentID:     m.ComponentID,
+		VariantID:       m.VariantID,
+		SKU:             m.SKU,
+		Name:            m.Name,
+		VariantName:     m.VariantName,
+		Quantity:        m.Quantity,
+		AllocatedAmount: m.AllocatedAmount,
+		CostAmount:      m.CostAmount,
+	}
+}
+
+type ModifierGroupModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID  uint64    `gorm:"not null"`
+	Name      string    `gorm:"size:100;not null"`
+	MinSelect int       `gorm:"not null"`
+	MaxSelect int       `gorm:"not null"`
+	SortOrder int       `gorm:"default:0"`
+	IsActive  bool      `gorm:"default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ModifierGroupModel) TableName() string {
+	return "modifier_groups"
+}
+
+func (m *ModifierGroupModel) ToDomainModifierGroup() *domain.ModifierGroup {
+	return &domain.ModifierGroup{
+		ID:        m.ID,
+		TenantID:  m.TenantID,
+		Name:      m.Name,
+		MinSelect: m.MinSelect,
+		MaxSelect: m.MaxSelect,
+		SortOrder: m.SortOrder,
+		IsActive:  m.IsActive,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		Modifiers: []*domain.Modifier{},
+	}
+}
+
+func (m *ModifierGroupModel) FromDomainModifierGroup(group *domain.ModifierGroup) {
+	m.ID = group.ID
+	m.TenantID = group.TenantID
+	m.Name = group.Name
+	m.MinSelect = group.MinSelect
+	m.MaxSelect = group.MaxSelect
+	m.SortOrder = group.SortOrder
+	m.IsActive = group.IsActive
+}
+
+type ModifierModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	GroupID    uint64    `gorm:"not null"`
+	Name       string    `gorm:"size:100;not null"`
+	PriceDelta float64   `gorm:"type:decimal(12,2);not null"`
+	ProductID  *uint64   `gorm:"column:product_id"`
+	Quantity   float64   `gorm:"type:decimal(15,3);not null"`
+	SortOrder  int       `gorm:"default:0"`
+	IsActive   bool      `gorm:"default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ModifierModel) TableName() string {
+	return "modifiers"
+}
+
+func (m *ModifierModel) FromDomainModifier(modifier *domain.Modifier) {
+	m.ID = modifier.ID
+	m.GroupID = modifier.GroupID
+	m.Name = modifier.Name
+	m.PriceDelta = modifier.PriceDelta
+	m.ProductID = modifier.ProductID
+	m.Quantity = modifier.Quantity
+	m.SortOrder = modifier.SortOrder
+	m.IsActive = modifier.IsActive
+}
+
+// ModifierRowModel is a modifier joined with the product it takes from
+// stock
+type ModifierRowModel struct {
+	ModifierModel
+	ProductSKU  string `gorm:"column:product_sku"`
+	ProductName string `gorm:"column:product_name"`
+}
+
+func (m *ModifierRowModel) ToDomainModifier() *domain.Modifier {
+	return &domain.Modifier{
+		ID:          m.ID,
+		GroupID:     m.GroupID,
+		Name:        m.Name,
+		PriceDelta:  m.PriceDelta,
+		ProductID:   m.ProductID,
+		Quantity:    m.Quantity,
+		SortOrder:   m.SortOrder,
+		IsActive:    m.IsActive,
+		ProductSKU:  m.ProductSKU,
+		ProductName: m.ProductName,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+type ProductModifierGroupModel struct {
+	ProductID       uint64 `gorm:"primaryKey"`
+	ModifierGroupID uint64 `gorm:"primaryKey"`
+	SortOrder       int    `gorm:"default:0"`
+}
+
+func (ProductModifierGroupModel) TableName() string {
+	return "product_modifier_groups"
+}
+
+type CategoryModifierGroupModel struct {
+	CategoryID      uint64 `gorm:"primaryKey"`
+	ModifierGroupID uint64 `gorm:"primaryKey"`
+	SortOrder       int    `gorm:"default:0"`
+}
+
+func (CategoryModifierGroupModel) TableName() string {
+	return "category_modifier_groups"
+}
+
+// OfferedModifierGroupModel is a modifier group a product offers, on its own
+// or through its category
+type OfferedModifierGroupModel struct {
+	ModifierGroupModel
+	OfferedTo uint64 `gorm:"column:offered_to"`
+	Inherited bool   `gorm:"column:inherited"`
+}
+
+// ModifierSalesModel is a row of the modifier sales
+type ModifierSalesModel struct {
+	ModifierID *uint64 `gorm:"column:modifier_id"`
+	GroupName  string  `gorm:"column:group_name"`
+	Name       string  `gorm:"column:name"`
+	Quantity   float64 `gorm:"column:quantity"`
+	Amount     float64 `gorm:"column:amount"`
+	CostAmount float64 `gorm:"column:cost_amount"`
+}
+
+func (m *ModifierSalesModel) ToDomainSales() *domain.ModifierSales {
+	return &domain.ModifierSales{
+		ModifierID: m.ModifierID,
+		GroupName:  m.GroupName,
+		Name:       m.Name,
+		Quantity:   m.Quantity,
+		Amount:     m.Amount,
+		CostAmount: m.CostAmount,
+	}
+}
+
+type UnitModel struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64    `gorm:"not null"`
+	Code         string    `gorm:"size:50;not null"`
+	Name         string    `gorm:"size:100;not null"`
+	AllowDecimal bool      `gorm:"default:false"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (UnitModel) TableName() string {
+	return "units"
+}
+
+func (m *UnitModel) ToDomainUnit() *domain.Unit {
+	return &domain.Unit{
+		ID:           m.ID,
+		TenantID:     m.TenantID,
+		Code:         m.Code,
+		Name:         m.Name,
+		AllowDecimal: m.AllowDecimal,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func (m *UnitModel) FromDomainUnit(unit *domain.Unit) {
+	m.ID = unit.ID
+	m.TenantID = unit.TenantID
+	m.Code = unit.Code
+	m.Name = unit.Name
+	m.AllowDecimal = unit.AllowDecimal
+}
+
+type ProductUnitModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null"`
+	UnitID    uint64    `gorm:"not null"`
+	Factor    float64   `gorm:"type:decimal(15,6);not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductUnitModel) TableName() string {
+	return "product_units"
+}
+
+// ProductUnitRowModel is a product's unit conversion joined with its unit
+type ProductUnitRowModel struct {
+	ProductUnitModel
+	Code         string `gorm:"column:code"`
+	Name         string `gorm:"column:name"`
+	AllowDecimal bool   `gorm:"column:allow_decimal"`
+}
+
+func (m *ProductUnitRowModel) ToDomainProductUnit() *domain.ProductUnit {
+	return &domain.ProductUnit{
+		ID:           m.ID,
+		ProductID:    m.ProductID,
+		UnitID:       m.UnitID,
+		Code:         m.Code,
+		Name:         m.Name,
+		AllowDecimal: m.AllowDecimal,
+		Factor:       m.Factor,
+	}
+}
+
+type PriceListModel struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64     `gorm:"not null"`
+	Name        string     `gorm:"size:255;not null"`
+	Description string     `gorm:"type:text"`
+	Priority    int        `gorm:"not null;default:0"`
+	StartsAt    *time.Time `gorm:"column:starts_at"`
+	EndsAt      *time.Time `gorm:"column:ends_at"`
+	IsActive    bool       `gorm:"default:true"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (PriceListModel) TableName() string {
+	return "price_lists"
+}
+
+// PriceListWithCountModel is a price list with the number of its items
+type PriceListWithCountModel struct {
+	PriceListModel
+	ItemCount int64 `gorm:"column:item_count"`
+}
+
+type PriceListOutletModel struct {
+	PriceListID uint64 `gorm:"primaryKey"`
+	OutletID    uint64 `gorm:"primaryKey"`
+}
+
+func (PriceListOutletModel) TableName() string {
+	return "price_list_outlets"
+}
+
+type PriceListCustomerGroupModel struct {
+	PriceListID     uint64 `gorm:"primaryKey"`
+	CustomerGroupID uint64 `gorm:"primaryKey"`
+}
+
+func (PriceListCustomerGroupModel) TableName() string {
+	return "price_list_customer_groups"
+}
+
+type PriceListItemModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	PriceListID uint64    `gorm:"not null"`
+	ProductID   uint64    `gorm:"not null"`
+	VariantID   *uint64   `gorm:"column:variant_id"`
+	Price       float64   `gorm:"type:decimal(12,2);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (PriceListItemModel) TableName() string {
+	return "price_list_items"
+}
+
+type PriceListItemRowModel struct {
+	PriceListItemModel
+	ProductSKU  string  `gorm:"column:product_sku"`
+	ProductName string  `gorm:"column:product_name"`
+	VariantSKU  *string `gorm:"column:variant_sku"`
+	VariantName *string `gorm:"column:variant_name"`
+}
+
+type PriceCandidateModel struct {
+	PriceListID   uint64     `gorm:"column:price_list_id"`
+	PriceListName string     `gorm:"column:price_list_name"`
+	Priority      int        `gorm:"column:priority"`
+	StartsAt      *time.Time `gorm:"column:starts_at"`
+	ProductID     uint64     `gorm:"column:product_id"`
+	VariantID     *uint64    `gorm:"column:variant_id"`
+	Price         float64    `gorm:"column:price"`
+}
+
+func (m *PriceListModel) ToDomainPriceList() *domain.PriceList {
+	return &domain.PriceList{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		Name:        m.Name,
+		Description: m.Description,
+		Priority:    m.Priority,
+		StartsAt:    m.StartsAt,
+		EndsAt:      m.EndsAt,
+		IsActive:    m.IsActive,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func (m *PriceListModel) FromDomainPriceList(list *domain.PriceList) {
+	m.ID = list.ID
+	m.TenantID = list.TenantID
+	m.Name = list.Name
+	m.Description = list.Description
+	m.Priority = list.Priority
+	m.StartsAt = list.StartsAt
+	m.EndsAt = list.EndsAt
+	m.IsActive = list.IsActive
+	m.CreatedAt = list.CreatedAt
+	m.UpdatedAt = list.UpdatedAt
+}
+
+func (m *PriceListItemRowModel) ToDomainPriceListItem() *domain.PriceListItem {
+	item := &domain.PriceListItem{
+		ID:          m.ID,
+		PriceListID: m.PriceListID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		Price:       m.Price,
+		ProductSKU:  m.ProductSKU,
+		ProductName: m.ProductName,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	if m.VariantSKU != nil {
+		item.VariantSKU = *m.VariantSKU
+	}
+	if m.VariantName != nil {
+		item.VariantName = *m.VariantName
+	}
+	return item
+}
+
+func (m *PriceCandidateModel) ToDomainPriceCandidate() *domain.PriceCandidate {
+	return &domain.PriceCandidate{
+		PriceListID:   m.PriceListID,
+		PriceListName: m.PriceListName,
+		Priority:      m.Priority,
+		StartsAt:      m.StartsAt,
+		ProductID:     m.ProductID,
+		VariantID:     m.VariantID,
+		Price:         m.Price,
+	}
+}
+
+type PriceChangeModel struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64    `gorm:"not null"`
+	ProductID       uint64    `gorm:"not null"`
+	OldCostPrice    float64   `gorm:"type:decimal(12,2);not null"`
+	NewCostPrice    float64   `gorm:"type:decimal(12,2);not null"`
+	OldSellingPrice float64   `gorm:"type:decimal(12,2);not null"`
+	NewSellingPrice float64   `gorm:"type:decimal(12,2);not null"`
+	Source          string    `gorm:"size:20;not null"`
+	Reason          string    `gorm:"type:text"`
+	ChangedBy       uint64    `gorm:"not null"`
+	EffectiveAt     time.Time `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (PriceChangeModel) TableName() string {
+	return "product_price_changes"
+}
+
+type PriceChangeRowModel struct {
+	PriceChangeModel
+	ProductSKU    string  `gorm:"column:product_sku"`
+	ProductName   string  `gorm:"column:product_name"`
+	ChangedByName *string `gorm:"column:changed_by_name"`
+}
+
+func (m *PriceChangeModel) FromDomainPriceChange(change *domain.PriceChange) {
+	m.ID = change.ID
+	m.TenantID = change.TenantID
+	m.ProductID = change.ProductID
+	m.OldCostPrice = change.OldCostPrice
+	m.NewCostPrice = change.NewCostPrice
+	m.OldSellingPrice = change.OldSellingPrice
+	m.NewSellingPrice = change.NewSellingPrice
+	m.Source = change.Source
+	m.Reason = change.Reason
+	m.ChangedBy = change.ChangedBy
+	m.EffectiveAt = change.EffectiveAt
+}
+
+func (m *PriceChangeRowModel) ToDomainPriceChange() *domain.PriceChange {
+	change := &domain.PriceChange{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		ProductID:       m.ProductID,
+		ProductSKU:      m.ProductSKU,
+		ProductName:     m.ProductName,
+		OldCostPrice:    m.OldCostPrice,
+		NewCostPrice:    m.NewCostPrice,
+		OldSellingPrice: m.OldSellingPrice,
+		NewSellingPrice: m.NewSellingPrice,
+		Source:          m.Source,
+		Reason:          m.Reason,
+		ChangedBy:       m.ChangedBy,
+		EffectiveAt:     m.EffectiveAt,
+		CreatedAt:       m.CreatedAt,
+	}
+	if m.ChangedByName != nil {
+		change.ChangedByName = *m.ChangedByName
+	}
+	return change
+}
+
+type JSONStringMapModel map[string]string
+
+func (j JSONStringMapModel) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(j)
+}
+
+func (j *JSONStringMapModel) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type ImageModel struct {
+	ID          uint64             `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64             `gorm:"not null"`
+	ProductID   *uint64            `gorm:"index"`
+	CategoryID  *uint64            `gorm:"index"`
+	StorageKey  string             `gorm:"size:500;not null"`
+	ContentType string             `gorm:"size:50;not null"`
+	Size        int64              `gorm:"not null"`
+	Width       int                `gorm:"not null"`
+	Height      int                `gorm:"not null"`
+	Thumbnails  JSONStringMapModel `gorm:"type:jsonb"`
+	CreatedBy   uint64             `gorm:"not null"`
+	CreatedAt   time.Time          `gorm:"autoCreateTime"`
+}
+
+func (ImageModel) TableName() string {
+	return "images"
+}
+
+func (m *ImageModel) ToDomainImage() *domain.Image {
+	return &domain.Image{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		ProductID:   m.ProductID,
+		CategoryID:  m.CategoryID,
+		StorageKey:  m.StorageKey,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Width:       m.Width,
+		Height:      m.Height,
+		Thumbnails:  m.Thumbnails,
+		CreatedBy:   m.CreatedBy,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func (m *ImageModel) FromDomainImage(image *domain.Image) {
+	m.ID = image.ID
+	m.TenantID = image.TenantID
+	m.ProductID = image.ProductID
+	m.CategoryID = image.CategoryID
+	m.StorageKey = image.StorageKey
+	m.ContentType = image.ContentType
+	m.Size = image.Size
+	m.Width = image.Width
+	m.Height = image.Height
+	m.Thumbnails = image.Thumbnails
+	m.CreatedBy = image.CreatedBy
+}
@@ -0,0 +1,432 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type modifierRepository struct {
+	db *gorm.DB
+}
+
+func NewModifierRepository(db *gorm.DB) domain.ModifierRepository {
+	return &modifierRepository{db: db}
+}
+
+func (r *modifierRepository) Create(ctx context.Context, group *domain.ModifierGroup) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model := &ModifierGroupModel{}
+		model.FromDomainModifierGroup(group)
+
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("failed to create modifier group: %w", err)
+		}
+
+		group.ID = model.ID
+		group.CreatedAt = model.CreatedAt
+		group.UpdatedAt = model.UpdatedAt
+
+		for i, modifier := range group.Modifiers {
+			modifier.GroupID = group.ID
+			modifier.SortOrder = i
+
+			modifierModel := &ModifierModel{}
+			modifierModel.FromDomainModifier(modifier)
+			if err := tx.Create(modifierModel).Error; err != nil {
+				return fmt.Errorf("failed to create modifier: %w", err)
+			}
+			modifier.ID = modifierModel.ID
+		}
+
+		return nil
+	})
+}
+
+func (r *modifierRepository) Update(ctx context.Context, group *domain.ModifierGroup) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ModifierGroupModel{}).
+			Where("id = ? AND tenant_id = ?", group.ID, group.TenantID).
+			Updates(map[string]interface{}{
+				"name":       group.Name,
+				"min_select": group.MinSelect,
+				"max_select": group.MaxSelect,
+				"sort_order": group.SortOrder,
+				"is_active":  group.IsActive,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update modifier group: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("modifier group not found")
+		}
+
+		keep := make([]uint64, 0, len(group.Modifiers))
+		for _, modifier := range group.Modifiers {
+			if modifier.ID != 0 {
+				keep = append(keep, modifier.ID)
+			}
+		}
+
+		// Past sales keep their snapshots of deleted modifiers
+		deleted := tx.Where("group_id = ?", group.ID)
+		if len(keep) > 0 {
+			deleted = deleted.Where("id NOT IN ?", keep)
+		}
+		if err := deleted.Delete(&ModifierModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete modifiers: %w", err)
+		}
+
+		for i, modifier := range group.Modifiers {
+			modifier.GroupID = group.ID
+			modifier.SortOrder = i
+
+			if modifier.ID == 0 {
+				model := &ModifierModel{}
+				model.FromDomainModifier(modifier)
+				if err := tx.Create(model).Error; err != nil {
+					return fmt.Errorf("failed to create modifier: %w", err)
+				}
+				modifier.ID = model.ID
+				continue
+			}
+
+			result := tx.Model(&ModifierModel{}).
+				Where("id = ? AND group_id = ?", modifier.ID, group.ID).
+				Updates(map[string]interface{}{
+					"name":        modifier.Name,
+					"price_delta": modifier.PriceDelta,
+					"product_id":  modifier.ProductID,
+					"quantity":    modifier.Quantity,
+					"sort_order":  modifier.SortOrder,
+					"is_active":   modifier.IsActive,
+					"updated_at":  time.Now(),
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to update modifier: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("modifier not found")
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *modifierRepository) Delete(ctx context.Context, tenantID, groupID uint64) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", groupID, tenantID).
+		Delete(&ModifierGroupModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete modifier group: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("modifier group not found")
+	}
+
+	return nil
+}
+
+func (r *modifierRepository) FindByID(ctx context.Context, tenantID, groupID uint64) (*domain.ModifierGroup, error) {
+	var model ModifierGroupModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", groupID, tenantID).
+		Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("modifier group not found")
+		}
+		return nil, fmt.Errorf("failed to find modifier group: %w", err)
+	}
+
+	group := model.ToDomainModifierGroup()
+	if err := r.withModifiers(ctx, []*domain.ModifierGroup{group}); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (r *modifierRepository) FindAll(ctx context.Context, tenantID uint64, query domain.ModifierGroupQuery) ([]*domain.ModifierGroup, int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&ModifierGroupModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.IsActive != nil {
+		db = db.Where("is_active = ?", *query.IsActive)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count modifier groups: %w", err)
+	}
+
+	var models []ModifierGroupModel
+	err := db.
+		Order("sort_order ASC, name ASC").
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find modifier groups: %w", err)
+	}
+
+	groups := make([]*domain.ModifierGroup, len(models))
+	for i := range models {
+		groups[i] = models[i].ToDomainModifierGroup()
+	}
+	if err := r.withModifiers(ctx, groups); err != nil {
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+// withModifiers loads the modifiers of the groups, in their order
+func (r *modifierRepository) withModifiers(ctx context.Context, groups []*domain.ModifierGroup) error {
+	byID := make(map[uint64][]*domain.ModifierGroup, len(groups))
+	groupIDs := make([]uint64, 0, len(groups))
+	for _, group := range groups {
+		if _, ok := byID[group.ID]; !ok {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		// A group offered to several products appears once for each
+		byID[group.ID] = append(byID[group.ID], group)
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	var models []ModifierRowModel
+	err := r.db.WithContext(ctx).
+		Table("modifiers m").
+		Select("m.*, p.sku AS product_sku, p.name AS product_name").
+		Joins("LEFT JOIN products p ON p.id = m.product_id").
+		Where("m.group_id IN ?", groupIDs).
+		Order("m.sort_order ASC, m.id ASC").
+		Find(&models).Error
+	if err != nil {
+		return fmt.Errorf("failed to find modifiers: %w", err)
+	}
+
+	for i := range models {
+		modifier := models[i].ToDomainModifier()
+		for _, group := range byID[modifier.GroupID] {
+			group.Modifiers = append(group.Modifiers, modifier)
+		}
+	}
+
+	return nil
+}
+
+func (r *modifierRepository) CountGroups(ctx context.Context, tenantID uint64, groupIDs []uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ModifierGroupModel{}).
+		Where("tenant_id = ? AND id IN ?", tenantID, groupIDs).
+		Count(&count).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to check modifier groups: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *modifierRepository) FindForProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64][]*domain.ModifierGroup, error) {
+	offered := make(map[uint64][]*domain.ModifierGroup, len(productIDs))
+	if len(productIDs) == 0 {
+		return offered, nil
+	}
+
+	// The category's groups come first, then the product's own; a group
+	// attached to both is offered once, as inherited
+	var models []OfferedModifierGroupModel
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT * FROM ("+
+			"SELECT DISTINCT ON (o.offered_to, g.id) g.*, o.offered_to, o.inherited, o.source, o.position FROM ("+
+			"SELECT p.id AS offered_to, cmg.modifier_group_id, true AS inherited, 0 AS source, cmg.sort_order AS position "+
+			"FROM products p JOIN category_modifier_groups cmg ON cmg.category_id = p.category_id "+
+			"WHERE p.tenant_id = @tenant AND p.id IN @products "+
+			"UNION ALL "+
+			"SELECT pmg.product_id, pmg.modifier_group_id, false, 1, pmg.sort_order "+
+			"FROM product_modifier_groups pmg WHERE pmg.product_id IN @products"+
+			") o JOIN modifier_groups g ON g.id = o.modifier_group_id AND g.tenant_id = @tenant "+
+			"ORDER BY o.offered_to, g.id, o.source"+
+			") offered ORDER BY offered_to, source, position",
+		map[string]interface{}{
+			"tenant":   tenantID,
+			"products": productIDs,
+		},
+	).Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product modifier groups: %w", err)
+	}
+
+	var groups []*domain.ModifierGroup
+	for i := range models {
+		group := models[i].ToDomainModifierGroup()
+		group.Inherited = models[i].Inherited
+		offered[models[i].OfferedTo] = append(offered[models[i].OfferedTo], group)
+		groups = append(groups, group)
+	}
+	if err := r.withModifiers(ctx, groups); err != nil {
+		return nil, err
+	}
+
+	return offered, nil
+}
+
+func (r *modifierRepository) FindByCategory(ctx context.Context, categoryID uint64) ([]*domain.ModifierGroup, error) {
+	var models []ModifierGroupModel
+	err := r.db.WithContext(ctx).
+		Table("modifier_groups g").
+		Select("g.*").
+		Joins("JOIN category_modifier_groups cmg ON cmg.modifier_group_id = g.id").
+		Where("cmg.category_id = ?", categoryID).
+		Order("cmg.sort_order ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find category modifier groups: %w", err)
+	}
+
+	groups := make([]*domain.ModifierGroup, len(models))
+	for i := range models {
+		groups[i] = models[i].ToDomainModifierGroup()
+	}
+	if err := r.withModifiers(ctx, groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (r *modifierRepository) ReplaceForProduct(ctx context.Context, productID uint64, groupIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent edits of the product's groups
+		var product ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", productID).
+			Take(&product).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductModifierGroupModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete product modifier groups: %w", err)
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+
+		models := make([]ProductModifierGroupModel, len(groupIDs))
+		for i, groupID := range groupIDs {
+			models[i] = ProductModifierGroupModel{ProductID: productID, ModifierGroupID: groupID, SortOrder: i}
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return fmt.Errorf("failed to create product modifier groups: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *modifierRepository) ReplaceForCategory(ctx context.Context, categoryID uint64, groupIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent edits of the category's groups
+		var category ProductCategoryModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", categoryID).
+			Take(&category).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product category not found")
+			}
+			return fmt.Errorf("failed to lock category: %w", err)
+		}
+
+		if err := tx.Where("category_id = ?", categoryID).Delete(&CategoryModifierGroupModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete category modifier groups: %w", err)
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+
+		models := make([]CategoryModifierGroupModel, len(groupIDs))
+		for i, groupID := range groupIDs {
+			models[i] = CategoryModifierGroupModel{CategoryID: categoryID, ModifierGroupID: groupID, SortOrder: i}
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return fmt.Errorf("failed to create category modifier groups: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *modifierRepository) IsModifierProduct(ctx context.Context, productID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ModifierModel{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check modifier products: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *modifierRepository) FindSales(ctx context.Context, tenantID uint64, query domain.ModifierSalesQuery) ([]*domain.ModifierSales, error) {
+	var models []ModifierSalesModel
+
+	db := r.db.WithContext(ctx).
+		Table("transaction_item_modifiers tim").
+		Select("tim.modifier_id, tim.group_name_snapshot AS group_name, tim.name_snapshot AS name, "+
+			"SUM(tim.quantity) AS quantity, SUM(tim.quantity * tim.price_delta) AS amount, SUM(tim.cost_amount) AS cost_amount").
+		Joins("JOIN transaction_items ti ON ti.id = tim.transaction_item_id").
+		Joins("JOIN transactions t ON t.id = ti.transaction_id").
+		Joins("JOIN tenants tn ON tn.id = t.tenant_id").
+		Where("t.tenant_id = ? AND t.status = 'completed'", tenantID)
+
+	// Days are cut at midnight in the tenant's timezone
+	if query.DateFrom != nil {
+		db = db.Where("t.transaction_date >= CAST(CAST(? AS date) AS timestamp) AT TIME ZONE COALESCE(NULLIF(tn.timezone, ''), 'UTC')",
+			query.DateFrom.Format("2006-01-02"))
+	}
+	if query.DateTo != nil {
+		db = db.Where("t.transaction_date < CAST(CAST(? AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(tn.timezone, ''), 'UTC')",
+			query.DateTo.Format("2006-01-02"))
+	}
+
+	err := db.
+		Group("tim.modifier_id, tim.group_name_snapshot, tim.name_snapshot").
+		Order("quantity DESC, group_name ASC, name ASC").
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find modifier sales: %w", err)
+	}
+
+	sales := make([]*domain.ModifierSales, len(models))
+	for i := range models {
+		sales[i] = models[i].ToDomainSales()
+	}
+
+	return sales, nil
+}
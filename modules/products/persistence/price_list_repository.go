@@ -0,0 +1,418 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// priceListScopeSQL keeps the lists that apply at an outlet and to a
+// customer group. A list without outlets or groups is not limited by them,
+// and a nil outlet or group only matches lists not limited by it.
+const priceListScopeSQL = "(NOT EXISTS (SELECT 1 FROM price_list_outlets plo WHERE plo.price_list_id = pl.id) " +
+	"OR EXISTS (SELECT 1 FROM price_list_outlets plo WHERE plo.price_list_id = pl.id AND plo.outlet_id = @outlet)) " +
+	"AND (NOT EXISTS (SELECT 1 FROM price_list_customer_groups plg WHERE plg.price_list_id = pl.id) " +
+	"OR EXISTS (SELECT 1 FROM price_list_customer_groups plg WHERE plg.price_list_id = pl.id AND plg.customer_group_id = @group))"
+
+type priceListRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceListRepository(db *gorm.DB) domain.PriceListRepository {
+	return &priceListRepository{db: db}
+}
+
+func (r *priceListRepository) Create(ctx context.Context, list *domain.PriceList) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model := &PriceListModel{}
+		model.FromDomainPriceList(list)
+
+		if err := tx.Create(model).Error; err != nil {
+			return fmt.Errorf("failed to create price list: %w", err)
+		}
+
+		if err := r.replaceScope(tx, model.ID, list); err != nil {
+			return err
+		}
+
+		list.ID = model.ID
+		list.CreatedAt = model.CreatedAt
+		list.UpdatedAt = model.UpdatedAt
+		return nil
+	})
+}
+
+func (r *priceListRepository) Update(ctx context.Context, list *domain.PriceList) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&PriceListModel{}).
+			Where("id = ? AND tenant_id = ?", list.ID, list.TenantID).
+			Updates(map[string]interface{}{
+				"name":        list.Name,
+				"description": list.Description,
+				"priority":    list.Priority,
+				"starts_at":   list.StartsAt,
+				"ends_at":     list.EndsAt,
+				"is_active":   list.IsActive,
+				"updated_at":  time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update price list: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("price list not found")
+		}
+
+		if err := tx.Where("price_list_id = ?", list.ID).Delete(&PriceListOutletModel{}).Error; err != nil {
+			return fmt.Errorf("failed to update price list outlets: %w", err)
+		}
+		if err := tx.Where("price_list_id = ?", list.ID).Delete(&PriceListCustomerGroupModel{}).Error; err != nil {
+			return fmt.Errorf("failed to update price list customer groups: %w", err)
+		}
+
+		return r.replaceScope(tx, list.ID, list)
+	})
+}
+
+func (r *priceListRepository) replaceScope(tx *gorm.DB, listID uint64, list *domain.PriceList) error {
+	if len(list.OutletIDs) > 0 {
+		outlets := make([]PriceListOutletModel, len(list.OutletIDs))
+		for i, outletID := range list.OutletIDs {
+			outlets[i] = PriceListOutletModel{PriceListID: listID, OutletID: outletID}
+		}
+		if err := tx.Create(&outlets).Error; err != nil {
+			return fmt.Errorf("failed to create price list outlets: %w", err)
+		}
+	}
+
+	if len(list.CustomerGroupIDs) > 0 {
+		groups := make([]PriceListCustomerGroupModel, len(list.CustomerGroupIDs))
+		for i, groupID := range list.CustomerGroupIDs {
+			groups[i] = PriceListCustomerGroupModel{PriceListID: listID, CustomerGroupID: groupID}
+		}
+		if err := tx.Create(&groups).Error; err != nil {
+			return fmt.Errorf("failed to create price list customer groups: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *priceListRepository) Delete(ctx context.Context, tenantID, listID uint64) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", listID, tenantID).
+		Delete(&PriceListModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete price list: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("price list not found")
+	}
+
+	return nil
+}
+
+func (r *priceListRepository) FindByID(ctx context.Context, tenantID, listID uint64) (*domain.PriceList, error) {
+	var model PriceListWithCountModel
+
+	err := r.db.WithContext(ctx).
+		Table("price_lists pl").
+		Select("pl.*, (SELECT COUNT(*) FROM price_list_items pli WHERE pli.price_list_id = pl.id) AS item_count").
+		Where("pl.id = ? AND pl.tenant_id = ?", listID, tenantID).
+		Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("price list not found")
+		}
+		return nil, fmt.Errorf("failed to find price list: %w", err)
+	}
+
+	lists, err := r.withScopes(ctx, []PriceListWithCountModel{model})
+	if err != nil {
+		return nil, err
+	}
+
+	return lists[0], nil
+}
+
+func (r *priceListRepository) FindAll(ctx context.Context, tenantID uint64, query domain.PriceListQuery) ([]*domain.PriceList, int64, error) {
+	db := r.db.WithContext(ctx).
+		Table("price_lists pl").
+		Where("pl.tenant_id = ?", tenantID)
+
+	if query.IsActive != nil {
+		db = db.Where("pl.is_active = ?", *query.IsActive)
+	}
+	if query.OutletID != nil {
+		// Lists that apply at the outlet, including those for every outlet
+		db = db.Where("NOT EXISTS (SELECT 1 FROM price_list_outlets plo WHERE plo.price_list_id = pl.id) "+
+			"OR EXISTS (SELECT 1 FROM price_list_outlets plo WHERE plo.price_list_id = pl.id AND plo.outlet_id = ?)", *query.OutletID)
+	}
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count price lists: %w", err)
+	}
+
+	var models []PriceListWithCountModel
+	err := db.
+		Select("pl.*, (SELECT COUNT(*) FROM price_list_items pli WHERE pli.price_list_id = pl.id) AS item_count").
+		Order("pl.priority DESC, pl.name ASC").
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find price lists: %w", err)
+	}
+
+	lists, err := r.withScopes(ctx, models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return lists, total, nil
+}
+
+// withScopes converts the models and loads their outlets and customer groups
+func (r *priceListRepository) withScopes(ctx context.Context, models []PriceListWithCountModel) ([]*domain.PriceList, error) {
+	lists := make([]*domain.PriceList, len(models))
+	byID := make(map[uint64]*domain.PriceList, len(models))
+	listIDs := make([]uint64, len(models))
+	for i := range models {
+		lists[i] = models[i].ToDomainPriceList()
+		lists[i].ItemCount = models[i].ItemCount
+		lists[i].OutletIDs = []uint64{}
+		lists[i].CustomerGroupIDs = []uint64{}
+		byID[lists[i].ID] = lists[i]
+		listIDs[i] = lists[i].ID
+	}
+	if len(listIDs) == 0 {
+		return lists, nil
+	}
+
+	var outlets []PriceListOutletModel
+	err := r.db.WithContext(ctx).
+		Where("price_list_id IN ?", listIDs).
+		Order("outlet_id ASC").
+		Find(&outlets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price list outlets: %w", err)
+	}
+	for _, outlet := range outlets {
+		list := byID[outlet.PriceListID]
+		list.OutletIDs = append(list.OutletIDs, outlet.OutletID)
+	}
+
+	var groups []PriceListCustomerGroupModel
+	err = r.db.WithContext(ctx).
+		Where("price_list_id IN ?", listIDs).
+		Order("customer_group_id ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price list customer groups: %w", err)
+	}
+	for _, group := range groups {
+		list := byID[group.PriceListID]
+		list.CustomerGroupIDs = append(list.CustomerGroupIDs, group.CustomerGroupID)
+	}
+
+	return lists, nil
+}
+
+func (r *priceListRepository) FindItems(ctx context.Context, listID uint64) ([]*domain.PriceListItem, error) {
+	var models []PriceListItemRowModel
+
+	err := r.db.WithContext(ctx).
+		Table("price_list_items pli").
+		Select("pli.*, p.sku AS product_sku, p.name AS product_name, v.sku AS variant_sku, v.name AS variant_name").
+		Joins("JOIN products p ON p.id = pli.product_id").
+		Joins("LEFT JOIN product_variants v ON v.id = pli.variant_id").
+		Where("pli.price_list_id = ?", listID).
+		Order("p.name ASC, pli.variant_id ASC NULLS FIRST").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price list items: %w", err)
+	}
+
+	items := make([]*domain.PriceListItem, len(models))
+	for i := range models {
+		items[i] = models[i].ToDomainPriceListItem()
+	}
+
+	return items, nil
+}
+
+func (r *priceListRepository) UpsertItems(ctx context.Context, listID uint64, items []*domain.PriceListItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			model := &PriceListItemModel{
+				PriceListID: listID,
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				Price:       item.Price,
+			}
+
+			// Product and variant items are unique through separate
+			// partial indexes
+			conflict := clause.OnConflict{
+				Columns:     []clause.Column{{Name: "price_list_id"}, {Name: "product_id"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NULL"}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"price", "updated_at"}),
+			}
+			if item.VariantID != nil {
+				conflict.Columns = []clause.Column{{Name: "price_list_id"}, {Name: "variant_id"}}
+				conflict.TargetWhere = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NOT NULL"}}}
+			}
+
+			if err := tx.Clauses(conflict).Create(model).Error; err != nil {
+				return fmt.Errorf("failed to save price list item: %w", err)
+			}
+		}
+
+		err := tx.Model(&PriceListModel{}).Where("id = ?", listID).Update("updated_at", time.Now()).Error
+		if err != nil {
+			return fmt.Errorf("failed to update price list: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *priceListRepository) DeleteItem(ctx context.Context, listID, itemID uint64) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND price_list_id = ?", itemID, listID).
+		Delete(&PriceListItemModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete price list item: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("price list item not found")
+	}
+
+	return nil
+}
+
+func (r *priceListRepository) CountOutlets(ctx context.Context, tenantID uint64, outletIDs []uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("outlets").
+		Where("tenant_id = ? AND id IN ?", tenantID, outletIDs).
+		Count(&count).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to check outlets: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *priceListRepository) CountCustomerGroups(ctx context.Context, tenantID uint64, groupIDs []uint64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("customer_groups").
+		Where("tenant_id = ? AND id IN ?", tenantID, groupIDs).
+		Count(&count).Error
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to check customer groups: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *priceListRepository) FindCustomerGroupID(ctx context.Context, tenantID, customerID uint64) (*uint64, error) {
+	var customer struct {
+		CustomerGroupID *uint64
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("customers").
+		Select("customer_group_id").
+		Where("id = ? AND tenant_id = ? AND is_active = ?", customerID, tenantID, true).
+		Take(&customer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+
+	return customer.CustomerGroupID, nil
+}
+
+func (r *priceListRepository) FindCandidates(ctx context.Context, tenantID uint64, productIDs []uint64, outletID, customerGroupID *uint64, at time.Time) ([]*domain.PriceCandidate, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	var models []PriceCandidateModel
+	err := r.db.WithContext(ctx).
+		Table("price_list_items pli").
+		Select("pl.id AS price_list_id, pl.name AS price_list_name, pl.priority, pl.starts_at, "+
+			"pli.product_id, pli.variant_id, pli.price").
+		Joins("JOIN price_lists pl ON pl.id = pli.price_list_id").
+		Where("pl.tenant_id = @tenant AND pl.is_active = true AND pli.product_id IN @products", map[string]interface{}{
+			"tenant":   tenantID,
+			"products": productIDs,
+		}).
+		Where("(pl.starts_at IS NULL OR pl.starts_at <= @at) AND (pl.ends_at IS NULL OR pl.ends_at > @at)", map[string]interface{}{
+			"at": at,
+		}).
+		Where(priceListScopeSQL, map[string]interface{}{
+			"outlet": outletID,
+			"group":  customerGroupID,
+		}).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prices: %w", err)
+	}
+
+	candidates := make([]*domain.PriceCandidate, len(models))
+	for i := range models {
+		candidates[i] = models[i].ToDomainPriceCandidate()
+	}
+
+	return candidates, nil
+}
+
+func (r *priceListRepository) FindBasePrices(ctx context.Context, tenantID uint64, productIDs, variantIDs []uint64) (map[uint64]float64, map[uint64]*domain.ProductVariant, error) {
+	prices := make(map[uint64]float64, len(productIDs))
+	variants := make(map[uint64]*domain.ProductVariant, len(variantIDs))
+
+	if len(productIDs) > 0 {
+		var products []ProductModel
+		err := r.db.WithContext(ctx).
+			Select("id, selling_price").
+			Where("tenant_id = ? AND id IN ?", tenantID, productIDs).
+			Find(&products).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find product prices: %w", err)
+		}
+		for _, product := range products {
+			prices[product.ID] = product.SellingPrice
+		}
+	}
+
+	if len(variantIDs) > 0 {
+		var models []ProductVariantModel
+		err := r.db.WithContext(ctx).
+			Where("tenant_id = ? AND id IN ?", tenantID, variantIDs).
+			Find(&models).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find variant prices: %w", err)
+		}
+		for i := range models {
+			variants[models[i].ID] = models[i].ToDomainVariant()
+		}
+	}
+
+	return prices, variants, nil
+}
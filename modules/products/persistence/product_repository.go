@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
 	"gorm.io/gorm"
 )
 
@@ -18,15 +22,63 @@ func NewProductRepository(db *gorm.DB) domain.ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+func (r *productRepository) Create(ctx context.Context, product *domain.Product, createdBy uint64) error {
 	model := &ProductModel{}
 	model.FromDomainProduct(product)
 
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
-		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		taken, err := database.LockSKU(tx, model.TenantID, model.SKU, nil, nil)
+		if err != nil {
+			return err
+		}
+		if taken {
 			return errors.New("product with this SKU already exists")
 		}
-		return fmt.Errorf("failed to create product: %w", err)
+		if err := checkBarcode(tx, model.TenantID, model.Barcode, nil, nil); err != nil {
+			return err
+		}
+
+		if err := tx.Create(model).Error; err != nil {
+			if isDuplicateBarcode(err) {
+				return errors.New("barcode is already in use")
+			}
+			if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+				return errors.New("product with this SKU already exists")
+			}
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+
+		// Every outlet gets a stock row; initial stock enters through the
+		// stock ledger at the product's cost price, while outlets starting
+		// empty get no movement
+		unitCost := model.CostPrice
+		for _, stock := range product.Stocks {
+			if stock.Quantity == 0 {
+				if _, err := stockledger.LockStockRow(tx, model.ID, nil, stock.OutletID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			_, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     model.ID,
+				OutletID:      stock.OutletID,
+				Quantity:      stock.Quantity,
+				MovementType:  "in",
+				ReferenceType: "initial",
+				Notes:         "Initial stock",
+				CreatedBy:     createdBy,
+				UnitCost:      &unitCost,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	product.ID = model.ID
@@ -36,26 +88,58 @@ func (r *productRepository) Create(ctx context.Context, product *domain.Product)
 	return nil
 }
 
-func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
+func (r *productRepository) Update(ctx context.Context, product *domain.Product, priceChange *domain.PriceChange) error {
 	model := &ProductModel{}
 	model.FromDomainProduct(product)
 
-	result := r.db.WithContext(ctx).
-		Where("id = ? AND tenant_id = ?", product.ID, product.TenantID).
-		Updates(model)
-
-	if result.Error != nil {
-		if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		taken, err := database.LockSKU(tx, product.TenantID, model.SKU, &product.ID, nil)
+		if err != nil {
+			return err
+		}
+		if taken {
 			return errors.New("product with this SKU already exists")
 		}
-		return fmt.Errorf("failed to update product: %w", result.Error)
-	}
+		if err := checkBarcode(tx, product.TenantID, model.Barcode, &product.ID, nil); err != nil {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return errors.New("product not found")
-	}
+		result := tx.
+			Where("id = ? AND tenant_id = ?", product.ID, product.TenantID).
+			Updates(model)
 
-	return nil
+		if result.Error != nil {
+			if isDuplicateBarcode(result.Error) {
+				return errors.New("barcode is already in use")
+			}
+			if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
+				return errors.New("product with this SKU already exists")
+			}
+			return fmt.Errorf("failed to update product: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("product not found")
+		}
+
+		if priceChange == nil {
+			return nil
+		}
+
+		// Updates skips zero fields, so a price changed to 0 is set here
+		err = tx.
+			Model(&ProductModel{}).
+			Where("id = ? AND tenant_id = ?", product.ID, product.TenantID).
+			Updates(map[string]interface{}{
+				"cost_price":    product.CostPrice,
+				"selling_price": product.SellingPrice,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update product price: %w", err)
+		}
+
+		return createPriceChanges(tx, []*domain.PriceChange{priceChange})
+	})
 }
 
 func (r *productRepository) Delete(ctx context.Context, tenantID, productID uint64) error {
@@ -104,28 +188,100 @@ func (r *productRepository) FindByID(ctx context.Context, tenantID, productID ui
 	return product, nil
 }
 
-func (r *productRepository) FindAll(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.Product, int64, error) {
+// FindAll lists the tenant's products matching the query. A search matches
+// prefixes of the words in the name, SKU, barcode and description, names
+// and category names resembling it despite typos, SKUs starting with it and
+// exact barcodes; results are ranked with exact SKU and barcode matches
+// first unless another sort is asked for.
+func (r *productRepository) FindAll(ctx context.Context, tenantID uint64, query domain.ProductQuery) ([]*domain.Product, int64, error) {
 	var models []ProductWithCategoryModel
 	var total int64
 
+	db := r.db.WithContext(ctx).
+		Table("products p").
+		Where("p.tenant_id = ?", tenantID)
+
+	search := strings.TrimSpace(query.Search)
+	prefix := prefixQuery(search)
+	args := map[string]interface{}{
+		"tenant_id": tenantID,
+		"search":    search,
+		"prefix":    prefix,
+		"like":      search + "%",
+	}
+	if search != "" {
+		match := "@search <% p.name OR p.sku ILIKE @like OR p.barcode = @search OR p.category_id IN (" +
+			"SELECT id FROM product_categories WHERE tenant_id = @tenant_id AND (name ILIKE @like OR @search <% name))"
+		if prefix != "" {
+			match = "p.search_vector @@ to_tsquery('simple', @prefix) OR " + match
+		}
+		db = db.Where(match, args)
+	}
+
+	// Apply filters
+	if query.CategoryID != nil {
+		db = db.Where("p.category_id = ?", *query.CategoryID)
+	}
+	if sku := strings.TrimSpace(query.SKU); sku != "" {
+		db = db.Where("p.sku ILIKE ?", "%"+sku+"%")
+	}
+	if barcode := strings.TrimSpace(query.Barcode); barcode != "" {
+		db = db.Where("p.barcode = ?", barcode)
+	}
+	if name := strings.TrimSpace(query.Name); name != "" {
+		db = db.Where("p.name ILIKE ?", "%"+name+"%")
+	}
+	if query.IsActive != nil {
+		db = db.Where("p.is_active = ?", *query.IsActive)
+	}
+	if query.TrackStock != nil {
+		db = db.Where("p.track_stock = ?", *query.TrackStock)
+	}
+	// Untracked products can always be sold, variants count for their product
+	if query.InStock && query.OutletID != nil {
+		db = db.Where("p.track_stock = ? OR EXISTS (SELECT 1 FROM product_stocks ps "+
+			"WHERE ps.product_id = p.id AND ps.outlet_id = ? AND ps.quantity > 0)", false, *query.OutletID)
+	}
+	if query.MinPrice != nil {
+		db = db.Where("p.selling_price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("p.selling_price <= ?", *query.MaxPrice)
+	}
+
 	// Count total records
-	err := r.db.WithContext(ctx).
-		Table("products").
-		Where("tenant_id = ?", tenantID).
-		Count(&total).Error
-	if err != nil {
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count products: %w", err)
 	}
 
-	// Get products with category information
-	err = r.db.WithContext(ctx).
-		Table("products p").
-		Select("p.*, pc.name as category_name").
-		Joins("LEFT JOIN product_categories pc ON p.category_id = pc.id").
-		Where("p.tenant_id = ?", tenantID).
-		Order("p.created_at DESC").
-		Limit(limit).
-		Offset(offset).
+	order := "ASC"
+	if strings.ToUpper(query.Order) == "DESC" {
+		order = "DESC"
+	}
+
+	fetch := db.Joins("LEFT JOIN product_categories pc ON p.category_id = pc.id")
+	switch query.Sort {
+	case "name", "sku", "selling_price", "created_at", "updated_at":
+		fetch = fetch.Select("p.*, pc.name as category_name").
+			Order(fmt.Sprintf("p.%s %s, p.id %s", query.Sort, order, order))
+	default:
+		if search == "" {
+			fetch = fetch.Select("p.*, pc.name as category_name").
+				Order("p.created_at DESC, p.id DESC")
+			break
+		}
+
+		rank := "CASE WHEN p.sku = @search OR p.barcode = @search THEN 2 ELSE 0 END + word_similarity(@search, p.name)"
+		if prefix != "" {
+			rank += " + ts_rank(p.search_vector, to_tsquery('simple', @prefix))"
+		}
+		fetch = fetch.Select("p.*, pc.name as category_name, "+rank+" AS search_rank", args).
+			Order("search_rank DESC, p.name ASC, p.id ASC")
+	}
+
+	err := fetch.
+		Limit(query.Limit).
+		Offset((query.Page - 1) * query.Limit).
 		Find(&models).Error
 
 	if err != nil {
@@ -148,6 +304,19 @@ func (r *productRepository) FindAll(ctx context.Context, tenantID uint64, limit,
 	return products, total, nil
 }
 
+// prefixQuery turns a search into a tsquery matching every word as a
+// prefix, e.g. "kopi sus" into "kopi:* & sus:*". Punctuation separates
+// words, so the search cannot inject tsquery operators.
+func prefixQuery(search string) string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
 func (r *productRepository) FindBySKU(ctx context.Context, tenantID uint64, sku string) (*domain.Product, error) {
 	var model ProductWithCategoryModel
 
@@ -266,6 +435,18 @@ func (r *productRepository) CheckSKUExists(ctx context.Context, tenantID uint64,
 	if err != nil {
 		return false, fmt.Errorf("failed to check SKU existence: %w", err)
 	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Variants share the tenant's SKUs with products
+	err = r.db.WithContext(ctx).
+		Model(&ProductVariantModel{}).
+		Where("tenant_id = ? AND sku = ?", tenantID, sku).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check SKU existence: %w", err)
+	}
 
 	return count > 0, nil
 }
@@ -283,3 +464,253 @@ func (r *productRepository) Count(ctx context.Context, tenantID uint64) (int64,
 
 	return count, nil
 }
+
+func (r *productRepository) FindActiveOutletIDs(ctx context.Context, tenantID uint64) ([]uint64, error) {
+	var outletIDs []uint64
+	err := r.db.WithContext(ctx).
+		Table("outlets").
+		Where("tenant_id = ? AND is_active = ?", tenantID, true).
+		Order("id ASC").
+		Pluck("id", &outletIDs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find outlets: %w", err)
+	}
+
+	return outletIDs, nil
+}
+
+func (r *productRepository) FindStocks(ctx context.Context, tenantID, productID uint64) ([]*domain.ProductStock, error) {
+	var models []OutletStockModel
+
+	err := r.db.WithContext(ctx).
+		Table("outlets o").
+		Select("o.id AS outlet_id, o.name AS outlet_name, o.code AS outlet_code, "+
+			"COALESCE(ps.quantity, 0) AS quantity, COALESCE(ps.reserved_quantity, 0) AS reserved_quantity, ps.updated_at").
+		Joins("LEFT JOIN product_stocks ps ON ps.outlet_id = o.id AND ps.product_id = ? AND ps.variant_id IS NULL", productID).
+		Where("o.tenant_id = ? AND (o.is_active = ? OR ps.id IS NOT NULL)", tenantID, true).
+		Order("o.name ASC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product stocks: %w", err)
+	}
+
+	stocks := make([]*domain.ProductStock, len(models))
+	for i := range models {
+		stocks[i] = models[i].ToDomainProductStock()
+	}
+
+	return stocks, nil
+}
+
+func (r *productRepository) FindBatch(ctx context.Context, tenantID, afterID uint64, limit int) ([]*domain.Product, error) {
+	var models []ProductModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id > ?", tenantID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+
+	products := make([]*domain.Product, len(models))
+	for i := range models {
+		products[i] = models[i].ToDomainProduct()
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) FindActiveOutlets(ctx context.Context, tenantID uint64) ([]*domain.Outlet, error) {
+	var models []OutletModel
+
+	err := r.db.WithContext(ctx).
+		Table("outlets").
+		Select("id, code, name").
+		Where("tenant_id = ? AND is_active = ?", tenantID, true).
+		Order("code ASC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find outlets: %w", err)
+	}
+
+	outlets := make([]*domain.Outlet, len(models))
+	for i := range models {
+		outlets[i] = &domain.Outlet{ID: models[i].ID, Code: models[i].Code, Name: models[i].Name}
+	}
+
+	return outlets, nil
+}
+
+func (r *productRepository) FindStockTotals(ctx context.Context, productIDs []uint64) (map[uint64]map[uint64]float64, error) {
+	totals := make(map[uint64]map[uint64]float64, len(productIDs))
+	if len(productIDs) == 0 {
+		return totals, nil
+	}
+
+	var models []StockTotalModel
+	err := r.db.WithContext(ctx).
+		Model(&ProductStockModel{}).
+		Select("product_id, outlet_id, SUM(quantity) AS quantity").
+		Where("product_id IN ?", productIDs).
+		Group("product_id, outlet_id").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum product stocks: %w", err)
+	}
+
+	for _, model := range models {
+		if totals[model.ProductID] == nil {
+			totals[model.ProductID] = make(map[uint64]float64)
+		}
+		totals[model.ProductID][model.OutletID] = model.Quantity
+	}
+
+	return totals, nil
+}
+
+func (r *productRepository) FindPriceChanges(ctx context.Context, tenantID, productID uint64, limit, offset int) ([]*domain.PriceChange, int64, error) {
+	query := r.db.WithContext(ctx).
+		Table("product_price_changes ppc").
+		Where("ppc.tenant_id = ? AND ppc.product_id = ?", tenantID, productID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count price changes: %w", err)
+	}
+
+	var models []PriceChangeRowModel
+	err := query.
+		Select("ppc.*, p.sku AS product_sku, p.name AS product_name, u.full_name AS changed_by_name").
+		Joins("JOIN products p ON p.id = ppc.product_id").
+		Joins("LEFT JOIN users u ON u.id = ppc.changed_by").
+		Order("ppc.effective_at DESC, ppc.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find price changes: %w", err)
+	}
+
+	changes := make([]*domain.PriceChange, len(models))
+	for i := range models {
+		changes[i] = models[i].ToDomainPriceChange()
+	}
+
+	return changes, total, nil
+}
+
+func (r *productRepository) FindActiveByCategory(ctx context.Context, tenantID, categoryID uint64, includeSubcategories bool) ([]*domain.Product, error) {
+	query := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND is_active = ?", tenantID, true)
+
+	if includeSubcategories {
+		query = query.Where("category_id IN (WITH RECURSIVE tree AS ("+
+			"SELECT id FROM product_categories WHERE id = ? AND tenant_id = ? "+
+			"UNION SELECT pc.id FROM product_categories pc JOIN tree ON pc.parent_id = tree.id"+
+			") SELECT id FROM tree)", categoryID, tenantID)
+	} else {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var models []ProductModel
+	if err := query.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find products by category: %w", err)
+	}
+
+	products := make([]*domain.Product, len(models))
+	for i := range models {
+		products[i] = models[i].ToDomainProduct()
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) UpdatePrices(ctx context.Context, changes []*domain.PriceChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, change := range changes {
+			result := tx.
+				Model(&ProductModel{}).
+				Where("id = ? AND tenant_id = ?", change.ProductID, change.TenantID).
+				Updates(map[string]interface{}{
+					"cost_price":    change.NewCostPrice,
+					"selling_price": change.NewSellingPrice,
+					"updated_at":    now,
+				})
+
+			if result.Error != nil {
+				return fmt.Errorf("failed to update product price: %w", result.Error)
+			}
+
+			if result.RowsAffected == 0 {
+				return errors.New("product not found")
+			}
+		}
+
+		return createPriceChanges(tx, changes)
+	})
+}
+
+// createPriceChanges records the changes, filling in their IDs
+func createPriceChanges(tx *gorm.DB, changes []*domain.PriceChange) error {
+	models := make([]*PriceChangeModel, len(changes))
+	for i, change := range changes {
+		models[i] = &PriceChangeModel{}
+		models[i].FromDomainPriceChange(change)
+	}
+
+	if err := tx.CreateInBatches(models, 500).Error; err != nil {
+		return fmt.Errorf("failed to record price changes: %w", err)
+	}
+
+	for i, model := range models {
+		changes[i].ID = model.ID
+		changes[i].CreatedAt = model.CreatedAt
+	}
+
+	return nil
+}
+
+func (r *productRepository) FindNumberFormat(ctx context.Context, tenantID uint64) (*domain.NumberFormat, error) {
+	var row struct {
+		DecimalSeparator   string
+		ThousandsSeparator string
+		DecimalPlaces      int
+		CurrencySymbol     string
+	}
+
+	// Tenants that never saved settings use the default number format
+	err := r.db.WithContext(ctx).
+		Table("tenants").
+		Select("COALESCE(NULLIF(settings->'number_format'->>'decimal_separator', ''), ',') AS decimal_separator, "+
+			"COALESCE(settings->'number_format'->>'thousands_separator', '.') AS thousands_separator, "+
+			"COALESCE((settings->'number_format'->>'decimal_places')::int, 0) AS decimal_places, "+
+			"COALESCE(settings->'number_format'->>'currency_symbol', 'Rp') AS currency_symbol").
+		Where("id = ?", tenantID).
+		Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to find number format: %w", err)
+	}
+
+	return &domain.NumberFormat{
+		DecimalSeparator:   row.DecimalSeparator,
+		ThousandsSeparator: row.ThousandsSeparator,
+		DecimalPlaces:      row.DecimalPlaces,
+		CurrencySymbol:     row.CurrencySymbol,
+	}, nil
+}
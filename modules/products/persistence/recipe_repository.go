@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type recipeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecipeRepository(db *gorm.DB) domain.RecipeRepository {
+	return &recipeRepository{db: db}
+}
+
+func (r *recipeRepository) FindByProduct(ctx context.Context, productID uint64) ([]*domain.RecipeItem, error) {
+	var models []RecipeItemRowModel
+
+	err := r.db.WithContext(ctx).
+		Table("product_recipe_items ri").
+		Select("ri.*, c.sku AS component_sku, c.name AS component_name, c.unit AS component_unit, "+
+			"c.cost_price AS component_cost_price, c.track_stock AS component_track_stock").
+		Joins("JOIN products c ON c.id = ri.component_id").
+		Where("ri.product_id = ?", productID).
+		Order("c.name ASC, ri.id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recipe: %w", err)
+	}
+
+	items := make([]*domain.RecipeItem, len(models))
+	for i := range models {
+		items[i] = models[i].ToDomainRecipeItem()
+	}
+
+	return items, nil
+}
+
+func (r *recipeRepository) Replace(ctx context.Context, productID uint64, items []*domain.RecipeItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent edits of the same recipe
+		var product ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", productID).
+			Take(&product).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductRecipeItemModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recipe: %w", err)
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		models := make([]ProductRecipeItemModel, len(items))
+		for i, item := range items {
+			models[i] = ProductRecipeItemModel{
+				ProductID:   productID,
+				ComponentID: item.ComponentID,
+				Quantity:    item.Quantity,
+			}
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return fmt.Errorf("failed to create recipe: %w", err)
+		}
+
+		for i := range models {
+			items[i].ID = models[i].ID
+			items[i].ProductID = productID
+		}
+
+		return nil
+	})
+}
+
+func (r *recipeRepository) FindComponents(ctx context.Context, tenantID uint64, componentIDs []uint64) (map[uint64]*domain.Product, error) {
+	var models []ProductModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, componentIDs).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find components: %w", err)
+	}
+
+	components := make(map[uint64]*domain.Product, len(models))
+	for i := range models {
+		components[models[i].ID] = models[i].ToDomainProduct()
+	}
+
+	return components, nil
+}
+
+func (r *recipeRepository) HasRecipe(ctx context.Context, productIDs []uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ProductRecipeItemModel{}).
+		Where("product_id IN ?", productIDs).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check recipes: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *recipeRepository) IsComponent(ctx context.Context, productID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ProductRecipeItemModel{}).
+		Where("component_id = ?", productID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check recipe components: %w", err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,220 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type unitRepository struct {
+	db *gorm.DB
+}
+
+func NewUnitRepository(db *gorm.DB) domain.UnitRepository {
+	return &unitRepository{db: db}
+}
+
+func (r *unitRepository) Create(ctx context.Context, unit *domain.Unit) error {
+	model := &UnitModel{}
+	model.FromDomainUnit(unit)
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create unit: %w", err)
+	}
+
+	unit.ID = model.ID
+	unit.CreatedAt = model.CreatedAt
+	unit.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+func (r *unitRepository) Update(ctx context.Context, unit *domain.Unit) error {
+	result := r.db.WithContext(ctx).
+		Model(&UnitModel{}).
+		Where("id = ? AND tenant_id = ?", unit.ID, unit.TenantID).
+		Updates(map[string]interface{}{
+			"name":          unit.Name,
+			"allow_decimal": unit.AllowDecimal,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update unit: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("unit not found")
+	}
+
+	return nil
+}
+
+func (r *unitRepository) Delete(ctx context.Context, tenantID, unitID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model UnitModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", unitID, tenantID).
+			Take(&model).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("unit not found")
+			}
+			return fmt.Errorf("failed to lock unit: %w", err)
+		}
+
+		var count int64
+		err = tx.Model(&ProductUnitModel{}).
+			Where("unit_id = ?", model.ID).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check product units: %w", err)
+		}
+		if count == 0 {
+			err = tx.Model(&ProductModel{}).
+				Where("tenant_id = ? AND unit = ?", tenantID, model.Code).
+				Count(&count).Error
+			if err != nil {
+				return fmt.Errorf("failed to check product base units: %w", err)
+			}
+		}
+		if count > 0 {
+			return errors.New("unit is used by a product")
+		}
+
+		if err := tx.Delete(&model).Error; err != nil {
+			return fmt.Errorf("failed to delete unit: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *unitRepository) FindByID(ctx context.Context, tenantID, unitID uint64) (*domain.Unit, error) {
+	var model UnitModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", unitID, tenantID).
+		Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unit not found")
+		}
+		return nil, fmt.Errorf("failed to find unit: %w", err)
+	}
+
+	return model.ToDomainUnit(), nil
+}
+
+func (r *unitRepository) FindAll(ctx context.Context, tenantID uint64) ([]*domain.Unit, error) {
+	var models []UnitModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("code ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find units: %w", err)
+	}
+
+	units := make([]*domain.Unit, len(models))
+	for i := range models {
+		units[i] = models[i].ToDomainUnit()
+	}
+
+	return units, nil
+}
+
+func (r *unitRepository) FindByCodes(ctx context.Context, tenantID uint64, codes []string) (map[string]*domain.Unit, error) {
+	units := make(map[string]*domain.Unit, len(codes))
+	if len(codes) == 0 {
+		return units, nil
+	}
+
+	var models []UnitModel
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND code IN ?", tenantID, codes).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find units: %w", err)
+	}
+
+	for i := range models {
+		units[models[i].Code] = models[i].ToDomainUnit()
+	}
+
+	return units, nil
+}
+
+func (r *unitRepository) CodeExists(ctx context.Context, tenantID uint64, code string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&UnitModel{}).
+		Where("tenant_id = ? AND code = ?", tenantID, code).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check unit code: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *unitRepository) FindProductUnits(ctx context.Context, productID uint64) ([]*domain.ProductUnit, error) {
+	var models []ProductUnitRowModel
+
+	err := r.db.WithContext(ctx).
+		Table("product_units pu").
+		Select("pu.*, u.code, u.name, u.allow_decimal").
+		Joins("JOIN units u ON u.id = pu.unit_id").
+		Where("pu.product_id = ?", productID).
+		Order("pu.factor ASC, u.code ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product units: %w", err)
+	}
+
+	units := make([]*domain.ProductUnit, len(models))
+	for i := range models {
+		units[i] = models[i].ToDomainProductUnit()
+	}
+
+	return units, nil
+}
+
+func (r *unitRepository) ReplaceProductUnits(ctx context.Context, productID uint64, units []*domain.ProductUnit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent edits of the product's units
+		var product ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", productID).
+			Take(&product).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&ProductUnitModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete product units: %w", err)
+		}
+		if len(units) == 0 {
+			return nil
+		}
+
+		models := make([]ProductUnitModel, len(units))
+		for i, unit := range units {
+			models[i] = ProductUnitModel{ProductID: productID, UnitID: unit.UnitID, Factor: unit.Factor}
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return fmt.Errorf("failed to create product units: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,287 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) domain.VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func (r *variantRepository) FindByID(ctx context.Context, tenantID, productID, variantID uint64) (*domain.ProductVariant, error) {
+	var model ProductVariantModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND product_id = ? AND tenant_id = ?", variantID, productID, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, fmt.Errorf("failed to find variant: %w", err)
+	}
+
+	return model.ToDomainVariant(), nil
+}
+
+func (r *variantRepository) FindByProduct(ctx context.Context, productID uint64) ([]*domain.ProductVariant, error) {
+	var models []ProductVariantModel
+
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find variants: %w", err)
+	}
+
+	variants := make([]*domain.ProductVariant, len(models))
+	for i := range models {
+		variants[i] = models[i].ToDomainVariant()
+	}
+
+	return variants, nil
+}
+
+func (r *variantRepository) FindByBarcode(ctx context.Context, tenantID uint64, barcode string) (*domain.ProductVariant, error) {
+	var model ProductVariantModel
+
+	err := r.db.WithContext(ctx).
+		Where("barcode = ? AND tenant_id = ?", barcode, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, fmt.Errorf("failed to find variant by barcode: %w", err)
+	}
+
+	return model.ToDomainVariant(), nil
+}
+
+// FindStocks lists the stock of every variant at the tenant's active
+// outlets, and at inactive outlets still holding a stock row
+func (r *variantRepository) FindStocks(ctx context.Context, tenantID uint64, variantIDs []uint64) (map[uint64][]*domain.ProductStock, error) {
+	stocks := make(map[uint64][]*domain.ProductStock, len(variantIDs))
+	if len(variantIDs) == 0 {
+		return stocks, nil
+	}
+
+	var models []VariantStockModel
+	err := r.db.WithContext(ctx).
+		Table("product_variants v").
+		Select("v.id AS variant_id, o.id AS outlet_id, o.name AS outlet_name, o.code AS outlet_code, "+
+			"COALESCE(ps.quantity, 0) AS quantity, COALESCE(ps.reserved_quantity, 0) AS reserved_quantity, ps.updated_at").
+		Joins("JOIN outlets o ON o.tenant_id = v.tenant_id").
+		Joins("LEFT JOIN product_stocks ps ON ps.variant_id = v.id AND ps.outlet_id = o.id").
+		Where("v.tenant_id = ? AND v.id IN ? AND (o.is_active = ? OR ps.id IS NOT NULL)", tenantID, variantIDs, true).
+		Order("v.id ASC, o.name ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find variant stocks: %w", err)
+	}
+
+	for i := range models {
+		stocks[models[i].VariantID] = append(stocks[models[i].VariantID], models[i].ToDomainProductStock())
+	}
+
+	return stocks, nil
+}
+
+func (r *variantRepository) Sync(ctx context.Context, product *domain.Product, variants []*domain.ProductVariant, stockOutletIDs []uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent generation for the same product
+		var locked ProductModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", product.ID).
+			Take(&locked).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+
+		options := make(JSONVariantsModel, len(product.Variants))
+		for name, values := range product.Variants {
+			options[name] = values
+		}
+		err = tx.Model(&ProductModel{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+			"variants":   options,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update product options: %w", err)
+		}
+
+		for _, variant := range variants {
+			model := &ProductVariantModel{}
+			model.FromDomainVariant(variant)
+
+			if variant.ID == 0 {
+				taken, err := database.LockSKU(tx, product.TenantID, model.SKU, nil, nil)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return fmt.Errorf("variant SKU %s already exists", variant.SKU)
+				}
+			}
+
+			if variant.ID != 0 {
+				err := tx.Model(model).Updates(map[string]interface{}{
+					"is_active":  model.IsActive,
+					"updated_at": time.Now(),
+				}).Error
+				if err != nil {
+					return fmt.Errorf("failed to update variant: %w", err)
+				}
+				continue
+			}
+
+			if err := tx.Create(model).Error; err != nil {
+				if isDuplicateKey(err) {
+					return fmt.Errorf("variant SKU %s already exists", variant.SKU)
+				}
+				return fmt.Errorf("failed to create variant: %w", err)
+			}
+			variant.ID = model.ID
+			variant.CreatedAt = model.CreatedAt
+			variant.UpdatedAt = model.UpdatedAt
+
+			for _, outletID := range stockOutletIDs {
+				stock := &ProductStockModel{
+					ProductID: product.ID,
+					VariantID: &model.ID,
+					OutletID:  outletID,
+				}
+				if err := tx.Create(stock).Error; err != nil {
+					return fmt.Errorf("failed to create variant stock: %w", err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	model := &ProductVariantModel{}
+	model.FromDomainVariant(variant)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		taken, err := database.LockSKU(tx, variant.TenantID, model.SKU, nil, &variant.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("variant with this SKU already exists")
+		}
+		if err := checkBarcode(tx, variant.TenantID, model.Barcode, nil, &variant.ID); err != nil {
+			return err
+		}
+
+		err = tx.Model(model).Updates(map[string]interface{}{
+			"sku":           model.SKU,
+			"barcode":       model.Barcode,
+			"selling_price": model.SellingPrice,
+			"is_active":     model.IsActive,
+			"updated_at":    time.Now(),
+		}).Error
+		if err != nil {
+			if isDuplicateBarcode(err) {
+				return errors.New("barcode is already in use")
+			}
+			if isDuplicateKey(err) {
+				return errors.New("variant with this SKU already exists")
+			}
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *variantRepository) Delete(ctx context.Context, variantID uint64) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ?", variantID).
+		Delete(&ProductVariantModel{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete variant: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("variant not found")
+	}
+
+	return nil
+}
+
+func (r *variantRepository) HasMovements(ctx context.Context, variantID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&StockMovementModel{}).
+		Where("variant_id = ?", variantID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check variant stock movements: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// CheckSKUExists looks for the SKU among the tenant's variants, other than
+// excludeID, and its products
+func (r *variantRepository) CheckSKUExists(ctx context.Context, tenantID uint64, sku string, excludeID *uint64) (bool, error) {
+	query := r.db.WithContext(ctx).
+		Model(&ProductVariantModel{}).
+		Where("tenant_id = ? AND sku = ?", tenantID, sku)
+
+	if excludeID != nil {
+		query = query.Where("id != ?", *excludeID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check SKU existence: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&ProductModel{}).
+		Where("tenant_id = ? AND sku = ?", tenantID, sku).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check SKU existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func isDuplicateKey(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint")
+}
+
+// isDuplicateBarcode reports whether a unique barcode index refused err's
+// write, whose constraint names end in _barcode
+func isDuplicateBarcode(err error) bool {
+	return isDuplicateKey(err) && strings.Contains(err.Error(), "_barcode")
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+func (s *productService) AssignBarcodes(ctx context.Context, tenantID uint64, req domain.AssignBarcodesRequest) ([]*domain.Product, error) {
+	settings, err := s.barcodeRepo.FindSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := domain.BarcodeScheme{Prefix: settings.Prefix, Format: settings.Format}
+	if req.Format != "" {
+		scheme.Format = req.Format
+	}
+
+	return s.barcodeRepo.Assign(ctx, tenantID, uniqueIDs(req.ProductIDs), scheme)
+}
+
+// autoAssignBarcode gives a new product without a barcode one, when the
+// tenant's barcode settings ask for it
+func (s *productService) autoAssignBarcode(ctx context.Context, tenantID, productID uint64) error {
+	settings, err := s.barcodeRepo.FindSettings(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !settings.AutoAssign {
+		return nil
+	}
+
+	scheme := domain.BarcodeScheme{Prefix: settings.Prefix, Format: settings.Format}
+	_, err = s.barcodeRepo.Assign(ctx, tenantID, []uint64{productID}, scheme)
+	return err
+}
+
+// checkBarcode rejects a barcode another product or variant of the tenant
+// already has
+func (s *productService) checkBarcode(ctx context.Context, tenantID uint64, barcode string, productID, variantID *uint64) error {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil
+	}
+
+	exists, err := s.barcodeRepo.Exists(ctx, tenantID, barcode, productID, variantID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("barcode is already in use")
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+func (s *productService) GetBundle(ctx context.Context, tenantID, productID uint64) (*domain.Bundle, error) {
+	product, err := s.productRepo.FindByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := s.bundleRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	bundle.SellingPrice = product.SellingPrice
+
+	return bundle, nil
+}
+
+// SetBundle replaces the product's bundle. A bundle holds no stock of its
+// own: selling it takes its components from stock instead. Bundles are a
+// single level deep and do not mix with recipes on the same product.
+func (s *productService) SetBundle(ctx context.Context, tenantID, productID uint64, req domain.SetBundleRequest) (*domain.Bundle, error) {
+	product, err := s.productRepo.FindByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.TrackStock {
+		return nil, errors.New("bundle products cannot track stock")
+	}
+
+	hasRecipe, err := s.recipeRepo.HasRecipe(ctx, []uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+	if hasRecipe {
+		return nil, errors.New("product has a recipe and cannot be a bundle")
+	}
+
+	inRecipe, err := s.recipeRepo.IsComponent(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	inBundle, err := s.bundleRepo.IsComponent(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	inModifier, err := s.modifierRepo.IsModifierProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if inRecipe || inBundle || inModifier {
+		return nil, errors.New("product is a component and cannot be a bundle")
+	}
+
+	if len(req.Items) == 0 && len(req.Groups) == 0 {
+		return nil, errors.New("bundle has no components")
+	}
+
+	var componentIDs []uint64
+	collect := func(items []domain.BundleItemRequest) error {
+		seen := make(map[[2]uint64]bool, len(items))
+		for _, item := range items {
+			if item.ComponentID == productID {
+				return errors.New("product cannot be a component of its own bundle")
+			}
+			key := [2]uint64{item.ComponentID, 0}
+			if item.VariantID != nil {
+				key[1] = *item.VariantID
+			}
+			if seen[key] {
+				return errors.New("duplicate component in bundle")
+			}
+			seen[key] = true
+			componentIDs = append(componentIDs, item.ComponentID)
+		}
+		return nil
+	}
+
+	if err := collect(req.Items); err != nil {
+		return nil, err
+	}
+	for _, group := range req.Groups {
+		if group.PickCount > len(group.Items) {
+			return nil, errors.New("bundle group has fewer choices than it picks")
+		}
+		if err := collect(group.Items); err != nil {
+			return nil, err
+		}
+	}
+	componentIDs = uniqueIDs(componentIDs)
+
+	components, err := s.recipeRepo.FindComponents(ctx, tenantID, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) != len(componentIDs) {
+		return nil, errors.New("component not found")
+	}
+
+	nested, err := s.bundleRepo.HasBundle(ctx, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if nested {
+		return nil, errors.New("component is a bundle itself")
+	}
+
+	toItems := func(requests []domain.BundleItemRequest) ([]*domain.BundleItem, error) {
+		items := make([]*domain.BundleItem, len(requests))
+		for i, request := range requests {
+			items[i] = &domain.BundleItem{
+				ComponentID: request.ComponentID,
+				VariantID:   request.VariantID,
+				Quantity:    request.Quantity,
+				Component:   components[request.ComponentID],
+			}
+			if request.VariantID != nil {
+				variant, err := s.variantRepo.FindByID(ctx, tenantID, request.ComponentID, *request.VariantID)
+				if err != nil {
+					return nil, err
+				}
+				items[i].Variant = variant
+			}
+		}
+		return items, nil
+	}
+
+	bundle := &domain.Bundle{ProductID: productID}
+	if bundle.Items, err = toItems(req.Items); err != nil {
+		return nil, err
+	}
+	for _, request := range req.Groups {
+		group := &domain.BundleGroup{
+			Name:      request.Name,
+			PickCount: request.PickCount,
+		}
+		if group.PickCount == 0 {
+			group.PickCount = 1
+		}
+		if group.Items, err = toItems(request.Items); err != nil {
+			return nil, err
+		}
+		bundle.Groups = append(bundle.Groups, group)
+	}
+
+	if err := s.bundleRepo.Replace(ctx, productID, bundle); err != nil {
+		return nil, err
+	}
+
+	return s.GetBundle(ctx, tenantID, productID)
+}
+
+func (s *productService) DeleteBundle(ctx context.Context, tenantID, productID uint64) error {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return err
+	}
+
+	return s.bundleRepo.Replace(ctx, productID, nil)
+}
+
+// GetBundleSales sums the components sold through the bundle, with their
+// share of the bundle amounts
+func (s *productService) GetBundleSales(ctx context.Context, tenantID, productID uint64, query domain.BundleSalesQuery) ([]*domain.BundleComponentSales, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	if query.DateFrom != nil && query.DateTo != nil && query.DateFrom.After(*query.DateTo) {
+		return nil, errors.New("date from must not be after date to")
+	}
+
+	return s.bundleRepo.FindComponentSales(ctx, tenantID, productID, query)
+}
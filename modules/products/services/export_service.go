@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/utils/spreadsheet"
+)
+
+// exportBatchSize is how many products are read per query while exporting
+const exportBatchSize = 500
+
+// categoryPathSeparator joins category names from the root down, the same
+// form the product import accepts
+const categoryPathSeparator = " > "
+
+// Export writes the product catalogue in the layout the product import
+// reads, followed by a stock column per active outlet. Rows are read in
+// batches and written as they come, so large catalogues are never held in
+// memory.
+func (s *productService) Export(ctx context.Context, tenantID uint64, format string, w io.Writer) error {
+	categories, _, err := s.categoryRepo.FindAll(ctx, tenantID, domain.ProductCategoryQuery{})
+	if err != nil {
+		return err
+	}
+	paths := categoryPaths(categories)
+
+	outlets, err := s.productRepo.FindActiveOutlets(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	writer, err := spreadsheet.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	header := []interface{}{
+		"sku", "name", "barcode", "category", "description", "unit",
+		"cost_price", "selling_price", "min_stock", "track_stock", "is_active", "images",
+	}
+	for _, outlet := range outlets {
+		header = append(header, "stock_"+outlet.Code)
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return err
+	}
+
+	var afterID uint64
+	for {
+		products, err := s.productRepo.FindBatch(ctx, tenantID, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		productIDs := make([]uint64, len(products))
+		for i, product := range products {
+			productIDs[i] = product.ID
+		}
+		stocks, err := s.productRepo.FindStockTotals(ctx, productIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			var category string
+			if product.CategoryID != nil {
+				category = paths[*product.CategoryID]
+			}
+
+			row := []interface{}{
+				product.SKU, product.Name, product.Barcode, category, product.Description, product.Unit,
+				product.CostPrice, product.SellingPrice, product.MinStock, product.TrackStock, product.IsActive,
+				strings.Join(product.Images, ", "),
+			}
+			for _, outlet := range outlets {
+				if product.TrackStock {
+					row = append(row, stocks[product.ID][outlet.ID])
+				} else {
+					row = append(row, nil)
+				}
+			}
+
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+		}
+
+		afterID = products[len(products)-1].ID
+	}
+
+	return writer.Close()
+}
+
+// categoryPaths maps every category to its path from the root category,
+// e.g. "Minuman > Kopi"
+func categoryPaths(categories []*domain.ProductCategory) map[uint64]string {
+	byID := make(map[uint64]*domain.ProductCategory, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	paths := make(map[uint64]string, len(categories))
+	for _, category := range categories {
+		names := []string{category.Name}
+		seen := map[uint64]bool{category.ID: true}
+		for parent := category.ParentID; parent != nil && !seen[*parent]; {
+			next, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			seen[next.ID] = true
+			names = append([]string{next.Name}, names...)
+			parent = next.ParentID
+		}
+		paths[category.ID] = strings.Join(names, categoryPathSeparator)
+	}
+
+	return paths
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/infrastructure/storage"
+	"github.com/exven/pos-system/shared/utils/imaging"
+)
+
+// maxImagePixels caps the decoded size of an upload, since a small file can
+// hold a huge image
+const maxImagePixels = 40_000_000
+
+// imageFormats maps the sniffed content types accepted for upload to their
+// image format and file extension
+var imageFormats = map[string][2]string{
+	"image/jpeg": {imaging.FormatJPEG, "jpg"},
+	"image/png":  {imaging.FormatPNG, "png"},
+	"image/gif":  {imaging.FormatGIF, "gif"},
+}
+
+type imageService struct {
+	imageRepo    domain.ImageRepository
+	productRepo  domain.ProductRepository
+	categoryRepo domain.ProductCategoryRepository
+	storage      storage.Storage
+	maxSize      int64
+}
+
+func NewImageService(
+	imageRepo domain.ImageRepository,
+	productRepo domain.ProductRepository,
+	categoryRepo domain.ProductCategoryRepository,
+	fileStorage storage.Storage,
+	maxSize int64,
+) domain.ImageService {
+	return &imageService{
+		imageRepo:    imageRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		storage:      fileStorage,
+		maxSize:      maxSize,
+	}
+}
+
+func (s *imageService) UploadProductImage(ctx context.Context, tenantID, userID, productID uint64, r io.Reader) (*domain.Image, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.imageRepo.CountByProduct(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxProductImages {
+		return nil, errors.New("product image limit reached")
+	}
+
+	img, err := s.store(ctx, fmt.Sprintf("tenants/%d/products/%d", tenantID, productID), r)
+	if err != nil {
+		return nil, err
+	}
+	img.TenantID = tenantID
+	img.ProductID = &productID
+	img.CreatedBy = userID
+
+	if err := s.imageRepo.CreateForProduct(ctx, img); err != nil {
+		s.removeFiles(ctx, img)
+		return nil, err
+	}
+
+	return img, nil
+}
+
+func (s *imageService) GetProductImages(ctx context.Context, tenantID, productID uint64) ([]*domain.Image, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.imageRepo.FindByProduct(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, img := range images {
+		s.setURLs(img)
+	}
+	return images, nil
+}
+
+func (s *imageService) DeleteProductImage(ctx context.Context, tenantID, productID, imageID uint64) error {
+	img, err := s.imageRepo.FindByID(ctx, tenantID, imageID)
+	if err != nil {
+		return err
+	}
+	if img.ProductID == nil || *img.ProductID != productID {
+		return errors.New("image not found")
+	}
+	s.setURLs(img)
+
+	if err := s.imageRepo.DeleteFromProduct(ctx, img); err != nil {
+		return err
+	}
+
+	s.removeFiles(ctx, img)
+	return nil
+}
+
+func (s *imageService) UploadCategoryImage(ctx context.Context, tenantID, userID, categoryID uint64, r io.Reader) (*domain.Image, error) {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return nil, err
+	}
+
+	img, err := s.store(ctx, fmt.Sprintf("tenants/%d/categories/%d", tenantID, categoryID), r)
+	if err != nil {
+		return nil, err
+	}
+	img.TenantID = tenantID
+	img.CategoryID = &categoryID
+	img.CreatedBy = userID
+
+	replaced, err := s.imageRepo.ReplaceForCategory(ctx, img)
+	if err != nil {
+		s.removeFiles(ctx, img)
+		return nil, err
+	}
+
+	for _, old := range replaced {
+		s.removeFiles(ctx, old)
+	}
+
+	return img, nil
+}
+
+func (s *imageService) DeleteCategoryImage(ctx context.Context, tenantID, categoryID uint64) error {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return err
+	}
+
+	deleted, err := s.imageRepo.DeleteFromCategory(ctx, tenantID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	for _, img := range deleted {
+		s.removeFiles(ctx, img)
+	}
+	return nil
+}
+
+// store checks an upload is a JPEG, PNG or GIF image within the size limits,
+// then stores it under dir with a thumbnail of every size. The image returned
+// has its storage keys and URLs set.
+func (s *imageService) store(ctx context.Context, dir string, r io.Reader) (*domain.Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.New("image is too large")
+	}
+
+	// The content type is sniffed from the data; the client's claim is not
+	// trusted
+	contentType := http.DetectContentType(data)
+	format, ok := imageFormats[contentType]
+	if !ok {
+		return nil, errors.New("unsupported image type")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, errors.New("image dimensions are too large")
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	img := &domain.Image{
+		StorageKey:  fmt.Sprintf("%s/%s.%s", dir, token, format[1]),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       config.Width,
+		Height:      config.Height,
+		Thumbnails:  make(map[string]string, len(domain.ThumbnailSizes)),
+	}
+
+	if err := s.storage.Put(ctx, img.StorageKey, data, contentType); err != nil {
+		return nil, err
+	}
+
+	for _, size := range domain.ThumbnailSizes {
+		thumbnail, thumbnailType, ext, err := imaging.Encode(imaging.Thumbnail(decoded, size.Size), format[0])
+		if err == nil {
+			key := fmt.Sprintf("%s/%s_%s.%s", dir, token, size.Name, ext)
+			err = s.storage.Put(ctx, key, thumbnail, thumbnailType)
+			if err == nil {
+				img.Thumbnails[size.Name] = key
+			}
+		}
+		if err != nil {
+			s.removeFiles(ctx, img)
+			return nil, err
+		}
+	}
+
+	s.setURLs(img)
+	return img, nil
+}
+
+func (s *imageService) setURLs(img *domain.Image) {
+	img.URL = s.storage.URL(img.StorageKey)
+	img.ThumbnailURLs = make(map[string]string, len(img.Thumbnails))
+	for name, key := range img.Thumbnails {
+		img.ThumbnailURLs[name] = s.storage.URL(key)
+	}
+}
+
+// removeFiles deletes an image's files from storage. Failures are ignored:
+// the image is already gone from the catalogue and a file left behind only
+// takes up space.
+func (s *imageService) removeFiles(ctx context.Context, img *domain.Image) {
+	s.storage.Delete(ctx, img.StorageKey)
+	for _, key := range img.Thumbnails {
+		s.storage.Delete(ctx, key)
+	}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate file name: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
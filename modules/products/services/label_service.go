@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/exven/pos-system/modules/products/domain"
+	"github.com/exven/pos-system/shared/utils/barcode"
+	"github.com/exven/pos-system/shared/utils/pdf"
+)
+
+// maxLabels caps the labels of one sheet request, copies included
+const maxLabels = 5000
+
+// labelTemplate lays labels out in a grid on A4 sheets. Lengths are in
+// millimetres and font sizes in points.
+type labelTemplate struct {
+	columns       int
+	rows          int
+	width         float64
+	height        float64
+	marginLeft    float64
+	marginTop     float64
+	gapX          float64
+	padding       float64
+	nameFont      pdf.Font
+	nameSize      float64
+	nameLines     int
+	priceSize     float64
+	barcodeHeight float64
+	codeSize      float64
+}
+
+var labelTemplates = map[string]labelTemplate{
+	// 70 x 37 mm, 24 to a sheet
+	domain.LabelTemplateShelf: {
+		columns: 3, rows: 8, width: 70, height: 37, marginTop: 0.5,
+		padding: 3, nameFont: pdf.HelveticaBold, nameSize: 9, nameLines: 2,
+		priceSize: 16, barcodeHeight: 10, codeSize: 6,
+	},
+	// 38.1 x 21.2 mm, 65 to a sheet
+	domain.LabelTemplatePriceTag: {
+		columns: 5, rows: 13, width: 38.1, height: 21.2, marginLeft: 4.75, marginTop: 10.7, gapX: 2.54,
+		padding: 1.5, nameFont: pdf.Helvetica, nameSize: 6.5, nameLines: 1,
+		priceSize: 11, barcodeHeight: 5.5, codeSize: 5,
+	},
+}
+
+// maxModuleWidth is the nominal width of the narrowest bar, in millimetres
+const maxModuleWidth = 0.33
+
+type labelService struct {
+	productRepo      domain.ProductRepository
+	variantRepo      domain.VariantRepository
+	priceListService domain.PriceListService
+}
+
+func NewLabelService(productRepo domain.ProductRepository, variantRepo domain.VariantRepository, priceListService domain.PriceListService) domain.LabelService {
+	return &labelService{
+		productRepo:      productRepo,
+		variantRepo:      variantRepo,
+		priceListService: priceListService,
+	}
+}
+
+func (s *labelService) RenderLabels(ctx context.Context, tenantID uint64, req domain.PrintLabelsRequest) ([]byte, error) {
+	template, ok := labelTemplates[req.Template]
+	if !ok {
+		return nil, errors.New("unknown label template")
+	}
+
+	total := 0
+	priceReq := domain.ResolvePricesRequest{OutletID: req.OutletID}
+	for _, item := range req.Items {
+		total += copies(item)
+		priceReq.Items = append(priceReq.Items, domain.PriceItemRequest{ProductID: item.ProductID, VariantID: item.VariantID})
+	}
+	if total > maxLabels {
+		return nil, errors.New("too many labels")
+	}
+
+	// Resolving prices also checks the outlet, products and variants
+	prices, err := s.priceListService.ResolvePrices(ctx, tenantID, priceReq)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := s.productRepo.FindNumberFormat(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[uint64]*domain.Product)
+	var labels []domain.Label
+	for i, item := range req.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			product, err = s.productRepo.FindByID(ctx, tenantID, item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			products[item.ProductID] = product
+		}
+
+		label := domain.Label{
+			Name:    product.Name,
+			SKU:     product.SKU,
+			Barcode: product.Barcode,
+			Price:   format.Format(prices[i].Price),
+		}
+		if item.VariantID != nil {
+			variant, err := s.variantRepo.FindByID(ctx, tenantID, item.ProductID, *item.VariantID)
+			if err != nil {
+				return nil, err
+			}
+			label.Name = product.Name + " - " + variant.Name
+			label.SKU = variant.SKU
+			label.Barcode = variant.Barcode
+		}
+
+		for n := 0; n < copies(item); n++ {
+			labels = append(labels, label)
+		}
+	}
+
+	return renderSheet(template, labels)
+}
+
+// renderSheet lays the labels out on as many A4 sheets as they fill
+func renderSheet(template labelTemplate, labels []domain.Label) ([]byte, error) {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+	perPage := template.columns * template.rows
+	var page *pdf.Page
+	for i, label := range labels {
+		if i%perPage == 0 {
+			page = doc.AddPage()
+		}
+		cell := i % perPage
+		x := (template.marginLeft + float64(cell%template.columns)*(template.width+template.gapX)) * pdf.MM
+		y := (template.marginTop + float64(cell/template.columns)*template.height) * pdf.MM
+		drawLabel(page, template, x, y, label)
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func copies(item domain.LabelItemRequest) int {
+	if item.Copies <= 0 {
+		return 1
+	}
+	return item.Copies
+}
+
+// drawLabel draws the name and price at the top of the label whose top left
+// corner is at x, y, and the barcode with its number below at the bottom.
+// Labels without a barcode show their SKU there instead.
+func drawLabel(page *pdf.Page, t labelTemplate, x, y float64, label domain.Label) {
+	padding := t.padding * pdf.MM
+	left := x + padding
+	width := t.width*pdf.MM - 2*padding
+	bottom := y + t.height*pdf.MM - padding
+
+	baseline := y + padding + t.nameSize*0.75
+	for i, line := range pdf.Wrap(t.nameFont, t.nameSize, width, label.Name, t.nameLines) {
+		if i > 0 {
+			baseline += t.nameSize * 1.15
+		}
+		page.Text(left, baseline, t.nameFont, t.nameSize, line)
+	}
+
+	baseline += t.nameSize*0.4 + t.priceSize*0.95
+	page.Text(left, baseline, pdf.HelveticaBold, t.priceSize, pdf.Fit(pdf.HelveticaBold, t.priceSize, width, label.Price))
+
+	code := label.SKU
+	if label.Barcode != "" {
+		if bars, err := barcode.Encode(label.Barcode); err == nil {
+			code = label.Barcode
+
+			moduleWidth := min(maxModuleWidth*pdf.MM, width/float64(len(bars)))
+			barsLeft := left + (width-moduleWidth*float64(len(bars)))/2
+			barsHeight := t.barcodeHeight * pdf.MM
+			barsTop := bottom - t.codeSize*1.1 - barsHeight
+			for i := 0; i < len(bars); {
+				if !bars[i] {
+					i++
+					continue
+				}
+				// Adjacent bar modules are drawn as one wider bar
+				run := i
+				for run < len(bars) && bars[run] {
+					run++
+				}
+				page.FillRect(barsLeft+float64(i)*moduleWidth, barsTop, float64(run-i)*moduleWidth, barsHeight)
+				i = run
+			}
+		}
+	}
+
+	code = pdf.Fit(pdf.Helvetica, t.codeSize, width, code)
+	codeWidth := pdf.TextWidth(pdf.Helvetica, t.codeSize, code)
+	page.Text(left+(width-codeWidth)/2, bottom, pdf.Helvetica, t.codeSize, code)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+// TestRenderSheetIsParseablePDF renders sheets of both templates and reads
+// them back through the cross-reference table, the way a viewer would.
+func TestRenderSheetIsParseablePDF(t *testing.T) {
+	labels := []domain.Label{
+		{Name: "Kopi Susu Gula Aren (Large)", SKU: "KSGA-L", Barcode: "8991234567006", Price: "Rp 28.000"},
+		{Name: "Teh Tarik", SKU: "TT-01", Barcode: "TT-01", Price: "Rp 15.000"},
+		{Name: "Croissant au beurre \\ pâtisserie", SKU: "CRS", Price: "Rp 22.500"},
+	}
+
+	for _, name := range []string{domain.LabelTemplateShelf, domain.LabelTemplatePriceTag} {
+		t.Run(name, func(t *testing.T) {
+			template := labelTemplates[name]
+			perPage := template.columns * template.rows
+
+			// One more label than a sheet holds takes a second page
+			var sheet []domain.Label
+			for len(sheet) < perPage+1 {
+				sheet = append(sheet, labels[len(sheet)%len(labels)])
+			}
+
+			out, err := renderSheet(template, sheet)
+			if err != nil {
+				t.Fatalf("renderSheet() error = %v", err)
+			}
+
+			objects := parsePDF(t, out)
+			pages := 0
+			var content strings.Builder
+			for _, object := range objects {
+				if strings.Contains(object, "/Type /Page ") {
+					pages++
+				}
+				if strings.Contains(object, "/Filter /FlateDecode") {
+					content.WriteString(inflate(t, object))
+				}
+			}
+			if pages != 2 {
+				t.Errorf("pages = %d, want 2", pages)
+			}
+
+			text := content.String()
+			for _, want := range []string{"(Rp 28.000) Tj", "(8991234567006) Tj", "(TT-01) Tj", "(CRS) Tj", " re f\n"} {
+				if !strings.Contains(text, want) {
+					t.Errorf("page content lacks %q", want)
+				}
+			}
+			// Every label of the sheet is drawn, on whichever page
+			if got, want := strings.Count(text, "(Rp 15.000) Tj"), (len(sheet)+1)/3; got != want {
+				t.Errorf("Rp 15.000 shows %d times, want %d", got, want)
+			}
+		})
+	}
+}
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	trailerPattern   = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R >>`)
+)
+
+// parsePDF checks the header, trailer and cross-reference table of a PDF
+// and returns its objects by number
+func parsePDF(t *testing.T, out []byte) map[int]string {
+	t.Helper()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) {
+		t.Fatalf("output does not start with a PDF header: %q", out[:min(len(out), 16)])
+	}
+	m := startxrefPattern.FindSubmatch(out)
+	if m == nil {
+		t.Fatal("output does not end with startxref and the end of file marker")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if xref >= len(out) || !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the cross-reference table", xref)
+	}
+
+	table := string(out[xref:])
+	lines := strings.Split(table, "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("cross-reference subsection %q: %v", lines[1], err)
+	}
+	size := trailerPattern.FindStringSubmatch(table)
+	if size == nil || size[1] != strconv.Itoa(count) {
+		t.Fatalf("trailer does not give the size %d of the cross-reference table", count)
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("object 0 entry = %q, want the free list head", lines[2])
+	}
+
+	objects := make(map[int]string, count-1)
+	for n := 1; n < count; n++ {
+		entry := lines[2+n]
+		var offset, generation int
+		var kind string
+		if _, err := fmt.Sscanf(entry, "%010d %05d %s", &offset, &generation, &kind); err != nil || kind != "n" || len(entry) != 19 {
+			t.Fatalf("cross-reference entry %d = %q", n, entry)
+		}
+
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		if offset >= xref || !bytes.HasPrefix(out[offset:], []byte(header)) {
+			t.Fatalf("object %d is not at offset %d", n, offset)
+		}
+		body := string(out[offset+len(header) : xref])
+		end := strings.Index(body, "\nendobj\n")
+		if end < 0 {
+			t.Fatalf("object %d has no endobj", n)
+		}
+		objects[n] = body[:end]
+	}
+
+	if !strings.Contains(objects[1], "/Type /Catalog /Pages 2 0 R") {
+		t.Errorf("object 1 = %q, want the catalog", objects[1])
+	}
+	return objects
+}
+
+var lengthPattern = regexp.MustCompile(`/Length (\d+)`)
+
+// inflate returns the decompressed stream of a stream object
+func inflate(t *testing.T, object string) string {
+	t.Helper()
+
+	m := lengthPattern.FindStringSubmatch(object)
+	start := strings.Index(object, "\nstream\n")
+	if m == nil || start < 0 {
+		t.Fatalf("stream object without length: %.60q", object)
+	}
+	length, _ := strconv.Atoi(m[1])
+	start += len("\nstream\n")
+	if start+length > len(object) || !strings.HasPrefix(object[start+length:], "\nendstream") {
+		t.Fatalf("stream of length %d does not end at endstream", length)
+	}
+
+	r, err := zlib.NewReader(strings.NewReader(object[start : start+length]))
+	if err != nil {
+		t.Fatalf("stream is not zlib: %v", err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to inflate stream: %v", err)
+	}
+	return string(content)
+}
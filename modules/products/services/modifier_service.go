@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+type modifierService struct {
+	modifierRepo domain.ModifierRepository
+	productRepo  domain.ProductRepository
+	categoryRepo domain.ProductCategoryRepository
+	bundleRepo   domain.BundleRepository
+}
+
+func NewModifierService(modifierRepo domain.ModifierRepository, productRepo domain.ProductRepository, categoryRepo domain.ProductCategoryRepository, bundleRepo domain.BundleRepository) domain.ModifierService {
+	return &modifierService{
+		modifierRepo: modifierRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		bundleRepo:   bundleRepo,
+	}
+}
+
+func (s *modifierService) Create(ctx context.Context, tenantID uint64, req domain.CreateModifierGroupRequest) (*domain.ModifierGroup, error) {
+	group := &domain.ModifierGroup{
+		TenantID:  tenantID,
+		Name:      strings.TrimSpace(req.Name),
+		MinSelect: req.MinSelect,
+		MaxSelect: req.MaxSelect,
+		SortOrder: req.SortOrder,
+		IsActive:  true,
+	}
+
+	modifiers, err := s.toModifiers(ctx, tenantID, req.Modifiers, false)
+	if err != nil {
+		return nil, err
+	}
+	group.Modifiers = modifiers
+
+	if err := checkSelectLimits(group); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.FindByID(ctx, tenantID, group.ID)
+}
+
+func (s *modifierService) Update(ctx context.Context, tenantID, groupID uint64, req domain.UpdateModifierGroupRequest) (*domain.ModifierGroup, error) {
+	group, err := s.modifierRepo.FindByID(ctx, tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = strings.TrimSpace(req.Name)
+	group.MinSelect = req.MinSelect
+	group.MaxSelect = req.MaxSelect
+	group.SortOrder = req.SortOrder
+	group.IsActive = req.IsActive
+
+	modifiers, err := s.toModifiers(ctx, tenantID, req.Modifiers, true)
+	if err != nil {
+		return nil, err
+	}
+	group.Modifiers = modifiers
+
+	if err := checkSelectLimits(group); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.FindByID(ctx, tenantID, groupID)
+}
+
+// toModifiers checks the requested modifiers and the products they take
+// from stock. Only an update keeps the IDs and active flags of modifiers.
+func (s *modifierService) toModifiers(ctx context.Context, tenantID uint64, requests []domain.ModifierRequest, update bool) ([]*domain.Modifier, error) {
+	modifiers := make([]*domain.Modifier, len(requests))
+	names := make(map[string]bool, len(requests))
+	ids := make(map[uint64]bool, len(requests))
+	var productIDs []uint64
+
+	for i, request := range requests {
+		name := strings.TrimSpace(request.Name)
+		if names[strings.ToLower(name)] {
+			return nil, errors.New("modifier names must be unique within the group")
+		}
+		names[strings.ToLower(name)] = true
+
+		modifiers[i] = &domain.Modifier{
+			Name:       name,
+			PriceDelta: request.PriceDelta,
+			ProductID:  request.ProductID,
+			Quantity:   request.Quantity,
+			IsActive:   true,
+		}
+		if modifiers[i].Quantity == 0 {
+			modifiers[i].Quantity = 1
+		}
+
+		if update && request.ID != nil {
+			if ids[*request.ID] {
+				return nil, errors.New("duplicate modifier in group")
+			}
+			ids[*request.ID] = true
+			modifiers[i].ID = *request.ID
+			modifiers[i].IsActive = request.IsActive
+		}
+
+		if request.ProductID != nil {
+			productIDs = append(productIDs, *request.ProductID)
+		}
+	}
+
+	productIDs = uniqueIDs(productIDs)
+	for _, productID := range productIDs {
+		if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+			if err.Error() == "product not found" {
+				return nil, errors.New("modifier product not found")
+			}
+			return nil, err
+		}
+	}
+
+	if len(productIDs) > 0 {
+		isBundle, err := s.bundleRepo.HasBundle(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		if isBundle {
+			return nil, errors.New("modifier product is a bundle")
+		}
+	}
+
+	return modifiers, nil
+}
+
+// checkSelectLimits makes sure a sale can pick as many of the group's active
+// modifiers as it requires
+func checkSelectLimits(group *domain.ModifierGroup) error {
+	if group.MinSelect > group.MaxSelect {
+		return errors.New("min select must not be above max select")
+	}
+
+	active := 0
+	for _, modifier := range group.Modifiers {
+		if modifier.IsActive {
+			active++
+		}
+	}
+	if group.MinSelect > active {
+		return errors.New("modifier group has fewer modifiers than it requires")
+	}
+
+	return nil
+}
+
+func (s *modifierService) Delete(ctx context.Context, tenantID, groupID uint64) error {
+	return s.modifierRepo.Delete(ctx, tenantID, groupID)
+}
+
+func (s *modifierService) GetByID(ctx context.Context, tenantID, groupID uint64) (*domain.ModifierGroup, error) {
+	return s.modifierRepo.FindByID(ctx, tenantID, groupID)
+}
+
+func (s *modifierService) GetAll(ctx context.Context, tenantID uint64, query domain.ModifierGroupQuery) ([]*domain.ModifierGroup, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	return s.modifierRepo.FindAll(ctx, tenantID, query)
+}
+
+func (s *modifierService) GetProductGroups(ctx context.Context, tenantID, productID uint64) ([]*domain.ModifierGroup, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	offered, err := s.modifierRepo.FindForProducts(ctx, tenantID, []uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := offered[productID]
+	if groups == nil {
+		groups = []*domain.ModifierGroup{}
+	}
+	return groups, nil
+}
+
+// SetProductGroups attaches the groups to the product. Groups of its
+// category are offered anyway and need not be attached.
+func (s *modifierService) SetProductGroups(ctx context.Context, tenantID, productID uint64, req domain.SetModifierGroupsRequest) ([]*domain.ModifierGroup, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	groupIDs, err := s.checkGroups(ctx, tenantID, req.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.ReplaceForProduct(ctx, productID, groupIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetProductGroups(ctx, tenantID, productID)
+}
+
+func (s *modifierService) GetCategoryGroups(ctx context.Context, tenantID, categoryID uint64) ([]*domain.ModifierGroup, error) {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.FindByCategory(ctx, categoryID)
+}
+
+// SetCategoryGroups attaches the groups to the category, offering them with
+// every product directly in it
+func (s *modifierService) SetCategoryGroups(ctx context.Context, tenantID, categoryID uint64, req domain.SetModifierGroupsRequest) ([]*domain.ModifierGroup, error) {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return nil, err
+	}
+
+	groupIDs, err := s.checkGroups(ctx, tenantID, req.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.ReplaceForCategory(ctx, categoryID, groupIDs); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.FindByCategory(ctx, categoryID)
+}
+
+// checkGroups drops repeated group IDs and makes sure the groups belong to
+// the tenant
+func (s *modifierService) checkGroups(ctx context.Context, tenantID uint64, groupIDs []uint64) ([]uint64, error) {
+	groupIDs = uniqueIDs(groupIDs)
+	if len(groupIDs) == 0 {
+		return groupIDs, nil
+	}
+
+	count, err := s.modifierRepo.CountGroups(ctx, tenantID, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(groupIDs)) {
+		return nil, errors.New("modifier group not found")
+	}
+
+	return groupIDs, nil
+}
+
+// GetSales sums the modifiers sold, under the names they were sold with
+func (s *modifierService) GetSales(ctx context.Context, tenantID uint64, query domain.ModifierSalesQuery) ([]*domain.ModifierSales, error) {
+	if query.DateFrom != nil && query.DateTo != nil && query.DateFrom.After(*query.DateTo) {
+		return nil, errors.New("date from must not be after date to")
+	}
+
+	return s.modifierRepo.FindSales(ctx, tenantID, query)
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+type priceListService struct {
+	priceListRepo domain.PriceListRepository
+	modifierRepo  domain.ModifierRepository
+}
+
+func NewPriceListService(priceListRepo domain.PriceListRepository, modifierRepo domain.ModifierRepository) domain.PriceListService {
+	return &priceListService{
+		priceListRepo: priceListRepo,
+		modifierRepo:  modifierRepo,
+	}
+}
+
+func (s *priceListService) Create(ctx context.Context, tenantID uint64, req domain.CreatePriceListRequest) (*domain.PriceList, error) {
+	list := &domain.PriceList{
+		TenantID:    tenantID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Priority:    req.Priority,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		IsActive:    true,
+	}
+
+	if err := s.setScope(ctx, list, req.OutletIDs, req.CustomerGroupIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.Create(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return s.priceListRepo.FindByID(ctx, tenantID, list.ID)
+}
+
+func (s *priceListService) Update(ctx context.Context, tenantID, listID uint64, req domain.UpdatePriceListRequest) (*domain.PriceList, error) {
+	list, err := s.priceListRepo.FindByID(ctx, tenantID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Name = strings.TrimSpace(req.Name)
+	list.Description = strings.TrimSpace(req.Description)
+	list.Priority = req.Priority
+	list.StartsAt = req.StartsAt
+	list.EndsAt = req.EndsAt
+	list.IsActive = req.IsActive
+
+	if err := s.setScope(ctx, list, req.OutletIDs, req.CustomerGroupIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceListRepo.Update(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, tenantID, listID)
+}
+
+// setScope checks the list's window and that its outlets and customer
+// groups belong to the tenant
+func (s *priceListService) setScope(ctx context.Context, list *domain.PriceList, outletIDs, groupIDs []uint64) error {
+	if list.StartsAt != nil && list.EndsAt != nil && !list.EndsAt.After(*list.StartsAt) {
+		return errors.New("ends at must be after starts at")
+	}
+
+	outletIDs = uniqueIDs(outletIDs)
+	if len(outletIDs) > 0 {
+		count, err := s.priceListRepo.CountOutlets(ctx, list.TenantID, outletIDs)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(outletIDs)) {
+			return errors.New("outlet not found")
+		}
+	}
+
+	groupIDs = uniqueIDs(groupIDs)
+	if len(groupIDs) > 0 {
+		count, err := s.priceListRepo.CountCustomerGroups(ctx, list.TenantID, groupIDs)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(groupIDs)) {
+			return errors.New("customer group not found")
+		}
+	}
+
+	list.OutletIDs = outletIDs
+	list.CustomerGroupIDs = groupIDs
+	return nil
+}
+
+func (s *priceListService) Delete(ctx context.Context, tenantID, listID uint64) error {
+	return s.priceListRepo.Delete(ctx, tenantID, listID)
+}
+
+func (s *priceListService) GetByID(ctx context.Context, tenantID, listID uint64) (*domain.PriceList, error) {
+	list, err := s.priceListRepo.FindByID(ctx, tenantID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Items, err = s.priceListRepo.FindItems(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *priceListService) GetAll(ctx context.Context, tenantID uint64, query domain.PriceListQuery) ([]*domain.PriceList, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	return s.priceListRepo.FindAll(ctx, tenantID, query)
+}
+
+func (s *priceListService) SetItems(ctx context.Context, tenantID, listID uint64, req domain.SetPriceListItemsRequest) (*domain.PriceList, error) {
+	if _, err := s.priceListRepo.FindByID(ctx, tenantID, listID); err != nil {
+		return nil, err
+	}
+
+	var productIDs, variantIDs []uint64
+	seen := make(map[[2]uint64]bool, len(req.Items))
+	for _, item := range req.Items {
+		key := [2]uint64{item.ProductID, 0}
+		if item.VariantID != nil {
+			key[1] = *item.VariantID
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+		if seen[key] {
+			return nil, errors.New("duplicate item in price list")
+		}
+		seen[key] = true
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	prices, variants, err := s.priceListRepo.FindBasePrices(ctx, tenantID, uniqueIDs(productIDs), uniqueIDs(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*domain.PriceListItem, len(req.Items))
+	for i, item := range req.Items {
+		if _, ok := prices[item.ProductID]; !ok {
+			return nil, errors.New("product not found")
+		}
+		if item.VariantID != nil {
+			variant, ok := variants[*item.VariantID]
+			if !ok || variant.ProductID != item.ProductID {
+				return nil, errors.New("variant not found")
+			}
+		}
+
+		items[i] = &domain.PriceListItem{
+			PriceListID: listID,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			Price:       item.Price,
+		}
+	}
+
+	if err := s.priceListRepo.UpsertItems(ctx, listID, items); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, tenantID, listID)
+}
+
+func (s *priceListService) DeleteItem(ctx context.Context, tenantID, listID, itemID uint64) error {
+	if _, err := s.priceListRepo.FindByID(ctx, tenantID, listID); err != nil {
+		return err
+	}
+
+	return s.priceListRepo.DeleteItem(ctx, listID, itemID)
+}
+
+// ResolvePrices prices every item at its variant's or product's selling
+// price, unless a price list valid at the time applies at the outlet and to
+// the customer's group; then the best ranked list's price is used. The
+// price deltas of the modifiers picked for an item are added on top. Items
+// without modifiers are priced as they are, as for lookups and labels, so
+// required groups are only enforced once modifiers are picked.
+func (s *priceListService) ResolvePrices(ctx context.Context, tenantID uint64, req domain.ResolvePricesRequest) ([]*domain.ResolvedPrice, error) {
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	if req.OutletID != nil {
+		count, err := s.priceListRepo.CountOutlets(ctx, tenantID, []uint64{*req.OutletID})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errors.New("outlet not found")
+		}
+	}
+
+	var groupID *uint64
+	if req.CustomerID != nil {
+		var err error
+		groupID, err = s.priceListRepo.FindCustomerGroupID(ctx, tenantID, *req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var productIDs, variantIDs, modifiedIDs []uint64
+	for _, item := range req.Items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+		if len(item.ModifierIDs) > 0 {
+			modifiedIDs = append(modifiedIDs, item.ProductID)
+		}
+	}
+	productIDs = uniqueIDs(productIDs)
+
+	prices, variants, err := s.priceListRepo.FindBasePrices(ctx, tenantID, productIDs, uniqueIDs(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.priceListRepo.FindCandidates(ctx, tenantID, productIDs, req.OutletID, groupID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	modifierGroups, err := s.modifierRepo.FindForProducts(ctx, tenantID, uniqueIDs(modifiedIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]*domain.ResolvedPrice, len(req.Items))
+	for i, item := range req.Items {
+		basePrice, ok := prices[item.ProductID]
+		if !ok {
+			return nil, errors.New("product not found")
+		}
+		if item.VariantID != nil {
+			variant, ok := variants[*item.VariantID]
+			if !ok || variant.ProductID != item.ProductID {
+				return nil, errors.New("variant not found")
+			}
+			if variant.SellingPrice != nil {
+				basePrice = *variant.SellingPrice
+			}
+		}
+
+		price := &domain.ResolvedPrice{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			BasePrice: basePrice,
+			Price:     basePrice,
+		}
+		if best := domain.BestPrice(candidates, item.ProductID, item.VariantID); best != nil {
+			listID := best.PriceListID
+			price.Price = best.Price
+			price.PriceListID = &listID
+			price.PriceListName = best.PriceListName
+		}
+
+		if len(item.ModifierIDs) > 0 {
+			modifiers, err := domain.SelectModifiers(modifierGroups[item.ProductID], item.ModifierIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, modifier := range modifiers {
+				price.ModifiersPrice += modifier.PriceDelta
+			}
+			price.ModifiersPrice = math.Round(price.ModifiersPrice*100) / 100
+			price.Modifiers = modifiers
+			// Negative deltas never take the price below zero
+			price.Price = math.Max(0, math.Round((price.Price+price.ModifiersPrice)*100)/100)
+		}
+		resolved[i] = price
+	}
+
+	return resolved, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	unique := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
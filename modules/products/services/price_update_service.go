@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+func (s *productService) GetPriceHistory(ctx context.Context, tenantID, productID uint64, query domain.PriceChangeQuery) ([]*domain.PriceChange, int64, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, 0, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	return s.productRepo.FindPriceChanges(ctx, tenantID, productID, query.Limit, (query.Page-1)*query.Limit)
+}
+
+// BulkUpdatePrices applies the request's rule to the cost or selling price
+// of every active product in the category. Products whose price ends up
+// unchanged are left out; the others are updated, with their history, in
+// one transaction.
+func (s *productService) BulkUpdatePrices(ctx context.Context, tenantID, userID uint64, req domain.BulkPriceUpdateRequest) ([]*domain.PriceChange, error) {
+	if req.Method == domain.PriceRuleMethodPercentage && req.Value < -100 {
+		return nil, errors.New("percentage cannot be below -100")
+	}
+
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, req.CategoryID); err != nil {
+		return nil, errors.New("category not found")
+	}
+
+	products, err := s.productRepo.FindActiveByCategory(ctx, tenantID, req.CategoryID, req.IncludeSubcategories)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := domain.PriceRule{
+		Method:   req.Method,
+		Value:    req.Value,
+		Rounding: req.Rounding,
+		RoundTo:  req.RoundTo,
+	}
+	now := time.Now()
+
+	changes := make([]*domain.PriceChange, 0, len(products))
+	for _, product := range products {
+		change := &domain.PriceChange{
+			TenantID:        tenantID,
+			ProductID:       product.ID,
+			ProductSKU:      product.SKU,
+			ProductName:     product.Name,
+			OldCostPrice:    product.CostPrice,
+			NewCostPrice:    product.CostPrice,
+			OldSellingPrice: product.SellingPrice,
+			NewSellingPrice: product.SellingPrice,
+			Source:          domain.PriceChangeSourceBulkUpdate,
+			Reason:          strings.TrimSpace(req.Reason),
+			ChangedBy:       userID,
+			EffectiveAt:     now,
+		}
+
+		if req.Field == "cost_price" {
+			change.NewCostPrice = rule.Apply(product.CostPrice)
+			if change.NewCostPrice < 0 {
+				return nil, fmt.Errorf("cost price of product %s would be negative", product.SKU)
+			}
+			if change.NewCostPrice == change.OldCostPrice {
+				continue
+			}
+		} else {
+			change.NewSellingPrice = rule.Apply(product.SellingPrice)
+			if change.NewSellingPrice < 0 {
+				return nil, fmt.Errorf("selling price of product %s would be negative", product.SKU)
+			}
+			if change.NewSellingPrice == change.OldSellingPrice {
+				continue
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	if req.DryRun {
+		return changes, nil
+	}
+
+	if err := s.productRepo.UpdatePrices(ctx, changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
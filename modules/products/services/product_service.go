@@ -12,16 +12,28 @@ import (
 type productService struct {
 	productRepo  domain.ProductRepository
 	categoryRepo domain.ProductCategoryRepository
+	variantRepo  domain.VariantRepository
+	recipeRepo   domain.RecipeRepository
+	barcodeRepo  domain.BarcodeRepository
+	bundleRepo   domain.BundleRepository
+	modifierRepo domain.ModifierRepository
+	unitRepo     domain.UnitRepository
 }
 
-func NewProductService(productRepo domain.ProductRepository, categoryRepo domain.ProductCategoryRepository) domain.ProductService {
+func NewProductService(productRepo domain.ProductRepository, categoryRepo domain.ProductCategoryRepository, variantRepo domain.VariantRepository, recipeRepo domain.RecipeRepository, barcodeRepo domain.BarcodeRepository, bundleRepo domain.BundleRepository, modifierRepo domain.ModifierRepository, unitRepo domain.UnitRepository) domain.ProductService {
 	return &productService{
 		productRepo:  productRepo,
 		categoryRepo: categoryRepo,
+		variantRepo:  variantRepo,
+		recipeRepo:   recipeRepo,
+		barcodeRepo:  barcodeRepo,
+		bundleRepo:   bundleRepo,
+		modifierRepo: modifierRepo,
+		unitRepo:     unitRepo,
 	}
 }
 
-func (s *productService) Create(ctx context.Context, tenantID uint64, req domain.CreateProductRequest) (*domain.Product, error) {
+func (s *productService) Create(ctx context.Context, tenantID, userID uint64, req domain.CreateProductRequest) (*domain.Product, error) {
 	// Validate SKU uniqueness
 	exists, err := s.productRepo.CheckSKUExists(ctx, tenantID, req.SKU, nil)
 	if err != nil {
@@ -31,6 +43,10 @@ func (s *productService) Create(ctx context.Context, tenantID uint64, req domain
 		return nil, errors.New("product with this SKU already exists")
 	}
 
+	if err := s.checkBarcode(ctx, tenantID, req.Barcode, nil, nil); err != nil {
+		return nil, err
+	}
+
 	// Validate category exists if provided
 	if req.CategoryID != nil {
 		_, err := s.categoryRepo.FindByID(ctx, tenantID, *req.CategoryID)
@@ -52,6 +68,7 @@ func (s *productService) Create(ctx context.Context, tenantID uint64, req domain
 		SellingPrice: req.SellingPrice,
 		MinStock:     req.MinStock,
 		TrackStock:   req.TrackStock,
+		TrackLots:    req.TrackLots,
 		IsActive:     true,
 		Images:       req.Images,
 		Variants:     req.Variants,
@@ -72,16 +89,37 @@ func (s *productService) Create(ctx context.Context, tenantID uint64, req domain
 		product.Variants = make(map[string]interface{})
 	}
 
-	err = s.productRepo.Create(ctx, product)
+	if product.TrackLots && !product.TrackStock {
+		return nil, errors.New("lot tracking requires stock tracking")
+	}
+
+	// Tracked products start with a stock row at every active outlet
+	if product.TrackStock {
+		stocks, err := s.initialStocks(ctx, tenantID, req.InitialStock)
+		if err != nil {
+			return nil, err
+		}
+		product.Stocks = stocks
+	} else if len(req.InitialStock) > 0 {
+		return nil, errors.New("initial stock requires stock tracking")
+	}
+
+	err = s.productRepo.Create(ctx, product, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	if product.Barcode == "" {
+		if err := s.autoAssignBarcode(ctx, tenantID, product.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Return product with category information if exists
 	return s.productRepo.FindByID(ctx, tenantID, product.ID)
 }
 
-func (s *productService) Update(ctx context.Context, tenantID, productID uint64, req domain.UpdateProductRequest) (*domain.Product, error) {
+func (s *productService) Update(ctx context.Context, tenantID, userID, productID uint64, req domain.UpdateProductRequest) (*domain.Product, error) {
 	// Check if product exists
 	existingProduct, err := s.productRepo.FindByID(ctx, tenantID, productID)
 	if err != nil {
@@ -97,6 +135,13 @@ func (s *productService) Update(ctx context.Context, tenantID, productID uint64,
 		return nil, errors.New("product with this SKU already exists")
 	}
 
+	// Barcodes shared before uniqueness was checked are left alone
+	if strings.TrimSpace(req.Barcode) != existingProduct.Barcode {
+		if err := s.checkBarcode(ctx, tenantID, req.Barcode, &productID, nil); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate category exists if provided
 	if req.CategoryID != nil {
 		_, err := s.categoryRepo.FindByID(ctx, tenantID, *req.CategoryID)
@@ -105,7 +150,36 @@ func (s *productService) Update(ctx context.Context, tenantID, productID uint64,
 		}
 	}
 
+	variants, err := s.variantRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(variants) > 0 {
+		// The options of a product with variants change by generating them
+		req.Variants = existingProduct.Variants
+		if req.TrackLots {
+			return nil, errors.New("lot tracked products cannot have variants")
+		}
+	}
+
+	var priceChange *domain.PriceChange
+	if req.CostPrice != existingProduct.CostPrice || req.SellingPrice != existingProduct.SellingPrice {
+		priceChange = &domain.PriceChange{
+			TenantID:        tenantID,
+			ProductID:       productID,
+			OldCostPrice:    existingProduct.CostPrice,
+			NewCostPrice:    req.CostPrice,
+			OldSellingPrice: existingProduct.SellingPrice,
+			NewSellingPrice: req.SellingPrice,
+			Source:          domain.PriceChangeSourceProductUpdate,
+			Reason:          strings.TrimSpace(req.PriceChangeReason),
+			ChangedBy:       userID,
+			EffectiveAt:     time.Now(),
+		}
+	}
+
 	// Update product entity
+	baseUnit := existingProduct.Unit
 	existingProduct.CategoryID = req.CategoryID
 	existingProduct.SKU = strings.TrimSpace(req.SKU)
 	existingProduct.Barcode = strings.TrimSpace(req.Barcode)
@@ -116,6 +190,7 @@ func (s *productService) Update(ctx context.Context, tenantID, productID uint64,
 	existingProduct.SellingPrice = req.SellingPrice
 	existingProduct.MinStock = req.MinStock
 	existingProduct.TrackStock = req.TrackStock
+	existingProduct.TrackLots = req.TrackLots
 	existingProduct.IsActive = req.IsActive
 	existingProduct.Images = req.Images
 	existingProduct.Variants = req.Variants
@@ -126,6 +201,18 @@ func (s *productService) Update(ctx context.Context, tenantID, productID uint64,
 		existingProduct.Unit = "pcs"
 	}
 
+	// Conversions are factors of the base unit, so it stays while there are
+	// any
+	if existingProduct.Unit != baseUnit {
+		conversions, err := s.unitRepo.FindProductUnits(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		if len(conversions) > 0 {
+			return nil, errors.New("product unit cannot change while it has unit conversions")
+		}
+	}
+
 	// Initialize slices/maps if nil
 	if existingProduct.Images == nil {
 		existingProduct.Images = []string{}
@@ -134,7 +221,21 @@ func (s *productService) Update(ctx context.Context, tenantID, productID uint64,
 		existingProduct.Variants = make(map[string]interface{})
 	}
 
-	err = s.productRepo.Update(ctx, existingProduct)
+	if existingProduct.TrackLots && !existingProduct.TrackStock {
+		return nil, errors.New("lot tracking requires stock tracking")
+	}
+
+	if existingProduct.TrackStock {
+		isBundle, err := s.bundleRepo.HasBundle(ctx, []uint64{productID})
+		if err != nil {
+			return nil, err
+		}
+		if isBundle {
+			return nil, errors.New("bundle products cannot track stock")
+		}
+	}
+
+	err = s.productRepo.Update(ctx, existingProduct, priceChange)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +251,31 @@ func (s *productService) Delete(ctx context.Context, tenantID, productID uint64)
 		return err
 	}
 
+	// Recipes would otherwise lose an ingredient without anyone noticing
+	isComponent, err := s.recipeRepo.IsComponent(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if isComponent {
+		return errors.New("product is used in a recipe")
+	}
+
+	inBundle, err := s.bundleRepo.IsComponent(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if inBundle {
+		return errors.New("product is used in a bundle")
+	}
+
+	inModifier, err := s.modifierRepo.IsModifierProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if inModifier {
+		return errors.New("product is used by a modifier")
+	}
+
 	return s.productRepo.Delete(ctx, tenantID, productID)
 }
 
@@ -157,19 +283,30 @@ func (s *productService) GetByID(ctx context.Context, tenantID, productID uint64
 	return s.productRepo.FindByID(ctx, tenantID, productID)
 }
 
-func (s *productService) GetAll(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.Product, int64, error) {
+func (s *productService) GetAll(ctx context.Context, tenantID uint64, query domain.ProductQuery) ([]*domain.Product, int64, error) {
 	// Set default pagination if not provided
-	if limit <= 0 {
-		limit = 50
+	if query.Limit <= 0 {
+		query.Limit = 50
 	}
-	if limit > 100 {
-		limit = 100
+	if query.Limit > 100 {
+		query.Limit = 100
 	}
-	if offset < 0 {
-		offset = 0
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	if query.InStock && query.OutletID == nil {
+		return nil, 0, errors.New("outlet is required to filter by stock")
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, 0, errors.New("min price must not be greater than max price")
 	}
 
-	return s.productRepo.FindAll(ctx, tenantID, limit, offset)
+	return s.productRepo.FindAll(ctx, tenantID, query)
+}
+
+func (s *productService) GetStocks(ctx context.Context, tenantID, productID uint64) ([]*domain.ProductStock, error) {
+	return s.productRepo.FindStocks(ctx, tenantID, productID)
 }
 
 func (s *productService) GetBySKU(ctx context.Context, tenantID uint64, sku string) (*domain.Product, error) {
@@ -185,7 +322,28 @@ func (s *productService) GetByBarcode(ctx context.Context, tenantID uint64, barc
 		return nil, errors.New("barcode cannot be empty")
 	}
 
-	return s.productRepo.FindByBarcode(ctx, tenantID, strings.TrimSpace(barcode))
+	barcode = strings.TrimSpace(barcode)
+	product, err := s.productRepo.FindByBarcode(ctx, tenantID, barcode)
+	if err == nil || err.Error() != "product not found" {
+		return product, err
+	}
+
+	// Variants carry their own barcodes
+	variant, err := s.variantRepo.FindByBarcode(ctx, tenantID, barcode)
+	if err != nil {
+		if err.Error() == "variant not found" {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	product, err = s.productRepo.FindByID(ctx, tenantID, variant.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	product.Variant = variant
+
+	return product, nil
 }
 
 func (s *productService) GetByCategory(ctx context.Context, tenantID, categoryID uint64, limit, offset int) ([]*domain.Product, int64, error) {
@@ -208,3 +366,30 @@ func (s *productService) GetByCategory(ctx context.Context, tenantID, categoryID
 
 	return s.productRepo.FindByCategory(ctx, tenantID, categoryID, limit, offset)
 }
+
+func (s *productService) initialStocks(ctx context.Context, tenantID uint64, req []domain.InitialStockRequest) ([]*domain.ProductStock, error) {
+	outletIDs, err := s.productRepo.FindActiveOutletIDs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uint64]float64, len(req))
+	for _, initial := range req {
+		quantities[initial.OutletID] = initial.Quantity
+	}
+
+	stocks := make([]*domain.ProductStock, len(outletIDs))
+	for i, outletID := range outletIDs {
+		stocks[i] = &domain.ProductStock{
+			OutletID: outletID,
+			Quantity: quantities[outletID],
+		}
+		delete(quantities, outletID)
+	}
+
+	if len(quantities) > 0 {
+		return nil, errors.New("outlet not found")
+	}
+
+	return stocks, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+func (s *productService) GetRecipe(ctx context.Context, tenantID, productID uint64) (*domain.Recipe, error) {
+	// Check if product exists
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.recipeRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Recipe{ProductID: productID, Items: items}, nil
+}
+
+// SetRecipe replaces the product's recipe. Recipes are a single level deep:
+// a component cannot have a recipe of its own, and a product used as a
+// component cannot get one. Bundles neither have recipes nor are part of
+// them.
+func (s *productService) SetRecipe(ctx context.Context, tenantID, productID uint64, req domain.SetRecipeRequest) (*domain.Recipe, error) {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	isComponent, err := s.recipeRepo.IsComponent(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if isComponent {
+		return nil, errors.New("product is used in a recipe and cannot have one")
+	}
+
+	isBundle, err := s.bundleRepo.HasBundle(ctx, []uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+	if isBundle {
+		return nil, errors.New("bundle products cannot have a recipe")
+	}
+
+	componentIDs := make([]uint64, 0, len(req.Items))
+	seen := make(map[uint64]bool, len(req.Items))
+	for _, item := range req.Items {
+		if item.ComponentID == productID {
+			return nil, errors.New("product cannot be a component of its own recipe")
+		}
+		if seen[item.ComponentID] {
+			return nil, errors.New("duplicate component in recipe")
+		}
+		seen[item.ComponentID] = true
+		componentIDs = append(componentIDs, item.ComponentID)
+	}
+
+	components, err := s.recipeRepo.FindComponents(ctx, tenantID, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) != len(componentIDs) {
+		return nil, errors.New("component not found")
+	}
+
+	nested, err := s.recipeRepo.HasRecipe(ctx, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if nested {
+		return nil, errors.New("component has a recipe of its own")
+	}
+
+	bundled, err := s.bundleRepo.HasBundle(ctx, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if bundled {
+		return nil, errors.New("component is a bundle")
+	}
+
+	items := make([]*domain.RecipeItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = &domain.RecipeItem{
+			ComponentID: item.ComponentID,
+			Quantity:    item.Quantity,
+			Component:   components[item.ComponentID],
+		}
+	}
+
+	if err := s.recipeRepo.Replace(ctx, productID, items); err != nil {
+		return nil, err
+	}
+
+	return s.GetRecipe(ctx, tenantID, productID)
+}
+
+func (s *productService) DeleteRecipe(ctx context.Context, tenantID, productID uint64) error {
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return err
+	}
+
+	return s.recipeRepo.Replace(ctx, productID, nil)
+}
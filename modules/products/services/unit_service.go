@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+type unitService struct {
+	unitRepo    domain.UnitRepository
+	productRepo domain.ProductRepository
+}
+
+func NewUnitService(unitRepo domain.UnitRepository, productRepo domain.ProductRepository) domain.UnitService {
+	return &unitService{
+		unitRepo:    unitRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *unitService) Create(ctx context.Context, tenantID uint64, req domain.CreateUnitRequest) (*domain.Unit, error) {
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return nil, errors.New("unit code is required")
+	}
+
+	exists, err := s.unitRepo.CodeExists(ctx, tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("unit with this code already exists")
+	}
+
+	unit := &domain.Unit{
+		TenantID:     tenantID,
+		Code:         code,
+		Name:         strings.TrimSpace(req.Name),
+		AllowDecimal: req.AllowDecimal,
+	}
+
+	if err := s.unitRepo.Create(ctx, unit); err != nil {
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+func (s *unitService) Update(ctx context.Context, tenantID, unitID uint64, req domain.UpdateUnitRequest) (*domain.Unit, error) {
+	unit, err := s.unitRepo.FindByID(ctx, tenantID, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	unit.Name = strings.TrimSpace(req.Name)
+	unit.AllowDecimal = req.AllowDecimal
+
+	if err := s.unitRepo.Update(ctx, unit); err != nil {
+		return nil, err
+	}
+
+	return s.unitRepo.FindByID(ctx, tenantID, unitID)
+}
+
+func (s *unitService) Delete(ctx context.Context, tenantID, unitID uint64) error {
+	return s.unitRepo.Delete(ctx, tenantID, unitID)
+}
+
+func (s *unitService) GetByID(ctx context.Context, tenantID, unitID uint64) (*domain.Unit, error) {
+	return s.unitRepo.FindByID(ctx, tenantID, unitID)
+}
+
+func (s *unitService) GetAll(ctx context.Context, tenantID uint64) ([]*domain.Unit, error) {
+	return s.unitRepo.FindAll(ctx, tenantID)
+}
+
+func (s *unitService) GetProductUnits(ctx context.Context, tenantID, productID uint64) ([]*domain.ProductUnit, error) {
+	product, err := s.productRepo.FindByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.productUnits(ctx, product)
+}
+
+// SetProductUnits replaces the units the product converts to its base
+// unit. Every unit appears once and is not the base unit itself. Unless the
+// base unit allows decimals, a unit must hold a whole number of it.
+func (s *unitService) SetProductUnits(ctx context.Context, tenantID, productID uint64, req domain.SetProductUnitsRequest) ([]*domain.ProductUnit, error) {
+	product, err := s.productRepo.FindByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(req.Units)+1)
+	codes = append(codes, product.Unit)
+	for _, request := range req.Units {
+		codes = append(codes, strings.TrimSpace(request.Unit))
+	}
+
+	units, err := s.unitRepo.FindByCodes(ctx, tenantID, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	baseDecimal := false
+	if base, ok := units[product.Unit]; ok {
+		baseDecimal = base.AllowDecimal
+	}
+
+	seen := make(map[string]bool, len(req.Units))
+	conversions := make([]*domain.ProductUnit, len(req.Units))
+	for i, request := range req.Units {
+		code := strings.TrimSpace(request.Unit)
+		unit, ok := units[code]
+		if !ok {
+			return nil, errors.New("unit not found")
+		}
+		if code == product.Unit {
+			return nil, errors.New("unit is the product's base unit")
+		}
+		if seen[code] {
+			return nil, errors.New("duplicate unit in conversions")
+		}
+		seen[code] = true
+
+		factor := math.Round(request.Factor*1000000) / 1000000
+		if factor <= 0 {
+			return nil, errors.New("conversion factor must be positive")
+		}
+		if !baseDecimal && factor != math.Trunc(factor) {
+			return nil, errors.New("conversion factor must be a whole number of the base unit")
+		}
+
+		conversions[i] = &domain.ProductUnit{
+			ProductID: productID,
+			UnitID:    unit.ID,
+			Factor:    factor,
+		}
+	}
+
+	if err := s.unitRepo.ReplaceProductUnits(ctx, productID, conversions); err != nil {
+		return nil, err
+	}
+
+	return s.productUnits(ctx, product)
+}
+
+// productUnits lists the product's base unit, named after the tenant's unit
+// of its code when there is one, then its conversions
+func (s *unitService) productUnits(ctx context.Context, product *domain.Product) ([]*domain.ProductUnit, error) {
+	base := &domain.ProductUnit{
+		ProductID: product.ID,
+		Code:      product.Unit,
+		Name:      product.Unit,
+		Factor:    1,
+		IsBase:    true,
+	}
+
+	units, err := s.unitRepo.FindByCodes(ctx, product.TenantID, []string{product.Unit})
+	if err != nil {
+		return nil, err
+	}
+	if unit, ok := units[product.Unit]; ok {
+		base.UnitID = unit.ID
+		base.Name = unit.Name
+		base.AllowDecimal = unit.AllowDecimal
+	}
+
+	conversions, err := s.unitRepo.FindProductUnits(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*domain.ProductUnit{base}, conversions...), nil
+}
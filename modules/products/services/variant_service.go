@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/exven/pos-system/modules/products/domain"
+)
+
+// maxVariants caps the combinations a product's options can generate
+const maxVariants = 100
+
+func (s *productService) GetVariants(ctx context.Context, tenantID, productID uint64) ([]*domain.ProductVariant, error) {
+	// Check if product exists
+	if _, err := s.productRepo.FindByID(ctx, tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	return s.variantRepo.FindByProduct(ctx, productID)
+}
+
+// GetVariantStocks fills in the per-outlet stock of the variants
+func (s *productService) GetVariantStocks(ctx context.Context, tenantID uint64, variants []*domain.ProductVariant) error {
+	variantIDs := make([]uint64, len(variants))
+	for i, variant := range variants {
+		variantIDs[i] = variant.ID
+	}
+
+	stocks, err := s.variantRepo.FindStocks(ctx, tenantID, variantIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		variant.Stocks = stocks[variant.ID]
+	}
+
+	return nil
+}
+
+// GenerateVariants sets the product's options and creates a variant for
+// every combination of their values that does not have one yet. Variants
+// whose combination is no longer offered are deactivated rather than
+// deleted, since their stock movements still refer to them.
+func (s *productService) GenerateVariants(ctx context.Context, tenantID, productID uint64, req domain.GenerateVariantsRequest) ([]*domain.ProductVariant, error) {
+	product, err := s.productRepo.FindByID(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lots are kept per product, not per variant
+	if product.TrackLots {
+		return nil, errors.New("lot tracked products cannot have variants")
+	}
+
+	options, err := productOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 1
+	for _, option := range options {
+		count *= len(option.Values)
+	}
+	if count > maxVariants {
+		return nil, fmt.Errorf("options generate more than %d variants", maxVariants)
+	}
+	combinations := optionCombinations(options)
+
+	existing, err := s.variantRepo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range existing {
+		variant.IsActive = false
+	}
+
+	variants := existing
+	skus := make(map[string]bool)
+	for _, combination := range combinations {
+		if variant := matchingVariant(existing, combination); variant != nil {
+			variant.IsActive = true
+			continue
+		}
+
+		values := make([]string, len(options))
+		for i, option := range options {
+			values[i] = combination[option.Name]
+		}
+
+		sku, err := s.variantSKU(ctx, tenantID, product.SKU, values, skus)
+		if err != nil {
+			return nil, err
+		}
+		skus[sku] = true
+
+		variants = append(variants, &domain.ProductVariant{
+			TenantID:  tenantID,
+			ProductID: productID,
+			SKU:       sku,
+			Name:      strings.Join(values, " / "),
+			Options:   combination,
+			IsActive:  true,
+		})
+	}
+
+	product.Variants = make(map[string]interface{}, len(options))
+	for _, option := range options {
+		product.Variants[option.Name] = option.Values
+	}
+
+	// Tracked variants start with a stock row at every active outlet
+	var outletIDs []uint64
+	if product.TrackStock {
+		outletIDs, err = s.productRepo.FindActiveOutletIDs(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.variantRepo.Sync(ctx, product, variants, outletIDs); err != nil {
+		return nil, err
+	}
+
+	return s.variantRepo.FindByProduct(ctx, productID)
+}
+
+func (s *productService) UpdateVariant(ctx context.Context, tenantID, productID, variantID uint64, req domain.UpdateVariantRequest) (*domain.ProductVariant, error) {
+	variant, err := s.variantRepo.FindByID(ctx, tenantID, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	sku := strings.TrimSpace(req.SKU)
+	exists, err := s.variantRepo.CheckSKUExists(ctx, tenantID, sku, &variantID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("variant with this SKU already exists")
+	}
+
+	if strings.TrimSpace(req.Barcode) != variant.Barcode {
+		if err := s.checkBarcode(ctx, tenantID, req.Barcode, nil, &variantID); err != nil {
+			return nil, err
+		}
+	}
+
+	variant.SKU = sku
+	variant.Barcode = strings.TrimSpace(req.Barcode)
+	variant.SellingPrice = req.SellingPrice
+	variant.IsActive = req.IsActive
+
+	if err := s.variantRepo.Update(ctx, variant); err != nil {
+		return nil, err
+	}
+
+	return s.variantRepo.FindByID(ctx, tenantID, productID, variantID)
+}
+
+// DeleteVariant removes a variant that never moved stock. Variants with
+// history are deactivated instead, so the ledger keeps its references.
+func (s *productService) DeleteVariant(ctx context.Context, tenantID, productID, variantID uint64) error {
+	if _, err := s.variantRepo.FindByID(ctx, tenantID, productID, variantID); err != nil {
+		return err
+	}
+
+	moved, err := s.variantRepo.HasMovements(ctx, variantID)
+	if err != nil {
+		return err
+	}
+	if moved {
+		return errors.New("variant has stock movements and can only be deactivated")
+	}
+
+	inBundle, err := s.bundleRepo.IsVariantComponent(ctx, variantID)
+	if err != nil {
+		return err
+	}
+	if inBundle {
+		return errors.New("variant is used in a bundle")
+	}
+
+	return s.variantRepo.Delete(ctx, variantID)
+}
+
+// variantSKU derives a variant's SKU from the product's SKU and its option
+// values, e.g. TSHIRT-L-RED, adding a number when the SKU is taken
+func (s *productService) variantSKU(ctx context.Context, tenantID uint64, productSKU string, values []string, taken map[string]bool) (string, error) {
+	parts := []string{productSKU}
+	for _, value := range values {
+		if part := skuPart(value); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	base := strings.Join(parts, "-")
+
+	for n := 1; ; n++ {
+		sku := base
+		if n > 1 {
+			sku = fmt.Sprintf("%s-%d", base, n)
+		}
+		if len(sku) > 100 {
+			return "", errors.New("variant SKU is too long")
+		}
+		if taken[sku] {
+			continue
+		}
+
+		exists, err := s.variantRepo.CheckSKUExists(ctx, tenantID, sku, nil)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return sku, nil
+		}
+	}
+}
+
+// skuPart upper-cases a value and joins its words with dashes
+func skuPart(value string) string {
+	words := strings.FieldsFunc(strings.ToUpper(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// productOptions trims the requested options and rejects repeated names
+// and values
+func productOptions(req []domain.ProductOptionRequest) ([]domain.ProductOption, error) {
+	options := make([]domain.ProductOption, len(req))
+	names := make(map[string]bool, len(req))
+
+	for i, option := range req {
+		name := strings.TrimSpace(option.Name)
+		if name == "" {
+			return nil, errors.New("option name cannot be empty")
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("option %s is listed twice", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		values := make([]string, 0, len(option.Values))
+		seen := make(map[string]bool, len(option.Values))
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil, fmt.Errorf("option %s has an empty value", name)
+			}
+			if seen[strings.ToLower(value)] {
+				return nil, fmt.Errorf("option %s lists %s twice", name, value)
+			}
+			seen[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+
+		options[i] = domain.ProductOption{Name: name, Values: values}
+	}
+
+	return options, nil
+}
+
+// optionCombinations lists every combination of the options' values, the
+// first option varying slowest
+func optionCombinations(options []domain.ProductOption) []map[string]string {
+	combinations := []map[string]string{{}}
+
+	for _, option := range options {
+		next := make([]map[string]string, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				extended := make(map[string]string, len(combination)+1)
+				for name, chosen := range combination {
+					extended[name] = chosen
+				}
+				extended[option.Name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+
+	return combinations
+}
+
+func matchingVariant(variants []*domain.ProductVariant, options map[string]string) *domain.ProductVariant {
+	for _, variant := range variants {
+		if variant.SameOptions(options) {
+			return variant
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package domain
+
+import "time"
+
+type CreateSupplierRequest struct {
+	Code            string `json:"code" validate:"omitempty,max=50"`
+	Name            string `json:"name" validate:"required,min=1,max=255"`
+	ContactPerson   string `json:"contact_person" validate:"max=255"`
+	Email           string `json:"email" validate:"omitempty,email,max=255"`
+	Phone           string `json:"phone" validate:"omitempty,max=20"`
+	Address         string `json:"address"`
+	City            string `json:"city" validate:"max=100"`
+	TaxNumber       string `json:"tax_number" validate:"max=50"`
+	PaymentTermDays int    `json:"payment_term_days" validate:"min=0,max=365"`
+	Notes           string `json:"notes"`
+}
+
+type UpdateSupplierRequest struct {
+	Code            string `json:"code" validate:"required,max=50"`
+	Name            string `json:"name" validate:"required,min=1,max=255"`
+	ContactPerson   string `json:"contact_person" validate:"max=255"`
+	Email           string `json:"email" validate:"omitempty,email,max=255"`
+	Phone           string `json:"phone" validate:"omitempty,max=20"`
+	Address         string `json:"address"`
+	City            string `json:"city" validate:"max=100"`
+	TaxNumber       string `json:"tax_number" validate:"max=50"`
+	PaymentTermDays int    `json:"payment_term_days" validate:"min=0,max=365"`
+	Notes           string `json:"notes"`
+	IsActive        bool   `json:"is_active"`
+}
+
+type SupplierQuery struct {
+	Search   string `query:"search"`
+	IsActive *bool  `query:"is_active"`
+}
+
+type SupplierResponse struct {
+	ID              uint64 `json:"id"`
+	Code            string `json:"code"`
+	Name            string `json:"name"`
+	ContactPerson   string `json:"contact_person"`
+	Email           string `json:"email"`
+	Phone           string `json:"phone"`
+	Address         string `json:"address"`
+	City            string `json:"city"`
+	TaxNumber       string `json:"tax_number"`
+	PaymentTermDays int    `json:"payment_term_days"`
+	Notes           string `json:"notes"`
+	IsActive        bool   `json:"is_active"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+type PurchaseOrderQuery struct {
+	SupplierID *uint64 `query:"supplier_id"`
+	OutletID   *uint64 `query:"outlet_id"`
+	Status     string  `query:"status"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID   uint64                     `json:"supplier_id" validate:"required"`
+	OutletID     uint64                     `json:"outlet_id" validate:"required"`
+	ExpectedDate *time.Time                 `json:"expected_date"`
+	Notes        string                     `json:"notes" validate:"max=1000"`
+	Items        []PurchaseOrderItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+type UpdatePurchaseOrderRequest struct {
+	ExpectedDate *time.Time                 `json:"expected_date"`
+	Notes        string                     `json:"notes" validate:"max=1000"`
+	Items        []PurchaseOrderItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// PurchaseOrderItemRequest is one product, or variant of the product, to
+// order. Quantity and UnitCost are in Unit, the product's base unit when
+// empty.
+type PurchaseOrderItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required"`
+	VariantID *uint64 `json:"variant_id"`
+	Unit      string  `json:"unit" validate:"max=50"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	UnitCost  float64 `json:"unit_cost" validate:"min=0"`
+}
+
+type PurchaseOrderResponse struct {
+	ID           uint64                      `json:"id"`
+	OrderNumber  string                      `json:"order_number"`
+	SupplierID   uint64                      `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name"`
+	OutletID     uint64                      `json:"outlet_id"`
+	OutletName   string                      `json:"outlet_name"`
+	Status       string                      `json:"status"`
+	ExpectedDate *string                     `json:"expected_date"`
+	TotalAmount  float64                     `json:"total_amount"`
+	Notes        string                      `json:"notes"`
+	CreatedBy    uint64                      `json:"created_by"`
+	OrderedBy    *uint64                     `json:"ordered_by"`
+	OrderedAt    *string                     `json:"ordered_at"`
+	Items        []PurchaseOrderItemResponse `json:"items,omitempty"`
+	CreatedAt    string                      `json:"created_at"`
+	UpdatedAt    string                      `json:"updated_at"`
+}
+
+type PurchaseOrderItemResponse struct {
+	ID                  uint64  `json:"id"`
+	ProductID           uint64  `json:"product_id"`
+	SKU                 string  `json:"sku"`
+	ProductName         string  `json:"product_name"`
+	VariantID           *uint64 `json:"variant_id"`
+	VariantSKU          string  `json:"variant_sku,omitempty"`
+	VariantName         string  `json:"variant_name,omitempty"`
+	Unit                string  `json:"unit"`
+	UnitFactor          float64 `json:"unit_factor"`
+	Quantity            float64 `json:"quantity"`
+	UnitCost            float64 `json:"unit_cost"`
+	Subtotal            float64 `json:"subtotal"`
+	ReceivedQuantity    float64 `json:"received_quantity"`
+	OutstandingQuantity float64 `json:"outstanding_quantity"`
+}
+
+type GoodsReceiptQuery struct {
+	SupplierID      *uint64 `query:"supplier_id"`
+	OutletID        *uint64 `query:"outlet_id"`
+	PurchaseOrderID *uint64 `query:"purchase_order_id"`
+}
+
+// CreateGoodsReceiptRequest receives goods without a purchase order
+type CreateGoodsReceiptRequest struct {
+	SupplierID      uint64                    `json:"supplier_id" validate:"required"`
+	OutletID        uint64                    `json:"outlet_id" validate:"required"`
+	SupplierInvoice string                    `json:"supplier_invoice" validate:"max=100"`
+	Notes           string                    `json:"notes" validate:"max=1000"`
+	Items           []GoodsReceiptItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// ReceivePurchaseOrderRequest receives goods against a purchase order
+type ReceivePurchaseOrderRequest struct {
+	SupplierInvoice string                    `json:"supplier_invoice" validate:"max=100"`
+	Notes           string                    `json:"notes" validate:"max=1000"`
+	Items           []GoodsReceiptItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+// GoodsReceiptItemRequest is one received product, or variant of the
+// product. UnitCost defaults to
+// the purchase order's unit cost, or to the product's cost price when
+// receiving without an order. LotNumber is required for products that track
+// lots and not allowed for others. Quantity and UnitCost are in Unit, which
+// defaults to the purchase order line's unit, or to the product's base unit
+// when receiving without an order.
+type GoodsReceiptItemRequest struct {
+	ProductID  uint64     `json:"product_id" validate:"required"`
+	VariantID  *uint64    `json:"variant_id"`
+	Unit       string     `json:"unit" validate:"max=50"`
+	Quantity   float64    `json:"quantity" validate:"required,gt=0"`
+	UnitCost   *float64   `json:"unit_cost" validate:"omitempty,min=0"`
+	LotNumber  string     `json:"lot_number" validate:"max=100"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+}
+
+type GoodsReceiptResponse struct {
+	ID              uint64                     `json:"id"`
+	ReceiptNumber   string                     `json:"receipt_number"`
+	PurchaseOrderID *uint64                    `json:"purchase_order_id"`
+	OrderNumber     string                     `json:"order_number,omitempty"`
+	SupplierID      uint64                     `json:"supplier_id"`
+	SupplierName    string                     `json:"supplier_name"`
+	OutletID        uint64                     `json:"outlet_id"`
+	OutletName      string                     `json:"outlet_name"`
+	SupplierInvoice string                     `json:"supplier_invoice"`
+	CostPricePolicy string                     `json:"cost_price_policy"`
+	TotalCost       float64                    `json:"total_cost"`
+	Notes           string                     `json:"notes"`
+	ReceivedBy      uint64                     `json:"received_by"`
+	Items           []GoodsReceiptItemResponse `json:"items,omitempty"`
+	CreatedAt       string                     `json:"created_at"`
+}
+
+type GoodsReceiptItemResponse struct {
+	ID                  uint64  `json:"id"`
+	PurchaseOrderItemID *uint64 `json:"purchase_order_item_id"`
+	ProductID           uint64  `json:"product_id"`
+	SKU                 string  `json:"sku"`
+	ProductName         string  `json:"product_name"`
+	VariantID           *uint64 `json:"variant_id"`
+	VariantSKU          string  `json:"variant_sku,omitempty"`
+	VariantName         string  `json:"variant_name,omitempty"`
+	Unit                string  `json:"unit"`
+	UnitFactor          float64 `json:"unit_factor"`
+	Quantity            float64 `json:"quantity"`
+	UnitCost            float64 `json:"unit_cost"`
+	Subtotal            float64 `json:"subtotal"`
+	PreviousCostPrice   float64 `json:"previous_cost_price"`
+	NewCostPrice        float64 `json:"new_cost_price"`
+	LotID               *uint64 `json:"lot_id"`
+	LotNumber           string  `json:"lot_number,omitempty"`
+	ExpiryDate          *string `json:"expiry_date"`
+}
@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	PurchaseOrderStatusDraft             = "draft"
+	PurchaseOrderStatusOrdered           = "ordered"
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	PurchaseOrderStatusReceived          = "received"
+	PurchaseOrderStatusCancelled         = "cancelled"
+)
+
+const (
+	CostPricePolicyLastCost        = "last_cost"
+	CostPricePolicyWeightedAverage = "weighted_average"
+)
+
+const (
+	MovementTypeIn        = "in"
+	ReferenceTypePurchase = "purchase"
+)
+
+type Supplier struct {
+	ID              uint64
+	TenantID        uint64
+	Code            string
+	Name            string
+	ContactPerson   string
+	Email           string
+	Phone           string
+	Address         string
+	City            string
+	TaxNumber       string
+	PaymentTermDays int
+	Notes           string
+	IsActive        bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type PurchaseOrder struct {
+	ID           uint64
+	TenantID     uint64
+	OrderNumber  string
+	SupplierID   uint64
+	SupplierName string
+	OutletID     uint64
+	OutletName   string
+	Status       string
+	ExpectedDate *time.Time
+	TotalAmount  float64
+	Notes        string
+	CreatedBy    uint64
+	OrderedBy    *uint64
+	OrderedAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Items        []*PurchaseOrderItem
+}
+
+func (p *PurchaseOrder) IsDraft() bool {
+	return p.Status == PurchaseOrderStatusDraft
+}
+
+// CanReceive reports whether goods can still be received against the order
+func (p *PurchaseOrder) CanReceive() bool {
+	return p.Status == PurchaseOrderStatusOrdered || p.Status == PurchaseOrderStatusPartiallyReceived
+}
+
+// CanCancel reports whether the order can be cancelled. Orders that have
+// received goods cannot.
+func (p *PurchaseOrder) CanCancel() bool {
+	return p.Status == PurchaseOrderStatusDraft || p.Status == PurchaseOrderStatusOrdered
+}
+
+// ItemsTotal is the expected cost of the order
+func (p *PurchaseOrder) ItemsTotal() float64 {
+	var total float64
+	for _, item := range p.Items {
+		total += item.Subtotal()
+	}
+	return math.Round(total*100) / 100
+}
+
+// PurchaseOrderItem is one ordered product, or variant of a product. Its
+// quantities and unit cost are in Unit, of which one is UnitFactor of the
+// product's base unit.
+type PurchaseOrderItem struct {
+	ID               uint64
+	ProductID        uint64
+	SKU              string
+	ProductName      string
+	VariantID        *uint64
+	VariantSKU       string
+	VariantName      string
+	Unit             string
+	UnitFactor       float64
+	Quantity         float64
+	UnitCost         float64
+	ReceivedQuantity float64
+}
+
+func (i *PurchaseOrderItem) Key() ItemKey {
+	return NewItemKey(i.ProductID, i.VariantID)
+}
+
+func (i *PurchaseOrderItem) Subtotal() float64 {
+	return i.Quantity * i.UnitCost
+}
+
+func (i *PurchaseOrderItem) OutstandingQuantity() float64 {
+	if i.ReceivedQuantity >= i.Quantity {
+		return 0
+	}
+	return RoundQuantity(i.Quantity - i.ReceivedQuantity)
+}
+
+// ItemKey identifies the stock an order or receipt line is for: a product,
+// or one of its variants. A product and each of its variants can appear once
+// per order and receipt.
+type ItemKey struct {
+	ProductID uint64
+	VariantID uint64 // 0 for the product itself
+}
+
+func NewItemKey(productID uint64, variantID *uint64) ItemKey {
+	key := ItemKey{ProductID: productID}
+	if variantID != nil {
+		key.VariantID = *variantID
+	}
+	return key
+}
+
+// RoundQuantity rounds a quantity to the three decimals it is stored with
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// UnitConversion is a unit a product is ordered or received in. One of the
+// unit is Factor of the product's base unit.
+type UnitConversion struct {
+	Unit         string
+	Factor       float64
+	AllowDecimal bool
+}
+
+// ProductUnits are the units of one product: its base unit, at factor 1,
+// and the units converted to it, keyed by unit code
+type ProductUnits struct {
+	BaseUnit string
+	Units    map[string]*UnitConversion
+}
+
+// Convert returns the unit a quantity is given in, the base unit when unit
+// is empty. Quantities in a unit that does not allow decimals must be whole.
+func (u *ProductUnits) Convert(unit string, quantity float64) (*UnitConversion, error) {
+	if unit == "" {
+		unit = u.BaseUnit
+	}
+
+	conversion, ok := u.Units[unit]
+	if !ok {
+		return nil, errors.New("unit is not configured for the product")
+	}
+	if !conversion.AllowDecimal && quantity != math.Trunc(quantity) {
+		return nil, errors.New("quantity must be a whole number of its unit")
+	}
+
+	return conversion, nil
+}
+
+// GoodsReceipt is a delivery from a supplier, received against a purchase
+// order or directly
+type GoodsReceipt struct {
+	ID              uint64
+	TenantID        uint64
+	ReceiptNumber   string
+	PurchaseOrderID *uint64
+	OrderNumber     string
+	SupplierID      uint64
+	SupplierName    string
+	OutletID        uint64
+	OutletName      string
+	SupplierInvoice string
+	CostPricePolicy string
+	TotalCost       float64
+	Notes           string
+	ReceivedBy      uint64
+	CreatedAt       time.Time
+	Items           []*GoodsReceiptItem
+}
+
+func (g *GoodsReceipt) ItemsTotal() float64 {
+	var total float64
+	for _, item := range g.Items {
+		total += item.Subtotal()
+	}
+	return math.Round(total*100) / 100
+}
+
+// GoodsReceiptItem is one received product, or variant of a product, which
+// goes into the variant's stock. PreviousCostPrice and NewCostPrice record
+// how the receipt changed the product's cost price, which its variants
+// share. Products that track lots are received into the lot named by
+// LotNumber.
+// Quantity and UnitCost are in Unit, of which one is UnitFactor of the
+// product's base unit; stock and cost prices are kept in the base unit.
+type GoodsReceiptItem struct {
+	ID                  uint64
+	PurchaseOrderItemID *uint64
+	ProductID           uint64
+	SKU                 string
+	ProductName         string
+	VariantID           *uint64
+	VariantSKU          string
+	VariantName         string
+	Unit                string
+	UnitFactor          float64
+	Quantity            float64
+	UnitCost            float64
+	PreviousCostPrice   float64
+	NewCostPrice        float64
+	LotID               *uint64
+	LotNumber           string
+	ExpiryDate          *time.Time
+}
+
+func (i *GoodsReceiptItem) Key() ItemKey {
+	return NewItemKey(i.ProductID, i.VariantID)
+}
+
+func (i *GoodsReceiptItem) Subtotal() float64 {
+	return i.Quantity * i.UnitCost
+}
+
+// BaseQuantity is the received quantity in the product's base unit
+func (i *GoodsReceiptItem) BaseQuantity() float64 {
+	return RoundQuantity(i.Quantity * i.UnitFactor)
+}
+
+// BaseUnitCost is the cost of one base unit of the received stock
+func (i *GoodsReceiptItem) BaseUnitCost() float64 {
+	if i.UnitFactor == 0 {
+		return i.UnitCost
+	}
+	return math.Round(i.UnitCost/i.UnitFactor*100) / 100
+}
+
+// PurchaseProduct is the product data needed to order and receive goods
+type PurchaseProduct struct {
+	ID         uint64
+	SKU        string
+	Name       string
+	CostPrice  float64
+	TrackStock bool
+	TrackLots  bool
+}
+
+// PurchaseVariant is the variant data needed to order and receive goods
+type PurchaseVariant struct {
+	ID        uint64
+	ProductID uint64
+	SKU       string
+	Name      string
+}
+
+// ReceivedCostPrice returns a product's cost price after receiving quantity
+// base units at unitCost. With the weighted average policy the units already
+// on hand keep their current cost; negative stock counts as none on hand.
+func ReceivedCostPrice(policy string, costPrice, onHand, quantity, unitCost float64) float64 {
+	if policy != CostPricePolicyWeightedAverage {
+		return unitCost
+	}
+
+	if onHand < 0 {
+		onHand = 0
+	}
+	if onHand+quantity <= 0 {
+		return unitCost
+	}
+
+	value := onHand*costPrice + quantity*unitCost
+	return math.Round(value/(onHand+quantity)*100) / 100
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+)
+
+type SupplierRepository interface {
+	Create(ctx context.Context, supplier *Supplier) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*Supplier, error)
+	FindAll(ctx context.Context, tenantID uint64, query SupplierQuery, limit, offset int) ([]*Supplier, int64, error)
+	Update(ctx context.Context, supplier *Supplier) error
+	Delete(ctx context.Context, tenantID, id uint64) error
+	IsCodeExists(ctx context.Context, tenantID uint64, code string, excludeID *uint64) (bool, error)
+	Count(ctx context.Context, tenantID uint64) (int64, error)
+}
+
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, order *PurchaseOrder) error
+	UpdateDraft(ctx context.Context, order *PurchaseOrder) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*PurchaseOrder, error)
+	FindAll(ctx context.Context, tenantID uint64, query PurchaseOrderQuery, limit, offset int) ([]*PurchaseOrder, int64, error)
+	MarkOrdered(ctx context.Context, tenantID, id, orderedBy uint64) error
+	Cancel(ctx context.Context, tenantID, id uint64) error
+	OutletExists(ctx context.Context, tenantID, outletID uint64) (bool, error)
+	FindProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*PurchaseProduct, error)
+	FindProductUnits(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*ProductUnits, error)
+	FindVariants(ctx context.Context, tenantID uint64, variantIDs []uint64) (map[uint64]*PurchaseVariant, error)
+}
+
+type GoodsReceiptRepository interface {
+	// Create saves the receipt, adds the goods to stock, updates product
+	// cost prices and, for receipts against a purchase order, the order's
+	// received quantities and status.
+	Create(ctx context.Context, receipt *GoodsReceipt) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*GoodsReceipt, error)
+	FindAll(ctx context.Context, tenantID uint64, query GoodsReceiptQuery, limit, offset int) ([]*GoodsReceipt, int64, error)
+	FindCostPricePolicy(ctx context.Context, tenantID uint64) (string, error)
+}
+
+type PurchasingService interface {
+	CreateSupplier(ctx context.Context, tenantID uint64, req CreateSupplierRequest) (*Supplier, error)
+	GetSupplier(ctx context.Context, tenantID, id uint64) (*Supplier, error)
+	GetSuppliers(ctx context.Context, tenantID uint64, query SupplierQuery, limit, offset int) ([]*Supplier, int64, error)
+	UpdateSupplier(ctx context.Context, tenantID, id uint64, req UpdateSupplierRequest) (*Supplier, error)
+	DeleteSupplier(ctx context.Context, tenantID, id uint64) error
+
+	CreatePurchaseOrder(ctx context.Context, tenantID, userID uint64, req CreatePurchaseOrderRequest) (*PurchaseOrder, error)
+	UpdatePurchaseOrder(ctx context.Context, tenantID, id uint64, req UpdatePurchaseOrderRequest) (*PurchaseOrder, error)
+	GetPurchaseOrder(ctx context.Context, tenantID, id uint64) (*PurchaseOrder, error)
+	GetPurchaseOrders(ctx context.Context, tenantID uint64, query PurchaseOrderQuery, limit, offset int) ([]*PurchaseOrder, int64, error)
+	PlacePurchaseOrder(ctx context.Context, tenantID, userID, id uint64) (*PurchaseOrder, error)
+	CancelPurchaseOrder(ctx context.Context, tenantID, id uint64) (*PurchaseOrder, error)
+	ReceivePurchaseOrder(ctx context.Context, tenantID, userID, id uint64, req ReceivePurchaseOrderRequest) (*GoodsReceipt, error)
+
+	CreateGoodsReceipt(ctx context.Context, tenantID, userID uint64, req CreateGoodsReceiptRequest) (*GoodsReceipt, error)
+	GetGoodsReceipt(ctx context.Context, tenantID, id uint64) (*GoodsReceipt, error)
+	GetGoodsReceipts(ctx context.Context, tenantID uint64, query GoodsReceiptQuery, limit, offset int) ([]*GoodsReceipt, int64, error)
+}
@@ -0,0 +1,620 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type PurchasingHandler struct {
+	purchasingService domain.PurchasingService
+}
+
+func NewPurchasingHandler(purchasingService domain.PurchasingService) *PurchasingHandler {
+	return &PurchasingHandler{
+		purchasingService: purchasingService,
+	}
+}
+
+func (h *PurchasingHandler) RegisterRoutes(e *echo.Group) {
+	// Supplier routes
+	suppliers := e.Group("/suppliers")
+	suppliers.GET("", h.GetSuppliers)
+	suppliers.POST("", h.CreateSupplier)
+	suppliers.GET("/:id", h.GetSupplier)
+	suppliers.PUT("/:id", h.UpdateSupplier)
+	suppliers.DELETE("/:id", h.DeleteSupplier)
+
+	// Purchase order routes
+	orders := e.Group("/purchase-orders")
+	orders.GET("", h.GetPurchaseOrders)
+	orders.POST("", h.CreatePurchaseOrder)
+	orders.GET("/:id", h.GetPurchaseOrder)
+	orders.PUT("/:id", h.UpdatePurchaseOrder)
+	orders.POST("/:id/place", h.PlacePurchaseOrder)
+	orders.POST("/:id/cancel", h.CancelPurchaseOrder)
+	orders.POST("/:id/receive", h.ReceivePurchaseOrder)
+
+	// Goods receipt routes
+	receipts := e.Group("/goods-receipts")
+	receipts.GET("", h.GetGoodsReceipts)
+	receipts.POST("", h.CreateGoodsReceipt)
+	receipts.GET("/:id", h.GetGoodsReceipt)
+}
+
+func (h *PurchasingHandler) CreateSupplier(c echo.Context) error {
+	var req domain.CreateSupplierRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	supplier, err := h.purchasingService.CreateSupplier(c.Request().Context(), tenantID, req)
+	if err != nil {
+		return h.supplierError(c, err, "Failed to create supplier")
+	}
+
+	return response.Created(c, "Supplier created successfully", h.supplierToResponse(supplier))
+}
+
+func (h *PurchasingHandler) GetSuppliers(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := pagination(c)
+
+	query := domain.SupplierQuery{
+		Search: c.QueryParam("search"),
+	}
+
+	if value := c.QueryParam("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return response.ValidationError(c, map[string][]string{
+				"is_active": {"Must be true or false"},
+			})
+		}
+		query.IsActive = &isActive
+	}
+
+	suppliers, total, err := h.purchasingService.GetSuppliers(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get suppliers")
+	}
+
+	supplierResponses := make([]domain.SupplierResponse, len(suppliers))
+	for i, supplier := range suppliers {
+		supplierResponses[i] = h.supplierToResponse(supplier)
+	}
+
+	return response.SuccessWithPagination(c, "Suppliers retrieved successfully", supplierResponses, page, limit, int(total))
+}
+
+func (h *PurchasingHandler) GetSupplier(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid supplier ID")
+	}
+
+	supplier, err := h.purchasingService.GetSupplier(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.supplierError(c, err, "Failed to get supplier")
+	}
+
+	return response.Success(c, "Supplier retrieved successfully", h.supplierToResponse(supplier))
+}
+
+func (h *PurchasingHandler) UpdateSupplier(c echo.Context) error {
+	var req domain.UpdateSupplierRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid supplier ID")
+	}
+
+	supplier, err := h.purchasingService.UpdateSupplier(c.Request().Context(), tenantID, id, req)
+	if err != nil {
+		return h.supplierError(c, err, "Failed to update supplier")
+	}
+
+	return response.Success(c, "Supplier updated successfully", h.supplierToResponse(supplier))
+}
+
+func (h *PurchasingHandler) DeleteSupplier(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid supplier ID")
+	}
+
+	if err := h.purchasingService.DeleteSupplier(c.Request().Context(), tenantID, id); err != nil {
+		return h.supplierError(c, err, "Failed to delete supplier")
+	}
+
+	return response.Success(c, "Supplier deleted successfully", nil)
+}
+
+func (h *PurchasingHandler) CreatePurchaseOrder(c echo.Context) error {
+	var req domain.CreatePurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	order, err := h.purchasingService.CreatePurchaseOrder(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to create purchase order")
+	}
+
+	return response.Created(c, "Purchase order created successfully", h.orderToResponse(order))
+}
+
+func (h *PurchasingHandler) GetPurchaseOrders(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := pagination(c)
+
+	query := domain.PurchaseOrderQuery{}
+	fieldErrors := idParams(c, map[string]**uint64{
+		"supplier_id": &query.SupplierID,
+		"outlet_id":   &query.OutletID,
+	})
+
+	switch status := c.QueryParam("status"); status {
+	case "", domain.PurchaseOrderStatusDraft, domain.PurchaseOrderStatusOrdered, domain.PurchaseOrderStatusPartiallyReceived,
+		domain.PurchaseOrderStatusReceived, domain.PurchaseOrderStatusCancelled:
+		query.Status = status
+	default:
+		fieldErrors["status"] = []string{"Must be one of draft, ordered, partially_received, received, cancelled"}
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	orders, total, err := h.purchasingService.GetPurchaseOrders(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get purchase orders")
+	}
+
+	orderResponses := make([]domain.PurchaseOrderResponse, len(orders))
+	for i, order := range orders {
+		orderResponses[i] = h.orderToResponse(order)
+	}
+
+	return response.SuccessWithPagination(c, "Purchase orders retrieved successfully", orderResponses, page, limit, int(total))
+}
+
+func (h *PurchasingHandler) GetPurchaseOrder(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid purchase order ID")
+	}
+
+	order, err := h.purchasingService.GetPurchaseOrder(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to get purchase order")
+	}
+
+	return response.Success(c, "Purchase order retrieved successfully", h.orderToResponse(order))
+}
+
+func (h *PurchasingHandler) UpdatePurchaseOrder(c echo.Context) error {
+	var req domain.UpdatePurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid purchase order ID")
+	}
+
+	order, err := h.purchasingService.UpdatePurchaseOrder(c.Request().Context(), tenantID, id, req)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to update purchase order")
+	}
+
+	return response.Success(c, "Purchase order updated successfully", h.orderToResponse(order))
+}
+
+func (h *PurchasingHandler) PlacePurchaseOrder(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid purchase order ID")
+	}
+
+	order, err := h.purchasingService.PlacePurchaseOrder(c.Request().Context(), tenantID, userID, id)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to place purchase order")
+	}
+
+	return response.Success(c, "Purchase order placed successfully", h.orderToResponse(order))
+}
+
+func (h *PurchasingHandler) CancelPurchaseOrder(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid purchase order ID")
+	}
+
+	order, err := h.purchasingService.CancelPurchaseOrder(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to cancel purchase order")
+	}
+
+	return response.Success(c, "Purchase order cancelled successfully", h.orderToResponse(order))
+}
+
+func (h *PurchasingHandler) ReceivePurchaseOrder(c echo.Context) error {
+	var req domain.ReceivePurchaseOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid purchase order ID")
+	}
+
+	receipt, err := h.purchasingService.ReceivePurchaseOrder(c.Request().Context(), tenantID, userID, id, req)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to receive purchase order")
+	}
+
+	return response.Created(c, "Goods received successfully", h.receiptToResponse(receipt))
+}
+
+func (h *PurchasingHandler) CreateGoodsReceipt(c echo.Context) error {
+	var req domain.CreateGoodsReceiptRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	receipt, err := h.purchasingService.CreateGoodsReceipt(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to create goods receipt")
+	}
+
+	return response.Created(c, "Goods received successfully", h.receiptToResponse(receipt))
+}
+
+func (h *PurchasingHandler) GetGoodsReceipts(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := pagination(c)
+
+	query := domain.GoodsReceiptQuery{}
+	fieldErrors := idParams(c, map[string]**uint64{
+		"supplier_id":       &query.SupplierID,
+		"outlet_id":         &query.OutletID,
+		"purchase_order_id": &query.PurchaseOrderID,
+	})
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	receipts, total, err := h.purchasingService.GetGoodsReceipts(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		return response.InternalError(c, "Failed to get goods receipts")
+	}
+
+	receiptResponses := make([]domain.GoodsReceiptResponse, len(receipts))
+	for i, receipt := range receipts {
+		receiptResponses[i] = h.receiptToResponse(receipt)
+	}
+
+	return response.SuccessWithPagination(c, "Goods receipts retrieved successfully", receiptResponses, page, limit, int(total))
+}
+
+func (h *PurchasingHandler) GetGoodsReceipt(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid goods receipt ID")
+	}
+
+	receipt, err := h.purchasingService.GetGoodsReceipt(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.purchaseError(c, err, "Failed to get goods receipt")
+	}
+
+	return response.Success(c, "Goods receipt retrieved successfully", h.receiptToResponse(receipt))
+}
+
+// Helper functions
+
+// pagination reads page and limit, defaulting to the first 20 records
+func pagination(c echo.Context) (page, limit, offset int) {
+	page = 1
+	limit = 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	return page, limit, (page - 1) * limit
+}
+
+// idParams parses optional ID query parameters into their targets and
+// returns the field errors of invalid ones
+func idParams(c echo.Context, targets map[string]**uint64) map[string][]string {
+	fieldErrors := map[string][]string{}
+
+	for param, target := range targets {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be a valid ID"}
+				continue
+			}
+			*target = &id
+		}
+	}
+
+	return fieldErrors
+}
+
+func (h *PurchasingHandler) supplierError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "supplier not found":
+		return response.NotFound(c, "Supplier not found")
+	case "supplier with this code already exists":
+		return response.Error(c, http.StatusConflict, err.Error(), map[string][]string{
+			"code": {"Code already exists"},
+		})
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *PurchasingHandler) purchaseError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "purchase order not found":
+		return response.NotFound(c, "Purchase order not found")
+	case "goods receipt not found":
+		return response.NotFound(c, "Goods receipt not found")
+	case "supplier not found":
+		return response.ValidationError(c, map[string][]string{
+			"supplier_id": {"Supplier not found"},
+		})
+	case "supplier is inactive":
+		return response.ValidationError(c, map[string][]string{
+			"supplier_id": {"Supplier is inactive"},
+		})
+	case "outlet not found":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet not found"},
+		})
+	case "product not found":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product not found"},
+		})
+	case "variant not found":
+		return response.ValidationError(c, map[string][]string{
+			"variant_id": {"Variant not found"},
+		})
+	case "product does not track stock":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product does not track stock"},
+		})
+	case "duplicate product in purchase order", "duplicate product in receipt":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Each product and variant can only be listed once"},
+		})
+	case "lot number is required":
+		return response.ValidationError(c, map[string][]string{
+			"lot_number": {"Lot number is required for products that track lots"},
+		})
+	case "product does not track lots":
+		return response.ValidationError(c, map[string][]string{
+			"lot_number": {"Product does not track lots"},
+		})
+	case "unit is not configured for the product":
+		return response.ValidationError(c, map[string][]string{
+			"unit": {"Unit is not configured for the product"},
+		})
+	case "unit does not match the purchase order":
+		return response.ValidationError(c, map[string][]string{
+			"unit": {"Unit does not match the purchase order"},
+		})
+	case "quantity must be a whole number of its unit":
+		return response.ValidationError(c, map[string][]string{
+			"quantity": {"Quantity must be a whole number of its unit"},
+		})
+	case "lot expiry date does not match":
+		return response.ValidationError(c, map[string][]string{
+			"expiry_date": {"Expiry date does not match the existing lot"},
+		})
+	case "product is not part of the purchase order":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Product is not part of the purchase order"},
+		})
+	case "received quantity exceeds outstanding quantity":
+		return response.ValidationError(c, map[string][]string{
+			"items": {"Received quantity exceeds the outstanding quantity"},
+		})
+	case "purchase order is not a draft", "purchase order is not awaiting receipt", "purchase order cannot be cancelled":
+		return response.Error(c, http.StatusConflict, err.Error(), nil)
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *PurchasingHandler) supplierToResponse(supplier *domain.Supplier) domain.SupplierResponse {
+	return domain.SupplierResponse{
+		ID:              supplier.ID,
+		Code:            supplier.Code,
+		Name:            supplier.Name,
+		ContactPerson:   supplier.ContactPerson,
+		Email:           supplier.Email,
+		Phone:           supplier.Phone,
+		Address:         supplier.Address,
+		City:            supplier.City,
+		TaxNumber:       supplier.TaxNumber,
+		PaymentTermDays: supplier.PaymentTermDays,
+		Notes:           supplier.Notes,
+		IsActive:        supplier.IsActive,
+		CreatedAt:       supplier.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       supplier.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *PurchasingHandler) orderToResponse(order *domain.PurchaseOrder) domain.PurchaseOrderResponse {
+	orderResponse := domain.PurchaseOrderResponse{
+		ID:           order.ID,
+		OrderNumber:  order.OrderNumber,
+		SupplierID:   order.SupplierID,
+		SupplierName: order.SupplierName,
+		OutletID:     order.OutletID,
+		OutletName:   order.OutletName,
+		Status:       order.Status,
+		TotalAmount:  order.TotalAmount,
+		Notes:        order.Notes,
+		CreatedBy:    order.CreatedBy,
+		OrderedBy:    order.OrderedBy,
+		CreatedAt:    order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    order.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if order.ExpectedDate != nil {
+		expectedDate := order.ExpectedDate.Format("2006-01-02")
+		orderResponse.ExpectedDate = &expectedDate
+	}
+	if order.OrderedAt != nil {
+		orderedAt := order.OrderedAt.Format(time.RFC3339)
+		orderResponse.OrderedAt = &orderedAt
+	}
+
+	if order.Items != nil {
+		orderResponse.Items = make([]domain.PurchaseOrderItemResponse, len(order.Items))
+		for i, item := range order.Items {
+			orderResponse.Items[i] = domain.PurchaseOrderItemResponse{
+				ID:                  item.ID,
+				ProductID:           item.ProductID,
+				SKU:                 item.SKU,
+				ProductName:         item.ProductName,
+				VariantID:           item.VariantID,
+				VariantSKU:          item.VariantSKU,
+				VariantName:         item.VariantName,
+				Unit:                item.Unit,
+				UnitFactor:          item.UnitFactor,
+				Quantity:            item.Quantity,
+				UnitCost:            item.UnitCost,
+				Subtotal:            item.Subtotal(),
+				ReceivedQuantity:    item.ReceivedQuantity,
+				OutstandingQuantity: item.OutstandingQuantity(),
+			}
+		}
+	}
+
+	return orderResponse
+}
+
+func (h *PurchasingHandler) receiptToResponse(receipt *domain.GoodsReceipt) domain.GoodsReceiptResponse {
+	receiptResponse := domain.GoodsReceiptResponse{
+		ID:              receipt.ID,
+		ReceiptNumber:   receipt.ReceiptNumber,
+		PurchaseOrderID: receipt.PurchaseOrderID,
+		OrderNumber:     receipt.OrderNumber,
+		SupplierID:      receipt.SupplierID,
+		SupplierName:    receipt.SupplierName,
+		OutletID:        receipt.OutletID,
+		OutletName:      receipt.OutletName,
+		SupplierInvoice: receipt.SupplierInvoice,
+		CostPricePolicy: receipt.CostPricePolicy,
+		TotalCost:       receipt.TotalCost,
+		Notes:           receipt.Notes,
+		ReceivedBy:      receipt.ReceivedBy,
+		CreatedAt:       receipt.CreatedAt.Format(time.RFC3339),
+	}
+
+	if receipt.Items != nil {
+		receiptResponse.Items = make([]domain.GoodsReceiptItemResponse, len(receipt.Items))
+		for i, item := range receipt.Items {
+			receiptResponse.Items[i] = domain.GoodsReceiptItemResponse{
+				ID:                  item.ID,
+				PurchaseOrderItemID: item.PurchaseOrderItemID,
+				ProductID:           item.ProductID,
+				SKU:                 item.SKU,
+				ProductName:         item.ProductName,
+				VariantID:           item.VariantID,
+				VariantSKU:          item.VariantSKU,
+				VariantName:         item.VariantName,
+				Unit:                item.Unit,
+				UnitFactor:          item.UnitFactor,
+				Quantity:            item.Quantity,
+				UnitCost:            item.UnitCost,
+				Subtotal:            item.Subtotal(),
+				PreviousCostPrice:   item.PreviousCostPrice,
+				NewCostPrice:        item.NewCostPrice,
+				LotID:               item.LotID,
+				LotNumber:           item.LotNumber,
+			}
+			if item.ExpiryDate != nil {
+				expiryDate := item.ExpiryDate.Format("2006-01-02")
+				receiptResponse.Items[i].ExpiryDate = &expiryDate
+			}
+		}
+	}
+
+	return receiptResponse
+}
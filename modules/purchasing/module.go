@@ -0,0 +1,64 @@
+package purchasing
+
+import (
+	"github.com/exven/pos-system/modules/purchasing/handlers"
+	"github.com/exven/pos-system/modules/purchasing/persistence"
+	"github.com/exven/pos-system/modules/purchasing/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container container.Container
+	db        *gorm.DB
+	eventBus  messaging.EventBus
+}
+
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	eventBus messaging.EventBus,
+) *Module {
+	return &Module{
+		container: container,
+		db:        db,
+		eventBus:  eventBus,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("purchasing.supplierRepository", func() interface{} {
+		return persistence.NewSupplierRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("purchasing.purchaseOrderRepository", func() interface{} {
+		return persistence.NewPurchaseOrderRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("purchasing.goodsReceiptRepository", func() interface{} {
+		return persistence.NewGoodsReceiptRepository(m.db)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("purchasing.purchasingService", func() interface{} {
+		supplierRepo := persistence.NewSupplierRepository(m.db)
+		orderRepo := persistence.NewPurchaseOrderRepository(m.db)
+		receiptRepo := persistence.NewGoodsReceiptRepository(m.db)
+		return services.NewPurchasingService(supplierRepo, orderRepo, receiptRepo, m.eventBus)
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("purchasing.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+func (m *Module) GetHandler() *handlers.PurchasingHandler {
+	supplierRepo := persistence.NewSupplierRepository(m.db)
+	orderRepo := persistence.NewPurchaseOrderRepository(m.db)
+	receiptRepo := persistence.NewGoodsReceiptRepository(m.db)
+	purchasingService := services.NewPurchasingService(supplierRepo, orderRepo, receiptRepo, m.eventBus)
+	return handlers.NewPurchasingHandler(purchasingService)
+}
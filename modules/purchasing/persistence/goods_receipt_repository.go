@@ -0,0 +1,339 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/stockledger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type goodsReceiptRepository struct {
+	db *gorm.DB
+}
+
+func NewGoodsReceiptRepository(db *gorm.DB) domain.GoodsReceiptRepository {
+	return &goodsReceiptRepository{db: db}
+}
+
+// Create records the receipt in one transaction. Items are processed in
+// product and variant order so concurrent receipts lock products and stock
+// rows in the same order.
+func (r *goodsReceiptRepository) Create(ctx context.Context, receipt *domain.GoodsReceipt) error {
+	items := make([]*domain.GoodsReceiptItem, len(receipt.Items))
+	copy(items, receipt.Items)
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i].Key(), items[j].Key()
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		return a.VariantID < b.VariantID
+	})
+
+	var model GoodsReceiptModel
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var orderItems map[domain.ItemKey]*PurchaseOrderItemModel
+		if receipt.PurchaseOrderID != nil {
+			var err error
+			orderItems, err = r.matchOrderItems(tx, receipt, items)
+			if err != nil {
+				return err
+			}
+		}
+
+		number, err := nextDocumentNumber(tx, "goods_receipts", "receipt_number", "GRN", receipt.TenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		receipt.ReceiptNumber = number
+
+		for _, item := range items {
+			if err := r.updateCostPrice(tx, receipt, item); err != nil {
+				return err
+			}
+			if item.LotNumber == "" {
+				continue
+			}
+			lot, err := stockledger.LockLot(tx, receipt.TenantID, item.ProductID, receipt.OutletID, item.LotNumber, item.ExpiryDate)
+			if err != nil {
+				return err
+			}
+			lotID := lot.ID
+			item.LotID = &lotID
+		}
+
+		receipt.TotalCost = receipt.ItemsTotal()
+		model.FromDomainReceipt(receipt)
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create goods receipt: %w", err)
+		}
+
+		for _, item := range items {
+			quantity := item.BaseQuantity()
+			unitCost := item.BaseUnitCost()
+			costPrice := item.PreviousCostPrice
+			var lots []stockledger.LotQuantity
+			if item.LotNumber != "" {
+				lots = []stockledger.LotQuantity{{LotNumber: item.LotNumber, ExpiryDate: item.ExpiryDate, Quantity: quantity}}
+			}
+
+			// Stock from before costing is valued at the cost price the
+			// product had before this receipt changed it. Variants are
+			// received into their own stock rows.
+			_, err := stockledger.Apply(tx, stockledger.Change{
+				ProductID:     item.ProductID,
+				VariantID:     item.VariantID,
+				OutletID:      receipt.OutletID,
+				Quantity:      quantity,
+				MovementType:  domain.MovementTypeIn,
+				ReferenceType: domain.ReferenceTypePurchase,
+				ReferenceID:   model.ID,
+				Notes:         fmt.Sprintf("Goods receipt %s", model.ReceiptNumber),
+				CreatedBy:     receipt.ReceivedBy,
+				UnitCost:      &unitCost,
+				CostPrice:     &costPrice,
+				Lots:          lots,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if receipt.PurchaseOrderID != nil {
+			return r.updateOrder(tx, *receipt.PurchaseOrderID, orderItems, items)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	receipt.ID = model.ID
+	receipt.CreatedAt = model.CreatedAt
+	for i := range model.Items {
+		receipt.Items[i].ID = model.Items[i].ID
+	}
+
+	return nil
+}
+
+func (r *goodsReceiptRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.GoodsReceipt, error) {
+	var model GoodsReceiptModel
+
+	err := r.db.WithContext(ctx).
+		Preload("PurchaseOrder").
+		Preload("Supplier").
+		Preload("Outlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Variant").
+		Preload("Items.Lot").
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("goods receipt not found")
+		}
+		return nil, fmt.Errorf("failed to find goods receipt: %w", err)
+	}
+
+	return model.ToDomainReceipt(), nil
+}
+
+func (r *goodsReceiptRepository) FindAll(ctx context.Context, tenantID uint64, query domain.GoodsReceiptQuery, limit, offset int) ([]*domain.GoodsReceipt, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&GoodsReceiptModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.SupplierID != nil {
+		filtered = filtered.Where("supplier_id = ?", *query.SupplierID)
+	}
+	if query.OutletID != nil {
+		filtered = filtered.Where("outlet_id = ?", *query.OutletID)
+	}
+	if query.PurchaseOrderID != nil {
+		filtered = filtered.Where("purchase_order_id = ?", *query.PurchaseOrderID)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count goods receipts: %w", err)
+	}
+
+	var models []GoodsReceiptModel
+	err := filtered.
+		Preload("PurchaseOrder").
+		Preload("Supplier").
+		Preload("Outlet").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find goods receipts: %w", err)
+	}
+
+	receipts := make([]*domain.GoodsReceipt, len(models))
+	for i := range models {
+		receipts[i] = models[i].ToDomainReceipt()
+	}
+
+	return receipts, total, nil
+}
+
+// FindCostPricePolicy reads the cost price policy from the tenant settings
+// document. Tenants that never saved it use last cost.
+func (r *goodsReceiptRepository) FindCostPricePolicy(ctx context.Context, tenantID uint64) (string, error) {
+	var policy string
+
+	err := r.db.WithContext(ctx).
+		Table("tenants").
+		Select("COALESCE(NULLIF(settings->'inventory'->>'cost_price_policy', ''), ?)", domain.CostPricePolicyLastCost).
+		Where("id = ?", tenantID).
+		Scan(&policy).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to find cost price policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// matchOrderItems locks the purchase order, checks the receipt against its
+// outstanding quantities and links the receipt items to the order items
+func (r *goodsReceiptRepository) matchOrderItems(tx *gorm.DB, receipt *domain.GoodsReceipt, items []*domain.GoodsReceiptItem) (map[domain.ItemKey]*PurchaseOrderItemModel, error) {
+	order, err := lockPurchaseOrder(tx, receipt.TenantID, *receipt.PurchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.ToDomainOrder().CanReceive() {
+		return nil, errors.New("purchase order is not awaiting receipt")
+	}
+
+	var models []PurchaseOrderItemModel
+	if err := tx.Where("purchase_order_id = ?", order.ID).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find purchase order items: %w", err)
+	}
+
+	orderItems := make(map[domain.ItemKey]*PurchaseOrderItemModel, len(models))
+	for i := range models {
+		orderItems[domain.NewItemKey(models[i].ProductID, models[i].VariantID)] = &models[i]
+	}
+
+	for _, item := range items {
+		orderItem, ok := orderItems[item.Key()]
+		if !ok {
+			return nil, errors.New("product is not part of the purchase order")
+		}
+		if item.Unit != orderItem.Unit {
+			return nil, errors.New("unit does not match the purchase order")
+		}
+		if item.Quantity > orderItem.ToDomainItem().OutstandingQuantity() {
+			return nil, errors.New("received quantity exceeds outstanding quantity")
+		}
+		orderItemID := orderItem.ID
+		item.PurchaseOrderItemID = &orderItemID
+	}
+
+	return orderItems, nil
+}
+
+// updateCostPrice locks the product and sets its cost price according to
+// the policy. The weighted average uses the stock on hand across all of the
+// tenant's outlets before the receipt. Cost prices are per base unit. A
+// changed cost price is recorded in the product's price history.
+func (r *goodsReceiptRepository) updateCostPrice(tx *gorm.DB, receipt *domain.GoodsReceipt, item *domain.GoodsReceiptItem) error {
+	var product PurchaseProductModel
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", item.ProductID, receipt.TenantID).
+		First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product not found")
+		}
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	var onHand float64
+	err = tx.Model(&ProductStockModel{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("product_id = ?", product.ID).
+		Scan(&onHand).Error
+	if err != nil {
+		return fmt.Errorf("failed to find product stock: %w", err)
+	}
+
+	item.PreviousCostPrice = product.CostPrice
+	item.NewCostPrice = domain.ReceivedCostPrice(receipt.CostPricePolicy, product.CostPrice, onHand, item.BaseQuantity(), item.BaseUnitCost())
+
+	if item.NewCostPrice == product.CostPrice {
+		return nil
+	}
+
+	now := time.Now()
+	err = tx.Model(&PurchaseProductModel{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"cost_price": item.NewCostPrice,
+		"updated_at": now,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update product cost price: %w", err)
+	}
+
+	return database.RecordPriceChange(tx, &database.ProductPriceChange{
+		TenantID:        receipt.TenantID,
+		ProductID:       product.ID,
+		OldCostPrice:    product.CostPrice,
+		NewCostPrice:    item.NewCostPrice,
+		OldSellingPrice: product.SellingPrice,
+		NewSellingPrice: product.SellingPrice,
+		Source:          database.PriceChangeSourceGoodsReceipt,
+		Reason:          fmt.Sprintf("Goods receipt %s", receipt.ReceiptNumber),
+		ChangedBy:       receipt.ReceivedBy,
+		EffectiveAt:     now,
+	})
+}
+
+// updateOrder adds the received quantities to the order items and moves the
+// order to partially received, or received once nothing is outstanding
+func (r *goodsReceiptRepository) updateOrder(tx *gorm.DB, orderID uint64, orderItems map[domain.ItemKey]*PurchaseOrderItemModel, items []*domain.GoodsReceiptItem) error {
+	for _, item := range items {
+		orderItem := orderItems[item.Key()]
+		orderItem.ReceivedQuantity = domain.RoundQuantity(orderItem.ReceivedQuantity + item.Quantity)
+
+		err := tx.Model(&PurchaseOrderItemModel{}).
+			Where("id = ?", orderItem.ID).
+			Update("received_quantity", orderItem.ReceivedQuantity).Error
+		if err != nil {
+			return fmt.Errorf("failed to update purchase order item: %w", err)
+		}
+	}
+
+	status := domain.PurchaseOrderStatusReceived
+	for _, orderItem := range orderItems {
+		if orderItem.ReceivedQuantity < orderItem.Quantity {
+			status = domain.PurchaseOrderStatusPartiallyReceived
+			break
+		}
+	}
+
+	err := tx.Model(&PurchaseOrderModel{}).Where("id = ?", orderID).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+)
+
+type SupplierModel struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64    `gorm:"not null"`
+	Code            string    `gorm:"size:50;not null"`
+	Name            string    `gorm:"size:255;not null"`
+	ContactPerson   string    `gorm:"size:255"`
+	Email           string    `gorm:"size:255"`
+	Phone           string    `gorm:"size:20"`
+	Address         string    `gorm:"type:text"`
+	City            string    `gorm:"size:100"`
+	TaxNumber       string    `gorm:"size:50"`
+	PaymentTermDays int       `gorm:"default:0"`
+	Notes           string    `gorm:"type:text"`
+	IsActive        bool      `gorm:"default:true"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (SupplierModel) TableName() string {
+	return "suppliers"
+}
+
+type PurchaseOrderModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64 `gorm:"not null"`
+	OrderNumber  string `gorm:"size:50;not null"`
+	SupplierID   uint64 `gorm:"not null"`
+	OutletID     uint64 `gorm:"not null"`
+	Status       string `gorm:"size:30;not null"`
+	ExpectedDate *time.Time
+	TotalAmount  float64
+	Notes        string `gorm:"type:text"`
+	CreatedBy    uint64 `gorm:"not null"`
+	OrderedBy    *uint64
+	OrderedAt    *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	Supplier *SupplierModel           `gorm:"foreignKey:SupplierID"`
+	Outlet   *PurchaseOutletModel     `gorm:"foreignKey:OutletID"`
+	Items    []PurchaseOrderItemModel `gorm:"foreignKey:PurchaseOrderID"`
+}
+
+func (PurchaseOrderModel) TableName() string {
+	return "purchase_orders"
+}
+
+type PurchaseOrderItemModel struct {
+	ID               uint64 `gorm:"primaryKey;autoIncrement"`
+	PurchaseOrderID  uint64 `gorm:"not null"`
+	ProductID        uint64 `gorm:"not null"`
+	VariantID        *uint64
+	Unit             string  `gorm:"size:50;not null;default:''"`
+	UnitFactor       float64 `gorm:"type:decimal(15,6);not null;default:1"`
+	Quantity         float64 `gorm:"type:decimal(15,3);not null"`
+	UnitCost         float64
+	ReceivedQuantity float64 `gorm:"type:decimal(15,3);not null;default:0"`
+
+	Product *PurchaseProductModel `gorm:"foreignKey:ProductID"`
+	Variant *PurchaseVariantModel `gorm:"foreignKey:VariantID"`
+}
+
+func (PurchaseOrderItemModel) TableName() string {
+	return "purchase_order_items"
+}
+
+type GoodsReceiptModel struct {
+	ID              uint64 `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64 `gorm:"not null"`
+	ReceiptNumber   string `gorm:"size:50;not null"`
+	PurchaseOrderID *uint64
+	SupplierID      uint64 `gorm:"not null"`
+	OutletID        uint64 `gorm:"not null"`
+	SupplierInvoice string `gorm:"size:100"`
+	CostPricePolicy string `gorm:"size:30;not null"`
+	TotalCost       float64
+	Notes           string    `gorm:"type:text"`
+	ReceivedBy      uint64    `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+
+	PurchaseOrder *PurchaseOrderModel     `gorm:"foreignKey:PurchaseOrderID"`
+	Supplier      *SupplierModel          `gorm:"foreignKey:SupplierID"`
+	Outlet        *PurchaseOutletModel    `gorm:"foreignKey:OutletID"`
+	Items         []GoodsReceiptItemModel `gorm:"foreignKey:GoodsReceiptID"`
+}
+
+func (GoodsReceiptModel) TableName() string {
+	return "goods_receipts"
+}
+
+type GoodsReceiptItemModel struct {
+	ID                  uint64 `gorm:"primaryKey;autoIncrement"`
+	GoodsReceiptID      uint64 `gorm:"not null"`
+	PurchaseOrderItemID *uint64
+	ProductID           uint64 `gorm:"not null"`
+	VariantID           *uint64
+	Unit                string  `gorm:"size:50;not null;default:''"`
+	UnitFactor          float64 `gorm:"type:decimal(15,6);not null;default:1"`
+	Quantity            float64 `gorm:"type:decimal(15,3);not null"`
+	UnitCost            float64
+	PreviousCostPrice   float64
+	NewCostPrice        float64
+	LotID               *uint64
+
+	Product *PurchaseProductModel `gorm:"foreignKey:ProductID"`
+	Variant *PurchaseVariantModel `gorm:"foreignKey:VariantID"`
+	Lot     *StockLotModel        `gorm:"foreignKey:LotID"`
+}
+
+func (GoodsReceiptItemModel) TableName() string {
+	return "goods_receipt_items"
+}
+
+type PurchaseOutletModel struct {
+	ID   uint64
+	Name string
+}
+
+func (PurchaseOutletModel) TableName() string {
+	return "outlets"
+}
+
+type PurchaseProductModel struct {
+	ID           uint64
+	TenantID     uint64
+	SKU          string
+	Name         string
+	CostPrice    float64
+	SellingPrice float64
+	TrackStock   bool
+	TrackLots    bool
+}
+
+func (PurchaseProductModel) TableName() string {
+	return "products"
+}
+
+type PurchaseVariantModel struct {
+	ID        uint64
+	TenantID  uint64
+	ProductID uint64
+	SKU       string
+	Name      string
+}
+
+func (PurchaseVariantModel) TableName() string {
+	return "product_variants"
+}
+
+// UnitConversionModel is a unit one product may be ordered or received in,
+// its base unit included
+type UnitConversionModel struct {
+	ProductID    uint64  `gorm:"column:product_id"`
+	Unit         string  `gorm:"column:unit"`
+	Factor       float64 `gorm:"column:factor"`
+	AllowDecimal bool    `gorm:"column:allow_decimal"`
+	IsBase       bool    `gorm:"column:is_base"`
+}
+
+type ProductStockModel struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID        uint64    `gorm:"not null"`
+	OutletID         uint64    `gorm:"not null"`
+	Quantity         float64   `gorm:"type:decimal(15,3);not null;default:0"`
+	ReservedQuantity float64   `gorm:"type:decimal(15,3);default:0"`
+	AverageCost      float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProductStockModel) TableName() string {
+	return "product_stocks"
+}
+
+type StockLotModel struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID   uint64     `gorm:"not null"`
+	ProductID  uint64     `gorm:"not null"`
+	OutletID   uint64     `gorm:"not null"`
+	LotNumber  string     `gorm:"size:100;not null"`
+	ExpiryDate *time.Time `gorm:"type:date"`
+	Quantity   float64    `gorm:"type:decimal(15,3);not null;default:0"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (StockLotModel) TableName() string {
+	return "stock_lots"
+}
+
+func (m *SupplierModel) ToDomainSupplier() *domain.Supplier {
+	return &domain.Supplier{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		Code:            m.Code,
+		Name:            m.Name,
+		ContactPerson:   m.ContactPerson,
+		Email:           m.Email,
+		Phone:           m.Phone,
+		Address:         m.Address,
+		City:            m.City,
+		TaxNumber:       m.TaxNumber,
+		PaymentTermDays: m.PaymentTermDays,
+		Notes:           m.Notes,
+		IsActive:        m.IsActive,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
+
+func (m *SupplierModel) FromDomainSupplier(supplier *domain.Supplier) {
+	m.ID = supplier.ID
+	m.TenantID = supplier.TenantID
+	m.Code = supplier.Code
+	m.Name = supplier.Name
+	m.ContactPerson = supplier.ContactPerson
+	m.Email = supplier.Email
+	m.Phone = supplier.Phone
+	m.Address = supplier.Address
+	m.City = supplier.City
+	m.TaxNumber = supplier.TaxNumber
+	m.PaymentTermDays = supplier.PaymentTermDays
+	m.Notes = supplier.Notes
+	m.IsActive = supplier.IsActive
+	m.CreatedAt = supplier.CreatedAt
+	m.UpdatedAt = supplier.UpdatedAt
+}
+
+func (m *PurchaseOrderModel) ToDomainOrder() *domain.PurchaseOrder {
+	order := &domain.PurchaseOrder{
+		ID:           m.ID,
+		TenantID:     m.TenantID,
+		OrderNumber:  m.OrderNumber,
+		SupplierID:   m.SupplierID,
+		OutletID:     m.OutletID,
+		Status:       m.Status,
+		ExpectedDate: m.ExpectedDate,
+		TotalAmount:  m.TotalAmount,
+		Notes:        m.Notes,
+		CreatedBy:    m.CreatedBy,
+		OrderedBy:    m.OrderedBy,
+		OrderedAt:    m.OrderedAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+
+	if m.Supplier != nil {
+		order.SupplierName = m.Supplier.Name
+	}
+	if m.Outlet != nil {
+		order.OutletName = m.Outlet.Name
+	}
+
+	if m.Items != nil {
+		order.Items = make([]*domain.PurchaseOrderItem, len(m.Items))
+		for i := range m.Items {
+			order.Items[i] = m.Items[i].ToDomainItem()
+		}
+	}
+
+	return order
+}
+
+func (m *PurchaseOrderModel) FromDomainOrder(order *domain.PurchaseOrder) {
+	m.ID = order.ID
+	m.TenantID = order.TenantID
+	m.OrderNumber = order.OrderNumber
+	m.SupplierID = order.SupplierID
+	m.OutletID = order.OutletID
+	m.Status = order.Status
+	m.ExpectedDate = order.ExpectedDate
+	m.TotalAmount = order.TotalAmount
+	m.Notes = order.Notes
+	m.CreatedBy = order.CreatedBy
+	m.OrderedBy = order.OrderedBy
+	m.OrderedAt = order.OrderedAt
+
+	m.Items = make([]PurchaseOrderItemModel, len(order.Items))
+	for i, item := range order.Items {
+		m.Items[i] = PurchaseOrderItemModel{
+			PurchaseOrderID:  order.ID,
+			ProductID:        item.ProductID,
+			VariantID:        item.VariantID,
+			Unit:             item.Unit,
+			UnitFactor:       item.UnitFactor,
+			Quantity:         item.Quantity,
+			UnitCost:         item.UnitCost,
+			ReceivedQuantity: item.ReceivedQuantity,
+		}
+	}
+}
+
+func (m *PurchaseOrderItemModel) ToDomainItem() *domain.PurchaseOrderItem {
+	item := &domain.PurchaseOrderItem{
+		ID:               m.ID,
+		ProductID:        m.ProductID,
+		VariantID:        m.VariantID,
+		Unit:             m.Unit,
+		UnitFactor:       m.UnitFactor,
+		Quantity:         m.Quantity,
+		UnitCost:         m.UnitCost,
+		ReceivedQuantity: m.ReceivedQuantity,
+	}
+
+	if m.Product != nil {
+		item.SKU = m.Product.SKU
+		item.ProductName = m.Product.Name
+	}
+	if m.Variant != nil {
+		item.VariantSKU = m.Variant.SKU
+		item.VariantName = m.Variant.Name
+	}
+
+	return item
+}
+
+func (m *GoodsReceiptModel) ToDomainReceipt() *domain.GoodsReceipt {
+	receipt := &domain.GoodsReceipt{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		ReceiptNumber:   m.ReceiptNumber,
+		PurchaseOrderID: m.PurchaseOrderID,
+		SupplierID:      m.SupplierID,
+		OutletID:        m.OutletID,
+		SupplierInvoice: m.SupplierInvoice,
+		CostPricePolicy: m.CostPricePolicy,
+		TotalCost:       m.TotalCost,
+		Notes:           m.Notes,
+		ReceivedBy:      m.ReceivedBy,
+		CreatedAt:       m.CreatedAt,
+	}
+
+	if m.PurchaseOrder != nil {
+		receipt.OrderNumber = m.PurchaseOrder.OrderNumber
+	}
+	if m.Supplier != nil {
+		receipt.SupplierName = m.Supplier.Name
+	}
+	if m.Outlet != nil {
+		receipt.OutletName = m.Outlet.Name
+	}
+
+	if m.Items != nil {
+		receipt.Items = make([]*domain.GoodsReceiptItem, len(m.Items))
+		for i := range m.Items {
+			receipt.Items[i] = m.Items[i].ToDomainItem()
+		}
+	}
+
+	return receipt
+}
+
+func (m *GoodsReceiptModel) FromDomainReceipt(receipt *domain.GoodsReceipt) {
+	m.ID = receipt.ID
+	m.TenantID = receipt.TenantID
+	m.ReceiptNumber = receipt.ReceiptNumber
+	m.PurchaseOrderID = receipt.PurchaseOrderID
+	m.SupplierID = receipt.SupplierID
+	m.OutletID = receipt.OutletID
+	m.SupplierInvoice = receipt.SupplierInvoice
+	m.CostPricePolicy = receipt.CostPricePolicy
+	m.TotalCost = receipt.TotalCost
+	m.Notes = receipt.Notes
+	m.ReceivedBy = receipt.ReceivedBy
+
+	m.Items = make([]GoodsReceiptItemModel, len(receipt.Items))
+	for i, item := range receipt.Items {
+		m.Items[i] = GoodsReceiptItemModel{
+			PurchaseOrderItemID: item.PurchaseOrderItemID,
+			ProductID:           item.ProductID,
+			VariantID:           item.VariantID,
+			Unit:                item.Unit,
+			UnitFactor:          item.UnitFactor,
+			Quantity:            item.Quantity,
+			UnitCost:            item.UnitCost,
+			PreviousCostPrice:   item.PreviousCostPrice,
+			NewCostPrice:        item.NewCostPrice,
+			LotID:               item.LotID,
+		}
+	}
+}
+
+func (m *GoodsReceiptItemModel) ToDomainItem() *domain.GoodsReceiptItem {
+	item := &domain.GoodsReceiptItem{
+		ID:                  m.ID,
+		PurchaseOrderItemID: m.PurchaseOrderItemID,
+		ProductID:           m.ProductID,
+		VariantID:           m.VariantID,
+		Unit:                m.Unit,
+		UnitFactor:          m.UnitFactor,
+		Quantity:            m.Quantity,
+		UnitCost:            m.UnitCost,
+		PreviousCostPrice:   m.PreviousCostPrice,
+		NewCostPrice:        m.NewCostPrice,
+		LotID:               m.LotID,
+	}
+
+	if m.Product != nil {
+		item.SKU = m.Product.SKU
+		item.ProductName = m.Product.Name
+	}
+	if m.Variant != nil {
+		item.VariantSKU = m.Variant.SKU
+		item.VariantName = m.Variant.Name
+	}
+	if m.Lot != nil {
+		item.LotNumber = m.Lot.LotNumber
+		item.ExpiryDate = m.Lot.ExpiryDate
+	}
+
+	return item
+}
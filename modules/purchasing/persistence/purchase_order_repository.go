@@ -0,0 +1,326 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type purchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) domain.PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+func (r *purchaseOrderRepository) Create(ctx context.Context, order *domain.PurchaseOrder) error {
+	var model PurchaseOrderModel
+	model.FromDomainOrder(order)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		number, err := nextDocumentNumber(tx, "purchase_orders", "order_number", "PO", model.TenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		model.OrderNumber = number
+
+		if err := tx.Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create purchase order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	order.ID = model.ID
+	order.OrderNumber = model.OrderNumber
+	order.CreatedAt = model.CreatedAt
+	order.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// UpdateDraft replaces the expected date, notes and items of a draft order
+func (r *purchaseOrderRepository) UpdateDraft(ctx context.Context, order *domain.PurchaseOrder) error {
+	var model PurchaseOrderModel
+	model.FromDomainOrder(order)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockPurchaseOrder(tx, order.TenantID, order.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.PurchaseOrderStatusDraft {
+			return errors.New("purchase order is not a draft")
+		}
+
+		err = tx.Model(&PurchaseOrderModel{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+			"expected_date": model.ExpectedDate,
+			"total_amount":  model.TotalAmount,
+			"notes":         model.Notes,
+			"updated_at":    time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update purchase order: %w", err)
+		}
+
+		if err := tx.Where("purchase_order_id = ?", current.ID).Delete(&PurchaseOrderItemModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete purchase order items: %w", err)
+		}
+		if err := tx.Create(&model.Items).Error; err != nil {
+			return fmt.Errorf("failed to create purchase order items: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *purchaseOrderRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.PurchaseOrder, error) {
+	var model PurchaseOrderModel
+
+	err := r.db.WithContext(ctx).
+		Preload("Supplier").
+		Preload("Outlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Variant").
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, fmt.Errorf("failed to find purchase order: %w", err)
+	}
+
+	return model.ToDomainOrder(), nil
+}
+
+func (r *purchaseOrderRepository) FindAll(ctx context.Context, tenantID uint64, query domain.PurchaseOrderQuery, limit, offset int) ([]*domain.PurchaseOrder, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&PurchaseOrderModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.SupplierID != nil {
+		filtered = filtered.Where("supplier_id = ?", *query.SupplierID)
+	}
+	if query.OutletID != nil {
+		filtered = filtered.Where("outlet_id = ?", *query.OutletID)
+	}
+	if query.Status != "" {
+		filtered = filtered.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count purchase orders: %w", err)
+	}
+
+	var models []PurchaseOrderModel
+	err := filtered.
+		Preload("Supplier").
+		Preload("Outlet").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find purchase orders: %w", err)
+	}
+
+	orders := make([]*domain.PurchaseOrder, len(models))
+	for i := range models {
+		orders[i] = models[i].ToDomainOrder()
+	}
+
+	return orders, total, nil
+}
+
+// MarkOrdered records that a draft order was sent to the supplier
+func (r *purchaseOrderRepository) MarkOrdered(ctx context.Context, tenantID, id, orderedBy uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := lockPurchaseOrder(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if model.Status != domain.PurchaseOrderStatusDraft {
+			return errors.New("purchase order is not a draft")
+		}
+
+		now := time.Now()
+		err = tx.Model(&PurchaseOrderModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":     domain.PurchaseOrderStatusOrdered,
+			"ordered_by": orderedBy,
+			"ordered_at": now,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to place purchase order: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *purchaseOrderRepository) Cancel(ctx context.Context, tenantID, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		model, err := lockPurchaseOrder(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+		if !model.ToDomainOrder().CanCancel() {
+			return errors.New("purchase order cannot be cancelled")
+		}
+
+		err = tx.Model(&PurchaseOrderModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
+			"status":     domain.PurchaseOrderStatusCancelled,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to cancel purchase order: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *purchaseOrderRepository) OutletExists(ctx context.Context, tenantID, outletID uint64) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Table("outlets").
+		Where("id = ? AND tenant_id = ? AND is_active = ?", outletID, tenantID, true).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to find outlet: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *purchaseOrderRepository) FindProducts(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*domain.PurchaseProduct, error) {
+	var models []PurchaseProductModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, productIDs).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+
+	products := make(map[uint64]*domain.PurchaseProduct, len(models))
+	for _, model := range models {
+		products[model.ID] = &domain.PurchaseProduct{
+			ID:         model.ID,
+			SKU:        model.SKU,
+			Name:       model.Name,
+			CostPrice:  model.CostPrice,
+			TrackStock: model.TrackStock,
+			TrackLots:  model.TrackLots,
+		}
+	}
+
+	return products, nil
+}
+
+// FindVariants returns the tenant's variants with the given IDs, keyed by ID
+func (r *purchaseOrderRepository) FindVariants(ctx context.Context, tenantID uint64, variantIDs []uint64) (map[uint64]*domain.PurchaseVariant, error) {
+	variants := make(map[uint64]*domain.PurchaseVariant, len(variantIDs))
+	if len(variantIDs) == 0 {
+		return variants, nil
+	}
+
+	var models []PurchaseVariantModel
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, variantIDs).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find variants: %w", err)
+	}
+
+	for _, model := range models {
+		variants[model.ID] = &domain.PurchaseVariant{
+			ID:        model.ID,
+			ProductID: model.ProductID,
+			SKU:       model.SKU,
+			Name:      model.Name,
+		}
+	}
+
+	return variants, nil
+}
+
+// productUnitsSQL lists the base unit of every product, whose decimals
+// follow the tenant's unit of the same code, and the units converted to it
+const productUnitsSQL = `SELECT p.id AS product_id, p.unit, 1 AS factor,
+	COALESCE(u.allow_decimal, FALSE) AS allow_decimal, TRUE AS is_base
+FROM products p
+LEFT JOIN units u ON u.tenant_id = p.tenant_id AND u.code = p.unit
+WHERE p.tenant_id = @tenant_id AND p.id IN @product_ids
+UNION ALL
+SELECT pu.product_id, u.code, pu.factor, u.allow_decimal, FALSE
+FROM product_units pu
+JOIN units u ON u.id = pu.unit_id
+WHERE u.tenant_id = @tenant_id AND pu.product_id IN @product_ids`
+
+// FindProductUnits returns the units the products may be ordered and
+// received in, keyed by product ID
+func (r *purchaseOrderRepository) FindProductUnits(ctx context.Context, tenantID uint64, productIDs []uint64) (map[uint64]*domain.ProductUnits, error) {
+	units := make(map[uint64]*domain.ProductUnits, len(productIDs))
+	if len(productIDs) == 0 {
+		return units, nil
+	}
+
+	var models []UnitConversionModel
+	err := r.db.WithContext(ctx).Raw(productUnitsSQL, map[string]interface{}{
+		"tenant_id":   tenantID,
+		"product_ids": productIDs,
+	}).Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product units: %w", err)
+	}
+
+	for _, model := range models {
+		productUnits, ok := units[model.ProductID]
+		if !ok {
+			productUnits = &domain.ProductUnits{Units: make(map[string]*domain.UnitConversion)}
+			units[model.ProductID] = productUnits
+		}
+		if model.IsBase {
+			productUnits.BaseUnit = model.Unit
+		}
+		productUnits.Units[model.Unit] = &domain.UnitConversion{
+			Unit:         model.Unit,
+			Factor:       model.Factor,
+			AllowDecimal: model.AllowDecimal,
+		}
+	}
+
+	return units, nil
+}
+
+func lockPurchaseOrder(tx *gorm.DB, tenantID, id uint64) (*PurchaseOrderModel, error) {
+	var model PurchaseOrderModel
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, fmt.Errorf("failed to find purchase order: %w", err)
+	}
+
+	return &model, nil
+}
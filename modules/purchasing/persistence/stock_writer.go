@@ -0,0 +1,30 @@
+package persistence
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// nextDocumentNumber returns the next number of a tenant's purchasing
+// document, formatted as PREFIX-YYYYMMDD-NNNN. A transaction-scoped advisory
+// lock per table and tenant keeps concurrent documents from taking the same
+// number.
+func nextDocumentNumber(tx *gorm.DB, table, column, prefix string, tenantID uint64, now time.Time) (string, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", table, int32(tenantID)).Error; err != nil {
+		return "", fmt.Errorf("failed to lock document numbers: %w", err)
+	}
+
+	datePrefix := fmt.Sprintf("%s-%s-", prefix, now.Format("20060102"))
+
+	var count int64
+	err := tx.Table(table).
+		Where("tenant_id = ? AND "+column+" LIKE ?", tenantID, datePrefix+"%").
+		Count(&count).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	return fmt.Sprintf("%s%04d", datePrefix, count+1), nil
+}
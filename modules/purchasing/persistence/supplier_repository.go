@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"gorm.io/gorm"
+)
+
+type supplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) domain.SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+func (r *supplierRepository) Create(ctx context.Context, supplier *domain.Supplier) error {
+	model := &SupplierModel{}
+	model.FromDomainSupplier(supplier)
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return errors.New("supplier with this code already exists")
+		}
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	supplier.ID = model.ID
+	supplier.CreatedAt = model.CreatedAt
+	supplier.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// FindByID also returns inactive suppliers, since their purchase orders and
+// receipts still refer to them
+func (r *supplierRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.Supplier, error) {
+	var model SupplierModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("supplier not found")
+		}
+		return nil, fmt.Errorf("failed to find supplier: %w", err)
+	}
+
+	return model.ToDomainSupplier(), nil
+}
+
+func (r *supplierRepository) FindAll(ctx context.Context, tenantID uint64, query domain.SupplierQuery, limit, offset int) ([]*domain.Supplier, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&SupplierModel{}).
+		Where("tenant_id = ?", tenantID)
+
+	if query.Search != "" {
+		search := "%" + query.Search + "%"
+		filtered = filtered.Where("name ILIKE ? OR code ILIKE ? OR contact_person ILIKE ?", search, search, search)
+	}
+
+	if query.IsActive != nil {
+		filtered = filtered.Where("is_active = ?", *query.IsActive)
+	} else {
+		// Default filter for active suppliers only
+		filtered = filtered.Where("is_active = ?", true)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	var models []SupplierModel
+	err := filtered.
+		Order("name ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find suppliers: %w", err)
+	}
+
+	suppliers := make([]*domain.Supplier, len(models))
+	for i := range models {
+		suppliers[i] = models[i].ToDomainSupplier()
+	}
+
+	return suppliers, total, nil
+}
+
+func (r *supplierRepository) Update(ctx context.Context, supplier *domain.Supplier) error {
+	model := &SupplierModel{}
+	model.FromDomainSupplier(supplier)
+
+	result := r.db.WithContext(ctx).
+		Model(&SupplierModel{}).
+		Where("id = ? AND tenant_id = ?", supplier.ID, supplier.TenantID).
+		Updates(map[string]interface{}{
+			"code":              model.Code,
+			"name":              model.Name,
+			"contact_person":    model.ContactPerson,
+			"email":             model.Email,
+			"phone":             model.Phone,
+			"address":           model.Address,
+			"city":              model.City,
+			"tax_number":        model.TaxNumber,
+			"payment_term_days": model.PaymentTermDays,
+			"notes":             model.Notes,
+			"is_active":         model.IsActive,
+			"updated_at":        time.Now(),
+		})
+
+	if result.Error != nil {
+		if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
+			return errors.New("supplier with this code already exists")
+		}
+		return fmt.Errorf("failed to update supplier: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("supplier not found")
+	}
+
+	return nil
+}
+
+// Delete deactivates the supplier. Suppliers are never removed because
+// purchase orders and goods receipts refer to them.
+func (r *supplierRepository) Delete(ctx context.Context, tenantID, id uint64) error {
+	result := r.db.WithContext(ctx).
+		Model(&SupplierModel{}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete supplier: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("supplier not found")
+	}
+
+	return nil
+}
+
+func (r *supplierRepository) IsCodeExists(ctx context.Context, tenantID uint64, code string, excludeID *uint64) (bool, error) {
+	var count int64
+
+	query := r.db.WithContext(ctx).
+		Model(&SupplierModel{}).
+		Where("tenant_id = ? AND code = ?", tenantID, code)
+
+	if excludeID != nil {
+		query = query.Where("id != ?", *excludeID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check supplier code: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Count returns the number of suppliers of a tenant, including inactive ones
+func (r *supplierRepository) Count(ctx context.Context, tenantID uint64) (int64, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&SupplierModel{}).
+		Where("tenant_id = ?", tenantID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// CreatePurchaseOrder saves a draft order. Drafts can be edited until they
+// are placed with the supplier.
+func (s *purchasingService) CreatePurchaseOrder(ctx context.Context, tenantID, userID uint64, req domain.CreatePurchaseOrderRequest) (*domain.PurchaseOrder, error) {
+	if _, err := s.activeSupplier(ctx, tenantID, req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.orderRepo.OutletExists(ctx, tenantID, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	items, err := s.orderItems(ctx, tenantID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.PurchaseOrder{
+		TenantID:     tenantID,
+		SupplierID:   req.SupplierID,
+		OutletID:     req.OutletID,
+		Status:       domain.PurchaseOrderStatusDraft,
+		ExpectedDate: req.ExpectedDate,
+		Notes:        strings.TrimSpace(req.Notes),
+		CreatedBy:    userID,
+		Items:        items,
+	}
+	order.TotalAmount = order.ItemsTotal()
+
+	if err := s.orderRepo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.FindByID(ctx, tenantID, order.ID)
+}
+
+func (s *purchasingService) UpdatePurchaseOrder(ctx context.Context, tenantID, id uint64, req domain.UpdatePurchaseOrderRequest) (*domain.PurchaseOrder, error) {
+	order, err := s.orderRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.IsDraft() {
+		return nil, errors.New("purchase order is not a draft")
+	}
+
+	items, err := s.orderItems(ctx, tenantID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order.ExpectedDate = req.ExpectedDate
+	order.Notes = strings.TrimSpace(req.Notes)
+	order.Items = items
+	order.TotalAmount = order.ItemsTotal()
+
+	if err := s.orderRepo.UpdateDraft(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *purchasingService) GetPurchaseOrder(ctx context.Context, tenantID, id uint64) (*domain.PurchaseOrder, error) {
+	return s.orderRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *purchasingService) GetPurchaseOrders(ctx context.Context, tenantID uint64, query domain.PurchaseOrderQuery, limit, offset int) ([]*domain.PurchaseOrder, int64, error) {
+	limit, offset = pagination(limit, offset)
+	return s.orderRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+// PlacePurchaseOrder marks a draft as sent to the supplier, after which
+// goods can be received against it
+func (s *purchasingService) PlacePurchaseOrder(ctx context.Context, tenantID, userID, id uint64) (*domain.PurchaseOrder, error) {
+	order, err := s.orderRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeSupplier(ctx, tenantID, order.SupplierID); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.MarkOrdered(ctx, tenantID, id, userID); err != nil {
+		return nil, err
+	}
+
+	order, err = s.orderRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("purchase.order_placed", tenantID, userID, map[string]interface{}{
+			"purchase_order_id": order.ID,
+			"order_number":      order.OrderNumber,
+			"supplier_id":       order.SupplierID,
+			"outlet_id":         order.OutletID,
+			"total_amount":      order.TotalAmount,
+		})
+		s.eventBus.Publish(ctx, "purchase.order_placed", event)
+	}
+
+	return order, nil
+}
+
+func (s *purchasingService) CancelPurchaseOrder(ctx context.Context, tenantID, id uint64) (*domain.PurchaseOrder, error) {
+	if err := s.orderRepo.Cancel(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.FindByID(ctx, tenantID, id)
+}
+
+// orderItems checks the requested products and variants and turns them
+// into order items in the requested units. Every product and every variant
+// can appear once per order.
+func (s *purchasingService) orderItems(ctx context.Context, tenantID uint64, requests []domain.PurchaseOrderItemRequest) ([]*domain.PurchaseOrderItem, error) {
+	productIDs := make([]uint64, len(requests))
+	var variantIDs []uint64
+	for i, item := range requests {
+		productIDs[i] = item.ProductID
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	products, err := s.orderRepo.FindProducts(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.orderRepo.FindProductUnits(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.orderRepo.FindVariants(ctx, tenantID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[domain.ItemKey]bool, len(requests))
+	items := make([]*domain.PurchaseOrderItem, len(requests))
+	for i, item := range requests {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, errors.New("product not found")
+		}
+		if !product.TrackStock {
+			return nil, errors.New("product does not track stock")
+		}
+		variant, err := lineVariant(variants, product.ID, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		key := domain.NewItemKey(product.ID, item.VariantID)
+		if seen[key] {
+			return nil, errors.New("duplicate product in purchase order")
+		}
+		seen[key] = true
+
+		conversion, err := units[product.ID].Convert(strings.TrimSpace(item.Unit), item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		items[i] = &domain.PurchaseOrderItem{
+			ProductID:   product.ID,
+			SKU:         product.SKU,
+			ProductName: product.Name,
+			Unit:        conversion.Unit,
+			UnitFactor:  conversion.Factor,
+			Quantity:    item.Quantity,
+			UnitCost:    item.UnitCost,
+		}
+		if variant != nil {
+			items[i].VariantID = &variant.ID
+			items[i].VariantSKU = variant.SKU
+			items[i].VariantName = variant.Name
+		}
+	}
+
+	return items, nil
+}
+
+// lineVariant returns the variant a line names, nil for lines of the product
+// itself. The variant must be one of the line's product.
+func lineVariant(variants map[uint64]*domain.PurchaseVariant, productID uint64, variantID *uint64) (*domain.PurchaseVariant, error) {
+	if variantID == nil {
+		return nil, nil
+	}
+
+	variant, ok := variants[*variantID]
+	if !ok || variant.ProductID != productID {
+		return nil, errors.New("variant not found")
+	}
+
+	return variant, nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+type purchasingService struct {
+	supplierRepo domain.SupplierRepository
+	orderRepo    domain.PurchaseOrderRepository
+	receiptRepo  domain.GoodsReceiptRepository
+	eventBus     messaging.EventBus
+}
+
+func NewPurchasingService(
+	supplierRepo domain.SupplierRepository,
+	orderRepo domain.PurchaseOrderRepository,
+	receiptRepo domain.GoodsReceiptRepository,
+	eventBus messaging.EventBus,
+) domain.PurchasingService {
+	return &purchasingService{
+		supplierRepo: supplierRepo,
+		orderRepo:    orderRepo,
+		receiptRepo:  receiptRepo,
+		eventBus:     eventBus,
+	}
+}
+
+// pagination clamps list limits and offsets
+func pagination(limit, offset int) (int, int) {
+	// Set default pagination if not provided
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
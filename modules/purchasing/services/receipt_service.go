@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// ReceivePurchaseOrder records a delivery against an order. Lines are
+// received in the order's unit, and without a unit cost at the order's unit
+// cost.
+func (s *purchasingService) ReceivePurchaseOrder(ctx context.Context, tenantID, userID, id uint64, req domain.ReceivePurchaseOrderRequest) (*domain.GoodsReceipt, error) {
+	order, err := s.orderRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.CanReceive() {
+		return nil, errors.New("purchase order is not awaiting receipt")
+	}
+
+	orderItems := make(map[domain.ItemKey]*domain.PurchaseOrderItem, len(order.Items))
+	for _, item := range order.Items {
+		orderItems[item.Key()] = item
+	}
+
+	items, err := s.receiptItems(ctx, tenantID, req.Items, func(key domain.ItemKey, unit string) (string, *float64, error) {
+		orderItem, ok := orderItems[key]
+		if !ok {
+			return "", nil, errors.New("product is not part of the purchase order")
+		}
+		if unit != "" && unit != orderItem.Unit {
+			return "", nil, errors.New("unit does not match the purchase order")
+		}
+		return orderItem.Unit, &orderItem.UnitCost, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderID := order.ID
+	receipt := &domain.GoodsReceipt{
+		TenantID:        tenantID,
+		PurchaseOrderID: &orderID,
+		SupplierID:      order.SupplierID,
+		OutletID:        order.OutletID,
+		SupplierInvoice: strings.TrimSpace(req.SupplierInvoice),
+		Notes:           strings.TrimSpace(req.Notes),
+		ReceivedBy:      userID,
+		Items:           items,
+	}
+
+	return s.createReceipt(ctx, receipt)
+}
+
+// CreateGoodsReceipt records a delivery that was not ordered through a
+// purchase order. Lines without a unit cost are received at the product's
+// current cost price, for as many base units as their unit holds.
+func (s *purchasingService) CreateGoodsReceipt(ctx context.Context, tenantID, userID uint64, req domain.CreateGoodsReceiptRequest) (*domain.GoodsReceipt, error) {
+	if _, err := s.activeSupplier(ctx, tenantID, req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.orderRepo.OutletExists(ctx, tenantID, req.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("outlet not found")
+	}
+
+	items, err := s.receiptItems(ctx, tenantID, req.Items, func(key domain.ItemKey, unit string) (string, *float64, error) {
+		return unit, nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &domain.GoodsReceipt{
+		TenantID:        tenantID,
+		SupplierID:      req.SupplierID,
+		OutletID:        req.OutletID,
+		SupplierInvoice: strings.TrimSpace(req.SupplierInvoice),
+		Notes:           strings.TrimSpace(req.Notes),
+		ReceivedBy:      userID,
+		Items:           items,
+	}
+
+	return s.createReceipt(ctx, receipt)
+}
+
+func (s *purchasingService) GetGoodsReceipt(ctx context.Context, tenantID, id uint64) (*domain.GoodsReceipt, error) {
+	return s.receiptRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *purchasingService) GetGoodsReceipts(ctx context.Context, tenantID uint64, query domain.GoodsReceiptQuery, limit, offset int) ([]*domain.GoodsReceipt, int64, error) {
+	limit, offset = pagination(limit, offset)
+	return s.receiptRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *purchasingService) createReceipt(ctx context.Context, receipt *domain.GoodsReceipt) (*domain.GoodsReceipt, error) {
+	policy, err := s.receiptRepo.FindCostPricePolicy(ctx, receipt.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	receipt.CostPricePolicy = policy
+
+	if err := s.receiptRepo.Create(ctx, receipt); err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("purchase.goods_received", receipt.TenantID, receipt.ReceivedBy, map[string]interface{}{
+			"goods_receipt_id":  receipt.ID,
+			"receipt_number":    receipt.ReceiptNumber,
+			"purchase_order_id": receipt.PurchaseOrderID,
+			"supplier_id":       receipt.SupplierID,
+			"outlet_id":         receipt.OutletID,
+			"total_cost":        receipt.TotalCost,
+		})
+		s.eventBus.Publish(ctx, "purchase.goods_received", event)
+	}
+
+	return s.receiptRepo.FindByID(ctx, receipt.TenantID, receipt.ID)
+}
+
+// receiptItems checks the received products and variants and turns them
+// into receipt items. defaults returns the unit of a line given the
+// requested one, and the unit cost in it for lines without one; lines it
+// gives no unit cost default to the product's cost price, which its
+// variants share.
+func (s *purchasingService) receiptItems(ctx context.Context, tenantID uint64, requests []domain.GoodsReceiptItemRequest, defaults func(key domain.ItemKey, unit string) (string, *float64, error)) ([]*domain.GoodsReceiptItem, error) {
+	productIDs := make([]uint64, len(requests))
+	var variantIDs []uint64
+	for i, item := range requests {
+		productIDs[i] = item.ProductID
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	products, err := s.orderRepo.FindProducts(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.orderRepo.FindProductUnits(ctx, tenantID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.orderRepo.FindVariants(ctx, tenantID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[domain.ItemKey]bool, len(requests))
+	items := make([]*domain.GoodsReceiptItem, len(requests))
+	for i, item := range requests {
+		product, ok := products[item.ProductID]
+		if !ok {
+			return nil, errors.New("product not found")
+		}
+		if !product.TrackStock {
+			return nil, errors.New("product does not track stock")
+		}
+		variant, err := lineVariant(variants, product.ID, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		key := domain.NewItemKey(product.ID, item.VariantID)
+		if seen[key] {
+			return nil, errors.New("duplicate product in receipt")
+		}
+		seen[key] = true
+
+		lotNumber := strings.TrimSpace(item.LotNumber)
+		if product.TrackLots && lotNumber == "" {
+			return nil, errors.New("lot number is required")
+		}
+		if !product.TrackLots && (lotNumber != "" || item.ExpiryDate != nil) {
+			return nil, errors.New("product does not track lots")
+		}
+
+		unit, defaultCost, err := defaults(key, strings.TrimSpace(item.Unit))
+		if err != nil {
+			return nil, err
+		}
+		conversion, err := units[product.ID].Convert(unit, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		unitCost := math.Round(product.CostPrice*conversion.Factor*100) / 100
+		if defaultCost != nil {
+			unitCost = *defaultCost
+		}
+		if item.UnitCost != nil {
+			unitCost = *item.UnitCost
+		}
+
+		items[i] = &domain.GoodsReceiptItem{
+			ProductID:   product.ID,
+			SKU:         product.SKU,
+			ProductName: product.Name,
+			Unit:        conversion.Unit,
+			UnitFactor:  conversion.Factor,
+			Quantity:    item.Quantity,
+			UnitCost:    unitCost,
+			LotNumber:   lotNumber,
+			ExpiryDate:  item.ExpiryDate,
+		}
+		if variant != nil {
+			items[i].VariantID = &variant.ID
+			items[i].VariantSKU = variant.SKU
+			items[i].VariantName = variant.Name
+		}
+	}
+
+	return items, nil
+}
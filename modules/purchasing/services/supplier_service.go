@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/exven/pos-system/modules/purchasing/domain"
+)
+
+func (s *purchasingService) CreateSupplier(ctx context.Context, tenantID uint64, req domain.CreateSupplierRequest) (*domain.Supplier, error) {
+	code := strings.TrimSpace(req.Code)
+	if code != "" {
+		exists, err := s.supplierRepo.IsCodeExists(ctx, tenantID, code, nil)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("supplier with this code already exists")
+		}
+	} else {
+		generated, err := s.generateSupplierCode(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	}
+
+	supplier := &domain.Supplier{
+		TenantID:        tenantID,
+		Code:            code,
+		Name:            strings.TrimSpace(req.Name),
+		ContactPerson:   strings.TrimSpace(req.ContactPerson),
+		Email:           strings.TrimSpace(req.Email),
+		Phone:           strings.TrimSpace(req.Phone),
+		Address:         strings.TrimSpace(req.Address),
+		City:            strings.TrimSpace(req.City),
+		TaxNumber:       strings.TrimSpace(req.TaxNumber),
+		PaymentTermDays: req.PaymentTermDays,
+		Notes:           strings.TrimSpace(req.Notes),
+		IsActive:        true,
+	}
+
+	if err := s.supplierRepo.Create(ctx, supplier); err != nil {
+		return nil, err
+	}
+
+	return supplier, nil
+}
+
+func (s *purchasingService) GetSupplier(ctx context.Context, tenantID, id uint64) (*domain.Supplier, error) {
+	return s.supplierRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *purchasingService) GetSuppliers(ctx context.Context, tenantID uint64, query domain.SupplierQuery, limit, offset int) ([]*domain.Supplier, int64, error) {
+	limit, offset = pagination(limit, offset)
+	return s.supplierRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func (s *purchasingService) UpdateSupplier(ctx context.Context, tenantID, id uint64, req domain.UpdateSupplierRequest) (*domain.Supplier, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code != supplier.Code {
+		exists, err := s.supplierRepo.IsCodeExists(ctx, tenantID, code, &id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("supplier with this code already exists")
+		}
+	}
+
+	supplier.Code = code
+	supplier.Name = strings.TrimSpace(req.Name)
+	supplier.ContactPerson = strings.TrimSpace(req.ContactPerson)
+	supplier.Email = strings.TrimSpace(req.Email)
+	supplier.Phone = strings.TrimSpace(req.Phone)
+	supplier.Address = strings.TrimSpace(req.Address)
+	supplier.City = strings.TrimSpace(req.City)
+	supplier.TaxNumber = strings.TrimSpace(req.TaxNumber)
+	supplier.PaymentTermDays = req.PaymentTermDays
+	supplier.Notes = strings.TrimSpace(req.Notes)
+	supplier.IsActive = req.IsActive
+
+	if err := s.supplierRepo.Update(ctx, supplier); err != nil {
+		return nil, err
+	}
+
+	return s.supplierRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *purchasingService) DeleteSupplier(ctx context.Context, tenantID, id uint64) error {
+	return s.supplierRepo.Delete(ctx, tenantID, id)
+}
+
+// activeSupplier returns the supplier if new orders and receipts may use it
+func (s *purchasingService) activeSupplier(ctx context.Context, tenantID, id uint64) (*domain.Supplier, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !supplier.IsActive {
+		return nil, errors.New("supplier is inactive")
+	}
+	return supplier, nil
+}
+
+// generateSupplierCode numbers suppliers SUP0001, SUP0002, ... skipping
+// codes that were already taken by hand
+func (s *purchasingService) generateSupplierCode(ctx context.Context, tenantID uint64) (string, error) {
+	count, err := s.supplierRepo.Count(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	for next := count + 1; ; next++ {
+		code := fmt.Sprintf("SUP%04d", next)
+		exists, err := s.supplierRepo.IsCodeExists(ctx, tenantID, code, nil)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+}
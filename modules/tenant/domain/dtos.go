@@ -0,0 +1,150 @@
+package domain
+
+type UpdateTenantRequest struct {
+	Name         string `json:"name" validate:"required,min=3,max=255"`
+	BusinessType string `json:"business_type" validate:"max=100"`
+	Email        string `json:"email" validate:"required,email,max=255"`
+	Phone        string `json:"phone" validate:"max=20"`
+	Address      string `json:"address"`
+	City         string `json:"city" validate:"max=100"`
+	Province     string `json:"province" validate:"max=100"`
+	PostalCode   string `json:"postal_code" validate:"max=10"`
+	TaxNumber    string `json:"tax_number" validate:"max=50"`
+	LogoURL      string `json:"logo_url" validate:"omitempty,url,max=500"`
+	Timezone     string `json:"timezone" validate:"required,timezone"`
+	Currency     string `json:"currency" validate:"required,iso4217"`
+}
+
+// UpdateTenantSettingsRequest changes the sections of the settings document
+// it has; sections left out keep their saved values. A section sent replaces
+// that section as a whole.
+type UpdateTenantSettingsRequest struct {
+	Receipt          *ReceiptSettingsRequest      `json:"receipt"`
+	DefaultTaxRate   *float64                     `json:"default_tax_rate" validate:"omitempty,min=0,max=100"`
+	PricesIncludeTax *bool                        `json:"prices_include_tax"`
+	Rounding         *RoundingSettingsRequest     `json:"rounding"`
+	NumberFormat     *NumberFormatSettingsRequest `json:"number_format"`
+	Inventory        *InventorySettingsRequest    `json:"inventory"`
+	Barcode          *BarcodeSettingsRequest      `json:"barcode"`
+}
+
+type ReceiptSettingsRequest struct {
+	Header        string `json:"header" validate:"max=500"`
+	Footer        string `json:"footer" validate:"max=500"`
+	ShowLogo      bool   `json:"show_logo"`
+	ShowTaxNumber bool   `json:"show_tax_number"`
+}
+
+type RoundingSettingsRequest struct {
+	Mode      string  `json:"mode" validate:"required,oneof=none nearest up down"`
+	Increment float64 `json:"increment" validate:"min=0"`
+}
+
+type NumberFormatSettingsRequest struct {
+	DecimalSeparator   string `json:"decimal_separator" validate:"required,oneof=. ,"`
+	ThousandsSeparator string `json:"thousands_separator" validate:"omitempty,max=1,nefield=DecimalSeparator"`
+	DecimalPlaces      int    `json:"decimal_places" validate:"min=0,max=4"`
+	CurrencySymbol     string `json:"currency_symbol" validate:"max=10"`
+}
+
+type InventorySettingsRequest struct {
+	AdjustmentApprovalThreshold float64 `json:"adjustment_approval_threshold" validate:"min=0"`
+	CostPricePolicy             string  `json:"cost_price_policy" validate:"omitempty,oneof=last_cost weighted_average"`
+	CostingMethod               string  `json:"costing_method" validate:"omitempty,oneof=fifo weighted_average"`
+}
+
+type BarcodeSettingsRequest struct {
+	Prefix     string `json:"prefix" validate:"omitempty,numeric,min=2,max=9"`
+	Format     string `json:"format" validate:"omitempty,oneof=ean13 code128"`
+	AutoAssign bool   `json:"auto_assign"`
+}
+
+type TenantResponse struct {
+	ID           uint64                 `json:"id"`
+	Name         string                 `json:"name"`
+	BusinessType string                 `json:"business_type"`
+	Email        string                 `json:"email"`
+	Phone        string                 `json:"phone"`
+	Address      string                 `json:"address"`
+	City         string                 `json:"city"`
+	Province     string                 `json:"province"`
+	PostalCode   string                 `json:"postal_code"`
+	TaxNumber    string                 `json:"tax_number"`
+	LogoURL      string                 `json:"logo_url"`
+	Timezone     string                 `json:"timezone"`
+	Currency     string                 `json:"currency"`
+	IsActive     bool                   `json:"is_active"`
+	TrialEndsAt  *string                `json:"trial_ends_at"`
+	Settings     TenantSettingsResponse `json:"settings"`
+	CreatedAt    string                 `json:"created_at"`
+	UpdatedAt    string                 `json:"updated_at"`
+}
+
+type TenantSettingsResponse struct {
+	Receipt          ReceiptSettingsResponse      `json:"receipt"`
+	DefaultTaxRate   float64                      `json:"default_tax_rate"`
+	PricesIncludeTax bool                         `json:"prices_include_tax"`
+	Rounding         RoundingSettingsResponse     `json:"rounding"`
+	NumberFormat     NumberFormatSettingsResponse `json:"number_format"`
+	Inventory        InventorySettingsResponse    `json:"inventory"`
+	Barcode          BarcodeSettingsResponse      `json:"barcode"`
+}
+
+type ReceiptSettingsResponse struct {
+	Header        string `json:"header"`
+	Footer        string `json:"footer"`
+	ShowLogo      bool   `json:"show_logo"`
+	ShowTaxNumber bool   `json:"show_tax_number"`
+}
+
+type RoundingSettingsResponse struct {
+	Mode      string  `json:"mode"`
+	Increment float64 `json:"increment"`
+}
+
+type NumberFormatSettingsResponse struct {
+	DecimalSeparator   string `json:"decimal_separator"`
+	ThousandsSeparator string `json:"thousands_separator"`
+	DecimalPlaces      int    `json:"decimal_places"`
+	CurrencySymbol     string `json:"currency_symbol"`
+}
+
+type InventorySettingsResponse struct {
+	AdjustmentApprovalThreshold float64 `json:"adjustment_approval_threshold"`
+	CostPricePolicy             string  `json:"cost_price_policy"`
+	CostingMethod               string  `json:"costing_method"`
+}
+
+type BarcodeSettingsResponse struct {
+	Prefix     string `json:"prefix"`
+	Format     string `json:"format"`
+	AutoAssign bool   `json:"auto_assign"`
+}
+
+type CreateTenantExportRequest struct {
+	Format string `json:"format" validate:"required,oneof=csv json"`
+}
+
+type DeleteTenantRequest struct {
+	TenantName string `json:"tenant_name" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+}
+
+type TenantExportResponse struct {
+	ID           uint64           `json:"id"`
+	Format       string           `json:"format"`
+	Status       string           `json:"status"`
+	FileSize     int64            `json:"file_size"`
+	RecordCounts map[string]int64 `json:"record_counts"`
+	ErrorMessage string           `json:"error_message,omitempty"`
+	DownloadURL  *string          `json:"download_url"`
+	ExpiresAt    *string          `json:"expires_at"`
+	CompletedAt  *string          `json:"completed_at"`
+	CreatedAt    string           `json:"created_at"`
+}
+
+type TenantDeletionResponse struct {
+	TenantID       uint64 `json:"tenant_id"`
+	RecordsDeleted int64  `json:"records_deleted"`
+	DeletedAt      string `json:"deleted_at"`
+}
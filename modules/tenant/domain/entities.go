@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	RoundingModeNone    = "none"
+	RoundingModeNearest = "nearest"
+	RoundingModeUp      = "up"
+	RoundingModeDown    = "down"
+)
+
+const (
+	CostPricePolicyLastCost        = "last_cost"
+	CostPricePolicyWeightedAverage = "weighted_average"
+)
+
+const (
+	CostingMethodFIFO            = "fifo"
+	CostingMethodWeightedAverage = "weighted_average"
+)
+
+const (
+	BarcodeFormatEAN13   = "ean13"
+	BarcodeFormatCode128 = "code128"
+
+	// DefaultBarcodePrefix starts the GS1 range set aside for in-store
+	// numbers, which never clash with manufacturers' barcodes
+	DefaultBarcodePrefix = "20"
+)
+
+// OutletInheritKey is the outlet settings key that opts an outlet out of
+// tenant defaults when set to false. Outlets without the key inherit.
+const OutletInheritKey = "inherit_tenant_defaults"
+
+type Tenant struct {
+	ID           uint64
+	Name         string
+	BusinessType string
+	Email        string
+	Phone        string
+	Address      string
+	City         string
+	Province     string
+	PostalCode   string
+	TaxNumber    string
+	LogoURL      string
+	Timezone     string
+	Currency     string
+	IsActive     bool
+	TrialEndsAt  *time.Time
+	Settings     TenantSettings
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type TenantSettings struct {
+	Receipt          ReceiptSettings
+	DefaultTaxRate   float64
+	PricesIncludeTax bool
+	Rounding         RoundingSettings
+	NumberFormat     NumberFormatSettings
+	Inventory        InventorySettings
+	Barcode          BarcodeSettings
+}
+
+type ReceiptSettings struct {
+	Header        string
+	Footer        string
+	ShowLogo      bool
+	ShowTaxNumber bool
+}
+
+type RoundingSettings struct {
+	Mode      string
+	Increment float64
+}
+
+type NumberFormatSettings struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	DecimalPlaces      int
+	CurrencySymbol     string
+}
+
+// InventorySettings configures stock control. Stock adjustments worth more
+// than AdjustmentApprovalThreshold at cost wait for manager approval; zero
+// disables approval. CostPricePolicy decides how receiving goods from a
+// supplier updates a product's cost price. CostingMethod decides which cost
+// layers stock leaves from, and so the cost of goods sold and stock value.
+type InventorySettings struct {
+	AdjustmentApprovalThreshold float64
+	CostPricePolicy             string
+	CostingMethod               string
+}
+
+// BarcodeSettings configures the barcodes assigned to products without one:
+// Prefix followed by a sequence number, as an EAN-13 with check digit or as
+// a Code 128. With AutoAssign new products get one when they are created.
+type BarcodeSettings struct {
+	Prefix     string
+	Format     string
+	AutoAssign bool
+}
+
+// DefaultTenantSettings returns the settings used when a tenant has not
+// saved a settings document yet.
+func DefaultTenantSettings() TenantSettings {
+	return TenantSettings{
+		Receipt: ReceiptSettings{
+			Footer:        "Terima kasih",
+			ShowLogo:      true,
+			ShowTaxNumber: true,
+		},
+		DefaultTaxRate: 0,
+		Rounding: RoundingSettings{
+			Mode: RoundingModeNone,
+		},
+		NumberFormat: NumberFormatSettings{
+			DecimalSeparator:   ",",
+			ThousandsSeparator: ".",
+			DecimalPlaces:      0,
+			CurrencySymbol:     "Rp",
+		},
+		Inventory: InventorySettings{
+			CostPricePolicy: CostPricePolicyLastCost,
+			CostingMethod:   CostingMethodFIFO,
+		},
+		Barcode: BarcodeSettings{
+			Prefix: DefaultBarcodePrefix,
+			Format: BarcodeFormatEAN13,
+		},
+	}
+}
+
+// OutletDefaults returns the values pushed into the settings of every
+// outlet that inherits tenant defaults.
+func (t *Tenant) OutletDefaults() map[string]interface{} {
+	return map[string]interface{}{
+		"currency":           t.Currency,
+		"timezone":           t.Timezone,
+		"tax_rate":           t.Settings.DefaultTaxRate,
+		"prices_include_tax": t.Settings.PricesIncludeTax,
+		"receipt_header":     t.Settings.Receipt.Header,
+		"receipt_footer":     t.Settings.Receipt.Footer,
+		"rounding_mode":      t.Settings.Rounding.Mode,
+		"rounding_increment": t.Settings.Rounding.Increment,
+	}
+}
+
+const (
+	RoleTenantOwner = "tenant_owner"
+	RoleSuperAdmin  = "super_admin"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+)
+
+// ExportDatasets lists the datasets written to a tenant export, one file per
+// dataset, in the order they are written.
+var ExportDatasets = []string{
+	"outlets",
+	"product_categories",
+	"products",
+	"product_variants",
+	"product_stocks",
+	"customer_groups",
+	"customers",
+	"price_lists",
+	"price_list_items",
+	"transactions",
+	"transaction_items",
+	"transaction_item_components",
+	"transaction_item_modifiers",
+	"transaction_payments",
+	"archived_transactions",
+	"archived_transaction_items",
+	"archived_transaction_payments",
+	"stock_movements",
+	"audit_logs",
+}
+
+type TenantExport struct {
+	ID           uint64
+	TenantID     uint64
+	RequestedBy  uint64
+	Format       string
+	Status       string
+	FilePath     string
+	FileSize     int64
+	RecordCounts map[string]int64
+	ErrorMessage string
+	ExpiresAt    *time.Time
+	CompletedAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (e *TenantExport) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && now.After(*e.ExpiresAt)
+}
+
+// TenantUser is the subset of a tenant's user needed to authorize tenant-wide
+// operations such as export and deletion.
+type TenantUser struct {
+	ID           uint64
+	TenantID     uint64
+	Email        string
+	PasswordHash string
+	RoleName     string
+}
+
+func (u *TenantUser) IsOwner() bool {
+	return u.RoleName == RoleTenantOwner || u.RoleName == RoleSuperAdmin
+}
+
+type TenantDeletion struct {
+	TenantID        uint64
+	RecordsAffected int64
+	DeletedAt       time.Time
+}
+
+// RecordWriter receives the rows of an export dataset.
+type RecordWriter interface {
+	WriteHeader(columns []string) error
+	WriteRecord(values []interface{}) error
+}
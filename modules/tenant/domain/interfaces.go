@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type TenantRepository interface {
+	FindByID(ctx context.Context, tenantID uint64) (*Tenant, error)
+	IsEmailExists(ctx context.Context, email string, excludeID uint64) (bool, error)
+	// Update saves the tenant profile and settings and pushes the tenant
+	// defaults to inheriting outlets in the same database transaction.
+	Update(ctx context.Context, tenant *Tenant) error
+	FindUser(ctx context.Context, tenantID, userID uint64) (*TenantUser, error)
+	// Delete removes the tenant and everything it owns in one database
+	// transaction. Rows without a cascading foreign key to tenants are
+	// removed explicitly; the transaction is rolled back if any tenant data
+	// is left behind afterwards. It returns the number of records removed.
+	Delete(ctx context.Context, tenantID uint64) (int64, error)
+	RecordDeletion(ctx context.Context, tenant *Tenant, recordsAffected int64, executionTime time.Duration, deletionErr error) error
+}
+
+type ExportRepository interface {
+	Create(ctx context.Context, export *TenantExport) error
+	Update(ctx context.Context, export *TenantExport) error
+	FindByID(ctx context.Context, tenantID, exportID uint64) (*TenantExport, error)
+	FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*TenantExport, int64, error)
+	HasActiveExport(ctx context.Context, tenantID uint64) (bool, error)
+	// WriteDataset streams every row of the named dataset belonging to the
+	// tenant into w and returns the number of rows written.
+	WriteDataset(ctx context.Context, tenantID uint64, dataset string, w RecordWriter) (int64, error)
+}
+
+type TenantService interface {
+	GetProfile(ctx context.Context, tenantID uint64) (*Tenant, error)
+	UpdateProfile(ctx context.Context, tenantID uint64, req UpdateTenantRequest) (*Tenant, error)
+	GetSettings(ctx context.Context, tenantID uint64) (*TenantSettings, error)
+	UpdateSettings(ctx context.Context, tenantID uint64, req UpdateTenantSettingsRequest) (*TenantSettings, error)
+	DeleteTenant(ctx context.Context, tenantID, userID uint64, req DeleteTenantRequest) (*TenantDeletion, error)
+}
+
+type ExportService interface {
+	RequestExport(ctx context.Context, tenantID, userID uint64, req CreateTenantExportRequest) (*TenantExport, error)
+	GetExport(ctx context.Context, tenantID, exportID uint64) (*TenantExport, error)
+	GetExports(ctx context.Context, tenantID uint64, limit, offset int) ([]*TenantExport, int64, error)
+	// OpenExport returns a completed, unexpired export ready to be downloaded.
+	OpenExport(ctx context.Context, tenantID, exportID uint64) (*TenantExport, error)
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+	"github.com/exven/pos-system/shared/types"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type TenantHandler struct {
+	tenantService domain.TenantService
+	exportService domain.ExportService
+}
+
+func NewTenantHandler(tenantService domain.TenantService, exportService domain.ExportService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		exportService: exportService,
+	}
+}
+
+func (h *TenantHandler) RegisterRoutes(e *echo.Group) {
+	tenants := e.Group("/tenants")
+
+	// Current tenant routes
+	tenants.GET("/current", h.GetProfile)
+	tenants.PUT("/current", h.UpdateProfile)
+	tenants.GET("/current/settings", h.GetSettings)
+	tenants.PUT("/current/settings", h.UpdateSettings)
+	tenants.DELETE("/current", h.DeleteTenant)
+
+	// Data export routes
+	tenants.POST("/current/exports", h.CreateExport)
+	tenants.GET("/current/exports", h.GetExports)
+	tenants.GET("/current/exports/:id", h.GetExport)
+	tenants.GET("/current/exports/:id/download", h.DownloadExport)
+}
+
+func (h *TenantHandler) GetProfile(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	tenant, err := h.tenantService.GetProfile(c.Request().Context(), tenantID)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return response.InternalError(c, "Failed to get tenant")
+	}
+
+	return response.Success(c, "Tenant retrieved successfully", h.tenantToResponse(tenant))
+}
+
+func (h *TenantHandler) UpdateProfile(c echo.Context) error {
+	var req domain.UpdateTenantRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	tenant, err := h.tenantService.UpdateProfile(c.Request().Context(), tenantID, req)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			return response.NotFound(c, "Tenant not found")
+		case "tenant with this email already exists":
+			return response.Error(c, http.StatusConflict, err.Error(), map[string][]string{
+				"email": {"Email already exists"},
+			})
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Tenant updated successfully", h.tenantToResponse(tenant))
+}
+
+func (h *TenantHandler) GetSettings(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	settings, err := h.tenantService.GetSettings(c.Request().Context(), tenantID)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return response.InternalError(c, "Failed to get tenant settings")
+	}
+
+	return response.Success(c, "Tenant settings retrieved successfully", h.settingsToResponse(settings))
+}
+
+func (h *TenantHandler) UpdateSettings(c echo.Context) error {
+	var req domain.UpdateTenantSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	settings, err := h.tenantService.UpdateSettings(c.Request().Context(), tenantID, req)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			return response.NotFound(c, "Tenant not found")
+		case "rounding increment must be greater than 0":
+			return response.ValidationError(c, map[string][]string{
+				"increment": {"Increment must be greater than 0 when rounding is enabled"},
+			})
+		}
+		return response.BadRequest(c, err.Error())
+	}
+
+	return response.Success(c, "Tenant settings updated successfully", h.settingsToResponse(settings))
+}
+
+func (h *TenantHandler) DeleteTenant(c echo.Context) error {
+	var req domain.DeleteTenantRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	deletion, err := h.tenantService.DeleteTenant(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			return response.NotFound(c, "Tenant not found")
+		case "only the tenant owner can delete the tenant":
+			return response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case "tenant name does not match":
+			return response.ValidationError(c, map[string][]string{
+				"tenant_name": {"Tenant name does not match"},
+			})
+		case "invalid password":
+			return response.ValidationError(c, map[string][]string{
+				"password": {"Password is incorrect"},
+			})
+		}
+		return response.InternalError(c, "Failed to delete tenant")
+	}
+
+	return response.Success(c, "Tenant deleted successfully", domain.TenantDeletionResponse{
+		TenantID:       deletion.TenantID,
+		RecordsDeleted: deletion.RecordsAffected,
+		DeletedAt:      deletion.DeletedAt.Format(time.RFC3339),
+	})
+}
+
+func (h *TenantHandler) CreateExport(c echo.Context) error {
+	var req domain.CreateTenantExportRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	export, err := h.exportService.RequestExport(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		switch err.Error() {
+		case "only the tenant owner can export tenant data":
+			return response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case "an export is already in progress":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to start export")
+	}
+
+	return c.JSON(http.StatusAccepted, types.SuccessResponse{
+		Message: "Export started successfully",
+		Data:    h.exportToResponse(c, export),
+	})
+}
+
+func (h *TenantHandler) GetExports(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	exports, total, err := h.exportService.GetExports(c.Request().Context(), tenantID, limit, (page-1)*limit)
+	if err != nil {
+		return response.InternalError(c, "Failed to get exports")
+	}
+
+	exportResponses := make([]domain.TenantExportResponse, len(exports))
+	for i, export := range exports {
+		exportResponses[i] = h.exportToResponse(c, export)
+	}
+
+	return response.SuccessWithPagination(c, "Exports retrieved successfully", exportResponses, page, limit, int(total))
+}
+
+func (h *TenantHandler) GetExport(c echo.Context) error {
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid export ID")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	export, err := h.exportService.GetExport(c.Request().Context(), tenantID, exportID)
+	if err != nil {
+		if err.Error() == "export not found" {
+			return response.NotFound(c, "Export not found")
+		}
+		return response.InternalError(c, "Failed to get export")
+	}
+
+	return response.Success(c, "Export retrieved successfully", h.exportToResponse(c, export))
+}
+
+func (h *TenantHandler) DownloadExport(c echo.Context) error {
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid export ID")
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+
+	export, err := h.exportService.OpenExport(c.Request().Context(), tenantID, exportID)
+	if err != nil {
+		switch err.Error() {
+		case "export not found":
+			return response.NotFound(c, "Export not found")
+		case "export is not ready":
+			return response.Error(c, http.StatusConflict, err.Error(), nil)
+		case "export has expired":
+			return response.Error(c, http.StatusGone, err.Error(), nil)
+		}
+		return response.InternalError(c, "Failed to download export")
+	}
+
+	filename := fmt.Sprintf("tenant-%d-export-%d.zip", export.TenantID, export.ID)
+	return c.Attachment(export.FilePath, filename)
+}
+
+// Helper functions
+
+func (h *TenantHandler) exportToResponse(c echo.Context, export *domain.TenantExport) domain.TenantExportResponse {
+	exportResponse := domain.TenantExportResponse{
+		ID:           export.ID,
+		Format:       export.Format,
+		Status:       export.Status,
+		FileSize:     export.FileSize,
+		RecordCounts: export.RecordCounts,
+		ErrorMessage: export.ErrorMessage,
+		CreatedAt:    export.CreatedAt.Format(time.RFC3339),
+	}
+
+	if export.Status == domain.ExportStatusCompleted && !export.IsExpired(time.Now()) {
+		downloadURL := fmt.Sprintf("%s://%s/api/v1/tenants/current/exports/%d/download", c.Scheme(), c.Request().Host, export.ID)
+		exportResponse.DownloadURL = &downloadURL
+	}
+
+	if export.ExpiresAt != nil {
+		expiresAt := export.ExpiresAt.Format(time.RFC3339)
+		exportResponse.ExpiresAt = &expiresAt
+	}
+
+	if export.CompletedAt != nil {
+		completedAt := export.CompletedAt.Format(time.RFC3339)
+		exportResponse.CompletedAt = &completedAt
+	}
+
+	return exportResponse
+}
+
+func (h *TenantHandler) tenantToResponse(tenant *domain.Tenant) domain.TenantResponse {
+	response := domain.TenantResponse{
+		ID:           tenant.ID,
+		Name:         tenant.Name,
+		BusinessType: tenant.BusinessType,
+		Email:        tenant.Email,
+		Phone:        tenant.Phone,
+		Address:      tenant.Address,
+		City:         tenant.City,
+		Province:     tenant.Province,
+		PostalCode:   tenant.PostalCode,
+		TaxNumber:    tenant.TaxNumber,
+		LogoURL:      tenant.LogoURL,
+		Timezone:     tenant.Timezone,
+		Currency:     tenant.Currency,
+		IsActive:     tenant.IsActive,
+		Settings:     h.settingsToResponse(&tenant.Settings),
+		CreatedAt:    tenant.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    tenant.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if tenant.TrialEndsAt != nil {
+		trialEndsAt := tenant.TrialEndsAt.Format(time.RFC3339)
+		response.TrialEndsAt = &trialEndsAt
+	}
+
+	return response
+}
+
+func (h *TenantHandler) settingsToResponse(settings *domain.TenantSettings) domain.TenantSettingsResponse {
+	return domain.TenantSettingsResponse{
+		Receipt: domain.ReceiptSettingsResponse{
+			Header:        settings.Receipt.Header,
+			Footer:        settings.Receipt.Footer,
+			ShowLogo:      settings.Receipt.ShowLogo,
+			ShowTaxNumber: settings.Receipt.ShowTaxNumber,
+		},
+		DefaultTaxRate:   settings.DefaultTaxRate,
+		PricesIncludeTax: settings.PricesIncludeTax,
+		Rounding: domain.RoundingSettingsResponse{
+			Mode:      settings.Rounding.Mode,
+			Increment: settings.Rounding.Increment,
+		},
+		NumberFormat: domain.NumberFormatSettingsResponse{
+			DecimalSeparator:   settings.NumberFormat.DecimalSeparator,
+			ThousandsSeparator: settings.NumberFormat.ThousandsSeparator,
+			DecimalPlaces:      settings.NumberFormat.DecimalPlaces,
+			CurrencySymbol:     settings.NumberFormat.CurrencySymbol,
+		},
+		Inventory: domain.InventorySettingsResponse{
+			AdjustmentApprovalThreshold: settings.Inventory.AdjustmentApprovalThreshold,
+			CostPricePolicy:             settings.Inventory.CostPricePolicy,
+			CostingMethod:               settings.Inventory.CostingMethod,
+		},
+		Barcode: domain.BarcodeSettingsResponse{
+			Prefix:     settings.Barcode.Prefix,
+			Format:     settings.Barcode.Format,
+			AutoAssign: settings.Barcode.AutoAssign,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package tenant
+
+import (
+	"path/filepath"
+
+	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/tenant/handlers"
+	"github.com/exven/pos-system/modules/tenant/persistence"
+	"github.com/exven/pos-system/modules/tenant/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container     container.Container
+	db            *gorm.DB
+	eventBus      messaging.EventBus
+	storageConfig config.StorageConfig
+	exportConfig  config.DataExportConfig
+}
+
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	eventBus messaging.EventBus,
+	storageConfig config.StorageConfig,
+	exportConfig config.DataExportConfig,
+) *Module {
+	return &Module{
+		container:     container,
+		db:            db,
+		eventBus:      eventBus,
+		storageConfig: storageConfig,
+		exportConfig:  exportConfig,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("tenant.tenantRepository", func() interface{} {
+		return persistence.NewTenantRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("tenant.exportRepository", func() interface{} {
+		return persistence.NewExportRepository(m.db)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("tenant.tenantService", func() interface{} {
+		repo := persistence.NewTenantRepository(m.db)
+		return services.NewTenantService(repo, m.eventBus, m.storageConfig.LocalPath)
+	})
+
+	m.container.RegisterSingleton("tenant.exportService", func() interface{} {
+		repo := persistence.NewTenantRepository(m.db)
+		exportRepo := persistence.NewExportRepository(m.db)
+		return services.NewExportService(repo, exportRepo, m.eventBus, m.exportDir(), m.exportConfig.ExpiryHours)
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("tenant.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+func (m *Module) GetHandler() *handlers.TenantHandler {
+	repo := persistence.NewTenantRepository(m.db)
+	exportRepo := persistence.NewExportRepository(m.db)
+	service := services.NewTenantService(repo, m.eventBus, m.storageConfig.LocalPath)
+	exportService := services.NewExportService(repo, exportRepo, m.eventBus, m.exportDir(), m.exportConfig.ExpiryHours)
+	return handlers.NewTenantHandler(service, exportService)
+}
+
+func (m *Module) exportDir() string {
+	return filepath.Join(m.storageConfig.LocalPath, "exports")
+}
@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+	"gorm.io/gorm"
+)
+
+// exportQueries selects the rows of each export dataset for a tenant. Tables
+// without a tenant_id column are reached through their parent.
+var exportQueries = map[string]string{
+	"outlets":            "SELECT * FROM outlets WHERE tenant_id = ? ORDER BY id",
+	"product_categories": "SELECT * FROM product_categories WHERE tenant_id = ? ORDER BY id",
+	"products":           "SELECT * FROM products WHERE tenant_id = ? ORDER BY id",
+	"product_variants":   "SELECT * FROM product_variants WHERE tenant_id = ? ORDER BY id",
+	"product_stocks": "SELECT ps.* FROM product_stocks ps " +
+		"JOIN products p ON p.id = ps.product_id WHERE p.tenant_id = ? ORDER BY ps.id",
+	"customer_groups": "SELECT * FROM customer_groups WHERE tenant_id = ? ORDER BY id",
+	"customers":       "SELECT * FROM customers WHERE tenant_id = ? ORDER BY id",
+	"price_lists":     "SELECT * FROM price_lists WHERE tenant_id = ? ORDER BY id",
+	"price_list_items": "SELECT pli.* FROM price_list_items pli " +
+		"JOIN price_lists pl ON pl.id = pli.price_list_id WHERE pl.tenant_id = ? ORDER BY pli.id",
+	"transactions": "SELECT * FROM transactions WHERE tenant_id = ? ORDER BY id",
+	"transaction_items": "SELECT ti.* FROM transaction_items ti " +
+		"JOIN transactions t ON t.id = ti.transaction_id WHERE t.tenant_id = ? ORDER BY ti.id",
+	"transaction_item_components": "SELECT tic.* FROM transaction_item_components tic " +
+		"JOIN transaction_items ti ON ti.id = tic.transaction_item_id " +
+		"JOIN transactions t ON t.id = ti.transaction_id WHERE t.tenant_id = ? ORDER BY tic.id",
+	"transaction_item_modifiers": "SELECT tim.* FROM transaction_item_modifiers tim " +
+		"JOIN transaction_items ti ON ti.id = tim.transaction_item_id " +
+		"JOIN transactions t ON t.id = ti.transaction_id WHERE t.tenant_id = ? ORDER BY tim.id",
+	"transaction_payments": "SELECT tp.* FROM transaction_payments tp " +
+		"JOIN transactions t ON t.id = tp.transaction_id WHERE t.tenant_id = ? ORDER BY tp.id",
+	"archived_transactions": "SELECT * FROM archived_transactions WHERE tenant_id = ? ORDER BY id",
+	"archived_transaction_items": "SELECT ati.* FROM archived_transaction_items ati " +
+		"JOIN archived_transactions at ON at.id = ati.transaction_id WHERE at.tenant_id = ? ORDER BY ati.id",
+	"archived_transaction_payments": "SELECT atp.* FROM archived_transaction_payments atp " +
+		"JOIN archived_transactions at ON at.id = atp.transaction_id WHERE at.tenant_id = ? ORDER BY atp.id",
+	"stock_movements": "SELECT sm.* FROM stock_movements sm " +
+		"JOIN products p ON p.id = sm.product_id WHERE p.tenant_id = ? ORDER BY sm.id",
+	"audit_logs": "SELECT * FROM audit_logs WHERE tenant_id = ? ORDER BY id",
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) domain.ExportRepository {
+	return &exportRepository{db: db}
+}
+
+func (r *exportRepository) Create(ctx context.Context, export *domain.TenantExport) error {
+	model := &TenantExportModel{}
+	model.FromDomainExport(export)
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create tenant export: %w", err)
+	}
+
+	export.ID = model.ID
+	export.CreatedAt = model.CreatedAt
+	export.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+func (r *exportRepository) Update(ctx context.Context, export *domain.TenantExport) error {
+	model := &TenantExportModel{}
+	model.FromDomainExport(export)
+
+	if err := r.db.WithContext(ctx).Save(model).Error; err != nil {
+		return fmt.Errorf("failed to update tenant export: %w", err)
+	}
+
+	export.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (r *exportRepository) FindByID(ctx context.Context, tenantID, exportID uint64) (*domain.TenantExport, error) {
+	var model TenantExportModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND tenant_id = ?", exportID, tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("export not found")
+		}
+		return nil, fmt.Errorf("failed to find tenant export: %w", err)
+	}
+
+	return model.ToDomainExport(), nil
+}
+
+func (r *exportRepository) FindByTenant(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.TenantExport, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).
+		Model(&TenantExportModel{}).
+		Where("tenant_id = ?", tenantID).
+		Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenant exports: %w", err)
+	}
+
+	var models []TenantExportModel
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find tenant exports: %w", err)
+	}
+
+	exports := make([]*domain.TenantExport, len(models))
+	for i := range models {
+		exports[i] = models[i].ToDomainExport()
+	}
+
+	return exports, total, nil
+}
+
+func (r *exportRepository) HasActiveExport(ctx context.Context, tenantID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&TenantExportModel{}).
+		Where("tenant_id = ? AND status IN ?", tenantID, []string{domain.ExportStatusPending, domain.ExportStatusProcessing}).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check active exports: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *exportRepository) WriteDataset(ctx context.Context, tenantID uint64, dataset string, w domain.RecordWriter) (int64, error) {
+	query, ok := exportQueries[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown export dataset: %s", dataset)
+	}
+
+	rows, err := r.db.WithContext(ctx).Raw(query, tenantID).Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", dataset, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s columns: %w", dataset, err)
+	}
+
+	if err := w.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	var written int64
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return written, fmt.Errorf("failed to scan %s row: %w", dataset, err)
+		}
+		if err := w.WriteRecord(values); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	if err := rows.Err(); err != nil {
+		return written, fmt.Errorf("failed to read %s rows: %w", dataset, err)
+	}
+
+	return written, nil
+}
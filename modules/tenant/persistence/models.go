@@ -0,0 +1,325 @@
+package persistence
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+)
+
+// TenantSettingsModel is the JSON document stored in tenants.settings
+type TenantSettingsModel struct {
+	Receipt struct {
+		Header        string `json:"header"`
+		Footer        string `json:"footer"`
+		ShowLogo      bool   `json:"show_logo"`
+		ShowTaxNumber bool   `json:"show_tax_number"`
+	} `json:"receipt"`
+	DefaultTaxRate   float64 `json:"default_tax_rate"`
+	PricesIncludeTax bool    `json:"prices_include_tax"`
+	Rounding         struct {
+		Mode      string  `json:"mode"`
+		Increment float64 `json:"increment"`
+	} `json:"rounding"`
+	NumberFormat struct {
+		DecimalSeparator   string `json:"decimal_separator"`
+		ThousandsSeparator string `json:"thousands_separator"`
+		DecimalPlaces      int    `json:"decimal_places"`
+		CurrencySymbol     string `json:"currency_symbol"`
+	} `json:"number_format"`
+	Inventory struct {
+		AdjustmentApprovalThreshold float64 `json:"adjustment_approval_threshold"`
+		CostPricePolicy             string  `json:"cost_price_policy"`
+		CostingMethod               string  `json:"costing_method"`
+	} `json:"inventory"`
+	Barcode struct {
+		Prefix     string `json:"prefix"`
+		Format     string `json:"format"`
+		AutoAssign bool   `json:"auto_assign"`
+	} `json:"barcode"`
+}
+
+func (j TenantSettingsModel) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+func (j *TenantSettingsModel) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type TenantModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	Name         string `gorm:"size:255;not null"`
+	BusinessType string `gorm:"size:100"`
+	Email        string `gorm:"size:255;uniqueIndex;not null"`
+	Phone        string `gorm:"size:20"`
+	Address      string `gorm:"type:text"`
+	City         string `gorm:"size:100"`
+	Province     string `gorm:"size:100"`
+	PostalCode   string `gorm:"size:10"`
+	TaxNumber    string `gorm:"size:50"`
+	LogoURL      string `gorm:"size:500"`
+	Timezone     string `gorm:"size:50;default:'Asia/Jakarta'"`
+	Currency     string `gorm:"size:3;default:'IDR'"`
+	IsActive     bool   `gorm:"default:true;index"`
+	TrialEndsAt  *time.Time
+	Settings     *TenantSettingsModel `gorm:"type:jsonb"`
+	CreatedAt    time.Time            `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time            `gorm:"autoUpdateTime"`
+}
+
+func (TenantModel) TableName() string {
+	return "tenants"
+}
+
+// Mapper functions
+
+func (t *TenantModel) ToDomainTenant() *domain.Tenant {
+	settings := domain.DefaultTenantSettings()
+	if t.Settings != nil {
+		settings = t.Settings.ToDomainSettings()
+	}
+
+	return &domain.Tenant{
+		ID:           t.ID,
+		Name:         t.Name,
+		BusinessType: t.BusinessType,
+		Email:        t.Email,
+		Phone:        t.Phone,
+		Address:      t.Address,
+		City:         t.City,
+		Province:     t.Province,
+		PostalCode:   t.PostalCode,
+		TaxNumber:    t.TaxNumber,
+		LogoURL:      t.LogoURL,
+		Timezone:     t.Timezone,
+		Currency:     t.Currency,
+		IsActive:     t.IsActive,
+		TrialEndsAt:  t.TrialEndsAt,
+		Settings:     settings,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func (t *TenantModel) FromDomainTenant(tenant *domain.Tenant) {
+	t.ID = tenant.ID
+	t.Name = tenant.Name
+	t.BusinessType = tenant.BusinessType
+	t.Email = tenant.Email
+	t.Phone = tenant.Phone
+	t.Address = tenant.Address
+	t.City = tenant.City
+	t.Province = tenant.Province
+	t.PostalCode = tenant.PostalCode
+	t.TaxNumber = tenant.TaxNumber
+	t.LogoURL = tenant.LogoURL
+	t.Timezone = tenant.Timezone
+	t.Currency = tenant.Currency
+	t.IsActive = tenant.IsActive
+	t.TrialEndsAt = tenant.TrialEndsAt
+	t.CreatedAt = tenant.CreatedAt
+	t.UpdatedAt = tenant.UpdatedAt
+
+	settings := &TenantSettingsModel{}
+	settings.FromDomainSettings(tenant.Settings)
+	t.Settings = settings
+}
+
+func (s *TenantSettingsModel) ToDomainSettings() domain.TenantSettings {
+	// Documents saved before the cost price policy existed use last cost
+	costPricePolicy := s.Inventory.CostPricePolicy
+	if costPricePolicy == "" {
+		costPricePolicy = domain.CostPricePolicyLastCost
+	}
+
+	// Documents saved before the costing method existed use FIFO
+	costingMethod := s.Inventory.CostingMethod
+	if costingMethod == "" {
+		costingMethod = domain.CostingMethodFIFO
+	}
+
+	// Documents saved before barcode settings existed use the defaults
+	barcodePrefix := s.Barcode.Prefix
+	if barcodePrefix == "" {
+		barcodePrefix = domain.DefaultBarcodePrefix
+	}
+	barcodeFormat := s.Barcode.Format
+	if barcodeFormat == "" {
+		barcodeFormat = domain.BarcodeFormatEAN13
+	}
+
+	return domain.TenantSettings{
+		Receipt: domain.ReceiptSettings{
+			Header:        s.Receipt.Header,
+			Footer:        s.Receipt.Footer,
+			ShowLogo:      s.Receipt.ShowLogo,
+			ShowTaxNumber: s.Receipt.ShowTaxNumber,
+		},
+		DefaultTaxRate:   s.DefaultTaxRate,
+		PricesIncludeTax: s.PricesIncludeTax,
+		Rounding: domain.RoundingSettings{
+			Mode:      s.Rounding.Mode,
+			Increment: s.Rounding.Increment,
+		},
+		NumberFormat: domain.NumberFormatSettings{
+			DecimalSeparator:   s.NumberFormat.DecimalSeparator,
+			ThousandsSeparator: s.NumberFormat.ThousandsSeparator,
+			DecimalPlaces:      s.NumberFormat.DecimalPlaces,
+			CurrencySymbol:     s.NumberFormat.CurrencySymbol,
+		},
+		Inventory: domain.InventorySettings{
+			AdjustmentApprovalThreshold: s.Inventory.AdjustmentApprovalThreshold,
+			CostPricePolicy:             costPricePolicy,
+			CostingMethod:               costingMethod,
+		},
+		Barcode: domain.BarcodeSettings{
+			Prefix:     barcodePrefix,
+			Format:     barcodeFormat,
+			AutoAssign: s.Barcode.AutoAssign,
+		},
+	}
+}
+
+func (s *TenantSettingsModel) FromDomainSettings(settings domain.TenantSettings) {
+	s.Receipt.Header = settings.Receipt.Header
+	s.Receipt.Footer = settings.Receipt.Footer
+	s.Receipt.ShowLogo = settings.Receipt.ShowLogo
+	s.Receipt.ShowTaxNumber = settings.Receipt.ShowTaxNumber
+	s.DefaultTaxRate = settings.DefaultTaxRate
+	s.PricesIncludeTax = settings.PricesIncludeTax
+	s.Rounding.Mode = settings.Rounding.Mode
+	s.Rounding.Increment = settings.Rounding.Increment
+	s.NumberFormat.DecimalSeparator = settings.NumberFormat.DecimalSeparator
+	s.NumberFormat.ThousandsSeparator = settings.NumberFormat.ThousandsSeparator
+	s.NumberFormat.DecimalPlaces = settings.NumberFormat.DecimalPlaces
+	s.NumberFormat.CurrencySymbol = settings.NumberFormat.CurrencySymbol
+	s.Inventory.AdjustmentApprovalThreshold = settings.Inventory.AdjustmentApprovalThreshold
+	s.Inventory.CostPricePolicy = settings.Inventory.CostPricePolicy
+	s.Inventory.CostingMethod = settings.Inventory.CostingMethod
+	s.Barcode.Prefix = settings.Barcode.Prefix
+	s.Barcode.Format = settings.Barcode.Format
+	s.Barcode.AutoAssign = settings.Barcode.AutoAssign
+}
+
+// RecordCountsModel is the JSON document stored in tenant_exports.record_counts
+type RecordCountsModel map[string]int64
+
+func (j RecordCountsModel) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+func (j *RecordCountsModel) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+type TenantExportModel struct {
+	ID           uint64            `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64            `gorm:"not null"`
+	RequestedBy  uint64            `gorm:"not null"`
+	Format       string            `gorm:"size:10;not null"`
+	Status       string            `gorm:"size:20;not null"`
+	FilePath     string            `gorm:"size:500"`
+	FileSize     int64             `gorm:"default:0"`
+	RecordCounts RecordCountsModel `gorm:"type:jsonb"`
+	ErrorMessage string            `gorm:"type:text"`
+	ExpiresAt    *time.Time
+	CompletedAt  *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (TenantExportModel) TableName() string {
+	return "tenant_exports"
+}
+
+// TenantUserModel is a read model over users joined with their role name
+type TenantUserModel struct {
+	ID           uint64
+	TenantID     uint64
+	Email        string
+	PasswordHash string
+	RoleName     string
+}
+
+func (e *TenantExportModel) ToDomainExport() *domain.TenantExport {
+	counts := make(map[string]int64, len(e.RecordCounts))
+	for k, v := range e.RecordCounts {
+		counts[k] = v
+	}
+
+	return &domain.TenantExport{
+		ID:           e.ID,
+		TenantID:     e.TenantID,
+		RequestedBy:  e.RequestedBy,
+		Format:       e.Format,
+		Status:       e.Status,
+		FilePath:     e.FilePath,
+		FileSize:     e.FileSize,
+		RecordCounts: counts,
+		ErrorMessage: e.ErrorMessage,
+		ExpiresAt:    e.ExpiresAt,
+		CompletedAt:  e.CompletedAt,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+	}
+}
+
+func (e *TenantExportModel) FromDomainExport(export *domain.TenantExport) {
+	e.ID = export.ID
+	e.TenantID = export.TenantID
+	e.RequestedBy = export.RequestedBy
+	e.Format = export.Format
+	e.Status = export.Status
+	e.FilePath = export.FilePath
+	e.FileSize = export.FileSize
+	e.RecordCounts = RecordCountsModel(export.RecordCounts)
+	e.ErrorMessage = export.ErrorMessage
+	e.ExpiresAt = export.ExpiresAt
+	e.CompletedAt = export.CompletedAt
+	e.CreatedAt = export.CreatedAt
+	e.UpdatedAt = export.UpdatedAt
+}
+
+func (u *TenantUserModel) ToDomainUser() *domain.TenantUser {
+	return &domain.TenantUser{
+		ID:           u.ID,
+		TenantID:     u.TenantID,
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		RoleName:     u.RoleName,
+	}
+}
+
+type DataRetentionLogModel struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64    `gorm:"not null"`
+	RetentionType   string    `gorm:"not null"`
+	RecordsAffected int       `gorm:"not null"`
+	DateFrom        time.Time `gorm:"type:date;not null"`
+	DateTo          time.Time `gorm:"type:date;not null"`
+	ExecutionTime   *float64  `gorm:"type:decimal(8,3)"`
+	Status          string
+	ErrorMessage    string    `gorm:"type:text"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (DataRetentionLogModel) TableName() string {
+	return "data_retention_logs"
+}
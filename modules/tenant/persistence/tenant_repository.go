@@ -0,0 +1,326 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+	"gorm.io/gorm"
+)
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) domain.TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) FindByID(ctx context.Context, tenantID uint64) (*domain.Tenant, error) {
+	var model TenantModel
+
+	err := r.db.WithContext(ctx).
+		Where("id = ?", tenantID).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to find tenant: %w", err)
+	}
+
+	return model.ToDomainTenant(), nil
+}
+
+func (r *tenantRepository) IsEmailExists(ctx context.Context, email string, excludeID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&TenantModel{}).
+		Where("email = ? AND id != ?", email, excludeID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check email existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant *domain.Tenant) error {
+	model := &TenantModel{}
+	model.FromDomainTenant(tenant)
+
+	defaults, err := json.Marshal(tenant.OutletDefaults())
+	if err != nil {
+		return fmt.Errorf("failed to encode outlet defaults: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&TenantModel{}).
+			Where("id = ?", tenant.ID).
+			Updates(map[string]interface{}{
+				"name":          model.Name,
+				"business_type": model.BusinessType,
+				"email":         model.Email,
+				"phone":         model.Phone,
+				"address":       model.Address,
+				"city":          model.City,
+				"province":      model.Province,
+				"postal_code":   model.PostalCode,
+				"tax_number":    model.TaxNumber,
+				"logo_url":      model.LogoURL,
+				"timezone":      model.Timezone,
+				"currency":      model.Currency,
+				"settings":      model.Settings,
+				"updated_at":    model.UpdatedAt,
+			})
+
+		if result.Error != nil {
+			if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
+				return errors.New("tenant with this email already exists")
+			}
+			return fmt.Errorf("failed to update tenant: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("tenant not found")
+		}
+
+		// Merge the defaults into outlets that have not opted out
+		err := tx.Exec(
+			"UPDATE outlets SET settings = COALESCE(settings, '{}'::jsonb) || ?::jsonb, updated_at = ? "+
+				"WHERE tenant_id = ? AND COALESCE((settings->>?)::boolean, true)",
+			string(defaults), model.UpdatedAt, tenant.ID, domain.OutletInheritKey,
+		).Error
+		if err != nil {
+			return fmt.Errorf("failed to propagate tenant defaults to outlets: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// tenantSnapshots capture the ids of a tenant's parent rows before deletion so
+// the verification below still finds child rows that a missing cascade left
+// behind. The temporary tables are dropped when the transaction ends.
+var tenantSnapshots = []string{
+	"CREATE TEMP TABLE tmp_tenant_users ON COMMIT DROP AS SELECT id FROM users WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_outlets ON COMMIT DROP AS SELECT id FROM outlets WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_product_categories ON COMMIT DROP AS SELECT id FROM product_categories WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_products ON COMMIT DROP AS SELECT id FROM products WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_modifier_groups ON COMMIT DROP AS SELECT id FROM modifier_groups WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_units ON COMMIT DROP AS SELECT id FROM units WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_price_lists ON COMMIT DROP AS SELECT id FROM price_lists WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_transactions ON COMMIT DROP AS SELECT id FROM transactions WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_archived_transactions ON COMMIT DROP AS SELECT id FROM archived_transactions WHERE tenant_id = ?",
+	"CREATE TEMP TABLE tmp_tenant_stock_lots ON COMMIT DROP AS SELECT id FROM stock_lots WHERE tenant_id = ?",
+}
+
+// tenantDataChecks count the rows a tenant owns in every table. They are run
+// before deletion to size it and after deletion to verify nothing is left.
+var tenantDataChecks = []struct {
+	table string
+	query string
+}{
+	{"tenant_subscriptions", "SELECT COUNT(*) FROM tenant_subscriptions WHERE tenant_id = ?"},
+	{"tenant_usages", "SELECT COUNT(*) FROM tenant_usages WHERE tenant_id = ?"},
+	{"users", "SELECT COUNT(*) FROM users WHERE tenant_id = ?"},
+	{"outlets", "SELECT COUNT(*) FROM outlets WHERE tenant_id = ?"},
+	{"user_outlets", "SELECT COUNT(*) FROM user_outlets WHERE user_id IN (SELECT id FROM tmp_tenant_users) " +
+		"OR outlet_id IN (SELECT id FROM tmp_tenant_outlets)"},
+	{"product_categories", "SELECT COUNT(*) FROM product_categories WHERE tenant_id = ?"},
+	{"products", "SELECT COUNT(*) FROM products WHERE tenant_id = ?"},
+	{"product_variants", "SELECT COUNT(*) FROM product_variants WHERE tenant_id = ?"},
+	{"product_stocks", "SELECT COUNT(*) FROM product_stocks WHERE product_id IN (SELECT id FROM tmp_tenant_products) " +
+		"OR outlet_id IN (SELECT id FROM tmp_tenant_outlets)"},
+	{"product_recipe_items", "SELECT COUNT(*) FROM product_recipe_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"product_bundle_groups", "SELECT COUNT(*) FROM product_bundle_groups WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"product_bundle_items", "SELECT COUNT(*) FROM product_bundle_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"modifier_groups", "SELECT COUNT(*) FROM modifier_groups WHERE tenant_id = ?"},
+	{"modifiers", "SELECT COUNT(*) FROM modifiers WHERE group_id IN (SELECT id FROM tmp_tenant_modifier_groups)"},
+	{"product_modifier_groups", "SELECT COUNT(*) FROM product_modifier_groups WHERE product_id IN (SELECT id FROM tmp_tenant_products) " +
+		"OR modifier_group_id IN (SELECT id FROM tmp_tenant_modifier_groups)"},
+	{"category_modifier_groups", "SELECT COUNT(*) FROM category_modifier_groups WHERE category_id IN (SELECT id FROM tmp_tenant_product_categories) " +
+		"OR modifier_group_id IN (SELECT id FROM tmp_tenant_modifier_groups)"},
+	{"units", "SELECT COUNT(*) FROM units WHERE tenant_id = ?"},
+	{"product_units", "SELECT COUNT(*) FROM product_units WHERE product_id IN (SELECT id FROM tmp_tenant_products) " +
+		"OR unit_id IN (SELECT id FROM tmp_tenant_units)"},
+	{"product_price_changes", "SELECT COUNT(*) FROM product_price_changes WHERE tenant_id = ?"},
+	{"images", "SELECT COUNT(*) FROM images WHERE tenant_id = ?"},
+	{"customer_groups", "SELECT COUNT(*) FROM customer_groups WHERE tenant_id = ?"},
+	{"customers", "SELECT COUNT(*) FROM customers WHERE tenant_id = ?"},
+	{"price_lists", "SELECT COUNT(*) FROM price_lists WHERE tenant_id = ?"},
+	{"price_list_outlets", "SELECT COUNT(*) FROM price_list_outlets WHERE price_list_id IN (SELECT id FROM tmp_tenant_price_lists) " +
+		"OR outlet_id IN (SELECT id FROM tmp_tenant_outlets)"},
+	{"price_list_customer_groups", "SELECT COUNT(*) FROM price_list_customer_groups WHERE price_list_id IN (SELECT id FROM tmp_tenant_price_lists)"},
+	{"price_list_items", "SELECT COUNT(*) FROM price_list_items WHERE price_list_id IN (SELECT id FROM tmp_tenant_price_lists) " +
+		"OR product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"transactions", "SELECT COUNT(*) FROM transactions WHERE tenant_id = ?"},
+	{"transaction_items", "SELECT COUNT(*) FROM transaction_items WHERE transaction_id IN (SELECT id FROM tmp_tenant_transactions)"},
+	{"transaction_payments", "SELECT COUNT(*) FROM transaction_payments WHERE transaction_id IN (SELECT id FROM tmp_tenant_transactions)"},
+	{"transaction_item_components", "SELECT COUNT(*) FROM transaction_item_components WHERE transaction_item_id IN " +
+		"(SELECT id FROM transaction_items WHERE transaction_id IN (SELECT id FROM tmp_tenant_transactions))"},
+	{"transaction_item_modifiers", "SELECT COUNT(*) FROM transaction_item_modifiers WHERE transaction_item_id IN " +
+		"(SELECT id FROM transaction_items WHERE transaction_id IN (SELECT id FROM tmp_tenant_transactions))"},
+	{"archived_transactions", "SELECT COUNT(*) FROM archived_transactions WHERE tenant_id = ?"},
+	{"archived_transaction_items", "SELECT COUNT(*) FROM archived_transaction_items " +
+		"WHERE transaction_id IN (SELECT id FROM tmp_tenant_archived_transactions)"},
+	{"archived_transaction_payments", "SELECT COUNT(*) FROM archived_transaction_payments " +
+		"WHERE transaction_id IN (SELECT id FROM tmp_tenant_archived_transactions)"},
+	{"stock_movements", "SELECT COUNT(*) FROM stock_movements WHERE product_id IN (SELECT id FROM tmp_tenant_products) " +
+		"OR outlet_id IN (SELECT id FROM tmp_tenant_outlets)"},
+	{"stock_adjustments", "SELECT COUNT(*) FROM stock_adjustments WHERE tenant_id = ?"},
+	{"stock_adjustment_items", "SELECT COUNT(*) FROM stock_adjustment_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"stock_transfers", "SELECT COUNT(*) FROM stock_transfers WHERE tenant_id = ?"},
+	{"stock_transfer_items", "SELECT COUNT(*) FROM stock_transfer_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"stocktakes", "SELECT COUNT(*) FROM stocktakes WHERE tenant_id = ?"},
+	{"stocktake_items", "SELECT COUNT(*) FROM stocktake_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"stocktake_counts", "SELECT COUNT(*) FROM stocktake_counts WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"stock_alerts", "SELECT COUNT(*) FROM stock_alerts WHERE tenant_id = ?"},
+	{"stock_lots", "SELECT COUNT(*) FROM stock_lots WHERE tenant_id = ?"},
+	{"stock_movement_lots", "SELECT COUNT(*) FROM stock_movement_lots WHERE lot_id IN (SELECT id FROM tmp_tenant_stock_lots)"},
+	{"stock_cost_layers", "SELECT COUNT(*) FROM stock_cost_layers WHERE tenant_id = ?"},
+	{"suppliers", "SELECT COUNT(*) FROM suppliers WHERE tenant_id = ?"},
+	{"purchase_orders", "SELECT COUNT(*) FROM purchase_orders WHERE tenant_id = ?"},
+	{"purchase_order_items", "SELECT COUNT(*) FROM purchase_order_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"goods_receipts", "SELECT COUNT(*) FROM goods_receipts WHERE tenant_id = ?"},
+	{"goods_receipt_items", "SELECT COUNT(*) FROM goods_receipt_items WHERE product_id IN (SELECT id FROM tmp_tenant_products)"},
+	{"audit_logs", "SELECT COUNT(*) FROM audit_logs WHERE tenant_id = ?"},
+	{"impersonation_sessions", "SELECT COUNT(*) FROM impersonation_sessions WHERE tenant_id = ?"},
+	{"tenant_exports", "SELECT COUNT(*) FROM tenant_exports WHERE tenant_id = ?"},
+	{"import_jobs", "SELECT COUNT(*) FROM import_jobs WHERE tenant_id = ?"},
+}
+
+func (r *tenantRepository) FindUser(ctx context.Context, tenantID, userID uint64) (*domain.TenantUser, error) {
+	var model TenantUserModel
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.id, users.tenant_id, users.email, users.password_hash, roles.name AS role_name").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.id = ? AND users.tenant_id = ?", userID, tenantID).
+		Take(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return model.ToDomainUser(), nil
+}
+
+func (r *tenantRepository) Delete(ctx context.Context, tenantID uint64) (int64, error) {
+	var affected int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, snapshot := range tenantSnapshots {
+			if err := tx.Exec(snapshot, tenantID).Error; err != nil {
+				return fmt.Errorf("failed to snapshot tenant data: %w", err)
+			}
+		}
+
+		before, err := countTenantData(tx, tenantID)
+		if err != nil {
+			return err
+		}
+
+		// Archive tables have no foreign key to tenants, so the cascade
+		// does not reach them
+		archiveDeletes := []string{
+			"DELETE FROM archived_transaction_items WHERE transaction_id IN (SELECT id FROM tmp_tenant_archived_transactions)",
+			"DELETE FROM archived_transaction_payments WHERE transaction_id IN (SELECT id FROM tmp_tenant_archived_transactions)",
+			"DELETE FROM archived_transactions WHERE id IN (SELECT id FROM tmp_tenant_archived_transactions)",
+		}
+		for _, statement := range archiveDeletes {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to delete archived transactions: %w", err)
+			}
+		}
+
+		// Everything else is removed by the ON DELETE CASCADE constraints
+		result := tx.Where("id = ?", tenantID).Delete(&TenantModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete tenant: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("tenant not found")
+		}
+
+		after, err := countTenantData(tx, tenantID)
+		if err != nil {
+			return err
+		}
+
+		var leftovers []string
+		for _, check := range tenantDataChecks {
+			if after[check.table] > 0 {
+				leftovers = append(leftovers, fmt.Sprintf("%s (%d)", check.table, after[check.table]))
+			}
+		}
+		if len(leftovers) > 0 {
+			return fmt.Errorf("tenant deletion left data behind in %s", strings.Join(leftovers, ", "))
+		}
+
+		affected = result.RowsAffected
+		for _, count := range before {
+			affected += count
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+func (r *tenantRepository) RecordDeletion(ctx context.Context, tenant *domain.Tenant, recordsAffected int64, executionTime time.Duration, deletionErr error) error {
+	seconds := executionTime.Seconds()
+	model := &DataRetentionLogModel{
+		TenantID:        tenant.ID,
+		RetentionType:   "tenant_delete",
+		RecordsAffected: int(recordsAffected),
+		DateFrom:        tenant.CreatedAt,
+		DateTo:          time.Now(),
+		ExecutionTime:   &seconds,
+		Status:          "success",
+	}
+
+	if deletionErr != nil {
+		model.Status = "failed"
+		model.ErrorMessage = deletionErr.Error()
+	}
+
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to record tenant deletion: %w", err)
+	}
+
+	return nil
+}
+
+func countTenantData(tx *gorm.DB, tenantID uint64) (map[string]int64, error) {
+	counts := make(map[string]int64, len(tenantDataChecks))
+	for _, check := range tenantDataChecks {
+		var count int64
+
+		// Only the tenant_id checks take the parameter
+		args := []interface{}{}
+		if strings.Contains(check.query, "?") {
+			args = append(args, tenantID)
+		}
+
+		if err := tx.Raw(check.query, args...).Scan(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", check.table, err)
+		}
+		counts[check.table] = count
+	}
+
+	return counts, nil
+}
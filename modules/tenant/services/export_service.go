@@ -0,0 +1,235 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+// exportTimeout bounds a single export run so a stuck query cannot leave the
+// export in "processing" forever.
+const exportTimeout = 30 * time.Minute
+
+type exportService struct {
+	tenantRepo domain.TenantRepository
+	exportRepo domain.ExportRepository
+	eventBus   messaging.EventBus
+	exportDir  string
+	expiry     time.Duration
+}
+
+func NewExportService(
+	tenantRepo domain.TenantRepository,
+	exportRepo domain.ExportRepository,
+	eventBus messaging.EventBus,
+	exportDir string,
+	expiryHours int,
+) domain.ExportService {
+	return &exportService{
+		tenantRepo: tenantRepo,
+		exportRepo: exportRepo,
+		eventBus:   eventBus,
+		exportDir:  exportDir,
+		expiry:     time.Duration(expiryHours) * time.Hour,
+	}
+}
+
+func (s *exportService) RequestExport(ctx context.Context, tenantID, userID uint64, req domain.CreateTenantExportRequest) (*domain.TenantExport, error) {
+	user, err := s.tenantRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsOwner() {
+		return nil, errors.New("only the tenant owner can export tenant data")
+	}
+
+	active, err := s.exportRepo.HasActiveExport(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, errors.New("an export is already in progress")
+	}
+
+	export := &domain.TenantExport{
+		TenantID:     tenantID,
+		RequestedBy:  userID,
+		Format:       req.Format,
+		Status:       domain.ExportStatusPending,
+		RecordCounts: map[string]int64{},
+	}
+
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	// The export outlives the request, so it runs on its own context
+	go s.runExport(*export)
+
+	return export, nil
+}
+
+func (s *exportService) GetExport(ctx context.Context, tenantID, exportID uint64) (*domain.TenantExport, error) {
+	return s.exportRepo.FindByID(ctx, tenantID, exportID)
+}
+
+func (s *exportService) GetExports(ctx context.Context, tenantID uint64, limit, offset int) ([]*domain.TenantExport, int64, error) {
+	return s.exportRepo.FindByTenant(ctx, tenantID, limit, offset)
+}
+
+func (s *exportService) OpenExport(ctx context.Context, tenantID, exportID uint64) (*domain.TenantExport, error) {
+	export, err := s.exportRepo.FindByID(ctx, tenantID, exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status != domain.ExportStatusCompleted {
+		return nil, errors.New("export is not ready")
+	}
+	if export.IsExpired(time.Now()) {
+		return nil, errors.New("export has expired")
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		return nil, errors.New("export has expired")
+	}
+
+	return export, nil
+}
+
+func (s *exportService) runExport(export domain.TenantExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	export.Status = domain.ExportStatusProcessing
+	if err := s.exportRepo.Update(ctx, &export); err != nil {
+		log.Printf("tenant export %d: failed to mark processing: %v", export.ID, err)
+		return
+	}
+
+	path, size, err := s.writeArchive(ctx, &export)
+	if err != nil {
+		log.Printf("tenant export %d: %v", export.ID, err)
+		if path != "" {
+			os.Remove(path)
+		}
+
+		export.Status = domain.ExportStatusFailed
+		export.ErrorMessage = err.Error()
+		if err := s.exportRepo.Update(ctx, &export); err != nil {
+			log.Printf("tenant export %d: failed to mark failed: %v", export.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.expiry)
+	export.Status = domain.ExportStatusCompleted
+	export.FilePath = path
+	export.FileSize = size
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	if err := s.exportRepo.Update(ctx, &export); err != nil {
+		log.Printf("tenant export %d: failed to mark completed: %v", export.ID, err)
+		return
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("tenant.export_completed", export.TenantID, export.RequestedBy, map[string]interface{}{
+			"export_id": export.ID,
+			"file_size": export.FileSize,
+		})
+		s.eventBus.Publish(ctx, "tenant.export", event)
+	}
+}
+
+// writeArchive writes every export dataset into a zip file and returns its
+// path and size. The returned path is set whenever the file was created, so
+// the caller can clean it up on error.
+func (s *exportService) writeArchive(ctx context.Context, export *domain.TenantExport) (string, int64, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, export.TenantID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	dir := filepath.Join(s.exportDir, fmt.Sprintf("%d", export.TenantID))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("export-%d-%s.zip", export.ID, time.Now().Format("20060102150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	for _, dataset := range domain.ExportDatasets {
+		entry, err := archive.Create(dataset + "." + export.Format)
+		if err != nil {
+			return path, 0, fmt.Errorf("failed to add %s to archive: %w", dataset, err)
+		}
+
+		var writer interface {
+			domain.RecordWriter
+			Close() error
+		}
+		if export.Format == domain.ExportFormatJSON {
+			writer = newJSONRecordWriter(entry)
+		} else {
+			writer = newCSVRecordWriter(entry)
+		}
+
+		count, err := s.exportRepo.WriteDataset(ctx, export.TenantID, dataset, writer)
+		if err != nil {
+			return path, 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return path, 0, fmt.Errorf("failed to write %s: %w", dataset, err)
+		}
+
+		export.RecordCounts[dataset] = count
+	}
+
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"export_id":     export.ID,
+		"tenant_id":     tenant.ID,
+		"tenant_name":   tenant.Name,
+		"format":        export.Format,
+		"generated_at":  time.Now().Format(time.RFC3339),
+		"record_counts": export.RecordCounts,
+	}, "", "  ")
+	if err != nil {
+		return path, 0, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	entry, err := archive.Create("manifest.json")
+	if err != nil {
+		return path, 0, fmt.Errorf("failed to add manifest to archive: %w", err)
+	}
+	if _, err := entry.Write(manifest); err != nil {
+		return path, 0, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return path, 0, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return path, 0, fmt.Errorf("failed to stat export file: %w", err)
+	}
+
+	return path, info.Size(), nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// csvRecordWriter writes an export dataset as CSV with a header row.
+type csvRecordWriter struct {
+	writer *csv.Writer
+	row    []string
+}
+
+func newCSVRecordWriter(w io.Writer) *csvRecordWriter {
+	return &csvRecordWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *csvRecordWriter) WriteHeader(columns []string) error {
+	w.row = make([]string, len(columns))
+	return w.writer.Write(columns)
+}
+
+func (w *csvRecordWriter) WriteRecord(values []interface{}) error {
+	for i, value := range values {
+		w.row[i] = formatCSVValue(value)
+	}
+	return w.writer.Write(w.row)
+}
+
+func (w *csvRecordWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonRecordWriter writes an export dataset as a JSON array of objects keyed
+// by column name.
+type jsonRecordWriter struct {
+	writer  *bufio.Writer
+	columns []string
+	count   int
+}
+
+func newJSONRecordWriter(w io.Writer) *jsonRecordWriter {
+	return &jsonRecordWriter{writer: bufio.NewWriter(w)}
+}
+
+func (w *jsonRecordWriter) WriteHeader(columns []string) error {
+	w.columns = columns
+	_, err := w.writer.WriteString("[")
+	return err
+}
+
+func (w *jsonRecordWriter) WriteRecord(values []interface{}) error {
+	record := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		record[w.columns[i]] = formatJSONValue(value)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if w.count > 0 {
+		if _, err := w.writer.WriteString(","); err != nil {
+			return err
+		}
+	}
+	if _, err := w.writer.WriteString("\n  "); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(data); err != nil {
+		return err
+	}
+
+	w.count++
+	return nil
+}
+
+func (w *jsonRecordWriter) Close() error {
+	closing := "]\n"
+	if w.count > 0 {
+		closing = "\n]\n"
+	}
+	if _, err := w.writer.WriteString(closing); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
+
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		// JSON columns are embedded as documents rather than strings
+		if json.Valid(v) {
+			return json.RawMessage(v)
+		}
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/tenant/domain"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"github.com/exven/pos-system/shared/utils/crypto"
+)
+
+type tenantService struct {
+	tenantRepo  domain.TenantRepository
+	eventBus    messaging.EventBus
+	storagePath string
+}
+
+func NewTenantService(tenantRepo domain.TenantRepository, eventBus messaging.EventBus, storagePath string) domain.TenantService {
+	return &tenantService{
+		tenantRepo:  tenantRepo,
+		eventBus:    eventBus,
+		storagePath: storagePath,
+	}
+}
+
+func (s *tenantService) GetProfile(ctx context.Context, tenantID uint64) (*domain.Tenant, error) {
+	return s.tenantRepo.FindByID(ctx, tenantID)
+}
+
+func (s *tenantService) UpdateProfile(ctx context.Context, tenantID uint64, req domain.UpdateTenantRequest) (*domain.Tenant, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate email uniqueness (excluding current tenant)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	exists, err := s.tenantRepo.IsEmailExists(ctx, email, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("tenant with this email already exists")
+	}
+
+	tenant.Name = strings.TrimSpace(req.Name)
+	tenant.BusinessType = strings.TrimSpace(req.BusinessType)
+	tenant.Email = email
+	tenant.Phone = strings.TrimSpace(req.Phone)
+	tenant.Address = strings.TrimSpace(req.Address)
+	tenant.City = strings.TrimSpace(req.City)
+	tenant.Province = strings.TrimSpace(req.Province)
+	tenant.PostalCode = strings.TrimSpace(req.PostalCode)
+	tenant.TaxNumber = strings.TrimSpace(req.TaxNumber)
+	tenant.LogoURL = strings.TrimSpace(req.LogoURL)
+	tenant.Timezone = req.Timezone
+	tenant.Currency = strings.ToUpper(req.Currency)
+	tenant.UpdatedAt = time.Now()
+
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	return s.tenantRepo.FindByID(ctx, tenantID)
+}
+
+func (s *tenantService) GetSettings(ctx context.Context, tenantID uint64) (*domain.TenantSettings, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenant.Settings, nil
+}
+
+// UpdateSettings merges the sections present in the request into the saved
+// settings, so clients changing one section do not reset the others
+func (s *tenantService) UpdateSettings(ctx context.Context, tenantID uint64, req domain.UpdateTenantSettingsRequest) (*domain.TenantSettings, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := tenant.Settings
+
+	if req.Receipt != nil {
+		settings.Receipt = domain.ReceiptSettings{
+			Header:        strings.TrimSpace(req.Receipt.Header),
+			Footer:        strings.TrimSpace(req.Receipt.Footer),
+			ShowLogo:      req.Receipt.ShowLogo,
+			ShowTaxNumber: req.Receipt.ShowTaxNumber,
+		}
+	}
+	if req.DefaultTaxRate != nil {
+		settings.DefaultTaxRate = *req.DefaultTaxRate
+	}
+	if req.PricesIncludeTax != nil {
+		settings.PricesIncludeTax = *req.PricesIncludeTax
+	}
+	if req.Rounding != nil {
+		if req.Rounding.Mode != domain.RoundingModeNone && req.Rounding.Increment <= 0 {
+			return nil, errors.New("rounding increment must be greater than 0")
+		}
+		settings.Rounding = domain.RoundingSettings{
+			Mode:      req.Rounding.Mode,
+			Increment: req.Rounding.Increment,
+		}
+	}
+	if req.NumberFormat != nil {
+		settings.NumberFormat = domain.NumberFormatSettings{
+			DecimalSeparator:   req.NumberFormat.DecimalSeparator,
+			ThousandsSeparator: req.NumberFormat.ThousandsSeparator,
+			DecimalPlaces:      req.NumberFormat.DecimalPlaces,
+			CurrencySymbol:     strings.TrimSpace(req.NumberFormat.CurrencySymbol),
+		}
+	}
+	if req.Inventory != nil {
+		costPricePolicy := req.Inventory.CostPricePolicy
+		if costPricePolicy == "" {
+			costPricePolicy = domain.CostPricePolicyLastCost
+		}
+		costingMethod := req.Inventory.CostingMethod
+		if costingMethod == "" {
+			costingMethod = domain.CostingMethodFIFO
+		}
+		settings.Inventory = domain.InventorySettings{
+			AdjustmentApprovalThreshold: req.Inventory.AdjustmentApprovalThreshold,
+			CostPricePolicy:             costPricePolicy,
+			CostingMethod:               costingMethod,
+		}
+	}
+	if req.Barcode != nil {
+		barcodePrefix := req.Barcode.Prefix
+		if barcodePrefix == "" {
+			barcodePrefix = domain.DefaultBarcodePrefix
+		}
+		barcodeFormat := req.Barcode.Format
+		if barcodeFormat == "" {
+			barcodeFormat = domain.BarcodeFormatEAN13
+		}
+		settings.Barcode = domain.BarcodeSettings{
+			Prefix:     barcodePrefix,
+			Format:     barcodeFormat,
+			AutoAssign: req.Barcode.AutoAssign,
+		}
+	}
+
+	tenant.Settings = settings
+	tenant.UpdatedAt = time.Now()
+
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	return &tenant.Settings, nil
+}
+
+func (s *tenantService) DeleteTenant(ctx context.Context, tenantID, userID uint64, req domain.DeleteTenantRequest) (*domain.TenantDeletion, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.tenantRepo.FindUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsOwner() {
+		return nil, errors.New("only the tenant owner can delete the tenant")
+	}
+
+	// The caller proves intent by typing the tenant name and their password
+	if strings.TrimSpace(req.TenantName) != tenant.Name {
+		return nil, errors.New("tenant name does not match")
+	}
+	if err := crypto.CheckPassword(req.Password, user.PasswordHash); err != nil {
+		return nil, errors.New("invalid password")
+	}
+
+	startedAt := time.Now()
+	affected, deleteErr := s.tenantRepo.Delete(ctx, tenantID)
+	if err := s.tenantRepo.RecordDeletion(ctx, tenant, affected, time.Since(startedAt), deleteErr); err != nil {
+		log.Printf("tenant %d: %v", tenantID, err)
+	}
+	if deleteErr != nil {
+		return nil, fmt.Errorf("failed to delete tenant: %w", deleteErr)
+	}
+
+	// Export archives and import uploads live outside the database and are
+	// not covered by the cascade
+	for _, dir := range []string{"exports", "imports"} {
+		if err := os.RemoveAll(filepath.Join(s.storagePath, dir, fmt.Sprintf("%d", tenantID))); err != nil {
+			log.Printf("tenant %d: failed to remove %s files: %v", tenantID, dir, err)
+		}
+	}
+
+	deletion := &domain.TenantDeletion{
+		TenantID:        tenantID,
+		RecordsAffected: affected,
+		DeletedAt:       time.Now(),
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("tenant.deleted", tenantID, userID, map[string]interface{}{
+			"records_deleted": affected,
+		})
+		s.eventBus.Publish(ctx, "tenant.deleted", event)
+	}
+
+	return deletion, nil
+}
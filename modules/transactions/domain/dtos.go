@@ -0,0 +1,112 @@
+package domain
+
+import "time"
+
+// TransactionQuery filters transactions; dates are whole days in the
+// tenant's timezone
+type TransactionQuery struct {
+	OutletID   *uint64
+	CashierID  *uint64
+	CustomerID *uint64
+	DateFrom   *time.Time
+	DateTo     *time.Time
+}
+
+// CreateTransactionRequest completes a sale. Items are priced on the server
+// through the price lists valid at the outlet for the customer, plus the
+// price deltas of their modifiers, and the totals are worked out from those
+// prices.
+type CreateTransactionRequest struct {
+	OutletID       uint64                      `json:"outlet_id" validate:"required"`
+	CustomerID     *uint64                     `json:"customer_id"`
+	DiscountAmount float64                     `json:"discount_amount" validate:"min=0"`
+	TaxAmount      float64                     `json:"tax_amount" validate:"min=0"`
+	Notes          string                      `json:"notes" validate:"max=1000"`
+	Items          []TransactionItemRequest    `json:"items" validate:"required,min=1,max=200,dive"`
+	Payments       []TransactionPaymentRequest `json:"payments" validate:"required,min=1,max=10,dive"`
+}
+
+// TransactionItemRequest is one sold product or variant. Choices are the
+// bundle items picked from a bundle's groups and ModifierIDs the modifiers
+// picked for every base unit of the product. UnitPrice is the price per
+// unit the POS showed, if it sends one; the sale is refused when it is no
+// longer the current price.
+type TransactionItemRequest struct {
+	ProductID      uint64   `json:"product_id" validate:"required"`
+	VariantID      *uint64  `json:"variant_id"`
+	Quantity       float64  `json:"quantity" validate:"required,gt=0"`
+	Unit           string   `json:"unit" validate:"max=50"`
+	UnitPrice      *float64 `json:"unit_price" validate:"omitempty,min=0"`
+	DiscountAmount float64  `json:"discount_amount" validate:"min=0"`
+	Notes          string   `json:"notes" validate:"max=500"`
+	Choices        []uint64 `json:"choices" validate:"max=50"`
+	ModifierIDs    []uint64 `json:"modifier_ids" validate:"max=50"`
+}
+
+type TransactionPaymentRequest struct {
+	PaymentMethod   string  `json:"payment_method" validate:"required,oneof=cash card transfer ewallet"`
+	Amount          float64 `json:"amount" validate:"required,gt=0"`
+	ReferenceNumber string  `json:"reference_number" validate:"max=100"`
+	Notes           string  `json:"notes" validate:"max=500"`
+}
+
+type TransactionResponse struct {
+	ID                uint64                       `json:"id"`
+	TransactionNumber string                       `json:"transaction_number"`
+	TransactionDate   string                       `json:"transaction_date"`
+	OutletID          uint64                       `json:"outlet_id"`
+	OutletName        string                       `json:"outlet_name"`
+	OutletCode        string                       `json:"outlet_code"`
+	CashierID         uint64                       `json:"cashier_id"`
+	CashierName       string                       `json:"cashier_name"`
+	CustomerID        *uint64                      `json:"customer_id"`
+	CustomerName      string                       `json:"customer_name"`
+	CustomerPhone     string                       `json:"customer_phone"`
+	CustomerEmail     string                       `json:"customer_email"`
+	Subtotal          float64                      `json:"subtotal"`
+	DiscountAmount    float64                      `json:"discount_amount"`
+	TaxAmount         float64                      `json:"tax_amount"`
+	TotalAmount       float64                      `json:"total_amount"`
+	PaidAmount        float64                      `json:"paid_amount"`
+	ChangeAmount      float64                      `json:"change_amount"`
+	PaymentMethod     string                       `json:"payment_method"`
+	Status            string                       `json:"status"`
+	Notes             string                       `json:"notes"`
+	Items             []TransactionItemResponse    `json:"items,omitempty"`
+	Payments          []TransactionPaymentResponse `json:"payments,omitempty"`
+	CreatedAt         string                       `json:"created_at"`
+}
+
+type TransactionItemResponse struct {
+	ID             uint64                            `json:"id"`
+	ProductID      uint64                            `json:"product_id"`
+	VariantID      *uint64                           `json:"variant_id"`
+	SKU            string                            `json:"sku"`
+	ProductName    string                            `json:"product_name"`
+	Category       string                            `json:"category"`
+	Unit           string                            `json:"unit"`
+	UnitFactor     float64                           `json:"unit_factor"`
+	Quantity       float64                           `json:"quantity"`
+	UnitPrice      float64                           `json:"unit_price"`
+	CostPrice      float64                           `json:"cost_price"`
+	DiscountAmount float64                           `json:"discount_amount"`
+	TotalPrice     float64                           `json:"total_price"`
+	Notes          string                            `json:"notes"`
+	Modifiers      []TransactionItemModifierResponse `json:"modifiers,omitempty"`
+}
+
+type TransactionItemModifierResponse struct {
+	ModifierID *uint64 `json:"modifier_id"`
+	GroupName  string  `json:"group_name"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+	Quantity   float64 `json:"quantity"`
+}
+
+type TransactionPaymentResponse struct {
+	ID              uint64  `json:"id"`
+	PaymentMethod   string  `json:"payment_method"`
+	Amount          float64 `json:"amount"`
+	ReferenceNumber string  `json:"reference_number"`
+	Notes           string  `json:"notes"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	StatusCompleted = "completed"
+)
+
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodMultiple = "multiple"
+)
+
+// Transaction is a completed sale at an outlet. The names of its outlet,
+// cashier, customer and products are kept as they were at the sale.
+type Transaction struct {
+	ID                uint64
+	TenantID          uint64
+	OutletID          uint64
+	OutletName        string
+	OutletCode        string
+	CashierID         uint64
+	CashierName       string
+	CustomerID        *uint64
+	CustomerName      string
+	CustomerPhone     string
+	CustomerEmail     string
+	TransactionNumber string
+	TransactionDate   time.Time
+	Subtotal          float64
+	DiscountAmount    float64
+	TaxAmount         float64
+	TotalAmount       float64
+	PaidAmount        float64
+	ChangeAmount      float64
+	PaymentMethod     string
+	Status            string
+	Notes             string
+	Items             []*TransactionItem
+	Payments          []*TransactionPayment
+	CreatedAt         time.Time
+}
+
+// TransactionItem is one line of a transaction. Quantity is in Unit, the
+// product's base unit when empty, and UnitFactor is the base units in one
+// of it. CostPrice is the cost of one sold unit, as stock costed it at the
+// sale. Choices and ModifierIDs are the bundle items and modifiers picked
+// for the line; the picked modifiers are stored as Modifiers.
+type TransactionItem struct {
+	ID             uint64
+	TransactionID  uint64
+	ProductID      uint64
+	VariantID      *uint64
+	ProductName    string
+	SKU            string
+	Category       string
+	Unit           string
+	UnitFactor     float64
+	Quantity       float64
+	UnitPrice      float64
+	CostPrice      float64
+	DiscountAmount float64
+	TotalPrice     float64
+	Notes          string
+	Choices        []uint64
+	ModifierIDs    []uint64
+	Modifiers      []*TransactionItemModifier
+}
+
+// TransactionItemModifier is a modifier picked on an item, as it was at the
+// sale
+type TransactionItemModifier struct {
+	ModifierID *uint64
+	GroupName  string
+	Name       string
+	PriceDelta float64
+	Quantity   float64
+}
+
+type TransactionPayment struct {
+	ID              uint64
+	PaymentMethod   string
+	Amount          float64
+	ReferenceNumber string
+	Notes           string
+}
+
+// GrossPrice is the item's price before its discount
+func (i *TransactionItem) GrossPrice() float64 {
+	return math.Round(i.Quantity*i.UnitPrice*100) / 100
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "context"
+
+type TransactionRepository interface {
+	// Create saves a completed transaction with its items and payments and
+	// snapshots of its outlet, cashier, customer and products. recordStock
+	// runs once they are stored, in the same database transaction, so a
+	// sale stock cannot take is not stored either; the item units, unit
+	// factors and cost prices it fills in are stored with the items.
+	Create(ctx context.Context, transaction *Transaction, recordStock func(ctx context.Context) error) error
+	FindByID(ctx context.Context, tenantID, id uint64) (*Transaction, error)
+	FindAll(ctx context.Context, tenantID uint64, query TransactionQuery, limit, offset int) ([]*Transaction, int64, error)
+}
+
+// StockRecorder takes a transaction's items from its outlet's stock. The
+// inventory module provides it; it is called with the context of the
+// transaction being stored and joins it. It fills in every item's unit, its
+// unit factor and its cost price.
+type StockRecorder interface {
+	RecordSale(ctx context.Context, userID uint64, transaction *Transaction) error
+}
+
+// PriceResolver prices sold items on the server. The products module
+// provides it. It fills in every item's unit price: the price of one unit
+// it is sold in, at its outlet to its customer now, after price lists and
+// the price deltas of its modifiers.
+type PriceResolver interface {
+	ResolvePrices(ctx context.Context, transaction *Transaction) error
+}
+
+// UsageMeter meters completed sales against the tenant's plan. The usage
+// module provides it.
+type UsageMeter interface {
+	// CheckTransactionLimit fails with "monthly transaction limit reached"
+	// once the tenant has no transactions left this month
+	CheckTransactionLimit(ctx context.Context, tenantID uint64) error
+	RecordTransaction(ctx context.Context, tenantID uint64) error
+}
+
+type TransactionService interface {
+	CreateTransaction(ctx context.Context, tenantID, userID uint64, req CreateTransactionRequest) (*Transaction, error)
+	GetTransaction(ctx context.Context, tenantID, id uint64) (*Transaction, error)
+	GetTransactions(ctx context.Context, tenantID uint64, query TransactionQuery, limit, offset int) ([]*Transaction, int64, error)
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/transactions/domain"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type TransactionHandler struct {
+	transactionService domain.TransactionService
+}
+
+func NewTransactionHandler(transactionService domain.TransactionService) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+	}
+}
+
+func (h *TransactionHandler) RegisterRoutes(e *echo.Group) {
+	transactions := e.Group("/transactions")
+	transactions.GET("", h.GetTransactions)
+	transactions.POST("", h.CreateTransaction)
+	transactions.GET("/:id", h.GetTransaction)
+}
+
+func (h *TransactionHandler) CreateTransaction(c echo.Context) error {
+	var req domain.CreateTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request format")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ValidationErrorFromErr(c, err)
+	}
+
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	transaction, err := h.transactionService.CreateTransaction(c.Request().Context(), tenantID, userID, req)
+	if err != nil {
+		return h.transactionError(c, err, "Failed to create transaction")
+	}
+
+	return response.Created(c, "Transaction completed successfully", h.transactionToResponse(transaction))
+}
+
+func (h *TransactionHandler) GetTransactions(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	page, limit, offset := pagination(c)
+
+	query := domain.TransactionQuery{}
+	fieldErrors := idParams(c, map[string]**uint64{
+		"outlet_id":   &query.OutletID,
+		"cashier_id":  &query.CashierID,
+		"customer_id": &query.CustomerID,
+	})
+	if dateFrom := dateParam(c, "date_from", fieldErrors); !dateFrom.IsZero() {
+		query.DateFrom = &dateFrom
+	}
+	if dateTo := dateParam(c, "date_to", fieldErrors); !dateTo.IsZero() {
+		query.DateTo = &dateTo
+	}
+
+	if len(fieldErrors) > 0 {
+		return response.ValidationError(c, fieldErrors)
+	}
+
+	transactions, total, err := h.transactionService.GetTransactions(c.Request().Context(), tenantID, query, limit, offset)
+	if err != nil {
+		if err.Error() == "date from must not be after date to" {
+			return response.ValidationError(c, map[string][]string{
+				"date_from": {"Must not be after date_to"},
+			})
+		}
+		return response.InternalError(c, "Failed to get transactions")
+	}
+
+	transactionResponses := make([]domain.TransactionResponse, len(transactions))
+	for i, transaction := range transactions {
+		transactionResponses[i] = h.transactionToResponse(transaction)
+	}
+
+	return response.SuccessWithPagination(c, "Transactions retrieved successfully", transactionResponses, page, limit, int(total))
+}
+
+func (h *TransactionHandler) GetTransaction(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid transaction ID")
+	}
+
+	transaction, err := h.transactionService.GetTransaction(c.Request().Context(), tenantID, id)
+	if err != nil {
+		return h.transactionError(c, err, "Failed to get transaction")
+	}
+
+	return response.Success(c, "Transaction retrieved successfully", h.transactionToResponse(transaction))
+}
+
+func pagination(c echo.Context) (page, limit, offset int) {
+	page = 1
+	limit = 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if pageInt, err := strconv.Atoi(p); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 && limitInt <= 100 {
+			limit = limitInt
+		}
+	}
+
+	return page, limit, (page - 1) * limit
+}
+
+// idParams parses optional ID query parameters into their targets and
+// returns the field errors of invalid ones
+func idParams(c echo.Context, targets map[string]**uint64) map[string][]string {
+	fieldErrors := map[string][]string{}
+
+	for param, target := range targets {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				fieldErrors[param] = []string{"Must be a valid ID"}
+				continue
+			}
+			*target = &id
+		}
+	}
+
+	return fieldErrors
+}
+
+func dateParam(c echo.Context, param string, fieldErrors map[string][]string) time.Time {
+	value := c.QueryParam(param)
+	if value == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		fieldErrors[param] = []string{"Must be a date in YYYY-MM-DD format"}
+	}
+	return date
+}
+
+func (h *TransactionHandler) transactionError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "transaction not found":
+		return response.NotFound(c, "Transaction not found")
+	case "outlet not found":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet not found"},
+		})
+	case "outlet is inactive":
+		return response.ValidationError(c, map[string][]string{
+			"outlet_id": {"Outlet is inactive"},
+		})
+	case "customer not found":
+		return response.ValidationError(c, map[string][]string{
+			"customer_id": {"Customer not found"},
+		})
+	case "product not found":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product not found"},
+		})
+	case "product is inactive":
+		return response.ValidationError(c, map[string][]string{
+			"product_id": {"Product is inactive"},
+		})
+	case "variant not found":
+		return response.ValidationError(c, map[string][]string{
+			"variant_id": {"Variant not found"},
+		})
+	case "variant is inactive":
+		return response.ValidationError(c, map[string][]string{
+			"variant_id": {"Variant is inactive"},
+		})
+	case "unit is not configured for the product":
+		return response.ValidationError(c, map[string][]string{
+			"unit": {"Unit is not configured for the product"},
+		})
+	case "quantity must be a whole number of its unit":
+		return response.ValidationError(c, map[string][]string{
+			"quantity": {"Quantity must be a whole number of its unit"},
+		})
+	case "only bundles have choices":
+		return response.ValidationError(c, map[string][]string{
+			"choices": {"Only bundles have choices"},
+		})
+	case "invalid bundle choice", "bundle choices do not match its groups":
+		return response.ValidationError(c, map[string][]string{
+			"choices": {"Choices must pick every group of the bundle"},
+		})
+	case "invalid modifier", "modifier is picked more than once", "modifiers do not match the product's modifier groups":
+		return response.ValidationError(c, map[string][]string{
+			"modifier_ids": {"Modifiers must match the product's modifier groups"},
+		})
+	case "unit price does not match the current price":
+		return response.ValidationError(c, map[string][]string{
+			"unit_price": {"Unit price does not match the current price"},
+		})
+	case "item discount exceeds its price":
+		return response.ValidationError(c, map[string][]string{
+			"discount_amount": {"Item discount exceeds its price"},
+		})
+	case "discount exceeds the subtotal":
+		return response.ValidationError(c, map[string][]string{
+			"discount_amount": {"Discount exceeds the subtotal"},
+		})
+	case "payment is less than the total":
+		return response.ValidationError(c, map[string][]string{
+			"payments": {"Payments must cover the total"},
+		})
+	case "only cash payments can give change":
+		return response.ValidationError(c, map[string][]string{
+			"payments": {"Only cash payments can exceed the total"},
+		})
+	case "insufficient stock", "insufficient unexpired stock":
+		return response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+	case "monthly transaction limit reached":
+		return response.Error(c, http.StatusForbidden, err.Error(), nil)
+	}
+	return response.InternalError(c, fallback)
+}
+
+func (h *TransactionHandler) transactionToResponse(transaction *domain.Transaction) domain.TransactionResponse {
+	transactionResponse := domain.TransactionResponse{
+		ID:                transaction.ID,
+		TransactionNumber: transaction.TransactionNumber,
+		TransactionDate:   transaction.TransactionDate.Format(time.RFC3339),
+		OutletID:          transaction.OutletID,
+		OutletName:        transaction.OutletName,
+		OutletCode:        transaction.OutletCode,
+		CashierID:         transaction.CashierID,
+		CashierName:       transaction.CashierName,
+		CustomerID:        transaction.CustomerID,
+		CustomerName:      transaction.CustomerName,
+		CustomerPhone:     transaction.CustomerPhone,
+		CustomerEmail:     transaction.CustomerEmail,
+		Subtotal:          transaction.Subtotal,
+		DiscountAmount:    transaction.DiscountAmount,
+		TaxAmount:         transaction.TaxAmount,
+		TotalAmount:       transaction.TotalAmount,
+		PaidAmount:        transaction.PaidAmount,
+		ChangeAmount:      transaction.ChangeAmount,
+		PaymentMethod:     transaction.PaymentMethod,
+		Status:            transaction.Status,
+		Notes:             transaction.Notes,
+		CreatedAt:         transaction.CreatedAt.Format(time.RFC3339),
+	}
+
+	if transaction.Items != nil {
+		transactionResponse.Items = make([]domain.TransactionItemResponse, len(transaction.Items))
+		for i, item := range transaction.Items {
+			transactionResponse.Items[i] = domain.TransactionItemResponse{
+				ID:             item.ID,
+				ProductID:      item.ProductID,
+				VariantID:      item.VariantID,
+				SKU:            item.SKU,
+				ProductName:    item.ProductName,
+				Category:       item.Category,
+				Unit:           item.Unit,
+				UnitFactor:     item.UnitFactor,
+				Quantity:       item.Quantity,
+				UnitPrice:      item.UnitPrice,
+				CostPrice:      item.CostPrice,
+				DiscountAmount: item.DiscountAmount,
+				TotalPrice:     item.TotalPrice,
+				Notes:          item.Notes,
+			}
+			for _, modifier := range item.Modifiers {
+				transactionResponse.Items[i].Modifiers = append(transactionResponse.Items[i].Modifiers, domain.TransactionItemModifierResponse{
+					ModifierID: modifier.ModifierID,
+					GroupName:  modifier.GroupName,
+					Name:       modifier.Name,
+					PriceDelta: modifier.PriceDelta,
+					Quantity:   modifier.Quantity,
+				})
+			}
+		}
+	}
+
+	if transaction.Payments != nil {
+		transactionResponse.Payments = make([]domain.TransactionPaymentResponse, len(transaction.Payments))
+		for i, payment := range transaction.Payments {
+			transactionResponse.Payments[i] = domain.TransactionPaymentResponse{
+				ID:              payment.ID,
+				PaymentMethod:   payment.PaymentMethod,
+				Amount:          payment.Amount,
+				ReferenceNumber: payment.ReferenceNumber,
+				Notes:           payment.Notes,
+			}
+		}
+	}
+
+	return transactionResponse
+}
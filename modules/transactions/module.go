@@ -0,0 +1,68 @@
+package transactions
+
+import (
+	"github.com/exven/pos-system/modules/transactions/domain"
+	"github.com/exven/pos-system/modules/transactions/handlers"
+	"github.com/exven/pos-system/modules/transactions/persistence"
+	"github.com/exven/pos-system/modules/transactions/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container container.Container
+	db        *gorm.DB
+	eventBus  messaging.EventBus
+	prices    domain.PriceResolver
+	stock     domain.StockRecorder
+	usage     domain.UsageMeter
+}
+
+// NewModule builds the transactions module. prices prices sold items; the
+// products module provides it. stock takes sold items from stock; the
+// inventory module provides it. usage meters sales against the tenant's
+// plan; the usage module provides it.
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	eventBus messaging.EventBus,
+	prices domain.PriceResolver,
+	stock domain.StockRecorder,
+	usage domain.UsageMeter,
+) *Module {
+	return &Module{
+		container: container,
+		db:        db,
+		eventBus:  eventBus,
+		prices:    prices,
+		stock:     stock,
+		usage:     usage,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("transactions.transactionRepository", func() interface{} {
+		return persistence.NewTransactionRepository(m.db)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("transactions.transactionService", func() interface{} {
+		return m.GetService()
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("transactions.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+func (m *Module) GetService() domain.TransactionService {
+	transactionRepo := persistence.NewTransactionRepository(m.db)
+	return services.NewTransactionService(transactionRepo, m.prices, m.stock, m.usage, m.eventBus)
+}
+
+func (m *Module) GetHandler() *handlers.TransactionHandler {
+	return handlers.NewTransactionHandler(m.GetService())
+}
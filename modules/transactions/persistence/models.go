@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/exven/pos-system/modules/transactions/domain"
+)
+
+type TransactionModel struct {
+	ID                    uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID              uint64    `gorm:"not null"`
+	OutletID              uint64    `gorm:"not null"`
+	CashierID             uint64    `gorm:"not null"`
+	CustomerID            *uint64   `gorm:"column:customer_id"`
+	CustomerNameSnapshot  string    `gorm:"size:255"`
+	CustomerPhoneSnapshot string    `gorm:"size:20"`
+	CustomerEmailSnapshot string    `gorm:"size:255"`
+	CashierNameSnapshot   string    `gorm:"size:255;not null"`
+	OutletNameSnapshot    string    `gorm:"size:255;not null"`
+	OutletCodeSnapshot    string    `gorm:"size:50;not null"`
+	TransactionNumber     string    `gorm:"size:100;not null"`
+	TransactionDate       time.Time `gorm:"not null"`
+	Subtotal              float64   `gorm:"type:decimal(15,2);not null"`
+	DiscountAmount        float64   `gorm:"type:decimal(15,2);default:0.00"`
+	TaxAmount             float64   `gorm:"type:decimal(15,2);default:0.00"`
+	TotalAmount           float64   `gorm:"type:decimal(15,2);not null"`
+	PaidAmount            float64   `gorm:"type:decimal(15,2);not null"`
+	ChangeAmount          float64   `gorm:"type:decimal(15,2);default:0.00"`
+	PaymentMethod         string    `gorm:"not null"`
+	Status                string    `gorm:"not null"`
+	Notes                 string    `gorm:"type:text"`
+	CreatedAt             time.Time `gorm:"autoCreateTime"`
+	UpdatedAt             time.Time `gorm:"autoUpdateTime"`
+
+	Items    []TransactionItemModel    `gorm:"foreignKey:TransactionID"`
+	Payments []TransactionPaymentModel `gorm:"foreignKey:TransactionID"`
+}
+
+func (TransactionModel) TableName() string {
+	return "transactions"
+}
+
+type TransactionItemModel struct {
+	ID                      uint64  `gorm:"primaryKey;autoIncrement"`
+	TransactionID           uint64  `gorm:"not null"`
+	ProductID               uint64  `gorm:"not null"`
+	VariantID               *uint64 `gorm:"column:variant_id"`
+	ProductNameSnapshot     string  `gorm:"size:255;not null"`
+	ProductSKUSnapshot      string  `gorm:"column:product_sku_snapshot;size:100;not null"`
+	ProductCategorySnapshot string  `gorm:"size:255"`
+	ProductUnitSnapshot     string  `gorm:"size:50"`
+	Quantity                float64 `gorm:"type:decimal(15,3);not null"`
+	UnitFactor              float64 `gorm:"type:decimal(15,6);not null;default:1"`
+	UnitPrice               float64 `gorm:"type:decimal(12,2);not null"`
+	CostPriceSnapshot       float64 `gorm:"type:decimal(12,2);default:0.00"`
+	DiscountAmount          float64 `gorm:"type:decimal(12,2);default:0.00"`
+	TotalPrice              float64 `gorm:"type:decimal(15,2);not null"`
+	Notes                   string  `gorm:"type:text"`
+
+	Modifiers []TransactionItemModifierModel `gorm:"foreignKey:TransactionItemID"`
+}
+
+func (TransactionItemModel) TableName() string {
+	return "transaction_items"
+}
+
+type TransactionItemModifierModel struct {
+	ID                uint64  `gorm:"primaryKey;autoIncrement"`
+	TransactionItemID uint64  `gorm:"not null"`
+	ModifierID        *uint64 `gorm:"column:modifier_id"`
+	GroupNameSnapshot string  `gorm:"size:100;not null"`
+	NameSnapshot      string  `gorm:"size:100;not null"`
+	PriceDelta        float64 `gorm:"type:decimal(12,2);not null"`
+	Quantity          float64 `gorm:"type:decimal(15,3);not null"`
+}
+
+func (TransactionItemModifierModel) TableName() string {
+	return "transaction_item_modifiers"
+}
+
+type TransactionPaymentModel struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TransactionID   uint64    `gorm:"not null"`
+	PaymentMethod   string    `gorm:"not null"`
+	Amount          float64   `gorm:"type:decimal(15,2);not null"`
+	ReferenceNumber string    `gorm:"size:100"`
+	Notes           string    `gorm:"type:text"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (TransactionPaymentModel) TableName() string {
+	return "transaction_payments"
+}
+
+// SaleOutletModel is the outlet a transaction is made at
+type SaleOutletModel struct {
+	ID       uint64
+	Name     string
+	Code     string
+	IsActive bool
+}
+
+// SaleCashierModel is the user who makes a transaction
+type SaleCashierModel struct {
+	ID       uint64
+	FullName string
+}
+
+// SaleCustomerModel is the customer a transaction is made for
+type SaleCustomerModel struct {
+	ID    uint64
+	Name  string
+	Phone string
+	Email string
+}
+
+// SaleProductModel is a sold product with its category name
+type SaleProductModel struct {
+	ID           uint64
+	SKU          string
+	Name         string
+	IsActive     bool
+	CategoryName string
+}
+
+// SaleVariantModel is a sold variant of a product
+type SaleVariantModel struct {
+	ID        uint64
+	ProductID uint64
+	SKU       string
+	Name      string
+	IsActive  bool
+}
+
+func (m *TransactionModel) ToDomainTransaction() *domain.Transaction {
+	transaction := &domain.Transaction{
+		ID:                m.ID,
+		TenantID:          m.TenantID,
+		OutletID:          m.OutletID,
+		OutletName:        m.OutletNameSnapshot,
+		OutletCode:        m.OutletCodeSnapshot,
+		CashierID:         m.CashierID,
+		CashierName:       m.CashierNameSnapshot,
+		CustomerID:        m.CustomerID,
+		CustomerName:      m.CustomerNameSnapshot,
+		CustomerPhone:     m.CustomerPhoneSnapshot,
+		CustomerEmail:     m.CustomerEmailSnapshot,
+		TransactionNumber: m.TransactionNumber,
+		TransactionDate:   m.TransactionDate,
+		Subtotal:          m.Subtotal,
+		DiscountAmount:    m.DiscountAmount,
+		TaxAmount:         m.TaxAmount,
+		TotalAmount:       m.TotalAmount,
+		PaidAmount:        m.PaidAmount,
+		ChangeAmount:      m.ChangeAmount,
+		PaymentMethod:     m.PaymentMethod,
+		Status:            m.Status,
+		Notes:             m.Notes,
+		CreatedAt:         m.CreatedAt,
+	}
+
+	if m.Items != nil {
+		transaction.Items = make([]*domain.TransactionItem, len(m.Items))
+		for i, item := range m.Items {
+			transaction.Items[i] = &domain.TransactionItem{
+				ID:             item.ID,
+				TransactionID:  item.TransactionID,
+				ProductID:      item.ProductID,
+				VariantID:      item.VariantID,
+				ProductName:    item.ProductNameSnapshot,
+				SKU:            item.ProductSKUSnapshot,
+				Category:       item.ProductCategorySnapshot,
+				Unit:           item.ProductUnitSnapshot,
+				UnitFactor:     item.UnitFactor,
+				Quantity:       item.Quantity,
+				UnitPrice:      item.UnitPrice,
+				CostPrice:      item.CostPriceSnapshot,
+				DiscountAmount: item.DiscountAmount,
+				TotalPrice:     item.TotalPrice,
+				Notes:          item.Notes,
+			}
+			for _, modifier := range item.Modifiers {
+				transaction.Items[i].Modifiers = append(transaction.Items[i].Modifiers, &domain.TransactionItemModifier{
+					ModifierID: modifier.ModifierID,
+					GroupName:  modifier.GroupNameSnapshot,
+					Name:       modifier.NameSnapshot,
+					PriceDelta: modifier.PriceDelta,
+					Quantity:   modifier.Quantity,
+				})
+			}
+		}
+	}
+
+	if m.Payments != nil {
+		transaction.Payments = make([]*domain.TransactionPayment, len(m.Payments))
+		for i, payment := range m.Payments {
+			transaction.Payments[i] = &domain.TransactionPayment{
+				ID:              payment.ID,
+				PaymentMethod:   payment.PaymentMethod,
+				Amount:          payment.Amount,
+				ReferenceNumber: payment.ReferenceNumber,
+				Notes:           payment.Notes,
+			}
+		}
+	}
+
+	return transaction
+}
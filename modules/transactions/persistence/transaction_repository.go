@@ -0,0 +1,338 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/transactions/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) domain.TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+func (r *transactionRepository) Create(ctx context.Context, transaction *domain.Transaction, recordStock func(ctx context.Context) error) error {
+	return database.InTransaction(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		if err := r.takeSnapshots(tx, transaction); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		number, err := nextTransactionNumber(tx, transaction.OutletID, now)
+		if err != nil {
+			return err
+		}
+		transaction.TransactionNumber = number
+		transaction.TransactionDate = now
+
+		model := TransactionModel{
+			TenantID:              transaction.TenantID,
+			OutletID:              transaction.OutletID,
+			CashierID:             transaction.CashierID,
+			CustomerID:            transaction.CustomerID,
+			CustomerNameSnapshot:  transaction.CustomerName,
+			CustomerPhoneSnapshot: transaction.CustomerPhone,
+			CustomerEmailSnapshot: transaction.CustomerEmail,
+			CashierNameSnapshot:   transaction.CashierName,
+			OutletNameSnapshot:    transaction.OutletName,
+			OutletCodeSnapshot:    transaction.OutletCode,
+			TransactionNumber:     transaction.TransactionNumber,
+			TransactionDate:       transaction.TransactionDate,
+			Subtotal:              transaction.Subtotal,
+			DiscountAmount:        transaction.DiscountAmount,
+			TaxAmount:             transaction.TaxAmount,
+			TotalAmount:           transaction.TotalAmount,
+			PaidAmount:            transaction.PaidAmount,
+			ChangeAmount:          transaction.ChangeAmount,
+			PaymentMethod:         transaction.PaymentMethod,
+			Status:                transaction.Status,
+			Notes:                 transaction.Notes,
+		}
+		if err := tx.Omit(clause.Associations).Create(&model).Error; err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		transaction.ID = model.ID
+		transaction.CreatedAt = model.CreatedAt
+
+		items := make([]*TransactionItemModel, len(transaction.Items))
+		for i, item := range transaction.Items {
+			item.TransactionID = model.ID
+			items[i] = &TransactionItemModel{
+				TransactionID:           model.ID,
+				ProductID:               item.ProductID,
+				VariantID:               item.VariantID,
+				ProductNameSnapshot:     item.ProductName,
+				ProductSKUSnapshot:      item.SKU,
+				ProductCategorySnapshot: item.Category,
+				ProductUnitSnapshot:     item.Unit,
+				Quantity:                item.Quantity,
+				UnitFactor:              1,
+				UnitPrice:               item.UnitPrice,
+				DiscountAmount:          item.DiscountAmount,
+				TotalPrice:              item.TotalPrice,
+				Notes:                   item.Notes,
+			}
+			if err := tx.Omit(clause.Associations).Create(items[i]).Error; err != nil {
+				return fmt.Errorf("failed to create transaction item: %w", err)
+			}
+			item.ID = items[i].ID
+		}
+
+		for _, payment := range transaction.Payments {
+			paymentModel := TransactionPaymentModel{
+				TransactionID:   model.ID,
+				PaymentMethod:   payment.PaymentMethod,
+				Amount:          payment.Amount,
+				ReferenceNumber: payment.ReferenceNumber,
+				Notes:           payment.Notes,
+			}
+			if err := tx.Create(&paymentModel).Error; err != nil {
+				return fmt.Errorf("failed to create transaction payment: %w", err)
+			}
+			payment.ID = paymentModel.ID
+		}
+
+		if err := recordStock(ctx); err != nil {
+			return err
+		}
+
+		// Stock resolved the units and costed the items
+		for i, item := range transaction.Items {
+			err := tx.Model(items[i]).Updates(map[string]interface{}{
+				"product_unit_snapshot": item.Unit,
+				"unit_factor":           item.UnitFactor,
+				"cost_price_snapshot":   item.CostPrice,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update transaction item: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// takeSnapshots fills in the names of the transaction's outlet, cashier,
+// customer and products as they are at the sale, checking they belong to
+// the tenant and can be sold
+func (r *transactionRepository) takeSnapshots(tx *gorm.DB, transaction *domain.Transaction) error {
+	var outlet SaleOutletModel
+	err := tx.Table("outlets").
+		Select("id, name, code, is_active").
+		Where("id = ? AND tenant_id = ?", transaction.OutletID, transaction.TenantID).
+		Take(&outlet).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("outlet not found")
+		}
+		return fmt.Errorf("failed to find outlet: %w", err)
+	}
+	if !outlet.IsActive {
+		return errors.New("outlet is inactive")
+	}
+	transaction.OutletName = outlet.Name
+	transaction.OutletCode = outlet.Code
+
+	var cashier SaleCashierModel
+	err = tx.Table("users").
+		Select("id, full_name").
+		Where("id = ? AND tenant_id = ?", transaction.CashierID, transaction.TenantID).
+		Take(&cashier).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("cashier not found")
+		}
+		return fmt.Errorf("failed to find cashier: %w", err)
+	}
+	transaction.CashierName = cashier.FullName
+
+	if transaction.CustomerID != nil {
+		var customer SaleCustomerModel
+		err := tx.Table("customers").
+			Select("id, name, phone, email").
+			Where("id = ? AND tenant_id = ?", *transaction.CustomerID, transaction.TenantID).
+			Take(&customer).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("customer not found")
+			}
+			return fmt.Errorf("failed to find customer: %w", err)
+		}
+		transaction.CustomerName = customer.Name
+		transaction.CustomerPhone = customer.Phone
+		transaction.CustomerEmail = customer.Email
+	}
+
+	productIDs := make([]uint64, len(transaction.Items))
+	var variantIDs []uint64
+	for i, item := range transaction.Items {
+		productIDs[i] = item.ProductID
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	var products []SaleProductModel
+	err = tx.Table("products p").
+		Select("p.id, p.sku, p.name, p.is_active, COALESCE(c.name, '') AS category_name").
+		Joins("LEFT JOIN product_categories c ON c.id = p.category_id").
+		Where("p.tenant_id = ? AND p.id IN ?", transaction.TenantID, productIDs).
+		Find(&products).Error
+	if err != nil {
+		return fmt.Errorf("failed to find products: %w", err)
+	}
+	productsByID := make(map[uint64]*SaleProductModel, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+
+	variantsByID := make(map[uint64]*SaleVariantModel, len(variantIDs))
+	if len(variantIDs) > 0 {
+		var variants []SaleVariantModel
+		err := tx.Table("product_variants").
+			Select("id, product_id, sku, name, is_active").
+			Where("tenant_id = ? AND id IN ?", transaction.TenantID, variantIDs).
+			Find(&variants).Error
+		if err != nil {
+			return fmt.Errorf("failed to find variants: %w", err)
+		}
+		for i := range variants {
+			variantsByID[variants[i].ID] = &variants[i]
+		}
+	}
+
+	for _, item := range transaction.Items {
+		product, ok := productsByID[item.ProductID]
+		if !ok {
+			return errors.New("product not found")
+		}
+		if !product.IsActive {
+			return errors.New("product is inactive")
+		}
+		item.ProductName = product.Name
+		item.SKU = product.SKU
+		item.Category = product.CategoryName
+
+		if item.VariantID == nil {
+			continue
+		}
+		variant, ok := variantsByID[*item.VariantID]
+		if !ok || variant.ProductID != product.ID {
+			return errors.New("variant not found")
+		}
+		if !variant.IsActive {
+			return errors.New("variant is inactive")
+		}
+		item.ProductName = fmt.Sprintf("%s - %s", product.Name, variant.Name)
+		item.SKU = variant.SKU
+	}
+
+	return nil
+}
+
+// nextTransactionNumber returns the next number of an outlet's transactions,
+// formatted as TRX-OUTLETID-YYYYMMDD-NNNN. Transaction numbers are unique
+// across tenants, so they are numbered per outlet; a transaction-scoped
+// advisory lock per outlet keeps concurrent sales from taking the same
+// number.
+func nextTransactionNumber(tx *gorm.DB, outletID uint64, now time.Time) (string, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('transactions'), ?)", int32(outletID)).Error; err != nil {
+		return "", fmt.Errorf("failed to lock transaction numbers: %w", err)
+	}
+
+	prefix := fmt.Sprintf("TRX-%d-%s-", outletID, now.Format("20060102"))
+
+	var count int64
+	err := tx.Model(&TransactionModel{}).
+		Where("outlet_id = ? AND transaction_number LIKE ?", outletID, prefix+"%").
+		Count(&count).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
+
+func (r *transactionRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.Transaction, error) {
+	var model TransactionModel
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
+	}
+
+	return model.ToDomainTransaction(), nil
+}
+
+func (r *transactionRepository) FindAll(ctx context.Context, tenantID uint64, query domain.TransactionQuery, limit, offset int) ([]*domain.Transaction, int64, error) {
+	filtered := r.db.WithContext(ctx).
+		Model(&TransactionModel{}).
+		Where("transactions.tenant_id = ?", tenantID)
+
+	if query.OutletID != nil {
+		filtered = filtered.Where("transactions.outlet_id = ?", *query.OutletID)
+	}
+	if query.CashierID != nil {
+		filtered = filtered.Where("transactions.cashier_id = ?", *query.CashierID)
+	}
+	if query.CustomerID != nil {
+		filtered = filtered.Where("transactions.customer_id = ?", *query.CustomerID)
+	}
+	// Days are cut at midnight in the tenant's timezone
+	if query.DateFrom != nil || query.DateTo != nil {
+		filtered = filtered.Joins("JOIN tenants t ON t.id = transactions.tenant_id")
+	}
+	if query.DateFrom != nil {
+		filtered = filtered.Where("transactions.transaction_date >= CAST(CAST(? AS date) AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')",
+			query.DateFrom.Format("2006-01-02"))
+	}
+	if query.DateTo != nil {
+		filtered = filtered.Where("transactions.transaction_date < CAST(CAST(? AS date) + 1 AS timestamp) AT TIME ZONE COALESCE(NULLIF(t.timezone, ''), 'UTC')",
+			query.DateTo.Format("2006-01-02"))
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	var models []TransactionModel
+	err := filtered.
+		Select("transactions.*").
+		Order("transactions.transaction_date DESC, transactions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find transactions: %w", err)
+	}
+
+	transactions := make([]*domain.Transaction, len(models))
+	for i := range models {
+		transactions[i] = models[i].ToDomainTransaction()
+	}
+
+	return transactions, total, nil
+}
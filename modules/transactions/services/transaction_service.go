@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/exven/pos-system/modules/transactions/domain"
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/exven/pos-system/shared/infrastructure/messaging"
+)
+
+type transactionService struct {
+	transactionRepo domain.TransactionRepository
+	prices          domain.PriceResolver
+	stock           domain.StockRecorder
+	usage           domain.UsageMeter
+	eventBus        messaging.EventBus
+}
+
+func NewTransactionService(
+	transactionRepo domain.TransactionRepository,
+	prices domain.PriceResolver,
+	stock domain.StockRecorder,
+	usage domain.UsageMeter,
+	eventBus messaging.EventBus,
+) domain.TransactionService {
+	return &transactionService{
+		transactionRepo: transactionRepo,
+		prices:          prices,
+		stock:           stock,
+		usage:           usage,
+		eventBus:        eventBus,
+	}
+}
+
+// CreateTransaction completes a sale. Items are charged at the prices the
+// server resolves for them; a price sent along must match. The items are taken from the outlet's
+// stock in the same database transaction that stores the sale, so a sale is
+// only stored with its stock movements. Sales count towards the tenant's
+// monthly transactions once they commit, and are refused when the plan has
+// none left.
+func (s *transactionService) CreateTransaction(ctx context.Context, tenantID, userID uint64, req domain.CreateTransactionRequest) (*domain.Transaction, error) {
+	transaction := &domain.Transaction{
+		TenantID:       tenantID,
+		OutletID:       req.OutletID,
+		CashierID:      userID,
+		CustomerID:     req.CustomerID,
+		DiscountAmount: roundAmount(req.DiscountAmount),
+		TaxAmount:      roundAmount(req.TaxAmount),
+		Status:         domain.StatusCompleted,
+		Notes:          strings.TrimSpace(req.Notes),
+		Items:          make([]*domain.TransactionItem, len(req.Items)),
+		Payments:       make([]*domain.TransactionPayment, len(req.Payments)),
+	}
+
+	for i, itemReq := range req.Items {
+		transaction.Items[i] = &domain.TransactionItem{
+			ProductID:      itemReq.ProductID,
+			VariantID:      itemReq.VariantID,
+			Quantity:       itemReq.Quantity,
+			Unit:           strings.TrimSpace(itemReq.Unit),
+			DiscountAmount: roundAmount(itemReq.DiscountAmount),
+			Notes:          strings.TrimSpace(itemReq.Notes),
+			Choices:        itemReq.Choices,
+			ModifierIDs:    itemReq.ModifierIDs,
+		}
+	}
+
+	if err := s.prices.ResolvePrices(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	for i, item := range transaction.Items {
+		// The POS may send the price it showed, which must still be current
+		if price := req.Items[i].UnitPrice; price != nil && roundAmount(*price) != item.UnitPrice {
+			return nil, errors.New("unit price does not match the current price")
+		}
+		if item.DiscountAmount > item.GrossPrice() {
+			return nil, errors.New("item discount exceeds its price")
+		}
+		item.TotalPrice = roundAmount(item.GrossPrice() - item.DiscountAmount)
+		transaction.Subtotal += item.TotalPrice
+	}
+	transaction.Subtotal = roundAmount(transaction.Subtotal)
+
+	if transaction.DiscountAmount > transaction.Subtotal {
+		return nil, errors.New("discount exceeds the subtotal")
+	}
+	transaction.TotalAmount = roundAmount(transaction.Subtotal - transaction.DiscountAmount + transaction.TaxAmount)
+
+	hasCash := false
+	for i, paymentReq := range req.Payments {
+		payment := &domain.TransactionPayment{
+			PaymentMethod:   paymentReq.PaymentMethod,
+			Amount:          roundAmount(paymentReq.Amount),
+			ReferenceNumber: strings.TrimSpace(paymentReq.ReferenceNumber),
+			Notes:           strings.TrimSpace(paymentReq.Notes),
+		}
+		if payment.PaymentMethod == domain.PaymentMethodCash {
+			hasCash = true
+		}
+		if i == 0 {
+			transaction.PaymentMethod = payment.PaymentMethod
+		} else if payment.PaymentMethod != transaction.PaymentMethod {
+			transaction.PaymentMethod = domain.PaymentMethodMultiple
+		}
+		transaction.PaidAmount += payment.Amount
+		transaction.Payments[i] = payment
+	}
+	transaction.PaidAmount = roundAmount(transaction.PaidAmount)
+
+	if transaction.PaidAmount < transaction.TotalAmount {
+		return nil, errors.New("payment is less than the total")
+	}
+	transaction.ChangeAmount = roundAmount(transaction.PaidAmount - transaction.TotalAmount)
+	if transaction.ChangeAmount > 0 && !hasCash {
+		return nil, errors.New("only cash payments can give change")
+	}
+
+	if err := s.usage.CheckTransactionLimit(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	err := s.transactionRepo.Create(ctx, transaction, func(ctx context.Context) error {
+		if err := s.stock.RecordSale(ctx, userID, transaction); err != nil {
+			return err
+		}
+
+		database.AfterCommit(ctx, func(ctx context.Context) {
+			if err := s.usage.RecordTransaction(ctx, tenantID); err != nil {
+				log.Printf("usage: tenant %d: %v", tenantID, err)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		event := messaging.NewEvent("transaction.completed", tenantID, userID, map[string]interface{}{
+			"transaction_id":     transaction.ID,
+			"transaction_number": transaction.TransactionNumber,
+			"outlet_id":          transaction.OutletID,
+			"customer_id":        transaction.CustomerID,
+			"total_amount":       transaction.TotalAmount,
+		})
+		s.eventBus.Publish(ctx, "transaction.completed", event)
+	}
+
+	return s.transactionRepo.FindByID(ctx, tenantID, transaction.ID)
+}
+
+func (s *transactionService) GetTransaction(ctx context.Context, tenantID, id uint64) (*domain.Transaction, error) {
+	return s.transactionRepo.FindByID(ctx, tenantID, id)
+}
+
+func (s *transactionService) GetTransactions(ctx context.Context, tenantID uint64, query domain.TransactionQuery, limit, offset int) ([]*domain.Transaction, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if query.DateFrom != nil && query.DateTo != nil && query.DateFrom.After(*query.DateTo) {
+		return nil, 0, errors.New("date from must not be after date to")
+	}
+
+	return s.transactionRepo.FindAll(ctx, tenantID, query, limit, offset)
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/exven/pos-system/modules/transactions/domain"
+)
+
+// fakeTransactionRepository stores transactions in memory. Without a
+// database transaction on the context, functions deferred until commit run
+// as soon as they are added.
+type fakeTransactionRepository struct {
+	created []*domain.Transaction
+}
+
+func (r *fakeTransactionRepository) Create(ctx context.Context, transaction *domain.Transaction, recordStock func(ctx context.Context) error) error {
+	if err := recordStock(ctx); err != nil {
+		return err
+	}
+	transaction.ID = uint64(len(r.created) + 1)
+	r.created = append(r.created, transaction)
+	return nil
+}
+
+func (r *fakeTransactionRepository) FindByID(ctx context.Context, tenantID, id uint64) (*domain.Transaction, error) {
+	for _, transaction := range r.created {
+		if transaction.TenantID == tenantID && transaction.ID == id {
+			return transaction, nil
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
+
+func (r *fakeTransactionRepository) FindAll(ctx context.Context, tenantID uint64, query domain.TransactionQuery, limit, offset int) ([]*domain.Transaction, int64, error) {
+	return r.created, int64(len(r.created)), nil
+}
+
+// fakePriceResolver prices every unit of a product at its price
+type fakePriceResolver map[uint64]float64
+
+func (r fakePriceResolver) ResolvePrices(ctx context.Context, transaction *domain.Transaction) error {
+	for _, item := range transaction.Items {
+		price, ok := r[item.ProductID]
+		if !ok {
+			return errors.New("product not found")
+		}
+		item.UnitPrice = price
+	}
+	return nil
+}
+
+var prices = fakePriceResolver{3: 25000}
+
+type fakeStockRecorder struct {
+	err error
+}
+
+func (r *fakeStockRecorder) RecordSale(ctx context.Context, userID uint64, transaction *domain.Transaction) error {
+	return r.err
+}
+
+// fakeUsageMeter allows limit transactions a month
+type fakeUsageMeter struct {
+	limit    int
+	recorded int
+}
+
+func (m *fakeUsageMeter) CheckTransactionLimit(ctx context.Context, tenantID uint64) error {
+	if m.recorded >= m.limit {
+		return errors.New("monthly transaction limit reached")
+	}
+	return nil
+}
+
+func (m *fakeUsageMeter) RecordTransaction(ctx context.Context, tenantID uint64) error {
+	m.recorded++
+	return nil
+}
+
+func saleRequest() domain.CreateTransactionRequest {
+	return domain.CreateTransactionRequest{
+		OutletID: 1,
+		Items: []domain.TransactionItemRequest{
+			{ProductID: 3, Quantity: 2},
+		},
+		Payments: []domain.TransactionPaymentRequest{
+			{PaymentMethod: domain.PaymentMethodCash, Amount: 50000},
+		},
+	}
+}
+
+func TestCreateTransactionStopsAtTheMonthlyLimit(t *testing.T) {
+	repo := &fakeTransactionRepository{}
+	meter := &fakeUsageMeter{limit: 2}
+	service := NewTransactionService(repo, prices, &fakeStockRecorder{}, meter, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.CreateTransaction(ctx, 1, 7, saleRequest()); err != nil {
+			t.Fatalf("sale %d: error = %v", i+1, err)
+		}
+	}
+
+	_, err := service.CreateTransaction(ctx, 1, 7, saleRequest())
+	if err == nil || err.Error() != "monthly transaction limit reached" {
+		t.Fatalf("sale beyond the limit: error = %v, want monthly transaction limit reached", err)
+	}
+	if len(repo.created) != 2 {
+		t.Errorf("stored transactions = %d, want 2", len(repo.created))
+	}
+	if meter.recorded != 2 {
+		t.Errorf("metered transactions = %d, want 2", meter.recorded)
+	}
+}
+
+func TestCreateTransactionMetersOnlyStoredSales(t *testing.T) {
+	repo := &fakeTransactionRepository{}
+	meter := &fakeUsageMeter{limit: 10}
+	service := NewTransactionService(repo, prices, &fakeStockRecorder{err: errors.New("insufficient stock")}, meter, nil)
+
+	_, err := service.CreateTransaction(context.Background(), 1, 7, saleRequest())
+	if err == nil || err.Error() != "insufficient stock" {
+		t.Fatalf("error = %v, want insufficient stock", err)
+	}
+	if meter.recorded != 0 {
+		t.Errorf("metered transactions = %d, want 0 for a sale that was not stored", meter.recorded)
+	}
+}
+
+func TestCreateTransactionChargesResolvedPrices(t *testing.T) {
+	repo := &fakeTransactionRepository{}
+	service := NewTransactionService(repo, prices, &fakeStockRecorder{}, &fakeUsageMeter{limit: 10}, nil)
+	ctx := context.Background()
+
+	current := 25000.0
+	stale := 23000.0
+	tests := []struct {
+		name      string
+		unitPrice *float64
+		discount  float64
+		paid      float64
+		wantErr   string
+		wantTotal float64
+	}{
+		{"without a price", nil, 0, 50000, "", 50000},
+		{"with the current price", &current, 0, 50000, "", 50000},
+		{"discount off the resolved price", nil, 5000, 45000, "", 45000},
+		{"with a stale price", &stale, 0, 46000, "unit price does not match the current price", 0},
+		{"paid at a stale price", nil, 0, 46000, "payment is less than the total", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := saleRequest()
+			req.Items[0].UnitPrice = tt.unitPrice
+			req.Items[0].DiscountAmount = tt.discount
+			req.Payments[0].Amount = tt.paid
+
+			transaction, err := service.CreateTransaction(ctx, 1, 7, req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if item := transaction.Items[0]; item.UnitPrice != 25000 || item.TotalPrice != tt.wantTotal {
+				t.Errorf("item = %v at %v, want %v at 25000", item.TotalPrice, item.UnitPrice, tt.wantTotal)
+			}
+			if transaction.TotalAmount != tt.wantTotal {
+				t.Errorf("total = %v, want %v", transaction.TotalAmount, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+type UsageResponse struct {
+	Period       string `json:"period"`
+	PeriodStart  string `json:"period_start"`
+	Transactions int64  `json:"transactions"`
+	APICalls     int64  `json:"api_calls"`
+	ActiveUsers  int    `json:"active_users"`
+	Outlets      int    `json:"outlets"`
+	Products     int    `json:"products"`
+	StorageBytes int64  `json:"storage_bytes"`
+}
+
+type PlanLimitsResponse struct {
+	PlanName                string `json:"plan_name"`
+	MaxOutlets              *int   `json:"max_outlets"`
+	MaxUsers                *int   `json:"max_users"`
+	MaxProducts             *int   `json:"max_products"`
+	MaxTransactionsPerMonth *int   `json:"max_transactions_per_month"`
+}
+
+type TenantUsageResponse struct {
+	TenantID              uint64             `json:"tenant_id"`
+	TenantName            string             `json:"tenant_name"`
+	Timezone              string             `json:"timezone"`
+	Month                 string             `json:"month"`
+	Usage                 UsageResponse      `json:"usage"`
+	Limits                PlanLimitsResponse `json:"limits"`
+	TransactionsRemaining *int64             `json:"transactions_remaining"`
+}
+
+type TenantUsageDetailResponse struct {
+	TenantUsageResponse
+	Days []UsageResponse `json:"days"`
+}
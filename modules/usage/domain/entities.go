@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RoleTenantOwner = "tenant_owner"
+	RoleSuperAdmin  = "super_admin"
+)
+
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Counter metrics are incremented in Redis as they happen. Outlets, products
+// and storage are measured when the counters are rolled up.
+const (
+	MetricTransactions = "transactions"
+	MetricAPICalls     = "api_calls"
+)
+
+const (
+	DayLayout   = "2006-01-02"
+	MonthLayout = "2006-01"
+)
+
+// ErrTransactionLimitReached is returned when a tenant has used the monthly
+// transactions of its subscription plan.
+var ErrTransactionLimitReached = errors.New("monthly transaction limit reached")
+
+// Usage is the metered usage of a tenant for one day or month.
+type Usage struct {
+	TenantID     uint64
+	Period       string
+	PeriodStart  time.Time
+	Transactions int64
+	APICalls     int64
+	ActiveUsers  int
+	Outlets      int
+	Products     int
+	StorageBytes int64
+	UpdatedAt    time.Time
+}
+
+// Counters are the Redis counters of a tenant for one day or month.
+type Counters struct {
+	Transactions int64
+	APICalls     int64
+	ActiveUsers  int
+}
+
+// PendingPeriod is a tenant day with counters that have not been rolled up
+// since the day ended.
+type PendingPeriod struct {
+	TenantID uint64
+	Day      string
+}
+
+// Resources are the tenant's current outlets and products.
+type Resources struct {
+	Outlets  int
+	Products int
+}
+
+// PlanLimits are the limits of the tenant's active subscription plan. A nil
+// limit is unlimited.
+type PlanLimits struct {
+	PlanName                string
+	MaxOutlets              *int
+	MaxUsers                *int
+	MaxProducts             *int
+	MaxTransactionsPerMonth *int
+}
+
+type TenantClock struct {
+	TenantID uint64
+	Name     string
+	Timezone string
+}
+
+// Location returns the tenant's timezone, falling back to UTC when it is not
+// a valid IANA name.
+func (c *TenantClock) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// TenantUsageSummary is the usage of a tenant for one month, with the limits
+// of its plan.
+type TenantUsageSummary struct {
+	TenantID   uint64
+	TenantName string
+	Timezone   string
+	Month      time.Time
+	Usage      Usage
+	Limits     PlanLimits
+}
+
+type UsageUser struct {
+	ID       uint64
+	TenantID uint64
+	RoleName string
+}
+
+func (u *UsageUser) IsOwner() bool {
+	return u.RoleName == RoleTenantOwner || u.RoleName == RoleSuperAdmin
+}
+
+func (u *UsageUser) IsSuperAdmin() bool {
+	return u.RoleName == RoleSuperAdmin
+}
+
+// MonthStart returns the first day of the month of t.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CounterStore keeps the live usage counters in Redis. Day and month are in
+// the tenant's timezone.
+type CounterStore interface {
+	Increment(ctx context.Context, tenantID uint64, metric string, day time.Time, delta int64) error
+	AddActiveUser(ctx context.Context, tenantID uint64, day time.Time, userID uint64) error
+	GetCounters(ctx context.Context, tenantID uint64, period string, periodStart time.Time) (*Counters, error)
+	PendingPeriods(ctx context.Context) ([]PendingPeriod, error)
+	RemovePending(ctx context.Context, period PendingPeriod) error
+}
+
+type UsageRepository interface {
+	FindTenantClock(ctx context.Context, tenantID uint64) (*TenantClock, error)
+	FindUser(ctx context.Context, userID uint64) (*UsageUser, error)
+	CountResources(ctx context.Context, tenantID uint64) (*Resources, error)
+	FindPlanLimits(ctx context.Context, tenantID uint64) (*PlanLimits, error)
+	// Save stores a rolled up period. Counters never decrease, so a lost
+	// Redis counter does not erase usage that was already rolled up.
+	Save(ctx context.Context, usage *Usage) error
+	FindUsage(ctx context.Context, tenantID uint64, period string, periodStart time.Time) (*Usage, error)
+	FindDailyUsage(ctx context.Context, tenantID uint64, from, to time.Time) ([]*Usage, error)
+	FindTenantsUsage(ctx context.Context, month time.Time, limit, offset int) ([]*TenantUsageSummary, int64, error)
+}
+
+// UsageMeter records usage as it happens. Other modules use it to meter
+// their own events and to enforce plan limits.
+type UsageMeter interface {
+	RecordAPICall(ctx context.Context, tenantID, userID uint64)
+	RecordTransaction(ctx context.Context, tenantID uint64) error
+	// CheckTransactionLimit returns ErrTransactionLimitReached when the
+	// tenant has no transactions left this month. Checkout calls it before
+	// a sale is saved and RecordTransaction after.
+	CheckTransactionLimit(ctx context.Context, tenantID uint64) error
+}
+
+type UsageService interface {
+	GetCurrentUsage(ctx context.Context, tenantID, userID uint64, month string) (*TenantUsageSummary, []*Usage, error)
+	GetTenantUsage(ctx context.Context, userID, tenantID uint64, month string) (*TenantUsageSummary, []*Usage, error)
+	GetTenantsUsage(ctx context.Context, userID uint64, month string, limit, offset int) ([]*TenantUsageSummary, time.Time, int64, error)
+	// Rollup writes the Redis counters of every pending tenant day, and its
+	// month, to Postgres.
+	Rollup(ctx context.Context) error
+	RollupTenant(ctx context.Context, tenantID uint64) error
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+	"github.com/exven/pos-system/shared/utils/response"
+	"github.com/labstack/echo/v4"
+)
+
+type UsageHandler struct {
+	usageService domain.UsageService
+}
+
+func NewUsageHandler(usageService domain.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+func (h *UsageHandler) RegisterRoutes(e *echo.Group) {
+	usage := e.Group("/usage")
+
+	// Tenant owner routes
+	usage.GET("/current", h.GetCurrentUsage)
+
+	// Super admin routes
+	usage.GET("/tenants", h.GetTenantsUsage)
+	usage.GET("/tenants/:id", h.GetTenantUsage)
+}
+
+func (h *UsageHandler) GetCurrentUsage(c echo.Context) error {
+	tenantID := c.Get("tenant_id").(uint64)
+	userID := c.Get("user_id").(uint64)
+
+	summary, days, err := h.usageService.GetCurrentUsage(c.Request().Context(), tenantID, userID, c.QueryParam("month"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Usage retrieved successfully", h.detailToResponse(summary, days))
+}
+
+func (h *UsageHandler) GetTenantsUsage(c echo.Context) error {
+	userID := c.Get("user_id").(uint64)
+
+	page := 1
+	limit := 20
+
+	if p := c.QueryParam("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	summaries, _, total, err := h.usageService.GetTenantsUsage(c.Request().Context(), userID, c.QueryParam("month"), limit, (page-1)*limit)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	usageResponses := make([]domain.TenantUsageResponse, len(summaries))
+	for i, summary := range summaries {
+		usageResponses[i] = h.summaryToResponse(summary)
+	}
+
+	return response.SuccessWithPagination(c, "Usage retrieved successfully", usageResponses, page, limit, int(total))
+}
+
+func (h *UsageHandler) GetTenantUsage(c echo.Context) error {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, "Invalid tenant ID")
+	}
+
+	userID := c.Get("user_id").(uint64)
+
+	summary, days, err := h.usageService.GetTenantUsage(c.Request().Context(), userID, tenantID, c.QueryParam("month"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, "Usage retrieved successfully", h.detailToResponse(summary, days))
+}
+
+// Helper functions
+
+func (h *UsageHandler) handleError(c echo.Context, err error) error {
+	switch err.Error() {
+	case "tenant not found":
+		return response.NotFound(c, "Tenant not found")
+	case "invalid month":
+		return response.ValidationError(c, map[string][]string{
+			"month": {"Month must be in YYYY-MM format"},
+		})
+	case "only the tenant owner can view usage", "only super admins can view usage of other tenants", "user not found":
+		return response.Error(c, http.StatusForbidden, "You are not allowed to view this usage", nil)
+	}
+	return response.InternalError(c, "Failed to get usage")
+}
+
+func (h *UsageHandler) detailToResponse(summary *domain.TenantUsageSummary, days []*domain.Usage) domain.TenantUsageDetailResponse {
+	dayResponses := make([]domain.UsageResponse, len(days))
+	for i, day := range days {
+		dayResponses[i] = h.usageToResponse(day)
+	}
+
+	return domain.TenantUsageDetailResponse{
+		TenantUsageResponse: h.summaryToResponse(summary),
+		Days:                dayResponses,
+	}
+}
+
+func (h *UsageHandler) summaryToResponse(summary *domain.TenantUsageSummary) domain.TenantUsageResponse {
+	usageResponse := domain.TenantUsageResponse{
+		TenantID:   summary.TenantID,
+		TenantName: summary.TenantName,
+		Timezone:   summary.Timezone,
+		Month:      summary.Month.Format(domain.MonthLayout),
+		Usage:      h.usageToResponse(&summary.Usage),
+		Limits: domain.PlanLimitsResponse{
+			PlanName:                summary.Limits.PlanName,
+			MaxOutlets:              summary.Limits.MaxOutlets,
+			MaxUsers:                summary.Limits.MaxUsers,
+			MaxProducts:             summary.Limits.MaxProducts,
+			MaxTransactionsPerMonth: summary.Limits.MaxTransactionsPerMonth,
+		},
+	}
+
+	if limit := summary.Limits.MaxTransactionsPerMonth; limit != nil {
+		remaining := int64(*limit) - summary.Usage.Transactions
+		if remaining < 0 {
+			remaining = 0
+		}
+		usageResponse.TransactionsRemaining = &remaining
+	}
+
+	return usageResponse
+}
+
+func (h *UsageHandler) usageToResponse(usage *domain.Usage) domain.UsageResponse {
+	return domain.UsageResponse{
+		Period:       usage.Period,
+		PeriodStart:  usage.PeriodStart.Format(domain.DayLayout),
+		Transactions: usage.Transactions,
+		APICalls:     usage.APICalls,
+		ActiveUsers:  usage.ActiveUsers,
+		Outlets:      usage.Outlets,
+		Products:     usage.Products,
+		StorageBytes: usage.StorageBytes,
+	}
+}
@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/exven/pos-system/internal/config"
+	"github.com/exven/pos-system/modules/usage/domain"
+	"github.com/exven/pos-system/modules/usage/handlers"
+	"github.com/exven/pos-system/modules/usage/persistence"
+	"github.com/exven/pos-system/modules/usage/services"
+	"github.com/exven/pos-system/shared/container"
+	"github.com/exven/pos-system/shared/infrastructure/cache"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	container     container.Container
+	db            *gorm.DB
+	redisClient   *cache.RedisClient
+	storageConfig config.StorageConfig
+	meter         domain.UsageMeter
+}
+
+func NewModule(
+	container container.Container,
+	db *gorm.DB,
+	redisClient *cache.RedisClient,
+	storageConfig config.StorageConfig,
+) *Module {
+	return &Module{
+		container:     container,
+		db:            db,
+		redisClient:   redisClient,
+		storageConfig: storageConfig,
+	}
+}
+
+func (m *Module) Register() {
+	// Register repositories
+	m.container.RegisterSingleton("usage.usageRepository", func() interface{} {
+		return persistence.NewUsageRepository(m.db)
+	})
+
+	m.container.RegisterSingleton("usage.counterStore", func() interface{} {
+		return persistence.NewCounterStore(m.redisClient)
+	})
+
+	// Register services
+	m.container.RegisterSingleton("usage.meter", func() interface{} {
+		return m.GetMeter()
+	})
+
+	m.container.RegisterSingleton("usage.usageService", func() interface{} {
+		return m.newService()
+	})
+
+	// Register handlers
+	m.container.RegisterSingleton("usage.handler", func() interface{} {
+		return m.GetHandler()
+	})
+}
+
+func (m *Module) GetHandler() *handlers.UsageHandler {
+	return handlers.NewUsageHandler(m.newService())
+}
+
+// GetMeter returns the module's meter. It is shared so the tenant timezone
+// cache is kept across callers.
+func (m *Module) GetMeter() domain.UsageMeter {
+	if m.meter == nil {
+		repo := persistence.NewUsageRepository(m.db)
+		store := persistence.NewCounterStore(m.redisClient)
+		m.meter = services.NewUsageMeter(store, repo)
+	}
+	return m.meter
+}
+
+// StartRollup rolls the Redis counters up to Postgres every interval until
+// ctx is cancelled.
+func (m *Module) StartRollup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	service := m.newService()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := service.Rollup(ctx); err != nil {
+				log.Printf("usage rollup: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Module) newService() domain.UsageService {
+	repo := persistence.NewUsageRepository(m.db)
+	store := persistence.NewCounterStore(m.redisClient)
+	return services.NewUsageService(repo, store, m.storageConfig.LocalPath)
+}
@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+	"github.com/exven/pos-system/shared/infrastructure/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	pendingKey = "usage:pending"
+	// Day counters must outlive the rollup of the day after it ends, month
+	// counters the whole month for limit checks.
+	dayCounterTTL   = 8 * 24 * time.Hour
+	monthCounterTTL = 62 * 24 * time.Hour
+)
+
+type counterStore struct {
+	client *redis.Client
+}
+
+func NewCounterStore(redisClient *cache.RedisClient) domain.CounterStore {
+	return &counterStore{client: redisClient.GetClient()}
+}
+
+func (s *counterStore) Increment(ctx context.Context, tenantID uint64, metric string, day time.Time, delta int64) error {
+	dayKey := counterKey(tenantID, domain.PeriodDay, day)
+	monthKey := counterKey(tenantID, domain.PeriodMonth, day)
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, dayKey, metric, delta)
+		pipe.Expire(ctx, dayKey, dayCounterTTL)
+		pipe.HIncrBy(ctx, monthKey, metric, delta)
+		pipe.Expire(ctx, monthKey, monthCounterTTL)
+		pipe.SAdd(ctx, pendingKey, pendingMember(tenantID, day))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to increment usage counter: %w", err)
+	}
+
+	return nil
+}
+
+func (s *counterStore) AddActiveUser(ctx context.Context, tenantID uint64, day time.Time, userID uint64) error {
+	dayKey := counterKey(tenantID, domain.PeriodDay, day) + ":users"
+	monthKey := counterKey(tenantID, domain.PeriodMonth, day) + ":users"
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, dayKey, userID)
+		pipe.Expire(ctx, dayKey, dayCounterTTL)
+		pipe.SAdd(ctx, monthKey, userID)
+		pipe.Expire(ctx, monthKey, monthCounterTTL)
+		pipe.SAdd(ctx, pendingKey, pendingMember(tenantID, day))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record active user: %w", err)
+	}
+
+	return nil
+}
+
+func (s *counterStore) GetCounters(ctx context.Context, tenantID uint64, period string, periodStart time.Time) (*domain.Counters, error) {
+	key := counterKey(tenantID, period, periodStart)
+
+	var values *redis.MapStringStringCmd
+	var users *redis.IntCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, key)
+		users = pipe.SCard(ctx, key+":users")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage counters: %w", err)
+	}
+
+	counters := &domain.Counters{ActiveUsers: int(users.Val())}
+	counters.Transactions, _ = strconv.ParseInt(values.Val()[domain.MetricTransactions], 10, 64)
+	counters.APICalls, _ = strconv.ParseInt(values.Val()[domain.MetricAPICalls], 10, 64)
+
+	return counters, nil
+}
+
+func (s *counterStore) PendingPeriods(ctx context.Context) ([]domain.PendingPeriod, error) {
+	members, err := s.client.SMembers(ctx, pendingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending usage: %w", err)
+	}
+
+	periods := make([]domain.PendingPeriod, 0, len(members))
+	for _, member := range members {
+		tenant, day, ok := strings.Cut(member, ":")
+		tenantID, err := strconv.ParseUint(tenant, 10, 64)
+		if !ok || err != nil {
+			s.client.SRem(ctx, pendingKey, member)
+			continue
+		}
+		periods = append(periods, domain.PendingPeriod{TenantID: tenantID, Day: day})
+	}
+
+	return periods, nil
+}
+
+func (s *counterStore) RemovePending(ctx context.Context, period domain.PendingPeriod) error {
+	member := fmt.Sprintf("%d:%s", period.TenantID, period.Day)
+	if err := s.client.SRem(ctx, pendingKey, member).Err(); err != nil {
+		return fmt.Errorf("failed to remove pending usage: %w", err)
+	}
+	return nil
+}
+
+func counterKey(tenantID uint64, period string, day time.Time) string {
+	if period == domain.PeriodMonth {
+		return fmt.Sprintf("usage:%d:month:%s", tenantID, day.Format(domain.MonthLayout))
+	}
+	return fmt.Sprintf("usage:%d:day:%s", tenantID, day.Format(domain.DayLayout))
+}
+
+func pendingMember(tenantID uint64, day time.Time) string {
+	return fmt.Sprintf("%d:%s", tenantID, day.Format(domain.DayLayout))
+}
@@ -0,0 +1,126 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+)
+
+type TenantUsageModel struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64    `gorm:"column:tenant_id"`
+	Period       string    `gorm:"column:period"`
+	PeriodStart  time.Time `gorm:"column:period_start;type:date"`
+	Transactions int64     `gorm:"column:transactions"`
+	APICalls     int64     `gorm:"column:api_calls"`
+	ActiveUsers  int       `gorm:"column:active_users"`
+	Outlets      int       `gorm:"column:outlets"`
+	Products     int       `gorm:"column:products"`
+	StorageBytes int64     `gorm:"column:storage_bytes"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (TenantUsageModel) TableName() string {
+	return "tenant_usages"
+}
+
+func (m *TenantUsageModel) ToDomainUsage() *domain.Usage {
+	return &domain.Usage{
+		TenantID:     m.TenantID,
+		Period:       m.Period,
+		PeriodStart:  dateOnly(m.PeriodStart),
+		Transactions: m.Transactions,
+		APICalls:     m.APICalls,
+		ActiveUsers:  m.ActiveUsers,
+		Outlets:      m.Outlets,
+		Products:     m.Products,
+		StorageBytes: m.StorageBytes,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func (m *TenantUsageModel) FromDomainUsage(usage *domain.Usage) {
+	m.TenantID = usage.TenantID
+	m.Period = usage.Period
+	m.PeriodStart = usage.PeriodStart
+	m.Transactions = usage.Transactions
+	m.APICalls = usage.APICalls
+	m.ActiveUsers = usage.ActiveUsers
+	m.Outlets = usage.Outlets
+	m.Products = usage.Products
+	m.StorageBytes = usage.StorageBytes
+}
+
+type TenantClockModel struct {
+	ID       uint64 `gorm:"column:id"`
+	Name     string `gorm:"column:name"`
+	Timezone string `gorm:"column:timezone"`
+}
+
+type UsageUserModel struct {
+	ID       uint64 `gorm:"column:id"`
+	TenantID uint64 `gorm:"column:tenant_id"`
+	RoleName string `gorm:"column:role_name"`
+}
+
+type PlanLimitsModel struct {
+	PlanName                *string `gorm:"column:plan_name"`
+	MaxOutlets              *int    `gorm:"column:max_outlets"`
+	MaxUsers                *int    `gorm:"column:max_users"`
+	MaxProducts             *int    `gorm:"column:max_products"`
+	MaxTransactionsPerMonth *int    `gorm:"column:max_transactions_per_month"`
+}
+
+func (m *PlanLimitsModel) ToDomainPlanLimits() domain.PlanLimits {
+	limits := domain.PlanLimits{
+		MaxOutlets:              m.MaxOutlets,
+		MaxUsers:                m.MaxUsers,
+		MaxProducts:             m.MaxProducts,
+		MaxTransactionsPerMonth: m.MaxTransactionsPerMonth,
+	}
+	if m.PlanName != nil {
+		limits.PlanName = *m.PlanName
+	}
+	return limits
+}
+
+// TenantUsageRowModel is a row of the usage report across tenants
+type TenantUsageRowModel struct {
+	TenantID     uint64 `gorm:"column:tenant_id"`
+	TenantName   string `gorm:"column:tenant_name"`
+	Timezone     string `gorm:"column:timezone"`
+	Transactions int64  `gorm:"column:transactions"`
+	APICalls     int64  `gorm:"column:api_calls"`
+	ActiveUsers  int    `gorm:"column:active_users"`
+	Outlets      int    `gorm:"column:outlets"`
+	Products     int    `gorm:"column:products"`
+	StorageBytes int64  `gorm:"column:storage_bytes"`
+	PlanLimitsModel
+}
+
+func (m *TenantUsageRowModel) ToDomainSummary(month time.Time) *domain.TenantUsageSummary {
+	return &domain.TenantUsageSummary{
+		TenantID:   m.TenantID,
+		TenantName: m.TenantName,
+		Timezone:   m.Timezone,
+		Month:      month,
+		Usage: domain.Usage{
+			TenantID:     m.TenantID,
+			Period:       domain.PeriodMonth,
+			PeriodStart:  month,
+			Transactions: m.Transactions,
+			APICalls:     m.APICalls,
+			ActiveUsers:  m.ActiveUsers,
+			Outlets:      m.Outlets,
+			Products:     m.Products,
+			StorageBytes: m.StorageBytes,
+		},
+		Limits: m.PlanLimitsModel.ToDomainPlanLimits(),
+	}
+}
+
+// dateOnly drops the location the driver attaches to DATE columns
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,209 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activePlanJoin picks the plan of the tenant's active subscription that ends
+// last. It expects the tenants table aliased as t.
+const activePlanJoin = `LEFT JOIN LATERAL (
+	SELECT sp.name AS plan_name, sp.max_outlets, sp.max_users, sp.max_products, sp.max_transactions_per_month
+	FROM tenant_subscriptions ts
+	JOIN subscription_plans sp ON sp.id = ts.subscription_plan_id
+	WHERE ts.tenant_id = t.id AND ts.status = 'active' AND ts.ends_at > NOW()
+	ORDER BY ts.ends_at DESC
+	LIMIT 1
+) plan ON TRUE`
+
+type usageRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) domain.UsageRepository {
+	return &usageRepository{db: db}
+}
+
+func (r *usageRepository) FindTenantClock(ctx context.Context, tenantID uint64) (*domain.TenantClock, error) {
+	var model TenantClockModel
+
+	err := r.db.WithContext(ctx).
+		Table("tenants").
+		Select("id, name, timezone").
+		Where("id = ?", tenantID).
+		Take(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to find tenant: %w", err)
+	}
+
+	return &domain.TenantClock{
+		TenantID: model.ID,
+		Name:     model.Name,
+		Timezone: model.Timezone,
+	}, nil
+}
+
+func (r *usageRepository) FindUser(ctx context.Context, userID uint64) (*domain.UsageUser, error) {
+	var model UsageUserModel
+
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.id, users.tenant_id, roles.name AS role_name").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.id = ?", userID).
+		Take(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return &domain.UsageUser{
+		ID:       model.ID,
+		TenantID: model.TenantID,
+		RoleName: model.RoleName,
+	}, nil
+}
+
+func (r *usageRepository) CountResources(ctx context.Context, tenantID uint64) (*domain.Resources, error) {
+	var resources struct {
+		Outlets  int
+		Products int
+	}
+
+	err := r.db.WithContext(ctx).Raw(`SELECT
+		(SELECT COUNT(*) FROM outlets WHERE tenant_id = ?) AS outlets,
+		(SELECT COUNT(*) FROM products WHERE tenant_id = ?) AS products`,
+		tenantID, tenantID).
+		Scan(&resources).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant resources: %w", err)
+	}
+
+	return &domain.Resources{
+		Outlets:  resources.Outlets,
+		Products: resources.Products,
+	}, nil
+}
+
+func (r *usageRepository) FindPlanLimits(ctx context.Context, tenantID uint64) (*domain.PlanLimits, error) {
+	var models []PlanLimitsModel
+
+	err := r.db.WithContext(ctx).
+		Raw("SELECT plan.* FROM tenants t "+activePlanJoin+" WHERE t.id = ?", tenantID).
+		Scan(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plan limits: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, errors.New("tenant not found")
+	}
+
+	limits := models[0].ToDomainPlanLimits()
+	return &limits, nil
+}
+
+func (r *usageRepository) Save(ctx context.Context, usage *domain.Usage) error {
+	model := &TenantUsageModel{}
+	model.FromDomainUsage(usage)
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "period"}, {Name: "period_start"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"transactions":  gorm.Expr("GREATEST(tenant_usages.transactions, EXCLUDED.transactions)"),
+				"api_calls":     gorm.Expr("GREATEST(tenant_usages.api_calls, EXCLUDED.api_calls)"),
+				"active_users":  gorm.Expr("GREATEST(tenant_usages.active_users, EXCLUDED.active_users)"),
+				"outlets":       gorm.Expr("EXCLUDED.outlets"),
+				"products":      gorm.Expr("EXCLUDED.products"),
+				"storage_bytes": gorm.Expr("EXCLUDED.storage_bytes"),
+				"updated_at":    gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).
+		Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to save usage: %w", err)
+	}
+
+	return nil
+}
+
+func (r *usageRepository) FindUsage(ctx context.Context, tenantID uint64, period string, periodStart time.Time) (*domain.Usage, error) {
+	var model TenantUsageModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND period = ? AND period_start = ?", tenantID, period, periodStart.Format(domain.DayLayout)).
+		First(&model).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usage not found")
+		}
+		return nil, fmt.Errorf("failed to find usage: %w", err)
+	}
+
+	return model.ToDomainUsage(), nil
+}
+
+func (r *usageRepository) FindDailyUsage(ctx context.Context, tenantID uint64, from, to time.Time) ([]*domain.Usage, error) {
+	var models []TenantUsageModel
+
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND period = ? AND period_start BETWEEN ? AND ?",
+			tenantID, domain.PeriodDay, from.Format(domain.DayLayout), to.Format(domain.DayLayout)).
+		Order("period_start ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find daily usage: %w", err)
+	}
+
+	usages := make([]*domain.Usage, len(models))
+	for i, model := range models {
+		usages[i] = model.ToDomainUsage()
+	}
+
+	return usages, nil
+}
+
+func (r *usageRepository) FindTenantsUsage(ctx context.Context, month time.Time, limit, offset int) ([]*domain.TenantUsageSummary, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Table("tenants").Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	var models []TenantUsageRowModel
+	err := r.db.WithContext(ctx).Raw(`SELECT t.id AS tenant_id, t.name AS tenant_name, t.timezone,
+		COALESCE(u.transactions, 0) AS transactions, COALESCE(u.api_calls, 0) AS api_calls,
+		COALESCE(u.active_users, 0) AS active_users, COALESCE(u.outlets, 0) AS outlets,
+		COALESCE(u.products, 0) AS products, COALESCE(u.storage_bytes, 0) AS storage_bytes,
+		plan.plan_name, plan.max_outlets, plan.max_users, plan.max_products, plan.max_transactions_per_month
+		FROM tenants t
+		LEFT JOIN tenant_usages u ON u.tenant_id = t.id AND u.period = ? AND u.period_start = ?
+		`+activePlanJoin+`
+		ORDER BY COALESCE(u.transactions, 0) DESC, t.id ASC
+		LIMIT ? OFFSET ?`,
+		domain.PeriodMonth, month.Format(domain.DayLayout), limit, offset).
+		Scan(&models).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find tenants usage: %w", err)
+	}
+
+	summaries := make([]*domain.TenantUsageSummary, len(models))
+	for i := range models {
+		summaries[i] = models[i].ToDomainSummary(month)
+	}
+
+	return summaries, total, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+)
+
+// clockCacheTTL bounds how long a tenant's timezone is cached, so a timezone
+// change reaches the meter without a restart.
+const clockCacheTTL = 10 * time.Minute
+
+type cachedLocation struct {
+	location  *time.Location
+	expiresAt time.Time
+}
+
+type usageMeter struct {
+	store     domain.CounterStore
+	usageRepo domain.UsageRepository
+	locations sync.Map
+}
+
+func NewUsageMeter(store domain.CounterStore, usageRepo domain.UsageRepository) domain.UsageMeter {
+	return &usageMeter{
+		store:     store,
+		usageRepo: usageRepo,
+	}
+}
+
+func (m *usageMeter) RecordAPICall(ctx context.Context, tenantID, userID uint64) {
+	today, err := m.today(ctx, tenantID)
+	if err != nil {
+		log.Printf("usage: tenant %d: %v", tenantID, err)
+		return
+	}
+
+	if err := m.store.Increment(ctx, tenantID, domain.MetricAPICalls, today, 1); err != nil {
+		log.Printf("usage: tenant %d: %v", tenantID, err)
+	}
+
+	if userID != 0 {
+		if err := m.store.AddActiveUser(ctx, tenantID, today, userID); err != nil {
+			log.Printf("usage: tenant %d: %v", tenantID, err)
+		}
+	}
+}
+
+func (m *usageMeter) RecordTransaction(ctx context.Context, tenantID uint64) error {
+	today, err := m.today(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	return m.store.Increment(ctx, tenantID, domain.MetricTransactions, today, 1)
+}
+
+func (m *usageMeter) CheckTransactionLimit(ctx context.Context, tenantID uint64) error {
+	limits, err := m.usageRepo.FindPlanLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if limits.MaxTransactionsPerMonth == nil {
+		return nil
+	}
+
+	today, err := m.today(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	used, err := monthTransactions(ctx, m.store, m.usageRepo, tenantID, domain.MonthStart(today))
+	if err != nil {
+		return err
+	}
+
+	if used >= int64(*limits.MaxTransactionsPerMonth) {
+		return domain.ErrTransactionLimitReached
+	}
+
+	return nil
+}
+
+// today returns the current date in the tenant's timezone
+func (m *usageMeter) today(ctx context.Context, tenantID uint64) (time.Time, error) {
+	now := time.Now()
+
+	if cached, ok := m.locations.Load(tenantID); ok {
+		entry := cached.(cachedLocation)
+		if now.Before(entry.expiresAt) {
+			return localDate(now, entry.location), nil
+		}
+	}
+
+	clock, err := m.usageRepo.FindTenantClock(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	location := clock.Location()
+	m.locations.Store(tenantID, cachedLocation{location: location, expiresAt: now.Add(clockCacheTTL)})
+
+	return localDate(now, location), nil
+}
+
+// monthTransactions returns the transactions of a month, preferring whichever
+// of Redis and Postgres has seen more in case the Redis counter was lost.
+func monthTransactions(ctx context.Context, store domain.CounterStore, usageRepo domain.UsageRepository, tenantID uint64, month time.Time) (int64, error) {
+	var used int64
+
+	counters, err := store.GetCounters(ctx, tenantID, domain.PeriodMonth, month)
+	if err != nil {
+		log.Printf("usage: tenant %d: %v", tenantID, err)
+	} else {
+		used = counters.Transactions
+	}
+
+	stored, err := usageRepo.FindUsage(ctx, tenantID, domain.PeriodMonth, month)
+	if err != nil && err.Error() != "usage not found" {
+		return 0, err
+	}
+	if stored != nil && stored.Transactions > used {
+		used = stored.Transactions
+	}
+
+	return used, nil
+}
+
+// localDate returns the calendar date of t in location, as midnight UTC
+func localDate(t time.Time, location *time.Location) time.Time {
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var errInvalidMonth = errors.New("invalid month")
+
+// parseMonth parses a YYYY-MM month, defaulting to the month of today
+func parseMonth(month string, today time.Time) (time.Time, error) {
+	if month == "" {
+		return domain.MonthStart(today), nil
+	}
+
+	parsed, err := time.Parse(domain.MonthLayout, month)
+	if err != nil {
+		return time.Time{}, errInvalidMonth
+	}
+
+	return parsed, nil
+}
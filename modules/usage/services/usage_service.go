@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/exven/pos-system/modules/usage/domain"
+)
+
+type usageService struct {
+	usageRepo   domain.UsageRepository
+	store       domain.CounterStore
+	storagePath string
+}
+
+func NewUsageService(usageRepo domain.UsageRepository, store domain.CounterStore, storagePath string) domain.UsageService {
+	return &usageService{
+		usageRepo:   usageRepo,
+		store:       store,
+		storagePath: storagePath,
+	}
+}
+
+func (s *usageService) GetCurrentUsage(ctx context.Context, tenantID, userID uint64, month string) (*domain.TenantUsageSummary, []*domain.Usage, error) {
+	user, err := s.usageRepo.FindUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.TenantID != tenantID || !user.IsOwner() {
+		return nil, nil, errors.New("only the tenant owner can view usage")
+	}
+
+	return s.tenantUsage(ctx, tenantID, month)
+}
+
+func (s *usageService) GetTenantUsage(ctx context.Context, userID, tenantID uint64, month string) (*domain.TenantUsageSummary, []*domain.Usage, error) {
+	if err := s.requireSuperAdmin(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+
+	return s.tenantUsage(ctx, tenantID, month)
+}
+
+func (s *usageService) GetTenantsUsage(ctx context.Context, userID uint64, month string, limit, offset int) ([]*domain.TenantUsageSummary, time.Time, int64, error) {
+	if err := s.requireSuperAdmin(ctx, userID); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	// Tenants are in different timezones, so the current month is taken
+	// in UTC
+	monthStart, err := parseMonth(month, localDate(time.Now(), time.UTC))
+	if err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	summaries, total, err := s.usageRepo.FindTenantsUsage(ctx, monthStart, limit, offset)
+	if err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	return summaries, monthStart, total, nil
+}
+
+func (s *usageService) Rollup(ctx context.Context) error {
+	pending, err := s.store.PendingPeriods(ctx)
+	if err != nil {
+		return err
+	}
+
+	byTenant := map[uint64][]domain.PendingPeriod{}
+	for _, period := range pending {
+		byTenant[period.TenantID] = append(byTenant[period.TenantID], period)
+	}
+
+	var errs []error
+	for tenantID, periods := range byTenant {
+		if err := s.rollupPeriods(ctx, tenantID, periods); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %d: %w", tenantID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *usageService) RollupTenant(ctx context.Context, tenantID uint64) error {
+	clock, err := s.usageRepo.FindTenantClock(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	today := localDate(time.Now(), clock.Location())
+	return s.rollupPeriods(ctx, tenantID, []domain.PendingPeriod{
+		{TenantID: tenantID, Day: today.Format(domain.DayLayout)},
+	})
+}
+
+func (s *usageService) rollupPeriods(ctx context.Context, tenantID uint64, periods []domain.PendingPeriod) error {
+	clock, err := s.usageRepo.FindTenantClock(ctx, tenantID)
+	if err != nil {
+		if err.Error() == "tenant not found" {
+			// The tenant was deleted; its counters expire on their own
+			for _, period := range periods {
+				s.store.RemovePending(ctx, period)
+			}
+			return nil
+		}
+		return err
+	}
+
+	resources, err := s.usageRepo.CountResources(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	storageBytes, err := s.storageBytes(tenantID)
+	if err != nil {
+		return err
+	}
+
+	today := localDate(time.Now(), clock.Location())
+
+	for _, period := range periods {
+		day, err := time.Parse(domain.DayLayout, period.Day)
+		if err != nil {
+			s.store.RemovePending(ctx, period)
+			continue
+		}
+
+		for _, usagePeriod := range []string{domain.PeriodDay, domain.PeriodMonth} {
+			periodStart := day
+			if usagePeriod == domain.PeriodMonth {
+				periodStart = domain.MonthStart(day)
+			}
+
+			counters, err := s.store.GetCounters(ctx, tenantID, usagePeriod, periodStart)
+			if err != nil {
+				return err
+			}
+
+			err = s.usageRepo.Save(ctx, &domain.Usage{
+				TenantID:     tenantID,
+				Period:       usagePeriod,
+				PeriodStart:  periodStart,
+				Transactions: counters.Transactions,
+				APICalls:     counters.APICalls,
+				ActiveUsers:  counters.ActiveUsers,
+				Outlets:      resources.Outlets,
+				Products:     resources.Products,
+				StorageBytes: storageBytes,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// A day stays pending until it has been rolled up after it ended
+		if day.Before(today) {
+			if err := s.store.RemovePending(ctx, period); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *usageService) tenantUsage(ctx context.Context, tenantID uint64, month string) (*domain.TenantUsageSummary, []*domain.Usage, error) {
+	clock, err := s.usageRepo.FindTenantClock(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := localDate(time.Now(), clock.Location())
+	monthStart, err := parseMonth(month, today)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Bring today's counters in so the current month is up to date
+	if monthStart.Equal(domain.MonthStart(today)) {
+		if err := s.RollupTenant(ctx, tenantID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	summary := &domain.TenantUsageSummary{
+		TenantID:   clock.TenantID,
+		TenantName: clock.Name,
+		Timezone:   clock.Timezone,
+		Month:      monthStart,
+		Usage: domain.Usage{
+			TenantID:    tenantID,
+			Period:      domain.PeriodMonth,
+			PeriodStart: monthStart,
+		},
+	}
+
+	usage, err := s.usageRepo.FindUsage(ctx, tenantID, domain.PeriodMonth, monthStart)
+	if err != nil && err.Error() != "usage not found" {
+		return nil, nil, err
+	}
+	if usage != nil {
+		summary.Usage = *usage
+	}
+
+	limits, err := s.usageRepo.FindPlanLimits(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	summary.Limits = *limits
+
+	days, err := s.usageRepo.FindDailyUsage(ctx, tenantID, monthStart, monthStart.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return summary, days, nil
+}
+
+func (s *usageService) requireSuperAdmin(ctx context.Context, userID uint64) error {
+	user, err := s.usageRepo.FindUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsSuperAdmin() {
+		return errors.New("only super admins can view usage of other tenants")
+	}
+	return nil
+}
+
+// storageBytes sums the size of the tenant's files. Every storage area keeps
+// a tenant's files under <area>/<tenant id>.
+func (s *usageService) storageBytes(tenantID uint64) (int64, error) {
+	areas, err := os.ReadDir(s.storagePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read storage: %w", err)
+	}
+
+	var total int64
+	for _, area := range areas {
+		if !area.IsDir() {
+			continue
+		}
+
+		tenantDir := filepath.Join(s.storagePath, area.Name(), strconv.FormatUint(tenantID, 10))
+		err := filepath.WalkDir(tenantDir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if entry.Type().IsRegular() {
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				total += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure storage: %w", err)
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordPriceChange adds change to the price history of its product. Every
+// writer of a product's cost or selling price records the change in the same
+// transaction, so the history stays complete whichever module made it.
+func RecordPriceChange(tx *gorm.DB, change *ProductPriceChange) error {
+	if err := tx.Omit(clause.Associations).Create(change).Error; err != nil {
+		return fmt.Errorf("failed to record price change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// LockSKU locks the SKUs of a tenant until tx ends and reports whether a
+// product or variant other than the excluded ones already has sku.
+// Products and their variants share one SKU namespace per tenant, which the
+// unique index of either table alone does not cover, so every write of a
+// SKU checks both tables under this lock first.
+func LockSKU(tx *gorm.DB, tenantID uint64, sku string, excludeProductID, excludeVariantID *uint64) (bool, error) {
+	return lockCode(tx, "product_skus", "sku", tenantID, sku, excludeProductID, excludeVariantID)
+}
+
+// LockBarcode locks the barcodes of a tenant until tx ends and reports
+// whether a product or variant other than the excluded ones already has
+// barcode. Like SKUs, barcodes are unique across both tables, while their
+// unique indexes cover one table each. Barcode assignment holds the same
+// lock while it numbers new barcodes.
+func LockBarcode(tx *gorm.DB, tenantID uint64, barcode string, excludeProductID, excludeVariantID *uint64) (bool, error) {
+	return lockCode(tx, "product_barcodes", "barcode", tenantID, barcode, excludeProductID, excludeVariantID)
+}
+
+func lockCode(tx *gorm.DB, lock, column string, tenantID uint64, value string, excludeProductID, excludeVariantID *uint64) (bool, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", lock, int32(tenantID)).Error; err != nil {
+		return false, fmt.Errorf("failed to lock %s: %w", lock, err)
+	}
+
+	var count int64
+
+	products := tx.Table("products").Where("tenant_id = ? AND "+column+" = ?", tenantID, value)
+	if excludeProductID != nil {
+		products = products.Where("id != ?", *excludeProductID)
+	}
+	if err := products.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check product %s: %w", column, err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	variants := tx.Table("product_variants").Where("tenant_id = ? AND "+column+" = ?", tenantID, value)
+	if excludeVariantID != nil {
+		variants = variants.Where("id != ?", *excludeVariantID)
+	}
+	if err := variants.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check variant %s: %w", column, err)
+	}
+
+	return count > 0, nil
+}
@@ -44,11 +44,13 @@ type ArchivedTransactionItem struct {
 	ID                      uint64    `gorm:"primaryKey"`
 	TransactionID           uint64    `gorm:"not null;index:idx_archived_transaction_items_transaction;index:idx_archived_items_trans_product"`
 	ProductID               uint64    `gorm:"not null;index:idx_archived_items_trans_product"`
+	VariantID               *uint64   `gorm:"index:idx_archived_transaction_items_variant"`
 	ProductNameSnapshot     string    `gorm:"size:255;not null"`
 	ProductSKUSnapshot      string    `gorm:"size:100;not null;index:idx_archived_transaction_items_product_sku_snapshot"`
 	ProductCategorySnapshot string    `gorm:"size:255"`
 	ProductUnitSnapshot     string    `gorm:"size:50;default:'pcs'"`
-	Quantity                int       `gorm:"not null"`
+	Quantity                float64   `gorm:"type:decimal(15,3);not null"`
+	UnitFactor              float64   `gorm:"type:decimal(15,6);not null;default:1"` // Base units in one sold unit
 	UnitPrice               float64   `gorm:"type:decimal(12,2);not null"`
 	CostPriceSnapshot       float64   `gorm:"type:decimal(12,2);default:0.00"`
 	DiscountAmount          float64   `gorm:"type:decimal(12,2);default:0.00"`
@@ -72,14 +72,15 @@ type Product struct {
 	TenantID     uint64       `gorm:"not null;uniqueIndex:idx_products_tenant_sku;index:idx_products_tenant_category"`
 	CategoryID   *uint64      `gorm:"index:idx_products_tenant_category"`
 	SKU          string       `gorm:"size:100;not null;uniqueIndex:idx_products_tenant_sku"`
-	Barcode      string       `gorm:"size:100;index:idx_products_barcode"`
+	Barcode      string       `gorm:"size:100"` // Unique per tenant when set, see cmd/migration
 	Name         string       `gorm:"size:255;not null;index:idx_products_name"`
 	Description  string       `gorm:"type:text"`
 	Unit         string       `gorm:"size:50;default:'pcs'"`
 	CostPrice    float64      `gorm:"type:decimal(12,2);default:0.00"`
 	SellingPrice float64      `gorm:"type:decimal(12,2);not null"`
-	MinStock     int          `gorm:"default:0"`
+	MinStock     float64      `gorm:"type:decimal(15,3);default:0"`
 	TrackStock   bool         `gorm:"default:true"`
+	TrackLots    bool         `gorm:"default:false"` // Stock is held in lots with expiry dates
 	IsActive     bool         `gorm:"default:true"`
 	Images       JSONArray    `gorm:"type:jsonb"`
 	Variants     JSONVariants `gorm:"type:jsonb"`
@@ -89,18 +90,315 @@ type Product struct {
 	Tenant           Tenant            `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
 	Category         *ProductCategory  `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
 	ProductStocks    []ProductStock    `gorm:"foreignKey:ProductID"`
+	ProductVariants  []ProductVariant  `gorm:"foreignKey:ProductID"`
 	TransactionItems []TransactionItem `gorm:"foreignKey:ProductID"`
 	StockMovements   []StockMovement   `gorm:"foreignKey:ProductID"`
 }
 
+// ProductSearchSQL sets up product search, which AutoMigrate cannot
+// express: a generated tsvector over the product's name, SKU, barcode and
+// description, and trigram indexes for typo-tolerant and prefix matching of
+// names, SKUs and category names. Every statement can run again.
+var ProductSearchSQL = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(sku, '') || ' ' || coalesce(barcode, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING gin (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING gin (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_sku_trgm ON products USING gin (sku gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_product_categories_name_trgm ON product_categories USING gin (name gin_trgm_ops)`,
+}
+
+// ProductVariant is one combination of a product's option values, such as
+// size L in red. The product's Variants column lists the options and their
+// values; every variant has its own SKU, barcode, price and stock.
+// Variant SKUs share the tenant's namespace with product SKUs; the unique
+// index only covers variants, so writers check both tables under LockSKU.
+type ProductVariant struct {
+	ID           uint64       `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64       `gorm:"not null;uniqueIndex:idx_product_variants_tenant_sku"`
+	ProductID    uint64       `gorm:"not null;index"`
+	SKU          string       `gorm:"size:100;not null;uniqueIndex:idx_product_variants_tenant_sku"`
+	Barcode      string       `gorm:"size:100"` // Unique per tenant when set, see cmd/migration
+	Name         string       `gorm:"size:255;not null"`
+	Options      JSONVariants `gorm:"type:jsonb;not null"` // Option name to value, e.g. {"Size": "L", "Color": "Red"}
+	SellingPrice *float64     `gorm:"type:decimal(12,2)"`  // Overrides the product's price when set
+	IsActive     bool         `gorm:"default:true"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time    `gorm:"autoUpdateTime"`
+
+	Tenant        Tenant         `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product       Product        `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ProductStocks []ProductStock `gorm:"foreignKey:VariantID"`
+}
+
+// ProductStock is the stock of a product, or of one of its variants, at an
+// outlet. Product and variant stock rows are unique per outlet separately.
 type ProductStock struct {
 	ID               uint64    `gorm:"primaryKey;autoIncrement"`
-	ProductID        uint64    `gorm:"not null;uniqueIndex:idx_product_stocks_product_outlet"`
-	OutletID         uint64    `gorm:"not null;uniqueIndex:idx_product_stocks_product_outlet;index:idx_product_stocks_outlet_quantity"`
-	Quantity         int       `gorm:"not null;default:0;index:idx_product_stocks_outlet_quantity"`
-	ReservedQuantity int       `gorm:"default:0"`
+	ProductID        uint64    `gorm:"not null;uniqueIndex:idx_product_stocks_product_outlet_base,where:variant_id IS NULL"`
+	VariantID        *uint64   `gorm:"uniqueIndex:idx_product_stocks_variant_outlet,where:variant_id IS NOT NULL"`
+	OutletID         uint64    `gorm:"not null;uniqueIndex:idx_product_stocks_product_outlet_base,where:variant_id IS NULL;uniqueIndex:idx_product_stocks_variant_outlet,where:variant_id IS NOT NULL;index:idx_product_stocks_outlet_quantity"`
+	Quantity         float64   `gorm:"type:decimal(15,3);not null;default:0;index:idx_product_stocks_outlet_quantity"`
+	ReservedQuantity float64   `gorm:"type:decimal(15,3);default:0"`
+	AverageCost      float64   `gorm:"type:decimal(12,2);not null;default:0"` // Weighted average unit cost of the stock on hand
 	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
 
+	Product Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Outlet  Outlet          `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+}
+
+// Unit is a unit of measure of a tenant, such as pcs, kg or ctn. Products
+// keep their base unit as its code in products.unit. Quantities in a unit
+// that does not allow decimals must be whole; a code without a unit row
+// counts in whole numbers too.
+type Unit struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64    `gorm:"not null;uniqueIndex:idx_units_tenant_code"`
+	Code         string    `gorm:"size:50;not null;uniqueIndex:idx_units_tenant_code"`
+	Name         string    `gorm:"size:100;not null"`
+	AllowDecimal bool      `gorm:"default:false"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+}
+
+// ProductUnit is another unit a product is bought, moved or sold in, such as
+// a carton of 24 pieces. One of the unit is Factor of the product's base
+// unit; stock is always kept in the base unit.
+type ProductUnit struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null;uniqueIndex:idx_product_units_product_unit"`
+	UnitID    uint64    `gorm:"not null;uniqueIndex:idx_product_units_product_unit;index"`
+	Factor    float64   `gorm:"type:decimal(15,6);not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	Product Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Unit    Unit    `gorm:"foreignKey:UnitID;constraint:OnDelete:NO ACTION"` // Checked at the end of the statement so tenant deletion can cascade
+}
+
+// ProductUnitSQL gives purchase and transfer lines from before units their
+// product's base unit, which their quantities were always in. Every
+// statement can run again.
+var ProductUnitSQL = []string{
+	`UPDATE purchase_order_items i SET unit = p.unit FROM products p WHERE p.id = i.product_id AND i.unit = ''`,
+	`UPDATE goods_receipt_items i SET unit = p.unit FROM products p WHERE p.id = i.product_id AND i.unit = ''`,
+	`UPDATE stock_transfer_items i SET unit = p.unit FROM products p WHERE p.id = i.product_id AND i.unit = ''`,
+}
+
+// ProductRecipeItem is one component of a product's recipe. Selling the
+// product consumes Quantity of the component, in the component's unit, for
+// every unit sold.
+type ProductRecipeItem struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64    `gorm:"not null;uniqueIndex:idx_product_recipe_items_product_component"`
+	ComponentID uint64    `gorm:"not null;uniqueIndex:idx_product_recipe_items_product_component;index:idx_product_recipe_items_component"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+
+	Product   Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Component Product `gorm:"foreignKey:ComponentID;constraint:OnDelete:NO ACTION"` // Checked at the end of the statement so tenant deletion can cascade
+}
+
+// ProductBundleGroup is a choice within a bundle, such as one drink out of
+// four: every bundle sold includes PickCount of the group's items.
+type ProductBundleGroup struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null;index"`
+	Name      string    `gorm:"size:100;not null"`
+	PickCount int       `gorm:"not null;default:1"`
+	SortOrder int       `gorm:"default:0"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
 	Product Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
-	Outlet  Outlet  `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+}
+
+// ProductBundleItem is one component of a bundle product. Items without a
+// group are part of every bundle sold; the items of a group are its choices.
+// Quantity is in the component's unit, per bundle sold.
+type ProductBundleItem struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64    `gorm:"not null;index"`
+	GroupID     *uint64   `gorm:"index"`
+	ComponentID uint64    `gorm:"not null;index"`
+	VariantID   *uint64   `gorm:"index"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+
+	Product   Product             `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Group     *ProductBundleGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	Component Product             `gorm:"foreignKey:ComponentID;constraint:OnDelete:NO ACTION"` // Checked at the end of the statement so tenant deletion can cascade
+	Variant   *ProductVariant     `gorm:"foreignKey:VariantID;constraint:OnDelete:NO ACTION"`
+}
+
+// ModifierGroup is a set of options added to a product at the counter, such
+// as sugar level or toppings. A sale picks from MinSelect up to MaxSelect
+// of its modifiers; a MinSelect above zero makes the group required.
+type ModifierGroup struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID  uint64    `gorm:"not null;index"`
+	Name      string    `gorm:"size:100;not null"`
+	MinSelect int       `gorm:"not null;default:0"`
+	MaxSelect int       `gorm:"not null;default:1"`
+	SortOrder int       `gorm:"default:0"`
+	IsActive  bool      `gorm:"default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	Tenant    Tenant     `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Modifiers []Modifier `gorm:"foreignKey:GroupID"`
+}
+
+// Modifier is one option of a modifier group. PriceDelta is added to the
+// price of the product it is picked for. A modifier with a product takes
+// Quantity of it from stock for every unit sold, in the product's unit.
+type Modifier struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	GroupID    uint64    `gorm:"not null;index"`
+	Name       string    `gorm:"size:100;not null"`
+	PriceDelta float64   `gorm:"type:decimal(12,2);not null;default:0.00"`
+	ProductID  *uint64   `gorm:"index"`
+	Quantity   float64   `gorm:"type:decimal(15,3);not null;default:1"`
+	SortOrder  int       `gorm:"default:0"`
+	IsActive   bool      `gorm:"default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+
+	Group   ModifierGroup `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	Product *Product      `gorm:"foreignKey:ProductID;constraint:OnDelete:NO ACTION"` // Checked at the end of the statement so tenant deletion can cascade
+}
+
+// ProductModifierGroup attaches a modifier group to a product
+type ProductModifierGroup struct {
+	ProductID       uint64 `gorm:"primaryKey"`
+	ModifierGroupID uint64 `gorm:"primaryKey;index"`
+	SortOrder       int    `gorm:"default:0"`
+
+	Product       Product       `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ModifierGroup ModifierGroup `gorm:"foreignKey:ModifierGroupID;constraint:OnDelete:CASCADE"`
+}
+
+// CategoryModifierGroup attaches a modifier group to every product of a
+// category
+type CategoryModifierGroup struct {
+	CategoryID      uint64 `gorm:"primaryKey"`
+	ModifierGroupID uint64 `gorm:"primaryKey;index"`
+	SortOrder       int    `gorm:"default:0"`
+
+	Category      ProductCategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+	ModifierGroup ModifierGroup   `gorm:"foreignKey:ModifierGroupID;constraint:OnDelete:CASCADE"`
+}
+
+// PriceList overrides selling prices while it is valid. A list without
+// outlets applies at every outlet, and one without customer groups to every
+// customer; when several lists price a product the highest priority wins.
+type PriceList struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64     `gorm:"not null;index"`
+	Name        string     `gorm:"size:255;not null"`
+	Description string     `gorm:"type:text"`
+	Priority    int        `gorm:"not null;default:0"`
+	StartsAt    *time.Time // NULL means valid from the start
+	EndsAt      *time.Time // Exclusive; NULL means valid until further notice
+	IsActive    bool       `gorm:"default:true"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+
+	Tenant         Tenant                   `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Outlets        []PriceListOutlet        `gorm:"foreignKey:PriceListID"`
+	CustomerGroups []PriceListCustomerGroup `gorm:"foreignKey:PriceListID"`
+	Items          []PriceListItem          `gorm:"foreignKey:PriceListID"`
+}
+
+type PriceListOutlet struct {
+	PriceListID uint64 `gorm:"primaryKey"`
+	OutletID    uint64 `gorm:"primaryKey;index"`
+
+	PriceList PriceList `gorm:"foreignKey:PriceListID;constraint:OnDelete:CASCADE"`
+	Outlet    Outlet    `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+}
+
+type PriceListCustomerGroup struct {
+	PriceListID     uint64 `gorm:"primaryKey"`
+	CustomerGroupID uint64 `gorm:"primaryKey;index"`
+
+	PriceList     PriceList     `gorm:"foreignKey:PriceListID;constraint:OnDelete:CASCADE"`
+	CustomerGroup CustomerGroup `gorm:"foreignKey:CustomerGroupID;constraint:OnDelete:CASCADE"`
+}
+
+// PriceListItem is the price of a product, or of one of its variants, in a
+// price list. A product's item also prices its variants that have no item of
+// their own.
+type PriceListItem struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	PriceListID uint64    `gorm:"not null;uniqueIndex:idx_price_list_items_product,where:variant_id IS NULL;uniqueIndex:idx_price_list_items_variant,where:variant_id IS NOT NULL"`
+	ProductID   uint64    `gorm:"not null;uniqueIndex:idx_price_list_items_product,where:variant_id IS NULL;index"`
+	VariantID   *uint64   `gorm:"uniqueIndex:idx_price_list_items_variant,where:variant_id IS NOT NULL"`
+	Price       float64   `gorm:"type:decimal(12,2);not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+
+	PriceList PriceList       `gorm:"foreignKey:PriceListID;constraint:OnDelete:CASCADE"`
+	Product   Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant   *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+}
+
+type PriceChangeSource string
+
+const (
+	PriceChangeSourceProductUpdate PriceChangeSource = "product_update"
+	PriceChangeSourceBulkUpdate    PriceChangeSource = "bulk_update"
+	PriceChangeSourceGoodsReceipt  PriceChangeSource = "goods_receipt"
+	PriceChangeSourceImport        PriceChangeSource = "import"
+)
+
+// ProductPriceChange records a change of a product's cost or selling price
+// with the prices before and after it.
+type ProductPriceChange struct {
+	ID              uint64            `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64            `gorm:"not null;index"`
+	ProductID       uint64            `gorm:"not null;index:idx_product_price_changes_product_effective"`
+	OldCostPrice    float64           `gorm:"type:decimal(12,2);not null"`
+	NewCostPrice    float64           `gorm:"type:decimal(12,2);not null"`
+	OldSellingPrice float64           `gorm:"type:decimal(12,2);not null"`
+	NewSellingPrice float64           `gorm:"type:decimal(12,2);not null"`
+	Source          PriceChangeSource `gorm:"size:20;not null"`
+	Reason          string            `gorm:"type:text"`
+	ChangedBy       uint64            `gorm:"not null"`
+	EffectiveAt     time.Time         `gorm:"not null;index:idx_product_price_changes_product_effective"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime"`
+
+	Tenant        Tenant  `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product       Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ChangedByUser User    `gorm:"foreignKey:ChangedBy"`
+}
+
+// Image is an uploaded picture of a product or a category, kept in file
+// storage. Thumbnails maps each thumbnail size name to its storage key.
+type Image struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID    uint64    `gorm:"not null;index"`
+	ProductID   *uint64   `gorm:"index"`
+	CategoryID  *uint64   `gorm:"index"`
+	StorageKey  string    `gorm:"size:500;not null"`
+	ContentType string    `gorm:"size:50;not null"`
+	Size        int64     `gorm:"not null"`
+	Width       int       `gorm:"not null"`
+	Height      int       `gorm:"not null"`
+	Thumbnails  JSONMap   `gorm:"type:jsonb"`
+	CreatedBy   uint64    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+
+	Tenant        Tenant           `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product       *Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Category      *ProductCategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+	CreatedByUser User             `gorm:"foreignKey:CreatedBy"`
 }
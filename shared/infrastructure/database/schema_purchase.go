@@ -0,0 +1,124 @@
+package database
+
+import (
+	"time"
+)
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderStatusDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderStatusOrdered           PurchaseOrderStatus = "ordered"
+	PurchaseOrderStatusPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderStatusReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderStatusCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+type Supplier struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64    `gorm:"not null;uniqueIndex:idx_suppliers_tenant_code;index:idx_suppliers_tenant_name"`
+	Code            string    `gorm:"size:50;not null;uniqueIndex:idx_suppliers_tenant_code"`
+	Name            string    `gorm:"size:255;not null;index:idx_suppliers_tenant_name"`
+	ContactPerson   string    `gorm:"size:255"`
+	Email           string    `gorm:"size:255"`
+	Phone           string    `gorm:"size:20"`
+	Address         string    `gorm:"type:text"`
+	City            string    `gorm:"size:100"`
+	TaxNumber       string    `gorm:"size:50"`
+	PaymentTermDays int       `gorm:"default:0"`
+	Notes           string    `gorm:"type:text"`
+	IsActive        bool      `gorm:"default:true"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+
+	Tenant         Tenant          `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	PurchaseOrders []PurchaseOrder `gorm:"foreignKey:SupplierID"`
+}
+
+// PurchaseOrder is an order placed with a supplier for delivery to one
+// outlet. Stock only changes when goods are received against it.
+type PurchaseOrder struct {
+	ID           uint64              `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64              `gorm:"not null;index:idx_purchase_orders_tenant_status;uniqueIndex:idx_purchase_orders_tenant_number"`
+	OrderNumber  string              `gorm:"size:50;not null;uniqueIndex:idx_purchase_orders_tenant_number"`
+	SupplierID   uint64              `gorm:"not null;index"`
+	OutletID     uint64              `gorm:"not null"`
+	Status       PurchaseOrderStatus `gorm:"size:30;not null;default:'draft';index:idx_purchase_orders_tenant_status"`
+	ExpectedDate *time.Time          `gorm:"type:date"`
+	TotalAmount  float64             `gorm:"type:decimal(15,2);not null;default:0"`
+	Notes        string              `gorm:"type:text"`
+	CreatedBy    uint64              `gorm:"not null"`
+	OrderedBy    *uint64
+	OrderedAt    *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	Tenant        Tenant              `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Supplier      Supplier            `gorm:"foreignKey:SupplierID;constraint:OnDelete:CASCADE"`
+	Outlet        Outlet              `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	CreatedByUser User                `gorm:"foreignKey:CreatedBy"`
+	OrderedByUser *User               `gorm:"foreignKey:OrderedBy"`
+	Items         []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderID"`
+}
+
+type PurchaseOrderItem struct {
+	ID               uint64  `gorm:"primaryKey;autoIncrement"`
+	PurchaseOrderID  uint64  `gorm:"not null;index"`
+	ProductID        uint64  `gorm:"not null"`
+	VariantID        *uint64 `gorm:"index"` // Variant ordered, if any
+	Unit             string  `gorm:"size:50;not null;default:''"`
+	UnitFactor       float64 `gorm:"type:decimal(15,6);not null;default:1"` // Base units in one Unit
+	Quantity         float64 `gorm:"type:decimal(15,3);not null"`           // Quantities and unit cost are per Unit
+	UnitCost         float64 `gorm:"type:decimal(15,2);not null;default:0"`
+	ReceivedQuantity float64 `gorm:"type:decimal(15,3);not null;default:0"`
+
+	PurchaseOrder PurchaseOrder   `gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:CASCADE"`
+	Product       Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant       *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+}
+
+// GoodsReceipt records goods delivered by a supplier, with or without a
+// purchase order. Receiving adds the goods to stock and updates the cost
+// price of the products.
+type GoodsReceipt struct {
+	ID              uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64    `gorm:"not null;index:idx_goods_receipts_tenant_date;uniqueIndex:idx_goods_receipts_tenant_number"`
+	ReceiptNumber   string    `gorm:"size:50;not null;uniqueIndex:idx_goods_receipts_tenant_number"`
+	PurchaseOrderID *uint64   `gorm:"index"`
+	SupplierID      uint64    `gorm:"not null;index"`
+	OutletID        uint64    `gorm:"not null"`
+	SupplierInvoice string    `gorm:"size:100"`
+	CostPricePolicy string    `gorm:"size:30;not null"`
+	TotalCost       float64   `gorm:"type:decimal(15,2);not null;default:0"`
+	Notes           string    `gorm:"type:text"`
+	ReceivedBy      uint64    `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"autoCreateTime;index:idx_goods_receipts_tenant_date"`
+
+	Tenant         Tenant             `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	PurchaseOrder  *PurchaseOrder     `gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:SET NULL"`
+	Supplier       Supplier           `gorm:"foreignKey:SupplierID;constraint:OnDelete:CASCADE"`
+	Outlet         Outlet             `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	ReceivedByUser User               `gorm:"foreignKey:ReceivedBy"`
+	Items          []GoodsReceiptItem `gorm:"foreignKey:GoodsReceiptID"`
+}
+
+type GoodsReceiptItem struct {
+	ID                  uint64  `gorm:"primaryKey;autoIncrement"`
+	GoodsReceiptID      uint64  `gorm:"not null;index"`
+	PurchaseOrderItemID *uint64 `gorm:"index"`
+	ProductID           uint64  `gorm:"not null"`
+	VariantID           *uint64 `gorm:"index"` // Variant received, if any
+	Unit                string  `gorm:"size:50;not null;default:''"`
+	UnitFactor          float64 `gorm:"type:decimal(15,6);not null;default:1"` // Base units in one Unit
+	Quantity            float64 `gorm:"type:decimal(15,3);not null"`           // Quantity and unit cost are per Unit
+	UnitCost            float64 `gorm:"type:decimal(15,2);not null;default:0"`
+	PreviousCostPrice   float64 `gorm:"type:decimal(15,2);not null;default:0"`
+	NewCostPrice        float64 `gorm:"type:decimal(15,2);not null;default:0"`
+	LotID               *uint64 `gorm:"index"` // Lot the goods were received into
+
+	GoodsReceipt      GoodsReceipt       `gorm:"foreignKey:GoodsReceiptID;constraint:OnDelete:CASCADE"`
+	PurchaseOrderItem *PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderItemID;constraint:OnDelete:SET NULL"`
+	Product           Product            `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant           *ProductVariant    `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Lot               *StockLot          `gorm:"foreignKey:LotID;constraint:OnDelete:SET NULL"`
+}
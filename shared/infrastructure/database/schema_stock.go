@@ -18,21 +18,280 @@ const (
 	ReferenceTypeAdjustment ReferenceType = "adjustment"
 	ReferenceTypeTransfer   ReferenceType = "transfer"
 	ReferenceTypeInitial    ReferenceType = "initial"
+	ReferenceTypeStocktake  ReferenceType = "stocktake"
 )
 
 type StockMovement struct {
 	ID            uint64        `gorm:"primaryKey;autoIncrement"`
 	ProductID     uint64        `gorm:"not null;index:idx_stock_movements_product_outlet"`
+	VariantID     *uint64       `gorm:"index"` // Set for movements of a variant's stock
 	OutletID      uint64        `gorm:"not null;index:idx_stock_movements_product_outlet;index:idx_stock_movements_outlet_date"`
 	MovementType  MovementType  `gorm:"not null"`
-	Quantity      int           `gorm:"not null"`
+	Quantity      float64       `gorm:"type:decimal(15,3);not null"`
 	ReferenceType ReferenceType `gorm:"not null;index:idx_stock_movements_reference"`
 	ReferenceID   *uint64       `gorm:"index:idx_stock_movements_reference"`
 	Notes         string        `gorm:"type:text"`
+	UnitCost      *float64      `gorm:"type:decimal(12,2)"` // Null for movements recorded before costing
+	TotalCost     *float64      `gorm:"type:decimal(15,2)"` // Signed like Quantity
 	CreatedBy     uint64        `gorm:"not null"`
 	CreatedAt     time.Time     `gorm:"autoCreateTime;index:idx_stock_movements_outlet_date"`
 
-	Product       Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
-	Outlet        Outlet  `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
-	CreatedByUser User    `gorm:"foreignKey:CreatedBy"`
+	Product       Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant       *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Outlet        Outlet          `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	CreatedByUser User            `gorm:"foreignKey:CreatedBy"`
+}
+
+type AdjustmentStatus string
+type AdjustmentReason string
+
+const (
+	AdjustmentStatusPendingApproval AdjustmentStatus = "pending_approval"
+	AdjustmentStatusApplied         AdjustmentStatus = "applied"
+	AdjustmentStatusRejected        AdjustmentStatus = "rejected"
+
+	AdjustmentReasonBreakage        AdjustmentReason = "breakage"
+	AdjustmentReasonTheft           AdjustmentReason = "theft"
+	AdjustmentReasonExpiry          AdjustmentReason = "expiry"
+	AdjustmentReasonCountCorrection AdjustmentReason = "count_correction"
+	AdjustmentReasonFound           AdjustmentReason = "found"
+	AdjustmentReasonOther           AdjustmentReason = "other"
+)
+
+// StockAdjustment is a manual stock correction at one outlet. Its lines are
+// written to product_stocks and stock_movements only once it is applied.
+type StockAdjustment struct {
+	ID               uint64           `gorm:"primaryKey;autoIncrement"`
+	TenantID         uint64           `gorm:"not null;index:idx_stock_adjustments_tenant_status;uniqueIndex:idx_stock_adjustments_tenant_number"`
+	OutletID         uint64           `gorm:"not null"`
+	AdjustmentNumber string           `gorm:"size:50;not null;uniqueIndex:idx_stock_adjustments_tenant_number"`
+	Status           AdjustmentStatus `gorm:"size:30;not null;index:idx_stock_adjustments_tenant_status"`
+	TotalValue       float64          `gorm:"type:decimal(15,2);not null;default:0"`
+	Notes            string           `gorm:"type:text"`
+	CreatedBy        uint64           `gorm:"not null"`
+	ReviewedBy       *uint64
+	ReviewedAt       *time.Time
+	RejectionReason  string    `gorm:"type:text"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+
+	Tenant         Tenant                `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Outlet         Outlet                `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	CreatedByUser  User                  `gorm:"foreignKey:CreatedBy"`
+	ReviewedByUser *User                 `gorm:"foreignKey:ReviewedBy"`
+	Items          []StockAdjustmentItem `gorm:"foreignKey:AdjustmentID"`
+}
+
+type StockAdjustmentItem struct {
+	ID           uint64           `gorm:"primaryKey;autoIncrement"`
+	AdjustmentID uint64           `gorm:"not null;index"`
+	ProductID    uint64           `gorm:"not null"`
+	Quantity     float64          `gorm:"type:decimal(15,3);not null"` // Negative quantities remove stock
+	Reason       AdjustmentReason `gorm:"size:30;not null"`
+	UnitCost     float64          `gorm:"type:decimal(15,2);not null;default:0"`
+	Notes        string           `gorm:"type:text"`
+
+	Adjustment StockAdjustment `gorm:"foreignKey:AdjustmentID;constraint:OnDelete:CASCADE"`
+	Product    Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusDraft             TransferStatus = "draft"
+	TransferStatusDispatched        TransferStatus = "dispatched"
+	TransferStatusPartiallyReceived TransferStatus = "partially_received"
+	TransferStatusReceived          TransferStatus = "received"
+	TransferStatusCancelled         TransferStatus = "cancelled"
+)
+
+// StockTransfer moves stock from one outlet of a tenant to another. Dispatch
+// takes the stock out of the source outlet and every receipt puts the
+// received quantities into the destination outlet.
+type StockTransfer struct {
+	ID                  uint64         `gorm:"primaryKey;autoIncrement"`
+	TenantID            uint64         `gorm:"not null;index:idx_stock_transfers_tenant_status;uniqueIndex:idx_stock_transfers_tenant_number"`
+	TransferNumber      string         `gorm:"size:50;not null;uniqueIndex:idx_stock_transfers_tenant_number"`
+	SourceOutletID      uint64         `gorm:"not null;index"`
+	DestinationOutletID uint64         `gorm:"not null;index"`
+	Status              TransferStatus `gorm:"size:30;not null;default:'draft';index:idx_stock_transfers_tenant_status"`
+	Notes               string         `gorm:"type:text"`
+	CreatedBy           uint64         `gorm:"not null"`
+	DispatchedBy        *uint64
+	DispatchedAt        *time.Time
+	ReceivedBy          *uint64
+	ReceivedAt          *time.Time
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+
+	Tenant            Tenant              `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	SourceOutlet      Outlet              `gorm:"foreignKey:SourceOutletID;constraint:OnDelete:CASCADE"`
+	DestinationOutlet Outlet              `gorm:"foreignKey:DestinationOutletID;constraint:OnDelete:CASCADE"`
+	CreatedByUser     User                `gorm:"foreignKey:CreatedBy"`
+	DispatchedByUser  *User               `gorm:"foreignKey:DispatchedBy"`
+	ReceivedByUser    *User               `gorm:"foreignKey:ReceivedBy"`
+	Items             []StockTransferItem `gorm:"foreignKey:TransferID"`
+}
+
+type StockTransferItem struct {
+	ID                uint64  `gorm:"primaryKey;autoIncrement"`
+	TransferID        uint64  `gorm:"not null;index"`
+	ProductID         uint64  `gorm:"not null"`
+	Unit              string  `gorm:"size:50;not null;default:''"`
+	UnitFactor        float64 `gorm:"type:decimal(15,6);not null;default:1"` // Base units in one Unit
+	Quantity          float64 `gorm:"type:decimal(15,3);not null"`           // In Unit, like ReceivedQuantity
+	ReceivedQuantity  float64 `gorm:"type:decimal(15,3);not null;default:0"`
+	DiscrepancyReason string  `gorm:"type:text"`
+
+	Transfer StockTransfer `gorm:"foreignKey:TransferID;constraint:OnDelete:CASCADE"`
+	Product  Product       `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+type StocktakeStatus string
+
+const (
+	StocktakeStatusCounting  StocktakeStatus = "counting"
+	StocktakeStatusFinalized StocktakeStatus = "finalized"
+	StocktakeStatusCancelled StocktakeStatus = "cancelled"
+)
+
+// Stocktake is a physical count of an outlet's stock. The expected
+// quantities are frozen when it starts; finalizing it posts the difference
+// between counted and expected quantities as adjustment movements.
+type Stocktake struct {
+	ID              uint64          `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64          `gorm:"not null;index:idx_stocktakes_tenant_status;uniqueIndex:idx_stocktakes_tenant_number"`
+	OutletID        uint64          `gorm:"not null;index"`
+	StocktakeNumber string          `gorm:"size:50;not null;uniqueIndex:idx_stocktakes_tenant_number"`
+	CategoryID      *uint64         // Limits the count to one category
+	Status          StocktakeStatus `gorm:"size:30;not null;default:'counting';index:idx_stocktakes_tenant_status"`
+	Notes           string          `gorm:"type:text"`
+	CreatedBy       uint64          `gorm:"not null"`
+	FinalizedBy     *uint64
+	FinalizedAt     *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+
+	Tenant          Tenant           `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Outlet          Outlet           `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	Category        *ProductCategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
+	CreatedByUser   User             `gorm:"foreignKey:CreatedBy"`
+	FinalizedByUser *User            `gorm:"foreignKey:FinalizedBy"`
+	Items           []StocktakeItem  `gorm:"foreignKey:StocktakeID"`
+}
+
+type StocktakeItem struct {
+	ID               uint64   `gorm:"primaryKey;autoIncrement"`
+	StocktakeID      uint64   `gorm:"not null;uniqueIndex:idx_stocktake_items_stocktake_product"`
+	ProductID        uint64   `gorm:"not null;uniqueIndex:idx_stocktake_items_stocktake_product"`
+	ExpectedQuantity float64  `gorm:"type:decimal(15,3);not null"`
+	CountedQuantity  *float64 `gorm:"type:decimal(15,3)"` // Nil until the product is counted
+	UnitCost         float64  `gorm:"type:decimal(15,2);not null;default:0"`
+	CountedAt        *time.Time
+
+	Stocktake Stocktake `gorm:"foreignKey:StocktakeID;constraint:OnDelete:CASCADE"`
+	Product   Product   `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+// StocktakeCount is one count submitted by a counter. A product's counted
+// quantity is the sum of its counts, so several devices can count the same
+// stocktake at once.
+type StocktakeCount struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	StocktakeID uint64    `gorm:"not null;index"`
+	ProductID   uint64    `gorm:"not null"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null"` // Negative quantities correct earlier counts
+	DeviceID    string    `gorm:"size:100"`
+	CountedBy   uint64    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+
+	Stocktake     Stocktake `gorm:"foreignKey:StocktakeID;constraint:OnDelete:CASCADE"`
+	Product       Product   `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	CountedByUser User      `gorm:"foreignKey:CountedBy"`
+}
+
+type StockAlertStatus string
+
+const (
+	StockAlertStatusOpen         StockAlertStatus = "open"
+	StockAlertStatusAcknowledged StockAlertStatus = "acknowledged"
+)
+
+// StockAlert records a stock movement that took a product at an outlet down
+// to its minimum stock. A product has at most one open alert per outlet.
+type StockAlert struct {
+	ID              uint64           `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64           `gorm:"not null;index:idx_stock_alerts_tenant_status"`
+	ProductID       uint64           `gorm:"not null;index:idx_stock_alerts_product_outlet"`
+	OutletID        uint64           `gorm:"not null;index:idx_stock_alerts_product_outlet"`
+	Quantity        float64          `gorm:"type:decimal(15,3);not null"` // Stock left after the movement
+	MinStock        float64          `gorm:"type:decimal(15,3);not null"`
+	Status          StockAlertStatus `gorm:"size:30;not null;default:'open';index:idx_stock_alerts_tenant_status"`
+	StockMovementID *uint64
+	ReferenceType   string  `gorm:"size:30;not null;index:idx_stock_alerts_reference"`
+	ReferenceID     *uint64 `gorm:"index:idx_stock_alerts_reference"`
+	AcknowledgedBy  *uint64
+	AcknowledgedAt  *time.Time
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+
+	Tenant             Tenant         `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product            Product        `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Outlet             Outlet         `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	StockMovement      *StockMovement `gorm:"foreignKey:StockMovementID;constraint:OnDelete:SET NULL"`
+	AcknowledgedByUser *User          `gorm:"foreignKey:AcknowledgedBy"`
+}
+
+// StockLot is the part of a product's stock at an outlet that came in as one
+// batch. Lots are only kept for products with track_lots; their quantities
+// never add up to more than the outlet's product_stocks quantity, and any
+// stock not held in a lot is consumed after all lots.
+type StockLot struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID   uint64     `gorm:"not null;index:idx_stock_lots_tenant_expiry"`
+	ProductID  uint64     `gorm:"not null;uniqueIndex:idx_stock_lots_product_outlet_number"`
+	OutletID   uint64     `gorm:"not null;uniqueIndex:idx_stock_lots_product_outlet_number"`
+	LotNumber  string     `gorm:"size:100;not null;uniqueIndex:idx_stock_lots_product_outlet_number"`
+	ExpiryDate *time.Time `gorm:"type:date;index:idx_stock_lots_tenant_expiry"`
+	Quantity   float64    `gorm:"type:decimal(15,3);not null;default:0"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+
+	Tenant  Tenant  `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Outlet  Outlet  `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+}
+
+// StockMovementLot is the share of a stock movement taken from or put into
+// one lot, so every lot can be traced through the movements that touched it.
+type StockMovementLot struct {
+	ID              uint64  `gorm:"primaryKey;autoIncrement"`
+	StockMovementID uint64  `gorm:"not null;index"`
+	LotID           uint64  `gorm:"not null;index"`
+	Quantity        float64 `gorm:"type:decimal(15,3);not null"` // Negative quantities are taken from the lot
+
+	StockMovement StockMovement `gorm:"foreignKey:StockMovementID;constraint:OnDelete:CASCADE"`
+	Lot           StockLot      `gorm:"foreignKey:LotID;constraint:OnDelete:CASCADE"`
+}
+
+// StockCostLayer is stock that entered an outlet at one unit cost. Layers
+// are consumed oldest first as stock leaves the outlet, which gives the FIFO
+// cost of every decrease; stock on hand from before costing existed has no
+// layer and is consumed before all layers.
+type StockCostLayer struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID          uint64    `gorm:"not null;index"`
+	ProductID         uint64    `gorm:"not null;index:idx_stock_cost_layers_product_outlet"`
+	VariantID         *uint64   `gorm:"index"`
+	OutletID          uint64    `gorm:"not null;index:idx_stock_cost_layers_product_outlet"`
+	StockMovementID   uint64    `gorm:"not null;index"`
+	UnitCost          float64   `gorm:"type:decimal(12,2);not null"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	RemainingQuantity float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+
+	Tenant        Tenant          `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Product       Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variant       *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	Outlet        Outlet          `gorm:"foreignKey:OutletID;constraint:OnDelete:CASCADE"`
+	StockMovement StockMovement   `gorm:"foreignKey:StockMovementID;constraint:OnDelete:CASCADE"`
 }
@@ -29,6 +29,29 @@ func (j *JSONMap) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// JSONRaw stores an arbitrary JSON document as is
+type JSONRaw json.RawMessage
+
+func (j JSONRaw) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return []byte(j), nil
+}
+
+func (j *JSONRaw) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	*j = append((*j)[:0], bytes...)
+	return nil
+}
+
 type RetentionType string
 type RetentionStatus string
 
@@ -36,6 +59,7 @@ const (
 	RetentionTypeTransactionArchive RetentionType = "transaction_archive"
 	RetentionTypeTransactionDelete  RetentionType = "transaction_delete"
 	RetentionTypeAuditCleanup       RetentionType = "audit_cleanup"
+	RetentionTypeTenantDelete       RetentionType = "tenant_delete"
 
 	RetentionStatusSuccess RetentionStatus = "success"
 	RetentionStatusFailed  RetentionStatus = "failed"
@@ -46,6 +70,7 @@ type AuditLog struct {
 	ID        uint64    `gorm:"primaryKey;autoIncrement"`
 	TenantID  uint64    `gorm:"not null;index:idx_audit_logs_tenant_date"`
 	UserID    *uint64   `gorm:"index:idx_audit_logs_user_date;constraint:OnDelete:SET NULL"`
+	ActorID   *uint64   `gorm:"index:idx_audit_logs_actor_date"`
 	Action    string    `gorm:"size:100;not null"`
 	TableName string    `gorm:"size:100;index:idx_audit_logs_table_record"`
 	RecordID  *uint64   `gorm:"index:idx_audit_logs_table_record"`
@@ -53,10 +78,28 @@ type AuditLog struct {
 	NewValues JSONMap   `gorm:"type:jsonb"`
 	IPAddress net.IP    `gorm:"type:inet"`
 	UserAgent string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_audit_logs_tenant_date;index:idx_audit_logs_user_date"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_audit_logs_tenant_date;index:idx_audit_logs_user_date;index:idx_audit_logs_actor_date"`
 
 	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
 	User   *User  `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
+	Actor  *User  `gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL"`
+}
+
+type ImpersonationSession struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID  uint64    `gorm:"not null;index:idx_impersonation_sessions_tenant_date"`
+	ActorID   uint64    `gorm:"not null;index:idx_impersonation_sessions_actor"`
+	UserID    uint64    `gorm:"not null"`
+	Reason    string    `gorm:"type:text;not null"`
+	IPAddress string    `gorm:"size:45"`
+	UserAgent string    `gorm:"type:text"`
+	ExpiresAt time.Time `gorm:"not null"`
+	EndedAt   *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_impersonation_sessions_tenant_date"`
+
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	Actor  User   `gorm:"foreignKey:ActorID;constraint:OnDelete:CASCADE"`
+	User   User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
 
 type DataRetentionLog struct {
@@ -71,5 +114,61 @@ type DataRetentionLog struct {
 	ErrorMessage    string          `gorm:"type:text"`
 	CreatedAt       time.Time       `gorm:"autoCreateTime;index:idx_data_retention_logs_tenant_type_date"`
 
-	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	// No foreign key to tenants: tenant deletions are recorded here and the
+	// log must outlive the tenant it describes.
+}
+
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "pending"
+	ExportStatusProcessing ExportStatus = "processing"
+	ExportStatusCompleted  ExportStatus = "completed"
+	ExportStatusFailed     ExportStatus = "failed"
+)
+
+type TenantExport struct {
+	ID           uint64       `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64       `gorm:"not null;index:idx_tenant_exports_tenant_date"`
+	RequestedBy  uint64       `gorm:"not null"`
+	Format       string       `gorm:"size:10;not null"`
+	Status       ExportStatus `gorm:"size:20;not null;default:'pending'"`
+	FilePath     string       `gorm:"size:500"`
+	FileSize     int64        `gorm:"default:0"`
+	RecordCounts JSONMap      `gorm:"type:jsonb"`
+	ErrorMessage string       `gorm:"type:text"`
+	ExpiresAt    *time.Time
+	CompletedAt  *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_tenant_exports_tenant_date"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	Tenant          Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	RequestedByUser User   `gorm:"foreignKey:RequestedBy;constraint:OnDelete:CASCADE"`
+}
+
+type ImportJob struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID          uint64    `gorm:"not null;index:idx_import_jobs_tenant_date"`
+	CreatedBy         uint64    `gorm:"not null"`
+	ImportType        string    `gorm:"size:30;not null"`
+	FileName          string    `gorm:"size:255;not null"`
+	FilePath          string    `gorm:"size:500;not null"`
+	FileFormat        string    `gorm:"size:10;not null"`
+	Status            string    `gorm:"size:30;not null;default:'uploaded'"`
+	Headers           JSONArray `gorm:"type:jsonb"`
+	Mapping           JSONMap   `gorm:"type:jsonb"`
+	TotalRows         int       `gorm:"default:0"`
+	ProcessedRows     int       `gorm:"default:0"`
+	SuccessRows       int       `gorm:"default:0"`
+	FailedRows        int       `gorm:"default:0"`
+	RollbackOnFailure bool      `gorm:"default:false"`
+	RowErrors         JSONRaw   `gorm:"type:jsonb"`
+	ErrorMessage      string    `gorm:"type:text"`
+	StartedAt         *time.Time
+	CompletedAt       *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime;index:idx_import_jobs_tenant_date"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
+
+	Tenant        Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+	CreatedByUser User   `gorm:"foreignKey:CreatedBy;constraint:OnDelete:CASCADE"`
 }
@@ -72,8 +72,9 @@ type Tenant struct {
 	Currency     string `gorm:"size:3;default:'IDR'"`
 	IsActive     bool   `gorm:"default:true;index"`
 	TrialEndsAt  *time.Time
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	Settings     JSONSettings `gorm:"type:jsonb"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time    `gorm:"autoUpdateTime"`
 
 	Subscriptions []TenantSubscription `gorm:"foreignKey:TenantID"`
 	Users         []User               `gorm:"foreignKey:TenantID"`
@@ -95,3 +96,29 @@ type TenantSubscription struct {
 	Tenant           Tenant           `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
 	SubscriptionPlan SubscriptionPlan `gorm:"foreignKey:SubscriptionPlanID"`
 }
+
+type UsagePeriod string
+
+const (
+	UsagePeriodDay   UsagePeriod = "day"
+	UsagePeriodMonth UsagePeriod = "month"
+)
+
+// TenantUsage holds the metered usage of a tenant for one day or month.
+// PeriodStart is the first day of the period in the tenant's timezone.
+type TenantUsage struct {
+	ID           uint64      `gorm:"primaryKey;autoIncrement"`
+	TenantID     uint64      `gorm:"not null;uniqueIndex:idx_tenant_usages_tenant_period"`
+	Period       UsagePeriod `gorm:"size:10;not null;uniqueIndex:idx_tenant_usages_tenant_period;index:idx_tenant_usages_period_start"`
+	PeriodStart  time.Time   `gorm:"type:date;not null;uniqueIndex:idx_tenant_usages_tenant_period;index:idx_tenant_usages_period_start"`
+	Transactions int64       `gorm:"default:0"`
+	APICalls     int64       `gorm:"column:api_calls;default:0"`
+	ActiveUsers  int         `gorm:"default:0"`
+	Outlets      int         `gorm:"default:0"`
+	Products     int         `gorm:"default:0"`
+	StorageBytes int64       `gorm:"default:0"`
+	CreatedAt    time.Time   `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time   `gorm:"autoUpdateTime"`
+
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE"`
+}
@@ -67,11 +67,13 @@ type TransactionItem struct {
 	ID                      uint64  `gorm:"primaryKey;autoIncrement"`
 	TransactionID           uint64  `gorm:"not null;index:idx_transaction_items_transaction"`
 	ProductID               uint64  `gorm:"not null;index:idx_transaction_items_product"`
+	VariantID               *uint64 `gorm:"index:idx_transaction_items_variant"` // Variant sold, if any
 	ProductNameSnapshot     string  `gorm:"size:255;not null;index:idx_transaction_items_product_name_snapshot"`
 	ProductSKUSnapshot      string  `gorm:"size:100;not null;index:idx_transaction_items_product_sku_snapshot"`
 	ProductCategorySnapshot string  `gorm:"size:255"`
 	ProductUnitSnapshot     string  `gorm:"size:50;default:'pcs'"`
-	Quantity                int     `gorm:"not null"`
+	Quantity                float64 `gorm:"type:decimal(15,3);not null"`
+	UnitFactor              float64 `gorm:"type:decimal(15,6);not null;default:1"` // Base units in one sold unit
 	UnitPrice               float64 `gorm:"type:decimal(12,2);not null"`
 	CostPriceSnapshot       float64 `gorm:"type:decimal(12,2);default:0.00"`
 	DiscountAmount          float64 `gorm:"type:decimal(12,2);default:0.00"`
@@ -80,6 +82,49 @@ type TransactionItem struct {
 
 	Transaction SalesTransaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE"`
 	Product     Product          `gorm:"foreignKey:ProductID"`
+	Variant     *ProductVariant  `gorm:"foreignKey:VariantID;constraint:OnDelete:SET NULL"`
+}
+
+// TransactionItemComponent is a component of a bundle sold in a
+// transaction item. The item's amount is allocated over its components in
+// proportion to their own selling prices, so sales of the components can be
+// reported with their share of the bundle price.
+type TransactionItemComponent struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TransactionItemID uint64    `gorm:"not null;index"`
+	ProductID         uint64    `gorm:"not null;index"`
+	VariantID         *uint64   `gorm:"index"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	AllocatedAmount   float64   `gorm:"type:decimal(15,2);not null"`
+	CostAmount        float64   `gorm:"type:decimal(15,2);default:0.00"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+
+	TransactionItem TransactionItem `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE"`
+	Product         Product         `gorm:"foreignKey:ProductID"`
+	Variant         *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:SET NULL"`
+}
+
+// TransactionItemModifier is a modifier picked for a transaction item, with
+// its group, name and price delta as they were at the sale so receipts,
+// kitchen tickets and reports show them after the modifier changes. Quantity
+// is the number of units it was picked for, and ProductQuantity what it
+// took of its stock product, if any.
+type TransactionItemModifier struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TransactionItemID uint64    `gorm:"not null;index"`
+	ModifierID        *uint64   `gorm:"index"`
+	GroupNameSnapshot string    `gorm:"size:100;not null"`
+	NameSnapshot      string    `gorm:"size:100;not null"`
+	PriceDelta        float64   `gorm:"type:decimal(12,2);not null;default:0.00"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	ProductID         *uint64   `gorm:"index"`
+	ProductQuantity   float64   `gorm:"type:decimal(15,3);not null;default:0"`
+	CostAmount        float64   `gorm:"type:decimal(15,2);default:0.00"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+
+	TransactionItem TransactionItem `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE"`
+	Modifier        *Modifier       `gorm:"foreignKey:ModifierID;constraint:OnDelete:SET NULL"`
+	Product         *Product        `gorm:"foreignKey:ProductID;constraint:OnDelete:SET NULL"`
 }
 
 type TransactionPayment struct {
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+// transactionState is the transaction a context carries and what runs once
+// it commits
+type transactionState struct {
+	tx          *gorm.DB
+	afterCommit []func(ctx context.Context)
+}
+
+// InTransaction runs fn in a database transaction carried by the context fn
+// is given, so repositories of other modules called with that context join
+// it instead of starting their own. Called with a context that already
+// carries a transaction, fn runs in a savepoint of it. Functions added with
+// AfterCommit run once the outermost transaction has committed.
+func InTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	if state, ok := ctx.Value(transactionKey{}).(*transactionState); ok {
+		return state.tx.Transaction(func(tx *gorm.DB) error {
+			return fn(ctx, tx)
+		})
+	}
+
+	state := &transactionState{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, transactionKey{}, state), tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, run := range state.afterCommit {
+		run(ctx)
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the context's transaction has committed, or right
+// away when the context carries none. It is dropped if the transaction rolls
+// back.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(transactionKey{}).(*transactionState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
@@ -0,0 +1,118 @@
+package stockledger
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// changeCost is what a stock change costs and the outlet's average unit
+// cost after it
+type changeCost struct {
+	UnitCost    float64
+	TotalCost   float64 // Signed like the change's quantity
+	AverageCost float64
+}
+
+// costChange prices a change of the locked stock row. Increases come in at
+// their own unit cost, or the outlet's average cost when they have none,
+// and move the average. Decreases take stock from the cost layers oldest
+// first and are costed from those layers under FIFO, or at the average cost
+// under weighted average costing; the layers are consumed either way, so a
+// tenant can switch methods without the layers drifting from the stock.
+func costChange(tx *gorm.DB, change Change, product product, stock StockRow) (changeCost, error) {
+	average := averageCost(stock, product)
+
+	if change.Quantity < 0 {
+		fifoCost, err := consumeCostLayers(tx, change, stock, average)
+		if err != nil {
+			return changeCost{}, err
+		}
+
+		quantity := -change.Quantity
+		totalCost := fifoCost
+		if product.CostingMethod == CostingMethodWeightedAverage {
+			totalCost = quantity * average
+		}
+
+		return changeCost{
+			UnitCost:    RoundCost(totalCost / quantity),
+			TotalCost:   -RoundCost(totalCost),
+			AverageCost: average,
+		}, nil
+	}
+
+	unitCost := average
+	if change.UnitCost != nil {
+		unitCost = *change.UnitCost
+	}
+	if change.Quantity == 0 {
+		return changeCost{UnitCost: unitCost, AverageCost: average}, nil
+	}
+
+	totalCost := RoundCost(change.Quantity * unitCost)
+	onHand := stock.Quantity
+	return changeCost{
+		UnitCost:    unitCost,
+		TotalCost:   totalCost,
+		AverageCost: RoundCost((onHand*average + totalCost) / (onHand + change.Quantity)),
+	}, nil
+}
+
+// consumeCostLayers takes a decrease from the product's cost layers at the
+// outlet and returns its FIFO cost. Stock on hand from before costing has no
+// layer; it is the oldest stock, so it goes first, at the average cost.
+func consumeCostLayers(tx *gorm.DB, change Change, stock StockRow, average float64) (float64, error) {
+	layered := tx.Where("product_id = ? AND outlet_id = ? AND remaining_quantity > 0", change.ProductID, change.OutletID)
+	if change.VariantID != nil {
+		layered = layered.Where("variant_id = ?", *change.VariantID)
+	} else {
+		layered = layered.Where("variant_id IS NULL")
+	}
+
+	var layers []costLayer
+	err := layered.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("id ASC").
+		Find(&layers).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock stock cost layers: %w", err)
+	}
+
+	unlayered := stock.Quantity
+	for _, layer := range layers {
+		unlayered -= layer.RemainingQuantity
+	}
+
+	needed := -change.Quantity
+	taken := min(max(RoundQuantity(unlayered), 0), needed)
+	cost := taken * average
+	needed = RoundQuantity(needed - taken)
+
+	for i := range layers {
+		if needed <= 0 {
+			break
+		}
+
+		taken := min(layers[i].RemainingQuantity, needed)
+		err := tx.Model(&costLayer{}).
+			Where("id = ?", layers[i].ID).
+			Update("remaining_quantity", RoundQuantity(layers[i].RemainingQuantity-taken)).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update stock cost layer: %w", err)
+		}
+		cost += taken * layers[i].UnitCost
+		needed = RoundQuantity(needed - taken)
+	}
+
+	return cost, nil
+}
+
+// averageCost is the outlet's average unit cost of the product, or its cost
+// price while the outlet has none, as for stock from before costing
+func averageCost(stock StockRow, product product) float64 {
+	if stock.AverageCost > 0 {
+		return stock.AverageCost
+	}
+	return product.CostPrice
+}
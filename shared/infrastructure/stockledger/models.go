@@ -0,0 +1,102 @@
+package stockledger
+
+import "time"
+
+// The ledger's models cover the columns the writer reads and writes; modules
+// keep their own models for reading stock.
+
+type StockRow struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64    `gorm:"not null"`
+	VariantID   *uint64   `gorm:"column:variant_id"`
+	OutletID    uint64    `gorm:"not null"`
+	Quantity    float64   `gorm:"type:decimal(15,3);not null;default:0"`
+	AverageCost float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (StockRow) TableName() string {
+	return "product_stocks"
+}
+
+type Movement struct {
+	ID            uint64  `gorm:"primaryKey;autoIncrement"`
+	ProductID     uint64  `gorm:"not null"`
+	VariantID     *uint64 `gorm:"column:variant_id"`
+	OutletID      uint64  `gorm:"not null"`
+	MovementType  string  `gorm:"not null"`
+	Quantity      float64 `gorm:"type:decimal(15,3);not null"`
+	ReferenceType string  `gorm:"not null"`
+	ReferenceID   *uint64
+	Notes         string    `gorm:"type:text"`
+	UnitCost      *float64  `gorm:"type:decimal(12,2)"`
+	TotalCost     *float64  `gorm:"type:decimal(15,2)"`
+	CreatedBy     uint64    `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (Movement) TableName() string {
+	return "stock_movements"
+}
+
+type costLayer struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	TenantID          uint64    `gorm:"not null"`
+	ProductID         uint64    `gorm:"not null"`
+	VariantID         *uint64   `gorm:"column:variant_id"`
+	OutletID          uint64    `gorm:"not null"`
+	StockMovementID   uint64    `gorm:"not null"`
+	UnitCost          float64   `gorm:"type:decimal(12,2);not null"`
+	Quantity          float64   `gorm:"type:decimal(15,3);not null"`
+	RemainingQuantity float64   `gorm:"type:decimal(15,3);not null"`
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (costLayer) TableName() string {
+	return "stock_cost_layers"
+}
+
+type Lot struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement"`
+	TenantID   uint64     `gorm:"not null"`
+	ProductID  uint64     `gorm:"not null"`
+	OutletID   uint64     `gorm:"not null"`
+	LotNumber  string     `gorm:"size:100;not null"`
+	ExpiryDate *time.Time `gorm:"type:date"`
+	Quantity   float64    `gorm:"type:decimal(15,3);not null;default:0"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (Lot) TableName() string {
+	return "stock_lots"
+}
+
+type movementLot struct {
+	ID              uint64  `gorm:"primaryKey;autoIncrement"`
+	StockMovementID uint64  `gorm:"not null"`
+	LotID           uint64  `gorm:"not null"`
+	Quantity        float64 `gorm:"type:decimal(15,3);not null"`
+}
+
+func (movementLot) TableName() string {
+	return "stock_movement_lots"
+}
+
+type alert struct {
+	ID              uint64  `gorm:"primaryKey;autoIncrement"`
+	TenantID        uint64  `gorm:"not null"`
+	ProductID       uint64  `gorm:"not null"`
+	OutletID        uint64  `gorm:"not null"`
+	Quantity        float64 `gorm:"type:decimal(15,3);not null"`
+	MinStock        float64 `gorm:"type:decimal(15,3);not null"`
+	Status          string  `gorm:"size:30;not null"`
+	StockMovementID *uint64
+	ReferenceType   string `gorm:"size:30;not null"`
+	ReferenceID     *uint64
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (alert) TableName() string {
+	return "stock_alerts"
+}
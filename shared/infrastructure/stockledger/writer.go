@@ -0,0 +1,374 @@
+// Package stockledger writes changes of product stock. Every module that
+// moves stock goes through Apply, so stock rows, movements, cost layers,
+// lots and low stock alerts stay consistent with each other.
+package stockledger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CostingMethodFIFO            = "fifo"
+	CostingMethodWeightedAverage = "weighted_average"
+
+	// Sales skip expired lots
+	ReferenceTypeSale = "sale"
+
+	alertStatusOpen = "open"
+)
+
+// Change is one signed change of a product's stock at an outlet, recorded
+// as a stock movement.
+type Change struct {
+	ProductID     uint64
+	VariantID     *uint64 // Changes the variant's stock instead of the product's
+	OutletID      uint64
+	Quantity      float64
+	MovementType  string
+	ReferenceType string
+	ReferenceID   uint64 // Zero when no document is behind the change, as for initial stock
+	Notes         string
+	CreatedBy     uint64
+	UnitCost      *float64      // Cost of an increase; the outlet's average cost when nil
+	CostPrice     *float64      // Cost of stock from before costing; the product's cost price when nil
+	Lots          []LotQuantity // Lots an increase goes into; the rest is not held in a lot
+}
+
+// LotQuantity is the part of an increase that goes into one lot
+type LotQuantity struct {
+	LotNumber  string
+	ExpiryDate *time.Time
+	Quantity   float64
+}
+
+// product is what the writer needs to know about the changed product
+type product struct {
+	TenantID      uint64
+	MinStock      float64
+	TrackLots     bool
+	CostPrice     float64
+	CostingMethod string
+}
+
+// LockStockRow locks the stock row of a product, or of one of its variants,
+// at an outlet, creating it when missing
+func LockStockRow(tx *gorm.DB, productID uint64, variantID *uint64, outletID uint64) (StockRow, error) {
+	stock := StockRow{ProductID: productID, VariantID: variantID, OutletID: outletID}
+
+	// Product and variant rows are unique through separate partial indexes
+	conflict := clause.OnConflict{
+		Columns:     []clause.Column{{Name: "product_id"}, {Name: "outlet_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NULL"}}},
+		DoNothing:   true,
+	}
+	locate := tx.Where("product_id = ? AND outlet_id = ? AND variant_id IS NULL", productID, outletID)
+	if variantID != nil {
+		conflict.Columns = []clause.Column{{Name: "variant_id"}, {Name: "outlet_id"}}
+		conflict.TargetWhere = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "variant_id IS NOT NULL"}}}
+		locate = tx.Where("variant_id = ? AND outlet_id = ?", *variantID, outletID)
+	}
+
+	if err := tx.Clauses(conflict).Create(&stock).Error; err != nil {
+		return stock, fmt.Errorf("failed to create product stock: %w", err)
+	}
+
+	err := locate.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stock).Error
+	if err != nil {
+		return stock, fmt.Errorf("failed to lock product stock: %w", err)
+	}
+
+	return stock, nil
+}
+
+// Apply updates product_stocks and writes the matching stock_movements row
+// within tx. The stock row is created when missing and locked for the
+// update, so concurrent changes queue up instead of losing writes. Changes
+// that would leave the stock below zero are refused, every change is costed
+// from the outlet's cost layers under the tenant's costing method, changes
+// of lot-tracked products are spread over their lots, and changes that take
+// the stock down to the product's minimum raise an alert. It returns the
+// movement written.
+func Apply(tx *gorm.DB, change Change) (*Movement, error) {
+	var product product
+	err := tx.Table("products p").
+		Select("p.tenant_id, p.min_stock, p.track_lots, p.cost_price, "+
+			"COALESCE(NULLIF(t.settings->'inventory'->>'costing_method', ''), ?) AS costing_method", CostingMethodFIFO).
+		Joins("JOIN tenants t ON t.id = p.tenant_id").
+		Where("p.id = ?", change.ProductID).
+		Take(&product).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if change.CostPrice != nil {
+		product.CostPrice = *change.CostPrice
+	}
+
+	stock, err := LockStockRow(tx, change.ProductID, change.VariantID, change.OutletID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := RoundQuantity(stock.Quantity + change.Quantity)
+	if quantity < 0 {
+		return nil, errors.New("insufficient stock")
+	}
+
+	cost, err := costChange(tx, change, product, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Model(&stock).Updates(map[string]interface{}{
+		"quantity":     quantity,
+		"average_cost": cost.AverageCost,
+		"updated_at":   time.Now(),
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product stock: %w", err)
+	}
+
+	// Initial stock has no document to reference
+	var referenceID *uint64
+	if change.ReferenceID != 0 {
+		id := change.ReferenceID
+		referenceID = &id
+	}
+	movement := &Movement{
+		ProductID:     change.ProductID,
+		VariantID:     change.VariantID,
+		OutletID:      change.OutletID,
+		MovementType:  change.MovementType,
+		Quantity:      change.Quantity,
+		ReferenceType: change.ReferenceType,
+		ReferenceID:   referenceID,
+		Notes:         change.Notes,
+		UnitCost:      &cost.UnitCost,
+		TotalCost:     &cost.TotalCost,
+		CreatedBy:     change.CreatedBy,
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	if change.Quantity > 0 {
+		layer := &costLayer{
+			TenantID:          product.TenantID,
+			ProductID:         change.ProductID,
+			VariantID:         change.VariantID,
+			OutletID:          change.OutletID,
+			StockMovementID:   movement.ID,
+			UnitCost:          cost.UnitCost,
+			Quantity:          change.Quantity,
+			RemainingQuantity: change.Quantity,
+		}
+		if err := tx.Create(layer).Error; err != nil {
+			return nil, fmt.Errorf("failed to create stock cost layer: %w", err)
+		}
+	}
+
+	// Lots and stock alerts are kept for products, not their variants
+	if change.VariantID != nil {
+		return movement, nil
+	}
+
+	if product.TrackLots {
+		if change.Quantity > 0 {
+			err = receiveIntoLots(tx, change, product.TenantID, movement.ID)
+		} else {
+			err = consumeLots(tx, change, stock.Quantity, movement.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if change.Quantity < 0 {
+		if err := raiseLowStockAlert(tx, change, product, stock.Quantity, quantity, movement.ID); err != nil {
+			return nil, err
+		}
+	}
+	return movement, nil
+}
+
+// receiveIntoLots adds the lot quantities of an increase to their lots.
+// Whatever the lots do not account for stays outside of any lot.
+func receiveIntoLots(tx *gorm.DB, change Change, tenantID, movementID uint64) error {
+	for _, lotChange := range change.Lots {
+		if lotChange.Quantity <= 0 {
+			continue
+		}
+
+		lot, err := LockLot(tx, tenantID, change.ProductID, change.OutletID, lotChange.LotNumber, lotChange.ExpiryDate)
+		if err != nil {
+			return err
+		}
+		if err := changeLot(tx, lot, lotChange.Quantity, movementID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consumeLots takes a decrease from the product's lots at the outlet,
+// first-expired, first-out, and from the stock not held in any lot last.
+// Sales skip expired lots, so a sale that only expired stock could cover is
+// refused.
+func consumeLots(tx *gorm.DB, change Change, before float64, movementID uint64) error {
+	var lots []Lot
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND outlet_id = ? AND quantity > 0", change.ProductID, change.OutletID).
+		Order("expiry_date ASC NULLS LAST, id ASC").
+		Find(&lots).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock stock lots: %w", err)
+	}
+
+	unassigned := before
+	for _, lot := range lots {
+		unassigned -= lot.Quantity
+	}
+	unassigned = max(RoundQuantity(unassigned), 0)
+
+	needed := -change.Quantity
+	today := time.Now().Format("2006-01-02")
+	for i := range lots {
+		if needed <= 0 {
+			break
+		}
+		expired := lots[i].ExpiryDate != nil && lots[i].ExpiryDate.Format("2006-01-02") < today
+		if change.ReferenceType == ReferenceTypeSale && expired {
+			continue
+		}
+
+		taken := min(lots[i].Quantity, needed)
+		if err := changeLot(tx, &lots[i], -taken, movementID); err != nil {
+			return err
+		}
+		needed = RoundQuantity(needed - taken)
+	}
+
+	if needed > unassigned {
+		return errors.New("insufficient unexpired stock")
+	}
+	return nil
+}
+
+// LockLot finds a product's lot at an outlet by its number, creating it when
+// missing, and locks it. Receiving into an existing lot must repeat the
+// lot's expiry date, so one lot number never stands for two batches.
+func LockLot(tx *gorm.DB, tenantID, productID, outletID uint64, lotNumber string, expiryDate *time.Time) (*Lot, error) {
+	lot := Lot{
+		TenantID:   tenantID,
+		ProductID:  productID,
+		OutletID:   outletID,
+		LotNumber:  lotNumber,
+		ExpiryDate: expiryDate,
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "outlet_id"}, {Name: "lot_number"}},
+		DoNothing: true,
+	}).Create(&lot).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stock lot: %w", err)
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND outlet_id = ? AND lot_number = ?", productID, outletID, lotNumber).
+		First(&lot).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock stock lot: %w", err)
+	}
+
+	if !sameDate(lot.ExpiryDate, expiryDate) {
+		return nil, errors.New("lot expiry date does not match")
+	}
+
+	return &lot, nil
+}
+
+// changeLot applies a signed quantity to a locked lot and records it
+// against the stock movement
+func changeLot(tx *gorm.DB, lot *Lot, quantity float64, movementID uint64) error {
+	lot.Quantity = RoundQuantity(lot.Quantity + quantity)
+
+	err := tx.Model(&Lot{}).Where("id = ?", lot.ID).Updates(map[string]interface{}{
+		"quantity":   lot.Quantity,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update stock lot: %w", err)
+	}
+
+	record := &movementLot{
+		StockMovementID: movementID,
+		LotID:           lot.ID,
+		Quantity:        quantity,
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return fmt.Errorf("failed to create stock movement lot: %w", err)
+	}
+
+	return nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// raiseLowStockAlert records an alert when a movement takes the stock from
+// above the product's minimum to at or below it. The stock row lock held by
+// the caller keeps two movements from opening an alert each.
+func raiseLowStockAlert(tx *gorm.DB, change Change, product product, before, after float64, movementID uint64) error {
+	if before <= product.MinStock || after > product.MinStock {
+		return nil
+	}
+
+	var open int64
+	err := tx.Model(&alert{}).
+		Where("product_id = ? AND outlet_id = ? AND status = ?", change.ProductID, change.OutletID, alertStatusOpen).
+		Count(&open).Error
+	if err != nil {
+		return fmt.Errorf("failed to check stock alerts: %w", err)
+	}
+	if open > 0 {
+		return nil
+	}
+
+	referenceID := change.ReferenceID
+	record := &alert{
+		TenantID:        product.TenantID,
+		ProductID:       change.ProductID,
+		OutletID:        change.OutletID,
+		Quantity:        after,
+		MinStock:        product.MinStock,
+		Status:          alertStatusOpen,
+		StockMovementID: &movementID,
+		ReferenceType:   change.ReferenceType,
+		ReferenceID:     &referenceID,
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return fmt.Errorf("failed to create stock alert: %w", err)
+	}
+
+	return nil
+}
+
+// RoundQuantity rounds a stock quantity to the 3 decimals it is stored with
+func RoundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// RoundCost rounds a cost to cents
+func RoundCost(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Storage keeps uploaded files under slash separated keys, such as
+// "tenants/1/products/5/ab12.jpg", and serves them from a public URL.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the file; a missing file is not an error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+type Config struct {
+	Driver    string
+	LocalPath string
+	// PublicURL is the base URL files are served from. For S3 it defaults to
+	// the bucket's own URL.
+	PublicURL string
+	S3        S3Config
+}
+
+// New builds the storage backend selected by the config's driver
+func New(config Config) (Storage, error) {
+	switch config.Driver {
+	case "", DriverLocal:
+		return NewLocalStorage(config.LocalPath, config.PublicURL), nil
+	case DriverS3:
+		return NewS3Storage(config.S3, config.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+	}
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -55,6 +55,24 @@ func JWTAuth(secret string) echo.MiddlewareFunc {
 			c.Set("email", email)
 			c.Set("role_id", roleID)
 
+			// Impersonation tokens carry the real caller in the "act" claim
+			if act, ok := claims["act"].(map[string]interface{}); ok {
+				actorID, ok := act["user_id"].(float64)
+				if !ok {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "Invalid token claims",
+					})
+				}
+				impersonationID, ok := claims["impersonation_id"].(float64)
+				if !ok {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "Invalid token claims",
+					})
+				}
+				c.Set("actor_id", uint64(actorID))
+				c.Set("impersonation_id", uint64(impersonationID))
+			}
+
 			return next(c)
 		}
 	}
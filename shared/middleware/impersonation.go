@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/exven/pos-system/shared/infrastructure/database"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ImpersonationAudit rejects impersonation tokens whose session has ended or
+// expired and records every request made with a live one in audit_logs,
+// tagged with the impersonating actor. Requests made with regular tokens pass
+// through untouched.
+func ImpersonationAudit(db *gorm.DB) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			actorID, ok := c.Get("actor_id").(uint64)
+			if !ok {
+				return next(c)
+			}
+
+			impersonationID := c.Get("impersonation_id").(uint64)
+			tenantID := c.Get("tenant_id").(uint64)
+			userID := c.Get("user_id").(uint64)
+
+			var active int64
+			err := db.WithContext(c.Request().Context()).
+				Model(&database.ImpersonationSession{}).
+				Where("id = ? AND tenant_id = ? AND actor_id = ? AND ended_at IS NULL AND expires_at > NOW()",
+					impersonationID, tenantID, actorID).
+				Count(&active).Error
+			if err != nil || active == 0 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Impersonation session has ended",
+				})
+			}
+
+			handlerErr := next(c)
+
+			status := c.Response().Status
+			if he, ok := handlerErr.(*echo.HTTPError); ok {
+				status = he.Code
+			}
+
+			newValues := database.JSONMap{
+				"method": c.Request().Method,
+				"path":   c.Path(),
+				"uri":    c.Request().RequestURI,
+				"status": status,
+			}
+
+			auditLog := map[string]interface{}{
+				"tenant_id":  tenantID,
+				"user_id":    userID,
+				"actor_id":   actorID,
+				"action":     "impersonation.request",
+				"table_name": "impersonation_sessions",
+				"record_id":  impersonationID,
+				"new_values": newValues,
+				"user_agent": c.Request().UserAgent(),
+				"created_at": time.Now(),
+			}
+			if ip := net.ParseIP(c.RealIP()); ip != nil {
+				auditLog["ip_address"] = ip.String()
+			}
+
+			// Audit failures must not change the outcome of the request
+			db.WithContext(c.Request().Context()).Table("audit_logs").Create(auditLog)
+
+			return handlerErr
+		}
+	}
+}
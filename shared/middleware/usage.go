@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UsageRecorder meters API calls per tenant
+type UsageRecorder interface {
+	RecordAPICall(ctx context.Context, tenantID, userID uint64)
+}
+
+// UsageMetering counts every authenticated request towards the tenant's API
+// calls and marks the user active for the day. Requests made while
+// impersonating count as API calls but do not make the user active.
+func UsageMetering(recorder UsageRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			handlerErr := next(c)
+
+			tenantID, ok := c.Get("tenant_id").(uint64)
+			if !ok {
+				return handlerErr
+			}
+
+			var userID uint64
+			if _, impersonating := c.Get("actor_id").(uint64); !impersonating {
+				userID, _ = c.Get("user_id").(uint64)
+			}
+
+			recorder.RecordAPICall(c.Request().Context(), tenantID, userID)
+
+			return handlerErr
+		}
+	}
+}
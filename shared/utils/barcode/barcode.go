@@ -0,0 +1,179 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// EAN13CheckDigit returns the check digit completing the first 12 digits of
+// an EAN-13 code
+func EAN13CheckDigit(digits string) (byte, error) {
+	if len(digits) != 12 || !isDigits(digits) {
+		return 0, errors.New("EAN-13 needs 12 digits before the check digit")
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+// ValidEAN13 reports whether code is 13 digits ending in the right check
+// digit
+func ValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	check, err := EAN13CheckDigit(code[:12])
+	return err == nil && check == code[12]
+}
+
+var ean13Left = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+var ean13Even = [10]string{
+	"0100111", "0110011", "0011011", "0100001", "0011101",
+	"0111001", "0000101", "0010001", "0001001", "0010111",
+}
+
+var ean13Right = [10]string{
+	"1110010", "1100110", "1101100", "1000010", "1011100",
+	"1001110", "1010000", "1000100", "1001000", "1110100",
+}
+
+// ean13Parity picks odd (L) or even (G) codes for digits 2 to 7 by the
+// first digit, which is not drawn itself
+var ean13Parity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EncodeEAN13 returns the 95 modules of an EAN-13 symbol, true for a bar
+func EncodeEAN13(code string) ([]bool, error) {
+	if !ValidEAN13(code) {
+		return nil, errors.New("invalid EAN-13 code")
+	}
+
+	var b strings.Builder
+	b.WriteString("101")
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		d := code[i] - '0'
+		if parity[i-1] == 'L' {
+			b.WriteString(ean13Left[d])
+		} else {
+			b.WriteString(ean13Even[d])
+		}
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		b.WriteString(ean13Right[code[i]-'0'])
+	}
+	b.WriteString("101")
+
+	return modules(b.String()), nil
+}
+
+// code128Patterns are the bar and space widths of every Code 128 symbol
+// value; 103 to 105 are the start codes and 106 is the stop code
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// EncodeCode128 returns the modules of a Code 128 symbol for printable
+// ASCII data, true for a bar. Data of an even number of digits is encoded
+// in code set C, two digits a symbol; anything else in code set B.
+func EncodeCode128(data string) ([]bool, error) {
+	if data == "" {
+		return nil, errors.New("Code 128 data is empty")
+	}
+
+	var values []int
+	if len(data)%2 == 0 && isDigits(data) {
+		values = append(values, code128StartC)
+		for i := 0; i < len(data); i += 2 {
+			values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for i := 0; i < len(data); i++ {
+			c := data[i]
+			if c < 32 || c > 126 {
+				return nil, errors.New("Code 128 data must be printable ASCII")
+			}
+			values = append(values, int(c)-32)
+		}
+	}
+
+	check := values[0]
+	for i := 1; i < len(values); i++ {
+		check += i * values[i]
+	}
+	values = append(values, check%103, code128Stop)
+
+	var b strings.Builder
+	for _, value := range values {
+		bar := true
+		for _, width := range code128Patterns[value] {
+			for n := 0; n < int(width-'0'); n++ {
+				if bar {
+					b.WriteByte('1')
+				} else {
+					b.WriteByte('0')
+				}
+			}
+			bar = !bar
+		}
+	}
+
+	return modules(b.String()), nil
+}
+
+// Encode returns the modules of code as EAN-13 when it is a valid EAN-13
+// code and as Code 128 otherwise
+func Encode(code string) ([]bool, error) {
+	if ValidEAN13(code) {
+		return EncodeEAN13(code)
+	}
+	return EncodeCode128(code)
+}
+
+func modules(pattern string) []bool {
+	bars := make([]bool, len(pattern))
+	for i := range pattern {
+		bars[i] = pattern[i] == '1'
+	}
+	return bars
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
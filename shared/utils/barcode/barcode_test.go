@@ -0,0 +1,164 @@
+package barcode
+
+import "testing"
+
+func TestEAN13CheckDigit(t *testing.T) {
+	tests := []struct {
+		name    string
+		digits  string
+		want    byte
+		wantErr bool
+	}{
+		{"EAN-13", "400638133393", '1', false},
+		{"EAN-13 of GS1 Poland", "590123412345", '7', false},
+		{"ISBN-13", "978030640615", '7', false},
+		{"UPC-A 036000291452", "003600029145", '2', false},
+		{"UPC-A 012345678905", "001234567890", '5', false},
+		{"in-store prefix", "200000000000", '8', false},
+		{"check digit of zero", "000000000000", '0', false},
+		{"too short", "40063813339", 0, true},
+		{"check digit included", "4006381333931", 0, true},
+		{"not digits", "40063813339X", 0, true},
+		{"empty", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EAN13CheckDigit(tt.digits)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EAN13CheckDigit(%q) = %q, want an error", tt.digits, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EAN13CheckDigit(%q) error = %v", tt.digits, err)
+			}
+			if got != tt.want {
+				t.Errorf("EAN13CheckDigit(%q) = %q, want %q", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"9780306406157", true},
+		{"0036000291452", true},
+		{"4006381333932", false},
+		{"5901234123475", false},
+		// A UPC-A code is valid EAN-13 only with its leading zero
+		{"036000291452", false},
+		{"40063813339310", false},
+		{"400638133393A", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidEAN13(tt.code); got != tt.want {
+			t.Errorf("ValidEAN13(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeEAN13(t *testing.T) {
+	bars, err := EncodeEAN13("4006381333931")
+	if err != nil {
+		t.Fatalf("EncodeEAN13() error = %v", err)
+	}
+	if len(bars) != 95 {
+		t.Fatalf("EncodeEAN13() has %d modules, want 95", len(bars))
+	}
+
+	// Start, centre and end guards
+	for _, guard := range []struct {
+		at      int
+		pattern string
+	}{{0, "101"}, {45, "01010"}, {92, "101"}} {
+		if got := pattern(bars[guard.at : guard.at+len(guard.pattern)]); got != guard.pattern {
+			t.Errorf("guard at module %d = %s, want %s", guard.at, got, guard.pattern)
+		}
+	}
+
+	// 4 picks L G L L G G for the left half: 0 as L, then 0 as G
+	if got := pattern(bars[3:10]); got != "0001101" {
+		t.Errorf("second digit = %s, want 0 in L code 0001101", got)
+	}
+	if got := pattern(bars[10:17]); got != "0100111" {
+		t.Errorf("third digit = %s, want 0 in G code 0100111", got)
+	}
+	// The check digit 1 is the last symbol of the right half
+	if got := pattern(bars[85:92]); got != "1100110" {
+		t.Errorf("check digit = %s, want 1 in R code 1100110", got)
+	}
+
+	if _, err := EncodeEAN13("4006381333932"); err == nil {
+		t.Error("EncodeEAN13() of a wrong check digit: want an error")
+	}
+}
+
+func TestEncodeCode128(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		modules int
+		wantErr bool
+	}{
+		// Start, check and stop take 11 + 11 + 13 modules around the data
+		{"code set B", "SKU-1", 35 + 5*11, false},
+		{"even digits in code set C", "123456", 35 + 3*11, false},
+		{"odd digits in code set B", "12345", 35 + 5*11, false},
+		{"empty", "", 0, true},
+		{"not printable", "café", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bars, err := EncodeCode128(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EncodeCode128(%q): want an error", tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeCode128(%q) error = %v", tt.data, err)
+			}
+			if len(bars) != tt.modules {
+				t.Errorf("EncodeCode128(%q) has %d modules, want %d", tt.data, len(bars), tt.modules)
+			}
+			if !bars[0] || !bars[len(bars)-1] {
+				t.Errorf("EncodeCode128(%q) does not start and end with a bar", tt.data)
+			}
+		})
+	}
+}
+
+func TestEncodePicksTheSymbology(t *testing.T) {
+	ean, err := Encode("5901234123457")
+	if err != nil || len(ean) != 95 {
+		t.Errorf("Encode() of an EAN-13 code = %d modules, %v, want 95", len(ean), err)
+	}
+
+	// 13 digits with a wrong check digit fall back to Code 128
+	other, err := Encode("5901234123458")
+	if err != nil || len(other) == 95 {
+		t.Errorf("Encode() of a wrong EAN-13 code = %d modules, %v, want Code 128", len(other), err)
+	}
+}
+
+func pattern(bars []bool) string {
+	b := make([]byte, len(bars))
+	for i, bar := range bars {
+		b[i] = '0'
+		if bar {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
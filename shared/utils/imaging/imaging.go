@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+// Thumbnail scales the image down to fit within size x size pixels, keeping
+// its aspect ratio. Each target pixel averages the source pixels it covers,
+// weighted by their alpha. Images that already fit are returned unchanged.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (srcW <= size && srcH <= size) {
+		return src
+	}
+
+	dstW, dstH := size, size
+	if srcW >= srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(nrgba, nrgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, (y+1)*srcH/dstH
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, (x+1)*srcW/dstW
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := nrgba.Pix[sy*nrgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					pa := uint64(p[3])
+					r += uint64(p[0]) * pa
+					g += uint64(p[1]) * pa
+					b += uint64(p[2]) * pa
+					a += pa
+					n++
+				}
+			}
+
+			c := color.NRGBA{}
+			if a > 0 {
+				c.R = uint8(r / a)
+				c.G = uint8(g / a)
+				c.B = uint8(b / a)
+				c.A = uint8(a / n)
+			}
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+
+	return dst
+}
+
+// Encode writes the image as JPEG when the source was a JPEG and as PNG
+// otherwise, so transparency survives. It returns the data, its content type
+// and the file extension to store it under.
+func Encode(img image.Image, sourceFormat string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+
+	if sourceFormat == FormatJPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode image: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", "jpg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), "image/png", "png", nil
+}
@@ -0,0 +1,272 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// FormatFromFilename returns the spreadsheet format for a file name, or an
+// empty string when the extension is not supported.
+func FormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	}
+	return ""
+}
+
+// ReadFile reads every row of a CSV file or of the first worksheet of an XLSX
+// file. Trailing empty rows are dropped.
+func ReadFile(filePath, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return ReadCSV(file)
+	case FormatXLSX:
+		return ReadXLSX(filePath)
+	}
+	return nil, fmt.Errorf("unsupported spreadsheet format: %s", format)
+}
+
+// ReadCSV reads comma or semicolon separated values. The separator is taken
+// from the header line, since spreadsheets saved with a comma decimal locale
+// use semicolons.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	headerLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		headerLine = data[:i]
+	}
+	if bytes.Count(headerLine, []byte(";")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+
+	return trimEmptyRows(rows), nil
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref       string       `xml:"r,attr"`
+			Type      string       `xml:"t,attr"`
+			Value     string       `xml:"v"`
+			InlineStr xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the first worksheet of an Office Open XML workbook. Cell
+// values are returned as displayed text for strings and as the stored value
+// for numbers; dates stay as serial numbers.
+func ReadXLSX(filePath string) ([][]string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXMLFile(f, &shared); err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("XLSX worksheet not found")
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeXMLFile(sheetFile, &sheet); err != nil {
+		return nil, fmt.Errorf("failed to read worksheet: %w", err)
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		values := []string{}
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				column = columnIndex(cell.Ref)
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid shared string reference in cell %s", cell.Ref)
+				}
+				values[column] = shared.Items[index].String()
+			case "inlineStr":
+				values[column] = cell.InlineStr.String()
+			case "b":
+				values[column] = map[string]string{"1": "true", "0": "false"}[cell.Value]
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return trimEmptyRows(rows), nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("not an XLSX workbook")
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeXMLFile(workbookFile, &workbook); err != nil {
+		return "", fmt.Errorf("failed to read workbook: %w", err)
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("XLSX workbook has no worksheets")
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+
+	var rels xlsxRelationships
+	if err := decodeXMLFile(relsFile, &rels); err != nil {
+		return "", fmt.Errorf("failed to read workbook relationships: %w", err)
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelationshipID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeXMLFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// columnIndex converts the column part of a cell reference such as "AB12"
+// to a zero-based index.
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
+
+func trimEmptyRows(rows [][]string) [][]string {
+	for len(rows) > 0 {
+		last := rows[len(rows)-1]
+		empty := true
+		for _, value := range last {
+			if strings.TrimSpace(value) != "" {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			break
+		}
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}